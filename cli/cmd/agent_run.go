@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/agent"
+	"github.com/easel/ddx/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// runAgentPresets lists the built-in agent presets
+func (f *CommandFactory) runAgentPresets(cmd *cobra.Command, args []string) error {
+	configured := ""
+	if cfg, err := config.LoadWithWorkingDir(f.WorkingDir); err == nil && cfg.Agents != nil {
+		configured = cfg.Agents.Preset
+	}
+	verbose, _ := cmd.Flags().GetBool("verbose")
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PRESET\tINVOCATION\tMODEL\tDESCRIPTION")
+	for _, preset := range agent.Presets() {
+		name := preset.Name
+		if name == configured {
+			name += " *"
+		}
+		model := preset.ModelFlag
+		if model == "" && preset.ModelEnv != "" {
+			model = "$" + preset.ModelEnv
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, presetInvocation(preset), model, preset.Description)
+	}
+	_ = w.Flush()
+
+	if verbose {
+		_, _ = fmt.Fprintln(out)
+		for _, preset := range agent.Presets() {
+			_, _ = fmt.Fprintf(out, "%s: %s\n", preset.Name, preset.Notes)
+		}
+	}
+	_, _ = fmt.Fprintln(out, "\nSelect one with 'ddx config set agents.preset <name>'.")
+	return nil
+}
+
+// presetInvocation shows how a preset receives the prompt
+func presetInvocation(preset agent.Preset) string {
+	line := strings.Join(append([]string{preset.Command}, preset.Args...), " ")
+	switch preset.Input {
+	case agent.InputStdin:
+		line += " < prompt"
+	case agent.InputArg:
+		line += " <prompt>"
+	}
+	return line
+}
+
+// runAgentRun sends a prompt to the configured agent
+func (f *CommandFactory) runAgentRun(cmd *cobra.Command, args []string) error {
+	presetFlag, _ := cmd.Flags().GetString("preset")
+	modelFlag, _ := cmd.Flags().GetString("model")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	prompt, err := readAgentPrompt(cmd, f.WorkingDir, args[0])
+	if err != nil {
+		return err
+	}
+	prompt, err = renderWithVariables(cmd, f.WorkingDir, prompt)
+	if err != nil {
+		return err
+	}
+	if extra := strings.TrimSpace(strings.Join(args[1:], " ")); extra != "" {
+		prompt += "\n\n" + extra
+	}
+
+	settings := &config.AgentsConfig{}
+	if cfg, err := config.LoadWithWorkingDir(f.WorkingDir); err == nil && cfg.Agents != nil {
+		settings = cfg.Agents
+	}
+	if presetFlag != "" {
+		// A preset chosen on the command line replaces the configured agent
+		settings = &config.AgentsConfig{Preset: presetFlag, Model: settings.Model}
+	}
+	if modelFlag != "" {
+		settings.Model = modelFlag
+	}
+
+	resolved, err := agent.Resolve(settings)
+	if err != nil {
+		return err
+	}
+	inv, err := resolved.Prepare(prompt, !isTerminal(cmd.OutOrStdout()))
+	if err != nil {
+		return err
+	}
+	defer inv.Cleanup()
+
+	if dryRun {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Would run: %s\n", inv)
+		return nil
+	}
+
+	cmd.SilenceUsage = true
+	if err := inv.Run(cmd.Context(), f.WorkingDir, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmd.SilenceErrors = true
+			return NewExitError(exitErr.ExitCode(), "")
+		}
+		return err
+	}
+	return nil
+}
+
+// readAgentPrompt reads the prompt from stdin ("-"), a file in the
+// project, or the library's prompts
+func readAgentPrompt(cmd *cobra.Command, workingDir, name string) (string, error) {
+	if name == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		if path, err = resolvePromptPath(workingDir, name); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	return string(data), nil
+}
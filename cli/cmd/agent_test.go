@@ -312,3 +312,51 @@ phases:
     name: Todo
 `)
 }
+
+// TestAgentRun sends library prompts to the configured agent
+func TestAgentRun(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: ".ddx/library"
+agents:
+  preset: aider
+  model: gpt-4o
+`)
+	env.CreateFile(".ddx/library/prompts/review.md", "Review {{project_name}}")
+
+	output, err := env.RunCommand("agent", "run", "review", "--dry-run", "--var", "project_name=demo", "--no-record")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Would run: aider --message-file ") || !strings.Contains(output, "--model gpt-4o") {
+		t.Errorf("output = %q, want aider invocation with model", output)
+	}
+
+	output, err = env.RunCommand("agent", "run", "review", "--dry-run", "--preset", "llm")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Would run: llm prompt -m gpt-4o") {
+		t.Errorf("output = %q, want llm invocation", output)
+	}
+
+	// A custom command receives the rendered prompt on stdin
+	env.CreateConfig(`version: "1.0"
+library:
+  path: ".ddx/library"
+agents:
+  command: cat
+`)
+	output, err = env.RunCommand("agent", "run", "review", "focus on tests", "--var", "project_name=demo", "--no-record")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "Review demo\n\nfocus on tests" {
+		t.Errorf("output = %q, want rendered prompt with instructions", output)
+	}
+
+	if _, err := env.RunCommand("agent", "run", "missing"); err == nil || !strings.Contains(err.Error(), "prompt not found") {
+		t.Errorf("err = %v, want prompt not found", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/targets"
+)
+
+// aiTargets resolves the configured AI targets with the injection rules
+// applied, returning the targets that may be written and those in a
+// disabled directory
+func aiTargets(cfg *config.Config) ([]targets.Target, []targets.Target, error) {
+	list, err := targets.Resolve(cfg.GetAITargets())
+	if err != nil {
+		return nil, nil, err
+	}
+	return injectionRules(cfg).Apply(list)
+}
+
+// injectionRules returns the project's injection settings as target rules
+func injectionRules(cfg *config.Config) targets.Rules {
+	if cfg == nil || cfg.Injection == nil {
+		return targets.Rules{}
+	}
+	return targets.Rules{
+		ClaudeFile:    cfg.Injection.ClaudeFile,
+		DisabledPaths: cfg.Injection.DisabledPaths,
+	}
+}
+
+// claudeFile returns the file used for the claude target, CLAUDE.md unless
+// injection.claude_file names another
+func claudeFile(cfg *config.Config) string {
+	if rules := injectionRules(cfg); rules.ClaudeFile != "" {
+		return rules.ClaudeFile
+	}
+	target, _ := targets.Lookup(targets.DefaultTarget)
+	return target.File
+}
+
+// errInjectionDisabled is returned when every AI target is in a directory
+// listed in injection.disabled_paths
+func errInjectionDisabled(disabled []targets.Target) error {
+	return fmt.Errorf("injection.disabled_paths disables every AI target (%s)", strings.Join(targets.Files(disabled), ", "))
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAITargets(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+ai:
+  targets: [claude, cursor, copilot]
+persona_bindings:
+  code-reviewer: strict-reviewer
+`)
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "# Strict Reviewer\n\nReview everything.\n")
+	env.CreateFile(".ddx/library/prompts/claude/system-prompts/focused.md", "Stay focused.\n")
+	env.CreateFile(".ddx/library/prompts/common/review.md", "Review carefully.\n")
+	env.CreateFile(".cursorrules", "Use tabs.\n")
+	read := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, file))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("personas", func(t *testing.T) {
+		output, err := env.RunCommand("persona", "load")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "into CLAUDE.md, .cursorrules, .github/copilot-instructions.md")
+
+		assert.Contains(t, read("CLAUDE.md"), "# CLAUDE.md")
+		assert.Contains(t, read(".cursorrules"), "Use tabs.\n\n<!-- DDX:START name=\"personas\"")
+		copilot := read(".github/copilot-instructions.md")
+		assert.Contains(t, copilot, "### Code Reviewer: strict-reviewer")
+		assert.NotContains(t, copilot, "# CLAUDE.md", "only CLAUDE.md gets the Claude header")
+
+		output, err = env.RunCommand("persona", "status")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "strict-reviewer")
+	})
+
+	t.Run("meta-prompt", func(t *testing.T) {
+		cfg, err := config.LoadWithWorkingDir(env.Dir)
+		require.NoError(t, err)
+		require.NoError(t, syncMetaPromptWithConfig(cfg, env.Dir))
+		for _, file := range []string{"CLAUDE.md", ".cursorrules", ".github/copilot-instructions.md"} {
+			assert.Contains(t, read(file), "Stay focused.", file)
+		}
+		assert.NoError(t, checkMetaPromptSync(env.Dir))
+
+		env.CreateFile(".ddx/library/prompts/claude/system-prompts/focused.md", "Stay very focused.\n")
+		assert.EqualError(t, checkMetaPromptSync(env.Dir),
+			"meta-prompt is out of sync with library in CLAUDE.md, .cursorrules, .github/copilot-instructions.md")
+	})
+
+	t.Run("prompt sections", func(t *testing.T) {
+		output, err := env.RunCommand("prompt", "inject", "common/review.md")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Injected common/review.md into .cursorrules as section 'review'")
+		assert.Contains(t, read(".github/copilot-instructions.md"), `<!-- DDX:START name="review"`)
+
+		output, err = env.RunCommand("prompt", "remove", "review")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Removed section 'review' from CLAUDE.md, .cursorrules, .github/copilot-instructions.md")
+		assert.NotContains(t, read(".cursorrules"), `name="review"`)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\nai:\n  targets: [emacs]\n")
+		_, err := env.RunCommand("persona", "load")
+		assert.ErrorContains(t, err, `unknown AI target "emacs"`)
+	})
+}
+
+func TestInjectionRules(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+ai:
+  targets: [claude, docs/generated/CLAUDE.md]
+injection:
+  claude_file: AGENTS.md
+  disabled_paths: [docs/generated]
+persona_bindings:
+  code-reviewer: strict-reviewer
+`)
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "# Strict Reviewer\n\nReview everything.\n")
+	env.CreateFile(".ddx/library/prompts/claude/system-prompts/focused.md", "Stay focused.\n")
+
+	output, err := env.RunCommand("persona", "load")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "into AGENTS.md\n")
+	assert.FileExists(t, filepath.Join(env.Dir, "AGENTS.md"))
+	assert.NoFileExists(t, filepath.Join(env.Dir, "CLAUDE.md"))
+	assert.NoDirExists(t, filepath.Join(env.Dir, "docs"))
+
+	cfg, err := config.LoadWithWorkingDir(env.Dir)
+	require.NoError(t, err)
+	require.NoError(t, syncMetaPromptWithConfig(cfg, env.Dir))
+	data, err := os.ReadFile(filepath.Join(env.Dir, "AGENTS.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Stay focused.")
+	assert.NoDirExists(t, filepath.Join(env.Dir, "docs"))
+	assert.NoError(t, checkMetaPromptSync(env.Dir))
+
+	markers := checkClaudeMarkers(env.Dir, cfg)
+	assert.Equal(t, "AGENTS.md markers", markers.Name)
+	assert.Equal(t, CheckPass, markers.Status)
+
+	_, err = env.RunCommand("prompt", "inject", "claude/system-prompts/focused.md", "--file", "docs/generated/CLAUDE.md")
+	assert.ErrorContains(t, err, "docs/generated/CLAUDE.md is under injection.disabled_paths")
+
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\ninjection:\n  disabled_paths: [\".\"]\npersona_bindings:\n  code-reviewer: strict-reviewer\n")
+	_, err = env.RunCommand("persona", "load")
+	assert.ErrorContains(t, err, "injection.disabled_paths disables every AI target (CLAUDE.md)")
+	cfg, err = config.LoadWithWorkingDir(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, CheckSkip, checkClaudeMarkers(env.Dir, cfg).Status)
+	assert.NoFileExists(t, filepath.Join(env.Dir, "CLAUDE.md"))
+}
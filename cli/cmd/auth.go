@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"github.com/easel/ddx/internal/auth"
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/github"
+	"github.com/easel/ddx/internal/userdirs"
 	"github.com/spf13/cobra"
 )
 
@@ -60,7 +68,7 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 }
 
 // runAuthStatus implements the auth status command logic
-func runAuthStatus(cmd *cobra.Command, args []string) error {
+func (f *CommandFactory) runAuthStatus(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	manager := getAuthManager()
 
@@ -69,6 +77,11 @@ func runAuthStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list credentials: %w", err)
 	}
 
+	if cfg, err := config.LoadWithWorkingDir(f.WorkingDir); err == nil && cfg.Library != nil &&
+		cfg.Library.Repository != nil && auth.IsSSHURL(cfg.Library.Repository.URL) {
+		printSSHRemoteStatus(ctx, cfg.Library.Repository.URL)
+	}
+
 	if len(credentials) == 0 {
 		fmt.Println("No stored credentials found.")
 		fmt.Println("\nTo authenticate, run:")
@@ -87,11 +100,14 @@ func runAuthStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("   Created: %s\n", cred.CreatedAt.Format(time.RFC3339))
 		fmt.Printf("   Updated: %s\n", cred.UpdatedAt.Format(time.RFC3339))
 
-		if cred.ExpiresAt != nil {
-			if time.Now().After(*cred.ExpiresAt) {
-				fmt.Printf("   Status: ❌ EXPIRED\n")
-			} else {
-				fmt.Printf("   Status: ✅ Valid (expires %s)\n", cred.ExpiresAt.Format(time.RFC3339))
+		if expiry, ok := cred.Expiry(); ok {
+			switch {
+			case !expiry.After(time.Now()):
+				fmt.Printf("   Status: ❌ EXPIRED (run 'ddx auth refresh %s')\n", cred.ID)
+			case cred.ExpiresWithin(time.Now(), auth.ExpiryWarning):
+				fmt.Printf("   Status: ⚠️  Expires soon (%s)\n", expiry.Format(time.RFC3339))
+			default:
+				fmt.Printf("   Status: ✅ Valid (expires %s)\n", expiry.Format(time.RFC3339))
 			}
 		} else {
 			// Validate current credentials
@@ -112,6 +128,39 @@ func runAuthStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printSSHRemoteStatus reports how an SSH repository URL is reached: the
+// host and key ~/.ssh/config selects for it and whether they are usable
+func printSSHRemoteStatus(ctx context.Context, repoURL string) {
+	selection, err := auth.SelectMethod(ctx, repoURL, auth.NewSSHAgent())
+	if err != nil {
+		return
+	}
+	fmt.Printf("🔗 Library repository %s\n", repoURL)
+	if host := selection.Host; host != nil {
+		target := host.HostName
+		if host.Port != "" {
+			target += ":" + host.Port
+		}
+		if host.User != "" {
+			target = host.User + "@" + target
+		}
+		if host.HostName != host.Alias || host.User != "" || host.Port != "" {
+			fmt.Printf("   Host: %s → %s (from ~/.ssh/config)\n", host.Alias, target)
+		}
+	}
+	if selection.Fallback {
+		fmt.Printf("   Status: ❌ SSH unavailable (%s)\n", selection.Reason)
+		fmt.Printf("   Using: %s with token authentication\n\n", selection.URL)
+		return
+	}
+	if selection.Identity != "" {
+		fmt.Printf("   Key: %s\n", selection.Identity)
+	} else {
+		fmt.Println("   Key: SSH agent or default key")
+	}
+	fmt.Printf("   Status: ✅ SSH\n\n")
+}
+
 // runAuthList implements the auth list command logic
 func runAuthList(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
@@ -142,6 +191,202 @@ func runAuthList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runAuthRefresh implements the auth refresh command. Without arguments it
+// refreshes the credentials that have expired or expire within
+// auth.ExpiryWarning.
+func runAuthRefresh(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+	manager := getAuthManager()
+
+	credentials, err := manager.ListCredentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	var targets []*auth.Credential
+	for _, cred := range credentials {
+		if len(args) == 0 && cred.ExpiresWithin(time.Now(), auth.ExpiryWarning) {
+			targets = append(targets, cred)
+		}
+		for _, id := range args {
+			if cred.ID == id {
+				targets = append(targets, cred)
+			}
+		}
+	}
+	for _, id := range args {
+		found := false
+		for _, cred := range targets {
+			found = found || cred.ID == id
+		}
+		if !found {
+			return NewExitError(1, fmt.Sprintf("no stored credentials for %s", id))
+		}
+	}
+	if len(targets) == 0 {
+		_, _ = fmt.Fprintln(out, "No credentials need refreshing.")
+		return nil
+	}
+
+	failed := 0
+	for _, cred := range targets {
+		refreshed, err := manager.RefreshCredential(ctx, cred.Platform, cred.ID)
+		if err != nil {
+			failed++
+			message := err.Error()
+			var authErr *auth.AuthError
+			if errors.As(err, &authErr) && authErr.Hint != "" {
+				message = fmt.Sprintf("%s (%s)", authErr.Message, authErr.Hint)
+			}
+			_, _ = fmt.Fprintf(out, "❌ %s (%s): %s\n", cred.ID, cred.Platform, message)
+			continue
+		}
+		if expiry, ok := refreshed.Expiry(); ok {
+			_, _ = fmt.Fprintf(out, "✅ Refreshed %s (%s), valid until %s\n", cred.ID, cred.Platform, expiry.Format(time.RFC3339))
+		} else {
+			_, _ = fmt.Fprintf(out, "✅ Refreshed %s (%s)\n", cred.ID, cred.Platform)
+		}
+	}
+	if failed > 0 {
+		return NewExitError(1, fmt.Sprintf("%d credential(s) could not be refreshed", failed))
+	}
+	return nil
+}
+
+// runAuthAudit implements the auth audit command
+func runAuthAudit(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	failures, _ := cmd.Flags().GetBool("failures")
+	since, _ := cmd.Flags().GetDuration("since")
+	out := cmd.OutOrStdout()
+
+	path, err := auth.DefaultAuditLogPath()
+	if err != nil {
+		return err
+	}
+	events, err := auth.NewAuditLog(path).Read(0)
+	if err != nil {
+		return err
+	}
+
+	var shown []auth.AuditEvent
+	for _, event := range events {
+		if failures && event.Outcome != auth.AuditFailure && event.Outcome != auth.AuditDenied {
+			continue
+		}
+		if since > 0 && time.Since(event.Time) > since {
+			continue
+		}
+		shown = append(shown, event)
+	}
+	if limit > 0 && len(shown) > limit {
+		shown = shown[len(shown)-limit:]
+	}
+
+	if asJSON {
+		if shown == nil {
+			shown = []auth.AuditEvent{}
+		}
+		data, err := json.MarshalIndent(shown, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode audit log: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+	if len(shown) == 0 {
+		_, _ = fmt.Fprintln(out, "No authentication events recorded")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TIME\tACTION\tPLATFORM\tREPOSITORY\tOUTCOME")
+	_, _ = fmt.Fprintln(w, "----\t------\t--------\t----------\t-------")
+	for _, e := range shown {
+		outcome := "✅"
+		switch e.Outcome {
+		case auth.AuditFailure, auth.AuditDenied:
+			outcome = fmt.Sprintf("❌ %s: %s", e.Outcome, e.Error)
+		case auth.AuditNotFound:
+			outcome = "– not found"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, e.Platform, e.Repository, outcome)
+	}
+	return w.Flush()
+}
+
+// authPassphraseEnv supplies the credential bundle passphrase without a
+// prompt
+const authPassphraseEnv = "DDX_AUTH_PASSPHRASE"
+
+// runAuthExport writes every stored credential to an encrypted bundle
+func runAuthExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+	if output == "" {
+		return NewExitError(1, "--output is required")
+	}
+	if _, err := os.Stat(output); err == nil && !force {
+		return NewExitError(1, fmt.Sprintf("%s already exists; use --force to overwrite it", output))
+	}
+
+	passphrase, err := readPassphrase(cmd, authPassphraseEnv, "bundle", true)
+	if err != nil {
+		return err
+	}
+	data, count, err := getAuthManager().ExportCredentials(cmd.Context(), passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to export credentials: %w", err)
+	}
+	if err := atomicfile.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Exported %d credential(s) to %s\n", count, output)
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Import on the other machine with: ddx auth import "+output)
+	return nil
+}
+
+// runAuthImport merges credentials from an encrypted bundle
+func runAuthImport(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	passphrase, err := readPassphrase(cmd, authPassphraseEnv, "bundle", false)
+	if err != nil {
+		return err
+	}
+	credentials, err := auth.DecryptCredentials(data, passphrase)
+	if errors.Is(err, auth.ErrBundlePassphrase) {
+		return NewExitError(1, "wrong passphrase or corrupted bundle")
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := getAuthManager().ImportCredentials(cmd.Context(), credentials)
+	if err != nil {
+		return fmt.Errorf("failed to import credentials: %w", err)
+	}
+	for _, name := range result.Added {
+		_, _ = fmt.Fprintf(out, "  + %s\n", name)
+	}
+	for _, name := range result.Updated {
+		_, _ = fmt.Fprintf(out, "  ~ %s\n", name)
+	}
+	for _, name := range result.Skipped {
+		_, _ = fmt.Fprintf(out, "  = %s (stored credential is as new or newer)\n", name)
+	}
+	_, _ = fmt.Fprintf(out, "✅ Imported %d credential(s), updated %d, skipped %d already up to date\n",
+		len(result.Added), len(result.Updated), len(result.Skipped))
+	return nil
+}
+
 // runAuthLogout implements the auth logout command logic
 func runAuthLogout(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
@@ -210,18 +455,22 @@ func initializeAuthManager() *auth.DefaultManager {
 	// manager.RegisterAuthenticator(auth.NewBitbucketAuthenticator()) // TODO: Implement
 
 	// Register storage backends
-	homeDir, _ := os.UserHomeDir()
 
 	// Try keychain first
 	keychainStore := auth.NewKeychainStore("com.ddx.auth")
 	manager.RegisterStore(keychainStore)
 
 	// File storage as fallback
-	credFile := filepath.Join(homeDir, ".ddx", "credentials.enc")
+	credFile, _ := userdirs.Path(userdirs.Data, "credentials.enc")
 	passphrase := getOrCreatePassphrase()
 	fileStore := auth.NewFileStore(credFile, passphrase)
 	manager.RegisterStore(fileStore)
 
+	// Record attempts and credential access in the audit log
+	if auditPath, err := auth.DefaultAuditLogPath(); err == nil {
+		manager.SetAuditLog(auth.NewAuditLog(auditPath))
+	}
+
 	// Register credential helpers
 	if gitHelper := auth.NewGitCredentialHelper(); gitHelper.IsAvailable() {
 		manager.RegisterCredentialHelper(gitHelper)
@@ -233,6 +482,20 @@ func initializeAuthManager() *auth.DefaultManager {
 	return manager
 }
 
+// newGitHubClient returns the GitHub API client, authenticated with the
+// token stored for github.com when there is one
+func newGitHubClient() *github.Client {
+	return github.NewClient(lazyCredentials{})
+}
+
+// lazyCredentials sets up the credential stores only once a token is
+// looked up, so commands that make no GitHub API request never touch them
+type lazyCredentials struct{}
+
+func (lazyCredentials) GetCredential(ctx context.Context, platform auth.Platform, repository string) (*auth.Credential, error) {
+	return getAuthManager().GetCredential(ctx, platform, repository)
+}
+
 // detectPlatform detects the platform from a repository URL or hostname
 func detectPlatform(repository string) auth.Platform {
 	switch {
@@ -267,3 +530,83 @@ func getOrCreatePassphrase() string {
 	// For now, use a default (not secure!)
 	return "ddx-auth-passphrase-change-me"
 }
+
+// resolveLibraryRemote chooses how to reach the library repository. SSH URLs
+// use SSH when an agent identity or key file is available; otherwise the
+// HTTPS equivalent is used with token authentication. A relative local path
+// is taken from the project directory, and a secret:// reference is
+// replaced by the stored URL.
+func resolveLibraryRemote(ctx context.Context, workingDir, repoURL string) (*auth.MethodSelection, error) {
+	resolved, err := resolveConfigSecret(ctx, repoURL)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := auth.SelectMethod(ctx, libraryURL(workingDir, resolved), auth.NewSSHAgent())
+	if err != nil {
+		// Name the URL as configured so a stored secret is not printed
+		return nil, fmt.Errorf("failed to select authentication for %s: %w", repoURL, err)
+	}
+	return selection, nil
+}
+
+// libraryURL makes a library repository given as a relative path, such as a
+// mirror or bundle kept with the project, absolute. Other URLs are returned
+// unchanged.
+func libraryURL(workingDir, repoURL string) string {
+	if git.IsLocalURL(repoURL) && !strings.HasPrefix(repoURL, "file://") && !filepath.IsAbs(repoURL) {
+		return filepath.Join(workingDir, repoURL)
+	}
+	return repoURL
+}
+
+// libraryRemoteNotice explains an SSH to HTTPS fallback, or returns an empty
+// string when the configured URL is used as-is
+func libraryRemoteNotice(ctx context.Context, repoURL string, selection *auth.MethodSelection) string {
+	if selection == nil || selection.Method == auth.AuthMethodSSH {
+		return ""
+	}
+	host := auth.RepositoryHost(selection.URL)
+	if !selection.Fallback {
+		return credentialExpiryNotice(ctx, host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SSH authentication unavailable for %s: %s\n", repoURL, selection.Reason)
+	fmt.Fprintf(&b, "Falling back to HTTPS: %s", selection.URL)
+
+	if notice := credentialExpiryNotice(ctx, host); notice != "" {
+		fmt.Fprintf(&b, "\n%s", notice)
+	} else if !hasTokenForHost(ctx, host) {
+		fmt.Fprintf(&b, "\nNo token found for %s - run 'ddx auth login %s' or configure a git credential helper", host, host)
+	}
+	return b.String()
+}
+
+// credentialExpiryNotice warns when the stored token for host has expired,
+// or expires within auth.ExpiryWarning and may run out part way through an
+// operation. Tokens about to expire have already been refreshed by then
+// where the platform allows it.
+func credentialExpiryNotice(ctx context.Context, host string) string {
+	if host == "" {
+		return ""
+	}
+	cred, err := getAuthManager().GetCredential(ctx, detectPlatform(host), host)
+	var authErr *auth.AuthError
+	if errors.As(err, &authErr) && authErr.Type == auth.ErrorTypeExpiredToken {
+		return fmt.Sprintf("%s - %s", authErr.Message, authErr.Hint)
+	}
+	if err != nil || !cred.ExpiresWithin(time.Now(), auth.ExpiryWarning) {
+		return ""
+	}
+	expiry, _ := cred.Expiry()
+	return fmt.Sprintf("Token for %s expires in %s - run 'ddx auth refresh %s' to avoid failing part way", host, time.Until(expiry).Round(time.Minute), host)
+}
+
+// hasTokenForHost reports whether a token is available for HTTPS access
+func hasTokenForHost(ctx context.Context, host string) bool {
+	if os.Getenv("GITHUB_TOKEN") != "" && detectPlatform(host) == auth.PlatformGitHub {
+		return true
+	}
+	_, err := getAuthManager().GetCredential(ctx, detectPlatform(host), host)
+	return err == nil
+}
@@ -648,28 +648,52 @@ func TestSecurity_US016_ThreatMitigation(t *testing.T) {
 
 // TestSecurity_US016_AuditLogging tests audit and monitoring requirements
 func TestSecurity_US016_AuditLogging(t *testing.T) {
-	// This test will fail until audit logging is implemented
-	t.Skip("Audit logging not yet implemented - failing tests for TDD Red phase")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	auditPath, err := auth.DefaultAuditLogPath()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	manager := auth.NewDefaultManager()
+	manager.RegisterAuthenticator(auth.NewGitHubAuthenticator())
+	manager.RegisterStore(auth.NewFileStore(filepath.Join(home, ".ddx", "credentials.enc"), "test-pass"))
+	manager.SetAuditLog(auth.NewAuditLog(auditPath))
 
 	t.Run("authentication_attempt_logging", func(t *testing.T) {
-		// Test: All auth attempts logged
-		// Test: Failed attempts tracked
-		// Test: Security events recorded
-		t.Error("FAIL: Authentication attempt logging not implemented")
+		// Token authentication needs interactive mode, so this attempt fails
+		_, err := manager.Authenticate(ctx, &auth.AuthRequest{Platform: auth.PlatformGitHub, Repository: "github.com", Method: auth.AuthMethodToken})
+		require.NoError(t, err)
+
+		output, err := executeCommand(NewCommandFactory(t.TempDir()).NewRootCommand(), "auth", "audit", "--failures")
+		require.NoError(t, err)
+		assert.Contains(t, output, "authenticate")
+		assert.Contains(t, output, "github.com")
+		assert.Contains(t, output, "❌ failure")
 	})
 
 	t.Run("rate_limiting", func(t *testing.T) {
-		// Test: Failed attempt rate limiting
-		// Test: Brute force protection
-		// Test: Account lockout mechanisms
-		t.Error("FAIL: Rate limiting not implemented")
+		req := &auth.AuthRequest{Platform: auth.PlatformGitHub, Repository: "ghe.example.com"}
+		var err error
+		for i := 0; i <= auth.MaxFailedAttempts; i++ {
+			_, err = manager.Authenticate(ctx, req)
+		}
+		var authErr *auth.AuthError
+		require.ErrorAs(t, err, &authErr)
+		assert.Equal(t, auth.ErrorTypeRateLimited, authErr.Type)
 	})
 
 	t.Run("credential_usage_audit", func(t *testing.T) {
-		// Test: Credential access logged
-		// Test: Usage patterns monitored
-		// Test: Anomaly detection
-		t.Error("FAIL: Credential usage audit not implemented")
+		require.NoError(t, manager.StoreCredential(ctx, &auth.Credential{
+			ID: "github.com", Platform: auth.PlatformGitHub, Method: auth.AuthMethodToken, Token: "ghp_audit_secret",
+		}))
+		_, err := manager.GetCredential(ctx, auth.PlatformGitHub, "github.com")
+		require.NoError(t, err)
+
+		output, err := executeCommand(NewCommandFactory(t.TempDir()).NewRootCommand(), "auth", "audit", "--json")
+		require.NoError(t, err)
+		assert.Contains(t, output, `"action": "credential_store"`)
+		assert.Contains(t, output, `"action": "credential_access"`)
+		assert.NotContains(t, output, "ghp_audit_secret")
 	})
 }
 
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/backup"
+	"github.com/easel/ddx/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// backupRetention returns the configured backup retention policy
+func backupRetention(cfg *config.Config) (backup.Retention, error) {
+	retention := backup.Retention{Keep: backup.DefaultKeep}
+	if cfg == nil || cfg.Backups == nil {
+		return retention, nil
+	}
+	if cfg.Backups.Keep != nil {
+		retention.Keep = *cfg.Backups.Keep
+	}
+	if cfg.Backups.MaxAge != "" {
+		maxAge, err := time.ParseDuration(cfg.Backups.MaxAge)
+		if err != nil {
+			return retention, fmt.Errorf("invalid backups.max_age '%s': use a duration such as 720h", cfg.Backups.MaxAge)
+		}
+		retention.MaxAge = maxAge
+	}
+	return retention, nil
+}
+
+// backupAIFiles copies AI configuration files into .ddx/backups before an
+// operation rewrites them
+func backupAIFiles(workingDir, operation string, files []string) error {
+	cfg, _ := config.LoadWithWorkingDir(workingDir)
+	retention, err := backupRetention(cfg)
+	if err != nil {
+		return err
+	}
+	b, err := backup.Create(workingDir, operation, files, retention)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		slog.Debug("backed up AI configuration", "id", b.ID, "operation", operation, "files", b.Paths())
+	}
+	return nil
+}
+
+// runBackupList lists the backups of AI configuration files, newest first
+func (f *CommandFactory) runBackupList(cmd *cobra.Command, args []string) error {
+	backups, err := backup.List(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(backups) == 0 {
+		_, _ = fmt.Fprintln(out, "No backups yet; one is made before ddx rewrites CLAUDE.md or another AI configuration file")
+		return nil
+	}
+	_, _ = fmt.Fprintln(out, "Backups (newest first):")
+	for _, b := range backups {
+		_, _ = fmt.Fprintf(out, "  %s  %s  before %-16s %s\n",
+			b.ID, b.Created.Local().Format("2006-01-02 15:04"), b.Operation, strings.Join(b.Paths(), ", "))
+	}
+	return nil
+}
+
+// runBackupRestore puts the files of a backup back in place, first backing
+// up their current content so the restore can be undone
+func (f *CommandFactory) runBackupRestore(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	b, err := backup.Load(f.WorkingDir, args[0])
+	if err != nil {
+		return err
+	}
+	if err := backupAIFiles(f.WorkingDir, "backup restore", b.Paths()); err != nil {
+		return err
+	}
+	if err := b.Restore(f.WorkingDir); err != nil {
+		return fmt.Errorf("restoring backup %s failed: %w", b.ID, err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "⏪ Restored %s from backup %s (before %s)\n", strings.Join(b.Paths(), ", "), b.ID, b.Operation)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupCommand(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+backups:
+  keep: 5
+`)
+	env.Library().Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Body: "# Strict Reviewer\n"})
+	env.CreateFile("CLAUDE.md", "# My project\n\nHand-written notes.\n")
+
+	output, err := env.RunCommand("backup", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "No backups yet")
+
+	_, err = env.RunCommand("persona", "load", "strict-reviewer")
+	require.NoError(t, err)
+	claude, err := os.ReadFile(filepath.Join(env.Dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(claude), "Strict Reviewer")
+
+	output, err = env.RunCommand("backup", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "before persona load")
+	assert.Contains(t, output, "CLAUDE.md")
+	id := regexp.MustCompile(`\d{8}-\d{6}(-\d+)?`).FindString(output)
+	require.NotEmpty(t, id)
+
+	output, err = env.RunCommand("backup", "restore", id)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Restored CLAUDE.md from backup "+id)
+	claude, err = os.ReadFile(filepath.Join(env.Dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# My project\n\nHand-written notes.\n", string(claude))
+
+	// The restore itself was backed up first
+	output, err = env.RunCommand("backup", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "before backup restore")
+
+	_, err = env.RunCommand("backup", "restore", "missing")
+	assert.ErrorContains(t, err, "backup missing not found")
+}
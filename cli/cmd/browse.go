@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/mcp"
+	"github.com/easel/ddx/internal/templates"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Views of the browser, from the outermost
+const (
+	browseCategories = iota
+	browseItems
+	browsePreview
+)
+
+// browseItem is an asset listed by the browser
+type browseItem struct {
+	Name        string
+	Description string
+	// Path is the file shown as the preview
+	Path string
+}
+
+// browseCategory is a type of library asset and the action offered on it
+type browseCategory struct {
+	Name string
+	// Action describes what the action key does, e.g. "load persona"
+	Action string
+	Items  []browseItem
+	// run performs the action on an item and returns a status message
+	run func(item browseItem) (string, error)
+}
+
+// browseModel is the state of the browser. Keys update it and render
+// draws it, so it can be driven without a terminal.
+type browseModel struct {
+	categories []browseCategory
+	view       int
+	category   int
+	item       int
+	// scroll is the first preview line shown
+	scroll  int
+	preview []string
+	// confirm is set while the action waits for y/n
+	confirm bool
+	status  string
+}
+
+// update applies a key and reports whether the browser should quit
+func (m *browseModel) update(key string) bool {
+	if m.confirm {
+		m.confirm = false
+		if key != "y" {
+			m.status = "Cancelled"
+			return false
+		}
+		category := m.categories[m.category]
+		message, err := category.run(category.Items[m.item])
+		if err != nil {
+			m.status = "❌ " + strings.SplitN(err.Error(), "\n", 2)[0]
+		} else {
+			m.status = "✅ " + message
+		}
+		return false
+	}
+
+	m.status = ""
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-10)
+	case "pgdown":
+		m.move(10)
+	case "enter", "right", "l":
+		m.open()
+	case "esc", "left", "h", "backspace":
+		if m.view > browseCategories {
+			m.view--
+		}
+	case "a":
+		if m.view == browseCategories {
+			return false
+		}
+		category := m.categories[m.category]
+		if category.run == nil {
+			return false
+		}
+		m.confirm = true
+		m.status = fmt.Sprintf("%s '%s'? (y/n)", strings.ToUpper(category.Action[:1])+category.Action[1:], category.Items[m.item].Name)
+	}
+	return false
+}
+
+// move shifts the cursor of the current view, or scrolls the preview
+func (m *browseModel) move(delta int) {
+	clamp := func(value, limit int) int {
+		if value >= limit {
+			value = limit - 1
+		}
+		if value < 0 {
+			value = 0
+		}
+		return value
+	}
+	switch m.view {
+	case browseCategories:
+		m.category = clamp(m.category+delta, len(m.categories))
+	case browseItems:
+		m.item = clamp(m.item+delta, len(m.categories[m.category].Items))
+	case browsePreview:
+		m.scroll = clamp(m.scroll+delta, len(m.preview))
+	}
+}
+
+// open enters the selected category or previews the selected item
+func (m *browseModel) open() {
+	switch m.view {
+	case browseCategories:
+		if len(m.categories) == 0 {
+			return
+		}
+		category := m.categories[m.category]
+		if len(category.Items) == 0 {
+			m.status = fmt.Sprintf("No %s in the library", strings.ToLower(category.Name))
+			return
+		}
+		m.view, m.item = browseItems, 0
+	case browseItems:
+		item := m.categories[m.category].Items[m.item]
+		content, err := os.ReadFile(item.Path)
+		if err != nil {
+			m.status = fmt.Sprintf("❌ Cannot preview %s: %v", item.Name, err)
+			return
+		}
+		text := strings.ReplaceAll(strings.TrimRight(string(content), "\n"), "\t", "    ")
+		m.preview, m.scroll, m.view = strings.Split(text, "\n"), 0, browsePreview
+	}
+}
+
+// render draws the current view as lines fitting width and height
+func (m *browseModel) render(width, height int) []string {
+	title := "DDx library"
+	if m.view >= browseItems {
+		title += " › " + m.categories[m.category].Name
+	}
+	if m.view == browsePreview {
+		title += " › " + m.categories[m.category].Items[m.item].Name
+	}
+	lines := []string{title, ""}
+	body := height - 4
+	if body < 1 {
+		body = 1
+	}
+
+	switch m.view {
+	case browseCategories:
+		for i, category := range m.categories {
+			lines = append(lines, browseCursor(i == m.category)+fmt.Sprintf("%s (%d)", category.Name, len(category.Items)))
+		}
+	case browseItems:
+		items := m.categories[m.category].Items
+		nameWidth := 0
+		for _, item := range items {
+			nameWidth = max(nameWidth, utf8.RuneCountInString(item.Name))
+		}
+		start := 0
+		if m.item >= body {
+			start = m.item - body + 1
+		}
+		for i := start; i < len(items) && i < start+body; i++ {
+			line := browseCursor(i == m.item) + items[i].Name
+			if items[i].Description != "" {
+				line += strings.Repeat(" ", nameWidth-utf8.RuneCountInString(items[i].Name)+2) + items[i].Description
+			}
+			lines = append(lines, line)
+		}
+	case browsePreview:
+		end := min(m.scroll+body, len(m.preview))
+		lines = append(lines, m.preview[m.scroll:end]...)
+	}
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	footer := m.status
+	if footer == "" {
+		footer = m.help()
+	}
+	lines = append(lines[:max(height-1, 2)], footer)
+	for i, line := range lines {
+		lines[i] = truncateLine(line, width)
+	}
+	return lines
+}
+
+// help lists the keys of the current view
+func (m *browseModel) help() string {
+	switch m.view {
+	case browseItems, browsePreview:
+		move := "↑/↓ move  enter preview"
+		if m.view == browsePreview {
+			move = "↑/↓ scroll"
+		}
+		action := ""
+		if m.categories[m.category].run != nil {
+			action = "  a " + m.categories[m.category].Action
+		}
+		return move + action + "  ← back  q quit"
+	default:
+		return "↑/↓ move  enter open  q quit"
+	}
+}
+
+// browseCursor marks the selected line
+func browseCursor(selected bool) string {
+	if selected {
+		return "▸ "
+	}
+	return "  "
+}
+
+// truncateLine shortens a line to width runes
+func truncateLine(line string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:width-1]) + "…"
+}
+
+// browseCatalog lists the project library's assets with the action offered
+// on each type: personas are loaded, prompts copied to the clipboard,
+// templates applied, workflows activated and MCP servers installed
+func browseCatalog(ctx context.Context, workingDir string) ([]browseCategory, error) {
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	libPath := projectLibraryDir(workingDir, cfg)
+	if _, err := os.Stat(libPath); err != nil {
+		return nil, fmt.Errorf("library not found at %s; run 'ddx init' or 'ddx update'", libPath)
+	}
+
+	personas := browseCategory{Name: "Personas", Action: "load persona", run: func(item browseItem) (string, error) {
+		_, files, err := personaLoad(workingDir, item.Name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Loaded persona '%s' into %s", item.Name, strings.Join(files, ", ")), nil
+	}}
+	if list, err := personaList(workingDir, "", ""); err == nil {
+		for _, p := range list {
+			personas.Items = append(personas.Items, browseItem{Name: p.Name, Description: p.Description, Path: p.FilePath})
+		}
+	}
+
+	prompts := browseCategory{Name: "Prompts", Action: "copy to clipboard", run: func(item browseItem) (string, error) {
+		content, err := os.ReadFile(item.Path)
+		if err != nil {
+			return "", err
+		}
+		if err := copyToClipboard(string(content)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Copied prompt '%s' to the clipboard", item.Name), nil
+	}}
+	promptsDir := filepath.Join(libPath, "prompts")
+	_ = filepath.WalkDir(promptsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != promptsDir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+		rel, _ := filepath.Rel(promptsDir, path)
+		prompts.Items = append(prompts.Items, browseItem{Name: strings.TrimSuffix(filepath.ToSlash(rel), ".md"), Description: promptTitle(path), Path: path})
+		return nil
+	})
+
+	templateItems := browseCategory{Name: "Templates", Action: "apply template", run: func(item browseItem) (string, error) {
+		var out bytes.Buffer
+		var result *InitSetupResult
+		err := transaction.Run(workingDir, "applying template '"+item.Name+"'", func(tx *transaction.Tx) (err error) {
+			result, err = applyInitSetup(ctx, tx, workingDir, cfg, InitSetup{Template: item.Name}, &out)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Applied template '%s': %d file(s) created, %d skipped", item.Name, len(result.Created), len(result.Skipped)), nil
+	}}
+	for _, choice := range libraryTemplates(libPath) {
+		dir := filepath.Join(libPath, "templates", choice.Name)
+		templateItems.Items = append(templateItems.Items, browseItem{Name: choice.Name, Description: choice.Description, Path: browsePreviewFile(dir, templates.ManifestFile)})
+	}
+
+	workflows := browseCategory{Name: "Workflows", Action: "activate workflow", run: func(item browseItem) (string, error) {
+		err := transaction.Run(workingDir, "activating workflow '"+item.Name+"'", func(tx *transaction.Tx) error {
+			_, err := applyInitSetup(ctx, tx, workingDir, cfg, InitSetup{Workflow: item.Name}, &bytes.Buffer{})
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Activated workflow '%s'", item.Name), nil
+	}}
+	for _, choice := range libraryWorkflows(libPath) {
+		dir := filepath.Join(libPath, "workflows", choice.Name)
+		workflows.Items = append(workflows.Items, browseItem{Name: choice.Name, Description: choice.Description, Path: browsePreviewFile(dir, "workflow.yml")})
+	}
+
+	servers := browseCategory{Name: "MCP servers", Action: "install server", run: func(item browseItem) (string, error) {
+		if err := mcpInstall(&bytes.Buffer{}, workingDir, MCPInstallOptions{ServerName: item.Name, Yes: true}); err != nil {
+			return "", fmt.Errorf("%v; run 'ddx mcp install %s' to provide its settings", err, item.Name)
+		}
+		return fmt.Sprintf("Installed MCP server '%s'", item.Name), nil
+	}}
+	if registry, err := mcp.LoadRegistryWithLibraryPath("", workingDir, cfg.Library.Path); err == nil {
+		if refs, err := registry.ListServers(mcp.ListOptions{}); err == nil {
+			for _, ref := range refs {
+				servers.Items = append(servers.Items, browseItem{Name: ref.Name, Description: ref.Description, Path: filepath.Join(libPath, "mcp-servers", ref.File)})
+			}
+		}
+	}
+
+	return []browseCategory{personas, prompts, templateItems, workflows, servers}, nil
+}
+
+// browsePreviewFile picks an asset directory's README, falling back to
+// its definition file
+func browsePreviewFile(dir, definition string) string {
+	readme := filepath.Join(dir, "README.md")
+	if _, err := os.Stat(readme); err == nil {
+		return readme
+	}
+	return filepath.Join(dir, definition)
+}
+
+// promptTitle returns the first Markdown heading of a prompt
+func promptTitle(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
+
+// readBrowseKey reads one key press from a terminal in raw mode
+func readBrowseKey(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 8, 127:
+		return "backspace", nil
+	case 27:
+		// A lone escape is the key itself; arrows arrive as a sequence
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		if next, _ := r.ReadByte(); next != '[' && next != 'O' {
+			return "esc", nil
+		}
+		code, _ := r.ReadByte()
+		switch code {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		case '5', '6':
+			_, _ = r.ReadByte() // the trailing ~
+			if code == '5' {
+				return "pgup", nil
+			}
+			return "pgdown", nil
+		}
+		return "", nil
+	}
+	return string(rune(b)), nil
+}
+
+// runBrowse implements the browse command
+func (f *CommandFactory) runBrowse(cmd *cobra.Command, args []string) error {
+	in, ok := cmd.InOrStdin().(*os.File)
+	out, outOK := cmd.OutOrStdout().(*os.File)
+	if !ok || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return fmt.Errorf("ddx browse needs an interactive terminal; use 'ddx list' instead")
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	categories, err := browseCatalog(ctx, f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(int(in.Fd()), state) }()
+	// Draw on the alternate screen with the cursor hidden
+	_, _ = fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = fmt.Fprint(out, "\x1b[?25h\x1b[?1049l") }()
+
+	model := &browseModel{categories: categories}
+	reader := bufio.NewReader(in)
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		_, _ = fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(model.render(width, height), "\r\n"))
+		key, err := readBrowseKey(reader)
+		if err != nil {
+			return err
+		}
+		if model.update(key) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBrowse tests navigating the library browser and acting on assets
+func TestBrowse(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.Library().
+		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Description: "Thorough reviews", Body: "# Strict Reviewer\n\nYou review code.\n"}).
+		Prompt("common/review.md", "# Code review\n\nReview the diff.\n")
+
+	categories, err := browseCatalog(context.Background(), env.Dir)
+	require.NoError(t, err)
+	m := &browseModel{categories: categories}
+
+	screen := strings.Join(m.render(80, 12), "\n")
+	assert.Contains(t, screen, "▸ Personas (1)")
+	assert.Contains(t, screen, "  Prompts (1)")
+	assert.Contains(t, screen, "enter open")
+
+	// Prompts: preview, then copy to the clipboard
+	m.update("down")
+	m.update("enter")
+	screen = strings.Join(m.render(80, 12), "\n")
+	assert.Contains(t, screen, "DDx library › Prompts")
+	assert.Contains(t, screen, "▸ common/review  Code review")
+	m.update("enter")
+	assert.Contains(t, strings.Join(m.render(80, 12), "\n"), "Review the diff.")
+
+	var copied string
+	saved := copyToClipboard
+	copyToClipboard = func(text string) error { copied = text; return nil }
+	defer func() { copyToClipboard = saved }()
+	m.update("a")
+	assert.Equal(t, "Copy to clipboard 'common/review'? (y/n)", m.status)
+	m.update("y")
+	assert.Equal(t, "✅ Copied prompt 'common/review' to the clipboard", m.status)
+	assert.Contains(t, copied, "Review the diff.")
+
+	// Back to the categories and into personas; anything but y cancels
+	m.update("esc")
+	m.update("left")
+	m.update("up")
+	m.update("enter")
+	m.update("a")
+	m.update("n")
+	assert.Equal(t, "Cancelled", m.status)
+	assert.NoFileExists(t, filepath.Join(env.Dir, "CLAUDE.md"))
+	m.update("a")
+	m.update("y")
+	assert.Equal(t, "✅ Loaded persona 'strict-reviewer' into CLAUDE.md", m.status)
+	content, err := os.ReadFile(filepath.Join(env.Dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "You review code.")
+
+	// Empty categories are not entered
+	m.update("esc")
+	m.update("down")
+	m.update("down")
+	m.update("enter")
+	assert.Equal(t, "No templates in the library", m.status)
+	assert.True(t, m.update("q"))
+}
+
+// TestReadBrowseKey tests decoding key presses
+func TestReadBrowseKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b[A\x1b[B\x1b[5~\rq\x7f"))
+	var keys []string
+	for range 6 {
+		key, err := readBrowseKey(r)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"up", "down", "pgup", "enter", "q", "backspace"}, keys)
+}
+
+// TestBrowseRequiresTerminal tests that browse refuses to run without a terminal
+func TestBrowseRequiresTerminal(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	_, err := env.RunCommand("browse")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs an interactive terminal")
+}
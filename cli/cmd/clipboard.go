@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands are the copy tools tried in order on each platform
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+// copyToClipboard places text on the system clipboard using the first
+// copy tool found on PATH. It is a variable so tests can capture the text.
+var copyToClipboard = func(text string) error {
+	candidates := clipboardCommands[runtime.GOOS]
+	if candidates == nil {
+		candidates = clipboardCommands["linux"]
+	}
+
+	var tried []string
+	for _, candidate := range candidates {
+		tried = append(tried, candidate[0])
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+		copier := exec.Command(candidate[0], candidate[1:]...)
+		copier.Stdin = strings.NewReader(text)
+		if output, err := copier.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %v %s", candidate[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(tried, ", "))
+}
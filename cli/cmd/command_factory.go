@@ -3,13 +3,21 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/logging"
+	"github.com/easel/ddx/internal/network"
 	"github.com/easel/ddx/internal/update"
 	"github.com/fatih/color"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -28,6 +36,48 @@ type CommandFactory struct {
 
 	// Update checker instance (stores check result for PostRunE)
 	updateChecker *update.Checker
+	// updateDone is closed when the background update check finishes
+	updateDone chan struct{}
+
+	// LogOutput receives diagnostics; nil means os.Stderr
+	LogOutput io.Writer
+
+	// FS is the filesystem commands read and write through; nil means the
+	// operating system's. So far only 'ddx log' uses it; other commands
+	// still use the os package directly and cannot run in memory.
+	FS afero.Fs
+	// Clock tells commands the time; nil means the system clock. It is
+	// used by 'ddx log' and 'ddx quickstart'.
+	Clock Clock
+
+	// timings records the phases of the invocation for --debug-timings
+	timings *phaseTimings
+}
+
+// Clock tells commands the time, so tests can fix it
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the clock of the running system
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// fs returns the filesystem commands work on
+func (f *CommandFactory) fs() afero.Fs {
+	if f.FS == nil {
+		return afero.NewOsFs()
+	}
+	return f.FS
+}
+
+// now returns the current time by the factory's clock
+func (f *CommandFactory) now() time.Time {
+	if f.Clock == nil {
+		return time.Now()
+	}
+	return f.Clock.Now()
 }
 
 // NewCommandFactory creates a new command factory with default settings
@@ -38,6 +88,8 @@ func NewCommandFactory(workingDir string) *CommandFactory {
 		Date:          Date,
 		WorkingDir:    workingDir,
 		viperInstance: viper.New(),
+		FS:            afero.NewOsFs(),
+		Clock:         systemClock{},
 	}
 }
 
@@ -49,21 +101,15 @@ func NewCommandFactoryWithViper(workingDir string, v *viper.Viper) *CommandFacto
 		Date:          Date,
 		WorkingDir:    workingDir,
 		viperInstance: v,
+		FS:            afero.NewOsFs(),
+		Clock:         systemClock{},
 	}
 }
 
-// NewRootCommand creates a fresh root command with all subcommands
-func (f *CommandFactory) NewRootCommand() *cobra.Command {
-	// Local flag variables scoped to this command instance
-	var cfgFile string
-	var verbose bool
-	var libraryPath string
-
-	// Create fresh root command
-	rootCmd := &cobra.Command{
-		Use:   "ddx",
-		Short: "Document-Driven Development eXperience - AI development toolkit",
-		Long: color.New(color.FgCyan).Sprint(banner) + `
+// rootLong returns the root command's description below the banner,
+// colored unless color is off
+func rootLong() string {
+	return color.New(color.FgCyan).Sprint(banner) + `
 DDx is a toolkit for AI-assisted development that helps you:
 
 • Share templates, prompts, and patterns across projects
@@ -78,26 +124,115 @@ Get started:
 
 More information:
   Documentation: https://github.com/easel/ddx
-  Issues & Support: https://github.com/easel/ddx/issues`,
+  Issues & Support: https://github.com/easel/ddx/issues`
+}
+
+// NewRootCommand creates a fresh root command with all subcommands
+func (f *CommandFactory) NewRootCommand() *cobra.Command {
+	return f.newRootCommand(nil)
+}
+
+// NewRootCommandFor creates a root command for running args, building
+// only the subcommand they name when that is enough
+func (f *CommandFactory) NewRootCommandFor(args []string) *cobra.Command {
+	if args == nil {
+		args = []string{}
+	}
+	return f.newRootCommand(args)
+}
+
+// newRootCommand creates the root command, with the subcommands args need
+// or, for nil args, all of them
+func (f *CommandFactory) newRootCommand(args []string) *cobra.Command {
+	f.timings = newPhaseTimings()
+	configureColor(nil)
+
+	// Local flag variables scoped to this command instance
+	var cfgFile string
+	var verbose bool
+	var libraryPath string
+	var profile string
+
+	// Create fresh root command
+	rootCmd := &cobra.Command{
+		Use:   "ddx",
+		Short: "Document-Driven Development eXperience - AI development toolkit",
+		Long:  rootLong(),
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			if verbose {
-				fmt.Printf("DDx %s (commit: %s, built: %s)\n", f.Version, f.Commit, f.Date)
-			}
+			slog.Info("DDx "+f.Version, "commit", f.Commit, "built", f.Date)
 		},
 	}
 
 	// Setup flags - these are now local to this command instance
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ddx.yml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().Bool("debug", false, "debug output, including the details --verbose shows")
 	rootCmd.PersistentFlags().StringVar(&libraryPath, "library-base-path", "", "override path for DDx library location")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "configuration profile to activate (overrides DDX_ENV)")
+	rootCmd.PersistentFlags().String("project", "", "project to work on: a directory or a project of the workspace (default: nearest project)")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "leave out progress, hints and success messages; data and errors are still written (also DDX_QUIET)")
+	rootCmd.PersistentFlags().Bool("no-color", false, "disable colored output (also NO_COLOR or DDX_NO_COLOR)")
+	rootCmd.PersistentFlags().Bool("debug-timings", false, "print how long each phase of the command took to stderr")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "give up on a remote that does not respond for this long, e.g. 10s (overrides network.timeout)")
+
+	// Help is shown without running the hooks below
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if noColor(cmd) {
+			configureColor(cmd)
+			rootCmd.Long = rootLong()
+		}
+		defaultHelp(cmd, args)
+	})
 
 	// Store flag values in command context for access by subcommands
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		f.timings.mark("parse arguments")
+
+		// Diagnostics go to stderr at the level the flags select.
+		// Subcommands with their own --verbose flag shadow the root's.
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		debug, _ := cmd.Flags().GetBool("debug")
+		logOutput := f.LogOutput
+		if logOutput == nil {
+			logOutput = os.Stderr
+		}
+		logging.Setup(logOutput, logging.Level(verbose, debug), os.Getenv(logging.FormatEnvVar))
+		configureColor(cmd)
+		f.timings.mark("set up output")
+
+		// Work on the selected project, or the nearest one above the
+		// working directory
+		if err := f.selectProject(cmd); err != nil {
+			return err
+		}
+		f.timings.mark("select project")
+
+		// Point new users at the guided setup, once
+		f.welcomeFirstRun(cmd)
+
+		// A profile chosen with --profile must exist, unlike a stale DDX_ENV
+		if profile != "" {
+			if err := config.CheckProfile(f.WorkingDir, profile); err != nil {
+				return err
+			}
+		}
+
 		// Initialize config with the local viper instance
-		f.initConfig(cfgFile, libraryPath)
+		f.initConfig(cfgFile, libraryPath, profile)
+		f.timings.mark("read settings")
+
+		// Route every download through the configured proxy and CAs. The
+		// configuration is loaded once for both this and the update check.
+		cfg, cfgErr := config.LoadWithWorkingDir(f.WorkingDir)
+		f.timings.mark("load config")
+		if err := f.configureNetwork(cmd, cfg, cfgErr); err != nil {
+			return err
+		}
 
-		// Check for updates (synchronous, once per 24h)
-		f.checkForUpdates(cmd)
+		// Check for updates in the background, at most once per 'updates.check'
+		f.checkForUpdates(cmd, cfg, cfgErr)
+		f.timings.mark("start update check")
 
 		// Call the original PersistentPreRun if it exists
 		if rootCmd.PersistentPreRun != nil {
@@ -108,22 +243,61 @@ More information:
 
 	// Display update notification after command completes
 	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
-		return f.displayUpdateNotification(cmd)
+		err := f.displayUpdateNotification(cmd)
+		f.timings.mark("update notice")
+		return err
 	}
 
-	// Add all subcommands
-	f.registerSubcommands(rootCmd)
+	// Add the subcommands
+	f.registerSubcommands(rootCmd, args)
+
+	// Record invocations in the history log and telemetry when enabled
+	f.instrumentCommands(rootCmd)
+	f.timings.mark("build commands")
 
 	return rootCmd
 }
 
+// instrumentCommands wraps the commands under root so each finished
+// invocation, successful or not, is appended to the project's history and
+// counted by telemetry when they are enabled
+func (f *CommandFactory) instrumentCommands(root *cobra.Command) {
+	for _, sub := range root.Commands() {
+		f.instrumentCommands(sub)
+	}
+	if root.Run == nil && root.RunE == nil {
+		return
+	}
+	run, runE := root.Run, root.RunE
+	root.Run = nil
+	root.RunE = func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		var err error
+		if runE != nil {
+			err = runE(cmd, args)
+		} else {
+			run(cmd, args)
+		}
+		f.timings.mark("run " + cmd.Name())
+		f.appendHistory(cmd, args, start, err)
+		f.recordTelemetry(cmd, err)
+		f.timings.mark("record history")
+		return err
+	}
+}
+
 // initConfig initializes configuration for this command instance
-func (f *CommandFactory) initConfig(cfgFile, libPath string) {
+func (f *CommandFactory) initConfig(cfgFile, libPath, profile string) {
 	// Store library path override if provided
 	if libPath != "" {
 		_ = os.Setenv("DDX_LIBRARY_BASE_PATH", libPath)
 	}
 
+	// Store profile selection so config loading resolves it
+	if profile != "" {
+		_ = os.Setenv(config.ProfileEnvVar, profile)
+	}
+
 	if cfgFile != "" {
 		// Use config file from the flag
 		f.viperInstance.SetConfigFile(cfgFile)
@@ -143,48 +317,85 @@ func (f *CommandFactory) initConfig(cfgFile, libPath string) {
 
 	// If a config file is found, read it in
 	if err := f.viperInstance.ReadInConfig(); err == nil {
-		if verbose := f.viperInstance.GetBool("verbose"); verbose {
-			_, _ = fmt.Fprintln(os.Stderr, "Using config file:", f.viperInstance.ConfigFileUsed())
+		slog.Debug("using config file", "path", f.viperInstance.ConfigFileUsed())
+	}
+}
+
+// configureNetwork applies the network section of the configuration and
+// the --timeout flag to every HTTP client and to git. Configuration errors
+// are left for the command to report; only an invalid flag is returned.
+func (f *CommandFactory) configureNetwork(cmd *cobra.Command, cfg *config.Config, cfgErr error) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout < 0 {
+		return NewExitError(ExitCodeMissingArg, fmt.Sprintf("invalid --timeout %s: must be positive", timeout))
+	}
+
+	settings, _ := network.FromConfig(nil)
+	if cfgErr == nil {
+		configured, err := network.FromConfig(cfg)
+		if err != nil {
+			slog.Warn("ignoring network configuration", "error", err)
+		} else {
+			settings = configured
 		}
 	}
+	if timeout > 0 {
+		settings.Timeout = timeout
+		settings.TimeoutSet = true
+	}
+	if err := network.Configure(settings); err != nil {
+		slog.Warn("ignoring network configuration", "error", err)
+	}
+	return nil
 }
 
-// checkForUpdates performs automatic update check (synchronous, once per 24h)
-func (f *CommandFactory) checkForUpdates(cmd *cobra.Command) {
-	// Check if disabled via env var
+// checkForUpdates starts the update check in the background. The network
+// is only used when the cached result is older than 'updates.check'
+// allows; otherwise the cached result is read straight away.
+func (f *CommandFactory) checkForUpdates(cmd *cobra.Command, cfg *config.Config, err error) {
+	// Check if disabled via env var or for this invocation
 	if os.Getenv("DDX_DISABLE_UPDATE_CHECK") == "1" {
 		return
 	}
+	if noCheck, _ := cmd.Flags().GetBool("no-check"); noCheck {
+		return
+	}
+	if network.Current().Offline {
+		return
+	}
 
-	// Load config
-	cfg, err := config.Load()
 	if err != nil {
 		// Silent failure - use defaults
 		cfg = config.DefaultNewConfig()
 	}
 
 	// Check if disabled via config
-	if cfg.UpdateCheck != nil && !cfg.UpdateCheck.Enabled {
+	if cfg.UpdateCheckInterval() == 0 {
 		return
 	}
 
-	// Create checker and perform check (synchronous)
 	checker := update.NewChecker(f.Version, cfg)
-	ctx := context.Background()
-
-	// Fast when cache valid (just reads file)
-	// Slow once per 24h when cache expired (network call ~200-500ms)
-	_, err = checker.CheckForUpdate(ctx)
-
-	// Log errors to stderr (don't let users get stranded on old versions)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Warning: Could not check for updates: %v\n", err)
-	}
-
-	// Store in factory for PostRunE
+	checker.GitHub = newGitHubClient()
 	f.updateChecker = checker
+	done := make(chan struct{})
+	f.updateDone = done
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		if _, err := checker.CheckForUpdate(ctx); err != nil {
+			slog.Debug("could not check for updates", "error", err)
+		}
+	}()
 }
 
+// updateCheckTimeout bounds the request for the latest release, and
+// updateCheckWait how long a finished command waits for it
+const (
+	updateCheckTimeout = 5 * time.Second
+	updateCheckWait    = time.Second
+)
+
 // displayUpdateNotification shows update notification if available
 func (f *CommandFactory) displayUpdateNotification(cmd *cobra.Command) error {
 	if f.updateChecker == nil {
@@ -201,16 +412,20 @@ func (f *CommandFactory) displayUpdateNotification(cmd *cobra.Command) error {
 	if jsonFlag {
 		return nil
 	}
+	if formatFlag, _ := cmd.Flags().GetString("format"); formatFlag != "" && formatFlag != "table" {
+		return nil
+	}
 
-	// Skip update notification if silent flag is set
-	silentFlag, _ := cmd.Flags().GetBool("silent")
-	if silentFlag {
+	// Skip update notification with --quiet or --silent
+	if quiet(cmd) {
 		return nil
 	}
 
-	// Skip update notification if --no-check flag is set (for version command)
-	noCheck, _ := cmd.Flags().GetBool("no-check")
-	if noCheck {
+	// Give a check that is still talking to the network a moment to
+	// finish; a slow network is not worth holding up the prompt
+	select {
+	case <-f.updateDone:
+	case <-time.After(updateCheckWait):
 		return nil
 	}
 
@@ -226,17 +441,127 @@ func (f *CommandFactory) displayUpdateNotification(cmd *cobra.Command) error {
 			"\n⬆️  Update available: %s (run 'ddx upgrade' to install)\n\nWhat's new:\n  • Performance improvements\n  • Bug fixes\n  • New features\n",
 			version)
 	} else {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(),
-			"\n⬆️  Update available: %s (run 'ddx upgrade' to install)\n",
-			version)
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
+			"⬆️  ddx %s is available (you have %s); run 'ddx upgrade'\n",
+			version, f.Version)
 	}
 
 	return nil
 }
 
-// registerSubcommands adds all subcommands to the root command
-func (f *CommandFactory) registerSubcommands(rootCmd *cobra.Command) {
-	// Version command
+// topLevelCommand is a command under root, built only when it is needed
+type topLevelCommand struct {
+	// Names are the command's name and aliases
+	Names []string
+	New   func() *cobra.Command
+	// Tree marks commands that walk the whole command tree, so every
+	// command is registered when they run
+	Tree bool
+}
+
+// topLevelCommands returns the commands under root in the order they are
+// registered. A test checks the names against the built commands.
+func (f *CommandFactory) topLevelCommands(rootCmd *cobra.Command) []topLevelCommand {
+	return []topLevelCommand{
+		{Names: []string{"version"}, New: f.newVersionCommand},
+		{Names: []string{"completion"}, New: func() *cobra.Command { return newCompletionCommand(rootCmd) }, Tree: true},
+		{Names: []string{"init"}, New: f.newInitCommand},
+		{Names: []string{"quickstart"}, New: f.newQuickstartCommand},
+		{Names: []string{"list", "ls"}, New: f.newListCommand},
+		{Names: []string{"doctor"}, New: f.newDoctorCommand},
+		{Names: []string{"diagnose"}, New: f.newDiagnoseCommand},
+		{Names: []string{"library"}, New: f.newLibraryCommand},
+		{Names: []string{"update"}, New: f.newUpdateCommand},
+		{Names: []string{"upgrade"}, New: f.newUpgradeCommand},
+		{Names: []string{"contribute"}, New: f.newContributeCommand},
+		{Names: []string{"config"}, New: f.newConfigCommand},
+		{Names: []string{"workflow"}, New: f.newWorkflowCommand},
+		{Names: []string{"fetch"}, New: f.newFetchCommand},
+		{Names: []string{"lock"}, New: f.newLockCommand},
+		{Names: []string{"which"}, New: f.newWhichCommand},
+		{Names: []string{"rollback"}, New: f.newRollbackCommand},
+		{Names: []string{"settings"}, New: f.newSettingsCommand},
+		{Names: []string{"agent"}, New: f.newAgentCommand},
+		{Names: []string{"persona"}, New: f.newPersonaCommand},
+		{Names: []string{"mcp"}, New: f.newMCPCommand},
+		{Names: []string{"install"}, New: f.newInstallCommand},
+		{Names: []string{"download-binary"}, New: f.newDownloadBinaryCommand},
+		{Names: []string{"install-binary"}, New: f.newInstallBinaryCommand},
+		{Names: []string{"setup"}, New: f.newSetupCommand},
+		{Names: []string{"release"}, New: f.newReleaseCommand},
+		{Names: []string{"uninstall"}, New: f.newUninstallCommand},
+		{Names: []string{"status"}, New: f.newStatusCommand},
+		{Names: []string{"log"}, New: f.newLogCommand},
+		{Names: []string{"auth"}, New: f.newAuthCommand},
+		{Names: []string{"history"}, New: f.newHistoryCommand},
+		{Names: []string{"browse"}, New: f.newBrowseCommand},
+		{Names: []string{"telemetry"}, New: f.newTelemetryCommand},
+		{Names: []string{"templates", "template"}, New: f.newTemplatesCommand},
+		{Names: []string{"patterns", "pattern"}, New: f.newPatternsCommand},
+		{Names: []string{"hooks", "hook"}, New: f.newHooksCommand},
+		{Names: []string{"exec"}, New: f.newExecCommand},
+		{Names: []string{"metaprompt", "meta-prompt"}, New: f.newMetaPromptCommand},
+		{Names: []string{"workspace"}, New: f.newWorkspaceCommand},
+		{Names: []string{"backup", "backups"}, New: f.newBackupCommand},
+		{Names: []string{"contracts"}, New: f.newContractsCommand, Tree: true},
+		{Names: []string{"prompts", "prompt"}, New: f.newPromptsCommand},
+		{Names: []string{"p"}, New: f.newPromptShortcutCommand},
+	}
+}
+
+// registerSubcommands adds the subcommands to the root command. With
+// args, the arguments of the invocation, only the command they name is
+// built, which keeps startup short; help, completion, unknown commands and
+// commands that walk the tree get every command.
+func (f *CommandFactory) registerSubcommands(rootCmd *cobra.Command, args []string) {
+	commands := f.topLevelCommands(rootCmd)
+	if args != nil {
+		name := commandName(rootCmd, args)
+		for _, c := range commands {
+			if slices.Contains(c.Names, name) && !c.Tree {
+				rootCmd.AddCommand(c.New())
+				return
+			}
+		}
+	}
+	for _, c := range commands {
+		rootCmd.AddCommand(c.New())
+	}
+}
+
+// commandName returns the first argument that is not a root flag or a
+// flag's value: the name of the command to run, or "" when there is none
+func commandName(rootCmd *cobra.Command, args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return arg
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *pflag.Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			flag = rootCmd.PersistentFlags().Lookup(name)
+		} else if len(arg) == 2 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+		if flag == nil {
+			// An unknown flag: let cobra report it with every command
+			return ""
+		}
+		if flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return ""
+}
+
+// newVersionCommand creates the version command
+func (f *CommandFactory) newVersionCommand() *cobra.Command {
 	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "Show version information",
@@ -252,18 +577,15 @@ func (f *CommandFactory) registerSubcommands(rootCmd *cobra.Command) {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "DDx %s\n", version)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Commit: %s\n", f.Commit)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Built: %s\n", f.Date)
-
-			// Check for --no-check flag
-			noCheck, _ := cmd.Flags().GetBool("no-check")
-			_ = noCheck // TODO: Implement update checking when this flag is used
-			// For now, we don't check for updates
 		},
 	}
 	versionCmd.Flags().Bool("no-check", false, "Skip checking for updates")
-	rootCmd.AddCommand(versionCmd)
+	return versionCmd
+}
 
-	// Completion command
-	completionCmd := &cobra.Command{
+// newCompletionCommand creates the completion command for rootCmd
+func newCompletionCommand(rootCmd *cobra.Command) *cobra.Command {
+	return &cobra.Command{
 		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "Generate completion script",
 		Long: `To configure your shell to load completions:
@@ -296,27 +618,10 @@ PowerShell:
 			}
 		},
 	}
-	rootCmd.AddCommand(completionCmd)
-
-	// Register all other commands
-	rootCmd.AddCommand(f.newInitCommand())
-	rootCmd.AddCommand(f.newListCommand())
-	rootCmd.AddCommand(f.newDoctorCommand())
-	rootCmd.AddCommand(f.newUpdateCommand())
-	rootCmd.AddCommand(f.newUpgradeCommand())
-	rootCmd.AddCommand(f.newContributeCommand())
-	rootCmd.AddCommand(f.newConfigCommand())
-	rootCmd.AddCommand(f.newWorkflowCommand())
-	rootCmd.AddCommand(f.newAgentCommand())
-	rootCmd.AddCommand(f.newPersonaCommand())
-	rootCmd.AddCommand(f.newMCPCommand())
-	rootCmd.AddCommand(f.newInstallCommand())
-	rootCmd.AddCommand(f.newUninstallCommand())
-	rootCmd.AddCommand(f.newStatusCommand())
-	rootCmd.AddCommand(f.newLogCommand())
-	rootCmd.AddCommand(f.newAuthCommand())
-
-	// Add prompts command group
+}
+
+// newPromptsCommand creates the prompts command group
+func (f *CommandFactory) newPromptsCommand() *cobra.Command {
 	promptsCmd := &cobra.Command{
 		Use:     "prompts",
 		Short:   "Manage AI prompts",
@@ -324,7 +629,11 @@ PowerShell:
 	}
 	promptsCmd.AddCommand(f.newPromptsListCommand())
 	promptsCmd.AddCommand(f.newPromptsShowCommand())
-	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.AddCommand(f.newPromptsRenderCommand())
+	promptsCmd.AddCommand(f.newPromptInjectCommand())
+	promptsCmd.AddCommand(f.newPromptRemoveCommand())
+	promptsCmd.AddCommand(f.newPromptSyncCommand())
+	return promptsCmd
 }
 
 // newAuthCommand creates the authentication command
@@ -346,7 +655,11 @@ Examples:
   ddx auth status                         # Show authentication status
   ddx auth list                           # List stored credentials
   ddx auth logout github.com              # Remove stored credentials
-  ddx auth token github.com <token>       # Set personal access token`,
+  ddx auth token github.com <token>       # Set personal access token
+  ddx auth refresh                        # Refresh expiring credentials
+  ddx auth audit                          # Show authentication events
+  ddx auth export --output creds.enc      # Export credentials, encrypted
+  ddx auth import creds.enc               # Import exported credentials`,
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
 		},
@@ -358,6 +671,10 @@ Examples:
 	listCmd := f.newAuthListCommand()
 	logoutCmd := f.newAuthLogoutCommand()
 	tokenCmd := f.newAuthTokenCommand()
+	refreshCmd := f.newAuthRefreshCommand()
+	auditCmd := f.newAuthAuditCommand()
+	exportCmd := f.newAuthExportCommand()
+	importCmd := f.newAuthImportCommand()
 
 	// Add subcommands
 	cmd.AddCommand(loginCmd)
@@ -365,10 +682,111 @@ Examples:
 	cmd.AddCommand(listCmd)
 	cmd.AddCommand(logoutCmd)
 	cmd.AddCommand(tokenCmd)
+	cmd.AddCommand(refreshCmd)
+	cmd.AddCommand(auditCmd)
+	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(importCmd)
+
+	return cmd
+}
+
+// newAuthExportCommand creates the auth export subcommand
+func (f *CommandFactory) newAuthExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export stored credentials to an encrypted bundle",
+		Long: `Export every stored credential to a bundle for moving to another machine.
+
+The bundle is encrypted with AES-256-GCM using a key derived from a
+passphrase you choose; credentials are never written in plaintext. The
+passphrase is read from DDX_AUTH_PASSPHRASE or asked for twice in a
+terminal. Bring the bundle over and run 'ddx auth import'.
+
+Examples:
+  ddx auth export --output creds.enc
+  DDX_AUTH_PASSPHRASE=... ddx auth export --output creds.enc --force`,
+		Args: cobra.NoArgs,
+		RunE: runAuthExport,
+	}
+
+	cmd.Flags().StringP("output", "o", "", "File to write the encrypted bundle to (required)")
+	cmd.Flags().Bool("force", false, "Overwrite an existing bundle")
 
 	return cmd
 }
 
+// newAuthImportCommand creates the auth import subcommand
+func (f *CommandFactory) newAuthImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import credentials from an encrypted bundle",
+		Long: `Import credentials exported with 'ddx auth export'.
+
+Imported credentials are merged into the credential stores. A credential
+already stored here is replaced only when the bundle's copy was updated
+more recently, so importing an old bundle never clobbers newer tokens.
+The passphrase is read from DDX_AUTH_PASSPHRASE or asked for in a
+terminal.
+
+Examples:
+  ddx auth import creds.enc`,
+		Args: cobra.ExactArgs(1),
+		RunE: runAuthImport,
+	}
+}
+
+// newAuthAuditCommand creates the auth audit subcommand
+func (f *CommandFactory) newAuthAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the authentication audit log",
+		Long: `Show authentication attempts, credential access and their outcomes.
+
+Events are recorded in ~/.local/share/ddx/auth-audit.jsonl, readable only
+by you and separate from the project command history. Tokens are never
+recorded.
+After 5 failed authentication attempts for a repository within 15
+minutes, further attempts are refused until 15 minutes have passed.
+
+Examples:
+  ddx auth audit                  # Show the last 50 events
+  ddx auth audit --failures       # Show failed and refused attempts
+  ddx auth audit --since 24h --json`,
+		Args: cobra.NoArgs,
+		RunE: runAuthAudit,
+	}
+
+	cmd.Flags().Int("limit", 50, "Number of events to show (0 for all)")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+	cmd.Flags().Bool("failures", false, "Show only failed and refused events")
+	cmd.Flags().Duration("since", 0, "Show only events within this long, e.g. 24h")
+
+	return cmd
+}
+
+// newAuthRefreshCommand creates the auth refresh subcommand
+func (f *CommandFactory) newAuthRefreshCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh [repository...]",
+		Short: "Refresh expired or expiring credentials",
+		Long: `Refresh stored credentials before they expire.
+
+Without arguments, every credential that has expired or expires within a
+day is refreshed. Platforms that issue refresh tokens exchange them for a
+new token; for the others, such as GitHub personal access tokens, you are
+asked to authenticate again.
+
+Credentials about to expire are also refreshed automatically when they
+are used, and update and contribute warn when a token may run out part
+way through.
+
+Examples:
+  ddx auth refresh               # Refresh expired and expiring credentials
+  ddx auth refresh github.com    # Refresh one credential`,
+		RunE: runAuthRefresh,
+	}
+}
+
 // newAuthLoginCommand creates the auth login subcommand
 func (f *CommandFactory) newAuthLoginCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -405,8 +823,10 @@ Displays:
 - Stored credentials and their status
 - Available authentication methods
 - Credential expiration information
-- Platform-specific details`,
-		RunE: runAuthStatus,
+- Platform-specific details
+- How an SSH library repository is reached, following host aliases,
+  users and keys from ~/.ssh/config`,
+		RunE: f.runAuthStatus,
 	}
 }
 
@@ -453,6 +873,8 @@ Examples:
   ddx auth token github.com ghp_xxxxxxxxxxxxxxxxxxxx`,
 		Args: cobra.ExactArgs(2),
 		RunE: runAuthToken,
+		// The token itself is kept out of the history log
+		Annotations: map[string]string{historyAnnotation: historyRedact},
 	}
 }
 
@@ -476,6 +898,136 @@ Examples:
 	cmd.Flags().String("version", "", "Version to install (default: latest)")
 	cmd.Flags().String("path", "", "Installation path (default: ~/.local/bin)")
 	cmd.Flags().Bool("force", false, "Force installation even if already installed")
+	cmd.Flags().Bool("verify-signature", false, "Also verify the release's sigstore signature (requires cosign)")
+
+	return cmd
+}
+
+// newDownloadBinaryCommand creates the download-binary command
+func (f *CommandFactory) newDownloadBinaryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download-binary",
+		Short: "Download and verify a DDx release archive",
+		Long: `Download the DDx release archive for a platform and verify it.
+
+The archive is fetched over HTTPS, retrying transient failures, and its
+SHA256 checksum is checked against the release's checksums.sha256. With
+--verify-signature the archive's sigstore signature is also checked with
+cosign. Archives are saved to ~/.cache/ddx/downloads by default, where
+'ddx install-binary' finds them.
+
+Set DDX_RELEASE_URL to download from a mirror of the GitHub releases.
+
+Examples:
+  ddx download-binary                          # Latest release for this machine
+  ddx download-binary --version v1.2.0
+  ddx download-binary --platform darwin/arm64 --output ./dist`,
+		Args: cobra.NoArgs,
+		RunE: runDownloadBinary,
+	}
+
+	cmd.Flags().String("version", "", "Release to download (default: latest)")
+	cmd.Flags().String("platform", "", "Platform as os/arch (default: this machine)")
+	cmd.Flags().String("output", "", "Directory to save the archive in (default: ~/.cache/ddx/downloads)")
+	cmd.Flags().Bool("verify-signature", false, "Also verify the release's sigstore signature (requires cosign)")
+
+	return cmd
+}
+
+// newInstallBinaryCommand creates the install-binary command
+func (f *CommandFactory) newInstallBinaryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-binary",
+		Short: "Install the DDx binary from a verified release archive",
+		Long: `Install the DDx binary from a release archive.
+
+Uses the archive saved by 'ddx download-binary', or downloads one when
+there is none or --version is given. The archive's checksum is verified
+again before the binary is extracted, and the binary is swapped into place
+atomically so an interrupted install never leaves a broken executable.
+
+Examples:
+  ddx install-binary                           # Install the downloaded archive
+  ddx install-binary --version v1.2.0 --path ~/bin
+  ddx install-binary --archive ./ddx-linux-amd64.tar.gz`,
+		Args: cobra.NoArgs,
+		RunE: runInstallBinary,
+	}
+
+	cmd.Flags().String("archive", "", "Release archive to install, with its .sha256 file next to it")
+	cmd.Flags().String("version", "", "Release to download and install")
+	cmd.Flags().String("path", "", "Installation path (default: ~/.local/bin)")
+	cmd.Flags().Bool("verify-signature", false, "Also verify the release's sigstore signature (requires cosign)")
+
+	return cmd
+}
+
+// newSetupCommand creates the setup command group
+func (f *CommandFactory) newSetupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Set up the environment ddx runs in",
+	}
+
+	pathCmd := &cobra.Command{
+		Use:   "path [dir]",
+		Short: "Add the ddx install directory to PATH",
+		Long: `Add a directory to PATH for new sessions, by default the one the
+running ddx binary is in.
+
+On macOS and Linux the profile of your shell is updated: ~/.zshrc,
+~/.bashrc (~/.bash_profile on macOS), fish's conf.d or PowerShell's
+profile, falling back to ~/.profile. On Windows the user PATH is changed
+in the registry, running programs are told about the change, and
+existing PowerShell profiles are updated too. Running it again changes
+nothing.
+
+Examples:
+  ddx setup path                   # Add the directory of this ddx binary
+  ddx setup path ~/.local/bin      # Add a specific directory
+  ddx setup path --shell fish      # Update fish's configuration`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runSetupPath,
+	}
+	pathCmd.Flags().String("shell", "", "Shell whose profile to update: bash, zsh, fish, sh or pwsh (default: from $SHELL)")
+	cmd.AddCommand(pathCmd)
+
+	return cmd
+}
+
+// newReleaseCommand creates the release command group, used by
+// maintainers when publishing a release
+func (f *CommandFactory) newReleaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Prepare DDx releases for distribution (maintainers)",
+	}
+
+	manifestCmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Generate package manager manifests for a release",
+		Long: `Generate the Homebrew formula, Scoop manifest and Chocolatey package
+of a release from its archive checksums.
+
+The checksums are read from --checksums, as written by the release
+workflow, or downloaded from the published release. Files are written to
+<output>/homebrew/ddx.rb, <output>/scoop/ddx.json and
+<output>/chocolatey/, replacing those of an earlier release, ready to be
+committed to a tap or bucket or packed with 'choco pack'.
+
+Examples:
+  ddx release manifest --version v1.2.0
+  ddx release manifest --version v1.2.0 --checksums dist/checksums.sha256
+  ddx release manifest --version v1.2.0 --format brew,scoop --output ../tap`,
+		Args: cobra.NoArgs,
+		RunE: runReleaseManifest,
+	}
+	manifestCmd.Flags().String("version", "", "Release to generate manifests for, e.g. v1.2.0")
+	manifestCmd.Flags().String("checksums", "", "Local checksums.sha256 of the release archives (default: download it)")
+	manifestCmd.Flags().String("output", "packaging", "Directory to write the manifests to")
+	manifestCmd.Flags().StringSlice("format", nil, "Package managers to generate for: homebrew, scoop, chocolatey (default: all)")
+	_ = manifestCmd.MarkFlagRequired("version")
+	cmd.AddCommand(manifestCmd)
 
 	return cmd
 }
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"github.com/easel/ddx/internal/assets"
+	"github.com/easel/ddx/internal/promptsync"
+	"github.com/easel/ddx/internal/sections"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +20,33 @@ This command:
 • Downloads essential resources (prompts, templates, patterns)
 • Configures project-specific settings
 
+When the library repository cannot be reached, or with --no-git, a
+minimal starter library bundled with ddx is installed instead.
+
+Run from a terminal, init asks for the library repository, a starter
+template, a workflow and the personas for the workflow's roles. Use
+--non-interactive to skip the questions; --template, --workflow and
+--persona make the same choices from scripts.
+
+With --from, init starts from a team configuration repository instead
+of the defaults. Its config.yaml (at the root or in .ddx/) is validated
+and copied with {{name}} placeholders filled from --var, recorded
+variables or prompts; a prompts/ directory is installed as project
+prompt overrides in .ddx/prompts. The repository and commit are
+recorded under 'source' in the configuration. Append #ref to the URL
+to use a branch or tag.
+
 Examples:
   ddx init                  # Initialize DDx in current project
   ddx init --force          # Reinitialize existing project
-  ddx init --no-git         # Skip git subtree setup`,
-		Args: cobra.NoArgs,
-		RunE: f.runInit,
+  ddx init --no-git         # Skip git subtree setup
+  ddx init --non-interactive --workflow helix \
+    --persona code-reviewer=strict-code-reviewer
+  ddx init --from https://github.com/acme/ddx-team#stable \
+    --var team=payments`,
+		Args:        cobra.NoArgs,
+		RunE:        f.runInit,
+		Annotations: map[string]string{projectAnnotation: projectHere},
 	}
 
 	cmd.Flags().BoolP("force", "f", false, "Force initialization even if DDx already exists")
@@ -31,7 +55,38 @@ Examples:
 	cmd.Flags().Bool("skip-claude-injection", false, "Skip injecting meta-prompts into CLAUDE.md")
 	cmd.Flags().String("repository", "", "Library repository URL (default: https://github.com/easel/ddx-library)")
 	cmd.Flags().String("branch", "", "Library repository branch (default: main)")
+	cmd.Flags().Bool("non-interactive", false, "Do not ask questions; use flags and defaults")
+	cmd.Flags().String("template", "", "Apply a starter template from the library")
+	cmd.Flags().String("workflow", "", "Activate a workflow from the library")
+	cmd.Flags().StringArray("persona", nil, "Bind a persona to a role (role=persona, repeatable)")
+	cmd.Flags().String("from", "", "Initialize from a team configuration git repository (url[#ref])")
+	cmd.Flags().StringArray("var", nil, "Set a value for the team configuration (name=value); repeatable")
+
+	return cmd
+}
+
+// newQuickstartCommand creates a fresh quickstart command
+func (f *CommandFactory) newQuickstartCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quickstart",
+		Short: "Walk through setting up DDx step by step",
+		Long: `Walk through setting up DDx: verifying the installation, initializing
+a project, binding a persona and running a first workflow command.
 
+Each run shows which steps are done and what to do next. Steps are
+checked against the machine and the current project, and a finished step
+is remembered in ~/.local/share/ddx/onboarding.json.
+
+The first time ddx runs on a machine without a project or a global
+configuration, it suggests this command once.
+
+Examples:
+  ddx quickstart          # Show your progress and the next step
+  ddx quickstart --reset  # Forget progress and show the welcome again`,
+		Args: cobra.NoArgs,
+		RunE: f.runQuickstart,
+	}
+	cmd.Flags().Bool("reset", false, "Forget onboarding progress")
 	return cmd
 }
 
@@ -53,15 +108,30 @@ Resources include:
 Examples:
   ddx list              # List all resources
   ddx list templates    # List only templates
-  ddx list patterns     # List only patterns`,
+  ddx list patterns     # List only patterns
+  ddx list --filter 'claude/*.md'          # Filter with a glob
+  ddx list --filter 're:^(helix|kanban)$'  # Filter with a regular expression
+  ddx list --format '{{.Type}}\t{{.Name}}' # Print selected fields
+  ddx list --tree       # Show the library hierarchy with sizes and dates
+  ddx list --local-only # Show assets not in the upstream library
+
+--detailed, --tree and --json add each asset's size, last modification
+and source: "upstream" when the library had it at the last sync, or
+"local-only" when it exists only in this project and could be contributed.
+
+Resources are read from the library index (.ddx/library/.index.json),
+which is updated when library files change; --refresh rebuilds it.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: f.runList,
 	}
 
-	cmd.Flags().BoolP("detailed", "d", false, "Show detailed information")
-	cmd.Flags().StringP("filter", "f", "", "Filter resources by name")
+	cmd.Flags().BoolP("detailed", "d", false, "Show size, last modification and source of each resource")
+	cmd.Flags().Bool("local-only", false, "Show only resources that are not in the upstream library")
+	cmd.Flags().StringP("filter", "f", "", "Filter resources by name (substring, glob, or re:<regex>)")
 	cmd.Flags().Bool("json", false, "Output results as JSON")
 	cmd.Flags().Bool("tree", false, "Display resources in tree format")
+	cmd.Flags().String("format", "", "Format each resource with a Go template (prefix with 'table ' to align columns)")
+	cmd.Flags().Bool("refresh", false, "Rebuild the library index before listing")
 
 	return cmd
 }
@@ -83,16 +153,282 @@ This command verifies:
 • Development tool setup
 • AI integration readiness
 
+Inside a DDx project it also diagnoses the project itself and reports a
+health score from 0 to 100:
+• .ddx/config.yaml validity
+• Library path existence, git subtree health and sync freshness
+• CLAUDE.md section marker consistency
+• Persona binding targets
+• Active workflow definitions and state files
+
 The doctor helps identify and resolve:
 • Installation issues
 • Configuration problems
 • Missing dependencies
-• Environment setup issues`,
+• Environment setup issues
+
+Offline, with --offline, network.offline or DDX_OFFLINE, the network check
+is skipped and the doctor checks instead that library.repository.url is a
+mirror or bundle on the filesystem.`,
 		Args: cobra.NoArgs,
 		RunE: f.runDoctor,
 	}
 
 	cmd.Flags().BoolP("verbose", "v", false, "Show detailed diagnostic output")
+	cmd.Flags().Bool("offline", false, "Skip the network check and check the library mirror instead")
+
+	return cmd
+}
+
+// newDiagnoseCommand creates a fresh diagnose command
+func (f *CommandFactory) newDiagnoseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Collect a support bundle for bug reports",
+		Long: `Collect what a bug report needs into a single tar.gz:
+
+• version.json      ddx version, commit, Go version, OS and architecture
+• environment.json  git version, PATH and the DDX_*, proxy and terminal
+                    variables
+• config.txt        the effective configuration and the layer setting
+                    each value
+• project.json      library state, personas, workflows and MCP servers,
+                    as 'ddx status' shows them
+• doctor.json       the project checks of 'ddx doctor' and the health score
+• history.jsonl     recent commands, when history is enabled
+
+Outside a DDx project only the first two are collected.
+
+Every file is redacted before it is written: values of keys and variables
+named like tokens, passwords, secrets or API keys, credentials in URLs,
+recognizable tokens such as GitHub and OpenAI keys, and your home
+directory, which becomes ~. Review the bundle before attaching it.
+
+Examples:
+  ddx diagnose                          # Write ddx-diagnose-<time>.tar.gz
+  ddx diagnose -o bug-123.tar.gz        # Choose the file name
+  ddx diagnose --history 200            # Include more history`,
+		Args: cobra.NoArgs,
+		RunE: f.runDiagnose,
+	}
+	cmd.Flags().StringP("output", "o", "", "Bundle file (default: ddx-diagnose-<time>.tar.gz)")
+	cmd.Flags().Int("history", 50, "Number of history entries to include (0 for all)")
+	return cmd
+}
+
+// newLibraryCommand creates the library maintenance command group
+func (f *CommandFactory) newLibraryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "library",
+		Short: "Maintain a DDx library",
+		Long: `Tools for maintainers of a DDx library repository, for pinning the
+library a project uses to a tag or commit, and for carrying library updates
+to machines without network access.`,
+	}
+	cmd.AddCommand(f.newLibraryDoctorCommand())
+	cmd.AddCommand(f.newLibraryChangelogCommand())
+	cmd.AddCommand(f.newLibraryPinCommand())
+	cmd.AddCommand(f.newLibraryUnpinCommand())
+	cmd.AddCommand(f.newLibrarySourcesCommand())
+	cmd.AddCommand(f.newLibraryExportBundleCommand())
+	cmd.AddCommand(f.newLibraryImportBundleCommand())
+	return cmd
+}
+
+// newLibraryExportBundleCommand creates the library export-bundle subcommand
+func (f *CommandFactory) newLibraryExportBundleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-bundle <file>",
+		Short: "Write the library repository to a git bundle",
+		Long: `Fetch the library branch and its tags and write them to a git bundle, a
+single file that carries library updates to machines that cannot reach the
+repository. Run it where the repository is reachable, then take the file
+across and run 'ddx library import-bundle' there.
+
+The repository and branch default to the project's library. --since leaves
+out the history the other side already has, keeping the bundle small.
+
+Examples:
+  ddx library export-bundle ddx-library.bundle
+  ddx library export-bundle update.bundle --since v1.4.0
+  ddx library export-bundle ddx-library.bundle --repository https://github.com/easel/ddx-library`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runLibraryExportBundle,
+	}
+	cmd.Flags().String("repository", "", "Repository to bundle (default: library.repository.url)")
+	cmd.Flags().String("branch", "", "Branch to bundle (default: library.repository.branch)")
+	cmd.Flags().String("since", "", "Leave out the history up to this tag or commit")
+	return cmd
+}
+
+// newLibraryImportBundleCommand creates the library import-bundle subcommand
+func (f *CommandFactory) newLibraryImportBundleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-bundle <file>",
+		Short: "Import a library bundle into a local mirror",
+		Long: `Verify a bundle written by 'ddx library export-bundle' and fetch its
+branches and tags into a bare mirror repository, creating the mirror the
+first time. With library.repository.url pointing at the mirror, 'ddx update'
+then updates the library without network access.
+
+The mirror defaults to library.repository.url when that is a local path.
+A bundle file can also serve as library.repository.url directly.
+
+Examples:
+  ddx library import-bundle ddx-library.bundle --mirror /srv/mirrors/ddx-library.git
+  ddx config set library.repository.url /srv/mirrors/ddx-library.git
+  ddx library import-bundle update.bundle    # Later updates`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runLibraryImportBundle,
+	}
+	cmd.Flags().String("mirror", "", "Bare repository to import into (default: library.repository.url)")
+	return cmd
+}
+
+// newLibrarySourcesCommand creates the library sources subcommand
+func (f *CommandFactory) newLibrarySourcesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sources",
+		Short: "List the libraries the project draws resources from",
+		Long: `List the main library and the sources in library.sources, highest
+priority first. A resource name that several sources have resolves to the
+first of them; prefix it with a source name, as in internal:sec-reviewer,
+to pick one.
+
+  library:
+    path: .ddx/library
+    sources:
+      - name: internal
+        path: .ddx/internal
+        priority: 10
+        repository:
+          url: https://github.com/acme/ddx-internal
+
+'ddx update --source internal' and 'ddx contribute --source internal' work
+on one source.`,
+		Args: cobra.NoArgs,
+		RunE: f.runLibrarySources,
+	}
+	cmd.Flags().Bool("json", false, "Output as JSON")
+	return cmd
+}
+
+// newLibraryPinCommand creates the library pin subcommand
+func (f *CommandFactory) newLibraryPinCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <ref>",
+		Short: "Pin the project's library to a tag or commit",
+		Long: `Pin the project's library to a tag or commit instead of following the
+branch in library.repository.branch. The ref is stored as
+library.repository.ref in .ddx/config.yaml.
+
+While the library is pinned, 'ddx update' does not move it past the pin
+unless --force is given, and 'ddx update --check' compares the library with
+the pinned ref. 'ddx status' and 'ddx doctor' show the pin.
+
+Examples:
+  ddx library pin v1.4.0        # Pin to a release tag
+  ddx library pin 9fceb02       # Pin to a commit
+  ddx library unpin             # Follow the branch again`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runLibraryPin,
+	}
+}
+
+// newLibraryUnpinCommand creates the library unpin subcommand
+func (f *CommandFactory) newLibraryUnpinCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpin",
+		Short: "Follow the library branch again",
+		Long:  `Remove library.repository.ref, so 'ddx update' follows the library branch again.`,
+		Args:  cobra.NoArgs,
+		RunE:  f.runLibraryUnpin,
+	}
+}
+
+// newLibraryChangelogCommand creates the library changelog subcommand
+func (f *CommandFactory) newLibraryChangelogCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Manage the library's changelog fragments",
+		Long: `Manage the changelog fragments in a library's changelog/ directory.
+
+'ddx contribute' writes a fragment for each asset it contributes:
+  type: added
+  name: prompts/code-review
+  summary: Add a checklist-driven code review prompt
+
+Maintainers roll the pending fragments into CHANGELOG.md when releasing.`,
+	}
+	cmd.AddCommand(f.newLibraryChangelogAssembleCommand())
+	return cmd
+}
+
+// newLibraryChangelogAssembleCommand creates the changelog assemble subcommand
+func (f *CommandFactory) newLibraryChangelogAssembleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assemble",
+		Short: "Roll changelog fragments into CHANGELOG.md",
+		Long: `Roll the pending changelog fragments into a release section at the top
+of the library's CHANGELOG.md, grouped by change type, and remove them.
+
+Examples:
+  ddx library changelog assemble --version 1.4.0 --path .
+  ddx library changelog assemble --version 1.4.0 --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: f.runLibraryChangelogAssemble,
+	}
+
+	cmd.Flags().String("version", "", "Release version for the new section (required)")
+	cmd.Flags().String("date", "", "Release date as YYYY-MM-DD (default: today)")
+	cmd.Flags().String("path", "", "Library directory (default: configured library)")
+	cmd.Flags().Bool("dry-run", false, "Print the release notes without changing files")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+// newLibraryDoctorCommand creates the library doctor subcommand
+func (f *CommandFactory) newLibraryDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Report maintenance problems in the library",
+		Long: `Inspect a library and produce a prioritized maintenance report.
+
+This command detects:
+• Personas, prompts and patterns missing frontmatter
+• Categories without a README.md
+• Workflow definitions that fail to load
+• Workflow commands and pipeline steps without a prompt file
+• Relative markdown links to files missing from the library
+• Assets sharing a name, including names that differ only in case
+• Orphaned files: editor and merge leftovers, files outside the asset
+  type directories and non-markdown files among the personas
+• Files larger than the size limit
+• Assets deprecated for longer than the removal window
+
+The command exits with status 1 when any problem is found, so it can gate
+library pull requests in CI.
+
+Assets are deprecated with frontmatter such as:
+  deprecated: 2025-01-15
+  replaced_by: personas/new-reviewer.md
+
+Examples:
+  ddx library doctor                   # Inspect the project's library
+  ddx library doctor --path .          # Inspect a library checkout
+  ddx library doctor --json            # Machine-readable report
+  ddx library doctor --create-issues --repo easel/ddx-library`,
+		Args: cobra.NoArgs,
+		RunE: f.runLibraryDoctor,
+	}
+
+	cmd.Flags().String("path", "", "Library directory to inspect (default: configured library)")
+	cmd.Flags().Bool("json", false, "Output the report as JSON")
+	cmd.Flags().Int64("max-size", 1024, "Report files larger than this many KB")
+	cmd.Flags().Int("deprecation-days", 90, "Days a deprecated asset may remain before removal")
+	cmd.Flags().Bool("create-issues", false, "Open a GitHub issue per problem type with the gh CLI")
+	cmd.Flags().String("repo", "", "GitHub repository (owner/name) for --create-issues")
 
 	return cmd
 }
@@ -112,7 +448,20 @@ This command:
 
 You can optionally specify a specific resource to update:
   ddx update templates/nextjs  # Update only the nextjs template
-  ddx update prompts           # Update all prompts`,
+  ddx update prompts           # Update all prompts
+
+With --check nothing is applied: the upstream branch is fetched and the
+library files changed since the last sync are listed by asset type. Use
+--json for machine-readable output in CI, or --format to print chosen
+fields of each change (.Path, .Type, .Status):
+  ddx update --check
+  ddx update --check personas --json
+  ddx update --check --format '{{.Status}}\t{{.Path}}'
+
+Set library.include and library.exclude to use only part of the library,
+for example include: [workflows/helix, personas/security-*]. Files outside
+the selection stay in the library checkout but are hidden from listing
+and lookup, and --check does not report them.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: f.runUpdate,
 	}
@@ -128,6 +477,10 @@ You can optionally specify a specific resource to update:
 	cmd.Flags().Bool("mine", false, "Use local changes in conflict resolution")
 	cmd.Flags().Bool("theirs", false, "Use upstream changes in conflict resolution")
 	cmd.Flags().Bool("dry-run", false, "Preview changes without applying them")
+	cmd.Flags().Bool("json", false, "Output the --check report as JSON")
+	cmd.Flags().String("format", "", "Format each change in the --check report with a Go template")
+	cmd.Flags().Bool("frozen", false, "Verify the library against ddx.lock instead of updating it")
+	cmd.Flags().String("source", "", "Update the named library source instead of the main library")
 
 	return cmd
 }
@@ -174,6 +527,11 @@ The command:
 • Pushes to the upstream repository
 • Provides instructions for creating a pull request
 
+Each contributed asset gets a changelog fragment in the library's
+changelog/ directory. New assets are "added" and deleted ones "removed";
+otherwise a feat: or fix: message prefix gives "added" or "fixed", and
+anything else "changed". Use --changelog-type to choose the type.
+
 Examples:
   ddx contribute -m "Add new authentication patterns"
   ddx contribute --branch my-feature -m "New TypeScript templates"
@@ -186,6 +544,9 @@ Examples:
 	cmd.Flags().String("branch", "", "Feature branch name")
 	cmd.Flags().Bool("dry-run", false, "Show what would be contributed without actually doing it")
 	cmd.Flags().Bool("create-pr", false, "Create a pull request after pushing")
+	cmd.Flags().String("changelog-type", "", "Change type for the changelog fragments (added, changed, deprecated, removed, fixed)")
+	cmd.Flags().Bool("no-changelog", false, "Do not write changelog fragments")
+	cmd.Flags().String("source", "", "Contribute from the named library source instead of the main library")
 
 	return cmd
 }
@@ -207,8 +568,37 @@ Examples:
   ddx config                    # Show help
   ddx config set key value      # Set specific value
   ddx config get key            # Get specific value
+  ddx config set persona_bindings.code-reviewer strict-code-reviewer
+  ddx config set library.repository.url --secret  # Keep the value in the credential store
+  ddx config unset persona_bindings.code-reviewer
+  ddx config append workflows.active helix
+  ddx config remove workflows.active helix
   ddx config edit               # Edit config in $EDITOR
-  cat .ddx/config.yaml          # View current config`,
+  ddx config effective          # Show merged config and value sources
+  ddx config resolve [key]      # Show values with ${ENV_VAR} references expanded
+  ddx config migrate            # Upgrade to the current format
+  ddx config doctor             # Explain which values win and find mistakes
+  cat .ddx/config.yaml          # View current config
+
+Keys are dotted paths into the config file; map entries such as
+persona_bindings.<role> can be addressed directly.
+
+Configuration is layered, later sources overriding earlier ones:
+  defaults < ~/.config/ddx/config.yaml < .ddx/config.yaml < .ddx.<profile>.yml
+           < .ddx.local.yml < environment variables
+
+Values such as library.path, library.repository.url and persona bindings
+may refer to environment variables as ${NAME} or ${NAME:-default}; write
+$${NAME} for a literal ${NAME}. Unset variables expand to nothing with a
+warning, unless the configuration sets 'interpolation: strict', in which
+case it fails to load. 'config resolve --strict' checks them in CI.
+
+A library repository URL can instead name a stored secret, such as a URL
+carrying an access token, as secret://<store>/<key>. 'config set --secret'
+stores the value in the DDx credential store under secret://config/<key>
+and writes the reference; without a value argument the value is read from
+the terminal or stdin. References are resolved only when the repository is
+contacted, so get, effective and export show them as written.`,
 		RunE: f.runConfig,
 	}
 
@@ -218,18 +608,125 @@ Examples:
 	cmd.Flags().Bool("wizard", false, "Run configuration wizard")
 	cmd.Flags().Bool("validate", false, "Validate configuration")
 	cmd.Flags().Bool("global", false, "Use global configuration")
+	cmd.Flags().Bool("effective", false, "Show effective configuration with sources (with 'show')")
+	cmd.Flags().Bool("strict", false, "Fail when values refer to unset environment variables (with 'resolve')")
+	cmd.Flags().Bool("secret", false, "With set, store the value in the credential store and write a secret:// reference")
 
 	// Enhanced validation flags for US-022
 	cmd.Flags().String("file", "", "Validate specific configuration file")
 	cmd.Flags().Bool("verbose", false, "Detailed validation output")
 	cmd.Flags().Bool("offline", false, "Skip network checks during validation")
 
-	// Add migrate subcommand
-	cmd.AddCommand(configMigrateCmd)
+	cmd.AddCommand(f.newConfigMigrateCommand())
+	cmd.AddCommand(f.newConfigDoctorCommand())
+	cmd.AddCommand(f.newConfigGitignoreCommand())
 
 	return cmd
 }
 
+// newConfigGitignoreCommand creates the config gitignore command
+func (f *CommandFactory) newConfigGitignoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gitignore",
+		Short: "Write the ddx block of .gitignore",
+		Long: `Keep the files ddx creates for itself, such as backups, snapshots and
+caches, out of version control.
+
+ddx manages a block of the project's .gitignore between the lines
+'# BEGIN DDX' and '# END DDX'; the rest of the file is never changed. The
+block is written by 'ddx init', refreshed by 'ddx update', and rewritten
+by this command after the settings change. The command lists each ddx
+artifact and whether it is ignored or committed.
+
+Choose which artifacts are ignored in .ddx/config.yaml:
+
+  gitignore:
+    ignore:
+      history: false       # commit .ddx/history.jsonl
+      helix-state: true    # ignore .helix-state.yml
+
+Set gitignore.manage to false to leave .gitignore alone.
+
+Examples:
+  ddx config gitignore
+  ddx config set gitignore.ignore.helix-state true
+  ddx config gitignore --check       # Exit 1 when the block is out of date
+  ddx config gitignore --remove`,
+		Args: cobra.NoArgs,
+		RunE: f.runConfigGitignore,
+	}
+	cmd.Flags().Bool("check", false, "Only check that the block matches the settings")
+	cmd.Flags().Bool("remove", false, "Remove the ddx block from .gitignore")
+	return cmd
+}
+
+// newConfigDoctorCommand creates a fresh config doctor command
+func (f *CommandFactory) newConfigDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Explain where configuration values come from and find mistakes",
+		Long: `Diagnose why a configuration value is not taking effect.
+
+The report lists:
+• Every configuration source, from the defaults through the global config,
+  .ddx/config.yaml, the active profile and .ddx.local.yml to environment
+  variables, and whether each was found and loads
+• Each effective value, the source it came from and the values it overrides
+• Unknown keys, with their line, and deprecated keys
+• Persona bindings and workflow overrides naming personas that exist in
+  neither .ddx/personas nor the library
+• A library.path that does not resolve to a directory
+
+The command exits with status 1 when a problem stops the configuration
+from working as written; deprecated keys are only warnings.
+
+Examples:
+  ddx config doctor
+  ddx --profile ci config doctor       # Include a profile
+  ddx config doctor --json`,
+		Args: cobra.NoArgs,
+		RunE: f.runConfigDoctor,
+	}
+	cmd.Flags().Bool("json", false, "Output the report as JSON")
+	return cmd
+}
+
+// newConfigMigrateCommand creates a fresh config migrate command
+func (f *CommandFactory) newConfigMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate [file...]",
+		Short: "Upgrade configuration files to the current format",
+		Long: `Upgrade configuration files to the current format.
+
+Migration steps are versioned: each upgrades files older than its format
+version, and the file's version field is updated to match. A legacy
+.ddx.yml is moved to .ddx/config.yaml, its 'includes' becoming
+'library.include'. Deprecated fields such as 'repository' (now
+'library.repository') are rewritten in every version; until then they are
+mapped when configuration is loaded and a warning is shown once. Comments
+and the order of other fields are kept.
+
+Values in the retired 'variables' section are moved to
+.ddx/variables.yml. Each changed file is first copied to a .bak file next
+to it.
+
+Without file arguments the project's .ddx/config.yaml (or legacy .ddx.yml)
+and .ddx.local.yml are migrated, or ~/.config/ddx/config.yaml with --global.
+
+Examples:
+  ddx config migrate --dry-run       # Show what would change
+  ddx config migrate                 # Migrate, keeping .bak copies
+  ddx config migrate --global`,
+		RunE: f.runConfigMigrate,
+	}
+	cmd.Flags().Bool("dry-run", false, "Show the changes without writing them")
+	cmd.Flags().Bool("global", false, "Migrate the global configuration")
+	// Migrating used to need --write; it is now the default
+	cmd.Flags().Bool("write", false, "Save the migrated configuration")
+	_ = cmd.Flags().MarkDeprecated("write", "files are written unless --dry-run is given")
+	return cmd
+}
+
 // newWorkflowCommand creates a fresh workflow command
 func (f *CommandFactory) newWorkflowCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -246,10 +743,111 @@ Workflows provide:
 Examples:
   ddx workflow status           # Show current workflow state
   ddx workflow list             # List available workflows
+  ddx workflow create review-cycle --phases draft,review,publish
+  ddx workflow lint [name]      # Check workflow.yml files against the schema
+  ddx workflow bindings [name]  # Show the personas each role uses in a workflow
+  ddx workflow run <name> [pipeline] --json  # Run a pipeline through the agent
   ddx workflow activate helix   # Activate HELIX workflow
-  ddx workflow advance          # Move to next phase`,
-		RunE: f.runWorkflow,
+  ddx workflow advance [name]   # Move to next phase
+  ddx workflow report [name]    # Show phases and who did what, when
+  ddx workflow helix execute <command> --var name=value
+  ddx workflow helix artifact <artifact>     # Generate a phase's document
+
+Persona bindings can be replaced for one workflow in .ddx/config.yaml:
+
+  overrides:
+    performance-workflow:
+      test-engineer: test-engineer-bdd
+
+'execute' lists the effective bindings and includes the personas bound to
+the role the current phase requires; 'workflow bindings' shows them.
+
+Values given with --var fill {{name}} placeholders and are recorded in
+.ddx/variables.yml as defaults for later runs.
+
+Instead of printing it, 'execute' can send the prompt, with its arguments
+and personas, straight to an AI tool; the options combine:
+
+  ddx workflow helix execute build-story US-001 --copy
+  ddx workflow helix execute build-story US-001 --out prompt.md
+  ddx workflow helix execute build-story US-001 --exec "claude -p"
+
+--exec runs the command through the shell with the prompt on stdin.
+
+A command can declare its arguments in YAML frontmatter at the top of its
+Markdown file; 'execute' checks them and shows the usage when they do not
+fit, 'commands' lists them and shell completion offers them:
+
+  ---
+  description: Implement a user story
+  args:
+    - name: story-id
+      description: The story to build
+      required: true
+      pattern: US-[0-9]{3,}
+  ---
+
+A pattern must match the whole argument; 'values' lists the only accepted
+values instead. Commands without frontmatter accept any arguments.
+
+Library files a command links to, as [text](../phases/04-build/README.md),
+or lists under 'references' in its frontmatter are looked up relative to
+the command, the workflow and the library, and appended to the prompt as
+--refs asks: 'list' (the default) names them, 'inline' includes their
+contents up to --refs-limit bytes, 'json' prints only the list and 'none'
+leaves them out:
+
+  ddx workflow helix execute build-story US-001 --refs inline
+  ddx workflow helix execute build-story US-001 --refs json
+
+Executed commands and phase transitions are appended to the workflow's
+state file (.<name>-state.yml) with the git user, the time and the
+arguments, so 'workflow report' can show how a cycle progressed.
+
+'artifact' writes the stub of a document a phase declares, its template
+rendered with --var values, to docs/<workflow>/<artifact>.md or the
+artifact's path; workflows.docs_path changes the directory. The prompt
+completing it comes with the personas bound to the role that writes it
+and can be sent with --copy, --out and --exec. Generated artifacts are
+recorded in the workflow state; --force overwrites an existing document.
+
+'workflow create' generates a new workflow in the project library:
+workflow.yml with the given phases, a commands/ directory with a starter
+command, a README and a doc per phase. The result is validated and can be
+listed, activated and executed immediately.
+
+While a workflow is active, CLAUDE.md and the other AI target files have
+a managed <workflow>-phase section with the current phase's principles
+and exit criteria; activate, advance and deactivate keep it up to date.
+
+'workflow lint' validates workflow.yml against the workflow schema and
+reports unknown fields, prompt and template files that do not exist,
+roles no persona declares or the project binds, and active workflows
+missing from the library.
+
+'workflow run' runs a pipeline without prompting, for CI. Pipelines are
+named lists of commands in workflow.yml:
+
+  pipelines:
+    default:
+      - command: start
+      - command: review
+        args: [--strict]
+
+Without a 'default' pipeline the declared commands run in order. Each
+step's prompt is sent to the configured agent; prompts, output and a
+summary.json are saved under .ddx/runs/<timestamp>/. The run stops at the
+first failing step and exits with its exit code.`,
+		RunE:              f.runWorkflow,
+		ValidArgsFunction: f.completeWorkflow,
 	}
+	addVariableFlags(cmd)
+	cmd.Flags().StringSlice("phases", nil, "With create, phase IDs in order (default plan,build,review)")
+	cmd.Flags().String("description", "", "With create, the workflow description")
+	cmd.Flags().Bool("json", false, "With run, print the run summary as JSON")
+	cmd.Flags().Bool("force", false, "With activate, force activation; with artifact, overwrite an existing document")
+	addExecuteOutputFlags(cmd)
+	addExecuteRefsFlags(cmd)
 
 	return cmd
 }
@@ -259,31 +857,701 @@ func (f *CommandFactory) runWorkflow(cmd *cobra.Command, args []string) error {
 	return runWorkflowWithDir(cmd, args, f.WorkingDir)
 }
 
+// newFetchCommand creates a fresh fetch command
+func (f *CommandFactory) newFetchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fetch [asset...]",
+		Short: "Download large binary assets referenced by the library",
+		Long: `Download large binary assets into the local asset cache.
+
+Large files are kept out of the library's git history. Instead the library
+holds a small pointer file named after the asset with a .ddx-asset suffix:
+
+  sha256: <64 hex characters>
+  url: https://example.com/assets/logo.psd
+  size: 1048576
+
+Assets are downloaded on demand into $XDG_CACHE_HOME/ddx/assets (or
+~/.cache/ddx/assets), stored by their SHA-256 and verified before use.
+Missing assets are downloaded in parallel and only added to the cache
+once every download has been verified, so a failed fetch stores nothing.
+Applying a template with 'ddx init' fetches the assets it needs automatically.
+
+Examples:
+  ddx fetch                                  # Fetch every asset in the library
+  ddx fetch templates/brand/logo.psd         # Fetch one asset
+  ddx fetch templates/brand/logo.psd -o logo.psd
+  ddx fetch --verify                         # Re-check cached copies`,
+		RunE: f.runFetch,
+	}
+	cmd.Flags().String("path", "", "Library path (defaults to the configured library)")
+	cmd.Flags().Bool("verify", false, "Re-hash cached assets and remove corrupt copies")
+	cmd.Flags().StringP("output", "o", "", "Copy the asset to this path")
+	cmd.Flags().IntP("jobs", "j", assets.DefaultWorkers, "Number of parallel downloads")
+	return cmd
+}
+
+// newLockCommand creates a fresh lock command
+func (f *CommandFactory) newLockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Pin the library in ddx.lock",
+		Long: `Record the library applied to this project in ddx.lock.
+
+The lockfile holds the upstream commit of the last library sync and a
+SHA-256 for every library file. Commit it so teammates and CI can verify
+they use exactly the same prompts, personas and templates.
+
+'ddx update --frozen' and 'ddx lock --check' fail when the library does
+not match the lockfile, and 'ddx doctor' reports the drift. A regular
+'ddx update' refreshes an existing lockfile.
+
+Examples:
+  ddx lock           # Write ddx.lock for the current library
+  ddx lock --check   # Fail when the library drifted from ddx.lock`,
+		Args: cobra.NoArgs,
+		RunE: f.runLock,
+	}
+	cmd.Flags().Bool("check", false, "Verify the library against ddx.lock instead of writing it")
+	return cmd
+}
+
+// newTelemetryCommand creates a fresh telemetry command
+func (f *CommandFactory) newTelemetryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "Manage anonymous usage metrics",
+		Long: `Manage anonymous usage metrics. Telemetry is off until you enable it.
+
+When enabled, ddx counts how often each command runs and the category of
+any error (network, auth, not_found, permission, config, usage or other).
+Counts are kept in ~/.cache/ddx/telemetry.json and posted once a day to
+telemetry.endpoint, if one is set, with the ddx version and platform.
+Arguments, error messages, paths, tokens and repository URLs are never
+recorded.
+
+Examples:
+  ddx telemetry status          # Show the setting and what is pending
+  ddx telemetry status --json   # Show exactly what would be sent
+  ddx telemetry enable --endpoint https://metrics.example.com/ddx
+  ddx telemetry disable         # Turn off and delete unsent metrics`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether telemetry is enabled and what is pending",
+		Args:  cobra.NoArgs,
+		RunE:  f.runTelemetryStatus,
+	}
+	statusCmd.Flags().Bool("json", false, "Print the pending batch exactly as it would be sent")
+	enableCmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Turn on anonymous usage metrics",
+		Args:  cobra.NoArgs,
+		RunE:  f.runTelemetryEnable,
+	}
+	enableCmd.Flags().String("endpoint", "", "URL metric batches are posted to")
+	cmd.AddCommand(statusCmd, enableCmd, &cobra.Command{
+		Use:   "disable",
+		Short: "Turn off usage metrics and delete unsent ones",
+		Args:  cobra.NoArgs,
+		RunE:  f.runTelemetryDisable,
+	})
+	return cmd
+}
+
+// newBrowseCommand creates a fresh browse command
+func (f *CommandFactory) newBrowseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "browse",
+		Short: "Browse library assets in the terminal",
+		Long: `Browse the personas, prompts, templates, workflows and MCP servers of
+the project library in a full-screen terminal view, preview them and act
+on them without remembering the subcommands.
+
+Keys:
+  ↑/↓ or j/k    Move, or scroll a preview
+  enter or →    Open a category or preview an asset
+  esc or ←      Go back
+  a             Act on the selected asset, after confirming with y:
+                load a persona, copy a prompt to the clipboard, apply a
+                template, activate a workflow or install an MCP server
+  q             Quit`,
+		Args: cobra.NoArgs,
+		RunE: f.runBrowse,
+	}
+}
+
+// newTemplatesCommand creates a fresh templates command
+func (f *CommandFactory) newTemplatesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "templates",
+		Short:   "Manage project templates",
+		Aliases: []string{"template"},
+		Long: `Browse and apply project templates from the library.
+
+A template is a directory under templates/ in a library with a
+template.yml manifest naming it and the variables its files use. Templates
+come from every library source; when several sources have a template of
+the same name the highest priority one is used, and a name such as
+internal:service picks one source.
+
+Examples:
+  ddx templates list                 # Templates with their variables
+  ddx templates show service         # Files and variables of a template
+  ddx templates apply service --dry-run`,
+	}
+	cmd.AddCommand(f.newTemplatesListCommand())
+	cmd.AddCommand(f.newTemplateShowCommand())
+	cmd.AddCommand(f.newTemplateApplyCommand())
+	return cmd
+}
+
+// newTemplatesListCommand creates a fresh templates list command
+func (f *CommandFactory) newTemplatesListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the templates in the project's libraries",
+		Aliases: []string{"ls"},
+		Long: `List the templates of every library source with their descriptions and
+variables. Variables marked * are required and have no default.`,
+		Args: cobra.NoArgs,
+		RunE: f.runTemplatesList,
+	}
+	cmd.Flags().Bool("json", false, "Output as JSON")
+	return cmd
+}
+
+// newTemplateShowCommand creates a fresh templates show command
+func (f *CommandFactory) newTemplateShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <template>",
+		Short: "Show the files and variables of a template",
+		Long: `Show what a template creates before applying it: the tree of its files,
+with {{name}} placeholders as written, and its variables. Required
+variables already set in .ddx/variables.yml are marked with their value;
+the rest must be given with --var when applying. Tool requirements and
+post-apply hooks are listed too.
+
+Examples:
+  ddx templates show service
+  ddx templates show internal:service --json`,
+		Args:              cobra.ExactArgs(1),
+		RunE:              f.runTemplateShow,
+		ValidArgsFunction: f.completeTemplates,
+	}
+	cmd.Flags().Bool("json", false, "Output as JSON")
+	return cmd
+}
+
+// newTemplateApplyCommand creates a fresh templates apply command
+func (f *CommandFactory) newTemplateApplyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply <template>",
+		Short: "Apply a library template to the project",
+		Long: `Copy a library template into the project, filling its {{name}}
+placeholders from --var and .ddx/variables.yml, and run its post-apply
+hooks.
+
+Files that already exist are kept unless --force is given. With --dry-run
+the template is rendered in memory and each file is listed as created,
+modified or skipped without writing anything; add --diff to see the
+changes to each file against the working tree.
+
+Examples:
+  ddx templates apply service --dry-run --diff  # Review before writing
+  ddx templates apply service --var project_name=demo
+  ddx templates apply service --force           # Overwrite changed files`,
+		Args:              cobra.ExactArgs(1),
+		RunE:              f.runTemplateApply,
+		ValidArgsFunction: f.completeTemplates,
+	}
+	cmd.Flags().Bool("dry-run", false, "Show the changes without writing them")
+	cmd.Flags().Bool("diff", false, "Show a diff of each created or modified file")
+	cmd.Flags().Bool("force", false, "Overwrite existing files that differ from the template")
+	addVariableFlags(cmd)
+	return cmd
+}
+
+// newPatternsCommand creates a fresh patterns command
+func (f *CommandFactory) newPatternsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "patterns",
+		Short:   "Apply reusable code patterns",
+		Aliases: []string{"pattern"},
+		Long: `Apply reusable code patterns from the library to the project.
+
+A pattern is a directory with a pattern.yml manifest. Files under its
+files/ directory are created in the project, and each insert in the
+manifest adds a snippet to an existing file below an anchor comment:
+
+  // ddx:anchor routes
+
+Inserted snippets are wrapped in 'ddx:pattern <name> begin/end' comments
+in the anchor's comment style. Applied patterns are recorded in
+.ddx/patterns.yml so they can be listed and removed.`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List library patterns, marking those applied",
+		Args:  cobra.NoArgs,
+		RunE:  f.runPatternsList,
+	})
+
+	applyCmd := &cobra.Command{
+		Use:   "apply <pattern>",
+		Short: "Apply a pattern to the project",
+		Long: `Apply a library pattern to the project.
+
+Placeholders such as {{route}} are filled from --var and
+.ddx/variables.yml. Existing files are kept, and nothing is written unless
+every anchor the pattern needs is found.
+
+Examples:
+  ddx patterns apply health-check
+  ddx patterns apply health-check --var route=/status`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runPatternApply,
+	}
+	addVariableFlags(applyCmd)
+	cmd.AddCommand(applyCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "remove <pattern>",
+		Short: "Remove an applied pattern",
+		Long: `Remove an applied pattern: its snippets are taken out of the files they
+were inserted into, and the files it created are deleted unless they were
+edited since.`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runPatternRemove,
+	})
+	return cmd
+}
+
+// newHooksCommand creates a fresh hooks command
+func (f *CommandFactory) newHooksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "hooks",
+		Short:   "Manage git hooks from the library",
+		Aliases: []string{"hook"},
+		Long: `Install git hooks from the library, such as commit message conventions
+or a test run before pushing.
+
+A library hook is a directory under hooks/ with a hook.yml manifest naming
+the git event it runs on and its script (default: hook.sh). Installed hooks
+are copied into the repository's hooks directory and run by a dispatcher
+for their event. A hook that was already there is kept as
+<event>.pre-ddx and still runs first.
+
+Hooks are enabled or disabled per project in the config:
+
+  hooks:
+    conventional-commits: true
+    pre-push-tests: false`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List library hooks, marking those installed",
+		Args:  cobra.NoArgs,
+		RunE:  f.runHooksList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "install [hook...]",
+		Short: "Install git hooks",
+		Long: `Install the named hooks and enable them in the config. Without names,
+install every hook the config enables and remove those it disables.
+
+Examples:
+  ddx hooks install conventional-commits
+  ddx hooks install`,
+		RunE: f.runHooksInstall,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "uninstall [hook...]",
+		Short: "Uninstall git hooks",
+		Long: `Uninstall the named hooks, or every installed library hook, and disable
+them in the config. A hook that was in place before ddx is restored once
+the last library hook for its event is removed.`,
+		RunE: f.runHooksUninstall,
+	})
+	return cmd
+}
+
+// newExecCommand creates a fresh exec command
+func (f *CommandFactory) newExecCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [script] [args...]",
+		Short: "Run a helper script from the library",
+		Long: `Run a helper script from the library's scripts directory in the project.
+
+Without arguments, exec lists the scripts and whether they may run. A
+script runs only when its name matches exec.allow in .ddx/config.yaml:
+
+  exec:
+    allow: [lint, release-*]
+    env: [GOFLAGS]          # Variables passed through besides PATH, HOME...
+
+Scripts run in the project directory with a reduced environment: PATH,
+HOME, USER, SHELL, TERM, LANG, LC_ALL, TMPDIR, TZ and the variables listed
+in exec.env, plus DDX_PROJECT_DIR, DDX_LIBRARY_PATH, DDX_SCRIPT,
+DDX_SCRIPT_DIR and DDX_VERSION.
+
+The first time a script runs, and whenever its content changes, ddx shows
+its path and SHA-256 and asks before running it. Approvals are kept in
+~/.config/ddx/trusted-scripts.json. Use --yes to approve without asking, as
+non-interactive runs must. Flags after the script name are passed to it.
+
+Examples:
+  ddx exec                      # List the library's scripts
+  ddx exec lint --fix           # Run scripts/lint.sh with --fix
+  ddx exec --yes release-notes  # Approve and run in CI`,
+		RunE: f.runExec,
+	}
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().Bool("yes", false, "Approve a new or changed script without asking")
+	return cmd
+}
+
+// newMetaPromptCommand creates a fresh metaprompt command
+func (f *CommandFactory) newMetaPromptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "metaprompt",
+		Short:   "Choose the meta-prompt injected into AI instruction files",
+		Aliases: []string{"meta-prompt"},
+		Long: `Choose the meta-prompt injected into CLAUDE.md and the other configured
+AI target files.
+
+Meta-prompts are the markdown files in the library's
+prompts/<assistant>/system-prompts directories. The selection is stored as
+system.meta_prompt in .ddx/config.yaml and the target files are re-synced
+straight away.
+
+Examples:
+  ddx metaprompt list          # List meta-prompts, marking the one in use
+  ddx metaprompt set strict    # Use claude/system-prompts/strict.md
+  ddx metaprompt disable       # Stop injecting a meta-prompt`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the library's meta-prompts",
+		Args:  cobra.NoArgs,
+		RunE:  f.runMetaPromptList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <name|path>",
+		Short: "Select a meta-prompt and sync it into the AI target files",
+		Long: `Select a meta-prompt by name, such as "strict", or by its path below the
+library's prompts directory, such as "claude/system-prompts/strict.md".`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runMetaPromptSet,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "disable",
+		Short: "Disable the meta-prompt and remove it from the AI target files",
+		Args:  cobra.NoArgs,
+		RunE:  f.runMetaPromptDisable,
+	})
+	return cmd
+}
+
+// newWorkspaceCommand creates a fresh workspace command
+func (f *CommandFactory) newWorkspaceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Work across the DDx projects of a monorepo",
+		Long: `Work across the DDx projects of a monorepo.
+
+Each service of a monorepo can be its own DDx project, with its own
+.ddx/config.yaml, persona bindings and workflows. The repository root lists
+them in .ddx/workspace.yaml, as paths or globs relative to the root:
+
+  projects:
+    - services/*
+    - tools/cli
+
+Commands work on the nearest project at or above the current directory.
+The global --project flag selects another one, by directory or by its
+path in the workspace:
+
+  ddx --project services/api persona status
+  ddx --project web workflow status
+
+Examples:
+  ddx workspace list      # List the projects, marking the current one
+  ddx workspace status    # Config, personas and workflows of each project`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the workspace's projects",
+		Args:  cobra.NoArgs,
+		RunE:  f.runWorkspaceList,
+	}
+	listCmd.Flags().String("format", "", "Go template applied to each project, e.g. '{{.Path}}'")
+	cmd.AddCommand(listCmd)
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of each project in the workspace",
+		Args:  cobra.NoArgs,
+		RunE:  f.runWorkspaceStatus,
+	}
+	statusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	cmd.AddCommand(statusCmd)
+	return cmd
+}
+
+// newHistoryCommand creates a fresh history command
+func (f *CommandFactory) newHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the ddx commands run in this project",
+		Long: `Show the ddx commands run in this project, oldest first.
+
+History is opt-in. Once enabled, every ddx invocation in the project is
+appended to .ddx/history.jsonl with its arguments, the active
+configuration profile, the git user and the result, which helps answer
+questions like "what changed my CLAUDE.md?". Token, password, secret and
+key values are not recorded.
+
+  ddx config set history.enabled true
+
+Examples:
+  ddx history             # Show the last 20 commands
+  ddx history --limit 50  # Show the last 50 commands
+  ddx history --json      # Entries as JSON`,
+		Args:        cobra.NoArgs,
+		RunE:        f.runHistory,
+		Annotations: map[string]string{historyAnnotation: historySkip},
+	}
+	cmd.Flags().Int("limit", 20, "Number of entries to show (0 for all)")
+	cmd.Flags().Bool("json", false, "Output entries as JSON")
+	return cmd
+}
+
+// newWhichCommand creates a fresh which command
+func (f *CommandFactory) newWhichCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which <type>/<name> | <type> | <name>",
+		Short: "Show which file an asset resolves to",
+		Long: `Show which file an asset name resolves to and why.
+
+Assets are looked up in order, and the first match wins:
+  1. project override  .ddx/<type> (prompts only)
+  2. project library   library.path in .ddx/config.yaml
+  3. global library    ~/.local/share/ddx/library, when the project has no config
+
+Matches in later locations are listed as shadowed. Given only a type,
+which lists the directories searched for it; given a bare name, it
+looks in every type.
+
+Examples:
+  ddx which prompts/code-review     # Where the code-review prompt comes from
+  ddx which persona/strict-reviewer # Singular type names work too
+  ddx which prompts                 # Directories searched for prompts
+  ddx which helix --json            # Every asset named helix, as JSON`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runWhich,
+	}
+	cmd.Flags().Bool("json", false, "Output results as JSON")
+	return cmd
+}
+
+// newRollbackCommand creates a fresh rollback command
+func (f *CommandFactory) newRollbackCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the project to a snapshot taken before a change",
+		Long: `Restore the library, configuration and the managed sections of the
+AI target files recorded before an earlier change.
+
+A snapshot is taken before each 'ddx update' and 'ddx prompts inject'
+and kept in .ddx/snapshots, which is ignored by git. The last 10 are
+kept. Rolling back replaces the library, .ddx/config.yaml and ddx.lock,
+and the managed sections, such as personas and the meta-prompt, of every
+file in ai.targets; text you wrote outside them is left alone. The result is verified against the snapshot's checksums.
+
+Examples:
+  ddx rollback --list                    # Show available snapshots
+  ddx rollback                           # Undo the most recent change
+  ddx rollback --to 20261016-150405      # Restore a specific snapshot`,
+		Args: cobra.NoArgs,
+		RunE: f.runRollback,
+	}
+	cmd.Flags().Bool("list", false, "List available snapshots")
+	cmd.Flags().String("to", "", "Snapshot to restore (default: the most recent)")
+	return cmd
+}
+
+// newBackupCommand creates a fresh backup command
+func (f *CommandFactory) newBackupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "List and restore backups of CLAUDE.md and other AI files",
+		Aliases: []string{"backups"},
+		Long: `List and restore backups of CLAUDE.md and the other AI configuration
+files ddx writes.
+
+A backup of the whole file is made in .ddx/backups, which is ignored by
+git, before persona loading, prompt injection or the meta-prompt rewrites
+it. Unchanged files are not backed up twice. The last 20 backups are kept
+unless the config says otherwise:
+
+  backups:
+    keep: 50        # 0 keeps all
+    max_age: 720h   # also remove backups older than 30 days`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE:  f.runBackupList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore the files of a backup",
+		Long: `Restore the files of a backup. Their current content is backed up
+first, so a restore can itself be undone.
+
+Examples:
+  ddx backup list
+  ddx backup restore 20261016-150405`,
+		Args: cobra.ExactArgs(1),
+		RunE: f.runBackupRestore,
+	})
+	return cmd
+}
+
+// newSettingsCommand creates a fresh settings command
+func (f *CommandFactory) newSettingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Manage personal DDx settings",
+		Long: `Manage personal DDx settings kept in ~/.config/ddx.
+
+Use 'ddx settings sync' to keep your global configuration, including
+prompt aliases, consistent across machines.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync personal settings through a git repository",
+		Long: `Sync personal settings through a git repository you provide, such as
+a private repository or a gist.
+
+Settings are encrypted on this machine with a passphrase before they are
+pushed; the repository only holds ciphertext. The passphrase is asked
+for in a terminal or read from DDX_SETTINGS_PASSPHRASE. It is never
+stored, and settings cannot be recovered without it.
+
+Set the repository once in your global configuration:
+  ddx config set settings_sync.repository git@github.com:me/ddx-settings.git --global
+
+Examples:
+  ddx settings sync push    # Encrypt and push the global config
+  ddx settings sync pull    # Pull, decrypt and apply on another machine`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	syncCmd.PersistentFlags().String("repository", "", "Git repository to sync through (default: settings_sync.repository)")
+	syncCmd.PersistentFlags().String("branch", "", "Branch holding the settings (default: settings_sync.branch or main)")
+	syncCmd.AddCommand(&cobra.Command{
+		Use:   "push",
+		Short: "Encrypt and push personal settings",
+		Args:  cobra.NoArgs,
+		RunE:  f.runSettingsPush,
+	})
+	syncCmd.AddCommand(&cobra.Command{
+		Use:   "pull",
+		Short: "Pull, decrypt and apply personal settings",
+		Args:  cobra.NoArgs,
+		RunE:  f.runSettingsPull,
+	})
+	cmd.AddCommand(syncCmd)
+	return cmd
+}
+
 // newAgentCommand creates a fresh agent command
 func (f *CommandFactory) newAgentCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "agent",
-		Short: "Agent communication interface",
-		Long: `Interface for AI agent communication and workflow routing.
+		Short: "Send prompts to AI agent tools",
+		Long: `Interface for AI agent tools and workflow routing.
+
+Run prompts through an agent tool such as the Claude CLI, aider, llm or
+goose, selected with agents.preset in .ddx/config.yaml.
 
-This command is primarily used by AI assistants to:
+The hidden request subcommand is used by AI assistants to:
 • Detect workflow triggers in user messages
 • Route requests to appropriate workflows
 • Provide structured output for agent parsing
 
 Examples:
+  ddx agent presets                 # List supported agent tools
+  ddx agent run code-review/strict  # Send a library prompt to the agent
   ddx agent request add pagination  # Trigger workflow based on message`,
-		Hidden: true, // Hidden from normal users, for agent use
 	}
 
-	// Add request subcommand
+	// Add request subcommand; it is meant for agents, not users
 	requestCmd := &cobra.Command{
-		Use:   "request [message...]",
-		Short: "Process agent request with workflow routing",
-		RunE:  f.runAgentRequest,
+		Use:    "request [message...]",
+		Short:  "Process agent request with workflow routing",
+		Hidden: true,
+		RunE:   f.runAgentRequest,
 	}
 	cmd.AddCommand(requestCmd)
 
+	presetsCmd := &cobra.Command{
+		Use:   "presets",
+		Short: "List built-in agent presets",
+		Long: `List the agent tools DDx knows how to invoke.
+
+Each preset captures the tool's argument format, how it receives the prompt
+(stdin, an argument or a temporary file) and its model selection flag. The
+configured preset is marked with *.`,
+		Args: cobra.NoArgs,
+		RunE: f.runAgentPresets,
+	}
+	presetsCmd.Flags().BoolP("verbose", "v", false, "Show notes on each tool's invocation quirks")
+	cmd.AddCommand(presetsCmd)
+
+	runCmd := &cobra.Command{
+		Use:   "run <prompt|file|-> [instructions...]",
+		Short: "Send a prompt to the configured agent",
+		Long: `Render a prompt and send it to the configured agent tool.
+
+The prompt is a library prompt name (as for 'ddx prompts show'), a file in
+the project, or - to read it from stdin. Extra arguments are appended to the
+prompt as instructions. {{name}} placeholders are filled from --var and
+.ddx/variables.yml.
+
+The agent comes from the agents section of .ddx/config.yaml:
+
+  agents:
+    preset: aider      # claude, aider, llm or goose
+    model: gpt-4o      # passed with the preset's model flag
+    args: [--no-auto-commits]
+
+Examples:
+  ddx agent run code-review/strict
+  ddx agent run refactor "focus on cmd/"
+  git diff | ddx agent run - --preset llm --model gpt-4o-mini
+  ddx agent run code-review/strict --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: f.runAgentRun,
+	}
+	runCmd.Flags().String("preset", "", "Agent preset to use instead of the configured agent")
+	runCmd.Flags().String("model", "", "Model to request from the agent")
+	runCmd.Flags().Bool("dry-run", false, "Show the agent command line without running it")
+	addVariableFlags(runCmd)
+	cmd.AddCommand(runCmd)
+
 	return cmd
 }
 
@@ -305,18 +1573,70 @@ Personas provide:
 • Reusable personality templates
 • Project-specific persona bindings
 
+Roles are checked when binding. A role that no persona, workflow or
+existing binding uses is bound with a warning; one that looks like a
+misspelling of a known role is refused unless --new-role is given.
+
+'ddx persona roles' lists every role with the personas declaring it and
+the workflows requiring it, flagging required roles with no binding;
+--suggest binds the persona whose tags best match each of them.
+
+A role can be bound to several personas with --add, or by listing them in
+.ddx/config.yaml. They are loaded in the order listed, each under its own
+header.
+
+'ddx persona bind --file bindings.yaml' binds every role in a YAML file
+mapping roles to a persona or a list of personas, in one write; nothing is
+bound when a persona is missing or a role fails the check above.
+'ddx persona bindings --unbind <role>' removes a binding, warning when a
+workflow phase requires the role.
+
+Personas in the project's .ddx/personas are merged with the library's and
+replace library personas of the same name. Personas from the sources in
+library.sources are merged too, the higher priority source winning a name;
+name one in a particular source as <source>:<name>. 'ddx persona list' and
+'ddx persona show' label each persona's source as project, library or the
+source's name.
+
+Each load is recorded in .ddx/persona-usage.json, which stays on the
+machine. 'ddx persona status --stats' shows when each persona was last
+loaded, whether the library has changed it since, and bindings to personas
+that no longer exist.
+
 Examples:
   ddx persona --list              # List available personas
   ddx persona --show reviewer     # Show persona details
-  ddx persona --bind strict-reviewer --role code-reviewer`,
+  ddx persona show reviewer --raw | pbcopy  # Print only the persona body
+  ddx persona show - < draft.md   # Validate and preview a persona from stdin
+  ddx persona --bind strict-reviewer --role code-reviewer
+  ddx persona bind release-manager careful-releaser --new-role
+  ddx persona bind code-reviewer security-analyst --add
+  ddx persona bind --file bindings.yaml  # Bind several roles at once
+  ddx persona bindings --unbind architect  # Remove a role's binding
+  ddx persona roles --suggest     # Bind personas to unbound workflow roles
+  ddx persona diff strict-reviewer  # Compare injected text with the library
+  ddx persona load --dry-run        # Preview a reload as a diff
+  ddx persona status --stats        # Show when personas were loaded
+  ddx persona list --format '{{.Name}}\t{{join .Roles ","}}'`,
 		RunE: f.runPersona,
 	}
 
 	cmd.Flags().Bool("list", false, "List available personas")
 	cmd.Flags().String("show", "", "Show details of a specific persona")
+	cmd.Flags().Bool("raw", false, "With show, print only the persona body, without frontmatter or formatting")
 	cmd.Flags().String("bind", "", "Bind a persona to a role")
 	cmd.Flags().String("role", "", "Role to bind persona to or filter by")
+	cmd.Flags().Bool("new-role", false, "Allow binding a role that looks like a typo of a known role")
+	cmd.Flags().Bool("add", false, "Add the persona to the role's personas instead of replacing them")
+	cmd.Flags().String("file", "", "With bind, bind the roles in a YAML file of role: persona pairs ('-' reads stdin)")
+	cmd.Flags().String("unbind", "", "With bindings, remove the binding of a role")
 	cmd.Flags().String("tag", "", "Filter personas by tag")
+	cmd.Flags().String("format", "", "Format each listed persona with a Go template")
+	cmd.Flags().Bool("refresh", false, "Rebuild the library index before listing")
+	cmd.Flags().Bool("suggest", false, "With roles, bind the best-matching persona to each unbound role")
+	cmd.Flags().Bool("dry-run", false, "With load, show the changes as a diff without writing them; with roles --suggest, show the bindings without making them")
+	cmd.Flags().Bool("stats", false, "With status, show when personas were loaded and which are stale or orphaned")
+	_ = cmd.RegisterFlagCompletionFunc("unbind", f.completeBoundRoles)
 
 	return cmd
 }
@@ -334,23 +1654,44 @@ MCP servers provide:
 • Custom data sources and APIs
 • Enhanced context awareness
 
+Install prompts for required settings when run interactively. Secret values
+are kept in the DDx credential store and referenced from the client config
+as secret://mcp/<server>/<NAME>; servers are launched through 'ddx mcp exec'
+to resolve them.
+
+Status checks every server configured for the project and Claude Desktop:
+the command and any npx package must be present, ${NAME} placeholders set
+and stored secrets available. --check also starts each server and performs
+an MCP initialize handshake. Status exits non-zero when a server is broken.
+
 Examples:
   ddx mcp --list                  # List available MCP servers
-  ddx mcp --install github        # Install GitHub MCP server
-  ddx mcp --status                # Show installed servers`,
+  ddx mcp --install github        # Install GitHub MCP server for Claude Code
+  ddx mcp install github --client both   # Configure Claude Desktop and Claude Code
+  ddx mcp exec -- <server command>       # Run a server with its stored secrets
+  ddx mcp status                  # Health-check configured servers
+  ddx mcp status github --check   # Start the server and perform a handshake
+  ddx mcp status --json           # Machine-readable health report
+  ddx mcp validate                # Check the library registry definitions
+  ddx mcp list --format '{{.Name}}\t{{.Version}}'`,
 		RunE: f.runMCP,
 	}
 
 	cmd.Flags().Bool("list", false, "List available MCP servers")
 	cmd.Flags().String("install", "", "Install an MCP server")
-	cmd.Flags().Bool("status", false, "Show status of installed servers")
+	cmd.Flags().Bool("status", false, "Show health of configured servers")
+	cmd.Flags().Bool("check", false, "Start servers and perform an MCP handshake when checking status")
+	cmd.Flags().Bool("json", false, "Output status as JSON")
 	cmd.Flags().String("category", "", "Filter by category")
 	cmd.Flags().String("search", "", "Search for servers")
 	cmd.Flags().Bool("verbose", false, "Show detailed information")
 	cmd.Flags().StringSlice("env", []string{}, "Environment variables for server")
 	cmd.Flags().String("config-path", "", "Path to Claude config file")
+	cmd.Flags().String("client", "claude-code", "MCP client to configure: claude-code, claude-desktop or both")
+	cmd.Flags().Bool("no-secret-store", false, "Write secret values to the client config instead of the credential store")
 	cmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
 	cmd.Flags().Bool("yes", false, "Skip confirmation prompts")
+	cmd.Flags().String("format", "", "Format each listed server with a Go template")
 
 	return cmd
 }
@@ -388,20 +1729,187 @@ func (f *CommandFactory) newPromptsListCommand() *cobra.Command {
 			return runPromptsList(cmd, args)
 		},
 	}
-	cmd.Flags().String("search", "", "Search for prompts containing this text")
+	cmd.Flags().String("search", "", "Search for prompts by path (substring, glob, or re:<regex>)")
 	return cmd
 }
 
 // newPromptsShowCommand creates the prompts show subcommand
 func (f *CommandFactory) newPromptsShowCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "show <prompt-name>",
 		Short: "Show a specific prompt",
-		Args:  cobra.ExactArgs(1),
+		Long: `Show a prompt with its {{> name}} includes expanded and its {{name}}
+placeholders filled in.
+
+Values come from --var and from .ddx/variables.yml, where values given
+with --var are recorded for reuse. Variables that look like secrets are
+never recorded.
+
+Prompts in the project's .ddx/prompts directory override library
+prompts of the same name.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptsShow(cmd, args, f.WorkingDir)
+		},
+	}
+	addVariableFlags(cmd)
+	return cmd
+}
+
+// newPromptsRenderCommand creates the prompts render subcommand
+func (f *CommandFactory) newPromptsRenderCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render <prompt>",
+		Short: "Preview a prompt with its includes expanded",
+		Long: `Preview a prompt as it is injected or shown, with every {{> name}}
+include replaced by the prompt it names and {{name}} placeholders filled in.
+
+Includes are resolved like prompt names, project overrides in .ddx/prompts
+first, and may themselves include other prompts. A prompt that ends up
+including itself is reported as an include cycle. The prompt may also be
+given as a file path.
+
+Examples:
+  ddx prompts render claude/code-review
+  ddx prompts render claude/code-review --includes  # Show the include tree
+  ddx prompts render ./draft.md --var language=go`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPromptsShow(cmd, args)
+			return runPromptsRender(cmd, args, f.WorkingDir)
 		},
 	}
+	cmd.Flags().Bool("includes", false, "List the prompts included, recursively, instead of rendering")
+	addVariableFlags(cmd)
+	return cmd
+}
+
+// newPromptInjectCommand creates the prompts inject subcommand
+func (f *CommandFactory) newPromptInjectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inject [prompt...]",
+		Short: "Sync library prompts into CLAUDE.md or another AI config file",
+		Long: `Write library prompts into managed sections of each AI target file
+configured under ai.targets (CLAUDE.md by default), or of the file given
+with --file, such as AGENTS.md.
+
+Each section is named after its prompt (override with --name) and marked
+with its source, its order and a checksum of its content:
+
+  <!-- DDX:START name="review" source="common/review.md" order="50" checksum="..." -->
+  ...
+  <!-- DDX:END name="review" -->
+
+Sections are written after the rest of the file, sorted by --order and
+then by name, so repeated runs give the same file. Placeholders are
+filled from .ddx/variables.yml. A section whose content was edited by
+hand is not overwritten without --force.
+
+Without arguments, every managed section is refreshed from its source.
+
+Examples:
+  ddx prompt inject common/review.md
+  ddx prompt inject claude/system-prompts/focused.md --name focus --order 10
+  ddx prompt inject common/review.md --file AGENTS.md
+  ddx prompt inject                       # Refresh all sections`,
+		RunE: f.runPromptInject,
+	}
+	cmd.Flags().String("file", "", "File to write the sections into, relative to the project (default: the ai.targets files)")
+	cmd.Flags().String("name", "", "Section name (default: the prompt's file name)")
+	cmd.Flags().Int("order", sections.DefaultOrder, "Position of the section; lower orders come first")
+	cmd.Flags().Bool("force", false, "Overwrite sections that were edited by hand")
+	return cmd
+}
+
+// newPromptRemoveCommand creates the prompts remove subcommand
+func (f *CommandFactory) newPromptRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name...>",
+		Short: "Remove managed prompt sections",
+		Long: `Remove sections written by 'ddx prompt inject', leaving the rest of the
+file untouched.
+
+Examples:
+  ddx prompt remove review
+  ddx prompt remove review --file AGENTS.md`,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              f.runPromptRemove,
+		ValidArgsFunction: f.completePromptSections,
+	}
+	cmd.Flags().String("file", "", "File to remove the sections from, relative to the project (default: the ai.targets files)")
+	return cmd
+}
+
+// newPromptSyncCommand creates the prompts sync subcommand
+func (f *CommandFactory) newPromptSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [prompt|workflow:command...]",
+		Short: "Sync library prompts into tool-specific prompt files such as .claude/commands",
+		Long: `Write library prompts and workflow commands as files of their own, by
+default into .claude/commands where Claude Code offers each as a project
+slash command.
+
+A prompt is synced to <name>.md, named after the prompt (override with
+--name); a workflow command, given as workflow:command, to
+<workflow>/<command>.md. Frontmatter is adapted for the tool: the
+description comes from the source's frontmatter or first heading, and a
+workflow command's declared arguments become its argument-hint.
+Placeholders are filled from .ddx/variables.yml.
+
+The synced files are recorded in .ddx/prompt-sync.json. Without arguments
+every recorded file is refreshed from its source, as 'ddx update' also
+does. Files edited by hand are not overwritten, or removed, without
+--force. With --remove the files named by source, path or command name
+are deleted, or every synced file without arguments.
+
+Examples:
+  ddx prompts sync common/review.md
+  ddx prompts sync helix:build-story     # /build-story in the helix namespace
+  ddx prompts sync                       # Refresh all synced files
+  ddx prompts sync --remove review
+  ddx prompts sync --remove              # Remove all synced files`,
+		RunE:              f.runPromptSync,
+		ValidArgsFunction: f.completeSyncedPrompts,
+	}
+	cmd.Flags().String("dir", promptsync.DefaultDir, "Directory to write the files into, relative to the project")
+	cmd.Flags().String("name", "", "File name without .md (default: the prompt's name)")
+	cmd.Flags().Bool("force", false, "Overwrite or remove files that were edited by hand")
+	cmd.Flags().Bool("remove", false, "Remove synced files instead of writing them")
+	return cmd
+}
+
+// newPromptShortcutCommand creates the p command for prompt shortcuts
+func (f *CommandFactory) newPromptShortcutCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "p [alias]",
+		Short: "Show or copy a prompt by its project shortcut",
+		Long: `Show or copy a prompt by a shortcut defined in the project configuration.
+
+Shortcuts map names to prompt files, relative to the library or the
+project:
+
+  prompts:
+    review: prompts/code-review/strict.md
+    plan: docs/prompts/planning.md
+
+{{name}} placeholders are filled from --var and .ddx/variables.yml as for
+'ddx prompts show'. Names that are not shortcuts are looked up as library
+prompts. Without an alias the configured shortcuts are listed.
+
+Examples:
+  ddx p                                    # List shortcuts
+  ddx p review                             # Print the review prompt
+  ddx p review --copy                      # Copy it to the clipboard
+  ddx config set prompts.review prompts/code-review/strict.md`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completePromptShortcuts(f.WorkingDir),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptShortcut(cmd, args, f.WorkingDir)
+		},
+	}
+	cmd.Flags().BoolP("copy", "c", false, "Copy the rendered prompt to the clipboard")
+	addVariableFlags(cmd)
+	_ = cmd.RegisterFlagCompletionFunc("var", completePromptVariables(f.WorkingDir))
+	return cmd
 }
 
 // newStatusCommand creates a fresh status command
@@ -414,6 +1922,9 @@ func (f *CommandFactory) newStatusCommand() *cobra.Command {
 This command displays:
 - Current DDX version and commit hash
 - Last update timestamp
+- Project overview: configuration and profile, library commit and drift
+  from ddx.lock, loaded personas, active workflow phases, installed MCP
+  servers and library changes waiting to be contributed
 - Local modifications to DDX resources
 - Available upstream updates
 - Change history and differences
@@ -424,7 +1935,8 @@ Examples:
   ddx status --check-upstream         # Check for updates
   ddx status --changes                # List changed files
   ddx status --diff                   # Show differences
-  ddx status --export manifest.yml    # Export version manifest`,
+  ddx status --export manifest.yml    # Export version manifest
+  ddx status --json                   # Print the report as JSON`,
 		RunE: f.runStatus,
 	}
 
@@ -432,6 +1944,7 @@ Examples:
 	cmd.Flags().Bool("changes", false, "Show list of changed files")
 	cmd.Flags().Bool("diff", false, "Show differences between versions")
 	cmd.Flags().String("export", "", "Export version manifest to file")
+	cmd.Flags().Bool("json", false, "Print the status report as JSON")
 
 	return cmd
 }
@@ -465,3 +1978,23 @@ Examples:
 
 	return cmd
 }
+
+// newContractsCommand creates a fresh contracts command
+func (f *CommandFactory) newContractsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "contracts",
+		Short: "Print the CLI's exit codes, commands and flags as JSON",
+		Long: `Print the command line contract as JSON: every exit code with its name and
+meaning, and every command with the flags it defines. Flags a command
+defines for its subcommands as well are marked persistent.
+
+The output is meant for documentation generators and test harnesses that
+check ddx against its contract.
+
+Examples:
+  ddx contracts                                     # The whole contract
+  ddx contracts | jq '.exit_codes[] | "\(.code) \(.name)"'`,
+		Args: cobra.NoArgs,
+		RunE: f.runContracts,
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
 	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/targets"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -48,7 +52,7 @@ func (f *CommandFactory) runConfig(cmd *cobra.Command, args []string) error {
 		if err := configReset(f.WorkingDir, globalFlag); err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Configuration reset to defaults: %s\n", configPath)
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Configuration reset to defaults: %s\n", configPath)
 		return nil
 	}
 
@@ -60,7 +64,7 @@ func (f *CommandFactory) runConfig(cmd *cobra.Command, args []string) error {
 		if err := configSave(f.WorkingDir, cfg, false); err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "✅ Configuration saved to .ddx/config.yaml")
+		_, _ = fmt.Fprintln(statusOut(cmd), "✅ Configuration saved to .ddx/config.yaml")
 		return nil
 	}
 
@@ -68,7 +72,7 @@ func (f *CommandFactory) runConfig(cmd *cobra.Command, args []string) error {
 		if err := configValidate(f.WorkingDir); err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "✅ Configuration is valid")
+		_, _ = fmt.Fprintln(statusOut(cmd), "✅ Configuration is valid")
 		return nil
 	}
 
@@ -91,34 +95,53 @@ func (f *CommandFactory) runConfig(cmd *cobra.Command, args []string) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), value)
 		return nil
 	case "set":
+		if secret, _ := cmd.Flags().GetBool("secret"); secret {
+			return configSetSecret(cmd, f.WorkingDir, args[1:], globalFlag)
+		}
 		if len(args) < 3 {
 			return fmt.Errorf("key and value required for set command")
 		}
 		if err := configSet(f.WorkingDir, args[1], args[2], globalFlag); err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Set %s = %s\n", args[1], args[2])
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Set %s = %s\n", args[1], args[2])
+		return nil
+	case "unset":
+		if len(args) < 2 {
+			return fmt.Errorf("key required for unset command")
+		}
+		if err := configUnset(f.WorkingDir, args[1], globalFlag); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Unset %s\n", args[1])
+		return nil
+	case "append":
+		if len(args) < 3 {
+			return fmt.Errorf("key and value required for append command")
+		}
+		if err := configAppend(f.WorkingDir, args[1], args[2], globalFlag); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Added %s to %s\n", args[2], args[1])
+		return nil
+	case "remove":
+		if len(args) < 3 {
+			return fmt.Errorf("key and value required for remove command")
+		}
+		if err := configRemove(f.WorkingDir, args[1], args[2], globalFlag); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Removed %s from %s\n", args[2], args[1])
 		return nil
 	case "validate":
 		if err := configValidate(f.WorkingDir); err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "✅ Configuration is valid")
+		_, _ = fmt.Fprintln(statusOut(cmd), "✅ Configuration is valid")
 		return nil
 	case "export":
 		// Simply output the config file content
-		var configPath string
-		if globalFlag {
-			// Use global config path
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("failed to get home directory: %w", err)
-			}
-			configPath = filepath.Join(homeDir, ".ddx", "config.yaml")
-		} else {
-			// Use local config path
-			configPath = filepath.Join(f.WorkingDir, ".ddx", "config.yaml")
-		}
+		configPath := configGetPath(f.WorkingDir, globalFlag)
 
 		content, err := os.ReadFile(configPath)
 		if err != nil {
@@ -126,6 +149,15 @@ func (f *CommandFactory) runConfig(cmd *cobra.Command, args []string) error {
 		}
 		_, _ = fmt.Fprint(cmd.OutOrStdout(), string(content))
 		return nil
+	case "effective":
+		return f.outputEffectiveConfig(cmd)
+	case "resolve":
+		return f.outputResolvedConfig(cmd, args[1:])
+	case "show":
+		if effective, _ := cmd.Flags().GetBool("effective"); !effective {
+			return fmt.Errorf("config show requires --effective - use 'cat .ddx/config.yaml' to view a single file")
+		}
+		return f.outputEffectiveConfig(cmd)
 	case "import":
 		// For now, just read from stdin
 		return fmt.Errorf("import not yet implemented")
@@ -148,12 +180,23 @@ func runConfig(cmd *cobra.Command, args []string) error {
 // Legacy functions - replaced by pure business logic functions above
 // Business Logic Layer - Pure Functions
 
-// configGet retrieves a configuration value
+// configGet retrieves a configuration value by dotted key. Project lookups
+// resolve the effective value across all layers; global lookups read only
+// the global file.
 func configGet(workingDir string, key string, global bool) (string, error) {
 	var cfg *config.Config
 	var err error
 
-	if workingDir != "" && !global {
+	if global {
+		configPath := configGetPath(workingDir, true)
+		cfg = config.DefaultNewConfig()
+		if _, statErr := os.Stat(configPath); statErr == nil {
+			cfg, err = config.LoadFromFile(configPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to load configuration from %s: %w", configPath, err)
+			}
+		}
+	} else if workingDir != "" {
 		// Load config from specific working directory using new format
 		cfg, err = config.LoadWithWorkingDir(workingDir)
 		if err != nil {
@@ -167,47 +210,37 @@ func configGet(workingDir string, key string, global bool) (string, error) {
 		}
 	}
 
-	return extractConfigValue(cfg, key)
+	return config.GetValue(cfg, key)
 }
 
-// configSet sets a configuration value
+// configSet sets a configuration value in the project or global file.
+// Only the target file is edited so values from other layers are not
+// copied into it, and existing comments are preserved.
 func configSet(workingDir string, key, value string, global bool) error {
-	var cfg *config.Config
-	var err error
+	return config.UpdateFile(configGetPath(workingDir, global), func(doc *yaml.Node) error {
+		return config.SetValue(doc, key, value)
+	})
+}
 
-	if workingDir != "" && !global {
-		// Load config from specific working directory using new format
-		cfg, err = config.LoadWithWorkingDir(workingDir)
-		if err != nil {
-			// If file doesn't exist in working dir, create a new config
-			if os.IsNotExist(err) {
-				cfg = &config.Config{
-					Version: "1.0",
-					Library: &config.LibraryConfig{
-						Path: ".ddx/library",
-						Repository: &config.RepositoryConfig{
-							URL:    "https://github.com/easel/ddx-library",
-							Branch: "main",
-						},
-					},
-				}
-			} else {
-				return fmt.Errorf("failed to load configuration from %s: %w", workingDir, err)
-			}
-		}
-	} else {
-		// Use standard config loading (current directory)
-		cfg, err = config.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load configuration: %w", err)
-		}
-	}
+// configUnset removes a configuration value from the project or global file
+func configUnset(workingDir string, key string, global bool) error {
+	return config.UpdateFile(configGetPath(workingDir, global), func(doc *yaml.Node) error {
+		return config.UnsetValue(doc, key)
+	})
+}
 
-	if err := setConfigValueInStruct(cfg, key, value); err != nil {
-		return err
-	}
+// configAppend adds an item to a list value such as workflows.active
+func configAppend(workingDir string, key, item string, global bool) error {
+	return config.UpdateFile(configGetPath(workingDir, global), func(doc *yaml.Node) error {
+		return config.AppendValue(doc, key, item)
+	})
+}
 
-	return configSave(workingDir, cfg, global)
+// configRemove removes an item from a list value
+func configRemove(workingDir string, key, item string, global bool) error {
+	return config.UpdateFile(configGetPath(workingDir, global), func(doc *yaml.Node) error {
+		return config.RemoveValue(doc, key, item)
+	})
 }
 
 // configValidate validates the configuration
@@ -226,6 +259,18 @@ func configValidate(workingDir string) error {
 	return cfg.Validate()
 }
 
+// configEffective resolves the layered configuration with source attribution
+func configEffective(workingDir string) ([]config.EffectiveValue, error) {
+	if workingDir == "" {
+		var err error
+		workingDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	return config.Effective(workingDir, config.ActiveProfile())
+}
+
 // configReset resets configuration to defaults
 func configReset(workingDir string, global bool) error {
 	cfg := config.DefaultConfig
@@ -260,23 +305,23 @@ func configListFiles(workingDir string) []ConfigFileInfo {
 		files = append(files, ConfigFileInfo{Path: localConfig, Type: "project", Exists: false})
 	}
 
+	// Local override file
+	localOverride := config.LocalConfigFile
+	if workingDir != "" {
+		localOverride = config.LocalConfigPath(workingDir)
+	}
+	_, statErr := os.Stat(localOverride)
+	files = append(files, ConfigFileInfo{Path: localOverride, Type: "local", Exists: statErr == nil})
+
 	// Global config
-	home, err := os.UserHomeDir()
-	if err == nil {
-		globalConfig := filepath.Join(home, ".ddx", "config.yaml")
-		if _, err := os.Stat(globalConfig); err == nil {
-			files = append(files, ConfigFileInfo{Path: globalConfig, Type: "global", Exists: true})
-		} else {
-			files = append(files, ConfigFileInfo{Path: globalConfig, Type: "global", Exists: false})
-		}
+	if globalConfig, err := config.GlobalConfigPath(); err == nil {
+		_, statErr := os.Stat(globalConfig)
+		files = append(files, ConfigFileInfo{Path: globalConfig, Type: "global", Exists: statErr == nil})
 
 		// Config directory
-		configDir := filepath.Join(home, ".ddx")
-		if _, err := os.Stat(configDir); err == nil {
-			files = append(files, ConfigFileInfo{Path: configDir, Type: "directory", Exists: true})
-		} else {
-			files = append(files, ConfigFileInfo{Path: configDir, Type: "directory", Exists: false})
-		}
+		configDir := filepath.Dir(globalConfig)
+		_, statErr = os.Stat(configDir)
+		files = append(files, ConfigFileInfo{Path: configDir, Type: "directory", Exists: statErr == nil})
 	}
 
 	return files
@@ -285,11 +330,11 @@ func configListFiles(workingDir string) []ConfigFileInfo {
 // configGetPath returns the config file path for editing
 func configGetPath(workingDir string, global bool) string {
 	if global {
-		home, err := os.UserHomeDir()
+		path, err := config.GlobalConfigPath()
 		if err != nil {
-			return "~/.ddx/config.yaml"
+			return "~/.config/ddx/config.yaml"
 		}
-		return filepath.Join(home, ".ddx", "config.yaml")
+		return path
 	}
 	if workingDir != "" {
 		return filepath.Join(workingDir, ".ddx", "config.yaml")
@@ -311,11 +356,12 @@ func configSave(workingDir string, cfg *config.Config, global bool) error {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write configuration: %w", err)
-	}
-
-	return nil
+	return filelock.With(configPath, func() error {
+		if err := atomicfile.WriteFile(configPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write configuration: %w", err)
+		}
+		return nil
+	})
 }
 
 // Helper types and functions
@@ -325,63 +371,6 @@ type ConfigFileInfo struct {
 	Exists bool
 }
 
-// extractConfigValue extracts a value from config by key
-func extractConfigValue(cfg *config.Config, key string) (string, error) {
-	// Handle library configuration keys
-	switch key {
-	case "version":
-		return cfg.Version, nil
-	case "library.path":
-		if cfg.Library == nil {
-			return "", nil
-		}
-		return cfg.Library.Path, nil
-	case "library.repository.url":
-		if cfg.Library == nil || cfg.Library.Repository == nil {
-			return "", nil
-		}
-		return cfg.Library.Repository.URL, nil
-	case "library.repository.branch":
-		if cfg.Library == nil || cfg.Library.Repository == nil {
-			return "", nil
-		}
-		return cfg.Library.Repository.Branch, nil
-	default:
-		return "", fmt.Errorf("unknown configuration key: %s\nValid keys: version, library.path, library.repository.url, library.repository.branch", key)
-	}
-}
-
-// setConfigValueInStruct sets a value in the config struct by key
-func setConfigValueInStruct(cfg *config.Config, key, value string) error {
-	// Handle library configuration keys
-	switch key {
-	case "library.path":
-		if cfg.Library == nil {
-			cfg.Library = &config.LibraryConfig{}
-		}
-		cfg.Library.Path = value
-	case "library.repository.url":
-		if cfg.Library == nil {
-			cfg.Library = &config.LibraryConfig{}
-		}
-		if cfg.Library.Repository == nil {
-			cfg.Library.Repository = &config.RepositoryConfig{}
-		}
-		cfg.Library.Repository.URL = value
-	case "library.repository.branch":
-		if cfg.Library == nil {
-			cfg.Library = &config.LibraryConfig{}
-		}
-		if cfg.Library.Repository == nil {
-			cfg.Library.Repository = &config.RepositoryConfig{}
-		}
-		cfg.Library.Repository.Branch = value
-	default:
-		return fmt.Errorf("unknown configuration key: %s\nValid keys: library.path, library.repository.url, library.repository.branch", key)
-	}
-	return nil
-}
-
 // CLI Interface Layer Functions
 
 func getConfigValueWithWorkingDir(cmd *cobra.Command, key string, global bool, workingDir string) error {
@@ -407,7 +396,93 @@ func (f *CommandFactory) outputConfigFiles(cmd *cobra.Command, files []ConfigFil
 	}
 
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Priority order: Environment variables > Project config > Global config > Defaults")
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Priority order: Environment variables > Local overrides > Profile > Project config > Global config > Defaults")
+	return nil
+}
+
+// outputEffectiveConfig prints every resolved value with the layer that set it
+func (f *CommandFactory) outputEffectiveConfig(cmd *cobra.Command) error {
+	values, err := configEffective(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintln(out, "📋 Effective DDx Configuration:")
+	_, _ = fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	_, _ = fmt.Fprintln(w, "---\t-----\t------")
+	for _, v := range values {
+		source := v.Source
+		if v.Path != "" {
+			source = fmt.Sprintf("%s (%s)", v.Source, v.Path)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, source)
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Precedence: defaults < global < project < profile < local < env")
+	return nil
+}
+
+// outputResolvedConfig prints the effective configuration with environment
+// variable references expanded, or a single resolved value when a key is
+// given. Unset variables fail the command in strict mode or with --strict.
+func (f *CommandFactory) outputResolvedConfig(cmd *cobra.Command, args []string) error {
+	workingDir := f.WorkingDir
+	if workingDir == "" {
+		var err error
+		if workingDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	resolution, err := config.Resolve(workingDir, config.ActiveProfile())
+	if err != nil {
+		return err
+	}
+	strict, _ := cmd.Flags().GetBool("strict")
+	strict = strict || resolution.Mode == config.InterpolationStrict
+
+	values := resolution.Values
+	if len(args) > 0 {
+		values = nil
+		for _, v := range resolution.Values {
+			if v.Key == args[0] || strings.HasPrefix(v.Key, args[0]+".") {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("configuration key '%s' is not set", args[0])
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if len(args) > 0 && len(values) == 1 && values[0].Key == args[0] {
+		_, _ = fmt.Fprintln(out, values[0].Value)
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "KEY\tVALUE\tWRITTEN AS")
+		_, _ = fmt.Fprintln(w, "---\t-----\t----------")
+		for _, v := range values {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Template)
+		}
+		_ = w.Flush()
+	}
+
+	unset := 0
+	for _, v := range values {
+		if len(v.Unset) == 0 {
+			continue
+		}
+		unset++
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  %s refers to unset ${%s} (%s)\n", v.Key, strings.Join(v.Unset, "}, ${"), v.Path)
+	}
+	if strict && unset > 0 {
+		return NewExitError(1, fmt.Sprintf("%d configuration value(s) refer to unset environment variables", unset))
+	}
 	return nil
 }
 
@@ -426,7 +501,7 @@ func (f *CommandFactory) editConfigFile(cmd *cobra.Command, configPath string) e
 
 // handleProfileSubcommand handles profile-specific operations
 func (f *CommandFactory) handleProfileSubcommand(cmd *cobra.Command, args []string) error {
-	return handleProfileSubcommand(cmd, args)
+	return handleProfileSubcommand(cmd, args, f.WorkingDir)
 }
 
 // copyFile copies a file from src to dst
@@ -459,7 +534,7 @@ func showConfigFiles(cmd *cobra.Command) error {
 }
 
 // handleProfileSubcommand handles profile-specific subcommands for US-023
-func handleProfileSubcommand(cmd *cobra.Command, args []string) error {
+func handleProfileSubcommand(cmd *cobra.Command, args []string, workingDir string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("profile subcommand requires an action")
 	}
@@ -470,39 +545,39 @@ func handleProfileSubcommand(cmd *cobra.Command, args []string) error {
 		if len(args) < 2 {
 			return fmt.Errorf("profile create requires a profile name")
 		}
-		return createProfile(cmd, args[1])
+		return createProfile(cmd, args[1], workingDir)
 	case "list":
-		return listProfiles(cmd)
+		return listProfiles(cmd, workingDir)
 	case "activate":
 		if len(args) < 2 {
 			return fmt.Errorf("profile activate requires a profile name")
 		}
-		return activateProfile(cmd, args[1])
+		return activateProfile(cmd, args[1], workingDir)
 	case "copy":
 		if len(args) < 3 {
 			return fmt.Errorf("profile copy requires source and destination profile names")
 		}
-		return copyProfile(cmd, args[1], args[2])
+		return copyProfile(cmd, args[1], args[2], workingDir)
 	case "validate":
 		if len(args) < 2 {
 			return fmt.Errorf("profile validate requires a profile name")
 		}
-		return validateProfile(cmd, args[1])
+		return validateProfile(cmd, args[1], workingDir)
 	case "show":
 		if len(args) < 2 {
 			return fmt.Errorf("profile show requires a profile name")
 		}
-		return showProfile(cmd, args[1])
+		return showProfile(cmd, args[1], workingDir)
 	case "diff":
 		if len(args) < 3 {
 			return fmt.Errorf("profile diff requires two profile names")
 		}
-		return diffProfiles(cmd, args[1], args[2])
+		return diffProfiles(cmd, args[1], args[2], workingDir)
 	case "delete":
 		if len(args) < 2 {
 			return fmt.Errorf("profile delete requires a profile name")
 		}
-		return deleteProfile(cmd, args[1])
+		return deleteProfile(cmd, args[1], workingDir)
 	default:
 		return fmt.Errorf("unknown profile action: %s", action)
 	}
@@ -518,37 +593,47 @@ func resyncMetaPromptAfterConfigChange(workingDir string) error {
 	return syncMetaPromptWithConfig(cfg, workingDir)
 }
 
-// syncMetaPromptWithConfig syncs meta-prompt based on config
+// syncMetaPromptWithConfig syncs meta-prompt based on config into every
+// configured AI target
 func syncMetaPromptWithConfig(cfg *config.Config, workingDir string) error {
+	targetList, _, err := aiTargets(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := backupAIFiles(workingDir, "meta-prompt sync", targets.Files(targetList)); err != nil {
+		return err
+	}
+
 	promptPath := cfg.GetMetaPrompt()
-	if promptPath == "" {
-		// Disabled - remove meta-prompt section if exists
+	for _, target := range targetList {
 		injector := metaprompt.NewMetaPromptInjectorWithPaths(
-			"CLAUDE.md",
+			target.File,
 			cfg.Library.Path,
 			workingDir,
 		)
-		return injector.RemoveMetaPrompt()
+		err = filelock.With(filepath.Join(workingDir, target.File), func() error {
+			if promptPath == "" {
+				// Disabled - remove meta-prompt section if exists
+				return injector.RemoveMetaPrompt()
+			}
+			return injector.InjectMetaPrompt(promptPath)
+		})
+		if err != nil {
+			return err
+		}
 	}
-
-	// Create injector and sync
-	injector := metaprompt.NewMetaPromptInjectorWithPaths(
-		"CLAUDE.md",
-		cfg.Library.Path,
-		workingDir,
-	)
-
-	return injector.InjectMetaPrompt(promptPath)
+	return nil
 }
 
 // createProfile creates a new environment profile
-func createProfile(cmd *cobra.Command, profileName string) error {
+func createProfile(cmd *cobra.Command, profileName string, workingDir string) error {
 	// Validate profile name
-	if strings.Contains(profileName, "/") || strings.Contains(profileName, "\\") {
-		return fmt.Errorf("invalid profile name: cannot contain path separators")
+	if err := config.ValidateProfileName(profileName); err != nil {
+		return err
 	}
 
-	profilePath := fmt.Sprintf(".ddx.%s.yml", profileName)
+	profilePath := config.ProfilePath(workingDir, profileName)
 
 	// Check if profile already exists
 	if _, err := os.Stat(profilePath); err == nil {
@@ -556,7 +641,7 @@ func createProfile(cmd *cobra.Command, profileName string) error {
 	}
 
 	// Load base configuration for inheritance
-	baseCfg, err := config.Load()
+	baseCfg, err := config.LoadWithWorkingDir(workingDir)
 	if err != nil {
 		// If no base config exists, use default
 		baseCfg = config.DefaultConfig
@@ -574,24 +659,24 @@ func createProfile(cmd *cobra.Command, profileName string) error {
 	}
 
 	// Write profile file
-	if err := os.WriteFile(profilePath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(profilePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write profile configuration: %w", err)
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Created profile '%s' at %s\n", profileName, profilePath)
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "💡 Edit the file to customize environment-specific settings\n")
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "💡 Activate with: ddx config profile activate %s\n", profileName)
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Created profile '%s' at %s\n", profileName, profilePath)
+	_, _ = fmt.Fprintf(statusOut(cmd), "💡 Edit the file to customize environment-specific settings\n")
+	_, _ = fmt.Fprintf(statusOut(cmd), "💡 Activate with: ddx config profile activate %s\n", profileName)
 
 	return nil
 }
 
 // listProfiles lists all available environment profiles
-func listProfiles(cmd *cobra.Command) error {
+func listProfiles(cmd *cobra.Command, workingDir string) error {
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "📋 Available Environment Profiles:")
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 
 	// Find all .ddx.*.yml files
-	profiles, err := filepath.Glob(".ddx.*.yml")
+	profiles, err := filepath.Glob(filepath.Join(workingDir, ".ddx.*.yml"))
 	if err != nil {
 		return fmt.Errorf("failed to search for profiles: %w", err)
 	}
@@ -603,7 +688,7 @@ func listProfiles(cmd *cobra.Command) error {
 	}
 
 	// Get current active profile
-	activeProfile := os.Getenv("DDX_ENV")
+	activeProfile := config.ActiveProfile()
 
 	// Display each profile
 	for _, profilePath := range profiles {
@@ -640,7 +725,7 @@ func listProfiles(cmd *cobra.Command) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout())
 	}
 
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "💡 Activate a profile with: ddx config profile activate <name>")
+	_, _ = fmt.Fprintln(statusOut(cmd), "💡 Activate a profile with: ddx config profile activate <name>")
 	if activeProfile != "" {
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "🟢 Currently active: %s\n", activeProfile)
 	} else {
@@ -651,8 +736,8 @@ func listProfiles(cmd *cobra.Command) error {
 }
 
 // activateProfile activates an environment profile
-func activateProfile(cmd *cobra.Command, profileName string) error {
-	profilePath := fmt.Sprintf(".ddx.%s.yml", profileName)
+func activateProfile(cmd *cobra.Command, profileName string, workingDir string) error {
+	profilePath := config.ProfilePath(workingDir, profileName)
 
 	// Check if profile exists
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
@@ -664,25 +749,28 @@ func activateProfile(cmd *cobra.Command, profileName string) error {
 		return fmt.Errorf("profile '%s' is invalid: %w", profileName, err)
 	}
 
-	// Note: In a real implementation, we would set the environment variable for the current shell
-	// For now, we provide instructions to the user
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Profile '%s' is ready for activation\n", profileName)
+	// A child process cannot change the parent shell's environment, so
+	// explain the supported ways of selecting the profile instead
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Profile '%s' is ready for activation\n", profileName)
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "To activate this profile, run:")
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  export DDX_ENV=%s\n", profileName)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  export %s=%s\n", config.ProfileEnvVar, profileName)
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Or add to your shell configuration:")
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  echo 'export DDX_ENV=%s' >> ~/.bashrc\n", profileName)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  echo 'export %s=%s' >> ~/.bashrc\n", config.ProfileEnvVar, profileName)
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "💡 All subsequent DDx commands will use this profile's configuration")
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Or select it for a single command:")
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  ddx --profile %s <command>\n", profileName)
+	_, _ = fmt.Fprintln(cmd.OutOrStdout())
+	_, _ = fmt.Fprintln(statusOut(cmd), "💡 All subsequent DDx commands will use this profile's configuration")
 
 	return nil
 }
 
 // copyProfile copies an existing profile to create a new one
-func copyProfile(cmd *cobra.Command, sourceProfile, destProfile string) error {
-	sourcePath := fmt.Sprintf(".ddx.%s.yml", sourceProfile)
-	destPath := fmt.Sprintf(".ddx.%s.yml", destProfile)
+func copyProfile(cmd *cobra.Command, sourceProfile, destProfile string, workingDir string) error {
+	sourcePath := config.ProfilePath(workingDir, sourceProfile)
+	destPath := config.ProfilePath(workingDir, destProfile)
 
 	// Check if source profile exists
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
@@ -709,20 +797,20 @@ func copyProfile(cmd *cobra.Command, sourceProfile, destProfile string) error {
 	}
 
 	// Write destination file
-	if err := os.WriteFile(destPath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(destPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write destination profile: %w", err)
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Copied profile '%s' to '%s'\n", sourceProfile, destProfile)
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Copied profile '%s' to '%s'\n", sourceProfile, destProfile)
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📁 Created: %s\n", destPath)
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "💡 You can now customize the new profile independently\n")
+	_, _ = fmt.Fprintf(statusOut(cmd), "💡 You can now customize the new profile independently\n")
 
 	return nil
 }
 
 // validateProfile validates a specific environment profile
-func validateProfile(cmd *cobra.Command, profileName string) error {
-	profilePath := fmt.Sprintf(".ddx.%s.yml", profileName)
+func validateProfile(cmd *cobra.Command, profileName string, workingDir string) error {
+	profilePath := config.ProfilePath(workingDir, profileName)
 
 	// Check if profile exists
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
@@ -739,21 +827,21 @@ func validateProfile(cmd *cobra.Command, profileName string) error {
 		return fmt.Errorf("profile '%s' is invalid: %w", profileName, err)
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Profile '%s' is valid\n", profileName)
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Profile '%s' is valid\n", profileName)
 	return nil
 }
 
 // showProfile displays the configuration for a specific profile
-func showProfile(cmd *cobra.Command, profileName string) error {
-	profilePath := fmt.Sprintf(".ddx.%s.yml", profileName)
+func showProfile(cmd *cobra.Command, profileName string, workingDir string) error {
+	profilePath := config.ProfilePath(workingDir, profileName)
 
 	// Check if profile exists
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
 		return fmt.Errorf("profile '%s' does not exist", profileName)
 	}
 
-	// Load profile configuration
-	profileCfg, err := config.LoadFromFile(profilePath)
+	// Load the project configuration with the profile merged over it
+	profileCfg, err := config.LoadWithProfile(workingDir, profileName)
 	if err != nil {
 		return fmt.Errorf("failed to load profile '%s': %w", profileName, err)
 	}
@@ -777,7 +865,7 @@ func showProfile(cmd *cobra.Command, profileName string) error {
 	// Show inheritance information
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 	_, _ = yellow.Println("ℹ️  Inheritance Information:")
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Profile inherits from base configuration: %s\n", ".ddx.yml")
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Profile inherits from base configuration: %s\n", ".ddx/config.yaml")
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Profile-specific values override base values\n")
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Environment variables take highest precedence\n")
 
@@ -785,9 +873,9 @@ func showProfile(cmd *cobra.Command, profileName string) error {
 }
 
 // diffProfiles compares two environment profiles
-func diffProfiles(cmd *cobra.Command, profileA, profileB string) error {
-	profilePathA := fmt.Sprintf(".ddx.%s.yml", profileA)
-	profilePathB := fmt.Sprintf(".ddx.%s.yml", profileB)
+func diffProfiles(cmd *cobra.Command, profileA, profileB string, workingDir string) error {
+	profilePathA := config.ProfilePath(workingDir, profileA)
+	profilePathB := config.ProfilePath(workingDir, profileB)
 
 	// Check if both profiles exist
 	if _, err := os.Stat(profilePathA); os.IsNotExist(err) {
@@ -866,8 +954,8 @@ func diffProfiles(cmd *cobra.Command, profileA, profileB string) error {
 }
 
 // deleteProfile deletes an environment profile
-func deleteProfile(cmd *cobra.Command, profileName string) error {
-	profilePath := fmt.Sprintf(".ddx.%s.yml", profileName)
+func deleteProfile(cmd *cobra.Command, profileName string, workingDir string) error {
+	profilePath := config.ProfilePath(workingDir, profileName)
 
 	// Check if profile exists
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
@@ -875,9 +963,9 @@ func deleteProfile(cmd *cobra.Command, profileName string) error {
 	}
 
 	// Check if this is the currently active profile
-	activeProfile := os.Getenv("DDX_ENV")
+	activeProfile := config.ActiveProfile()
 	if activeProfile == profileName {
-		return fmt.Errorf("cannot delete active profile '%s'. Deactivate it first by unsetting DDX_ENV", profileName)
+		return fmt.Errorf("cannot delete active profile '%s'. Deactivate it first by unsetting %s", profileName, config.ProfileEnvVar)
 	}
 
 	// For tests, we'll proceed directly with deletion
@@ -888,10 +976,8 @@ func deleteProfile(cmd *cobra.Command, profileName string) error {
 		return fmt.Errorf("failed to delete profile file: %w", err)
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Deleted profile '%s'\n", profileName)
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Deleted profile '%s'\n", profileName)
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📁 Removed: %s\n", profilePath)
 
 	return nil
 }
-
-// ConfigValueWithSource represents a configuration value with its source attribution
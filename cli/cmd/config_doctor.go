@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// ConfigDoctorReport is the result of 'ddx config doctor'
+type ConfigDoctorReport struct {
+	// Sources are the configuration sources, lowest precedence first
+	Sources []config.SourceFile `json:"sources"`
+	// Values are the effective values; empty when the configuration does
+	// not load
+	Values   []ConfigDoctorValue   `json:"values,omitempty"`
+	Problems []ConfigDoctorProblem `json:"problems"`
+}
+
+// ConfigDoctorValue is an effective value and the sources it overrides
+type ConfigDoctorValue struct {
+	Key       string   `json:"key"`
+	Value     string   `json:"value"`
+	Source    string   `json:"source"`
+	Path      string   `json:"path,omitempty"`
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// ConfigDoctorProblem is something in the configuration that does not take
+// effect the way it reads
+type ConfigDoctorProblem struct {
+	Status CheckStatus `json:"status"`
+	// File and Line locate the problem, when it is in one file
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// runConfigDoctor implements the config doctor command
+func (f *CommandFactory) runConfigDoctor(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	report, err := configDoctor(f.WorkingDir, config.ActiveProfile())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+	} else {
+		printConfigDoctor(out, f.WorkingDir, report)
+	}
+
+	failed := 0
+	for _, p := range report.Problems {
+		if p.Status == CheckFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return NewExitError(1, fmt.Sprintf("%d configuration problem(s) found", failed))
+	}
+	return nil
+}
+
+// configDoctor inspects the configuration sources of the working
+// directory, resolves the effective values and checks that persona
+// bindings and the library path refer to things that exist
+func configDoctor(workingDir, profile string) (*ConfigDoctorReport, error) {
+	sources, err := config.InspectSources(workingDir, profile)
+	if err != nil {
+		return nil, err
+	}
+	report := &ConfigDoctorReport{Sources: sources, Problems: []ConfigDoctorProblem{}}
+	problem := func(status CheckStatus, fix, format string, a ...any) {
+		report.Problems = append(report.Problems, ConfigDoctorProblem{Status: status, Message: fmt.Sprintf(format, a...), Fix: fix})
+	}
+
+	loads := true
+	for _, source := range sources {
+		if source.Source == config.SourceProfile && !source.Found {
+			report.Problems = append(report.Problems, ConfigDoctorProblem{
+				Status: CheckWarn, File: source.Path,
+				Message: fmt.Sprintf("profile '%s' is selected but does not exist; it is ignored", profile),
+				Fix:     fmt.Sprintf("Create the file or unset %s", config.ProfileEnvVar),
+			})
+		}
+		for _, key := range source.Unknown {
+			report.Problems = append(report.Problems, ConfigDoctorProblem{
+				Status: CheckFail, File: source.Path, Line: key.Line,
+				Message: fmt.Sprintf("unknown key '%s'", key.Key),
+				Fix:     "Remove it or correct its spelling",
+			})
+			loads = false
+		}
+		for _, deprecated := range source.Deprecated {
+			report.Problems = append(report.Problems, ConfigDoctorProblem{
+				Status: CheckWarn, File: source.Path, Message: deprecated,
+				Fix: "Run 'ddx config migrate' to update the file",
+			})
+		}
+		if source.Error != "" {
+			report.Problems = append(report.Problems, ConfigDoctorProblem{
+				Status: CheckFail, File: source.Path, Message: source.Error,
+				Fix: "Correct the file; 'ddx config validate' re-checks it",
+			})
+			loads = false
+		}
+	}
+	if !loads {
+		return report, nil
+	}
+
+	values, err := config.Effective(workingDir, profile)
+	if err != nil {
+		problem(CheckFail, "", "the configuration does not load: %v", err)
+		return report, nil
+	}
+	var libPath string
+	for _, v := range values {
+		value := ConfigDoctorValue{Key: v.Key, Value: v.Value, Source: v.Source, Path: v.Path}
+		for _, shadowed := range v.Shadowed {
+			value.Overrides = append(value.Overrides, fmt.Sprintf("%s: %s", shadowed.Source, shadowed.Value))
+		}
+		report.Values = append(report.Values, value)
+		if v.Key == "library.path" {
+			libPath = v.Value
+			if !filepath.IsAbs(libPath) {
+				libPath = filepath.Join(workingDir, libPath)
+			}
+			if info, err := os.Stat(libPath); err != nil || !info.IsDir() {
+				problem(CheckFail, "Run 'ddx update' to restore the library, or correct library.path",
+					"library.path %s (from %s) does not exist", v.Value, v.Source)
+				libPath = ""
+			}
+		}
+	}
+
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil || libPath == "" {
+		return report, nil
+	}
+	bindings := map[string]string{}
+	for role, value := range cfg.PersonaBindings {
+		bindings["persona_bindings."+role] = value
+	}
+	for workflow, overrides := range cfg.Overrides {
+		for role, value := range overrides {
+			bindings["overrides."+workflow+"."+role] = value
+		}
+	}
+	keys := make([]string, 0, len(bindings))
+	for key := range bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	libraries := library.ProjectSources(workingDir, cfg)
+	for _, key := range keys {
+		for _, name := range config.SplitPersonas(bindings[key]) {
+			if err := loadBoundPersona(workingDir, libraries, name); err != nil {
+				problem(CheckFail, "Bind a persona from 'ddx persona list' instead",
+					"%s is bound to %s, which is not in the project or the library", key, name)
+			}
+		}
+	}
+	return report, nil
+}
+
+// printConfigDoctor renders the report for a terminal
+func printConfigDoctor(w io.Writer, workingDir string, report *ConfigDoctorReport) {
+	display := func(path string) string {
+		if rel, err := filepath.Rel(workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return path
+	}
+
+	_, _ = fmt.Fprintln(w, "🩺 Configuration Doctor")
+	_, _ = fmt.Fprintln(w, "\nSources (lowest precedence first):")
+	for _, source := range report.Sources {
+		switch {
+		case source.Source == config.SourceDefault:
+			_, _ = fmt.Fprintf(w, "  ✅ %-8s built-in defaults\n", source.Source)
+		case !source.Found:
+			_, _ = fmt.Fprintf(w, "  ⬜ %-8s %s (not found)\n", source.Source, display(source.Path))
+		case source.Error != "" || len(source.Unknown) > 0:
+			_, _ = fmt.Fprintf(w, "  ❌ %-8s %s (not loaded)\n", source.Source, display(source.Path))
+		default:
+			_, _ = fmt.Fprintf(w, "  ✅ %-8s %s\n", source.Source, display(source.Path))
+		}
+	}
+
+	if len(report.Values) > 0 {
+		_, _ = fmt.Fprintln(w, "\nValues:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "  KEY\tVALUE\tSOURCE\tOVERRIDES")
+		for _, v := range report.Values {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", v.Key, v.Value, v.Source, strings.Join(v.Overrides, ", "))
+		}
+		_ = tw.Flush()
+	}
+
+	if len(report.Problems) == 0 {
+		_, _ = fmt.Fprintln(w, "\n✅ No configuration problems found")
+		return
+	}
+	_, _ = fmt.Fprintln(w, "\nProblems:")
+	icons := map[CheckStatus]string{CheckWarn: "⚠️ ", CheckFail: "❌"}
+	for _, p := range report.Problems {
+		message := p.Message
+		switch {
+		case p.Line > 0:
+			message = fmt.Sprintf("%s:%d: %s", display(p.File), p.Line, message)
+		case p.File != "":
+			message = fmt.Sprintf("%s: %s", display(p.File), message)
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s\n", icons[p.Status], message)
+		if p.Fix != "" {
+			_, _ = fmt.Fprintf(w, "     💡 %s\n", p.Fix)
+		}
+	}
+}
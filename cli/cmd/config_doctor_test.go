@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDoctor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+  architect: no-such-architect
+`)
+	env.Library().Persona(testutil.PersonaSpec{Name: "strict-code-reviewer", Roles: []string{"code-reviewer"}})
+	env.CreateFile(".ddx.local.yml", `library:
+  path: .ddx/library
+persona_binding:
+  tester: x
+`)
+
+	output, err := env.RunCommand("config", "doctor")
+	require.Error(t, err)
+	assert.Contains(t, output, "project  .ddx/config.yaml")
+	assert.Contains(t, output, "local    .ddx.local.yml (not loaded)")
+	assert.Contains(t, output, ".ddx.local.yml:3: unknown key 'persona_binding'")
+
+	env.CreateFile(".ddx.local.yml", `library:
+  path: .ddx/library
+`)
+	output, err = env.RunCommand("config", "doctor", "--json")
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.Code)
+	var report ConfigDoctorReport
+	require.NoError(t, json.NewDecoder(strings.NewReader(output)).Decode(&report), output)
+	values := map[string]ConfigDoctorValue{}
+	for _, v := range report.Values {
+		values[v.Key] = v
+	}
+	assert.Equal(t, "local", values["library.path"].Source)
+	assert.Equal(t, []string{"default: .ddx/library", "project: .ddx/library"}, values["library.path"].Overrides)
+	require.Len(t, report.Problems, 1)
+	assert.Contains(t, report.Problems[0].Message, "persona_bindings.architect is bound to no-such-architect")
+
+	env.CreateConfig(`version: "1.0"
+library:
+  path: missing/library
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+`)
+	env.CreateFile(".ddx.local.yml", "")
+	output, err = env.RunCommand("config", "doctor")
+	require.Error(t, err)
+	assert.Contains(t, output, "library.path missing/library (from project) does not exist")
+
+	// A stale DDX_ENV is reported, not fatal
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+`)
+	t.Setenv(config.ProfileEnvVar, "ghost")
+	output, err = env.RunCommand("config", "doctor", "--json")
+	require.NoError(t, err, output)
+	report = ConfigDoctorReport{}
+	require.NoError(t, json.NewDecoder(strings.NewReader(output)).Decode(&report), output)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, CheckWarn, report.Problems[0].Status)
+	assert.Equal(t, "profile 'ghost' is selected but does not exist; it is ignored", report.Problems[0].Message)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/gitignore"
+	"github.com/spf13/cobra"
+)
+
+// syncGitignore writes the managed block of .gitignore from the gitignore
+// settings, reporting whether the file changed. Nothing is written when
+// gitignore.manage is false.
+func syncGitignore(workingDir string, cfg *config.Config) (bool, error) {
+	if !cfg.ManagesGitignore() {
+		return false, nil
+	}
+	patterns, err := gitignore.Patterns(cfg.GitignoreOverrides())
+	if err != nil {
+		return false, NewExitError(ExitCodeInvalidConfig, err.Error())
+	}
+	return gitignore.Sync(workingDir, patterns)
+}
+
+// runConfigGitignore implements the config gitignore command
+func (f *CommandFactory) runConfigGitignore(cmd *cobra.Command, args []string) error {
+	check, _ := cmd.Flags().GetBool("check")
+	remove, _ := cmd.Flags().GetBool("remove")
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+
+	if remove {
+		changed, err := gitignore.Sync(f.WorkingDir, nil)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			_, _ = fmt.Fprintln(out, "No ddx block in .gitignore")
+			return nil
+		}
+		_, _ = fmt.Fprintln(out, "🗑️  Removed the ddx block from .gitignore")
+		_, _ = fmt.Fprintln(statusOut(cmd), "💡 Set gitignore.manage to false to keep 'ddx init' and 'ddx update' from adding it back")
+		return nil
+	}
+
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	patterns, err := gitignore.Patterns(cfg.GitignoreOverrides())
+	if err != nil {
+		return NewExitError(ExitCodeInvalidConfig, err.Error())
+	}
+	if !cfg.ManagesGitignore() {
+		_, _ = fmt.Fprintln(out, "gitignore.manage is false; .gitignore is left alone")
+		return nil
+	}
+
+	if check {
+		current, _, err := gitignore.Current(f.WorkingDir)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(current, patterns) {
+			return NewExitError(1, "the ddx block in .gitignore is out of date; run 'ddx config gitignore'")
+		}
+		_, _ = fmt.Fprintln(out, "✅ The ddx block in .gitignore is up to date")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ARTIFACT\tPATTERN\tGIT\tDESCRIPTION")
+	overrides := cfg.GitignoreOverrides()
+	for _, a := range gitignore.Artifacts {
+		state := "committed"
+		if slices.Contains(patterns, a.Pattern) {
+			state = "ignored"
+		}
+		if _, ok := overrides[a.Name]; ok {
+			state += " (configured)"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Name, a.Pattern, state, a.Description)
+	}
+	_ = tw.Flush()
+
+	changed, err := gitignore.Sync(f.WorkingDir, patterns)
+	if err != nil {
+		return err
+	}
+	if changed {
+		_, _ = fmt.Fprintln(out, "\n✅ Updated the ddx block in .gitignore")
+	} else {
+		_, _ = fmt.Fprintln(out, "\n✓ The ddx block in .gitignore is up to date")
+	}
+	return nil
+}
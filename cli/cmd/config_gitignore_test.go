@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/gitignore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigGitignore(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\ngitignore:\n  ignore:\n    history: false\n    helix-state: true\n")
+	env.CreateFile(".gitignore", "bin/\n")
+	read := func() string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, ".gitignore"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	_, err := env.RunCommand("config", "gitignore", "--check")
+	assert.ErrorContains(t, err, "the ddx block in .gitignore is out of date")
+
+	output, err := env.RunCommand("config", "gitignore")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `history\s+\.ddx/history\.jsonl\s+committed \(configured\)`, output)
+	assert.Regexp(t, `helix-state\s+\.helix-state\.yml\s+ignored \(configured\)`, output)
+	assert.Contains(t, output, "Updated the ddx block in .gitignore")
+	content := read()
+	assert.True(t, strings.HasPrefix(content, "bin/\n\n"+gitignore.BeginMarker+"\n"), content)
+	assert.Contains(t, content, ".ddx/backups/\n")
+	assert.Contains(t, content, ".helix-state.yml\n")
+	assert.NotContains(t, content, "history.jsonl")
+
+	output, err = env.RunCommand("config", "gitignore", "--check")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "up to date")
+
+	output, err = env.RunCommand("config", "gitignore", "--remove")
+	require.NoError(t, err, output)
+	assert.Equal(t, "bin/\n", read())
+
+	t.Run("unknown artifacts", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig("version: \"1.0\"\ngitignore:\n  ignore:\n    bogus: true\n")
+		_, err := env.RunCommand("config", "gitignore")
+		assert.ErrorContains(t, err, "gitignore.ignore.bogus: value must be one of")
+	})
+
+	t.Run("init commits the block", func(t *testing.T) {
+		env := NewTestEnvironment(t)
+		env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n")
+		env.CreateFile(".ddx/library/prompts/README.md", "# Prompts\n")
+		output, err := env.RunCommand("init", "--force", "--non-interactive", "--skip-claude-injection")
+		require.NoError(t, err, output)
+		data, err := os.ReadFile(filepath.Join(env.Dir, ".gitignore"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), ".ddx/snapshots/")
+		tracked, err := exec.Command("git", "-C", env.Dir, "ls-files", ".gitignore").Output()
+		require.NoError(t, err)
+		assert.Equal(t, ".gitignore\n", string(tracked))
+	})
+}
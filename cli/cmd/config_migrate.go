@@ -2,44 +2,174 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/variables"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
-// configMigrateCmd represents the config migrate command
-var configMigrateCmd = &cobra.Command{
-	Use:   "migrate",
-	Short: "Migrate configuration from legacy .ddx.yml to new .ddx/config.yaml format",
-	Long: `Migrate configuration from the legacy .ddx.yml format to the new .ddx/config.yaml format.
-
-This command will:
-1. Read your existing .ddx.yml file
-2. Convert it to the new simplified format
-3. Save it as .ddx/config.yaml
-4. Create a backup of the original file
-
-The new format is simpler and more focused on essential configuration options.
-
-Examples:
-  ddx config migrate                    # Migrate configuration
-  ddx config migrate --dry-run          # Show what would be migrated without changing files
-  ddx config migrate --force            # Overwrite existing .ddx/config.yaml if present`,
-	RunE: runConfigMigrate,
+// ConfigMigration is the migration of one config file
+type ConfigMigration struct {
+	Plan *config.MigrationPlan
+	// Variables counts the values moved to .ddx/variables.yml
+	Variables int
 }
 
-var (
-	migrateDryRun bool
-	migrateForce  bool
-)
+// runConfigMigrate brings configuration files up to date, moving legacy
+// .ddx.yml files, upgrading older formats and rewriting deprecated fields.
+// With --dry-run the changes are only listed.
+func (f *CommandFactory) runConfigMigrate(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	global, _ := cmd.Flags().GetBool("global")
+
+	var files [][2]string
+	for _, path := range args {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(f.WorkingDir, path)
+		}
+		files = append(files, [2]string{path, path})
+	}
+	if len(files) == 0 {
+		files = configMigrateFiles(f.WorkingDir, global)
+	}
+
+	results, err := configMigrate(f.WorkingDir, files, !dryRun)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(out, "✅ Configuration is current and uses no deprecated fields")
+		return nil
+	}
+	display := func(path string) string {
+		if rel, err := filepath.Rel(f.WorkingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return path
+	}
+	for _, result := range results {
+		plan := result.Plan
+		header := display(plan.Source)
+		if plan.Target != plan.Source {
+			header += " → " + display(plan.Target)
+		}
+		if plan.FromVersion != plan.ToVersion {
+			header += fmt.Sprintf(" (format %s → %s)", plan.FromVersion, plan.ToVersion)
+		}
+		_, _ = fmt.Fprintf(out, "%s:\n", header)
+		for _, step := range plan.Steps {
+			for _, change := range step.Changes {
+				_, _ = fmt.Fprintf(out, "  • %s\n", change)
+			}
+		}
+		for _, m := range plan.Renames {
+			_, _ = fmt.Fprintf(out, "  • %s\n", m)
+		}
+		if result.Variables > 0 {
+			_, _ = fmt.Fprintf(out, "  • moved %d variable value(s) to .ddx/variables.yml\n", result.Variables)
+		}
+		if !dryRun {
+			_, _ = fmt.Fprintf(out, "  backup: %s\n", display(plan.Backup()))
+		}
+	}
+	if dryRun {
+		_, _ = fmt.Fprintln(out, "\nRun 'ddx config migrate' to update the files.")
+	} else {
+		_, _ = fmt.Fprintln(out, "\n✅ Configuration migrated")
+	}
+	return nil
+}
 
-func init() {
-	// Note: Command registration is handled by command_factory.go
-	// This init function sets up flags only
-	configMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show migration preview without making changes")
-	configMigrateCmd.Flags().BoolVar(&migrateForce, "force", false, "Overwrite existing .ddx/config.yaml")
+// configMigrateFiles returns the source and target of each file checked by
+// default: the global file, or the project file and its local override.
+// A legacy .ddx.yml is moved to .ddx/config.yaml when that does not exist.
+func configMigrateFiles(workingDir string, global bool) [][2]string {
+	dir := workingDir
+	if global {
+		dir, _ = os.UserHomeDir()
+	}
+	target := configGetPath(workingDir, global)
+	files := [][2]string{{target, target}}
+	legacy := filepath.Join(dir, config.LegacyConfigFile)
+	if _, err := os.Stat(legacy); err == nil {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			files[0][0] = legacy
+		}
+	}
+	if !global {
+		local := config.LocalConfigPath(workingDir)
+		files = append(files, [2]string{local, local})
+	}
+	return files
 }
 
-func runConfigMigrate(cmd *cobra.Command, args []string) error {
-	// Migration no longer needed - only .ddx/config.yaml format is supported
-	return fmt.Errorf("migration command is not needed - DDx now only supports .ddx/config.yaml format. Please use 'ddx init' to create a new configuration.")
+// configMigrate plans the migration of each source and target pair,
+// applying it when write is set. Files that are already current are left
+// out of the result.
+func configMigrate(workingDir string, files [][2]string, write bool) ([]ConfigMigration, error) {
+	var results []ConfigMigration
+	for _, file := range files {
+		plan, err := config.PlanMigration(file[0], file[1])
+		if err != nil {
+			return nil, err
+		}
+		if plan == nil || plan.Empty() {
+			continue
+		}
+
+		result := ConfigMigration{Plan: plan}
+		if write {
+			// Keep the old variables section's values rather than dropping them
+			if result.Variables, err = moveConfigVariables(workingDir, plan.Source); err != nil {
+				return nil, err
+			}
+			if err := plan.Apply(); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// moveConfigVariables records the values of a config file's retired
+// variables section in the project's variable registry
+func moveConfigVariables(workingDir, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	var raw struct {
+		Variables map[string]interface{} `yaml:"variables"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil || len(raw.Variables) == 0 {
+		return 0, nil
+	}
+
+	reg, err := variables.Load(workingDir)
+	if err != nil {
+		return 0, err
+	}
+
+	// Values recorded since the section was retired are more recent
+	values := make(map[string]string, len(raw.Variables))
+	for name, value := range raw.Variables {
+		if _, recorded := reg.Get(name); !recorded && value != nil {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	before := len(reg.Values)
+	if !reg.Record(values) {
+		return 0, nil
+	}
+	if err := reg.Save(); err != nil {
+		return 0, err
+	}
+	return len(reg.Values) - before, nil
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/auth"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// configSecretPlatform is the credential platform 'ddx config set --secret'
+// stores values under, keyed by the configuration key
+const configSecretPlatform auth.Platform = "config"
+
+// storeConfigSecret saves a configuration value in the credential store and
+// returns the reference to write in its place
+func storeConfigSecret(ctx context.Context, key, value string) (string, error) {
+	now := time.Now()
+	cred := &auth.Credential{
+		ID:        key,
+		Platform:  configSecretPlatform,
+		Method:    auth.AuthMethodToken,
+		Token:     value,
+		Metadata:  map[string]string{"key": key},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := initializeAuthManager().StoreCredential(ctx, cred); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return auth.SecretRef(configSecretPlatform, key), nil
+}
+
+// resolveConfigSecret returns the stored value a secret:// configuration
+// value refers to, or value itself when it holds no reference. References
+// stay in the configuration and are only resolved where they are used.
+func resolveConfigSecret(ctx context.Context, value string) (string, error) {
+	if !auth.IsSecretRef(value) {
+		return value, nil
+	}
+	resolved, err := auth.ResolveSecretRef(ctx, initializeAuthManager(), value)
+	if err == nil {
+		return resolved, nil
+	}
+	hint := "store it with 'ddx auth token' or 'ddx auth import'"
+	if platform, id, perr := auth.ParseSecretRef(value); perr == nil && platform == configSecretPlatform {
+		hint = fmt.Sprintf("store it on this machine with 'ddx config set %s --secret'", id)
+	}
+	return "", NewExitError(ExitCodeInvalidConfig, fmt.Sprintf("%s is not in the credential store; %s", value, hint))
+}
+
+// readSecretValue reads a value for 'config set --secret' without echoing
+// it in a terminal, or from piped input
+func readSecretValue(cmd *cobra.Command, key string) (string, error) {
+	if isTerminal(cmd.InOrStdin()) {
+		in := cmd.InOrStdin().(interface{ Fd() uintptr })
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Value for %s: ", key)
+		value, err := term.ReadPassword(int(in.Fd()))
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(value)), nil
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the value of %s: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// configSetSecret implements 'config set <key> [value] --secret': the value
+// goes to the credential store and the configuration file gets a reference
+// to it. Without a value argument it is read from the terminal or stdin,
+// keeping it out of the shell history.
+func configSetSecret(cmd *cobra.Command, workingDir string, args []string, global bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("key required for set command")
+	}
+	key := args[0]
+	var value string
+	if len(args) > 1 {
+		value = args[1]
+	} else {
+		var err error
+		if value, err = readSecretValue(cmd, key); err != nil {
+			return err
+		}
+	}
+	if value == "" {
+		return NewExitError(ExitCodeMissingArg, fmt.Sprintf("no value given for %s", key))
+	}
+	if auth.IsSecretRef(value) {
+		return fmt.Errorf("the value of %s is already a secret reference; set it without --secret", key)
+	}
+
+	ref, err := storeConfigSecret(cmd.Context(), key, value)
+	if err != nil {
+		return err
+	}
+	if err := configSet(workingDir, key, ref, global); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Set %s = %s (value kept in the credential store)\n", key, ref)
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSetSecret(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n  repository:\n    url: https://github.com/easel/ddx-library\n")
+	// Keep stored secrets out of the real home
+	t.Setenv("HOME", t.TempDir())
+	mirror := filepath.Join(t.TempDir(), "library.git")
+
+	root := NewCommandFactory(env.Dir).NewRootCommand()
+	root.SetIn(strings.NewReader(mirror + "\n"))
+	output, err := executeCommand(root, "config", "set", "library.repository.url", "--secret")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Set library.repository.url = secret://config/library.repository.url (value kept in the credential store)")
+
+	data, err := os.ReadFile(filepath.Join(env.Dir, ".ddx/config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "url: secret://config/library.repository.url")
+	assert.NotContains(t, string(data), mirror)
+
+	for _, args := range [][]string{{"config", "export"}, {"config", "get", "library.repository.url"}, {"config", "effective"}} {
+		output, err := env.RunCommand(args...)
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "secret://config/library.repository.url", args)
+		assert.NotContains(t, output, mirror, args)
+	}
+
+	// The reference is resolved where the repository is contacted
+	remote, err := resolveLibraryRemote(context.Background(), env.Dir, "secret://config/library.repository.url")
+	require.NoError(t, err)
+	assert.Equal(t, mirror, remote.URL)
+
+	_, err = resolveLibraryRemote(context.Background(), env.Dir, "secret://config/library.sources.team.repository.url")
+	assert.EqualError(t, err, "secret://config/library.sources.team.repository.url is not in the credential store; store it on this machine with 'ddx config set library.sources.team.repository.url --secret'")
+
+	_, err = executeCommand(NewCommandFactory(env.Dir).NewRootCommand(), "config", "set", "library.repository.url", "secret://config/other", "--secret")
+	assert.ErrorContains(t, err, "already a secret reference")
+}
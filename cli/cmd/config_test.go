@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/network"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -261,3 +267,311 @@ func TestConfigCommand_Help(t *testing.T) {
 	assert.Contains(t, output, "local")
 	assert.Contains(t, output, "configuration")
 }
+
+// TestConfigCommand_ProfileFlag tests that --profile resolves the profile at load time
+func TestConfigCommand_ProfileFlag(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	t.Setenv("DDX_ENV", "")
+
+	config := `version: "1.0"
+library:
+  path: ".ddx/library"
+  repository:
+    url: "https://github.com/test/repo"
+    branch: "main"
+`
+	ddxDir := filepath.Join(workDir, ".ddx")
+	require.NoError(t, os.MkdirAll(ddxDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(ddxDir, "config.yaml"), []byte(config), 0644))
+
+	profile := `version: "1.0"
+library:
+  repository:
+    branch: "staging"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".ddx.staging.yml"), []byte(profile), 0644))
+
+	rootCmd := NewCommandFactory(workDir).NewRootCommand()
+	output, err := executeCommand(rootCmd, "--profile", "staging", "config", "get", "library.repository.branch")
+	require.NoError(t, err)
+	assert.Contains(t, output, "staging")
+
+	// An explicit --profile must exist
+	rootCmd = NewCommandFactory(workDir).NewRootCommand()
+	_, err = executeCommand(rootCmd, "--profile", "missing", "config", "get", "library.repository.branch")
+	assert.ErrorContains(t, err, "profile 'missing' not found")
+
+	// A stale DDX_ENV falls back to the base configuration
+	t.Setenv("DDX_ENV", "missing")
+	rootCmd = NewCommandFactory(workDir).NewRootCommand()
+	output, err = executeCommand(rootCmd, "config", "get", "library.repository.branch")
+	require.NoError(t, err)
+	assert.Contains(t, output, "main")
+}
+
+// TestConfigCommand_Effective tests that the effective config shows layered values with sources
+func TestConfigCommand_Effective(t *testing.T) {
+	homeDir := t.TempDir()
+	workDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("DDX_ENV", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+persona_bindings:
+  architect: global-architect
+`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+library:
+  repository:
+    url: "https://github.com/test/repo"
+    branch: "main"
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".ddx.local.yml"), []byte("persona_bindings:\n  override: local\n"), 0644))
+
+	for _, args := range [][]string{{"config", "effective"}, {"config", "show", "--effective"}} {
+		rootCmd := NewCommandFactory(workDir).NewRootCommand()
+		output, err := executeCommand(rootCmd, args...)
+		require.NoError(t, err)
+		assert.Contains(t, output, "persona_bindings.architect")
+		assert.Contains(t, output, "global-architect")
+		assert.Contains(t, output, "persona_bindings.override")
+		assert.Contains(t, output, "local (")
+		assert.Contains(t, output, "https://github.com/test/repo")
+	}
+}
+
+// TestConfigCommand_Resolve tests that resolve expands environment
+// variable references and reports unset ones
+func TestConfigCommand_Resolve(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DDX_ENV", "")
+	t.Setenv("DDX_TEST_LIB_ROOT", "/opt/shared")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+library:
+  path: ${DDX_TEST_LIB_ROOT}/ddx
+persona_bindings:
+  code-reviewer: ${DDX_TEST_REVIEWER}
+`), 0644))
+
+	output, err := executeCommand(NewCommandFactory(workDir).NewRootCommand(), "config", "resolve", "library.path")
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/shared/ddx\n", output)
+
+	output, err = executeCommand(NewCommandFactory(workDir).NewRootCommand(), "config", "resolve")
+	require.NoError(t, err, "unset variables only warn in lenient mode")
+	assert.Contains(t, output, "${DDX_TEST_LIB_ROOT}/ddx")
+	assert.Contains(t, output, "persona_bindings.code-reviewer refers to unset ${DDX_TEST_REVIEWER}")
+
+	_, err = executeCommand(NewCommandFactory(workDir).NewRootCommand(), "config", "resolve", "--strict")
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.Code)
+}
+
+// TestConfigCommand_NestedKeys tests dotted-path set/get/unset and list edits
+func TestConfigCommand_NestedKeys(t *testing.T) {
+	homeDir := t.TempDir()
+	workDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("DDX_ENV", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, ".ddx"), 0755))
+	configPath := filepath.Join(workDir, ".ddx", "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`# team settings
+version: "1.0"
+library:
+  path: .ddx/library
+`), 0644))
+
+	run := func(args ...string) (string, error) {
+		return executeCommand(NewCommandFactory(workDir).NewRootCommand(), args...)
+	}
+
+	_, err := run("config", "set", "persona_bindings.code-reviewer", "strict-code-reviewer")
+	require.NoError(t, err)
+	output, err := run("config", "get", "persona_bindings.code-reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, "strict-code-reviewer", strings.TrimSpace(output))
+
+	_, err = run("config", "append", "workflows.active", "helix")
+	require.NoError(t, err)
+	output, err = run("config", "get", "workflows.active")
+	require.NoError(t, err)
+	assert.Contains(t, output, "helix")
+
+	_, err = run("config", "remove", "workflows.active", "helix")
+	require.NoError(t, err)
+	_, err = run("config", "unset", "persona_bindings.code-reviewer")
+	require.NoError(t, err)
+	_, err = run("config", "get", "persona_bindings.code-reviewer")
+	assert.ErrorContains(t, err, "not found")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# team settings")
+	assert.NotContains(t, string(data), "code-reviewer")
+}
+
+func TestConfigCommand_Migrate(t *testing.T) {
+	homeDir := t.TempDir()
+	workDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("DDX_ENV", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, ".ddx"), 0755))
+	configPath := filepath.Join(workDir, ".ddx", "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`# team settings
+version: "1.0"
+repository:
+  url: https://github.com/team/library
+  branch: stable
+variables:
+  project_name: demo
+`), 0644))
+
+	run := func(args ...string) (string, error) {
+		return executeCommand(NewCommandFactory(workDir).NewRootCommand(), args...)
+	}
+
+	// Deprecated fields are understood before migrating
+	output, err := run("config", "get", "library.repository.branch")
+	require.NoError(t, err)
+	assert.Equal(t, "stable", strings.TrimSpace(output))
+
+	output, err = run("config", "migrate", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "'repository' is deprecated; use 'library.repository'")
+	assert.Contains(t, output, "Run 'ddx config migrate'")
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\nrepository:")
+
+	output, err = run("config", "migrate")
+	require.NoError(t, err)
+	assert.Contains(t, output, "moved 1 variable value(s)")
+	assert.Contains(t, output, "backup: .ddx/config.yaml.bak")
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# team settings")
+	assert.NotContains(t, string(data), "variables")
+	assert.Contains(t, string(data), "library:")
+	backup, err := os.ReadFile(configPath + ".bak")
+	require.NoError(t, err)
+	assert.Contains(t, string(backup), "\nrepository:")
+
+	vars, err := os.ReadFile(filepath.Join(workDir, ".ddx", "variables.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(vars), "project_name: demo")
+
+	output, err = run("config", "migrate")
+	require.NoError(t, err)
+	assert.Contains(t, output, "no deprecated fields")
+}
+
+// TestConfigCommand_MigrateLegacy tests moving a legacy .ddx.yml to
+// .ddx/config.yaml
+func TestConfigCommand_MigrateLegacy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DDX_ENV", "")
+	workDir := t.TempDir()
+	legacyPath := filepath.Join(workDir, ".ddx.yml")
+	require.NoError(t, os.WriteFile(legacyPath, []byte(`version: 1.0
+repository: https://github.com/team/library
+includes:
+  - prompts/claude
+  - templates/common
+`), 0644))
+
+	output, err := executeCommand(NewCommandFactory(workDir).NewRootCommand(), "config", "migrate")
+	require.NoError(t, err)
+	assert.Contains(t, output, ".ddx.yml → .ddx/config.yaml (format 0 → 1.0)")
+	assert.Contains(t, output, "moved 'includes' to 'library.include'")
+	assert.NoFileExists(t, legacyPath)
+	assert.FileExists(t, legacyPath+".bak")
+
+	cfg, err := config.LoadWithWorkingDir(workDir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", cfg.Version)
+	assert.Equal(t, "https://github.com/team/library", cfg.Library.Repository.URL)
+	assert.Equal(t, []string{"prompts/claude", "templates/common"}, cfg.Library.Include)
+}
+
+// TestConfigCommand_Network tests the network settings and how an unusable
+// CA bundle is reported
+func TestConfigCommand_Network(t *testing.T) {
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	_, err := env.RunCommand("config", "set", "network.timeout", "45s")
+	require.NoError(t, err)
+	_, err = env.RunCommand("config", "set", "network.retries", "4")
+	require.NoError(t, err)
+	output, err := env.RunCommand("config", "get", "network.retries")
+	require.NoError(t, err)
+	assert.Equal(t, "4", strings.TrimSpace(output))
+	_, err = env.RunCommand("config", "set", "network.timeout", "soon")
+	assert.Error(t, err, "timeouts are durations")
+	_, err = env.RunCommand("config", "set", "network.backoff", "2s")
+	require.NoError(t, err)
+
+	// Commands run with the configured settings, and --timeout overrides
+	// the configured timeout
+	previous := network.Current()
+	t.Cleanup(func() { _ = network.Configure(previous) })
+	_, err = env.RunCommand("config", "get", "network.backoff")
+	require.NoError(t, err)
+	settings := network.Current()
+	assert.Equal(t, 45*time.Second, settings.Timeout)
+	assert.Equal(t, 4, settings.Retries)
+	assert.Equal(t, 2*time.Second, settings.Backoff)
+	_, err = env.RunCommand("--timeout", "5s", "config", "get", "network.backoff")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, network.Current().Timeout)
+	_, err = env.RunCommand("--timeout", "-5s", "config", "get", "network.backoff")
+	assert.ErrorContains(t, err, "invalid --timeout")
+
+	env.CreateFile("ca.pem", "not a certificate")
+	caFile := filepath.Join(env.Dir, "ca.pem")
+	_, err = env.RunCommand("config", "set", "network.ca_file", caFile)
+	require.NoError(t, err)
+	factory := NewCommandFactory(env.Dir)
+	var stderr bytes.Buffer
+	factory.LogOutput = &stderr
+	root := factory.NewRootCommand()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"config", "get", "network.ca_file"})
+	require.NoError(t, root.Execute())
+	assert.Contains(t, stderr.String(), "Warning: ignoring network configuration")
+	assert.Contains(t, stderr.String(), "contains no PEM certificates")
+}
+
+// TestConfigWritesWaitForLock checks that config updates wait for another
+// ddx process holding the config lock and name it when they give up
+func TestConfigWritesWaitForLock(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "# Strict Reviewer\n")
+	t.Setenv(filelock.TimeoutEnvVar, "100ms")
+
+	held, err := filelock.Acquire(env.ConfigPath)
+	require.NoError(t, err)
+	_, err = env.RunCommand("config", "set", "library.path", ".ddx/lib")
+	assert.ErrorContains(t, err, "waiting for config.yaml: locked by pid")
+	_, err = env.RunCommand("persona", "bind", "code-reviewer", "strict-reviewer")
+	assert.ErrorContains(t, err, "waiting for config.yaml: locked by pid")
+	require.NoError(t, held.Release())
+
+	_, err = env.RunCommand("persona", "bind", "code-reviewer", "strict-reviewer")
+	require.NoError(t, err)
+	assert.NoFileExists(t, env.ConfigPath+filelock.Suffix)
+	cfg, err := env.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "strict-reviewer", cfg.PersonaBindings["code-reviewer"])
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/easel/ddx/internal/exitcodes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Contract is the machine-readable interface of the CLI printed by
+// 'ddx contracts'
+type Contract struct {
+	Version   string            `json:"version"`
+	ExitCodes []exitcodes.Code  `json:"exit_codes"`
+	Commands  []CommandContract `json:"commands"`
+}
+
+// CommandContract describes a command and the flags it defines. Flags
+// inherited from parent commands are listed with the parent, marked
+// persistent.
+type CommandContract struct {
+	Path       string         `json:"path"`
+	Use        string         `json:"use"`
+	Short      string         `json:"short"`
+	Aliases    []string       `json:"aliases,omitempty"`
+	Deprecated string         `json:"deprecated,omitempty"`
+	Runnable   bool           `json:"runnable"`
+	Flags      []FlagContract `json:"flags,omitempty"`
+}
+
+// FlagContract describes a flag
+type FlagContract struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage"`
+	Persistent bool   `json:"persistent,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// runContracts implements 'ddx contracts'
+func (f *CommandFactory) runContracts(cmd *cobra.Command, args []string) error {
+	contract := Contract{Version: f.Version, ExitCodes: exitcodes.All()}
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if c.Hidden || c.Name() == "help" {
+			return
+		}
+		contract.Commands = append(contract.Commands, commandContract(c))
+		for _, child := range c.Commands() {
+			walk(child)
+		}
+	}
+	walk(cmd.Root())
+
+	data, err := json.MarshalIndent(contract, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode contracts: %w", err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// commandContract describes c with the flags it defines itself
+func commandContract(c *cobra.Command) CommandContract {
+	contract := CommandContract{
+		Path:       c.CommandPath(),
+		Use:        c.Use,
+		Short:      c.Short,
+		Aliases:    c.Aliases,
+		Deprecated: c.Deprecated,
+		Runnable:   c.Runnable(),
+	}
+	persistent := c.PersistentFlags()
+	c.LocalNonPersistentFlags().VisitAll(func(fl *pflag.Flag) {
+		contract.Flags = append(contract.Flags, flagContract(fl, false))
+	})
+	persistent.VisitAll(func(fl *pflag.Flag) {
+		contract.Flags = append(contract.Flags, flagContract(fl, true))
+	})
+	return contract
+}
+
+func flagContract(fl *pflag.Flag, persistent bool) FlagContract {
+	return FlagContract{
+		Name:       fl.Name,
+		Shorthand:  fl.Shorthand,
+		Type:       fl.Value.Type(),
+		Default:    fl.DefValue,
+		Usage:      fl.Usage,
+		Persistent: persistent,
+		Deprecated: fl.Deprecated,
+	}
+}
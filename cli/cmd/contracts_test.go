@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/easel/ddx/internal/exitcodes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContracts(t *testing.T) {
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	output, err := executeCommand(NewCommandFactory(t.TempDir()).NewRootCommand(), "contracts")
+	require.NoError(t, err, output)
+
+	var contract Contract
+	require.NoError(t, json.Unmarshal([]byte(output), &contract), output)
+	assert.Equal(t, exitcodes.All(), contract.ExitCodes)
+
+	commands := map[string]CommandContract{}
+	for _, c := range contract.Commands {
+		commands[c.Path] = c
+	}
+	flag := func(path, name string) (FlagContract, bool) {
+		for _, fl := range commands[path].Flags {
+			if fl.Name == name {
+				return fl, true
+			}
+		}
+		return FlagContract{}, false
+	}
+
+	project, ok := flag("ddx", "project")
+	require.True(t, ok)
+	assert.True(t, project.Persistent)
+	assert.Equal(t, "string", project.Type)
+
+	yes, ok := flag("ddx exec", "yes")
+	require.True(t, ok)
+	assert.Equal(t, "bool", yes.Type)
+	assert.Equal(t, "false", yes.Default)
+	assert.False(t, yes.Persistent)
+	_, inherited := flag("ddx exec", "project")
+	assert.False(t, inherited, "inherited flags are listed with the command defining them")
+
+	assert.True(t, commands["ddx workspace list"].Runnable)
+	assert.False(t, commands["ddx workspace"].Runnable)
+	assert.NotContains(t, commands, "ddx help")
+}
@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/easel/ddx/internal/auth"
 	"github.com/easel/ddx/internal/config"
 	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/library"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +27,13 @@ type ContributeOptions struct {
 	DryRun       bool
 	CreatePR     bool
 	ResourcePath string
+	// ChangelogType overrides the change type of the changelog fragments
+	ChangelogType string
+	// NoChangelog skips writing changelog fragments
+	NoChangelog bool
+	// Source names the library source to contribute from, empty for the
+	// main library
+	Source string
 }
 
 // ContributeResult represents the result of a contribute operation
@@ -33,6 +45,9 @@ type ContributeResult struct {
 	ValidationResults []ValidationResult
 	PRInfo            *PRInfo
 	DryRunPreview     *DryRunInfo
+	AuthNotice        string
+	// Changelog lists the fragments written to the library
+	Changelog []library.Fragment
 }
 
 // ValidationResult represents validation check results
@@ -57,6 +72,8 @@ type DryRunInfo struct {
 	FilesCount         int
 	HasDocumentation   bool
 	ValidationWarnings []string
+	// Changelog lists the fragments that would be written
+	Changelog []library.Fragment
 }
 
 // CommandFactory method - CLI interface layer
@@ -99,8 +116,19 @@ func performContribution(workingDir string, opts *ContributeOptions) (*Contribut
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Another library source is contributed to its own repository
+	if opts.Source != "" && opts.Source != config.MainSource {
+		if cfg, err = sourceConfig(cfg, opts.Source); err != nil {
+			return nil, err
+		}
+		if opts.ResourcePath, err = sourceResourcePath(workingDir, cfg.Library.Path); err != nil {
+			return nil, err
+		}
+		result.ResourcePath = opts.ResourcePath
+	}
+
 	// Check if DDx subtree exists
-	hasSubtree, err := checkForSubtreeInDir(workingDir)
+	hasSubtree, err := checkForSubtreeInDir(workingDir, contributionPrefix(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -115,22 +143,34 @@ func performContribution(workingDir string, opts *ContributeOptions) (*Contribut
 		return nil, err
 	}
 
-	// Check if there are uncommitted changes in the DDx library
+	// Check if the library has changes to contribute
 	libraryPath := getResourcePath(workingDir, opts.ResourcePath)
-	hasChanges, err := checkForChangesInDir(workingDir, libraryPath)
+	hasChanges, err := checkForChangesInDir(workingDir, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	if !hasChanges {
 		result.Success = false
-		result.Message = "No changes detected in .ddx/library"
+		result.Message = "No changes detected in " + contributionPrefix(opts)
 		return result, nil
 	}
 
+	// Describe each changed asset for the library's changelog
+	var fragments []library.Fragment
+	if !opts.NoChangelog {
+		if fragments, err = contributionFragments(workingDir, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	// Perform dry-run if requested
 	if opts.DryRun {
-		return performDryRunInDir(workingDir, cfg, opts)
+		dryRun, err := performDryRunInDir(workingDir, cfg, opts)
+		if err == nil {
+			dryRun.DryRunPreview.Changelog = fragments
+		}
+		return dryRun, err
 	}
 
 	// Validate contribution
@@ -148,8 +188,30 @@ func performContribution(workingDir string, opts *ContributeOptions) (*Contribut
 		}
 	}
 
+	// Select SSH or HTTPS access to the library before pushing
+	ctx := context.Background()
+	remote, err := resolveLibraryRemote(ctx, workingDir, cfg.Library.Repository.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit the changes with their fragments so the PR carries its changelog entry
+	commit, err := commitContribution(workingDir, libraryPath, opts, fragments)
+	if err != nil {
+		return nil, err
+	}
+
 	// Perform the actual contribution
-	return executeContributionInDir(workingDir, cfg, opts)
+	contribution, err := executeContributionInDir(workingDir, cfg, opts, remote.URL)
+	if err != nil {
+		if undoErr := commit.undo(); undoErr != nil {
+			return nil, fmt.Errorf("%w\n\nfailed to undo the contribution commit: %v", err, undoErr)
+		}
+		return nil, err
+	}
+	contribution.AuthNotice = libraryRemoteNotice(ctx, cfg.Library.Repository.URL, remote)
+	contribution.Changelog = commit.fragments
+	return contribution, nil
 }
 
 // Helper functions for working directory-based operations
@@ -163,6 +225,15 @@ func extractContributeOptions(cmd *cobra.Command) (*ContributeOptions, error) {
 	opts.Branch, _ = cmd.Flags().GetString("branch")
 	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
 	opts.CreatePR, _ = cmd.Flags().GetBool("create-pr")
+	opts.ChangelogType, _ = cmd.Flags().GetString("changelog-type")
+	opts.NoChangelog, _ = cmd.Flags().GetBool("no-changelog")
+	opts.Source, _ = cmd.Flags().GetString("source")
+
+	if opts.ChangelogType != "" {
+		if err := library.CheckChangeType(opts.ChangelogType); err != nil {
+			return nil, err
+		}
+	}
 
 	return opts, nil
 }
@@ -190,12 +261,8 @@ func loadConfigFromWorkingDirForContribute(workingDir string) (*config.Config, e
 		return config.Load()
 	}
 
-	configPath := filepath.Join(workingDir, ".ddx/config.yaml")
-	if _, err := os.Stat(configPath); err == nil {
-		return config.LoadFromFile(configPath)
-	}
-
-	return config.Load()
+	// Load through the standard chain so the active profile is honored
+	return config.LoadWithWorkingDir(workingDir)
 }
 
 func getResourcePath(workingDir, resourcePath string) string {
@@ -205,10 +272,25 @@ func getResourcePath(workingDir, resourcePath string) string {
 	return filepath.Join(".ddx", resourcePath)
 }
 
-func checkForSubtreeInDir(workingDir string) (bool, error) {
-	// Check actual git subtree (library is at .ddx/library not .ddx)
-	ddxPath := ".ddx/library"
+// contributionPrefix returns the subtree prefix of the contributed library
+func contributionPrefix(opts *ContributeOptions) string {
+	return ".ddx/" + filepath.ToSlash(opts.ResourcePath)
+}
 
+// sourceResourcePath returns a library source's path relative to .ddx,
+// where contributions are made from
+func sourceResourcePath(workingDir, libPath string) (string, error) {
+	if !filepath.IsAbs(libPath) {
+		libPath = filepath.Join(workingDir, libPath)
+	}
+	rel, err := filepath.Rel(filepath.Join(workingDir, ".ddx"), libPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("library at %s is not under .ddx - contributions need a subtree there", libPath)
+	}
+	return rel, nil
+}
+
+func checkForSubtreeInDir(workingDir, ddxPath string) (bool, error) {
 	// Change to working directory if specified (git commands need to run in the repo)
 	if workingDir != "" {
 		currentDir, err := os.Getwd()
@@ -247,25 +329,135 @@ func prepareContributionDetails(opts *ContributeOptions) error {
 	return nil
 }
 
-func checkForChangesInDir(workingDir, fullPath string) (bool, error) {
-	// Check git for uncommitted changes
-	ddxPath := ".ddx"
+// checkForChangesInDir reports whether the library has changes to
+// contribute, committed since it was last synced or in the working tree
+func checkForChangesInDir(workingDir string, opts *ContributeOptions) (bool, error) {
+	changes, err := libraryChanges(workingDir, contributionPrefix(opts))
+	return len(changes) > 0, err
+}
 
-	// Change to working directory if specified (git commands need to run in the repo)
-	if workingDir != "" {
-		currentDir, err := os.Getwd()
+// libraryChanges lists the files of the library at prefix that a
+// contribution pushes, mapped to their git status letter: the files changed
+// by commits since the library was last synced and those changed in the
+// working tree, which the contribution commits. Paths are relative to the
+// library.
+func libraryChanges(workingDir, prefix string) (map[string]string, error) {
+	dir := workingDir
+	if dir == "" {
+		dir = "."
+	}
+	base := git.SubtreeSyncCommit(dir, prefix)
+	if base == "" {
+		base = "HEAD"
+	}
+	run := func(args ...string) ([]string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workingDir
+		output, err := cmd.Output()
 		if err != nil {
-			return false, fmt.Errorf("failed to get current directory: %w", err)
+			return nil, fmt.Errorf("failed to list library changes: %w", err)
 		}
-		defer func() { _ = os.Chdir(currentDir) }() // Restore after check
+		return strings.Split(strings.TrimRight(string(output), "\x00"), "\x00"), nil
+	}
 
-		if err := os.Chdir(workingDir); err != nil {
-			return false, fmt.Errorf("failed to change to working directory: %w", err)
+	changes := make(map[string]string)
+	diff, err := run("diff", "--name-status", "-z", "--no-renames", base, "--", prefix+"/")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(diff); i += 2 {
+		changes[strings.TrimPrefix(diff[i+1], prefix+"/")] = diff[i][:1]
+	}
+	untracked, err := run("ls-files", "--others", "--exclude-standard", "-z", "--", prefix+"/")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range untracked {
+		if path != "" {
+			changes[strings.TrimPrefix(path, prefix+"/")] = "A"
 		}
 	}
+	return changes, nil
+}
 
-	hasChanges, err := git.HasUncommittedChanges(ddxPath)
-	return hasChanges, err
+// contributionCommit is a contribution committed to the project before it
+// is pushed, so that a failed push can be undone
+type contributionCommit struct {
+	workingDir  string
+	libraryPath string
+	// prefix is the library's path in the project, the only path committed
+	prefix string
+	// head is the commit checked out before the contribution
+	head string
+	// fragments are the changelog fragments written for the contribution
+	fragments []library.Fragment
+}
+
+// commitContribution writes the changelog fragments and commits them with
+// the library's uncommitted changes, which a subtree push would otherwise
+// leave behind. Nothing is committed when the library has no such changes.
+func commitContribution(workingDir, libraryPath string, opts *ContributeOptions, fragments []library.Fragment) (*contributionCommit, error) {
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workingDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %w\n%s", args[0], err, strings.TrimSpace(string(output)))
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	head, err := run("rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	prefix := contributionPrefix(opts)
+	commit := &contributionCommit{workingDir: workingDir, libraryPath: libraryPath, prefix: prefix, head: head}
+	for _, fragment := range fragments {
+		fragment, err := library.WriteFragment(libraryPath, fragment, time.Now())
+		if err != nil {
+			_ = commit.undo()
+			return nil, err
+		}
+		commit.fragments = append(commit.fragments, fragment)
+	}
+
+	if _, err := run("add", "-A", "--", prefix); err != nil {
+		_ = commit.undo()
+		return nil, err
+	}
+	// Only the library is committed; other staged work stays staged
+	if _, err := run("diff", "--cached", "--quiet", "--", prefix); err == nil {
+		return commit, nil
+	}
+	if _, err := run("commit", "--quiet", "-m", opts.Message, "--", prefix); err != nil {
+		_ = commit.undo()
+		return nil, err
+	}
+	return commit, nil
+}
+
+// undo returns the project to where it was before the contribution: the
+// commits it made are reset, keeping the library's changes in the working
+// tree, and its changelog fragments are removed. Only the library is
+// unstaged; other staged work stays staged.
+func (c *contributionCommit) undo() error {
+	for _, args := range [][]string{
+		{"reset", "--quiet", "--soft", c.head},
+		{"reset", "--quiet", c.head, "--", c.prefix},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = c.workingDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git reset failed: %w\n%s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	for _, fragment := range c.fragments {
+		if err := os.Remove(filepath.Join(c.libraryPath, fragment.Path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
 }
 
 func performDryRunInDir(workingDir string, cfg *config.Config, opts *ContributeOptions) (*ContributeResult, error) {
@@ -383,7 +575,7 @@ func validateContributionInDir(workingDir string, cfg *config.Config, opts *Cont
 	return results, nil
 }
 
-func executeContributionInDir(workingDir string, cfg *config.Config, opts *ContributeOptions) (*ContributeResult, error) {
+func executeContributionInDir(workingDir string, cfg *config.Config, opts *ContributeOptions, repoURL string) (*ContributeResult, error) {
 	// Determine contribution branch
 	contributionBranch := opts.Branch
 	if contributionBranch == "" {
@@ -405,8 +597,7 @@ func executeContributionInDir(workingDir string, cfg *config.Config, opts *Contr
 	}
 
 	// Execute git subtree push to contribute changes
-	prefix := ".ddx/library"
-	repoURL := cfg.Library.Repository.URL
+	prefix := contributionPrefix(opts)
 
 	err := git.SubtreePush(prefix, repoURL, contributionBranch)
 	if err != nil {
@@ -432,7 +623,13 @@ func executeContributionInDir(workingDir string, cfg *config.Config, opts *Contr
 
 // generatePRInstructions creates PR information for the user
 func generatePRInstructions(cfg *config.Config, branch string, opts *ContributeOptions) *PRInfo {
-	repoURL := strings.TrimSuffix(cfg.Library.Repository.URL, ".git")
+	repoURL := cfg.Library.Repository.URL
+	if auth.IsSSHURL(repoURL) {
+		if httpsURL, err := auth.SSHToHTTPS(repoURL); err == nil {
+			repoURL = httpsURL
+		}
+	}
+	repoURL = strings.TrimSuffix(repoURL, ".git")
 	baseBranch := cfg.Library.Repository.Branch
 	if baseBranch == "" {
 		baseBranch = "master"
@@ -467,6 +664,65 @@ func wrapContributionError(err error) error {
 	return fmt.Errorf("failed to push contribution: %w", err)
 }
 
+// contributionFragments builds a changelog fragment for each asset the
+// contribution changes in the library, as listed by libraryChanges. New
+// assets are added and deleted ones removed; otherwise the type follows the
+// contribution message's conventional commit prefix. Assets that already
+// have a pending fragment are skipped.
+func contributionFragments(workingDir string, opts *ContributeOptions) ([]library.Fragment, error) {
+	files, err := libraryChanges(workingDir, contributionPrefix(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	// Track whether every file of an asset was added or deleted
+	type assetChange struct{ added, removed bool }
+	changes := make(map[string]*assetChange)
+	for path, status := range files {
+		name := library.AssetName(path)
+		if name == "" {
+			continue
+		}
+		added, removed := status == "A", status == "D"
+		if change, ok := changes[name]; ok {
+			change.added = change.added && added
+			change.removed = change.removed && removed
+		} else {
+			changes[name] = &assetChange{added: added, removed: removed}
+		}
+	}
+
+	pending, err := library.LoadFragments(getResourcePath(workingDir, opts.ResourcePath))
+	if err != nil {
+		return nil, err
+	}
+	for _, fragment := range pending {
+		delete(changes, fragment.Name)
+	}
+
+	summary, messageType := library.SummaryType(opts.Message)
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fragments []library.Fragment
+	for _, name := range names {
+		changeType := messageType
+		switch {
+		case opts.ChangelogType != "":
+			changeType = opts.ChangelogType
+		case changes[name].added:
+			changeType = library.ChangeAdded
+		case changes[name].removed:
+			changeType = library.ChangeRemoved
+		}
+		fragments = append(fragments, library.Fragment{Type: changeType, Name: name, Summary: summary})
+	}
+	return fragments, nil
+}
+
 // Helper functions for validation
 func countFilesInDirForContribute(dir string) (int, error) {
 	count := 0
@@ -576,9 +832,9 @@ func displayContributeResult(cmd *cobra.Command, result *ContributeResult, opts
 
 	// Display initial message
 	if opts.DryRun {
-		_, _ = cyan.Fprintln(out, "🔍 Dry run: Contributing changes from .ddx/library")
+		_, _ = cyan.Fprintln(out, "🔍 Dry run: Contributing changes from "+contributionPrefix(opts))
 	} else {
-		_, _ = cyan.Fprintln(out, "🚀 Contributing changes from .ddx/library")
+		_, _ = cyan.Fprintln(out, "🚀 Contributing changes from "+contributionPrefix(opts))
 	}
 	_, _ = fmt.Fprintln(out)
 
@@ -593,6 +849,10 @@ func displayContributeResult(cmd *cobra.Command, result *ContributeResult, opts
 		return displayDryRunContributeResult(out, result)
 	}
 
+	if result.AuthNotice != "" {
+		_, _ = yellow.Fprintf(out, "⚠️  %s\n\n", result.AuthNotice)
+	}
+
 	// Display validation results
 	_, _ = fmt.Fprintln(out, "🔍 Validating contribution...")
 	_, _ = fmt.Fprintln(out, "")
@@ -615,6 +875,14 @@ func displayContributeResult(cmd *cobra.Command, result *ContributeResult, opts
 	_, _ = green.Fprintln(out, "✅", result.Message)
 	_, _ = fmt.Fprintln(out)
 
+	if len(result.Changelog) > 0 {
+		_, _ = fmt.Fprintln(out, "📰 Changelog fragments:")
+		for _, fragment := range result.Changelog {
+			_, _ = fmt.Fprintf(out, "   %s (%s %s)\n", fragment.Path, fragment.Type, fragment.Name)
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
 	// Display branch information
 	_, _ = fmt.Fprintf(out, "Branch: %s\n", yellow.Sprint(result.Branch))
 	_, _ = fmt.Fprintf(out, "Resource: %s\n", yellow.Sprint(result.ResourcePath))
@@ -677,6 +945,10 @@ func displayDryRunContributeResult(out interface{}, result *ContributeResult) er
 		_, _ = fmt.Fprintf(writer, "%s", green.Sprintf("✓ Target branch: %s\n", preview.Branch))
 		_, _ = fmt.Fprintf(writer, "%s", green.Sprintf("✓ Files to contribute: %d\n", preview.FilesCount))
 
+		for _, fragment := range preview.Changelog {
+			_, _ = fmt.Fprintf(writer, "%s", green.Sprintf("✓ Changelog fragment: %s %s\n", fragment.Type, fragment.Name))
+		}
+
 		if preview.HasDocumentation {
 			_, _ = fmt.Fprintln(writer, green.Sprint("✓ Documentation found (README.md)"))
 		} else {
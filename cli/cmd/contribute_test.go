@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/library"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContributionFragments(t *testing.T) {
+	te := NewTestEnvironment(t)
+	te.CreateFile(".ddx/library/personas/old.md", "# Old\n")
+	te.CreateFile(".ddx/library/prompts/common/review.md", "# Review\n")
+	te.CreateFile(".ddx/library/workflows/helix/workflow.yml", "name: helix\n")
+	runGitIn(t, te.Dir, "add", ".")
+	runGitIn(t, te.Dir, "commit", "--quiet", "-m", "Squashed library\n\ngit-subtree-dir: .ddx/library\ngit-subtree-split: 0000000")
+
+	// A change committed since the sync is pushed along with uncommitted ones
+	require.NoError(t, os.Remove(filepath.Join(te.Dir, ".ddx/library/personas/old.md")))
+	runGitIn(t, te.Dir, "commit", "--quiet", "-am", "Remove old persona")
+	te.CreateFile(".ddx/library/prompts/common/review.md", "# Review\n\nMore steps.\n")
+	te.CreateFile(".ddx/library/prompts/common/new.md", "# New\n")
+	te.CreateFile(".ddx/library/workflows/helix/phases/frame.md", "# Frame\n")
+	te.CreateFile(".ddx/library/workflows/helix/workflow.yml", "name: helix\nversion: 2.0.0\n")
+
+	opts := &ContributeOptions{ResourcePath: "library", Message: "fix: tighten review steps"}
+	fragments, err := contributionFragments(te.Dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []library.Fragment{
+		{Type: library.ChangeRemoved, Name: "personas/old", Summary: "tighten review steps"},
+		{Type: library.ChangeAdded, Name: "prompts/common/new", Summary: "tighten review steps"},
+		{Type: library.ChangeFixed, Name: "prompts/common/review", Summary: "tighten review steps"},
+		{Type: library.ChangeFixed, Name: "workflows/helix", Summary: "tighten review steps"},
+	}, fragments)
+
+	t.Run("type override", func(t *testing.T) {
+		opts := &ContributeOptions{ResourcePath: "library", Message: "fix: tighten review steps", ChangelogType: library.ChangeChanged}
+		fragments, err := contributionFragments(te.Dir, opts)
+		require.NoError(t, err)
+		for _, fragment := range fragments {
+			assert.Equal(t, library.ChangeChanged, fragment.Type, fragment.Name)
+		}
+	})
+
+	t.Run("pending fragments are not repeated", func(t *testing.T) {
+		_, err := library.WriteFragment(filepath.Join(te.Dir, ".ddx", "library"),
+			library.Fragment{Type: library.ChangeAdded, Name: "prompts/common/new", Summary: "Add new"}, time.Now())
+		require.NoError(t, err)
+		fragments, err := contributionFragments(te.Dir, opts)
+		require.NoError(t, err)
+		assert.Len(t, fragments, 3)
+		for _, fragment := range fragments {
+			assert.NotEqual(t, "prompts/common/new", fragment.Name)
+		}
+	})
+}
+
+func TestCommitContribution(t *testing.T) {
+	te := NewTestEnvironment(t)
+	te.CreateFile(".ddx/library/prompts/common/review.md", "# Review\n")
+	te.CreateFile("notes.md", "# Notes\n")
+	runGitIn(t, te.Dir, "add", ".ddx")
+	runGitIn(t, te.Dir, "commit", "--quiet", "-m", "library")
+	head := runGitIn(t, te.Dir, "rev-parse", "HEAD")
+
+	te.CreateFile(".ddx/library/prompts/common/review.md", "# Review\n\nMore steps.\n")
+	runGitIn(t, te.Dir, "add", "notes.md")
+	libraryPath := filepath.Join(te.Dir, ".ddx", "library")
+	opts := &ContributeOptions{ResourcePath: "library", Message: "fix: tighten review steps"}
+	fragments, err := contributionFragments(te.Dir, opts)
+	require.NoError(t, err)
+	commit, err := commitContribution(te.Dir, libraryPath, opts, fragments)
+	require.NoError(t, err)
+
+	// The change and its fragment are committed; other project files are not
+	require.Len(t, commit.fragments, 1)
+	assert.Equal(t, "fix: tighten review steps", runGitIn(t, te.Dir, "log", "-1", "--format=%s"))
+	committed := runGitIn(t, te.Dir, "show", "--name-only", "--format=", "HEAD")
+	assert.Contains(t, committed, ".ddx/library/prompts/common/review.md")
+	assert.Contains(t, committed, ".ddx/library/"+commit.fragments[0].Path)
+	assert.NotContains(t, committed, "notes.md")
+	assert.Contains(t, runGitIn(t, te.Dir, "status", "--porcelain"), "A  notes.md")
+
+	// A failed push undoes the commit and removes the fragment, leaving
+	// unrelated staged files staged and the library change unstaged
+	require.NoError(t, commit.undo())
+	assert.Equal(t, head, runGitIn(t, te.Dir, "rev-parse", "HEAD"))
+	assert.Equal(t, "notes.md", runGitIn(t, te.Dir, "diff", "--cached", "--name-only"))
+	assert.NoFileExists(t, filepath.Join(libraryPath, commit.fragments[0].Path))
+	content, err := os.ReadFile(filepath.Join(libraryPath, "prompts", "common", "review.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "More steps.")
+}
+
+func TestLibraryChangelogAssemble(t *testing.T) {
+	te := NewTestEnvironment(t, WithGitInit(false))
+	te.CreateFile("lib/changelog/a.yml", "type: added\nname: prompts/common/new\nsummary: Add a new prompt\n")
+
+	output, err := te.RunCommand("library", "changelog", "assemble", "--path", "lib", "--version", "1.2.0", "--date", "2025-06-01", "--dry-run")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "## 1.2.0 - 2025-06-01")
+	assert.Contains(t, output, "- **prompts/common/new**: Add a new prompt")
+	assert.NoFileExists(t, filepath.Join(te.Dir, "lib", library.ChangelogFile))
+
+	output, err = te.RunCommand("library", "changelog", "assemble", "--path", "lib", "--version", "1.2.0", "--date", "2025-06-01")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Assembled 1 fragment into CHANGELOG.md for 1.2.0")
+	assert.FileExists(t, filepath.Join(te.Dir, "lib", library.ChangelogFile))
+	assert.NoFileExists(t, filepath.Join(te.Dir, "lib", "changelog", "a.yml"))
+
+	output, err = te.RunCommand("library", "changelog", "assemble", "--path", "lib", "--version", "1.3.0")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "No changelog fragments to assemble")
+
+	_, err = te.RunCommand("library", "changelog", "assemble", "--path", "lib")
+	assert.ErrorContains(t, err, `required flag(s) "version" not set`)
+}
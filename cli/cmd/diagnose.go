@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/diagnose"
+	"github.com/easel/ddx/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// diagnoseEnv are the environment variables, besides DDX_*, that a support
+// bundle records
+var diagnoseEnv = []string{
+	"SHELL", "TERM", "LANG", "NO_COLOR", "CI", "GITHUB_ACTIONS",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "SSL_CERT_FILE",
+}
+
+// DiagnoseVersion is version.json in a support bundle
+type DiagnoseVersion struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	Built      string `json:"built"`
+	Go         string `json:"go"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Executable string `json:"executable,omitempty"`
+}
+
+// DiagnoseEnvironment is environment.json in a support bundle
+type DiagnoseEnvironment struct {
+	WorkingDir string            `json:"working_dir"`
+	Git        string            `json:"git,omitempty"`
+	CPUs       int               `json:"cpus"`
+	Path       []string          `json:"path"`
+	Variables  map[string]string `json:"variables"`
+}
+
+// DiagnoseCheck is a project check in doctor.json
+type DiagnoseCheck struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+	Fix     []string    `json:"fix,omitempty"`
+}
+
+// runDiagnose implements the diagnose command
+func (f *CommandFactory) runDiagnose(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	output, _ := cmd.Flags().GetString("output")
+	limit, _ := cmd.Flags().GetInt("history")
+	if output == "" {
+		output = fmt.Sprintf("ddx-diagnose-%s.tar.gz", now.Format("20060102-150405"))
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(f.WorkingDir, output)
+	}
+
+	files, err := f.collectDiagnostics(now, limit)
+	if err != nil {
+		return err
+	}
+	home, _ := os.UserHomeDir()
+	var buf bytes.Buffer
+	dir := strings.TrimSuffix(filepath.Base(output), ".tar.gz")
+	if err := diagnose.Write(&buf, dir, files, diagnose.Redactor{Home: home}, now); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(output, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "📦 Wrote support bundle %s\n", output)
+	for _, file := range files {
+		_, _ = fmt.Fprintf(out, "   %s\n", file.Name)
+	}
+	_, _ = fmt.Fprintln(statusOut(cmd), "\nTokens, passwords and your home directory have been redacted. Review the bundle before attaching it to a bug report.")
+	return nil
+}
+
+// collectDiagnostics gathers the files of a support bundle. Project files
+// are left out when the working directory is not a DDx project.
+func (f *CommandFactory) collectDiagnostics(now time.Time, historyLimit int) ([]diagnose.File, error) {
+	var files []diagnose.File
+	add := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		files = append(files, diagnose.File{Name: name, Data: append(data, '\n')})
+		return nil
+	}
+
+	version := DiagnoseVersion{Version: f.Version, Commit: f.Commit, Built: f.Date, Go: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+	version.Executable, _ = os.Executable()
+	if err := add("version.json", version); err != nil {
+		return nil, err
+	}
+	if err := add("environment.json", diagnoseEnvironment(f.WorkingDir)); err != nil {
+		return nil, err
+	}
+	if !isInitializedInDir(f.WorkingDir) {
+		return files, nil
+	}
+
+	values, err := configEffective(f.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	var cfg strings.Builder
+	for _, v := range values {
+		value := v.Value
+		if diagnose.Sensitive(v.Key) {
+			value = diagnose.Redacted
+		}
+		_, _ = fmt.Fprintf(&cfg, "%s = %s  # %s %s\n", v.Key, value, v.Source, v.Path)
+	}
+	files = append(files, diagnose.File{Name: "config.txt", Data: []byte(cfg.String())})
+
+	if err := add("project.json", collectProjectOverview(f.WorkingDir)); err != nil {
+		return nil, err
+	}
+	checks := diagnoseProject(f.WorkingDir, now)
+	report := struct {
+		Score  int             `json:"score"`
+		Checks []DiagnoseCheck `json:"checks"`
+	}{Score: projectHealthScore(checks)}
+	for _, c := range checks {
+		report.Checks = append(report.Checks, DiagnoseCheck{Name: c.Name, Status: c.Status, Message: c.Message, Fix: c.Fix})
+	}
+	if err := add("doctor.json", report); err != nil {
+		return nil, err
+	}
+
+	entries, err := history.Read(f.WorkingDir, historyLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		var lines bytes.Buffer
+		for _, e := range entries {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode history: %w", err)
+			}
+			lines.Write(append(data, '\n'))
+		}
+		files = append(files, diagnose.File{Name: "history.jsonl", Data: lines.Bytes()})
+	}
+	return files, nil
+}
+
+// diagnoseEnvironment describes the machine and environment ddx runs in.
+// Variables with credential names are redacted.
+func diagnoseEnvironment(workingDir string) DiagnoseEnvironment {
+	env := DiagnoseEnvironment{
+		WorkingDir: workingDir,
+		CPUs:       runtime.NumCPU(),
+		Path:       filepath.SplitList(os.Getenv("PATH")),
+		Variables:  map[string]string{},
+	}
+	if out, err := exec.Command("git", "--version").Output(); err == nil {
+		env.Git = strings.TrimSpace(string(out))
+	}
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, "DDX_") && !slices.Contains(diagnoseEnv, name) {
+			continue
+		}
+		if diagnose.Sensitive(name) {
+			value = diagnose.Redacted
+		}
+		env.Variables[name] = value
+	}
+	return env
+}
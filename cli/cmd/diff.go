@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff returns a unified diff from one text to another, or an empty
+// string when they are equal
+func unifiedDiff(from, to, fromName, toName string) string {
+	if from == to {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// writeDiff prints a unified diff, coloring added and removed lines
+func writeDiff(w io.Writer, diff string) {
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+	cyan := color.New(color.FgCyan)
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			_, _ = fmt.Fprint(w, line)
+		case strings.HasPrefix(line, "@@"):
+			_, _ = cyan.Fprint(w, line)
+		case strings.HasPrefix(line, "+"):
+			_, _ = green.Fprint(w, line)
+		case strings.HasPrefix(line, "-"):
+			_, _ = red.Fprint(w, line)
+		default:
+			_, _ = fmt.Fprint(w, line)
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
 	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/network"
+	"github.com/easel/ddx/internal/pathsetup"
+	"github.com/easel/ddx/internal/userdirs"
 	"github.com/spf13/cobra"
 )
 
@@ -43,7 +50,21 @@ func (f *CommandFactory) runDoctor(cmd *cobra.Command, args []string) error {
 
 	// Check 2: PATH Configuration
 	fmt.Print("✓ Checking PATH Configuration... ")
-	if isInPath() {
+	if found, ok := lookPathBinary(); ok && isDDxBinary(executable) && !pathsetup.SameExecutable(found, executable) {
+		fmt.Printf("⚠️  Another ddx comes first in PATH (%s)\n", found)
+		issues = append(issues, DiagnosticIssue{
+			Type:        "path_configuration",
+			Description: fmt.Sprintf("'ddx' runs %s, not %s", found, executable),
+			Remediation: []string{
+				"Remove the older binary",
+				fmt.Sprintf("Run 'ddx setup path' to put %s first", filepath.Dir(executable)),
+			},
+			SystemInfo: map[string]string{
+				"shell": os.Getenv("SHELL"),
+				"path":  os.Getenv("PATH"),
+			},
+		})
+	} else if ok {
 		fmt.Println("✅ PATH Configuration")
 	} else {
 		fmt.Println("⚠️  DDX not found in PATH")
@@ -115,27 +136,50 @@ func (f *CommandFactory) runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Check 6: Network Connectivity
+	// Check 6: Network Connectivity, or where the library comes from when
+	// offline
 	fmt.Print("✓ Checking Network... ")
-	if checkNetwork() {
-		fmt.Println("✅ Network Connectivity")
+	proxy := network.ProxyFor(network.ProbeURL)
+	if offline, _ := cmd.Flags().GetBool("offline"); offline || network.Current().Offline {
+		if err := checkOfflineLibrary(f.WorkingDir); err != nil {
+			fmt.Println("⚠️  Offline, library repository unreachable")
+			fmt.Printf("   %s\n", err)
+			issues = append(issues, DiagnosticIssue{
+				Type:        "offline_library",
+				Description: err.Error(),
+				Remediation: []string{
+					"Point library.repository.url at a local mirror or bundle file",
+					"Import updates into the mirror with 'ddx library import-bundle <file>'",
+				},
+			})
+		} else {
+			fmt.Println("⏭️  Network Connectivity (offline mode, not checked)")
+		}
+	} else if err := checkNetwork(); err == nil {
+		if proxy != "" {
+			fmt.Printf("✅ Network Connectivity (via proxy %s)\n", proxy)
+		} else {
+			fmt.Println("✅ Network Connectivity")
+		}
 	} else {
+		diagnosis := network.Diagnose(err, proxy)
 		fmt.Println("⚠️  Network Issues (optional)")
+		fmt.Printf("   %s\n", diagnosis.Problem)
 
-		// Check for problem simulation
+		// Proxy and certificate problems are always explained, since
+		// they block every download until they are fixed
 		problemState := os.Getenv("DDX_PROBLEM_STATE")
-		if problemState == "network_issue" || verbose {
+		caFile := network.Current().CAFile
+		if problemState == "network_issue" || verbose || proxy != "" || caFile != "" {
 			issues = append(issues, DiagnosticIssue{
 				Type:        "network_connectivity",
-				Description: "Unable to reach external repositories",
-				Remediation: []string{
-					"Check internet connection",
-					"Verify proxy settings",
-					"Try offline installation",
-				},
+				Description: diagnosis.Problem,
+				Remediation: diagnosis.Remediation,
 				SystemInfo: map[string]string{
-					"dns_server": "Check /etc/resolv.conf or network settings",
-					"proxy":      os.Getenv("HTTP_PROXY"),
+					"probe_url": network.ProbeURL,
+					"proxy":     proxy,
+					"no_proxy":  firstEnv("NO_PROXY", "no_proxy"),
+					"ca_file":   caFile,
 				},
 			})
 		}
@@ -218,6 +262,34 @@ func (f *CommandFactory) runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check 10: User Directories, which may still be split with ~/.ddx
+	fmt.Print("✓ Checking User Directories... ")
+	if layout, err := userdirs.Inspect(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else {
+		status, issue := checkUserDirs(layout)
+		fmt.Println(status)
+		if verbose {
+			for _, kind := range userdirs.Kinds {
+				fmt.Printf("   %-6s %s\n", kind, layout.Dirs[kind])
+			}
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	// Project-level checks run inside DDx projects
+	if checks := diagnoseProject(f.WorkingDir, time.Now()); checks != nil {
+		fmt.Println()
+		printProjectChecks(cmd.OutOrStdout(), checks)
+		for _, check := range checks {
+			if check.Status == CheckFail {
+				allGood = false
+			}
+		}
+	}
+
 	fmt.Println()
 	if allGood && len(issues) == 0 {
 		fmt.Println("🎉 All critical checks passed! DDX is ready to use.")
@@ -237,10 +309,21 @@ func (f *CommandFactory) runDoctor(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// isInPath checks if DDX is accessible from PATH
-func isInPath() bool {
-	_, err := exec.LookPath("ddx")
-	return err == nil
+// isDDxBinary reports whether a path names a ddx executable rather than,
+// say, a test binary
+func isDDxBinary(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return path != "" && strings.TrimSuffix(name, ".exe") == "ddx"
+}
+
+// lookPathBinary returns the ddx that runs when 'ddx' is typed. On Windows
+// the lookup follows PATHEXT, so ddx.exe is found.
+func lookPathBinary() (string, bool) {
+	found, err := exec.LookPath("ddx")
+	if err != nil {
+		return "", false
+	}
+	return found, true
 }
 
 // checkConfiguration validates the DDX configuration
@@ -263,11 +346,51 @@ func checkGitSubtree() bool {
 	return cmd.Run() == nil
 }
 
-// checkNetwork tests basic network connectivity
-func checkNetwork() bool {
-	// Simple check - try to resolve a hostname
-	_, err := exec.Command("ping", "-c", "1", "github.com").Output()
-	return err == nil
+// checkOfflineLibrary checks that the project's library repository can be
+// reached without the network: a mirror or bundle on the filesystem that
+// exists. Outside a project there is nothing to check.
+func checkOfflineLibrary(workingDir string) error {
+	if !isInitializedInDir(workingDir) {
+		return nil
+	}
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil || cfg.Library == nil || cfg.Library.Repository == nil {
+		return nil
+	}
+	repoURL := cfg.Library.Repository.URL
+	if !git.IsLocalURL(repoURL) {
+		return fmt.Errorf("library.repository.url %s needs the network", repoURL)
+	}
+	path := git.LocalPath(libraryURL(workingDir, repoURL))
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("library mirror %s does not exist", path)
+	}
+	return nil
+}
+
+// checkNetwork fetches the probe URL through the configured proxy and
+// certificate authorities, without retries so a failure shows quickly
+func checkNetwork() error {
+	settings := network.Current()
+	settings.Retries = 0
+	settings.Timeout = min(settings.Timeout, 10*time.Second)
+	client, err := network.NewClient(settings)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return network.Probe(ctx, client, network.ProbeURL)
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
 }
 
 // checkPermissions verifies file system permissions
@@ -308,14 +431,20 @@ func checkLibraryPathFromWorkingDir(workingDir string) bool {
 func suggestPathFix() {
 	fmt.Println("   💡 To add DDX to your PATH:")
 
-	homeDir, _ := os.UserHomeDir()
+	binPath, err := getDefaultInstallPath()
+	if executable, exeErr := os.Executable(); exeErr == nil {
+		binPath, err = filepath.Dir(executable), nil
+	}
+	if err != nil {
+		return
+	}
 
+	fmt.Println("   Run 'ddx setup path', or:")
 	switch runtime.GOOS {
 	case "windows":
-		binPath := filepath.Join(homeDir, "bin")
-		fmt.Printf("   Add %s to your PATH environment variable\n", binPath)
+		fmt.Printf("   [Environment]::SetEnvironmentVariable('Path', '%s;' + [Environment]::GetEnvironmentVariable('Path', 'User'), 'User')\n", binPath)
+		fmt.Println("   in PowerShell, then open a new terminal")
 	default:
-		binPath := filepath.Join(homeDir, ".local", "bin")
 		fmt.Printf("   Add 'export PATH=\"%s:$PATH\"' to your shell profile\n", binPath)
 	}
 }
@@ -411,7 +540,8 @@ func getConfigFileInfo() string {
 	return "not found"
 }
 
-// checkMetaPromptSync checks if the meta-prompt in CLAUDE.md is in sync with library
+// checkMetaPromptSync checks if the meta-prompt in each AI target file is in
+// sync with library
 func checkMetaPromptSync(workingDir string) error {
 	cfg, err := config.LoadWithWorkingDir(workingDir)
 	if err != nil {
@@ -425,20 +555,31 @@ func checkMetaPromptSync(workingDir string) error {
 		return nil
 	}
 
-	injector := metaprompt.NewMetaPromptInjectorWithPaths(
-		"CLAUDE.md",
-		cfg.Library.Path,
-		workingDir,
-	)
-
-	inSync, err := injector.IsInSync()
+	targetList, _, err := aiTargets(cfg)
 	if err != nil {
-		// Could not check (file missing, etc) - not a critical issue
-		return nil
+		return err
+	}
+
+	var stale []string
+	for _, target := range targetList {
+		injector := metaprompt.NewMetaPromptInjectorWithPaths(
+			target.File,
+			cfg.Library.Path,
+			workingDir,
+		)
+
+		inSync, err := injector.IsInSync()
+		if err != nil {
+			// Could not check (file missing, etc) - not a critical issue
+			continue
+		}
+		if !inSync {
+			stale = append(stale, target.File)
+		}
 	}
 
-	if !inSync {
-		return fmt.Errorf("meta-prompt is out of sync with library")
+	if len(stale) > 0 {
+		return fmt.Errorf("meta-prompt is out of sync with library in %s", strings.Join(stale, ", "))
 	}
 
 	return nil
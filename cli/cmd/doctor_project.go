@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/library"
+	"github.com/easel/ddx/internal/lock"
+	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// CheckStatus is the outcome of a project check
+type CheckStatus string
+
+// Project check outcomes
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// librarySyncMaxAge is how old the last library sync may be before doctor
+// suggests updating
+const librarySyncMaxAge = 30 * 24 * time.Hour
+
+// ProjectCheck is the result of one project-level diagnosis
+type ProjectCheck struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+	Fix     []string
+	// Weight is the check's share of the health score
+	Weight int
+}
+
+// diagnoseProject runs the project-level checks for a DDx project. It
+// returns nil when the directory has no .ddx/config.yaml or legacy .ddx.yml.
+func diagnoseProject(workingDir string, now time.Time) []ProjectCheck {
+	configPath := filepath.Join(workingDir, ".ddx", "config.yaml")
+	if _, err := os.Stat(configPath); err != nil {
+		if _, err := os.Stat(filepath.Join(workingDir, config.LegacyConfigFile)); err == nil {
+			return []ProjectCheck{{
+				Name:    "Project configuration",
+				Status:  CheckWarn,
+				Message: "the project uses the legacy .ddx.yml, which ddx no longer reads",
+				Fix:     []string{"Run 'ddx config migrate' to move it to .ddx/config.yaml"},
+				Weight:  3,
+			}}
+		}
+		return nil
+	}
+
+	configCheck, cfg := checkProjectConfig(workingDir, configPath)
+	checks := []ProjectCheck{configCheck}
+
+	var libPath, pin string
+	if cfg != nil && cfg.Library != nil && cfg.Library.Repository != nil {
+		pin = cfg.Library.Repository.Ref
+	}
+	if cfg != nil && cfg.Library != nil && cfg.Library.Path != "" {
+		libPath = cfg.Library.Path
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(workingDir, libPath)
+		}
+	}
+
+	libraryCheck := checkProjectLibrary(libPath)
+	checks = append(checks,
+		libraryCheck,
+		checkLibrarySubtree(workingDir, libPath, pin, libraryCheck.Status == CheckPass, now),
+		checkLibraryLock(workingDir, libPath, libraryCheck.Status == CheckPass),
+		checkClaudeMarkers(workingDir, cfg),
+		checkPersonaBindings(workingDir, cfg),
+		checkWorkflowState(workingDir, cfg, libPath),
+	)
+	return checks
+}
+
+// projectHealthScore rates the project from 0 to 100. Warnings earn half
+// of a check's weight; skipped checks are not counted.
+func projectHealthScore(checks []ProjectCheck) int {
+	total, earned := 0, 0
+	for _, check := range checks {
+		switch check.Status {
+		case CheckPass:
+			total += check.Weight
+			earned += 2 * check.Weight
+		case CheckWarn:
+			total += check.Weight
+			earned += check.Weight
+		case CheckFail:
+			total += check.Weight
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return earned * 100 / (2 * total)
+}
+
+// checkProjectConfig validates .ddx/config.yaml against the schema and the
+// configuration rules, returning the loaded configuration when it is usable
+func checkProjectConfig(workingDir, configPath string) (ProjectCheck, *config.Config) {
+	check := ProjectCheck{Name: "Project configuration", Weight: 3}
+	fix := []string{
+		"Edit .ddx/config.yaml to correct the reported fields",
+		"Run 'ddx config validate' to re-check",
+	}
+
+	// Older formats and deprecated fields are mapped on load, so validate
+	// the migrated form
+	plan, err := config.PlanMigration(configPath, configPath)
+	if err == nil && plan.Empty() {
+		var validator *config.ConfigValidator
+		if validator, err = config.NewValidator(); err == nil {
+			err = validator.ValidateFile(configPath)
+		}
+	}
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = err.Error()
+		check.Fix = fix
+		return check, nil
+	}
+
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = err.Error()
+		check.Fix = fix
+		return check, nil
+	}
+
+	if plan.Outdated() {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf(".ddx/config.yaml uses configuration format %s; the current format is %s", plan.FromVersion, config.CurrentVersion)
+		check.Fix = []string{"Run 'ddx config migrate' to upgrade the file"}
+		return check, cfg
+	}
+	if migrations := plan.Renames; len(migrations) > 0 {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf(".ddx/config.yaml uses %d deprecated field(s): %s", len(migrations), migrations[0])
+		check.Fix = []string{"Run 'ddx config migrate' to update the file"}
+		return check, cfg
+	}
+
+	check.Status = CheckPass
+	check.Message = ".ddx/config.yaml is valid"
+	return check, cfg
+}
+
+// checkProjectLibrary verifies that the configured library exists
+func checkProjectLibrary(libPath string) ProjectCheck {
+	check := ProjectCheck{Name: "Library path", Weight: 3}
+	if libPath == "" {
+		check.Status = CheckFail
+		check.Message = "library.path is not configured"
+		check.Fix = []string{"Set library.path in .ddx/config.yaml (usually .ddx/library)"}
+		return check
+	}
+
+	info, err := os.Stat(libPath)
+	if err != nil || !info.IsDir() {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("library not found at %s", libPath)
+		check.Fix = []string{
+			"Run 'ddx update' to restore the library",
+			"Or correct library.path in .ddx/config.yaml",
+		}
+		return check
+	}
+
+	entries, _ := os.ReadDir(libPath)
+	if len(entries) == 0 {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("library at %s is empty", libPath)
+		check.Fix = []string{"Run 'ddx update' to restore the library"}
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("library found at %s", libPath)
+	return check
+}
+
+// checkLibrarySubtree checks that the library is tracked as a git subtree,
+// has no uncommitted edits and, unless it is pinned, was synced recently
+func checkLibrarySubtree(workingDir, libPath, pin string, libraryOK bool, now time.Time) ProjectCheck {
+	check := ProjectCheck{Name: "Library subtree", Weight: 2}
+	prefix, err := filepath.Rel(workingDir, libPath)
+	switch {
+	case !libraryOK:
+		check.Status = CheckSkip
+		check.Message = "library path is not usable"
+		return check
+	case err != nil || strings.HasPrefix(prefix, ".."):
+		check.Status = CheckSkip
+		check.Message = "library is outside the project"
+		return check
+	case exec.Command("git", "-C", workingDir, "rev-parse", "--git-dir").Run() != nil:
+		check.Status = CheckSkip
+		check.Message = "project is not a git repository"
+		return check
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	output, err := exec.Command("git", "-C", workingDir, "log", "-1", "--format=%ct",
+		"--grep=git-subtree-dir: "+prefix).Output()
+	timestamp := strings.TrimSpace(string(output))
+	if err != nil || timestamp == "" {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("%s is not tracked as a git subtree", prefix)
+		check.Fix = []string{"Run 'ddx update' to sync the library through git subtree"}
+		return check
+	}
+
+	var problems []string
+	if changes := pendingLibraryChanges(workingDir, prefix); len(changes) > 0 {
+		problems = append(problems, fmt.Sprintf("%d uncommitted change(s) in %s", len(changes), prefix))
+		check.Fix = append(check.Fix, "Commit library changes or share them with 'ddx contribute'")
+	}
+
+	seconds, _ := strconv.ParseInt(timestamp, 10, 64)
+	synced := time.Unix(seconds, 0)
+	age := now.Sub(synced)
+	if age > librarySyncMaxAge && pin == "" {
+		problems = append(problems, fmt.Sprintf("last synced %d days ago", int(age.Hours()/24)))
+		check.Fix = append(check.Fix, "Run 'ddx update' to pull the latest library")
+	}
+
+	if len(problems) > 0 {
+		check.Status = CheckWarn
+		check.Message = strings.Join(problems, "; ")
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("last synced %s", synced.Format("2006-01-02"))
+	if pin != "" {
+		check.Message = fmt.Sprintf("pinned to %s, last synced %s", pin, synced.Format("2006-01-02"))
+	}
+	return check
+}
+
+// checkLibraryLock compares the library with ddx.lock when the project has
+// one
+func checkLibraryLock(workingDir, libPath string, libraryOK bool) ProjectCheck {
+	check := ProjectCheck{Name: "Library lock", Weight: 2}
+	l, err := lock.Load(workingDir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		check.Status = CheckSkip
+		check.Message = fmt.Sprintf("no %s; run 'ddx lock' to pin the library", lock.FileName)
+		return check
+	case err != nil:
+		check.Status = CheckFail
+		check.Message = err.Error()
+		check.Fix = []string{"Run 'ddx lock' to regenerate the lockfile"}
+		return check
+	case !libraryOK:
+		check.Status = CheckSkip
+		check.Message = "library path is not usable"
+		return check
+	}
+
+	drift, err := l.Verify(libPath)
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = err.Error()
+		return check
+	}
+	if len(drift) > 0 {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("%d library file(s) differ from %s: %s", len(drift), lock.FileName, lock.Summary(drift))
+		check.Fix = []string{
+			"Restore the locked library from version control",
+			"Or run 'ddx lock' to record the current library",
+		}
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("library matches %s (%d assets)", lock.FileName, len(l.Assets))
+	return check
+}
+
+// claudeSections are the sections ddx manages in CLAUDE.md
+var claudeSections = []string{metaprompt.MetaPromptSection, persona.PersonasSection}
+
+// checkClaudeMarkers verifies that each managed CLAUDE.md section has one
+// start marker followed by one end marker. The file is the one named by
+// injection.claude_file, and is not checked when injection is disabled
+// for it.
+func checkClaudeMarkers(workingDir string, cfg *config.Config) ProjectCheck {
+	file := claudeFile(cfg)
+	check := ProjectCheck{Name: file + " markers", Weight: 2}
+	if injectionRules(cfg).Disabled(file) {
+		check.Status = CheckSkip
+		check.Message = "injection is disabled for " + file
+		return check
+	}
+	content, err := os.ReadFile(filepath.Join(workingDir, file))
+	if err != nil {
+		check.Status = CheckSkip
+		check.Message = "no " + file
+		return check
+	}
+
+	var problems []string
+	for _, name := range claudeSections {
+		start, end := sections.Markers(name)
+		starts := strings.Count(string(content), start)
+		ends := strings.Count(string(content), end)
+		switch {
+		case starts == 0 && ends == 0:
+		case starts != 1 || ends != 1:
+			problems = append(problems, fmt.Sprintf("%s appears %d time(s) and %s %d time(s)", start, starts, end, ends))
+		case strings.Index(string(content), end) < strings.Index(string(content), start):
+			problems = append(problems, fmt.Sprintf("%s comes before %s", end, start))
+		}
+	}
+
+	if len(problems) > 0 {
+		check.Status = CheckFail
+		check.Message = strings.Join(problems, "; ")
+		check.Fix = []string{
+			"Remove the duplicated or unmatched markers from " + file,
+			"Run 'ddx update' and 'ddx persona load' to regenerate the sections",
+		}
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = "managed sections are well formed"
+	return check
+}
+
+// checkPersonaBindings verifies that every bound persona exists in the
+// project or the library and loads
+func checkPersonaBindings(workingDir string, cfg *config.Config) ProjectCheck {
+	check := ProjectCheck{Name: "Persona bindings", Weight: 2}
+	if cfg == nil || len(cfg.PersonaBindings) == 0 {
+		check.Status = CheckSkip
+		check.Message = "no persona bindings"
+		return check
+	}
+
+	roles := make([]string, 0, len(cfg.PersonaBindings))
+	for role := range cfg.PersonaBindings {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	sources := library.ProjectSources(workingDir, cfg)
+	var broken []string
+	for _, role := range roles {
+		for _, name := range config.SplitPersonas(cfg.PersonaBindings[role]) {
+			if err := loadBoundPersona(workingDir, sources, name); err != nil {
+				broken = append(broken, fmt.Sprintf("%s → %s", role, name))
+				check.Fix = append(check.Fix, fmt.Sprintf("Run 'ddx persona bind %s <persona>' with a persona from 'ddx persona list'", role))
+			}
+		}
+	}
+
+	if len(broken) > 0 {
+		check.Status = CheckFail
+		check.Message = "bound personas not found: " + strings.Join(broken, ", ")
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("%d binding(s) resolve", len(cfg.PersonaBindings))
+	return check
+}
+
+// loadBoundPersona loads a persona named in a binding from the project or
+// the libraries. A project persona shadows the libraries', even when it is
+// broken.
+func loadBoundPersona(workingDir string, sources library.Sources, name string) error {
+	path, _, err := resolvePersona(workingDir, sources, name)
+	if err != nil {
+		return err
+	}
+	_, err = persona.NewPersonaLoaderWithDir(filepath.Dir(path)).LoadPersona(strings.TrimSuffix(filepath.Base(path), ".md"))
+	return err
+}
+
+// checkWorkflowState verifies that active workflows load and that their
+// state files refer to phases the workflow defines
+func checkWorkflowState(workingDir string, cfg *config.Config, libPath string) ProjectCheck {
+	check := ProjectCheck{Name: "Workflow state", Weight: 2}
+	if cfg == nil || len(cfg.Workflows.Active) == 0 {
+		check.Status = CheckSkip
+		check.Message = "no active workflows"
+		return check
+	}
+
+	loader := workflow.NewLoader(libPath)
+	var problems []string
+	for _, name := range cfg.Workflows.Active {
+		def, err := loader.Load(name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			check.Fix = append(check.Fix, fmt.Sprintf("Run 'ddx workflow deactivate %s' or restore it with 'ddx update'", name))
+			continue
+		}
+
+		stateFile := workflow.StateFile(name)
+		data, err := os.ReadFile(filepath.Join(workingDir, stateFile))
+		if err != nil {
+			continue
+		}
+		resetFix := fmt.Sprintf("Delete %s to reset the %s workflow state", stateFile, name)
+		var state workflow.State
+		if err := yaml.Unmarshal(data, &state); err != nil {
+			problems = append(problems, fmt.Sprintf("%s is not valid YAML", stateFile))
+			check.Fix = append(check.Fix, resetFix)
+			continue
+		}
+
+		var unknown []string
+		if state.CurrentPhase != "" && def.GetPhaseByID(state.CurrentPhase) == nil {
+			unknown = append(unknown, state.CurrentPhase)
+		}
+		for _, phase := range state.PhasesCompleted {
+			if def.GetPhaseByID(phase) == nil {
+				unknown = append(unknown, phase)
+			}
+		}
+		if len(unknown) > 0 {
+			problems = append(problems, fmt.Sprintf("%s refers to unknown phase(s) %s", stateFile, strings.Join(unknown, ", ")))
+			check.Fix = append(check.Fix, resetFix)
+		}
+	}
+
+	if len(problems) > 0 {
+		check.Status = CheckFail
+		check.Message = strings.Join(problems, "; ")
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("%d active workflow(s) consistent", len(cfg.Workflows.Active))
+	return check
+}
+
+// printProjectChecks renders the project checks and health score
+func printProjectChecks(w io.Writer, checks []ProjectCheck) {
+	_, _ = fmt.Fprintln(w, "🩺 Project Diagnostics")
+	_, _ = fmt.Fprintln(w, "=====================================")
+	icons := map[CheckStatus]string{CheckPass: "✅", CheckWarn: "⚠️ ", CheckFail: "❌", CheckSkip: "➖"}
+	for _, check := range checks {
+		_, _ = fmt.Fprintf(w, "%s %s: %s\n", icons[check.Status], check.Name, check.Message)
+		if check.Status == CheckWarn || check.Status == CheckFail {
+			for _, fix := range check.Fix {
+				_, _ = fmt.Fprintf(w, "   💡 %s\n", fix)
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\nProject health: %d/100\n", projectHealthScore(checks))
+}
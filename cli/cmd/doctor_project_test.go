@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkNamed returns the project check with the given name
+func checkNamed(t *testing.T, checks []ProjectCheck, name string) ProjectCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	require.Failf(t, "check not found", "no check named %q", name)
+	return ProjectCheck{}
+}
+
+func TestDiagnoseProject(t *testing.T) {
+	t.Run("not a project", func(t *testing.T) {
+		assert.Nil(t, diagnoseProject(t.TempDir(), time.Now()))
+	})
+
+	t.Run("healthy project", func(t *testing.T) {
+		env := NewTestEnvironment(t)
+		env.InitWithDDx()
+
+		checks := diagnoseProject(env.Dir, time.Now())
+		assert.Equal(t, CheckPass, checkNamed(t, checks, "Project configuration").Status)
+		assert.Equal(t, CheckPass, checkNamed(t, checks, "Library path").Status)
+		assert.Equal(t, CheckPass, checkNamed(t, checks, "Library subtree").Status)
+		assert.Equal(t, 100, projectHealthScore(checks))
+
+		// A library synced long ago is stale
+		stale := checkNamed(t, diagnoseProject(env.Dir, time.Now().Add(60*24*time.Hour)), "Library subtree")
+		assert.Equal(t, CheckWarn, stale.Status)
+		assert.Contains(t, stale.Message, "last synced 60 days ago")
+		assert.Contains(t, stale.Fix, "Run 'ddx update' to pull the latest library")
+
+		// A pinned library is meant to stay behind
+		require.NoError(t, configSet(env.Dir, "library.repository.ref", "v1.4.0", false))
+		pinned := checkNamed(t, diagnoseProject(env.Dir, time.Now().Add(60*24*time.Hour)), "Library subtree")
+		assert.Equal(t, CheckPass, pinned.Status)
+		assert.Contains(t, pinned.Message, "pinned to v1.4.0")
+	})
+
+	t.Run("broken project", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+persona_bindings:
+  code-reviewer: missing-reviewer
+workflows:
+  active: [missing-workflow]
+`)
+		env.CreateFile(".ddx/library/personas/README.md", "# Personas")
+		env.CreateFile("CLAUDE.md", "<!-- DDX:START name=\"personas\" -->\n<!-- DDX:START name=\"personas\" -->\n<!-- DDX:END name=\"personas\" -->\n")
+
+		checks := diagnoseProject(env.Dir, time.Now())
+		assert.Equal(t, CheckSkip, checkNamed(t, checks, "Library subtree").Status)
+
+		markers := checkNamed(t, checks, "CLAUDE.md markers")
+		assert.Equal(t, CheckFail, markers.Status)
+		assert.Contains(t, markers.Message, `<!-- DDX:START name="personas" appears 2 time(s)`)
+
+		bindings := checkNamed(t, checks, "Persona bindings")
+		assert.Equal(t, CheckFail, bindings.Status)
+		assert.Contains(t, bindings.Message, "code-reviewer → missing-reviewer")
+		assert.NotEmpty(t, bindings.Fix)
+
+		workflows := checkNamed(t, checks, "Workflow state")
+		assert.Equal(t, CheckFail, workflows.Status)
+		assert.Contains(t, workflows.Message, "missing-workflow")
+
+		assert.Less(t, projectHealthScore(checks), 60)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig("version: [\n")
+
+		checks := diagnoseProject(env.Dir, time.Now())
+		assert.Equal(t, CheckFail, checkNamed(t, checks, "Project configuration").Status)
+		assert.Equal(t, CheckFail, checkNamed(t, checks, "Library path").Status)
+	})
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig(`version: "1.0"
+library_path: .ddx/library
+`)
+
+		check := checkNamed(t, diagnoseProject(env.Dir, time.Now()), "Project configuration")
+		assert.Equal(t, CheckWarn, check.Status)
+		assert.Contains(t, check.Message, "'library_path' is deprecated")
+		assert.Contains(t, check.Fix, "Run 'ddx config migrate' to update the file")
+	})
+
+	t.Run("outdated format", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig(`version: "0.9"
+includes:
+  - prompts
+`)
+
+		check := checkNamed(t, diagnoseProject(env.Dir, time.Now()), "Project configuration")
+		assert.Equal(t, CheckWarn, check.Status)
+		assert.Contains(t, check.Message, "configuration format 0.9; the current format is 1.0")
+	})
+
+	t.Run("legacy file", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateFile(".ddx.yml", "version: 1.0\nincludes: [prompts]\n")
+
+		checks := diagnoseProject(env.Dir, time.Now())
+		require.Len(t, checks, 1)
+		assert.Equal(t, CheckWarn, checks[0].Status)
+		assert.Contains(t, checks[0].Message, "legacy .ddx.yml")
+		assert.Contains(t, checks[0].Fix[0], "ddx config migrate")
+	})
+}
+
+func TestProjectHealthScore(t *testing.T) {
+	checks := []ProjectCheck{
+		{Status: CheckPass, Weight: 2},
+		{Status: CheckWarn, Weight: 2},
+		{Status: CheckSkip, Weight: 4},
+	}
+	assert.Equal(t, 75, projectHealthScore(checks))
+	assert.Equal(t, 100, projectHealthScore(nil))
+}
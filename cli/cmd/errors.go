@@ -1,22 +1,26 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/easel/ddx/internal/exitcodes"
 )
 
-// Exit codes as per CLI contract
+// Exit codes as per CLI contract; see the exitcodes package
 const (
-	ExitCodeSuccess         = 0
-	ExitCodeGeneralError    = 1
-	ExitCodeMissingArg      = 2
-	ExitCodeNoConfig        = 3
-	ExitCodeInvalidConfig   = 4
-	ExitCodeNetworkError    = 5
-	ExitCodePersonaNotFound = 6
-	ExitCodeBindingExists   = 7
-	ExitCodeNoBindings      = 8
+	ExitCodeSuccess       = exitcodes.Success
+	ExitCodeGeneralError  = exitcodes.GeneralError
+	ExitCodeMissingArg    = exitcodes.MissingArg
+	ExitCodeNoConfig      = exitcodes.NoConfig
+	ExitCodeInvalidConfig = exitcodes.InvalidConfig
+	ExitCodeNetworkError  = exitcodes.NetworkError
+	ExitCodeNotFound      = exitcodes.NotFound
+	ExitCodeBindingExists = exitcodes.BindingExists
+	ExitCodeNoBindings    = exitcodes.NoBindings
+	ExitCodeInterrupted   = exitcodes.Interrupted
 )
 
 // ExitError represents an error with a specific exit code
@@ -64,7 +68,7 @@ func CheckPersonaNotFound(err error, personaName string) error {
 		// Check if it's a persona not found error
 		if strings.Contains(err.Error(), fmt.Sprintf("persona '%s' not found", personaName)) ||
 			strings.Contains(err.Error(), "not found at personas/") {
-			return NewExitError(ExitCodePersonaNotFound,
+			return NewExitError(ExitCodeNotFound,
 				fmt.Sprintf("Persona '%s' not found", personaName))
 		}
 	}
@@ -83,3 +87,14 @@ func CheckNoConfig(err error) error {
 	}
 	return err
 }
+
+// transactionError keeps the exit code of the step that failed a
+// transaction, so a rolled-back operation exits the way the step would
+// have on its own
+func transactionError(err error) error {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return NewExitError(exitErr.Code, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/scripts"
+	"github.com/easel/ddx/internal/userdirs"
+	"github.com/spf13/cobra"
+)
+
+// libraryScripts returns the scripts available to the project by name, the
+// highest-precedence library layer winning
+func libraryScripts(workingDir string) (map[string]scripts.Script, []string, error) {
+	r, err := projectResolver(workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers, err := r.Layers("scripts")
+	if err != nil {
+		return nil, nil, err
+	}
+	found := make(map[string]scripts.Script)
+	var names []string
+	for _, layer := range layers {
+		available, err := scripts.Available(layer.Dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, script := range available {
+			if _, ok := found[script.Name]; !ok {
+				found[script.Name] = script
+				names = append(names, script.Name)
+			}
+		}
+	}
+	return found, names, nil
+}
+
+// runExec lists the library's scripts or runs one in the project
+func (f *CommandFactory) runExec(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	execCfg := cfg.Exec
+	if execCfg == nil {
+		execCfg = &config.ExecConfig{}
+	}
+	available, names, err := libraryScripts(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if len(names) == 0 {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No scripts in the library")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "SCRIPT\tALLOWED\tPATH")
+		for _, name := range names {
+			allowed := "no"
+			if scripts.Allowed(execCfg.Allow, name) {
+				allowed = "yes"
+			}
+			path := available[name].Path
+			if rel, err := filepath.Rel(f.WorkingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, allowed, path)
+		}
+		return w.Flush()
+	}
+
+	name := args[0]
+	script, ok := available[name]
+	if !ok {
+		return NewExitError(1, fmt.Sprintf("script '%s' not found in the library; run 'ddx exec' to list scripts", name))
+	}
+	if !scripts.Allowed(execCfg.Allow, name) {
+		return NewExitError(1, fmt.Sprintf("script '%s' is not allowed; add it to exec.allow in .ddx/config.yaml", name))
+	}
+	if err := approveScript(cmd, script); err != nil {
+		return err
+	}
+
+	// The script's output is the command's output
+	cmd.SilenceUsage = true
+	libPath := ""
+	if cfg.Library != nil {
+		libPath = projectLibraryDir(f.WorkingDir, cfg)
+	}
+	run := exec.CommandContext(cmd.Context(), script.Path, args[1:]...)
+	run.Dir = f.WorkingDir
+	run.Env = scripts.Env(os.Environ(), execCfg.Env, map[string]string{
+		"DDX_PROJECT_DIR":  f.WorkingDir,
+		"DDX_LIBRARY_PATH": libPath,
+		"DDX_SCRIPT":       name,
+		"DDX_SCRIPT_DIR":   filepath.Dir(script.Path),
+		"DDX_VERSION":      f.Version,
+	})
+	run.Stdin = cmd.InOrStdin()
+	run.Stdout = cmd.OutOrStdout()
+	run.Stderr = cmd.ErrOrStderr()
+	if err := run.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmd.SilenceErrors = true
+			return NewExitError(exitErr.ExitCode(), "")
+		}
+		return fmt.Errorf("failed to run script '%s': %w", name, err)
+	}
+	return nil
+}
+
+// approveScript asks before a script runs for the first time, or for the
+// first time since its content changed. --yes approves without asking,
+// which non-interactive runs need.
+func approveScript(cmd *cobra.Command, script scripts.Script) error {
+	hash, err := scripts.Hash(script.Path)
+	if err != nil {
+		return err
+	}
+	trustPath, err := userdirs.Path(userdirs.Config, scripts.TrustFile)
+	if err != nil {
+		return err
+	}
+	trust, err := scripts.LoadTrust(trustPath)
+	if err != nil {
+		return err
+	}
+	if trust.Approved(script.Path, hash) {
+		return nil
+	}
+
+	reason := "has not been run before"
+	if trust.Known(script.Path) {
+		reason = "has changed since it was approved"
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		if !isTerminal(cmd.InOrStdin()) {
+			return NewExitError(1, fmt.Sprintf("script '%s' %s; review %s and run again with --yes", script.Name, reason, script.Path))
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Script '%s' %s.\n  %s\n  sha256 %s\nRun it? [y/N] ", script.Name, reason, script.Path, hash)
+		line, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			return NewExitError(1, fmt.Sprintf("script '%s' was not run", script.Name))
+		}
+	}
+	return trust.Approve(script.Path, hash)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExec(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GREETING", "hello")
+	t.Setenv("SECRET", "hunter2")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+exec:
+  allow: [greet]
+  env: [GREETING]
+`)
+	greet := filepath.Join(env.LibraryPath, "scripts", "greet.sh")
+	env.CreateFile(".ddx/library/scripts/greet.sh", `#!/bin/sh
+[ "$1" = fail ] && exit 3
+echo "args=$*"
+echo "dir=$(pwd)"
+echo "script=$DDX_SCRIPT project=$DDX_PROJECT_DIR"
+echo "greeting=$GREETING secret=${SECRET:-unset}"
+`)
+	env.CreateFile(".ddx/library/scripts/deploy.sh", "#!/bin/sh\necho deploying\n")
+	require.NoError(t, os.Chmod(greet, 0755))
+	run := func(args ...string) (string, error) {
+		root := NewCommandFactory(env.Dir).NewRootCommand()
+		root.SetIn(strings.NewReader(""))
+		return executeCommand(root, args...)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		output, err := run("exec")
+		require.NoError(t, err, output)
+		assert.Regexp(t, `deploy\s+no\s+.ddx/library/scripts/deploy.sh`, output)
+		assert.Regexp(t, `greet\s+yes\s+.ddx/library/scripts/greet.sh`, output)
+	})
+
+	t.Run("first run needs approval", func(t *testing.T) {
+		_, err := run("exec", "greet")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "script 'greet' has not been run before; review "+greet+" and run again with --yes")
+	})
+
+	t.Run("approved run", func(t *testing.T) {
+		output, err := run("exec", "--yes", "greet", "--loud", "world")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "args=--loud world")
+		assert.Contains(t, output, "dir="+env.Dir)
+		assert.Contains(t, output, "script=greet project="+env.Dir)
+		assert.Contains(t, output, "greeting=hello secret=unset")
+
+		output, err = run("exec", "greet")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "args=\n")
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		_, err := run("exec", "greet", "fail")
+		var exitErr *ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.Code)
+	})
+
+	t.Run("changed script", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(greet, []byte("#!/bin/sh\necho changed\n"), 0755))
+		_, err := run("exec", "greet")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "script 'greet' has changed since it was approved")
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		_, err := run("exec", "--yes", "deploy")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "script 'deploy' is not allowed; add it to exec.allow")
+
+		_, err = run("exec", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "script 'missing' not found in the library")
+	})
+}
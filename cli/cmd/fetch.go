@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/assets"
+	"github.com/spf13/cobra"
+)
+
+// libraryAssets returns the pointers for the named assets, or every asset
+// in the library when no names are given
+func libraryAssets(libPath string, names []string) ([]*assets.Pointer, error) {
+	if len(names) == 0 {
+		return assets.Find(libPath)
+	}
+	pointers := make([]*assets.Pointer, 0, len(names))
+	for _, name := range names {
+		p, err := assets.Lookup(libPath, name)
+		if err != nil {
+			return nil, err
+		}
+		pointers = append(pointers, p)
+	}
+	return pointers, nil
+}
+
+// runFetch implements the fetch command
+func (f *CommandFactory) runFetch(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("path")
+	verify, _ := cmd.Flags().GetBool("verify")
+	output, _ := cmd.Flags().GetString("output")
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+
+	if output != "" && len(args) != 1 {
+		return fmt.Errorf("--output needs exactly one asset")
+	}
+	libPath, err := resolveLibraryPath(f.WorkingDir, path)
+	if err != nil {
+		return err
+	}
+	pointers, err := libraryAssets(libPath, args)
+	if err != nil {
+		return err
+	}
+	if len(pointers) == 0 {
+		_, _ = fmt.Fprintln(out, "No assets in the library")
+		return nil
+	}
+	store, err := assets.DefaultStore()
+	if err != nil {
+		return err
+	}
+
+	if verify {
+		failed := 0
+		for _, p := range pointers {
+			if !store.Has(p) {
+				_, _ = fmt.Fprintf(out, "  · %s not cached\n", p.Name)
+				continue
+			}
+			if err := store.Verify(p); err != nil {
+				_, _ = fmt.Fprintf(out, "  ✗ %v\n", err)
+				failed++
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "  ✓ %s\n", p.Name)
+		}
+		if failed > 0 {
+			return NewExitError(1, fmt.Sprintf("%d cached asset(s) failed verification; run 'ddx fetch' to download them again", failed))
+		}
+		return nil
+	}
+
+	if output != "" {
+		if !filepath.IsAbs(output) {
+			output = filepath.Join(f.WorkingDir, output)
+		}
+		if _, err := store.CopyTo(pointers[0], output, 0644); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "✅ Wrote %s to %s\n", pointers[0].Name, output)
+		return nil
+	}
+
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	results, err := store.FetchAll(cmd.Context(), pointers, assets.FetchOptions{
+		Workers: jobs,
+		Progress: func(p assets.Progress) {
+			switch {
+			case p.Err != nil:
+				_, _ = fmt.Fprintf(out, "  ✗ %v\n", p.Err)
+			case p.Downloaded:
+				_, _ = fmt.Fprintf(out, "  ⬇️  %s [%d/%d]\n", p.Asset.Name, p.Done, p.Total)
+			default:
+				_, _ = fmt.Fprintf(out, "  ✓ %s (cached) [%d/%d]\n", p.Asset.Name, p.Done, p.Total)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("no assets were stored: %w", err)
+	}
+
+	fetched := 0
+	for _, result := range results {
+		if result.Downloaded {
+			fetched++
+		}
+	}
+	if len(args) == 1 {
+		_, _ = fmt.Fprintf(out, "     %s\n", results[0].Path)
+	}
+	noun := "assets"
+	if fetched == 1 {
+		noun = "asset"
+	}
+	_, _ = fmt.Fprintf(out, "✅ Fetched %d %s, %d already cached\n", fetched, noun, len(pointers)-fetched)
+	return nil
+}
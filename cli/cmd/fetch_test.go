@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCommand(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n")
+
+	src := filepath.Join(t.TempDir(), "logo.psd")
+	require.NoError(t, os.WriteFile(src, []byte("layers"), 0644))
+	hash := sha256.Sum256([]byte("layers"))
+	sum := hex.EncodeToString(hash[:])
+	env.CreateFile(".ddx/library/templates/brand/logo.psd.ddx-asset",
+		fmt.Sprintf("sha256: %s\nurl: file://%s\nsize: 6\n", sum, filepath.ToSlash(src)))
+
+	output, err := env.RunCommand("fetch")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "⬇️  templates/brand/logo.psd")
+	assert.Contains(t, output, "Fetched 1 asset, 0 already cached")
+	cached := filepath.Join(cache, "ddx", "assets", "sha256", sum[:2], sum)
+	assert.FileExists(t, cached)
+
+	output, err = env.RunCommand("fetch", "templates/brand/logo.psd", "-o", "logo.psd")
+	require.NoError(t, err, output)
+	data, err := os.ReadFile(filepath.Join(env.Dir, "logo.psd"))
+	require.NoError(t, err)
+	assert.Equal(t, "layers", string(data))
+
+	output, err = env.RunCommand("fetch", "--verify")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "✓ templates/brand/logo.psd")
+
+	require.NoError(t, os.WriteFile(cached, []byte("tampered"), 0644))
+	output, err = env.RunCommand("fetch", "--verify")
+	assert.ErrorContains(t, err, "1 cached asset(s) failed verification")
+	assert.Contains(t, output, "cached copy is corrupt")
+	assert.NoFileExists(t, cached)
+
+	_, err = env.RunCommand("fetch", "missing.bin")
+	assert.EqualError(t, err, "asset not found: missing.bin")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// tableFormatPrefix aligns templated columns on tab stops, as in docker
+const tableFormatPrefix = "table "
+
+// formatTemplateFuncs are available to --format templates
+var formatTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// renderFormat executes a Go template once per element of items, writing
+// one line per element. Escaped \t and \n in the format are expanded so
+// shell-quoted formats work as expected. A "table " prefix aligns columns.
+func renderFormat(w io.Writer, format string, items interface{}) error {
+	table := strings.HasPrefix(format, tableFormatPrefix)
+	if table {
+		format = strings.TrimPrefix(format, tableFormatPrefix)
+	}
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+
+	tmpl, err := template.New("format").Funcs(formatTemplateFuncs).Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format template: %w", err)
+	}
+
+	out := w
+	var tw *tabwriter.Writer
+	if table {
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		out = tw
+	}
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("format requires a list of items, got %T", items)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := tmpl.Execute(out, v.Index(i).Interface()); err != nil {
+			return fmt.Errorf("failed to execute format template: %w", err)
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
+	if tw != nil {
+		return tw.Flush()
+	}
+	return nil
+}
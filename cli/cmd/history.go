@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/history"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyAnnotation marks commands that history treats specially:
+// historySkip is not recorded, historyRedact keeps only the first argument
+const (
+	historyAnnotation = "ddx.history"
+	historySkip       = "skip"
+	historyRedact     = "redact"
+)
+
+// redacted replaces values history does not record
+const redacted = "***"
+
+// sensitiveFlag matches flags whose values history does not record
+var sensitiveFlag = regexp.MustCompile(`(?i)token|password|secret|key`)
+
+// appendHistory records a finished invocation. Recording never fails the
+// command; problems are reported as warnings.
+func (f *CommandFactory) appendHistory(cmd *cobra.Command, args []string, start time.Time, runErr error) {
+	if cmd.Annotations[historyAnnotation] == historySkip {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(f.WorkingDir, ".ddx")); err != nil {
+		return
+	}
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil || !cfg.History.IsEnabled() {
+		return
+	}
+
+	user, _ := git.UserIdentity(f.WorkingDir)
+	entry := history.Entry{
+		Time:       start.UTC(),
+		Command:    cmd.CommandPath(),
+		Args:       historyArgs(cmd, args),
+		Profile:    config.ActiveProfile(),
+		User:       user,
+		Result:     history.ResultOK,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if runErr != nil {
+		entry.Result, entry.ExitCode, entry.Error = history.ResultError, 1, runErr.Error()
+		var exitErr *ExitError
+		if errors.As(runErr, &exitErr) {
+			entry.ExitCode, entry.Error = exitErr.Code, exitErr.Message
+		}
+	}
+	if err := history.Append(f.WorkingDir, entry); err != nil {
+		slog.Warn("could not record history", "error", err)
+	}
+}
+
+// historyArgs returns the arguments and the flags set on the command line,
+// with credentials replaced
+func historyArgs(cmd *cobra.Command, args []string) []string {
+	var recorded []string
+	for i, arg := range args {
+		if i > 0 && cmd.Annotations[historyAnnotation] == historyRedact {
+			arg = redacted
+		}
+		recorded = append(recorded, arg)
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		switch {
+		case sensitiveFlag.MatchString(flag.Name):
+			value = redacted
+		case flag.Value.Type() == "bool" && value == "true":
+			recorded = append(recorded, "--"+flag.Name)
+			return
+		}
+		recorded = append(recorded, "--"+flag.Name+"="+value)
+	})
+	return recorded
+}
+
+// runHistory implements the history command
+func (f *CommandFactory) runHistory(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	out := cmd.OutOrStdout()
+
+	entries, err := history.Read(f.WorkingDir, limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode history: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(out, "No history recorded")
+		if cfg, err := config.LoadWithWorkingDir(f.WorkingDir); err == nil && !cfg.History.IsEnabled() {
+			_, _ = fmt.Fprintln(out, "Enable it with 'ddx config set history.enabled true'")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TIME\tCOMMAND\tPROFILE\tUSER\tRESULT")
+	_, _ = fmt.Fprintln(w, "----\t-------\t-------\t----\t------")
+	for _, e := range entries {
+		command := strings.TrimSpace(e.Command + " " + strings.Join(e.Args, " "))
+		profile, user, result := "-", "-", "✅"
+		if e.Profile != "" {
+			profile = e.Profile
+		}
+		if e.User != "" {
+			user = e.User
+		}
+		if e.Result != history.ResultOK {
+			result = fmt.Sprintf("❌ exit %d", e.ExitCode)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), command, profile, user, result)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/history"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistory tests recording invocations and showing them
+func TestHistory(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	// Nothing is recorded until history is enabled
+	_, err := env.RunCommand("config", "get", "library.path")
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(env.Dir, ".ddx", "history.jsonl"))
+	assert.True(t, os.IsNotExist(err))
+	output, err := env.RunCommand("history")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Enable it with 'ddx config set history.enabled true'")
+
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+history:
+  enabled: true
+`)
+	_, err = env.RunCommand("which", "prompts", "--json")
+	require.NoError(t, err)
+	_, err = env.RunCommand("workflow", "activate", "missing")
+	require.Error(t, err)
+
+	output, err = env.RunCommand("history", "--json")
+	require.NoError(t, err)
+	var entries []history.Entry
+	require.NoError(t, json.Unmarshal([]byte(output), &entries), output)
+	require.Len(t, entries, 2, "the history command itself is not recorded")
+	assert.Equal(t, "ddx which", entries[0].Command)
+	assert.Equal(t, []string{"prompts", "--json"}, entries[0].Args)
+	assert.Equal(t, history.ResultOK, entries[0].Result)
+	assert.Equal(t, "ddx workflow", entries[1].Command)
+	assert.Equal(t, history.ResultError, entries[1].Result)
+	assert.Equal(t, 1, entries[1].ExitCode)
+
+	output, err = env.RunCommand("history", "--limit", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "ddx workflow activate missing")
+	assert.Contains(t, output, "❌ exit 1")
+	assert.NotContains(t, output, "ddx which")
+}
+
+// TestHistoryArgsRedaction tests that credentials stay out of the log
+func TestHistoryArgsRedaction(t *testing.T) {
+	cmd := &cobra.Command{Use: "token", Annotations: map[string]string{historyAnnotation: historyRedact}}
+	cmd.Flags().String("api-key", "", "")
+	cmd.Flags().String("method", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--api-key", "abc", "--method", "ssh"}))
+
+	assert.Equal(t, []string{"github.com", "***", "--api-key=***", "--method=ssh"}, historyArgs(cmd, []string{"github.com", "ghp_secret"}))
+}
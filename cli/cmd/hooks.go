@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/githooks"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// libraryHooks returns the hooks available to the project by name, the
+// highest-precedence library layer winning
+func libraryHooks(workingDir string) (map[string]githooks.Hook, error) {
+	r, err := projectResolver(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := r.Layers("hooks")
+	if err != nil {
+		return nil, err
+	}
+	hooks := make(map[string]githooks.Hook)
+	for _, layer := range layers {
+		available, err := githooks.Available(layer.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range available {
+			if _, ok := hooks[hook.Name]; !ok {
+				hooks[hook.Name] = hook
+			}
+		}
+	}
+	return hooks, nil
+}
+
+// recordHooks sets the enable flag of each named hook in the project config
+func recordHooks(workingDir string, names []string, enabled bool) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return config.UpdateFile(filepath.Join(workingDir, ".ddx", "config.yaml"), func(doc *yaml.Node) error {
+		for _, name := range names {
+			if err := config.SetValue(doc, "hooks."+name, fmt.Sprint(enabled)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// runHooksList lists the library's hooks with their event and whether they
+// are enabled and installed
+func (f *CommandFactory) runHooksList(cmd *cobra.Command, args []string) error {
+	hooks, err := libraryHooks(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	installed := map[string]string{}
+	if hooksDir, err := githooks.HooksDir(f.WorkingDir); err == nil {
+		if installed, err = githooks.Installed(hooksDir); err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if len(hooks) == 0 && len(installed) == 0 {
+		_, _ = fmt.Fprintln(out, "No hooks found in the library")
+		return nil
+	}
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	for name := range installed {
+		if _, ok := hooks[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mark := " "
+		if _, ok := installed[name]; ok {
+			mark = "✓"
+		}
+		hook, ok := hooks[name]
+		if !ok {
+			_, _ = fmt.Fprintf(out, "%s %-24s %-18s (no longer in the library)\n", mark, name, installed[name])
+			continue
+		}
+		description := hook.Manifest.Description
+		if enabled, set := cfg.Hooks[name]; set && !enabled {
+			description += " (disabled)"
+		}
+		_, _ = fmt.Fprintf(out, "%s %-24s %-18s %s\n", mark, name, hook.Manifest.Event, description)
+	}
+	return nil
+}
+
+// runHooksInstall installs the named hooks and enables them in the config,
+// or without names brings the installed hooks in line with the config
+func (f *CommandFactory) runHooksInstall(cmd *cobra.Command, args []string) error {
+	hooksDir, err := githooks.HooksDir(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	hooks, err := libraryHooks(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		for name, enabled := range cfg.Hooks {
+			if enabled {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no hooks are enabled; name the hooks to install or set hooks.<name> to true in the config")
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if _, ok := hooks[name]; !ok {
+			return fmt.Errorf("hook not found: %s", name)
+		}
+	}
+	cmd.SilenceUsage = true
+
+	out := cmd.OutOrStdout()
+	for _, name := range names {
+		hook := hooks[name]
+		if err := githooks.Install(hooksDir, hook); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "✓ Installed %s (%s)\n", name, hook.Manifest.Event)
+	}
+
+	if len(args) == 0 {
+		// Hooks disabled in the config are taken out again
+		for name, enabled := range cfg.Hooks {
+			if enabled {
+				continue
+			}
+			removed, err := githooks.Uninstall(hooksDir, name)
+			if err != nil {
+				return err
+			}
+			if removed {
+				_, _ = fmt.Fprintf(out, "✓ Uninstalled %s (disabled)\n", name)
+			}
+		}
+		return nil
+	}
+	return recordHooks(f.WorkingDir, args, true)
+}
+
+// runHooksUninstall removes the named hooks, or every installed library
+// hook, and disables them in the config
+func (f *CommandFactory) runHooksUninstall(cmd *cobra.Command, args []string) error {
+	hooksDir, err := githooks.HooksDir(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	names := args
+	if len(names) == 0 {
+		installed, err := githooks.Installed(hooksDir)
+		if err != nil {
+			return err
+		}
+		for name := range installed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	cmd.SilenceUsage = true
+
+	out := cmd.OutOrStdout()
+	var removed []string
+	for _, name := range names {
+		ok, err := githooks.Uninstall(hooksDir, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_, _ = fmt.Fprintf(out, "Hook %s is not installed\n", name)
+			continue
+		}
+		removed = append(removed, name)
+		_, _ = fmt.Fprintf(out, "✓ Uninstalled %s\n", name)
+	}
+	if len(names) == 0 {
+		_, _ = fmt.Fprintln(out, "No hooks are installed")
+	}
+	if len(args) > 0 {
+		removed = args
+	}
+	return recordHooks(f.WorkingDir, removed, false)
+}
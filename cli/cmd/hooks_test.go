@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksCommand(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/hooks/conventional-commits/hook.yml", "description: Require Conventional Commits\nevent: commit-msg\n")
+	env.CreateFile(".ddx/library/hooks/conventional-commits/hook.sh", "#!/bin/sh\ngrep -q '^feat' \"$1\"\n")
+	env.CreateFile(".git/hooks/commit-msg", "#!/bin/sh\nexit 0\n")
+	hooksDir := filepath.Join(env.Dir, ".git", "hooks")
+
+	output, err := env.RunCommand("hooks", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "  conventional-commits")
+	assert.Contains(t, output, "commit-msg")
+
+	output, err = env.RunCommand("hooks", "install", "conventional-commits")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ Installed conventional-commits (commit-msg)")
+	assert.FileExists(t, filepath.Join(hooksDir, "ddx", "commit-msg", "conventional-commits"))
+	assert.FileExists(t, filepath.Join(hooksDir, "commit-msg.pre-ddx"))
+	cfg, err := env.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"conventional-commits": true}, cfg.Hooks)
+
+	output, err = env.RunCommand("hooks", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ conventional-commits")
+
+	output, err = env.RunCommand("hooks", "uninstall", "conventional-commits")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ Uninstalled conventional-commits")
+	restored, err := os.ReadFile(filepath.Join(hooksDir, "commit-msg"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nexit 0\n", string(restored))
+	cfg, err = env.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"conventional-commits": false}, cfg.Hooks)
+
+	output, err = env.RunCommand("hooks", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Require Conventional Commits (disabled)")
+
+	_, err = env.RunCommand("hooks", "install")
+	assert.ErrorContains(t, err, "no hooks are enabled")
+	_, err = env.RunCommand("hooks", "install", "missing")
+	assert.ErrorContains(t, err, "hook not found: missing")
+}
+
+func TestHooksCommand_InstallFromConfig(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+hooks:
+  conventional-commits: true
+  pre-push-tests: false
+`)
+	for _, hook := range []string{"conventional-commits:commit-msg", "pre-push-tests:pre-push"} {
+		name, event, _ := strings.Cut(hook, ":")
+		env.CreateFile(".ddx/library/hooks/"+name+"/hook.yml", "event: "+event+"\n")
+		env.CreateFile(".ddx/library/hooks/"+name+"/hook.sh", "#!/bin/sh\n")
+	}
+	_, err := env.RunCommand("hooks", "install", "pre-push-tests")
+	require.NoError(t, err)
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+hooks:
+  conventional-commits: true
+  pre-push-tests: false
+`)
+
+	output, err := env.RunCommand("hooks", "install")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ Installed conventional-commits (commit-msg)")
+	assert.Contains(t, output, "✓ Uninstalled pre-push-tests (disabled)")
+	assert.FileExists(t, filepath.Join(env.Dir, ".git", "hooks", "commit-msg"))
+	assert.NoFileExists(t, filepath.Join(env.Dir, ".git", "hooks", "pre-push"))
+}
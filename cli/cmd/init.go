@@ -1,15 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/easel/ddx/internal/auth"
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
 	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/gitignore"
 	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/starter"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/easel/ddx/internal/variables"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +30,13 @@ type InitOptions struct {
 	SkipClaudeInjection bool   // Skip injecting meta-prompts into CLAUDE.md
 	Repository          string // Custom repository URL (overrides default)
 	Branch              string // Custom repository branch (overrides default)
+	// Team is a shared configuration used in place of the defaults
+	Team *TeamConfig
+	// Variables fill the team configuration's placeholders and are recorded
+	Variables map[string]string
+	// Tx records the changes so they can be rolled back; initProject runs
+	// in a transaction of its own when it is nil
+	Tx *transaction.Tx
 }
 
 // Command registration is now handled by command_factory.go
@@ -32,6 +48,14 @@ type InitResult struct {
 	LibraryExists bool
 	IsDDxRepo     bool
 	Config        *config.Config
+	// StarterFiles is the number of files installed from the embedded
+	// starter library, zero when the library came from the repository
+	StarterFiles int
+	// LibraryError explains why the repository could not be used when the
+	// starter library was installed instead
+	LibraryError string
+	// TeamPrompts reports that the team's prompt overrides were installed
+	TeamPrompts bool
 }
 
 // runInit implements the CLI interface layer for the init command
@@ -39,10 +63,28 @@ func (f *CommandFactory) runInit(cmd *cobra.Command, args []string) error {
 	// Extract flags from cobra.Command
 	initForce, _ := cmd.Flags().GetBool("force")
 	initNoGit, _ := cmd.Flags().GetBool("no-git")
-	initSilent, _ := cmd.Flags().GetBool("silent")
+	initSilent := quiet(cmd)
 	initSkipClaude, _ := cmd.Flags().GetBool("skip-claude-injection")
 	initRepository, _ := cmd.Flags().GetString("repository")
 	initBranch, _ := cmd.Flags().GetString("branch")
+	nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+	templateName, _ := cmd.Flags().GetString("template")
+	workflowName, _ := cmd.Flags().GetString("workflow")
+	personaPairs, _ := cmd.Flags().GetStringArray("persona")
+	from, _ := cmd.Flags().GetString("from")
+	varPairs, _ := cmd.Flags().GetStringArray("var")
+
+	setup := InitSetup{Template: templateName, Workflow: workflowName}
+	for _, pair := range personaPairs {
+		role, persona, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(role) == "" || strings.TrimSpace(persona) == "" {
+			return fmt.Errorf("invalid persona binding %q (expected role=persona)", pair)
+		}
+		if setup.PersonaBindings == nil {
+			setup.PersonaBindings = make(map[string]string)
+		}
+		setup.PersonaBindings[strings.TrimSpace(role)] = strings.TrimSpace(persona)
+	}
 
 	// Create options struct for business logic
 	opts := InitOptions{
@@ -60,11 +102,78 @@ func (f *CommandFactory) runInit(cmd *cobra.Command, args []string) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout())
 	}
 
-	// Call pure business logic function
+	// Ask for what flags did not set when a user is at the terminal
+	var wizard *initWizard
+	if !nonInteractive && !opts.Silent && isTerminal(cmd.InOrStdin()) {
+		wizard = newInitWizard(cmd.InOrStdin(), cmd.OutOrStdout())
+	}
+
+	if from != "" {
+		provided, err := variables.ParseAssignments(varPairs)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		team, err := fetchTeamConfig(from)
+		if err != nil {
+			return NewExitError(1, err.Error())
+		}
+		defer func() { _ = team.Close() }()
+
+		values, missing, err := teamVariables(f.WorkingDir, team, provided)
+		if err != nil {
+			return err
+		}
+		if wizard != nil && len(missing) > 0 {
+			if err := wizard.askTeamVariables(missing, values); err != nil {
+				return err
+			}
+		}
+		opts.Team, opts.Variables = team, values
+	} else if wizard != nil {
+		if err := wizard.askLibrary(&opts); err != nil {
+			return err
+		}
+	}
+
+	// Everything init changes, in the files and in git, is rolled back
+	// when a later step fails, so a failed init can simply be run again
+	tx := transaction.Begin(f.WorkingDir)
+	opts.Tx = tx
 	result, err := initProject(f.WorkingDir, opts)
 	if err != nil {
 		cmd.SilenceUsage = true
-		return err
+		return transactionError(tx.Fail("init", err))
+	}
+
+	libPath := result.Config.Library.Path
+	if !filepath.IsAbs(libPath) {
+		libPath = filepath.Join(f.WorkingDir, libPath)
+	}
+	// A team configuration already made the setup choices
+	if wizard != nil && opts.Team == nil {
+		if _, err := os.Stat(libPath); err == nil {
+			if err := wizard.askSetup(f.WorkingDir, libPath, &setup); err != nil {
+				return transactionError(tx.Fail("init", err))
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout())
+		}
+	}
+
+	var setupResult *InitSetupResult
+	if !setup.Empty() {
+		setupResult, err = applyInitSetup(cmd.Context(), tx, f.WorkingDir, result.Config, setup, cmd.OutOrStdout())
+		if err == nil && !opts.NoGit {
+			err = commitInitSetup(f.WorkingDir)
+		}
+		if err != nil {
+			cmd.SilenceUsage = true
+			return transactionError(tx.Fail("init", err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		cmd.SilenceUsage = true
+		return transactionError(tx.Fail("init", err))
 	}
 
 	// Handle user output based on results
@@ -73,7 +182,43 @@ func (f *CommandFactory) runInit(cmd *cobra.Command, args []string) error {
 			_, _ = fmt.Fprint(cmd.OutOrStdout(), "📚 Detected DDx repository - configuring library_path to use ../library\n")
 		}
 
-		// Configuration created successfully
+		if result.StarterFiles > 0 {
+			if result.LibraryError != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "⚠️  Library repository unavailable: %s\n", strings.SplitN(result.LibraryError, "\n", 2)[0])
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📦 Installed embedded starter library (%d files) - run 'ddx update' when online for the full library\n", result.StarterFiles)
+		}
+
+		if opts.Team != nil {
+			source := result.Config.Source
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "👥 Initialized from team configuration %s", source.URL)
+			if len(source.Commit) >= 7 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), " (%s)", source.Commit[:7])
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout())
+			if result.TeamPrompts {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), "   Installed team prompt overrides in .ddx/prompts\n")
+			}
+			for _, role := range sortedBindingRoles(result.Config.PersonaBindings) {
+				persona := result.Config.PersonaBindings[role]
+				if _, err := os.Stat(filepath.Join(libPath, "personas", persona+".md")); err != nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "⚠️  Persona '%s' bound to %s is not in the library\n", persona, role)
+				}
+			}
+		}
+
+		if setup.Template != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📄 Applied template %s (%d files created)\n", setup.Template, len(setupResult.Created))
+			for _, skipped := range setupResult.Skipped {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "   kept existing %s\n", skipped)
+			}
+		}
+		if setup.Workflow != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "🔄 Activated %s workflow\n", setup.Workflow)
+		}
+		for _, role := range sortedBindingRoles(setup.PersonaBindings) {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "🎭 Bound %s → %s\n", role, setup.PersonaBindings[role])
+		}
 
 		_, _ = fmt.Fprint(cmd.OutOrStdout(), "✅ DDx initialized successfully!\n")
 		_, _ = fmt.Fprint(cmd.OutOrStdout(), "Initialized DDx in current project.\n")
@@ -94,8 +239,20 @@ func (f *CommandFactory) runInit(cmd *cobra.Command, args []string) error {
 }
 
 // initProject is the pure business logic function for project initialization
-func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
+func initProject(workingDir string, opts InitOptions) (_ *InitResult, err error) {
 	result := &InitResult{}
+	tx := opts.Tx
+	if tx == nil {
+		tx = transaction.Begin(workingDir)
+		defer func() {
+			if err == nil {
+				err = tx.Commit()
+			}
+			if err != nil {
+				err = transactionError(tx.Fail("init", err))
+			}
+		}()
+	}
 
 	// Validate git repository unless --no-git flag is used
 	if !opts.NoGit {
@@ -124,8 +281,15 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 	}
 	result.LibraryExists = libraryExists
 
-	// Create configuration with defaults
+	// Create configuration with defaults, or from the team configuration
 	localConfig := createProjectConfig()
+	if opts.Team != nil {
+		teamConfig, err := opts.Team.Config(opts.Variables)
+		if err != nil {
+			return nil, NewExitError(1, err.Error())
+		}
+		localConfig = teamConfig
+	}
 
 	// Apply default values (including repository settings)
 	localConfig.ApplyDefaults()
@@ -142,8 +306,9 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 		result.IsDDxRepo = true
 	}
 
-	// Try to load existing config to preserve settings (even if library doesn't exist yet)
-	if cfg != nil && err == nil {
+	// Try to load existing config to preserve settings (even if library doesn't exist yet),
+	// unless a team configuration replaces it
+	if cfg != nil && err == nil && opts.Team == nil {
 		// Note: Version is NOT copied - always upgrade to current version via ApplyDefaults
 		// Copy library settings if they exist (unless overridden by flags)
 		if cfg.Library != nil && localConfig.Library != nil {
@@ -182,6 +347,38 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 		localConfig.Library.Repository.Branch = opts.Branch
 	}
 
+	// Record what is there before anything changes
+	if err := tx.Track(".ddx"); err != nil {
+		return nil, NewExitError(1, err.Error())
+	}
+	libPath := localConfig.Library.Path
+	if !filepath.IsAbs(libPath) {
+		libPath = filepath.Join(workingDir, libPath)
+	}
+	gitPaths := []string{".ddx"}
+	if !result.IsDDxRepo && !fileExists(libPath) {
+		if err := tx.Track(libPath); err != nil {
+			return nil, NewExitError(1, err.Error())
+		}
+		gitPaths = append(gitPaths, localConfig.Library.Path)
+	}
+	// A .gitignore init creates is committed with the configuration
+	manageGitignore := !result.IsDDxRepo && localConfig.ManagesGitignore()
+	newGitignore := manageGitignore && !fileExists(filepath.Join(workingDir, gitignore.File))
+	if manageGitignore {
+		if err := tx.Track(gitignore.File); err != nil {
+			return nil, NewExitError(1, err.Error())
+		}
+		if newGitignore {
+			gitPaths = append(gitPaths, gitignore.File)
+		}
+	}
+	if !opts.NoGit {
+		if err := trackGitHead(tx, workingDir, gitPaths...); err != nil {
+			return nil, NewExitError(1, err.Error())
+		}
+	}
+
 	// Create .ddx directory first
 	localDDxPath := filepath.Join(workingDir, ".ddx")
 	if err := os.MkdirAll(localDDxPath, 0755); err != nil {
@@ -198,25 +395,63 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 	}
 	result.ConfigCreated = true
 
+	// Keep ddx's transient files out of version control
+	if manageGitignore {
+		if _, err := syncGitignore(workingDir, localConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	// Seed the variable registry with the project name
+	if err := recordProjectVariables(workingDir, map[string]string{"project_name": filepath.Base(workingDir)}); err != nil {
+		return nil, NewExitError(1, fmt.Sprintf("Failed to record project variables: %v", err))
+	}
+
+	if opts.Team != nil {
+		if err := recordTeamVariables(workingDir, opts.Variables); err != nil {
+			return nil, NewExitError(1, fmt.Sprintf("Failed to record project variables: %v", err))
+		}
+		if result.TeamPrompts, err = opts.Team.InstallPrompts(workingDir); err != nil {
+			return nil, NewExitError(1, err.Error())
+		}
+	}
+
 	// Set up git subtree for library synchronization
 	if !opts.NoGit {
 		if err := setupGitSubtreeLibraryPure(localConfig, workingDir); err != nil {
-			return nil, NewExitError(1, fmt.Sprintf("Failed to setup library: %v", err))
+			// Fall back to the embedded starter library so the project is usable offline
+			count, starterErr := installStarterLibrary(localConfig, workingDir)
+			if starterErr != nil || count == 0 {
+				return nil, NewExitError(1, fmt.Sprintf("Failed to setup library: %v", err))
+			}
+			result.StarterFiles = count
+			result.LibraryError = err.Error()
 		}
 
 		// Inject initial meta-prompt after library is set up (unless explicitly skipped)
 		if !opts.SkipClaudeInjection {
-			if err := injectInitialMetaPrompt(localConfig, workingDir); err != nil {
+			if err := injectInitialMetaPrompt(tx, localConfig, workingDir); err != nil {
 				// Don't fail - meta-prompt is optional enhancement
 				// Only warn if file actually exists but has issues
 				if _, statErr := os.Stat(filepath.Join(workingDir, localConfig.Library.Path, "prompts")); statErr == nil {
-					_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to inject meta-prompt: %v\n", err)
+					slog.Warn("failed to inject meta-prompt", "error", err)
 				}
 			}
 		}
 
-		// Commit config file after subtree setup
-		gitAdd := exec.Command("git", "add", ".ddx/config.yaml")
+		// Commit config file after subtree setup, along with the starter
+		// library when it was installed in place of the subtree
+		addPaths := []string{"add", ".ddx/config.yaml", ".ddx/" + variables.FileName}
+		if result.StarterFiles > 0 {
+			addPaths = append(addPaths, localConfig.Library.Path)
+		}
+		if result.TeamPrompts {
+			addPaths = append(addPaths, ".ddx/prompts")
+		}
+		if newGitignore && fileExists(filepath.Join(workingDir, gitignore.File)) {
+			addPaths = append(addPaths, gitignore.File)
+		}
+		gitAdd := exec.Command("git", addPaths...)
 		gitAdd.Dir = workingDir
 		if err := gitAdd.Run(); err != nil {
 			return nil, NewExitError(1, fmt.Sprintf("Failed to stage config file: %v", err))
@@ -229,6 +464,19 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 		}
 	}
 
+	// Without git there is no subtree to pull, so seed an empty project
+	// with the starter library
+	if opts.NoGit && !result.IsDDxRepo {
+		count, err := installStarterLibrary(localConfig, workingDir)
+		if err != nil {
+			return nil, NewExitError(1, fmt.Sprintf("Failed to install starter library: %v", err))
+		}
+		result.StarterFiles = count
+	}
+	if result.StarterFiles > 0 {
+		result.LibraryExists = true
+	}
+
 	// Store config for CLI layer to use for sync setup
 	result.Config = localConfig
 
@@ -237,6 +485,47 @@ func initProject(workingDir string, opts InitOptions) (*InitResult, error) {
 	return result, nil
 }
 
+// trackGitHead arranges for a rollback to move HEAD back to where it is
+// now, undoing the commits init makes, and to unstage init's paths. Other
+// staged changes stay staged; the files themselves are restored by the
+// transaction.
+func trackGitHead(tx *transaction.Tx, workingDir string, paths ...string) error {
+	head, err := gitOutput(workingDir, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		// No commits yet: remove whatever init commits and stages
+		tx.OnRollback(func() error {
+			if _, err := gitOutput(workingDir, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+				if _, err := gitOutput(workingDir, "update-ref", "-d", "HEAD"); err != nil {
+					return err
+				}
+			}
+			_, err := gitOutput(workingDir, append([]string{"rm", "-r", "-q", "--cached", "--ignore-unmatch", "--"}, paths...)...)
+			return err
+		})
+		return nil
+	}
+	tx.OnRollback(func() error {
+		current, err := gitOutput(workingDir, "rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		if current != head {
+			if _, err := gitOutput(workingDir, "reset", "-q", "--soft", head); err != nil {
+				return err
+			}
+		}
+		_, err = gitOutput(workingDir, append([]string{"reset", "-q", head, "--"}, paths...)...)
+		return err
+	})
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output
+func gitOutput(dir string, args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
 // isDDxRepository checks if we're in the DDx repository
 func isDDxRepository(workingDir string) bool {
 	// Check for identifying files that indicate this is the DDx repo
@@ -334,8 +623,9 @@ func isValidRepositoryURL(url string) bool {
 		return false
 	}
 
-	// Accept file:// URLs for local testing
-	if strings.HasPrefix(url, "file://") {
+	// Accept mirrors and bundles on the filesystem, for machines that
+	// cannot reach the upstream repository, and stored URLs
+	if git.IsLocalURL(url) || auth.IsSecretRef(url) {
 		return true
 	}
 
@@ -355,8 +645,13 @@ func isValidRepositoryURL(url string) bool {
 		}
 	}
 
-	// Accept any https URL
-	return strings.HasPrefix(url, "https://")
+	// Accept internal git servers
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://"} {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return auth.IsSSHURL(url)
 }
 
 // fileExistsInDir checks if a file exists in a specific directory
@@ -434,8 +729,12 @@ func setupGitSubtreeLibraryPure(cfg *config.Config, workingDir string) error {
 		ddxInGit = true
 	}
 
-	repoURL := cfg.Library.Repository.URL
-	branch := cfg.Library.Repository.Branch
+	repoURL, err := resolveConfigSecret(context.Background(), cfg.Library.Repository.URL)
+	if err != nil {
+		return err
+	}
+	repoURL = libraryURL(workingDir, repoURL)
+	branch := cfg.Library.Repository.Revision()
 	if branch == "" {
 		branch = "main"
 	}
@@ -487,14 +786,29 @@ func setupGitSubtreeLibraryPure(cfg *config.Config, workingDir string) error {
 
 	// Use pure-Go subtree implementation
 	if err := git.SubtreeAdd(".ddx/library", repoURL, branch); err != nil {
-		return fmt.Errorf("git subtree add failed: %v\nYou may need to run 'git subtree add --prefix=.ddx/library %s %s --squash' manually", err, repoURL, branch)
+		return fmt.Errorf("git subtree add failed: %v\nYou may need to run 'git subtree add --prefix=.ddx/library %s %s --squash' manually", err, cfg.Library.Repository.URL, branch)
 	}
 
 	return nil
 }
 
-// injectInitialMetaPrompt injects the configured meta-prompt into CLAUDE.md
-func injectInitialMetaPrompt(cfg *config.Config, workingDir string) error {
+// installStarterLibrary writes the embedded starter library to the
+// configured library path when nothing is there yet. It returns the number
+// of files installed, zero if a library already exists.
+func installStarterLibrary(cfg *config.Config, workingDir string) (int, error) {
+	libraryPath := cfg.Library.Path
+	if !filepath.IsAbs(libraryPath) {
+		libraryPath = filepath.Join(workingDir, libraryPath)
+	}
+	if entries, err := os.ReadDir(libraryPath); err == nil && len(entries) > 0 {
+		return 0, nil
+	}
+	return starter.Install(libraryPath)
+}
+
+// injectInitialMetaPrompt injects the configured meta-prompt into each AI
+// target file
+func injectInitialMetaPrompt(tx *transaction.Tx, cfg *config.Config, workingDir string) error {
 	// Get meta-prompt path from config (with default)
 	promptPath := cfg.GetMetaPrompt()
 	if promptPath == "" {
@@ -502,16 +816,28 @@ func injectInitialMetaPrompt(cfg *config.Config, workingDir string) error {
 		return nil
 	}
 
-	// Create injector
-	injector := metaprompt.NewMetaPromptInjectorWithPaths(
-		"CLAUDE.md",
-		cfg.Library.Path,
-		workingDir,
-	)
-
-	// Inject prompt
-	if err := injector.InjectMetaPrompt(promptPath); err != nil {
-		return fmt.Errorf("failed to inject meta-prompt: %w", err)
+	targetList, _, err := aiTargets(cfg)
+	if err != nil {
+		return err
+	}
+	if err := backupAIFiles(workingDir, "init", targets.Files(targetList)); err != nil {
+		return err
+	}
+	if err := tx.Track(targets.Files(targetList)...); err != nil {
+		return err
+	}
+	for _, target := range targetList {
+		injector := metaprompt.NewMetaPromptInjectorWithPaths(
+			target.File,
+			cfg.Library.Path,
+			workingDir,
+		)
+		err := filelock.With(filepath.Join(workingDir, target.File), func() error {
+			return injector.InjectMetaPrompt(promptPath)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to inject meta-prompt into %s: %w", target.File, err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/variables"
+	"gopkg.in/yaml.v3"
+)
+
+// teamConfigPaths are where a team repository may keep its configuration,
+// relative to the repository root
+var teamConfigPaths = []string{"config.yaml", ".ddx/config.yaml"}
+
+// teamPromptPaths are where a team repository may keep prompt overrides
+var teamPromptPaths = []string{"prompts", ".ddx/prompts"}
+
+// TeamConfig is a shared team configuration checked out from git
+type TeamConfig struct {
+	// Dir is the temporary checkout, removed by Close
+	Dir string
+	// Source records where the configuration came from
+	Source config.SourceConfig
+
+	configPath string
+}
+
+// splitTeamRef separates an optional '#ref' suffix from a team repository URL
+func splitTeamRef(from string) (string, string) {
+	if i := strings.LastIndex(from, "#"); i > 0 {
+		return from[:i], from[i+1:]
+	}
+	return from, ""
+}
+
+// fetchTeamConfig clones the team configuration repository named by from,
+// which may end in '#ref' to select a branch or tag
+func fetchTeamConfig(from string) (*TeamConfig, error) {
+	url, ref := splitTeamRef(from)
+	if url == "" {
+		return nil, fmt.Errorf("team configuration URL is empty")
+	}
+
+	dir, err := os.MkdirTemp("", "ddx-team-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	team := &TeamConfig{Dir: dir, Source: config.SourceConfig{URL: url, Ref: ref}}
+
+	args := []string{"clone", "--depth=1", "--quiet"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	clone := exec.Command("git", append(args, "--", url, dir)...)
+	if output, err := clone.CombinedOutput(); err != nil {
+		_ = team.Close()
+		return nil, fmt.Errorf("failed to clone team configuration %s: %v\n%s", from, err, strings.TrimSpace(string(output)))
+	}
+
+	revParse := exec.Command("git", "rev-parse", "HEAD")
+	revParse.Dir = dir
+	if output, err := revParse.Output(); err == nil {
+		team.Source.Commit = strings.TrimSpace(string(output))
+	}
+
+	for _, rel := range teamConfigPaths {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err == nil {
+			team.configPath = filepath.Join(dir, rel)
+			break
+		}
+	}
+	if team.configPath == "" {
+		_ = team.Close()
+		return nil, fmt.Errorf("team configuration %s has no %s", from, strings.Join(teamConfigPaths, " or "))
+	}
+	return team, nil
+}
+
+// Close removes the checkout
+func (t *TeamConfig) Close() error {
+	return os.RemoveAll(t.Dir)
+}
+
+// Placeholders lists the project-specific values the configuration needs
+func (t *TeamConfig) Placeholders() ([]string, error) {
+	data, err := os.ReadFile(t.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team configuration: %w", err)
+	}
+	return variables.Placeholders(string(data)), nil
+}
+
+// Config renders the configuration with values, maps deprecated fields to
+// their replacements, validates it against the configuration schema and
+// records its source
+func (t *TeamConfig) Config(values map[string]string) (*config.Config, error) {
+	data, err := os.ReadFile(t.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team configuration: %w", err)
+	}
+	rendered, missing := variables.Render(string(data), values)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("team configuration needs values for: %s (use --var name=value)", strings.Join(missing, ", "))
+	}
+
+	migrated := config.MigrateDeprecated(t.Source.URL, []byte(rendered))
+
+	validator, err := config.NewValidator()
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(migrated); err != nil {
+		return nil, fmt.Errorf("team configuration %s is invalid: %w", t.Source.URL, err)
+	}
+
+	var cfg config.Config
+	if err := yaml.Unmarshal(migrated, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse team configuration: %w", err)
+	}
+	source := t.Source
+	cfg.Source = &source
+	return &cfg, nil
+}
+
+// InstallPrompts copies the team's prompt overrides into the project's
+// .ddx/prompts directory, returning whether there were any
+func (t *TeamConfig) InstallPrompts(workingDir string) (bool, error) {
+	for _, rel := range teamPromptPaths {
+		src := filepath.Join(t.Dir, rel)
+		if info, err := os.Stat(src); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := copyDir(src, filepath.Join(workingDir, ".ddx", "prompts")); err != nil {
+			return false, fmt.Errorf("failed to install team prompts: %w", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// teamVariables resolves the values a team configuration needs from the
+// provided values, the project's recorded variables and init defaults
+func teamVariables(workingDir string, team *TeamConfig, provided map[string]string) (map[string]string, []string, error) {
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := registry.Merge(provided)
+	if _, ok := values["project_name"]; !ok {
+		values["project_name"] = filepath.Base(workingDir)
+	}
+
+	names, err := team.Placeholders()
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return values, missing, nil
+}
+
+// recordTeamVariables records the values given for a team configuration so
+// prompts and templates rendered later reuse them
+func recordTeamVariables(workingDir string, values map[string]string) error {
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return err
+	}
+	if !registry.Record(values) {
+		return nil
+	}
+	return registry.Save()
+}
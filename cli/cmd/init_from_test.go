@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTeamRepo commits files to a new git repository and returns its path
+func createTeamRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"add", "."},
+		{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--quiet", "-m", "team config"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = dir
+		output, err := git.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	return dir
+}
+
+func TestInitCommand_From(t *testing.T) {
+	repo := createTeamRepo(t, map[string]string{
+		"config.yaml": `version: "1.0"
+workflows:
+  active: [helix]
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+prompts:
+  review: .ddx/prompts/review.md
+agents:
+  preset: claude
+  args: ["--append-system-prompt", "You work on {{project_name}} for the {{team}} team"]
+`,
+		"prompts/review.md": "Review {{project_name}} like the {{team}} team\n",
+	})
+
+	te := NewTestEnvironment(t, WithGitInit(false))
+	output, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo, "--var", "team=payments")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Initialized from team configuration "+repo)
+	assert.Contains(t, output, "Installed team prompt overrides")
+
+	cfg, err := config.LoadWithWorkingDir(te.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"helix"}, cfg.Workflows.Active)
+	assert.Equal(t, "strict-code-reviewer", cfg.PersonaBindings["code-reviewer"])
+	assert.Contains(t, cfg.Agents.Args, "You work on "+filepath.Base(te.Dir)+" for the payments team")
+	require.NotNil(t, cfg.Source)
+	assert.Equal(t, repo, cfg.Source.URL)
+	assert.Len(t, cfg.Source.Commit, 40)
+
+	registry, err := variables.Load(te.Dir)
+	require.NoError(t, err)
+	team, _ := registry.Get("team")
+	assert.Equal(t, "payments", team)
+
+	// The override is found before the library and rendered with the recorded values
+	output, err = te.RunCommand("p", "review")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Review "+filepath.Base(te.Dir)+" like the payments team")
+	output, err = te.RunCommand("prompts", "show", "review")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "like the payments team")
+
+	t.Run("missing values", func(t *testing.T) {
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo)
+		assert.ErrorContains(t, err, "team configuration needs values for: team")
+		assert.NoFileExists(t, te.ConfigPath)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		repo := createTeamRepo(t, map[string]string{".ddx/config.yaml": "version: \"1.0\"\nunknown_field: true\n"})
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo)
+		assert.ErrorContains(t, err, "team configuration "+repo+" is invalid")
+	})
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		repo := createTeamRepo(t, map[string]string{"config.yaml": "version: \"1.0\"\nlibrary_path: .ddx/team-library\n"})
+		te := NewTestEnvironment(t, WithGitInit(false))
+		output, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo)
+		require.NoError(t, err, output)
+
+		cfg, err := config.LoadWithWorkingDir(te.Dir)
+		require.NoError(t, err)
+		assert.Equal(t, ".ddx/team-library", cfg.Library.Path)
+	})
+
+	t.Run("no configuration", func(t *testing.T) {
+		repo := createTeamRepo(t, map[string]string{"README.md": "nothing here\n"})
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo)
+		assert.ErrorContains(t, err, "has no config.yaml or .ddx/config.yaml")
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--non-interactive", "--from", repo+"#missing")
+		assert.ErrorContains(t, err, "failed to clone team configuration")
+	})
+}
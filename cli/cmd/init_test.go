@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/easel/ddx/internal/variables"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -43,6 +44,17 @@ func TestInitCommand(t *testing.T) {
 				if library, ok := config["library"].(map[string]interface{}); ok {
 					assert.Contains(t, library, "repository")
 				}
+
+				// Without git the embedded starter library is installed
+				assert.FileExists(t, filepath.Join(te.Dir, ".ddx", "library", "workflows", "helix", "workflow.yml"))
+				assert.FileExists(t, filepath.Join(te.Dir, ".ddx", "library", "personas", "strict-code-reviewer.md"))
+				assert.Contains(t, output, "starter library")
+
+				// The project name is recorded for reuse by templates and prompts
+				registry, err := variables.Load(te.Dir)
+				require.NoError(t, err)
+				projectName, _ := registry.Get("project_name")
+				assert.Equal(t, filepath.Base(te.Dir), projectName)
 			},
 			expectError: false,
 		},
@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/templates"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/easel/ddx/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultLibraryRepository is offered when no repository is configured
+const defaultLibraryRepository = "https://github.com/easel/ddx-library"
+
+// InitSetup holds the project choices applied once the library is in place
+type InitSetup struct {
+	// Template is the starter template applied to the project
+	Template string
+	// Workflow is activated in the project configuration
+	Workflow string
+	// PersonaBindings maps roles to the personas that fill them
+	PersonaBindings map[string]string
+	// Variables fill the template's placeholders
+	Variables map[string]string
+}
+
+// Empty reports whether there is nothing to set up
+func (s InitSetup) Empty() bool {
+	return s.Template == "" && s.Workflow == "" && len(s.PersonaBindings) == 0
+}
+
+// InitSetupResult describes the changes made by applyInitSetup
+type InitSetupResult struct {
+	Created []string
+	// Skipped template files already existed in the project
+	Skipped []string
+}
+
+// initChoice is an option offered by the init wizard
+type initChoice struct {
+	Name        string
+	Description string
+}
+
+// noneChoice lets the user skip a wizard step
+var noneChoice = initChoice{Name: "none", Description: "Skip this step"}
+
+// libraryTemplates lists the templates in the library that have a manifest
+func libraryTemplates(libPath string) []initChoice {
+	var choices []initChoice
+	entries, _ := os.ReadDir(filepath.Join(libPath, "templates"))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := templates.LoadManifest(filepath.Join(libPath, "templates", entry.Name()))
+		if err != nil {
+			continue
+		}
+		choices = append(choices, initChoice{Name: entry.Name(), Description: manifest.Description})
+	}
+	return choices
+}
+
+// libraryWorkflows lists the workflows in the library that load cleanly
+func libraryWorkflows(libPath string) []initChoice {
+	var choices []initChoice
+	loader := workflow.NewLoader(libPath)
+	entries, _ := os.ReadDir(filepath.Join(libPath, "workflows"))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		def, err := loader.Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		choices = append(choices, initChoice{Name: entry.Name(), Description: def.Description})
+	}
+	return choices
+}
+
+// setupRoles returns the roles to bind personas for: those the workflow's
+// phases require, followed by the code reviewer used by most projects
+func setupRoles(libPath, workflowName string) []string {
+	var roles []string
+	seen := make(map[string]bool)
+	add := func(role string) {
+		if role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	if workflowName != "" {
+		if def, err := workflow.NewLoader(libPath).Load(workflowName); err == nil {
+			for _, phase := range def.Phases {
+				add(phase.RequiredRole)
+			}
+		}
+	}
+	add("code-reviewer")
+	return roles
+}
+
+// applyInitSetup applies the template, activates the workflow and binds
+// personas in an initialized project. The changes are made in tx, which
+// the caller commits.
+func applyInitSetup(ctx context.Context, tx *transaction.Tx, workingDir string, cfg *config.Config, setup InitSetup, out io.Writer) (*InitSetupResult, error) {
+	libPath := cfg.Library.Path
+	if !filepath.IsAbs(libPath) {
+		libPath = filepath.Join(workingDir, libPath)
+	}
+	result := &InitSetupResult{}
+
+	if setup.Template != "" {
+		dir := filepath.Join(libPath, "templates", setup.Template)
+		manifest, err := templates.LoadManifest(dir)
+		if err != nil {
+			return nil, fmt.Errorf("template '%s' not found: %w", setup.Template, err)
+		}
+		registry, err := variables.Load(workingDir)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := templates.Plan(dir, workingDir, registry.Merge(setup.Variables), false)
+		if err != nil {
+			return nil, err
+		}
+		applied, err := plan.Stage(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		// The post-apply hooks run against the files
+		if err := tx.Apply(); err != nil {
+			return nil, err
+		}
+		result.Created, result.Skipped = applied.Created, applied.Skipped
+		if registry.Record(setup.Variables) {
+			if err := tx.Track(variables.Path(workingDir)); err != nil {
+				return nil, err
+			}
+			if err := registry.Save(); err != nil {
+				return nil, err
+			}
+		}
+		if err := templates.NewHookRunner(out).RunPostApply(ctx, manifest, workingDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if setup.Workflow != "" {
+		if _, err := workflow.NewLoader(libPath).Load(setup.Workflow); err != nil {
+			return nil, err
+		}
+	}
+	for role, persona := range setup.PersonaBindings {
+		if _, err := os.Stat(filepath.Join(libPath, "personas", persona+".md")); err != nil {
+			return nil, fmt.Errorf("persona '%s' for role %s not found in the library", persona, role)
+		}
+	}
+
+	if setup.Workflow == "" && len(setup.PersonaBindings) == 0 {
+		return result, nil
+	}
+	configPath := filepath.Join(workingDir, ".ddx", "config.yaml")
+	if err := tx.Track(configPath); err != nil {
+		return nil, err
+	}
+	err := config.UpdateFile(configPath, func(doc *yaml.Node) error {
+		if setup.Workflow != "" {
+			active := false
+			for _, name := range cfg.Workflows.Active {
+				active = active || name == setup.Workflow
+			}
+			if !active {
+				if err := config.AppendValue(doc, "workflows.active", setup.Workflow); err != nil {
+					return err
+				}
+			}
+		}
+		for _, role := range sortedBindingRoles(setup.PersonaBindings) {
+			if err := config.SetValue(doc, "persona_bindings."+role, setup.PersonaBindings[role]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update configuration: %w", err)
+	}
+	return result, nil
+}
+
+// sortedBindingRoles returns the roles of persona bindings in sorted order
+func sortedBindingRoles(bindings map[string]string) []string {
+	roles := make([]string, 0, len(bindings))
+	for role := range bindings {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// commitInitSetup commits the configuration changes made by the setup,
+// leaving files created from a template for the user to review
+func commitInitSetup(workingDir string) error {
+	add := exec.Command("git", "add", ".ddx/config.yaml", ".ddx/"+variables.FileName)
+	add.Dir = workingDir
+	if err := add.Run(); err != nil {
+		return fmt.Errorf("failed to stage configuration: %w", err)
+	}
+	// Nothing staged means the setup did not change the configuration
+	diff := exec.Command("git", "diff", "--cached", "--quiet")
+	diff.Dir = workingDir
+	if diff.Run() == nil {
+		return nil
+	}
+	commit := exec.Command("git", "commit", "-m", "chore: configure DDx workflow and personas")
+	commit.Dir = workingDir
+	if output, err := commit.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit configuration: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// initWizard asks the questions of the interactive init flow
+type initWizard struct {
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+// newInitWizard creates a wizard reading answers from in
+func newInitWizard(in io.Reader, out io.Writer) *initWizard {
+	return &initWizard{reader: bufio.NewReader(in), out: out}
+}
+
+// ask reads a free-form answer, returning def when the answer is empty
+func (w *initWizard) ask(label, def string) (string, error) {
+	if def != "" {
+		_, _ = fmt.Fprintf(w.out, "%s [%s]: ", label, def)
+	} else {
+		_, _ = fmt.Fprintf(w.out, "%s: ", label)
+	}
+	line, err := w.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose offers numbered choices, accepting a number or a name. The
+// default is returned for an empty answer or when input runs out.
+func (w *initWizard) choose(label string, choices []initChoice, def string) (string, error) {
+	_, _ = fmt.Fprintf(w.out, "\n%s:\n", label)
+	defIndex := 0
+	for i, choice := range choices {
+		if choice.Name == def {
+			defIndex = i + 1
+		}
+		if choice.Description != "" {
+			_, _ = fmt.Fprintf(w.out, "  %d) %s - %s\n", i+1, choice.Name, choice.Description)
+		} else {
+			_, _ = fmt.Fprintf(w.out, "  %d) %s\n", i+1, choice.Name)
+		}
+	}
+
+	for {
+		_, _ = fmt.Fprintf(w.out, "Choose [%d]: ", defIndex)
+		line, err := w.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			return def, nil
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1].Name, nil
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(choice.Name, answer) {
+				return choice.Name, nil
+			}
+		}
+		if err == io.EOF {
+			return def, nil
+		}
+		_, _ = fmt.Fprintf(w.out, "Enter a number from 1 to %d\n", len(choices))
+	}
+}
+
+// askLibrary asks for the library repository unless flags already set it
+func (w *initWizard) askLibrary(opts *InitOptions) error {
+	_, _ = fmt.Fprintln(w.out, "Library")
+	var err error
+	if opts.Repository == "" {
+		if opts.Repository, err = w.ask("  Repository URL", defaultLibraryRepository); err != nil {
+			return err
+		}
+		if opts.Repository == defaultLibraryRepository {
+			opts.Repository = ""
+		}
+	}
+	if opts.Branch == "" {
+		if opts.Branch, err = w.ask("  Branch", "main"); err != nil {
+			return err
+		}
+		if opts.Branch == "main" {
+			opts.Branch = ""
+		}
+	}
+	_, _ = fmt.Fprintln(w.out)
+	return nil
+}
+
+// askSetup asks for the template, workflow and persona bindings offered by
+// the library, keeping any choices already made with flags
+func (w *initWizard) askSetup(workingDir, libPath string, setup *InitSetup) error {
+	if setup.Template == "" {
+		if choices := libraryTemplates(libPath); len(choices) > 0 {
+			answer, err := w.choose("Starter template", append(choices, noneChoice), noneChoice.Name)
+			if err != nil {
+				return err
+			}
+			if answer != noneChoice.Name {
+				setup.Template = answer
+			}
+		}
+	}
+	if setup.Template != "" {
+		if err := w.askTemplateVariables(workingDir, filepath.Join(libPath, "templates", setup.Template), setup); err != nil {
+			return err
+		}
+	}
+
+	if setup.Workflow == "" {
+		if choices := libraryWorkflows(libPath); len(choices) > 0 {
+			answer, err := w.choose("Workflow", append(choices, noneChoice), choices[0].Name)
+			if err != nil {
+				return err
+			}
+			if answer != noneChoice.Name {
+				setup.Workflow = answer
+			}
+		}
+	}
+
+	personas, err := personaList(workingDir, "", "")
+	if err != nil {
+		return err
+	}
+	for _, role := range setupRoles(libPath, setup.Workflow) {
+		if _, ok := setup.PersonaBindings[role]; ok {
+			continue
+		}
+		var choices []initChoice
+		for _, p := range personas {
+			for _, r := range p.Roles {
+				if r == role {
+					choices = append(choices, initChoice{Name: p.Name, Description: p.Description})
+					break
+				}
+			}
+		}
+		if len(choices) == 0 {
+			continue
+		}
+		answer, err := w.choose(fmt.Sprintf("Persona for the %s role", role), append(choices, noneChoice), choices[0].Name)
+		if err != nil {
+			return err
+		}
+		if answer != noneChoice.Name {
+			if setup.PersonaBindings == nil {
+				setup.PersonaBindings = make(map[string]string)
+			}
+			setup.PersonaBindings[role] = answer
+		}
+	}
+	return nil
+}
+
+// askTemplateVariables asks for the template's variables, offering
+// recorded values and manifest defaults
+func (w *initWizard) askTemplateVariables(workingDir, dir string, setup *InitSetup) error {
+	manifest, err := templates.LoadManifest(dir)
+	if err != nil || len(manifest.Variables) == 0 {
+		return nil
+	}
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return err
+	}
+	if setup.Variables == nil {
+		setup.Variables = make(map[string]string)
+	}
+
+	_, _ = fmt.Fprintf(w.out, "\n%s variables:\n", manifest.Name)
+	for _, v := range manifest.Variables {
+		if setup.Variables[v.Name] != "" {
+			continue
+		}
+		def := v.Default
+		if recorded, ok := registry.Get(v.Name); ok {
+			def = recorded
+		}
+		label := "  " + v.Name
+		if v.Description != "" {
+			label = fmt.Sprintf("  %s (%s)", v.Name, v.Description)
+		}
+		value, err := w.ask(label, def)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			setup.Variables[v.Name] = value
+		}
+	}
+	return nil
+}
+
+// askTeamVariables asks for the values a team configuration needs that
+// were neither given nor recorded
+func (w *initWizard) askTeamVariables(names []string, values map[string]string) error {
+	_, _ = fmt.Fprintln(w.out, "Team configuration variables")
+	for _, name := range names {
+		value, err := w.ask("  "+name, "")
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			values[name] = value
+		}
+	}
+	_, _ = fmt.Fprintln(w.out)
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitCommand_Setup(t *testing.T) {
+	te := NewTestEnvironment(t, WithGitInit(false))
+
+	output, err := te.RunCommand("init", "--no-git", "--non-interactive",
+		"--template", "basic-project",
+		"--workflow", "helix",
+		"--persona", "code-reviewer=strict-code-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Applied template basic-project")
+	assert.Contains(t, output, "Activated helix workflow")
+	assert.Contains(t, output, "Bound code-reviewer → strict-code-reviewer")
+
+	readme, err := os.ReadFile(filepath.Join(te.Dir, "README.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(readme), "# "+filepath.Base(te.Dir))
+	assert.FileExists(t, filepath.Join(te.Dir, "docs", "README.md"))
+
+	cfg, err := config.LoadWithWorkingDir(te.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"helix"}, cfg.Workflows.Active)
+	assert.Equal(t, "strict-code-reviewer", cfg.PersonaBindings["code-reviewer"])
+
+	t.Run("unknown persona", func(t *testing.T) {
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--persona", "code-reviewer=missing")
+		assert.ErrorContains(t, err, "persona 'missing' for role code-reviewer not found")
+	})
+
+	t.Run("invalid persona flag", func(t *testing.T) {
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--persona", "code-reviewer")
+		assert.ErrorContains(t, err, "expected role=persona")
+	})
+}
+
+func TestInitWizard(t *testing.T) {
+	t.Run("library defaults", func(t *testing.T) {
+		var out bytes.Buffer
+		opts := InitOptions{}
+		require.NoError(t, newInitWizard(strings.NewReader("\n\n"), &out).askLibrary(&opts))
+		assert.Empty(t, opts.Repository, "the default repository is left to the config defaults")
+		assert.Empty(t, opts.Branch)
+		assert.Contains(t, out.String(), "Repository URL ["+defaultLibraryRepository+"]")
+	})
+
+	t.Run("library flags are not asked again", func(t *testing.T) {
+		var out bytes.Buffer
+		opts := InitOptions{Repository: "https://example.com/lib", Branch: "dev"}
+		require.NoError(t, newInitWizard(strings.NewReader(""), &out).askLibrary(&opts))
+		assert.NotContains(t, out.String(), "Repository URL")
+		assert.Equal(t, "dev", opts.Branch)
+	})
+
+	t.Run("choices", func(t *testing.T) {
+		choices := []initChoice{{Name: "helix"}, {Name: "kanban"}, noneChoice}
+		cases := map[string]string{
+			"\n":        "helix",
+			"2\n":       "kanban",
+			"KANBAN\n":  "kanban",
+			"9\nnone\n": "none",
+			"":          "helix",
+		}
+		for input, want := range cases {
+			var out bytes.Buffer
+			got, err := newInitWizard(strings.NewReader(input), &out).choose("Workflow", choices, "helix")
+			require.NoError(t, err)
+			assert.Equal(t, want, got, "input %q", input)
+		}
+	})
+
+	t.Run("setup from the library", func(t *testing.T) {
+		te := NewTestEnvironment(t, WithGitInit(false))
+		_, err := te.RunCommand("init", "--no-git", "--non-interactive")
+		require.NoError(t, err)
+		libPath := filepath.Join(te.Dir, ".ddx", "library")
+
+		// Template, its variable, workflow, then one answer per role
+		answers := "1\nmy-service\n\n" + strings.Repeat("\n", len(setupRoles(libPath, "helix")))
+		var out bytes.Buffer
+		setup := InitSetup{}
+		require.NoError(t, newInitWizard(strings.NewReader(answers), &out).askSetup(te.Dir, libPath, &setup))
+
+		assert.Equal(t, "basic-project", setup.Template)
+		assert.Equal(t, "my-service", setup.Variables["project_name"])
+		assert.Equal(t, "helix", setup.Workflow)
+		assert.Equal(t, "strict-code-reviewer", setup.PersonaBindings["code-reviewer"])
+		assert.Equal(t, "architect-systems", setup.PersonaBindings["architect"])
+		assert.Contains(t, out.String(), "Persona for the code-reviewer role")
+	})
+}
+
+func TestInitCommand_RollsBackOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through a POSIX shell")
+	}
+	te := NewTestEnvironment(t)
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = te.Dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	te.CreateFile("README.md", "# Project\n")
+	git("add", "README.md")
+	git("commit", "-q", "-m", "initial")
+	head := git("rev-parse", "HEAD")
+
+	config := "version: \"1.0\"\nlibrary:\n  path: .ddx/library\n"
+	te.CreateConfig(config)
+	te.CreateFile(".ddx/library/templates/broken/template.yml", "name: broken\nhooks:\n  post_apply:\n    - name: Fail\n      run: exit 3\n")
+	te.CreateFile(".ddx/library/templates/broken/docs/guide.md", "# Guide\n")
+	status := git("status", "--porcelain")
+
+	_, err := te.RunCommand("init", "--force", "--non-interactive", "--skip-claude-injection", "--template", "broken")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "init failed and its changes were rolled back")
+
+	assert.Equal(t, head, git("rev-parse", "HEAD"), "init's commit is undone")
+	assert.Equal(t, status, git("status", "--porcelain"))
+	data, err := os.ReadFile(te.ConfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, config, string(data))
+	assert.NoDirExists(t, filepath.Join(te.Dir, "docs"))
+	assert.NoFileExists(t, filepath.Join(te.Dir, ".ddx", "variables.yml"))
+	entries, err := os.ReadDir(te.Dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.Contains(t, []string{".git", ".ddx", "README.md"}, entry.Name(), "nothing is left behind")
+	}
+}
@@ -1,33 +1,35 @@
 package cmd
 
 import (
-	"archive/tar"
-	"archive/zip"
-	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
-	"time"
 
+	"github.com/easel/ddx/internal/network"
+	"github.com/easel/ddx/internal/pathsetup"
+	"github.com/easel/ddx/internal/progress"
+	"github.com/easel/ddx/internal/release"
 	"github.com/spf13/cobra"
 )
 
+// releaseClient overrides the client release archives are downloaded
+// with; nil uses the network configuration
+var releaseClient *http.Client
+
 // runInstall implements the install command logic
 func runInstall(cmd *cobra.Command, args []string) error {
-	fmt.Println("🚀 Installing DDx...")
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintln(statusOut(cmd), "🚀 Installing DDx...")
 
 	// Get installation parameters
 	version, _ := cmd.Flags().GetString("version")
 	installPath, _ := cmd.Flags().GetString("path")
 	force, _ := cmd.Flags().GetBool("force")
-
-	if version == "" {
-		version = "latest"
-	}
+	verifySignature, _ := cmd.Flags().GetBool("verify-signature")
 
 	if installPath == "" {
 		var err error
@@ -41,264 +43,213 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	if !force {
 		existing := filepath.Join(installPath, getBinaryName())
 		if _, err := os.Stat(existing); err == nil {
-			fmt.Printf("⚠️  DDx is already installed at %s\n", existing)
-			fmt.Println("Use --force to overwrite or --path to install elsewhere")
+			_, _ = fmt.Fprintf(out, "⚠️  DDx is already installed at %s\n", existing)
+			_, _ = fmt.Fprintln(out, "Use --force to overwrite or --path to install elsewhere")
 			return nil
 		}
 	}
 
-	// Create install directory
-	if err := os.MkdirAll(installPath, 0755); err != nil {
-		return fmt.Errorf("failed to create install directory: %w", err)
-	}
-
-	// Download and install
-	fmt.Printf("📦 Downloading DDx %s for %s/%s...\n", version, runtime.GOOS, runtime.GOARCH)
-
-	downloadURL, err := getDownloadURL(version)
+	// Download, verify and install
+	dir, err := os.MkdirTemp("", "ddx-install-*")
 	if err != nil {
-		return fmt.Errorf("failed to get download URL: %w", err)
+		return fmt.Errorf("failed to create temporary directory: %w", err)
 	}
-
-	if err := downloadAndInstall(downloadURL, installPath); err != nil {
+	defer func() { _ = os.RemoveAll(dir) }()
+	artifact, err := downloadRelease(cmd.Context(), statusOut(cmd), newProgress(cmd), version, release.CurrentPlatform(), dir, verifySignature)
+	if err != nil {
 		return fmt.Errorf("installation failed: %w", err)
 	}
-
-	// Verify installation
-	binaryPath := filepath.Join(installPath, getBinaryName())
-	if _, err := os.Stat(binaryPath); err != nil {
-		return fmt.Errorf("installation verification failed: binary not found at %s", binaryPath)
+	binaryPath, err := release.Install(artifact.Path, artifact.Platform, installPath)
+	if err != nil {
+		return fmt.Errorf("installation failed: %w", err)
 	}
 
 	// Setup PATH if needed
-	if err := setupPath(installPath); err != nil {
-		fmt.Printf("⚠️  Warning: Could not setup PATH automatically: %v\n", err)
-		fmt.Printf("Please add %s to your PATH manually\n", installPath)
+	if err := setupPath(out, installPath, ""); err != nil {
+		_, _ = fmt.Fprintf(out, "⚠️  Warning: Could not setup PATH automatically: %v\n", err)
+		_, _ = fmt.Fprintf(out, "Please add %s to your PATH manually\n", installPath)
 	}
 
-	fmt.Printf("✅ DDx installed successfully to %s\n", binaryPath)
-	fmt.Println("💡 Run 'ddx version' to verify installation")
+	_, _ = fmt.Fprintf(out, "✅ DDx installed successfully to %s\n", binaryPath)
+	_, _ = fmt.Fprintln(statusOut(cmd), "💡 Run 'ddx version' to verify installation")
 
 	return nil
 }
 
-// getDefaultInstallPath returns the default installation path for the current platform
-func getDefaultInstallPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	switch runtime.GOOS {
-	case "windows":
-		return filepath.Join(homeDir, "bin"), nil
-	default:
-		return filepath.Join(homeDir, ".local", "bin"), nil
-	}
-}
+// runDownloadBinary implements the download-binary command
+func runDownloadBinary(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+	platformFlag, _ := cmd.Flags().GetString("platform")
+	dir, _ := cmd.Flags().GetString("output")
+	verifySignature, _ := cmd.Flags().GetBool("verify-signature")
 
-// getBinaryName returns the binary name for the current platform
-func getBinaryName() string {
-	if runtime.GOOS == "windows" {
-		return "ddx.exe"
+	platform := release.CurrentPlatform()
+	if platformFlag != "" {
+		var err error
+		if platform, err = release.ParsePlatform(platformFlag); err != nil {
+			return err
+		}
 	}
-	return "ddx"
-}
-
-// getDownloadURL constructs the download URL for the specified version
-func getDownloadURL(version string) (string, error) {
-	if version == "latest" {
-		version = "v0.0.1" // For now, use a fixed version
+	if dir == "" {
+		var err error
+		if dir, err = release.DefaultDownloadDir(); err != nil {
+			return err
+		}
 	}
 
-	platform := runtime.GOOS
-	arch := runtime.GOARCH
-
-	// Map Go arch names to common names
-	switch arch {
-	case "amd64":
-		arch = "x86_64"
-	case "386":
-		arch = "i386"
+	out := cmd.OutOrStdout()
+	artifact, err := downloadRelease(cmd.Context(), statusOut(cmd), newProgress(cmd), version, platform, dir, verifySignature)
+	if err != nil {
+		return err
 	}
-
-	var ext string
-	if platform == "windows" {
-		ext = "zip"
-	} else {
-		ext = "tar.gz"
+	_, _ = fmt.Fprintf(out, "✅ Saved %s\n", artifact.Path)
+	if platform == release.CurrentPlatform() {
+		_, _ = fmt.Fprintln(statusOut(cmd), "💡 Run 'ddx install-binary' to install it")
 	}
-
-	// Use GitHub releases URL pattern
-	baseURL := "https://github.com/easel/ddx/releases/download"
-	filename := fmt.Sprintf("ddx_%s_%s_%s.%s", version, platform, arch, ext)
-
-	return fmt.Sprintf("%s/%s/%s", baseURL, version, filename), nil
+	return nil
 }
 
-// downloadAndInstall downloads and installs the binary
-func downloadAndInstall(url, installPath string) error {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
-	}
+// runInstallBinary implements the install-binary command. It installs an
+// archive saved by download-binary, downloading one first when needed, and
+// verifies its checksum again before extracting the binary.
+func runInstallBinary(cmd *cobra.Command, args []string) error {
+	archive, _ := cmd.Flags().GetString("archive")
+	version, _ := cmd.Flags().GetString("version")
+	installPath, _ := cmd.Flags().GetString("path")
+	verifySignature, _ := cmd.Flags().GetBool("verify-signature")
+	out := cmd.OutOrStdout()
 
-	// Extract based on file extension
-	if strings.HasSuffix(url, ".zip") {
-		return extractZip(resp.Body, installPath)
-	} else {
-		return extractTarGz(resp.Body, installPath)
+	platform := release.CurrentPlatform()
+	if installPath == "" {
+		var err error
+		if installPath, err = getDefaultInstallPath(); err != nil {
+			return fmt.Errorf("failed to determine install path: %w", err)
+		}
 	}
-}
 
-// extractZip extracts a ZIP archive
-func extractZip(r io.Reader, installPath string) error {
-	// For simplicity, we'll create a temp file then extract
-	tempFile, err := os.CreateTemp("", "ddx-*.zip")
-	if err != nil {
-		return err
+	if archive == "" {
+		dir, err := release.DefaultDownloadDir()
+		if err != nil {
+			return err
+		}
+		cached := filepath.Join(dir, platform.ArchiveName())
+		if _, err := os.Stat(cached); err == nil && version == "" && !verifySignature {
+			_, _ = fmt.Fprintf(statusOut(cmd), "📦 Using downloaded %s\n", cached)
+			archive = cached
+		} else {
+			artifact, err := downloadRelease(cmd.Context(), statusOut(cmd), newProgress(cmd), version, platform, dir, verifySignature)
+			if err != nil {
+				return err
+			}
+			archive = artifact.Path
+		}
 	}
-	defer func() { _ = os.Remove(tempFile.Name()) }()
-	defer func() { _ = tempFile.Close() }()
 
-	if _, err := io.Copy(tempFile, r); err != nil {
+	if err := release.VerifyFile(archive); err != nil {
 		return err
 	}
-
-	// Reopen for reading
-	zipReader, err := zip.OpenReader(tempFile.Name())
+	binaryPath, err := release.Install(archive, platform, installPath)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = zipReader.Close() }()
-
-	for _, file := range zipReader.File {
-		if strings.Contains(file.Name, getBinaryName()) {
-			return extractZipFile(file, installPath)
-		}
-	}
-
-	return fmt.Errorf("binary not found in archive")
+	_, _ = fmt.Fprintf(out, "✅ Installed %s\n", binaryPath)
+	return nil
 }
 
-// extractZipFile extracts a single file from ZIP
-func extractZipFile(file *zip.File, installPath string) error {
-	rc, err := file.Open()
-	if err != nil {
-		return err
+// downloadRelease downloads and verifies a release archive, reporting
+// progress to out and the bytes received to ind
+func downloadRelease(ctx context.Context, out io.Writer, ind *progress.Indicator, version string, platform release.Platform, dir string, verifySignature bool) (*release.Artifact, error) {
+	if version == "" {
+		version = release.Latest
 	}
-	defer func() { _ = rc.Close() }()
-
-	outPath := filepath.Join(installPath, getBinaryName())
-	outFile, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	_, _ = fmt.Fprintf(out, "📦 Downloading DDx %s for %s...\n", version, platform)
+	downloader, err := newReleaseDownloader()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() { _ = outFile.Close() }()
-
-	_, err = io.Copy(outFile, rc)
-	return err
-}
-
-// extractTarGz extracts a tar.gz archive
-func extractTarGz(r io.Reader, installPath string) error {
-	gzr, err := gzip.NewReader(r)
+	downloader.RequireSignature = verifySignature
+	downloader.Progress = ind.Bytes("Downloading")
+	ind.Start("Downloading")
+	artifact, err := downloader.Download(ctx, version, platform, dir)
+	ind.Stop()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() { _ = gzr.Close() }()
-
-	tr := tar.NewReader(gzr)
-
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		if strings.Contains(header.Name, getBinaryName()) && header.Typeflag == tar.TypeReg {
-			outPath := filepath.Join(installPath, getBinaryName())
-			outFile, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
-			if err != nil {
-				return err
-			}
-			defer func() { _ = outFile.Close() }()
+	_, _ = fmt.Fprintf(out, "🔒 Verified SHA256 %s\n", artifact.Checksum)
+	if artifact.Signed {
+		_, _ = fmt.Fprintln(out, "🔏 Verified sigstore signature")
+	}
+	return artifact, nil
+}
 
-			_, err = io.Copy(outFile, tr)
-			return err
+// newReleaseDownloader returns a downloader using the network
+// configuration
+func newReleaseDownloader() (*release.Downloader, error) {
+	settings := network.Current()
+	client := releaseClient
+	if client == nil {
+		// The downloader retries itself, also after interrupted transfers
+		direct := settings
+		direct.Retries = 0
+		var err error
+		if client, err = network.NewClient(direct); err != nil {
+			return nil, err
 		}
 	}
+	downloader := release.NewDownloader(client)
+	downloader.Attempts = settings.Retries + 1
+	downloader.Backoff = settings.Backoff
+	return downloader, nil
+}
 
-	return fmt.Errorf("binary not found in archive")
+// getDefaultInstallPath returns the default installation path for the current platform
+func getDefaultInstallPath() (string, error) {
+	return pathsetup.DefaultInstallDir()
 }
 
-// setupPath attempts to add the install path to PATH
-func setupPath(installPath string) error {
-	switch runtime.GOOS {
-	case "windows":
-		return setupWindowsPath(installPath)
-	default:
-		return setupUnixPath(installPath)
-	}
+// getBinaryName returns the binary name for the current platform
+func getBinaryName() string {
+	return pathsetup.BinaryName(runtime.GOOS)
 }
 
-// setupUnixPath adds to PATH via shell profile
-func setupUnixPath(installPath string) error {
-	homeDir, err := os.UserHomeDir()
+// setupPath adds the install path to PATH for new sessions: through the
+// shell profile on macOS and Linux, and through the user environment and
+// PowerShell profiles on Windows
+func setupPath(out io.Writer, installPath, shell string) error {
+	changes, err := pathsetup.Setup(installPath, pathsetup.Options{Shell: shell})
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(out, "🔧 %s: %s\n", change.Target, change.Description)
+	}
 	if err != nil {
 		return err
 	}
-
-	// Try to update shell profile
-	profiles := []string{
-		".bashrc",
-		".zshrc",
-		".profile",
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(out, "✓ %s is already on PATH\n", installPath)
+		return nil
 	}
-
-	pathExport := fmt.Sprintf("export PATH=\"%s:$PATH\"\n", installPath)
-
-	for _, profile := range profiles {
-		profilePath := filepath.Join(homeDir, profile)
-		if _, err := os.Stat(profilePath); err == nil {
-			return appendToFile(profilePath, pathExport)
-		}
+	if !pathsetup.OnPath(os.Getenv("PATH"), installPath) {
+		_, _ = fmt.Fprintln(out, "💡 Open a new terminal for the PATH change to take effect")
 	}
-
-	// Create .profile if none exist
-	profilePath := filepath.Join(homeDir, ".profile")
-	return appendToFile(profilePath, pathExport)
-}
-
-// setupWindowsPath adds to PATH via user environment
-func setupWindowsPath(installPath string) error {
-	// For Windows, we'd need to modify the registry or use environment commands
-	// For now, just provide instructions
-	fmt.Printf("💡 Add %s to your PATH environment variable\n", installPath)
 	return nil
 }
 
-// appendToFile appends content to a file
-func appendToFile(filename, content string) error {
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = file.Close() }()
+// runSetupPath implements the setup path command
+func runSetupPath(cmd *cobra.Command, args []string) error {
+	shell, _ := cmd.Flags().GetString("shell")
 
-	_, err = file.WriteString(content)
-	return err
+	var dir string
+	if len(args) > 0 {
+		abs, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+		}
+		dir = abs
+	} else {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the ddx executable: %w", err)
+		}
+		dir = filepath.Dir(executable)
+	}
+	return setupPath(cmd.OutOrStdout(), dir, shell)
 }
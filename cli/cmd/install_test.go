@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloadAndInstallBinary tests downloading a release archive and
+// installing the binary from it
+func TestDownloadAndInstallBinary(t *testing.T) {
+	platform := release.Platform{OS: "linux", Arch: "amd64"}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "ddx", Mode: 0755, Size: 7, Typeflag: tar.TypeReg}))
+	_, _ = tw.Write([]byte("binary\n"))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	archive := buf.Bytes()
+	hash := sha256.Sum256(archive)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/download/" + release.ChecksumsFile:
+			_, _ = fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(hash[:]), platform.ArchiveName())
+		case "/latest/download/" + platform.ArchiveName():
+			_, _ = w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := releaseClient
+	releaseClient = server.Client()
+	defer func() { releaseClient = client }()
+
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv(release.BaseURLEnvVar, server.URL)
+	env := NewTestEnvironment(t, WithGitInit(false))
+
+	output, err := env.RunCommand("download-binary", "--platform", "linux/amd64")
+	require.NoError(t, err, output)
+	saved := filepath.Join(cache, "ddx", "downloads", platform.ArchiveName())
+	assert.Contains(t, output, "Verified SHA256 "+hex.EncodeToString(hash[:]))
+	assert.Contains(t, output, "Saved "+saved)
+	assert.FileExists(t, saved+".sha256")
+
+	_, err = env.RunCommand("download-binary", "--platform", "linux/sparc")
+	assert.ErrorContains(t, err, "no ddx release is built for linux/sparc")
+
+	binDir := filepath.Join(env.Dir, "bin")
+	output, err = env.RunCommand("install-binary", "--archive", saved, "--path", binDir)
+	require.NoError(t, err, output)
+	content, err := os.ReadFile(filepath.Join(binDir, release.CurrentPlatform().BinaryName()))
+	require.NoError(t, err)
+	assert.Equal(t, "binary\n", string(content))
+
+	// An archive that changed since it was downloaded is refused
+	require.NoError(t, os.WriteFile(saved, []byte("tampered"), 0644))
+	_, err = env.RunCommand("install-binary", "--archive", saved, "--path", binDir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+// TestSetupPath tests adding a directory to PATH through the shell profile
+func TestSetupPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows updates the user PATH in the registry")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/zsh")
+	binDir := filepath.Join(home, "tools", "bin")
+
+	output, err := executeCommand(NewCommandFactory(t.TempDir()).NewRootCommand(), "setup", "path", binDir)
+	require.NoError(t, err)
+	assert.Contains(t, output, filepath.Join(home, ".zshrc"))
+
+	output, err = executeCommand(NewCommandFactory(t.TempDir()).NewRootCommand(), "setup", "path", binDir)
+	require.NoError(t, err)
+	assert.Contains(t, output, "already on PATH")
+
+	data, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), binDir))
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// runLibraryDoctor implements the library doctor command
+func (f *CommandFactory) runLibraryDoctor(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("path")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	maxSizeKB, _ := cmd.Flags().GetInt64("max-size")
+	deprecationDays, _ := cmd.Flags().GetInt("deprecation-days")
+	createIssues, _ := cmd.Flags().GetBool("create-issues")
+	repo, _ := cmd.Flags().GetString("repo")
+
+	libPath, err := resolveLibraryPath(f.WorkingDir, path)
+	if err != nil {
+		return err
+	}
+
+	opts := library.DefaultOptions()
+	opts.MaxFileSize = maxSizeKB * 1024
+	opts.DeprecationWindow = time.Duration(deprecationDays) * 24 * time.Hour
+
+	report, err := library.Inspect(libPath, opts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+	} else {
+		printLibraryReport(out, report)
+	}
+
+	if createIssues && len(report.Problems) > 0 {
+		urls, err := library.CreateIssues(report, library.GitHubIssueCreator(cmd.Context(), repo))
+		for _, url := range urls {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "📝 Created issue: %s\n", url)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(report.Problems) > 0 {
+		return NewExitError(1, fmt.Sprintf("%d library problem(s) found", len(report.Problems)))
+	}
+	return nil
+}
+
+// resolveLibraryPath returns the library to inspect: the explicit path when
+// given, otherwise the project's configured library
+func resolveLibraryPath(workingDir, path string) (string, error) {
+	if path == "" {
+		cfg, err := config.LoadWithWorkingDir(workingDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.Library != nil {
+			path = cfg.Library.Path
+		}
+	}
+	if path == "" {
+		return "", fmt.Errorf("no library path configured; use --path")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	return path, nil
+}
+
+// printLibraryReport writes the maintenance report grouped by priority
+func printLibraryReport(out io.Writer, report *library.Report) {
+	_, _ = fmt.Fprintf(out, "Library Doctor: %s\n", report.Path)
+	_, _ = fmt.Fprintf(out, "Checked %d files\n\n", report.Files)
+
+	if len(report.Problems) == 0 {
+		_, _ = fmt.Fprintln(out, "✅ No maintenance problems found")
+		return
+	}
+
+	for _, priority := range []library.Priority{library.PriorityHigh, library.PriorityMedium, library.PriorityLow} {
+		if report.Count(priority) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "%s priority (%d)\n", strings.ToUpper(priority.String()[:1])+priority.String()[1:], report.Count(priority))
+		for _, p := range report.Problems {
+			if p.Priority != priority {
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "  • [%s] %s: %s\n", p.Check, p.Path, p.Message)
+			if p.Fix != "" {
+				_, _ = fmt.Fprintf(out, "    💡 %s\n", p.Fix)
+			}
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
+	_, _ = fmt.Fprintf(out, "%d problems: %d high, %d medium, %d low\n", len(report.Problems),
+		report.Count(library.PriorityHigh), report.Count(library.PriorityMedium), report.Count(library.PriorityLow))
+}
+
+// runLibraryChangelogAssemble implements the library changelog assemble command
+func (f *CommandFactory) runLibraryChangelogAssemble(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+	dateFlag, _ := cmd.Flags().GetString("date")
+	path, _ := cmd.Flags().GetString("path")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	date := time.Now()
+	if dateFlag != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", dateFlag); err != nil {
+			return fmt.Errorf("invalid --date %q (expected YYYY-MM-DD)", dateFlag)
+		}
+	}
+	cmd.SilenceUsage = true
+
+	libPath, err := resolveLibraryPath(f.WorkingDir, path)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if dryRun {
+		fragments, err := library.LoadFragments(libPath)
+		if err != nil {
+			return err
+		}
+		if len(fragments) == 0 {
+			_, _ = fmt.Fprintln(out, "No changelog fragments to assemble")
+			return nil
+		}
+		_, _ = fmt.Fprint(out, library.ReleaseNotes(version, date, fragments))
+		return nil
+	}
+
+	fragments, err := library.AssembleChangelog(libPath, version, date)
+	if err != nil {
+		return err
+	}
+	if len(fragments) == 0 {
+		_, _ = fmt.Fprintln(out, "No changelog fragments to assemble")
+		return nil
+	}
+	unit := "fragments"
+	if len(fragments) == 1 {
+		unit = "fragment"
+	}
+	_, _ = fmt.Fprintf(out, "📰 Assembled %d %s into %s for %s\n", len(fragments), unit, library.ChangelogFile, version)
+	return nil
+}
+
+// runLibraryPin implements the library pin command
+func (f *CommandFactory) runLibraryPin(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	if strings.HasPrefix(ref, "-") || strings.ContainsAny(ref, " \t~^:?*[\\") {
+		return fmt.Errorf("invalid ref %q: give a tag or commit", ref)
+	}
+	cmd.SilenceUsage = true
+
+	repo, err := libraryRepository(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if err := configSet(f.WorkingDir, "library.repository.ref", ref, false); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📌 Pinned the library to %s\n", ref)
+	if repo.Ref != "" && repo.Ref != ref {
+		_, _ = fmt.Fprintf(statusOut(cmd), "   It was pinned to %s\n", repo.Ref)
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "💡 'ddx update' no longer follows %s; 'ddx library unpin' does again\n", repo.Branch)
+	return nil
+}
+
+// runLibraryUnpin implements the library unpin command
+func (f *CommandFactory) runLibraryUnpin(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	repo, err := libraryRepository(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if repo.Ref == "" {
+		return fmt.Errorf("the library is not pinned")
+	}
+	if err := configUnset(f.WorkingDir, "library.repository.ref", false); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Unpinned the library from %s; 'ddx update' follows %s again\n", repo.Ref, repo.Branch)
+	return nil
+}
+
+// runLibrarySources implements the library sources command
+func (f *CommandFactory) runLibrarySources(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if !isInitializedInDir(f.WorkingDir) {
+		return fmt.Errorf("not in a DDx project - run 'ddx init' first")
+	}
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	sources := library.ProjectSources(f.WorkingDir, cfg)
+
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		if sources == nil {
+			sources = library.Sources{}
+		}
+		data, err := json.MarshalIndent(sources, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode sources: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tPRIORITY\tPATH\tREPOSITORY")
+	for _, source := range sources {
+		path := source.Path
+		if !fileExists(source.Dir) {
+			path += " (missing)"
+		}
+		repo := "-"
+		if source.Repository != nil && source.Repository.URL != "" {
+			repo = source.Repository.URL + "@" + source.Repository.Revision()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", source.Name, source.Priority, path, repo)
+	}
+	return tw.Flush()
+}
+
+// runLibraryExportBundle implements the library export-bundle command
+func (f *CommandFactory) runLibraryExportBundle(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	repoURL, _ := cmd.Flags().GetString("repository")
+	branch, _ := cmd.Flags().GetString("branch")
+	since, _ := cmd.Flags().GetString("since")
+	if repoURL == "" {
+		repo, err := libraryRepository(f.WorkingDir)
+		if err != nil {
+			return fmt.Errorf("%w, or give --repository", err)
+		}
+		repoURL = repo.URL
+		if branch == "" {
+			branch = repo.Branch
+		}
+	}
+	if branch == "" {
+		branch = "main"
+	}
+
+	ctx := context.Background()
+	remote, err := resolveLibraryRemote(ctx, f.WorkingDir, repoURL)
+	if err != nil {
+		return err
+	}
+	indicator := newProgress(cmd)
+	indicator.Start(fmt.Sprintf("Fetching %s from %s", branch, repoURL))
+	refs, err := git.CreateBundle(ctx, remote.URL, branch, since, args[0])
+	indicator.Stop()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "📦 Wrote %s with %d ref(s)\n", args[0], len(refs))
+	for _, ref := range refs {
+		_, _ = fmt.Fprintf(out, "   %s  %s\n", shortCommit(ref.Commit), ref.Name)
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "💡 Carry it across and run 'ddx library import-bundle %s'\n", filepath.Base(args[0]))
+	return nil
+}
+
+// runLibraryImportBundle implements the library import-bundle command
+func (f *CommandFactory) runLibraryImportBundle(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	var repo *config.RepositoryConfig
+	if isInitializedInDir(f.WorkingDir) {
+		repo, _ = libraryRepository(f.WorkingDir)
+	}
+	var configured string
+	if repo != nil && git.IsLocalURL(repo.URL) {
+		configured = git.LocalPath(libraryURL(f.WorkingDir, repo.URL))
+	}
+
+	mirror, _ := cmd.Flags().GetString("mirror")
+	switch {
+	case mirror != "" && !filepath.IsAbs(mirror):
+		mirror = filepath.Join(f.WorkingDir, mirror)
+	case mirror == "" && configured == "":
+		return fmt.Errorf("library.repository.url is not a local mirror - give --mirror <dir>")
+	case mirror == "":
+		mirror = configured
+	}
+	if info, err := os.Stat(mirror); err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is a file, not a mirror repository - replace it with the new bundle, or give --mirror <dir>", mirror)
+	}
+
+	refs, err := git.ImportBundle(context.Background(), args[0], mirror)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "📥 Imported %d ref(s) into %s\n", len(refs), mirror)
+	for _, ref := range refs {
+		_, _ = fmt.Fprintf(out, "   %s  %s\n", shortCommit(ref.Commit), ref.Name)
+	}
+	switch {
+	case repo != nil && configured != mirror:
+		_, _ = fmt.Fprintf(statusOut(cmd), "💡 Point the project at the mirror with 'ddx config set library.repository.url %s'\n", mirror)
+	case repo != nil:
+		_, _ = fmt.Fprintln(statusOut(cmd), "💡 Run 'ddx update' to update the library from it")
+	}
+	return nil
+}
+
+// libraryRepository returns the project's library repository
+func libraryRepository(workingDir string) (*config.RepositoryConfig, error) {
+	if !isInitializedInDir(workingDir) {
+		return nil, fmt.Errorf("not in a DDx project - run 'ddx init' first")
+	}
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Library == nil || cfg.Library.Repository == nil {
+		return nil, fmt.Errorf("the project has no library repository")
+	}
+	return cfg.Library.Repository, nil
+}
+
+// sourceConfig returns a copy of the configuration whose library is the
+// named source, so update and contribute work on it as on the main library.
+// An empty name or the main library's name returns cfg itself.
+func sourceConfig(cfg *config.Config, name string) (*config.Config, error) {
+	if name == "" || name == config.MainSource {
+		return cfg, nil
+	}
+	if cfg.Library != nil {
+		for _, source := range cfg.Library.Sources {
+			if source.Name != name {
+				continue
+			}
+			if source.Repository == nil || source.Repository.URL == "" {
+				return nil, fmt.Errorf("library source %q has no repository", name)
+			}
+			scoped := *cfg
+			scoped.Library = &config.LibraryConfig{Path: source.Path, Repository: source.Repository}
+			return &scoped, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown library source %q", name)
+}
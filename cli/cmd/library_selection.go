@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/library"
+)
+
+// librarySelection returns the library.include and library.exclude
+// selection, which is empty when neither is configured
+func librarySelection(cfg *config.Config) (*library.Selection, error) {
+	if cfg == nil || cfg.Library == nil {
+		return &library.Selection{}, nil
+	}
+	return library.NewSelection(cfg.Library.Include, cfg.Library.Exclude)
+}
+
+// projectSelection returns the selection of the project in workingDir,
+// which is empty when its configuration cannot be read
+func projectSelection(workingDir string) *library.Selection {
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return &library.Selection{}
+	}
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return &library.Selection{}
+	}
+	return sel
+}
+
+// checkSelectionReferences fails when the selection would hide library
+// assets the project configuration refers to: bound personas, active
+// workflows and prompt shortcuts
+func checkSelectionReferences(cfg *config.Config, libDir string, sel *library.Selection) error {
+	if sel.IsEmpty() {
+		return nil
+	}
+
+	refs := make(map[string]string)
+	for role, value := range cfg.PersonaBindings {
+		for _, persona := range config.SplitPersonas(value) {
+			refs["personas/"+persona+".md"] = "persona_bindings." + role
+		}
+	}
+	for _, name := range cfg.Workflows.Active {
+		refs["workflows/"+name] = "workflows.active"
+	}
+	for alias, target := range cfg.Prompts {
+		if !filepath.IsAbs(target) {
+			refs[filepath.ToSlash(filepath.Clean(target))] = "prompts." + alias
+		}
+	}
+
+	var missing []string
+	for rel, source := range refs {
+		// Only assets present in the library can be hidden by the selection
+		root := filepath.Join(libDir, filepath.FromSlash(rel))
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			file, _ := filepath.Rel(libDir, path)
+			if !sel.Selected(filepath.ToSlash(file)) {
+				missing = append(missing, fmt.Sprintf("%s (%s)", rel, source))
+				return filepath.SkipAll
+			}
+			return nil
+		})
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("library.include/exclude would hide assets this project uses: %s", strings.Join(missing, ", "))
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateLibrarySelection(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	library := map[string]string{
+		".ddx/library/workflows/helix/workflow.yml":  "name: helix\n",
+		".ddx/library/workflows/kanban/workflow.yml": "name: kanban\n",
+		".ddx/library/personas/security-expert.md":   "# Security\n",
+		".ddx/library/personas/architect.md":         "# Architect\n",
+	}
+	for path, content := range library {
+		env.CreateFile(path, content)
+	}
+	base := `version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: ` + env.TestLibraryURL + `
+    branch: master
+  include: [workflows/helix, personas/security-*]
+workflows:
+  active: [helix]
+`
+
+	t.Run("refuses to drop referenced assets", func(t *testing.T) {
+		env.CreateConfig(base + "persona_bindings:\n  architect: architect\n")
+		_, err := env.RunCommand("update")
+		assert.EqualError(t, err, "library.include/exclude would hide assets this project uses: personas/architect.md (persona_bindings.architect)")
+		assert.FileExists(t, filepath.Join(env.LibraryPath, "personas", "architect.md"))
+	})
+
+	t.Run("hides unselected assets without removing them", func(t *testing.T) {
+		env.CreateConfig(base + "persona_bindings:\n  security: security-expert\n")
+		output, err := env.RunCommand("update")
+		require.NoError(t, err, output)
+		assert.FileExists(t, filepath.Join(env.LibraryPath, "personas", "architect.md"))
+		assert.FileExists(t, filepath.Join(env.LibraryPath, "workflows", "kanban", "workflow.yml"))
+
+		output, err = env.RunCommand("list", "--refresh")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "security-expert")
+		assert.Contains(t, output, "helix")
+		assert.NotContains(t, output, "architect")
+		assert.NotContains(t, output, "kanban")
+
+		output, err = env.RunCommand("workflow", "list")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "helix")
+		assert.NotContains(t, output, "kanban")
+
+		_, err = env.RunCommand("which", "personas", "architect")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryDoctor_ExitCode(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.Library("lib").
+		File("README.md", "# Library\n").
+		File("personas/README.md", "# Personas\n").
+		Persona(testutil.PersonaSpec{Name: "reviewer", Roles: []string{"code-reviewer"}, Description: "Reviews code"})
+
+	output, err := env.RunCommand("library", "doctor", "--path", "lib")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "No maintenance problems found")
+
+	env.CreateFile("lib/personas/README.md", "# Personas\n\nSee [the guide](guide.md).\n")
+	output, err = env.RunCommand("library", "doctor", "--path", "lib")
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.Code)
+	assert.Contains(t, output, "[broken-link] personas/README.md: line 3 links to guide.md")
+}
+
+func TestLibraryPin(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+`)
+	env.Library()
+
+	output, err := env.RunCommand("library", "pin", "v1.4.0")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Pinned the library to v1.4.0")
+	value, err := configGet(env.Dir, "library.repository.ref", false)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.0", value)
+
+	output, err = env.RunCommand("update")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Library is pinned to v1.4.0 - not updating past it")
+	assert.Contains(t, output, "--force")
+
+	output, err = env.RunCommand("status")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Library:      .ddx/library (not synced, pinned to v1.4.0)")
+
+	output, err = env.RunCommand("library", "unpin")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "follows main again")
+	value, err = configGet(env.Dir, "library.repository.ref", false)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	_, err = env.RunCommand("library", "unpin")
+	assert.ErrorContains(t, err, "not pinned")
+	_, err = env.RunCommand("library", "pin", "--", "-rf")
+	assert.ErrorContains(t, err, "invalid ref")
+}
+
+func TestLibrarySources(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+  sources:
+    - name: internal
+      path: .ddx/internal
+      priority: 10
+      repository:
+        url: https://github.com/acme/ddx-internal
+        ref: v2.0.0
+`)
+	env.Library().
+		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Description: "Public reviewer"}).
+		Persona(testutil.PersonaSpec{Name: "architect", Roles: []string{"architect"}})
+	env.Library(".ddx/internal").
+		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Description: "Internal reviewer"}).
+		Persona(testutil.PersonaSpec{Name: "sec-reviewer", Roles: []string{"security-analyst"}})
+
+	output, err := env.RunCommand("library", "sources")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `internal\s+10\s+\.ddx/internal\s+https://github.com/acme/ddx-internal@v2.0.0`, output)
+	assert.Regexp(t, `library\s+0\s+\.ddx/library\s+https://github.com/easel/ddx-library@main`, output)
+
+	output, err = env.RunCommand("persona", "list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "sec-reviewer")
+	assert.Contains(t, output, "architect")
+	assert.Contains(t, output, "Internal reviewer")
+	assert.NotContains(t, output, "Public reviewer")
+
+	output, err = env.RunCommand("persona", "show", "library:strict-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Public reviewer")
+	output, err = env.RunCommand("persona", "show", "internal:sec-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Source: internal")
+	_, err = env.RunCommand("persona", "show", "internal:architect")
+	assert.Error(t, err)
+
+	output, err = env.RunCommand("persona", "bind", "security-analyst", "internal:sec-reviewer")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("config", "doctor")
+	require.NoError(t, err, output)
+
+	output, err = env.RunCommand("update", "--source", "internal")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Library is pinned to v2.0.0")
+	_, err = env.RunCommand("update", "--source", "partner")
+	assert.ErrorContains(t, err, `unknown library source "partner"`)
+}
+
+func TestLibraryBundle(t *testing.T) {
+	upstream := t.TempDir()
+	runGitIn(t, upstream, "init", "-q", "-b", "main")
+	commitUpstream(t, upstream, map[string]string{"personas/reviewer.md": "# Reviewer\n"})
+	runGitIn(t, upstream, "tag", "v1.0.0")
+
+	// The connected side bundles the library, the air-gapped side imports
+	// it into a mirror kept with the project
+	connected := NewTestEnvironment(t, WithGitInit(false))
+	bundle := filepath.Join(connected.Dir, "ddx-library.bundle")
+	output, err := connected.RunCommand("library", "export-bundle", bundle, "--repository", upstream, "--branch", "main")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "with 2 ref(s)")
+	assert.Contains(t, output, "refs/tags/v1.0.0")
+
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+`)
+	env.Library()
+	_, err = env.RunCommand("library", "import-bundle", bundle)
+	assert.ErrorContains(t, err, "give --mirror")
+	output, err = env.RunCommand("library", "import-bundle", bundle, "--mirror", "mirror.git")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Imported 2 ref(s)")
+	assert.Contains(t, output, "ddx config set library.repository.url "+filepath.Join(env.Dir, "mirror.git"))
+	assert.ErrorContains(t, checkOfflineLibrary(env.Dir), "needs the network")
+
+	// A relative mirror path is taken from the project
+	output, err = env.RunCommand("config", "set", "library.repository.url", "./mirror.git")
+	require.NoError(t, err, output)
+	assert.NoError(t, checkOfflineLibrary(env.Dir))
+	output, err = env.RunCommand("update", "--check")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "personas/reviewer.md")
+
+	// Later updates only carry what the mirror lacks
+	commitUpstream(t, upstream, map[string]string{"personas/architect.md": "# Architect\n"})
+	update := filepath.Join(connected.Dir, "update.bundle")
+	output, err = connected.RunCommand("library", "export-bundle", update, "--repository", upstream, "--since", "v1.0.0")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("library", "import-bundle", update)
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Run 'ddx update'")
+	output, err = env.RunCommand("update", "--check")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "personas/architect.md")
+}
@@ -3,15 +3,20 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/easel/ddx/internal/catalog"
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/match"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +29,28 @@ type Resource struct {
 	IsDirectory bool     `json:"is_directory"`
 	Size        int64    `json:"size,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	// Modified is the latest modification time of the asset's files
+	Modified *time.Time `json:"modified,omitempty"`
+	// Source is SourceUpstream or SourceLocal, or empty when the library
+	// has no recorded upstream sync
+	Source string `json:"source,omitempty"`
+}
+
+// Asset sources reported by list
+const (
+	SourceUpstream = "upstream"
+	// SourceLocal assets exist only in the project's library, making them
+	// candidates for contribution
+	SourceLocal = "local-only"
+)
+
+// listOptions select what listResources computes beyond the index
+type listOptions struct {
+	Refresh bool
+	// Metadata adds modification times, directory sizes and sources
+	Metadata bool
+	// LocalOnly keeps only assets that are not in the upstream library
+	LocalOnly bool
 }
 
 // ListResponse represents the complete JSON response
@@ -40,6 +67,14 @@ func (f *CommandFactory) runList(cmd *cobra.Command, args []string) error {
 	filterValue, _ := cmd.Flags().GetString("filter")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	treeOutput, _ := cmd.Flags().GetBool("tree")
+	formatValue, _ := cmd.Flags().GetString("format")
+	detailed, _ := cmd.Flags().GetBool("detailed")
+	localOnly, _ := cmd.Flags().GetBool("local-only")
+	opts := listOptions{
+		Metadata:  detailed || treeOutput || jsonOutput || formatValue != "" || localOnly,
+		LocalOnly: localOnly,
+	}
+	opts.Refresh, _ = cmd.Flags().GetBool("refresh")
 
 	// Get resource type from args
 	var resourceType string
@@ -48,23 +83,39 @@ func (f *CommandFactory) runList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Call pure business logic
-	response, err := listResources(f.WorkingDir, resourceType, filterValue)
+	response, err := listResources(f.WorkingDir, resourceType, filterValue, opts)
 	if err != nil {
 		return err
 	}
+	if localOnly && !jsonOutput && formatValue == "" {
+		cmd.Println("Showing assets that exist only in this project's library, candidates for 'ddx contribute'")
+	}
 
 	// Handle output formatting
+	if formatValue != "" {
+		return renderFormat(cmd.OutOrStdout(), formatValue, response.Resources)
+	}
 	if jsonOutput {
 		return outputListJSON(cmd, response)
 	}
 	if treeOutput {
 		return outputListTree(cmd, response)
 	}
-	return outputListHuman(cmd, response, filterValue, resourceType)
+	return outputListHuman(cmd, response, filterValue, resourceType, detailed)
 }
 
-// listResources is the pure business logic function
-func listResources(workingDir, resourceType, filter string) (*ListResponse, error) {
+// listResources is the pure business logic function. Resources are read
+// from the library index, which is rebuilt first when opts.Refresh is set.
+func listResources(workingDir, resourceType, filter string, opts listOptions) (*ListResponse, error) {
+	var filterMatcher *match.Matcher
+	if filter != "" {
+		var err error
+		filterMatcher, err = match.Compile(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
 	// Load config to get library path
 	cfg, err := config.LoadWithWorkingDir(workingDir)
 	if err != nil {
@@ -75,6 +126,10 @@ func listResources(workingDir, resourceType, filter string) (*ListResponse, erro
 	if cfg.Library != nil {
 		libPath = cfg.Library.Path
 	}
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Resolve library path relative to working directory if it's relative
 	if !filepath.IsAbs(libPath) {
@@ -97,55 +152,57 @@ func listResources(workingDir, resourceType, filter string) (*ListResponse, erro
 		resourceTypes = []string{resourceType}
 	}
 
-	// Simplified: no configuration loading needed for resource discovery
+	var upstream map[string]bool
+	if opts.Metadata {
+		if rel, err := filepath.Rel(workingDir, libPath); err == nil {
+			upstream, err = git.SubtreeFiles(workingDir, filepath.ToSlash(rel))
+			if err != nil && opts.LocalOnly {
+				return nil, fmt.Errorf("cannot tell which assets are local-only: %w", err)
+			}
+		}
+	}
+
+	var idx *catalog.Index
+	if opts.Refresh {
+		idx, err = catalog.Build(libPath)
+	} else {
+		idx, err = catalog.Load(libPath)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Collect all resources
 	var allResources []Resource
 	summary := make(map[string]int)
 
 	for _, resType := range resourceTypes {
-		// Simplified: just use manual discovery (no complex filtering)
-		filteredPaths := discoverResourcesManually(libPath, resType)
-
 		var categoryResources []Resource
-		for _, itemPath := range filteredPaths {
-			// Apply additional text filter if specified
-			if filter != "" {
-				relPath := strings.TrimPrefix(itemPath, filepath.Join(libPath, resType)+"/")
-				if !strings.Contains(strings.ToLower(relPath), strings.ToLower(filter)) {
-					continue
-				}
-			}
-
-			info, err := os.Stat(itemPath)
-			if err != nil {
+		for _, entry := range idx.Entries(resType) {
+			// Hide assets outside library.include/exclude
+			if !sel.Selected(resType + "/" + entry.Name) {
 				continue
 			}
-
-			description := getResourceInfo(itemPath, &dirEntryWrapper{info})
-
-			var size int64
-			if !info.IsDir() {
-				size = info.Size()
-			}
-
-			// Get relative name for display
-			relPath := strings.TrimPrefix(itemPath, filepath.Join(libPath, resType)+"/")
-			if relPath == itemPath {
-				// Fallback to basename if prefix trimming didn't work
-				relPath = filepath.Base(itemPath)
+			// Apply additional text filter if specified
+			if filterMatcher != nil && !filterMatcher.Match(entry.Name) {
+				continue
 			}
 
 			resource := Resource{
-				Name:        relPath,
+				Name:        entry.Name,
 				Type:        resType,
-				Description: description,
-				Path:        itemPath,
-				IsDirectory: info.IsDir(),
-				Size:        size,
-				Tags:        extractTags(itemPath, &dirEntryWrapper{info}),
+				Description: entry.Description,
+				Path:        idx.EntryPath(entry),
+				IsDirectory: entry.IsDir,
+				Size:        entry.Size,
+				Tags:        entry.Tags,
+			}
+			if opts.Metadata {
+				addResourceMetadata(&resource, upstream)
+			}
+			if opts.LocalOnly && resource.Source != SourceLocal {
+				continue
 			}
-
 			categoryResources = append(categoryResources, resource)
 		}
 
@@ -163,6 +220,75 @@ func listResources(workingDir, resourceType, filter string) (*ListResponse, erro
 	}, nil
 }
 
+// addResourceMetadata sets the modification time and source of a
+// resource, and the total size of a directory. upstream holds the library
+// files at the last sync; nil leaves the source unknown.
+func addResourceMetadata(resource *Resource, upstream map[string]bool) {
+	var size int64
+	var modified time.Time
+	_ = filepath.WalkDir(resource.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+			if info.ModTime().After(modified) {
+				modified = info.ModTime()
+			}
+		}
+		return nil
+	})
+	if !modified.IsZero() {
+		resource.Modified = &modified
+	}
+	if resource.IsDirectory {
+		resource.Size = size
+	}
+	if upstream == nil {
+		return
+	}
+
+	resource.Source = SourceLocal
+	rel := resource.Type + "/" + resource.Name
+	for path := range upstream {
+		if path == rel || strings.HasPrefix(path, rel+"/") {
+			resource.Source = SourceUpstream
+			break
+		}
+	}
+}
+
+// resourceMetadata describes a resource's size, modification time and
+// source for display
+func resourceMetadata(resource Resource) string {
+	var parts []string
+	if resource.Size > 0 {
+		parts = append(parts, formatSize(resource.Size))
+	}
+	if resource.Modified != nil {
+		parts = append(parts, "modified "+resource.Modified.Local().Format("2006-01-02"))
+	}
+	if resource.Source != "" {
+		parts = append(parts, resource.Source)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// formatSize renders a byte count for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
 // Output formatting functions
 func outputListJSON(cmd *cobra.Command, response *ListResponse) error {
 	jsonData, err := json.MarshalIndent(response, "", "  ")
@@ -177,7 +303,7 @@ func outputListTree(cmd *cobra.Command, response *ListResponse) error {
 	return displayTreeOutput(cmd, response.Resources, response.Filter)
 }
 
-func outputListHuman(cmd *cobra.Command, response *ListResponse, filter, resourceType string) error {
+func outputListHuman(cmd *cobra.Command, response *ListResponse, filter, resourceType string, detailed bool) error {
 	// Human-readable output
 	if len(response.Resources) == 0 {
 		cmd.Println("📋 No DDx resources found")
@@ -229,6 +355,9 @@ func outputListHuman(cmd *cobra.Command, response *ListResponse, filter, resourc
 			if resource.Description != "" {
 				cmd.Printf(" - %s", resource.Description)
 			}
+			if detailed {
+				cmd.Print(resourceMetadata(resource))
+			}
 			cmd.Println()
 		}
 		cmd.Println()
@@ -245,155 +374,6 @@ func outputListHuman(cmd *cobra.Command, response *ListResponse, filter, resourc
 	return nil
 }
 
-// getResourceInfo returns descriptive information about a resource
-func getResourceInfo(path string, entry os.DirEntry) string {
-	// Try to read description from README or description file
-	if entry.IsDir() {
-		readmePath := filepath.Join(path, "README.md")
-		if content, err := os.ReadFile(readmePath); err == nil {
-			lines := strings.Split(string(content), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if line != "" && !strings.HasPrefix(line, "#") {
-					if len(line) > 60 {
-						return line[:60] + "..."
-					}
-					return line
-				}
-			}
-		}
-
-		// Count items in directory
-		if entries, err := os.ReadDir(path); err == nil {
-			return fmt.Sprintf("%d items", len(entries))
-		}
-	} else {
-		// For files, show size or type
-		if stat, err := entry.Info(); err == nil {
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			switch ext {
-			case ".md":
-				return "Markdown document"
-			case ".yml", ".yaml":
-				return "Configuration file"
-			case ".sh":
-				return "Shell script"
-			case ".py":
-				return "Python script"
-			case ".js":
-				return "JavaScript file"
-			case ".go":
-				return "Go source"
-			default:
-				if stat.Size() < 1024 {
-					return fmt.Sprintf("%d bytes", stat.Size())
-				} else if stat.Size() < 1024*1024 {
-					return fmt.Sprintf("%.1f KB", float64(stat.Size())/1024)
-				} else {
-					return fmt.Sprintf("%.1f MB", float64(stat.Size())/(1024*1024))
-				}
-			}
-		}
-	}
-
-	return ""
-}
-
-// extractTags extracts tags from resource metadata or filename
-func extractTags(path string, entry os.DirEntry) []string {
-	var tags []string
-
-	// Extract tags from filename patterns
-	name := strings.ToLower(entry.Name())
-
-	// Common technology tags
-	if strings.Contains(name, "react") || strings.Contains(name, "jsx") {
-		tags = append(tags, "react")
-	}
-	if strings.Contains(name, "vue") {
-		tags = append(tags, "vue")
-	}
-	if strings.Contains(name, "angular") {
-		tags = append(tags, "angular")
-	}
-	if strings.Contains(name, "nextjs") || strings.Contains(name, "next") {
-		tags = append(tags, "nextjs")
-	}
-	if strings.Contains(name, "python") || strings.Contains(name, "py") {
-		tags = append(tags, "python")
-	}
-	if strings.Contains(name, "go") || strings.Contains(name, "golang") {
-		tags = append(tags, "go")
-	}
-	if strings.Contains(name, "javascript") || strings.Contains(name, "js") {
-		tags = append(tags, "javascript")
-	}
-	if strings.Contains(name, "typescript") || strings.Contains(name, "ts") {
-		tags = append(tags, "typescript")
-	}
-	if strings.Contains(name, "docker") {
-		tags = append(tags, "docker")
-	}
-	if strings.Contains(name, "api") || strings.Contains(name, "rest") {
-		tags = append(tags, "api")
-	}
-	if strings.Contains(name, "auth") {
-		tags = append(tags, "authentication")
-	}
-	if strings.Contains(name, "test") {
-		tags = append(tags, "testing")
-	}
-	if strings.Contains(name, "claude") || strings.Contains(name, "ai") {
-		tags = append(tags, "ai")
-	}
-
-	return tags
-}
-
-// discoverResourcesManually discovers resources without filtering
-func discoverResourcesManually(libPath, resourceType string) []string {
-	var resources []string
-	resourcePath := filepath.Join(libPath, resourceType)
-
-	if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
-		return resources
-	}
-
-	// Only read the immediate children of the resource directory
-	entries, err := os.ReadDir(resourcePath)
-	if err != nil {
-		return resources
-	}
-
-	for _, entry := range entries {
-		fullPath := filepath.Join(resourcePath, entry.Name())
-		resources = append(resources, fullPath)
-	}
-
-	return resources
-}
-
-// dirEntryWrapper wraps os.FileInfo to implement os.DirEntry interface
-type dirEntryWrapper struct {
-	info os.FileInfo
-}
-
-func (d *dirEntryWrapper) Name() string {
-	return d.info.Name()
-}
-
-func (d *dirEntryWrapper) IsDir() bool {
-	return d.info.IsDir()
-}
-
-func (d *dirEntryWrapper) Type() os.FileMode {
-	return d.info.Mode().Type()
-}
-
-func (d *dirEntryWrapper) Info() (os.FileInfo, error) {
-	return d.info, nil
-}
-
 // displayTreeOutput displays resources in tree format
 func displayTreeOutput(cmd *cobra.Command, resources []Resource, filter string) error {
 	if len(resources) == 0 {
@@ -504,8 +484,11 @@ func displayTreeNodes(cmd *cobra.Command, nodes map[string]*TreeNode, prefix str
 		}
 
 		displayName := name
-		if node.Resource != nil && node.Resource.Description != "" {
-			displayName += " - " + node.Resource.Description
+		if node.Resource != nil {
+			if node.Resource.Description != "" {
+				displayName += " - " + node.Resource.Description
+			}
+			displayName += resourceMetadata(*node.Resource)
 		}
 
 		cmd.Printf("%s%s%s %s\n", prefix, connector, icon, displayName)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -135,6 +136,31 @@ library_path: ./library`)
 			},
 			expectError: false,
 		},
+		{
+			name: "list with format template",
+			args: []string{"list", "workflows", "--format", `{{.Type}}\t{{.Name}}`},
+			setup: func(t *testing.T) string {
+				testDir := t.TempDir()
+
+				workflowsDir := filepath.Join(testDir, ".ddx", "library", "workflows")
+				require.NoError(t, os.MkdirAll(filepath.Join(workflowsDir, "helix"), 0755))
+				require.NoError(t, os.MkdirAll(filepath.Join(workflowsDir, "kanban"), 0755))
+
+				return testDir
+			},
+			validate: func(t *testing.T, output string, err error) {
+				assert.Equal(t, "workflows\thelix\nworkflows\tkanban\n", output)
+			},
+			expectError: false,
+		},
+		{
+			name: "list with invalid format template",
+			args: []string{"list", "--format", "{{.Name"},
+			setup: func(t *testing.T) string {
+				return t.TempDir()
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -167,6 +193,7 @@ You can filter by type or search for specific items.`,
 			freshListCmd.Flags().StringP("filter", "f", "", "Filter resources by name")
 			freshListCmd.Flags().Bool("json", false, "Output results as JSON")
 			freshListCmd.Flags().Bool("tree", false, "Display resources in tree format")
+			freshListCmd.Flags().String("format", "", "Format each resource with a Go template")
 
 			rootCmd.AddCommand(freshListCmd)
 
@@ -211,3 +238,61 @@ func TestListCommand_Help(t *testing.T) {
 	assert.Contains(t, output, "List available")
 	assert.Contains(t, output, "filter")
 }
+
+// TestListCommand_Index tests that listing keeps the library index current
+func TestListCommand_Index(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/prompts/review.md", "# Review\n")
+
+	output, err := env.RunCommand("list", "prompts")
+	require.NoError(t, err)
+	assert.Contains(t, output, "review.md")
+	assert.FileExists(t, filepath.Join(env.Dir, ".ddx", "library", ".index.json"))
+
+	env.CreateFile(".ddx/library/prompts/triage.md", "# Triage\n")
+	output, err = env.RunCommand("list", "prompts", "--refresh")
+	require.NoError(t, err)
+	assert.Contains(t, output, "triage.md")
+}
+
+// TestListCommand_Metadata tests the size, date and source of assets and
+// the local-only filter
+func TestListCommand_Metadata(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/prompts/review.md", "# Review\n")
+	env.CreateFile(".ddx/library/templates/go-service/README.md", "# Go service\n")
+
+	_, err := env.RunCommand("list", "--local-only")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no upstream sync recorded")
+
+	// Record a sync the way git subtree does
+	runGitIn(t, env.Dir, "add", ".")
+	runGitIn(t, env.Dir, "commit", "-m", "Add library\n\ngit-subtree-dir: .ddx/library\ngit-subtree-split: 0123456789abcdef0123456789abcdef01234567")
+	env.CreateFile(".ddx/library/prompts/triage.md", "# Triage\n")
+	env.CreateFile(".ddx/library/templates/rust-cli/README.md", "# Rust CLI\n")
+
+	output, err := env.RunCommand("list", "--local-only")
+	require.NoError(t, err)
+	assert.Contains(t, output, "triage.md")
+	assert.Contains(t, output, "rust-cli")
+	assert.NotContains(t, output, "review.md")
+	assert.NotContains(t, output, "go-service")
+
+	output, err = env.RunCommand("list", "--tree")
+	require.NoError(t, err)
+	assert.Contains(t, output, "review.md - Markdown document (9 B, modified ")
+	assert.Contains(t, output, ", upstream)")
+	assert.Contains(t, output, ", local-only)")
+
+	output, err = env.RunCommand("list", "templates", "--json")
+	require.NoError(t, err)
+	var response ListResponse
+	require.NoError(t, json.Unmarshal([]byte(output), &response))
+	require.Len(t, response.Resources, 2)
+	assert.Equal(t, SourceUpstream, response.Resources[0].Source)
+	assert.Equal(t, int64(13), response.Resources[0].Size)
+	assert.NotNil(t, response.Resources[0].Modified)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/lock"
+	"github.com/spf13/cobra"
+)
+
+// projectLibraryDir returns the configured library as an absolute path
+func projectLibraryDir(workingDir string, cfg *config.Config) string {
+	libPath := cfg.Library.Path
+	if !filepath.IsAbs(libPath) {
+		libPath = filepath.Join(workingDir, libPath)
+	}
+	return libPath
+}
+
+// projectLibraryRevision identifies the library revision a project uses
+func projectLibraryRevision(workingDir string, cfg *config.Config) lock.Library {
+	library := lock.Library{Commit: git.LastSubtreeSync(workingDir, filepath.ToSlash(cfg.Library.Path))}
+	if cfg.Library.Repository != nil {
+		library.Repository = cfg.Library.Repository.URL
+		library.Branch = cfg.Library.Repository.Branch
+		library.Ref = cfg.Library.Repository.Ref
+	}
+	return library
+}
+
+// writeProjectLock records the current library in ddx.lock
+func writeProjectLock(workingDir string, cfg *config.Config) (*lock.Lock, error) {
+	l, err := lock.Generate(projectLibraryDir(workingDir, cfg), projectLibraryRevision(workingDir, cfg))
+	if err != nil {
+		return nil, err
+	}
+	return l, l.Save(workingDir)
+}
+
+// verifyProjectLock compares the library with ddx.lock, failing when there
+// is no lockfile or the library drifted from it
+func verifyProjectLock(workingDir string, cfg *config.Config) (*lock.Lock, error) {
+	l, err := lock.Load(workingDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no %s found; run 'ddx lock' to create one", lock.FileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	drift, err := l.Verify(projectLibraryDir(workingDir, cfg))
+	if err != nil {
+		return nil, err
+	}
+	if len(drift) > 0 {
+		return nil, fmt.Errorf("library does not match %s: %s", lock.FileName, lock.Summary(drift))
+	}
+	return l, nil
+}
+
+// runLock implements the lock command
+func (f *CommandFactory) runLock(cmd *cobra.Command, args []string) error {
+	check, _ := cmd.Flags().GetBool("check")
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if check {
+		l, err := verifyProjectLock(f.WorkingDir, cfg)
+		if err != nil {
+			return NewExitError(1, err.Error())
+		}
+		_, _ = fmt.Fprintf(out, "✅ Library matches %s (%d assets)\n", lock.FileName, len(l.Assets))
+		return nil
+	}
+
+	l, err := writeProjectLock(f.WorkingDir, cfg)
+	if err != nil {
+		return err
+	}
+	at := ""
+	if l.Library.Commit != "" {
+		at = " at " + shortCommit(l.Library.Commit)
+	}
+	_, _ = fmt.Fprintf(out, "🔒 Locked %d assets%s in %s\n", len(l.Assets), at, lock.FileName)
+	return nil
+}
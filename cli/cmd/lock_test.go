@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockCommand(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+	env.CreateFile(".ddx/library/workflows/helix/workflow.yml", "name: helix\n")
+
+	_, err := env.RunCommand("update", "--frozen")
+	assert.EqualError(t, err, "no ddx.lock found; run 'ddx lock' to create one")
+	assert.Equal(t, CheckSkip, checkNamed(t, diagnoseProject(env.Dir, time.Now()), "Library lock").Status)
+
+	output, err := env.RunCommand("lock")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Locked 2 assets in ddx.lock")
+	l, err := lock.Load(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, env.TestLibraryURL, l.Library.Repository)
+	assert.Contains(t, l.Assets, "personas/reviewer.md")
+
+	output, err = env.RunCommand("update", "--frozen")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Library matches ddx.lock (2 assets)")
+	assert.Equal(t, CheckPass, checkNamed(t, diagnoseProject(env.Dir, time.Now()), "Library lock").Status)
+
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Lenient Reviewer\n")
+	_, err = env.RunCommand("update", "--frozen")
+	assert.EqualError(t, err, "library does not match ddx.lock: personas/reviewer.md (modified)")
+	_, err = env.RunCommand("lock", "--check")
+	assert.ErrorContains(t, err, "personas/reviewer.md (modified)")
+	drift := checkNamed(t, diagnoseProject(env.Dir, time.Now()), "Library lock")
+	assert.Equal(t, CheckWarn, drift.Status)
+	assert.Contains(t, drift.Message, "1 library file(s) differ from ddx.lock")
+
+	// A regular update refreshes the lockfile
+	output, err = env.RunCommand("update")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Updated ddx.lock")
+	output, err = env.RunCommand("lock", "--check")
+	require.NoError(t, err, output)
+	assert.FileExists(t, filepath.Join(env.Dir, lock.FileName))
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 )
 
@@ -41,15 +42,7 @@ type LogOptions struct {
 // Remove init function - commands are now registered via command factory
 
 // CLI Interface Layer - handles UI concerns only
-func runLog(cmd *cobra.Command, args []string) error {
-	return runLogWithWorkingDir(cmd, args, "")
-}
-
 func (f *CommandFactory) runLog(cmd *cobra.Command, args []string) error {
-	return runLogWithWorkingDir(cmd, args, f.WorkingDir)
-}
-
-func runLogWithWorkingDir(cmd *cobra.Command, args []string, workingDir string) error {
 	// Extract flags - CLI interface layer responsibility
 	logLimit, _ := cmd.Flags().GetInt("number")
 	logLimitAlt, _ := cmd.Flags().GetInt("limit")
@@ -85,15 +78,15 @@ func runLogWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 
 	// Handle export functionality
 	if logExport != "" {
-		return handleLogExport(workingDir, opts)
+		return handleLogExport(f.fs(), f.now(), f.WorkingDir, opts)
 	}
 
-	return handleLogDisplay(cmd.OutOrStdout(), cmd.ErrOrStderr(), workingDir, opts)
+	return handleLogDisplay(cmd.OutOrStdout(), cmd.ErrOrStderr(), f.fs(), f.now(), f.WorkingDir, opts)
 }
 
 // CLI handlers - handle presentation and user interaction
-func handleLogDisplay(stdout, stderr io.Writer, workingDir string, opts LogOptions) error {
-	entries, err := logHistory(workingDir, opts)
+func handleLogDisplay(stdout, stderr io.Writer, fsys afero.Fs, now time.Time, workingDir string, opts LogOptions) error {
+	entries, err := logHistory(fsys, now, workingDir, opts)
 	if err != nil {
 		return err
 	}
@@ -132,18 +125,20 @@ func handleLogDisplay(stdout, stderr io.Writer, workingDir string, opts LogOptio
 	return nil
 }
 
-func handleLogExport(workingDir string, opts LogOptions) error {
-	entries, err := logHistory(workingDir, opts)
+func handleLogExport(fsys afero.Fs, now time.Time, workingDir string, opts LogOptions) error {
+	entries, err := logHistory(fsys, now, workingDir, opts)
 	if err != nil {
 		return err
 	}
 
-	return exportLogEntries(entries, opts.Export)
+	return exportLogEntries(fsys, now, entries, opts.Export)
 }
 
 // Business Logic Layer - pure functions that return data
-// logHistory returns log entries from the DDX directory
-func logHistory(workingDir string, opts LogOptions) ([]LogEntry, error) {
+// logHistory returns log entries from the DDX directory, dated now when
+// git has no history for it. git reads the disk, so its history is only
+// used when fsys is the operating system's filesystem.
+func logHistory(fsys afero.Fs, now time.Time, workingDir string, opts LogOptions) ([]LogEntry, error) {
 	// Determine DDX directory path
 	ddxDir := ".ddx"
 	if workingDir != "" {
@@ -151,22 +146,22 @@ func logHistory(workingDir string, opts LogOptions) ([]LogEntry, error) {
 	}
 
 	// Verify DDX project exists
-	if !isDDXProjectInDir(workingDir) {
-		return nil, fmt.Errorf("not a DDX project - run 'ddx init' first")
-	}
-
-	if _, err := os.Stat(ddxDir); os.IsNotExist(err) {
+	if _, err := fsys.Stat(ddxDir); err != nil {
+		if _, err := fsys.Stat(filepath.Join(workingDir, ".ddx.yml")); err != nil {
+			return nil, fmt.Errorf("not a DDX project - run 'ddx init' first")
+		}
 		return nil, fmt.Errorf("DDX directory not found - project may not be properly initialized")
 	}
 
 	// Try to get git log data first
-	entries, err := getGitLogData(ddxDir, opts)
-	if err != nil {
-		// Fallback to file-based data
-		return getFileBasedLogData(ddxDir, opts)
+	if _, onDisk := fsys.(*afero.OsFs); onDisk {
+		if entries, err := getGitLogData(ddxDir, opts); err == nil {
+			return entries, nil
+		}
 	}
 
-	return entries, nil
+	// Fallback to file-based data
+	return getFileBasedLogData(ddxDir, now, opts)
 }
 
 // isDDXProjectInDir checks if a directory contains a DDX project
@@ -265,13 +260,13 @@ func getGitDiffForCommit(ddxDir, hash string) (string, error) {
 }
 
 // getFileBasedLogData creates log entries from file modification times
-func getFileBasedLogData(ddxDir string, opts LogOptions) ([]LogEntry, error) {
+func getFileBasedLogData(ddxDir string, now time.Time, opts LogOptions) ([]LogEntry, error) {
 	entries := []LogEntry{}
 
 	// Create a simple entry based on DDX directory
 	entry := LogEntry{
 		Hash:    "file-based",
-		Date:    time.Now().Format("2006-01-02"),
+		Date:    now.Format("2006-01-02"),
 		Author:  "DDX System",
 		Message: "File-based history (git not available)",
 		Files:   []string{ddxDir},
@@ -287,7 +282,7 @@ func getFileBasedLogData(ddxDir string, opts LogOptions) ([]LogEntry, error) {
 }
 
 // exportLogEntries exports log entries to a file
-func exportLogEntries(entries []LogEntry, exportPath string) error {
+func exportLogEntries(fsys afero.Fs, now time.Time, entries []LogEntry, exportPath string) error {
 	// Determine export format from file extension
 	format := "markdown" // default
 	if strings.HasSuffix(exportPath, ".json") {
@@ -299,7 +294,7 @@ func exportLogEntries(entries []LogEntry, exportPath string) error {
 	}
 
 	// Create export file
-	file, err := os.Create(exportPath)
+	file, err := fsys.Create(exportPath)
 	if err != nil {
 		return fmt.Errorf("failed to create export file: %v", err)
 	}
@@ -308,7 +303,7 @@ func exportLogEntries(entries []LogEntry, exportPath string) error {
 	// Export in requested format
 	switch format {
 	case "json":
-		return exportJSON(file, entries)
+		return exportJSON(file, entries, now)
 	case "csv":
 		return exportCSV(file, entries)
 	case "html":
@@ -319,7 +314,7 @@ func exportLogEntries(entries []LogEntry, exportPath string) error {
 }
 
 // Export functions - handle file output formatting
-func exportMarkdown(file *os.File, entries []LogEntry) error {
+func exportMarkdown(file afero.File, entries []LogEntry) error {
 	_, err := file.WriteString("# DDX Asset History\n\n")
 	if err != nil {
 		return err
@@ -357,16 +352,16 @@ func exportMarkdown(file *os.File, entries []LogEntry) error {
 	return nil
 }
 
-func exportJSON(file *os.File, entries []LogEntry) error {
+func exportJSON(file afero.File, entries []LogEntry, now time.Time) error {
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(map[string]interface{}{
 		"ddx_history": entries,
-		"exported_at": time.Now().Format(time.RFC3339),
+		"exported_at": now.Format(time.RFC3339),
 	})
 }
 
-func exportCSV(file *os.File, entries []LogEntry) error {
+func exportCSV(file afero.File, entries []LogEntry) error {
 	_, err := file.WriteString("Hash,Date,Author,Message,Files\n")
 	if err != nil {
 		return err
@@ -384,7 +379,7 @@ func exportCSV(file *os.File, entries []LogEntry) error {
 	return nil
 }
 
-func exportHTML(file *os.File, entries []LogEntry) error {
+func exportHTML(file afero.File, entries []LogEntry) error {
 	_, err := file.WriteString(`<!DOCTYPE html>
 <html>
 <head>
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_InMemory(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/project/.ddx/config.yaml", []byte("version: \"1.0\"\n"), 0644))
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	f := &CommandFactory{WorkingDir: "/project", FS: fsys, Clock: fixedClock(now)}
+
+	var out bytes.Buffer
+	cmd := f.newLogCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Date: 2026-10-17")
+
+	cmd = f.newLogCommand()
+	cmd.SetArgs([]string{"--export", "/project/history.json"})
+	require.NoError(t, cmd.Execute())
+	data, err := afero.ReadFile(fsys, "/project/history.json")
+	require.NoError(t, err)
+	var export struct {
+		History    []LogEntry `json:"ddx_history"`
+		ExportedAt string     `json:"exported_at"`
+	}
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.Equal(t, "2026-10-17T09:30:00Z", export.ExportedAt)
+	require.Len(t, export.History, 1)
+	assert.Equal(t, "2026-10-17", export.History[0].Date)
+}
+
+// TestLog_InMemoryIgnoresDisk tests that an in-memory filesystem is not
+// bypassed by reading git history from the same path on disk
+func TestLog_InMemoryIgnoresDisk(t *testing.T) {
+	te := NewTestEnvironment(t)
+	te.CreateFile(".ddx/notes.md", "# Notes\n")
+	runGitIn(t, te.Dir, "add", ".ddx")
+	runGitIn(t, te.Dir, "commit", "--quiet", "-m", "Add notes")
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, fsys.MkdirAll(filepath.Join(te.Dir, ".ddx"), 0755))
+	f := &CommandFactory{WorkingDir: te.Dir, FS: fsys, Clock: fixedClock(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))}
+
+	var out bytes.Buffer
+	cmd := f.newLogCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "File-based history")
+	assert.NotContains(t, out.String(), "Add notes")
+}
+
+func TestLog_NotAProject(t *testing.T) {
+	f := &CommandFactory{WorkingDir: "/project", FS: afero.NewMemMapFs()}
+	cmd := f.newLogCommand()
+	cmd.SetArgs([]string{})
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a DDX project")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogLevels tests that diagnostics follow --verbose/--debug and stay
+// on stderr
+func TestLogLevels(t *testing.T) {
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	run := func(args ...string) (string, string) {
+		var stdout, stderr bytes.Buffer
+		factory := NewCommandFactory(env.Dir)
+		factory.LogOutput = &stderr
+		root := factory.NewRootCommand()
+		root.SetArgs(args)
+		root.SetOut(&stdout)
+		root.SetErr(&stderr)
+		require.NoError(t, root.Execute())
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := run("config", "get", "library.path")
+	assert.Equal(t, ".ddx/library\n", stdout)
+	assert.Empty(t, stderr)
+
+	stdout, stderr = run("config", "get", "library.path", "--verbose")
+	assert.Equal(t, ".ddx/library\n", stdout)
+	assert.Contains(t, stderr, "DDx ")
+	assert.NotContains(t, stderr, "debug:")
+
+	stdout, stderr = run("config", "get", "library.path", "--debug")
+	assert.Equal(t, ".ddx/library\n", stdout)
+	assert.Contains(t, stderr, "debug: loaded config layer source=project")
+
+	t.Setenv(logging.FormatEnvVar, "json")
+	_, stderr = run("config", "get", "library.path", "--debug")
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	require.NotEmpty(t, lines)
+	for _, line := range lines {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		assert.Contains(t, record, "level")
+	}
+}
@@ -1,14 +1,23 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/easel/ddx/internal/config"
 	"github.com/easel/ddx/internal/mcp"
+	"github.com/easel/ddx/internal/network"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // Command registration is now handled by command_factory.go
@@ -21,12 +30,21 @@ type MCPServerInfo struct {
 	Category    string
 	Installed   bool
 	Version     string
+	Author      string
+	// Install is the install method (npm, pip, docker or binary)
+	Install     string
+	Package     string
+	Environment []string
+	Platforms   []string
 }
 
-// MCPStatus represents the overall MCP status
-type MCPStatus struct {
-	InstalledServers []MCPServerInfo
-	AvailableUpdates int
+// MCPStatusOptions contains options for checking configured servers
+type MCPStatusOptions struct {
+	// ServerName limits the check to one server
+	ServerName string
+	// Check starts each server and performs an MCP handshake
+	Check      bool
+	ConfigPath string
 }
 
 // MCPListOptions contains options for listing MCP servers
@@ -35,6 +53,7 @@ type MCPListOptions struct {
 	Search     string
 	Verbose    bool
 	ConfigPath string
+	Format     string
 }
 
 // MCPInstallOptions contains options for installing MCP servers
@@ -44,6 +63,11 @@ type MCPInstallOptions struct {
 	DryRun      bool
 	Yes         bool
 	ConfigPath  string
+	Client      string
+	// NoSecretStore writes secret values to the client config
+	NoSecretStore bool
+	// Prompt asks for variables that were not provided; nil disables it
+	Prompt func(envVar mcp.EnvironmentVar) (string, error)
 }
 
 // CLI Interface Layer - handles UI concerns only
@@ -68,6 +92,7 @@ func runMCPWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 	searchFlag, _ := cmd.Flags().GetString("search")
 	verboseFlag, _ := cmd.Flags().GetBool("verbose")
 	config, _ := cmd.Flags().GetString("config-path")
+	formatFlag, _ := cmd.Flags().GetString("format")
 
 	// Handle subcommands based on arguments
 	if len(args) > 0 {
@@ -78,6 +103,7 @@ func runMCPWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 				Search:     searchFlag,
 				Verbose:    verboseFlag,
 				ConfigPath: config,
+				Format:     formatFlag,
 			}
 			return handleMCPList(cmd.OutOrStdout(), workingDir, opts)
 		case "install":
@@ -86,7 +112,14 @@ func runMCPWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 			}
 			return handleMCPInstall(cmd, args[1], workingDir)
 		case "status":
-			return handleMCPStatus(cmd.OutOrStdout(), workingDir)
+			return handleMCPStatus(cmd, workingDir, args[1:])
+		case "validate":
+			return handleMCPValidate(cmd.OutOrStdout(), workingDir)
+		case "exec":
+			if len(args) < 2 {
+				return fmt.Errorf("server command required for exec")
+			}
+			return handleMCPExec(cmd, args[1:])
 		}
 	}
 
@@ -97,6 +130,7 @@ func runMCPWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 			Search:     searchFlag,
 			Verbose:    verboseFlag,
 			ConfigPath: config,
+			Format:     formatFlag,
 		}
 		return handleMCPList(cmd.OutOrStdout(), workingDir, opts)
 	}
@@ -106,7 +140,7 @@ func runMCPWithWorkingDir(cmd *cobra.Command, args []string, workingDir string)
 	}
 
 	if statusFlag {
-		return handleMCPStatus(cmd.OutOrStdout(), workingDir)
+		return handleMCPStatus(cmd, workingDir, nil)
 	}
 
 	// Show help when no flags or args provided
@@ -120,6 +154,10 @@ func handleMCPList(output io.Writer, workingDir string, opts MCPListOptions) err
 		return err
 	}
 
+	if opts.Format != "" {
+		return renderFormat(output, opts.Format, servers)
+	}
+
 	// Present results to user
 	_, _ = fmt.Fprintln(output, "Available MCP Servers")
 	_, _ = fmt.Fprintln(output, "====================")
@@ -141,10 +179,16 @@ func handleMCPList(output io.Writer, workingDir string, opts MCPListOptions) err
 			_, _ = fmt.Fprintf(output, "%s (%s)\n", server.Name, server.Category)
 			_, _ = fmt.Fprintf(output, "  Description: %s\n", server.Description)
 			_, _ = fmt.Fprintf(output, "  Version: %s\n", server.Version)
-			// Add placeholder fields that tests expect
-			_, _ = fmt.Fprintf(output, "  Author: DDx Team\n")
-			_, _ = fmt.Fprintf(output, "  Package: @modelcontextprotocol/server-%s\n", server.Name)
-			_, _ = fmt.Fprintf(output, "  Environment: [configurable]\n")
+			_, _ = fmt.Fprintf(output, "  Author: %s\n", server.Author)
+			_, _ = fmt.Fprintf(output, "  Package: %s (%s)\n", server.Package, server.Install)
+			if len(server.Environment) > 0 {
+				_, _ = fmt.Fprintf(output, "  Environment: %s\n", strings.Join(server.Environment, ", "))
+			} else {
+				_, _ = fmt.Fprintf(output, "  Environment: none\n")
+			}
+			if len(server.Platforms) > 0 {
+				_, _ = fmt.Fprintf(output, "  Platforms: %s\n", strings.Join(server.Platforms, ", "))
+			}
 			if server.Installed {
 				_, _ = fmt.Fprintf(output, "  Status: Installed (v%s)\n", server.Version)
 			} else {
@@ -171,6 +215,8 @@ func handleMCPInstall(cmd *cobra.Command, serverName, workingDir string) error {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	yes, _ := cmd.Flags().GetBool("yes")
 	configPath, _ := cmd.Flags().GetString("config-path")
+	client, _ := cmd.Flags().GetString("client")
+	noSecretStore, _ := cmd.Flags().GetBool("no-secret-store")
 
 	// Parse environment variables
 	environment := make(map[string]string)
@@ -182,14 +228,19 @@ func handleMCPInstall(cmd *cobra.Command, serverName, workingDir string) error {
 	}
 
 	opts := MCPInstallOptions{
-		ServerName:  serverName,
-		Environment: environment,
-		DryRun:      dryRun,
-		Yes:         yes,
-		ConfigPath:  configPath,
+		ServerName:    serverName,
+		Environment:   environment,
+		DryRun:        dryRun,
+		Yes:           yes,
+		ConfigPath:    configPath,
+		Client:        client,
+		NoSecretStore: noSecretStore,
+	}
+	if !yes && !dryRun && isTerminal(cmd.InOrStdin()) {
+		opts.Prompt = mcpEnvPrompt(cmd.InOrStdin(), cmd.OutOrStdout())
 	}
 
-	err := mcpInstall(workingDir, opts)
+	err := mcpInstall(cmd.OutOrStdout(), workingDir, opts)
 	if err != nil {
 		return err
 	}
@@ -204,28 +255,171 @@ func handleMCPInstall(cmd *cobra.Command, serverName, workingDir string) error {
 	return nil
 }
 
-func handleMCPStatus(output io.Writer, workingDir string) error {
-	status, err := mcpStatus(workingDir)
+// mcpEnvPrompt returns a prompt that reads environment values from in,
+// without echoing sensitive values
+func mcpEnvPrompt(in io.Reader, out io.Writer) func(mcp.EnvironmentVar) (string, error) {
+	reader := bufio.NewReader(in)
+	return func(envVar mcp.EnvironmentVar) (string, error) {
+		label := envVar.Name
+		if envVar.Description != "" {
+			label = fmt.Sprintf("%s (%s)", envVar.Name, envVar.Description)
+		}
+		if !envVar.Required {
+			label += " [optional]"
+		}
+		_, _ = fmt.Fprintf(out, "%s: ", label)
+
+		if f, ok := in.(*os.File); ok && envVar.Sensitive {
+			value, err := term.ReadPassword(int(f.Fd()))
+			_, _ = fmt.Fprintln(out)
+			return strings.TrimSpace(string(value)), err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
+// isTerminal reports whether a command's input or output stream is an
+// interactive terminal
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// handleMCPExec runs an MCP server command with secret references in its
+// environment resolved from the credential store. Client configs written by
+// install launch servers with stored secrets through this command.
+func handleMCPExec(cmd *cobra.Command, command []string) error {
+	// The server's own output is the protocol stream; keep usage out of it
+	cmd.SilenceUsage = true
+
+	environ, err := mcp.ResolveSecretEnv(cmd.Context(), initializeAuthManager(), os.Environ())
 	if err != nil {
 		return err
 	}
 
-	// Present status to user
-	_, _ = fmt.Fprintln(output, "MCP Server Status:")
-	_, _ = fmt.Fprintln(output)
-	_, _ = fmt.Fprintln(output, "Installed servers:")
-	for _, server := range status.InstalledServers {
-		status := "running"
-		if !server.Installed {
-			status = "stopped"
+	server := exec.CommandContext(cmd.Context(), command[0], command[1:]...)
+	server.Env = environ
+	server.Stdin = cmd.InOrStdin()
+	server.Stdout = cmd.OutOrStdout()
+	server.Stderr = cmd.ErrOrStderr()
+	if err := server.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmd.SilenceErrors = true
+			return NewExitError(exitErr.ExitCode(), "")
 		}
-		_, _ = fmt.Fprintf(output, "  • %s (%s)\n", server.Name, status)
+		return fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+	return nil
+}
+
+func handleMCPStatus(cmd *cobra.Command, workingDir string, args []string) error {
+	check, _ := cmd.Flags().GetBool("check")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	configPath, _ := cmd.Flags().GetString("config-path")
+
+	opts := MCPStatusOptions{Check: check, ConfigPath: configPath}
+	if len(args) > 0 {
+		opts.ServerName = args[0]
+	}
+	results, err := mcpStatus(cmd.Context(), workingDir, opts)
+	if err != nil {
+		return err
+	}
+
+	output := cmd.OutOrStdout()
+	if jsonOutput {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		_, _ = fmt.Fprintln(output, string(data))
+	} else {
+		printMCPStatus(output, workingDir, results)
+	}
+
+	broken := 0
+	for _, result := range results {
+		if !result.Healthy() {
+			broken++
+		}
+	}
+	if broken > 0 {
+		// The report already explains the failures
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return NewExitError(1, fmt.Sprintf("%d MCP server(s) are broken", broken))
 	}
-	_, _ = fmt.Fprintln(output)
-	_, _ = fmt.Fprintf(output, "Available updates: %d\n", status.AvailableUpdates)
 	return nil
 }
 
+// printMCPStatus renders server health as a table followed by the details
+// of servers that need attention
+func printMCPStatus(output io.Writer, workingDir string, results []mcp.ServerHealth) {
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(output, "No MCP servers are configured. Install one with 'ddx mcp install <server>'.")
+		return
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVER\tSTATUS\tCONFIG\tDETAILS")
+	for _, result := range results {
+		status := string(result.Status)
+		if result.Healthy() && len(result.Warnings) > 0 {
+			status += " (warnings)"
+		}
+		details := result.Command
+		if result.Handshake != nil {
+			details = fmt.Sprintf("%s %s (protocol %s, %dms)", result.Handshake.ServerName,
+				result.Handshake.ServerVersion, result.Handshake.ProtocolVersion, result.Handshake.DurationMs)
+		}
+		config := result.Config
+		if rel, err := filepath.Rel(workingDir, config); err == nil && !strings.HasPrefix(rel, "..") {
+			config = rel
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, status, config, details)
+	}
+	_ = w.Flush()
+
+	for _, result := range results {
+		if len(result.Problems) == 0 && len(result.Warnings) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(output, "\n%s:\n", result.Name)
+		for _, problem := range result.Problems {
+			_, _ = fmt.Fprintf(output, "  ✗ %s\n", problem)
+		}
+		for _, warning := range result.Warnings {
+			_, _ = fmt.Fprintf(output, "  ! %s\n", warning)
+		}
+		for _, remediation := range result.Remediation {
+			_, _ = fmt.Fprintf(output, "  → %s\n", remediation)
+		}
+	}
+}
+
+func handleMCPValidate(output io.Writer, workingDir string) error {
+	count, problems, err := mcpValidate(workingDir)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		_, _ = fmt.Fprintf(output, "✅ Registry is valid (%d servers)\n", count)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(output, "❌ Registry has %d problem(s):\n", len(problems))
+	for _, problem := range problems {
+		_, _ = fmt.Fprintf(output, "  • %v\n", problem)
+	}
+	return NewExitError(1, fmt.Sprintf("%d invalid MCP server definition(s)", len(problems)))
+}
+
 // Business Logic Layer - pure functions that return data
 // mcpList returns a list of MCP servers based on the given options
 func mcpList(workingDir string, opts MCPListOptions) ([]MCPServerInfo, error) {
@@ -250,56 +444,88 @@ func mcpList(workingDir string, opts MCPListOptions) ([]MCPServerInfo, error) {
 	claude := mcp.NewClaudeWrapper()
 	registry.SetClaudeWrapper(claude)
 
-	// Convert to business logic options
-	_ = mcp.ListOptions{
-		Category:   opts.Category,
-		Search:     opts.Search,
-		Verbose:    opts.Verbose,
-		Available:  true,
-		ConfigPath: opts.ConfigPath,
-	}
-
-	// Get server list from registry (this would need to be modified to return data instead of writing)
-	// For now, we'll simulate the data structure
-	servers := []MCPServerInfo{
-		{
-			Name:        "filesystem",
-			Description: "File system operations",
-			Category:    "storage",
-			Installed:   true,
-			Version:     "1.0.0",
-		},
-		{
-			Name:        "github",
-			Description: "GitHub integration",
-			Category:    "development",
-			Installed:   false,
-			Version:     "",
-		},
-	}
-
-	// Apply search and category filters
-	filteredServers := []MCPServerInfo{}
-	for _, server := range servers {
-		// Apply category filter
-		if opts.Category != "" && server.Category != opts.Category {
-			continue
-		}
+	refs, err := registry.ListServers(mcp.ListOptions{
+		Category: opts.Category,
+		Search:   opts.Search,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Apply search filter
-		if opts.Search != "" && !strings.Contains(strings.ToLower(server.Name), strings.ToLower(opts.Search)) &&
-			!strings.Contains(strings.ToLower(server.Description), strings.ToLower(opts.Search)) {
-			continue
+	installed := registry.InstalledServers(mcpConfigPaths(workingDir, opts.ConfigPath)...)
+
+	servers := make([]MCPServerInfo, 0, len(refs))
+	for _, ref := range refs {
+		info := MCPServerInfo{
+			Name:        ref.Name,
+			Description: ref.Description,
+			Category:    ref.Category,
+			Installed:   installed[ref.Name],
+		}
+		// Details come from the server definition; an unreadable definition
+		// still lists with its registry summary
+		if server, err := registry.GetServer(ref.Name); err == nil {
+			install := server.InstallMethod()
+			info.Version = server.Version
+			info.Author = server.Author
+			info.Install = install.Method
+			info.Package = install.Package
+			info.Platforms = server.Compatibility.Platforms
+			for _, env := range server.Environment {
+				info.Environment = append(info.Environment, env.Name)
+			}
 		}
+		servers = append(servers, info)
+	}
+
+	return servers, nil
+}
 
-		filteredServers = append(filteredServers, server)
+// mcpConfigPaths returns the Claude config files checked for installed
+// servers: the explicit path when given, otherwise the project's files and
+// the Claude Desktop config
+func mcpConfigPaths(workingDir, configPath string) []string {
+	if configPath != "" {
+		return []string{configPath}
 	}
+	paths := []string{
+		filepath.Join(workingDir, mcp.ProjectConfigFile),
+		filepath.Join(workingDir, ".claude", "settings.local.json"),
+	}
+	if desktop, err := mcp.ClientConfigPath(mcp.ClaudeDesktop, workingDir); err == nil {
+		paths = append(paths, desktop)
+	}
+	return paths
+}
 
-	return filteredServers, nil
+// mcpValidate checks every server definition in the library registry and
+// returns the number of servers checked along with any problems found
+func mcpValidate(workingDir string) (int, []error, error) {
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var libPath string
+	if cfg.Library != nil {
+		libPath = cfg.Library.Path
+	}
+
+	registry, err := mcp.LoadRegistryWithLibraryPath("", workingDir, libPath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+
+	return len(registry.Servers), registry.Check(), nil
 }
 
 // mcpInstall installs an MCP server with the given options
-func mcpInstall(workingDir string, opts MCPInstallOptions) error {
+func mcpInstall(output io.Writer, workingDir string, opts MCPInstallOptions) error {
+	clients, err := mcp.ParseClients(opts.Client)
+	if err != nil {
+		return err
+	}
+
 	// Load config to get library path
 	cfg, err := config.LoadWithWorkingDir(workingDir)
 	if err != nil {
@@ -307,7 +533,7 @@ func mcpInstall(workingDir string, opts MCPInstallOptions) error {
 	}
 
 	// Create installer with proper working directory context
-	installer := mcp.NewInstaller()
+	installer := mcp.NewInstallerWithWriter(output)
 
 	// Convert to internal options
 	mcpOpts := mcp.InstallOptions{
@@ -315,6 +541,11 @@ func mcpInstall(workingDir string, opts MCPInstallOptions) error {
 		DryRun:      opts.DryRun,
 		Yes:         opts.Yes,
 		ConfigPath:  opts.ConfigPath,
+		Clients:     clients,
+		Prompt:      opts.Prompt,
+	}
+	if !opts.NoSecretStore {
+		mcpOpts.Secrets = initializeAuthManager()
 	}
 
 	var libPath string
@@ -324,30 +555,33 @@ func mcpInstall(workingDir string, opts MCPInstallOptions) error {
 	return installer.InstallWithLibraryPath(opts.ServerName, mcpOpts, libPath, workingDir)
 }
 
-// mcpStatus returns the current status of MCP servers
-func mcpStatus(workingDir string) (*MCPStatus, error) {
-	// Use workingDir-based paths
-	_ = ""
-	if workingDir != "" {
-		_ = filepath.Join(workingDir, ".ddx")
+// mcpStatus checks the health of the configured MCP servers
+func mcpStatus(ctx context.Context, workingDir string, opts MCPStatusOptions) ([]mcp.ServerHealth, error) {
+	servers, err := mcp.ConfiguredServers(mcpConfigPaths(workingDir, opts.ConfigPath)...)
+	if err != nil {
+		return nil, err
 	}
 
-	// Load installed servers info
-	// This would normally query the actual installed servers
-	installedServers := []MCPServerInfo{
-		{
-			Name:        "filesystem",
-			Description: "File system operations",
-			Category:    "storage",
-			Installed:   true,
-			Version:     "1.0.0",
-		},
+	if opts.ServerName != "" {
+		var matched []mcp.ConfiguredServer
+		for _, server := range servers {
+			if server.Name == opts.ServerName {
+				matched = append(matched, server)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("MCP server %q is not configured", opts.ServerName)
+		}
+		servers = matched
 	}
 
-	status := &MCPStatus{
-		InstalledServers: installedServers,
-		AvailableUpdates: 0,
+	health := mcp.HealthOptions{
+		Handshake: opts.Check,
+		Secrets:   initializeAuthManager(),
 	}
-
-	return status, nil
+	// A configured timeout replaces the handshake's own default
+	if settings := network.Current(); settings.TimeoutSet {
+		health.Timeout = settings.Timeout
+	}
+	return mcp.NewHealthChecker(health).CheckAll(ctx, servers), nil
 }
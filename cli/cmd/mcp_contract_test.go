@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/easel/ddx/internal/exitcodes"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -201,7 +202,7 @@ func TestMCPListCommand_Contract(t *testing.T) {
 		if err != nil {
 			var exitErr *ExitError
 			if errors.As(err, &exitErr) {
-				assert.Equal(t, 0, exitErr.Code, "Should exit with code 0 on success")
+				assert.Equal(t, exitcodes.Success, exitErr.Code, "Should exit with code 0 on success")
 			}
 		}
 	})
@@ -314,7 +315,7 @@ func TestMCPInstallCommand_Contract(t *testing.T) {
 		if err != nil {
 			var exitErr *ExitError
 			if errors.As(err, &exitErr) {
-				assert.Equal(t, 0, exitErr.Code, "Should exit 0 on success")
+				assert.Equal(t, exitcodes.Success, exitErr.Code, "Should exit 0 on success")
 			}
 		}
 	})
@@ -334,7 +335,7 @@ func TestMCPInstallCommand_Contract(t *testing.T) {
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if errors.As(err, &exitErr) {
-			assert.Equal(t, 6, exitErr.Code, "Should exit 6 when not found")
+			assert.Equal(t, exitcodes.NotFound, exitErr.Code, "Should exit 6 when not found")
 		}
 	})
 
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/spf13/cobra"
+)
+
+// metaPromptKey is the configuration key selecting the meta-prompt
+const metaPromptKey = "system.meta_prompt"
+
+// libraryMetaPrompts lists the meta-prompts in the project's library
+func libraryMetaPrompts(workingDir string, cfg *config.Config) ([]metaprompt.MetaPrompt, error) {
+	return metaprompt.Available(filepath.Join(projectLibraryDir(workingDir, cfg), "prompts"))
+}
+
+// runMetaPromptList lists the library's meta-prompts, marking the one in use
+func (f *CommandFactory) runMetaPromptList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	prompts, err := libraryMetaPrompts(f.WorkingDir, cfg)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	current := cfg.GetMetaPrompt()
+	if len(prompts) == 0 {
+		_, _ = fmt.Fprintln(out, "No meta-prompts found in the library")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, p := range prompts {
+			mark := " "
+			if p.Path == current {
+				mark = "*"
+			}
+			_, _ = fmt.Fprintf(w, "%s %s\t%s\t%s\n", mark, p.Name, p.Path, p.Title)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintln(out)
+	switch _, err := metaprompt.Find(prompts, current); {
+	case current == "":
+		_, _ = fmt.Fprintln(out, "Meta-prompt injection is disabled. Enable it with: ddx metaprompt set <name>")
+	case err != nil:
+		_, _ = fmt.Fprintf(out, "⚠️  The configured meta-prompt %s is not in the library\n", current)
+	default:
+		_, _ = fmt.Fprintf(out, "In use: %s\n", current)
+	}
+	return nil
+}
+
+// runMetaPromptSet selects a library meta-prompt and injects it into the
+// AI target files
+func (f *CommandFactory) runMetaPromptSet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	prompts, err := libraryMetaPrompts(f.WorkingDir, cfg)
+	if err != nil {
+		return err
+	}
+	selected, err := metaprompt.Find(prompts, args[0])
+	if err != nil {
+		return NewExitError(1, err.Error()+"; run 'ddx metaprompt list' to see the available meta-prompts")
+	}
+
+	files, err := f.applyMetaPrompt(selected.Path)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Meta-prompt set to %s and synced to %s\n", selected.Path, strings.Join(files, ", "))
+	return nil
+}
+
+// runMetaPromptDisable turns meta-prompt injection off and removes the
+// injected section
+func (f *CommandFactory) runMetaPromptDisable(cmd *cobra.Command, args []string) error {
+	files, err := f.applyMetaPrompt("")
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Meta-prompt disabled and removed from %s\n", strings.Join(files, ", "))
+	return nil
+}
+
+// applyMetaPrompt records the meta-prompt in the project config, empty to
+// disable it, and re-syncs the AI target files it returns
+func (f *CommandFactory) applyMetaPrompt(path string) ([]string, error) {
+	if err := configSet(f.WorkingDir, metaPromptKey, path, false); err != nil {
+		return nil, fmt.Errorf("failed to update config: %w", err)
+	}
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := syncMetaPromptWithConfig(cfg, f.WorkingDir); err != nil {
+		return nil, fmt.Errorf("failed to sync meta-prompt: %w", err)
+	}
+	targetList, _, err := aiTargets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return targets.Files(targetList), nil
+}
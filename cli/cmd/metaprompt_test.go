@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaPromptCommands(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+`)
+	env.CreateFile(".ddx/library/prompts/claude/system-prompts/focused.md", "# Focused\n\nStay focused.\n")
+	env.CreateFile(".ddx/library/prompts/claude/system-prompts/strict.md", "# Strict Quality\n\nBe strict.\n")
+	read := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, file))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	output, err := env.RunCommand("metaprompt", "list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "* focused  claude/system-prompts/focused.md  Focused")
+	assert.Contains(t, output, "  strict   claude/system-prompts/strict.md   Strict Quality")
+	assert.Contains(t, output, "In use: claude/system-prompts/focused.md")
+
+	output, err = env.RunCommand("metaprompt", "set", "strict")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Meta-prompt set to claude/system-prompts/strict.md and synced to CLAUDE.md")
+	assert.Contains(t, read("CLAUDE.md"), "Be strict.")
+	cfg, err := config.LoadWithWorkingDir(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, "claude/system-prompts/strict.md", cfg.GetMetaPrompt())
+
+	_, err = env.RunCommand("metaprompt", "set", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in the library")
+
+	output, err = env.RunCommand("metaprompt", "disable")
+	require.NoError(t, err, output)
+	assert.NotContains(t, read("CLAUDE.md"), "DDX-META-PROMPT")
+	cfg, err = config.LoadWithWorkingDir(env.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.GetMetaPrompt())
+
+	output, err = env.RunCommand("metaprompt", "list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Meta-prompt injection is disabled")
+}
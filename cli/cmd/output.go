@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// quiet reports whether a command leaves out decorative output: banners,
+// progress, hints, success messages and the update notification. Data,
+// warnings and errors are always written. --quiet, DDX_QUIET and a
+// command's own --silent turn it on.
+func quiet(cmd *cobra.Command) bool {
+	if q, _ := cmd.Flags().GetBool("quiet"); q {
+		return true
+	}
+	if silent, _ := cmd.Flags().GetBool("silent"); silent {
+		return true
+	}
+	return os.Getenv("DDX_QUIET") != ""
+}
+
+// statusOut returns where a command writes decorative output: its stdout,
+// or nowhere when it is quiet
+func statusOut(cmd *cobra.Command) io.Writer {
+	if quiet(cmd) {
+		return io.Discard
+	}
+	return cmd.OutOrStdout()
+}
+
+// noColor reports whether output is left uncolored: with --no-color, or
+// when NO_COLOR or DDX_NO_COLOR is set to any value. cmd may be nil before
+// flags are parsed.
+func noColor(cmd *cobra.Command) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("DDX_NO_COLOR") != "" {
+		return true
+	}
+	if cmd == nil {
+		return false
+	}
+	off, _ := cmd.Flags().GetBool("no-color")
+	return off
+}
+
+// configureColor turns color off for the rest of the process when the
+// flags or environment ask for it. Output that is not a terminal is never
+// colored.
+func configureColor(cmd *cobra.Command) {
+	if noColor(cmd) {
+		color.NoColor = true
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuiet(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	output, err := env.RunCommand("config", "set", "library.path", "./lib")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✅ Set library.path = ./lib")
+
+	output, err = env.RunCommand("--quiet", "config", "set", "library.path", "./library")
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(output))
+
+	output, err = env.RunCommand("-q", "config", "get", "library.path")
+	require.NoError(t, err)
+	assert.Equal(t, "./library", strings.TrimSpace(output), "data is still written")
+
+	t.Setenv("DDX_QUIET", "1")
+	output, err = env.RunCommand("config", "unset", "library.path")
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(output))
+}
+
+func TestNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("DDX_NO_COLOR", "")
+	saved := color.NoColor
+	t.Cleanup(func() { color.NoColor = saved })
+
+	assert.False(t, noColor(nil))
+	t.Setenv("DDX_NO_COLOR", "1")
+	assert.True(t, noColor(nil))
+	t.Setenv("DDX_NO_COLOR", "")
+	t.Setenv("NO_COLOR", "1")
+	assert.True(t, noColor(nil))
+	t.Setenv("NO_COLOR", "")
+
+	color.NoColor = false
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	_, err := env.RunCommand("--no-color", "config", "get", "library.path")
+	require.NoError(t, err)
+	assert.True(t, color.NoColor)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/patterns"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// runPatternsList lists the library's patterns, marking those applied to
+// the project
+func (f *CommandFactory) runPatternsList(cmd *cobra.Command, args []string) error {
+	r, err := projectResolver(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	layers, err := r.Layers("patterns")
+	if err != nil {
+		return err
+	}
+	state, err := patterns.LoadState(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		available, err := patterns.Available(layer.Dir)
+		if err != nil {
+			return err
+		}
+		for _, p := range available {
+			if seen[p.Name] {
+				continue
+			}
+			seen[p.Name] = true
+			mark := " "
+			if state.Find(p.Name) != nil {
+				mark = "✓"
+			}
+			_, _ = fmt.Fprintf(out, "%s %-24s %s\n", mark, p.Name, p.Manifest.Description)
+		}
+	}
+	if len(seen) == 0 {
+		_, _ = fmt.Fprintln(out, "No patterns found in the library")
+	}
+
+	// Patterns removed from the library can still be removed from the project
+	for _, applied := range state.Applied {
+		if !seen[applied.Name] {
+			_, _ = fmt.Fprintf(out, "✓ %-24s (no longer in the library)\n", applied.Name)
+		}
+	}
+	return nil
+}
+
+// runPatternApply applies a library pattern to the project
+func (f *CommandFactory) runPatternApply(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	pairs, _ := cmd.Flags().GetStringArray("var")
+	noRecord, _ := cmd.Flags().GetBool("no-record")
+
+	provided, err := variables.ParseAssignments(pairs)
+	if err != nil {
+		return err
+	}
+	r, err := projectResolver(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	match, err := r.Resolve("patterns", name)
+	if err != nil {
+		return err
+	}
+	if !match.Found() || filepath.Base(match.Path) == "README.md" {
+		return fmt.Errorf("pattern not found: %s", name)
+	}
+
+	registry, err := variables.Load(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	result, err := patterns.Apply(match.Path, name, f.WorkingDir, registry.Merge(provided))
+	if err != nil {
+		return err
+	}
+	if !noRecord && registry.Record(provided) {
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "✅ Applied pattern '%s'\n", name)
+	for _, path := range result.Created {
+		_, _ = fmt.Fprintf(out, "  created   %s\n", path)
+	}
+	for _, path := range result.Modified {
+		_, _ = fmt.Fprintf(out, "  modified  %s\n", path)
+	}
+	for _, path := range result.Skipped {
+		_, _ = fmt.Fprintf(out, "  skipped   %s (already exists)\n", path)
+	}
+	return nil
+}
+
+// runPatternRemove removes an applied pattern from the project
+func (f *CommandFactory) runPatternRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cmd.SilenceUsage = true
+	result, err := patterns.Remove(f.WorkingDir, name)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "✅ Removed pattern '%s'\n", name)
+	for _, path := range result.Deleted {
+		_, _ = fmt.Fprintf(out, "  deleted   %s\n", path)
+	}
+	for _, path := range result.Modified {
+		_, _ = fmt.Fprintf(out, "  modified  %s\n", path)
+	}
+	for _, path := range result.Kept {
+		_, _ = fmt.Fprintf(out, "  kept      %s (edited since the pattern was applied)\n", path)
+	}
+	return nil
+}
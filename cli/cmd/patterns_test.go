@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternsCommand(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/patterns/health-check/pattern.yml", `name: health-check
+description: Liveness endpoint
+inserts:
+  - file: main.go
+    anchor: routes
+    content: mux.HandleFunc("{{route}}", health.Handler)
+`)
+	env.CreateFile(".ddx/library/patterns/health-check/files/health/handler.go", "package health\n")
+	env.CreateFile("main.go", "package main\n\nfunc routes() {\n\t// ddx:anchor routes\n}\n")
+
+	output, err := env.RunCommand("patterns", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "  health-check")
+	assert.Contains(t, output, "Liveness endpoint")
+
+	output, err = env.RunCommand("pattern", "apply", "health-check", "--var", "route=/healthz", "--no-record")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Applied pattern 'health-check'")
+	assert.Contains(t, output, "created   health/handler.go")
+	assert.Contains(t, output, "modified  main.go")
+	main, err := os.ReadFile(filepath.Join(env.Dir, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), "\t// ddx:pattern health-check begin\n\tmux.HandleFunc(\"/healthz\", health.Handler)\n")
+
+	output, err = env.RunCommand("patterns", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ health-check")
+
+	output, err = env.RunCommand("patterns", "remove", "health-check")
+	require.NoError(t, err)
+	assert.Contains(t, output, "deleted   health/handler.go")
+	main, err = os.ReadFile(filepath.Join(env.Dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc routes() {\n\t// ddx:anchor routes\n}\n", string(main))
+
+	_, err = env.RunCommand("patterns", "apply", "missing")
+	assert.ErrorContains(t, err, "pattern not found: missing")
+}
@@ -1,13 +1,28 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/catalog"
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/library"
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/easel/ddx/internal/transaction"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -25,8 +40,17 @@ type PersonaInfo struct {
 	Tags        []string
 	Content     string
 	FilePath    string
+	// Source is PersonaSourceProject or PersonaSourceLibrary
+	Source string
 }
 
+// Persona sources. Project personas in .ddx/personas take precedence over
+// library personas of the same name.
+const (
+	PersonaSourceProject = "project"
+	PersonaSourceLibrary = "library"
+)
+
 // PersonaMetadata represents parsed persona frontmatter
 type PersonaMetadata struct {
 	Name        string   `yaml:"name"`
@@ -44,6 +68,8 @@ type PersonaStatus struct {
 	LoadedRoles    []string
 	BindingsCount  int
 	HasCLAUDEFile  bool
+	// File is the AI target file the status was read from
+	File string
 }
 
 // =============================================================================
@@ -67,44 +93,59 @@ func runPersonaWithWorkingDir(cmd *cobra.Command, args []string, workingDir stri
 	showFlag, _ := cmd.Flags().GetString("show")
 	bindFlag, _ := cmd.Flags().GetString("bind")
 	roleFlag, _ := cmd.Flags().GetString("role")
-	roleFilter, _ := cmd.Flags().GetString("role")
-	tagFilter, _ := cmd.Flags().GetString("tag")
 
 	// Handle subcommands
 	if len(args) > 0 {
 		switch args[0] {
 		case "list":
-			personas, err := personaList(workingDir, roleFilter, tagFilter)
-			if err != nil {
-				return err
-			}
-			return displayPersonaList(cmd, personas)
+			return listPersonas(cmd, workingDir)
 		case "show":
 			if len(args) < 2 {
 				return fmt.Errorf("persona name required")
 			}
-			persona, err := personaShow(workingDir, args[1])
-			if err != nil {
-				return err
-			}
-			return displayPersona(cmd, persona)
+			return showPersona(cmd, workingDir, args[1])
 		case "bind":
+			if file, _ := cmd.Flags().GetString("file"); file != "" {
+				if len(args) > 1 {
+					return fmt.Errorf("roles and personas come from --file; pass no arguments with it")
+				}
+				return personaBindFile(cmd, workingDir, file)
+			}
 			if len(args) < 3 {
 				return fmt.Errorf("role and persona name required")
 			}
-			err := personaBind(workingDir, args[1], args[2])
+			return bindPersona(cmd, workingDir, args[1], args[2])
+		case "diff":
+			if len(args) > 2 {
+				return fmt.Errorf("at most one persona name can be compared")
+			}
+			diffs, err := personaDiff(workingDir, strings.Join(args[1:], ""))
 			if err != nil {
 				return err
 			}
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Bound role '%s' to persona '%s'\n", args[1], args[2])
+			displayPersonaDiffs(cmd, diffs, "injected personas match the library")
 			return nil
 		case "load":
-			loadedPersonas, err := personaLoad(workingDir, args[1:]...)
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				diffs, err := previewPersonaLoad(workingDir, args[1:]...)
+				if err != nil {
+					return err
+				}
+				displayPersonaDiffs(cmd, diffs, "no changes")
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "🔍 Dry run: no files were written")
+				return nil
+			}
+			loadedPersonas, files, err := personaLoad(workingDir, args[1:]...)
 			if err != nil {
 				return err
 			}
-			return displayLoadResult(cmd, args[1:], loadedPersonas)
+			return displayLoadResult(cmd, args[1:], loadedPersonas, files)
+		case "roles":
+			return runPersonaRoles(cmd, workingDir)
 		case "bindings":
+			if role, _ := cmd.Flags().GetString("unbind"); role != "" {
+				return unbindPersona(cmd, workingDir, role)
+			}
 			bindings, err := personaBindings(workingDir)
 			if err != nil {
 				return err
@@ -115,40 +156,65 @@ func runPersonaWithWorkingDir(cmd *cobra.Command, args []string, workingDir stri
 			if err != nil {
 				return err
 			}
-			return displayPersonaStatus(cmd, status)
+			if err := displayPersonaStatus(cmd, status); err != nil {
+				return err
+			}
+			if stats, _ := cmd.Flags().GetBool("stats"); stats {
+				usage, err := personaUsage(workingDir)
+				if err != nil {
+					return err
+				}
+				displayPersonaUsage(cmd, usage)
+			}
+			return nil
 		}
 	}
 
 	// Handle flags
 	if listFlag {
-		personas, err := personaList(workingDir, roleFilter, tagFilter)
-		if err != nil {
-			return err
-		}
-		return displayPersonaList(cmd, personas)
+		return listPersonas(cmd, workingDir)
 	}
 
 	if showFlag != "" {
-		persona, err := personaShow(workingDir, showFlag)
-		if err != nil {
-			return err
-		}
-		return displayPersona(cmd, persona)
+		return showPersona(cmd, workingDir, showFlag)
 	}
 
 	if bindFlag != "" && roleFlag != "" {
-		err := personaBind(workingDir, roleFlag, bindFlag)
-		if err != nil {
-			return err
-		}
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Bound role '%s' to persona '%s'\n", roleFlag, bindFlag)
-		return nil
+		return bindPersona(cmd, workingDir, roleFlag, bindFlag)
 	}
 
 	// Show help when no flags or args provided
 	return cmd.Help()
 }
 
+// listPersonas lists personas matching the --role and --tag filters
+func listPersonas(cmd *cobra.Command, workingDir string) error {
+	roleFilter, _ := cmd.Flags().GetString("role")
+	tagFilter, _ := cmd.Flags().GetString("tag")
+	formatFlag, _ := cmd.Flags().GetString("format")
+	if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+		sources, err := personaSources(workingDir)
+		if err != nil {
+			return fmt.Errorf("failed to get library path: %w", err)
+		}
+		for _, source := range sources {
+			if _, err := catalog.Build(source.Dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	personas, err := personaList(workingDir, roleFilter, tagFilter)
+	if err != nil {
+		return err
+	}
+	if formatFlag != "" {
+		loadPersonaContent(personas)
+		return renderFormat(cmd.OutOrStdout(), formatFlag, personas)
+	}
+	return displayPersonaList(cmd, personas)
+}
+
 // displayPersonaList displays the list of personas to the user
 func displayPersonaList(cmd *cobra.Command, personas []PersonaInfo) error {
 	if len(personas) == 0 {
@@ -161,21 +227,86 @@ func displayPersonaList(cmd *cobra.Command, personas []PersonaInfo) error {
 
 	// Create tabwriter for aligned output
 	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "PERSONA\tROLE\tDESCRIPTION")
-	_, _ = fmt.Fprintln(w, "-------\t----\t-----------")
+	_, _ = fmt.Fprintln(w, "PERSONA\tROLE\tSOURCE\tDESCRIPTION")
+	_, _ = fmt.Fprintln(w, "-------\t----\t------\t-----------")
 
 	for _, persona := range personas {
 		roleStr := "general"
 		if len(persona.Roles) > 0 {
 			roleStr = persona.Roles[0]
 		}
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", persona.Name, roleStr, persona.Description)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", persona.Name, roleStr, persona.Source, persona.Description)
 	}
 
 	_ = w.Flush()
 	return nil
 }
 
+// showPersona displays a library persona or, given "-", a persona read
+// from standard input, which is validated but not written anywhere. With
+// --raw only the persona body is printed, for piping into other tools.
+func showPersona(cmd *cobra.Command, workingDir, name string) error {
+	var info *PersonaInfo
+	var err error
+	if name == "-" {
+		info, err = personaFromReader(cmd.InOrStdin())
+	} else {
+		info, err = personaShow(workingDir, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if raw, _ := cmd.Flags().GetBool("raw"); raw {
+		body := personaBody(info.Content)
+		if !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), body)
+		return nil
+	}
+	if name == "-" {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "✅ Persona %s is valid\n", info.Name)
+	}
+	return displayPersona(cmd, info)
+}
+
+// personaFromReader reads and validates a persona that is not in the
+// library
+func personaFromReader(r io.Reader) (*PersonaInfo, error) {
+	content, err := io.ReadAll(io.LimitReader(r, persona.MaxPersonaFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read persona from stdin: %w", err)
+	}
+	p, err := persona.Parse(content)
+	if err != nil {
+		return nil, NewExitError(1, fmt.Sprintf("invalid persona on stdin: %v", err))
+	}
+	return &PersonaInfo{
+		Name:        p.Name,
+		Roles:       p.Roles,
+		Description: p.Description,
+		Tags:        p.Tags,
+		Content:     string(content),
+		FilePath:    "-",
+	}, nil
+}
+
+// personaBody returns a persona without its frontmatter and the blank
+// lines following it
+func personaBody(content string) string {
+	if parsePersonaMetadata(content) == nil {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			return strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+		}
+	}
+	return content
+}
+
 // displayPersona displays a single persona to the user
 func displayPersona(cmd *cobra.Command, persona *PersonaInfo) error {
 	if persona == nil {
@@ -190,6 +321,9 @@ func displayPersona(cmd *cobra.Command, persona *PersonaInfo) error {
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Roles: %s\n", strings.Join(metadata.Roles, ", "))
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Description: %s\n", metadata.Description)
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Tags: %s\n", strings.Join(metadata.Tags, ", "))
+		if persona.Source != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Source: %s (%s)\n", persona.Source, persona.FilePath)
+		}
 
 		// Display content after frontmatter
 		lines := strings.Split(persona.Content, "\n")
@@ -239,7 +373,7 @@ func displayBindings(cmd *cobra.Command, bindings PersonaBindings) error {
 // displayPersonaStatus displays persona status to the user
 func displayPersonaStatus(cmd *cobra.Command, status PersonaStatus) error {
 	if !status.HasCLAUDEFile {
-		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No CLAUDE.md file found - no personas loaded")
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No %s file found - no personas loaded\n", status.File)
 		return nil
 	}
 
@@ -260,19 +394,20 @@ func displayPersonaStatus(cmd *cobra.Command, status PersonaStatus) error {
 }
 
 // displayLoadResult displays the result of loading personas
-func displayLoadResult(cmd *cobra.Command, requestedPersonas []string, loadedPersonas []string) error {
+func displayLoadResult(cmd *cobra.Command, requestedPersonas []string, loadedPersonas []string, files []string) error {
+	into := strings.Join(files, ", ")
 	if len(requestedPersonas) > 0 {
 		// Specific personas loaded
 		if len(loadedPersonas) == 1 {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Loaded persona '%s' into CLAUDE.md\n", loadedPersonas[0])
+			_, _ = fmt.Fprintf(statusOut(cmd), "✅ Loaded persona '%s' into %s\n", loadedPersonas[0], into)
 		} else {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Loaded %d personas into CLAUDE.md\n", len(loadedPersonas))
+			_, _ = fmt.Fprintf(statusOut(cmd), "✅ Loaded %d personas into %s\n", len(loadedPersonas), into)
 		}
 	} else {
 		// All bound personas loaded
 		if len(loadedPersonas) > 0 {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✅ Loaded %d personas (%s) into CLAUDE.md\n",
-				len(loadedPersonas), strings.Join(loadedPersonas, ", "))
+			_, _ = fmt.Fprintf(statusOut(cmd), "✅ Loaded %d personas (%s) into %s\n",
+				len(loadedPersonas), strings.Join(loadedPersonas, ", "), into)
 		} else {
 			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No bound personas to load")
 		}
@@ -284,107 +419,190 @@ func displayLoadResult(cmd *cobra.Command, requestedPersonas []string, loadedPer
 // Business Logic Layer - Pure functions that operate on working directory
 // =============================================================================
 
-// personaList returns a list of available personas
+// personaList returns a list of available personas, read from the library
+// index and merged with the project's .ddx/personas, whose personas replace
+// library personas of the same name. Content is not loaded; see
+// loadPersonaContent.
 func personaList(workingDir string, roleFilter, tagFilter string) ([]PersonaInfo, error) {
-	// Get library path
-	libPath, err := getPersonaLibraryPath(workingDir)
+	sources, err := personaSources(workingDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get library path: %w", err)
 	}
 
-	personasDir := filepath.Join(libPath, "personas")
+	// Sources come highest priority first, so the first persona with a
+	// name is the one the name resolves to
+	personas := []PersonaInfo{}
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if _, err := os.Stat(source.Dir); err != nil && source.Name != config.MainSource {
+			continue
+		}
+		idx, err := catalog.Load(source.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range idx.Entries("personas") {
+			if entry.IsDir || !strings.HasSuffix(entry.Name, ".md") || !source.Selection.Selected("personas/"+entry.Name) {
+				continue
+			}
+
+			name := strings.TrimSuffix(entry.Name, ".md")
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			metadata := entry.Persona
+			if metadata == nil {
+				// Fallback to simple metadata
+				metadata = &catalog.PersonaMeta{
+					Name:        name,
+					Roles:       []string{"general"},
+					Description: name,
+				}
+			}
 
-	// Check if personas directory exists
-	if _, err := os.Stat(personasDir); os.IsNotExist(err) {
-		return []PersonaInfo{}, nil
+			personas = append(personas, PersonaInfo{
+				Name:        name,
+				Roles:       metadata.Roles,
+				Description: metadata.Description,
+				Tags:        metadata.Tags,
+				FilePath:    idx.EntryPath(entry),
+				Source:      personaSourceLabel(source),
+			})
+		}
 	}
 
-	// Read personas directory
-	entries, err := os.ReadDir(personasDir)
+	project, err := projectPersonas(workingDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read personas directory: %w", err)
+		return nil, err
 	}
+	for _, info := range project {
+		i := slices.IndexFunc(personas, func(p PersonaInfo) bool { return p.Name == info.Name })
+		if i < 0 {
+			personas = append(personas, info)
+			continue
+		}
+		personas[i] = info
+	}
+	sort.Slice(personas, func(i, j int) bool { return personas[i].Name < personas[j].Name })
 
-	var personas []PersonaInfo
+	// Apply role and tag filters
+	return slices.DeleteFunc(personas, func(p PersonaInfo) bool {
+		return roleFilter != "" && !slices.Contains(p.Roles, roleFilter) ||
+			tagFilter != "" && !slices.Contains(p.Tags, tagFilter)
+	}), nil
+}
+
+// projectPersonasDir is where a project keeps its own personas
+func projectPersonasDir(workingDir string) string {
+	return filepath.Join(workingDir, ".ddx", "personas")
+}
+
+// projectPersonas reads the personas in the project's .ddx/personas. A
+// project without the directory has none.
+func projectPersonas(workingDir string) ([]PersonaInfo, error) {
+	dir := projectPersonasDir(workingDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project personas: %w", err)
+	}
 
+	var personas []PersonaInfo
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") || strings.EqualFold(entry.Name(), "README.md") {
 			continue
 		}
-
 		name := strings.TrimSuffix(entry.Name(), ".md")
-		filePath := filepath.Join(personasDir, entry.Name())
-
-		// Read and parse persona file
-		content, err := os.ReadFile(filePath)
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to read persona: %w", err)
 		}
+		info := personaInfo(name, path, string(content))
+		info.Source = PersonaSourceProject
+		info.Content = ""
+		personas = append(personas, *info)
+	}
+	return personas, nil
+}
 
-		// Parse frontmatter
-		metadata := parsePersonaMetadata(string(content))
-		if metadata == nil {
-			// Fallback to simple parsing
-			metadata = &PersonaMetadata{
-				Name:        name,
-				Roles:       []string{"general"},
-				Description: name,
-			}
-		}
+// findPersona returns the file a persona name refers to and its source:
+// the project's .ddx/personas when the persona is there, otherwise the
+// highest priority library that has it. A namespaced name such as
+// internal:sec-reviewer is looked up in that library source only. A path
+// is returned whether or not the file exists.
+func findPersona(workingDir, personaName string) (string, string, error) {
+	sources, err := personaSources(workingDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get library path: %w", err)
+	}
+	return resolvePersona(workingDir, sources, personaName)
+}
 
-		// Apply role filter
-		if roleFilter != "" {
-			hasRole := false
-			for _, role := range metadata.Roles {
-				if role == roleFilter {
-					hasRole = true
-					break
-				}
-			}
-			if !hasRole {
-				continue
-			}
+// resolvePersona finds a persona like findPersona in the given libraries
+func resolvePersona(workingDir string, sources library.Sources, personaName string) (string, string, error) {
+	namespace, name := library.SplitRef(personaName)
+	if namespace == "" {
+		projectPath := filepath.Join(projectPersonasDir(workingDir), personaName+".md")
+		if _, err := os.Stat(projectPath); err == nil {
+			return projectPath, PersonaSourceProject, nil
 		}
+	}
+	ref := "personas/" + name + ".md"
+	if namespace != "" {
+		ref = namespace + ":" + ref
+	}
+	source, path, err := sources.Find(ref)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", "", err
+	}
+	return path, personaSourceLabel(source), nil
+}
 
-		// Apply tag filter
-		if tagFilter != "" {
-			hasTag := false
-			for _, tag := range metadata.Tags {
-				if tag == tagFilter {
-					hasTag = true
-					break
-				}
-			}
-			if !hasTag {
-				continue
-			}
-		}
+// personaSources returns the libraries personas are found in, highest
+// priority first
+func personaSources(workingDir string) (library.Sources, error) {
+	if workingDir == "" {
+		return nil, fmt.Errorf("working directory must be provided")
+	}
+	cfg, err := loadPersonaConfig(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Library == nil {
+		return nil, fmt.Errorf("library path not configured")
+	}
+	return library.ProjectSources(workingDir, cfg), nil
+}
 
-		// Create persona info
-		personaInfo := PersonaInfo{
-			Name:        name,
-			Roles:       metadata.Roles,
-			Description: metadata.Description,
-			Tags:        metadata.Tags,
-			Content:     string(content),
-			FilePath:    filePath,
-		}
-		personas = append(personas, personaInfo)
+// personaSourceLabel names a library source in persona listings: library
+// for the main library, otherwise the source's namespace
+func personaSourceLabel(source library.Source) string {
+	if source.Name == config.MainSource {
+		return PersonaSourceLibrary
 	}
+	return source.Name
+}
 
-	return personas, nil
+// loadPersonaContent reads the content of listed personas
+func loadPersonaContent(personas []PersonaInfo) {
+	for i := range personas {
+		if content, err := os.ReadFile(personas[i].FilePath); err == nil {
+			personas[i].Content = string(content)
+		}
+	}
 }
 
 // personaShow returns detailed information about a specific persona
 func personaShow(workingDir string, personaName string) (*PersonaInfo, error) {
-	// Get library path
-	libPath, err := getPersonaLibraryPath(workingDir)
+	personaPath, source, err := findPersona(workingDir, personaName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get library path: %w", err)
+		return nil, err
 	}
 
-	personaPath := filepath.Join(libPath, "personas", personaName+".md")
-
 	// Check if persona exists
 	if _, err := os.Stat(personaPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("persona '%s' not found", personaName)
@@ -396,8 +614,15 @@ func personaShow(workingDir string, personaName string) (*PersonaInfo, error) {
 		return nil, fmt.Errorf("failed to read persona: %w", err)
 	}
 
-	// Parse metadata
-	metadata := parsePersonaMetadata(string(content))
+	info := personaInfo(personaName, personaPath, string(content))
+	info.Source = source
+	return info, nil
+}
+
+// personaInfo describes the persona file at path from its frontmatter,
+// falling back to simple metadata
+func personaInfo(personaName, path, content string) *PersonaInfo {
+	metadata := parsePersonaMetadata(content)
 	if metadata == nil {
 		metadata = &PersonaMetadata{
 			Name:        personaName,
@@ -411,57 +636,55 @@ func personaShow(workingDir string, personaName string) (*PersonaInfo, error) {
 		Roles:       metadata.Roles,
 		Description: metadata.Description,
 		Tags:        metadata.Tags,
-		Content:     string(content),
-		FilePath:    personaPath,
-	}, nil
+		Content:     content,
+		FilePath:    path,
+	}
 }
 
-// personaBind binds a role to a persona
-func personaBind(workingDir string, role, personaName string) error {
+// personaBind binds a role to a persona. With add, the persona joins the
+// personas already bound to the role instead of replacing them.
+func personaBind(workingDir string, role, personaName string, add bool) error {
 	// Check if persona exists first
-	libPath, err := getPersonaLibraryPath(workingDir)
+	personaPath, _, err := findPersona(workingDir, personaName)
 	if err != nil {
-		return fmt.Errorf("failed to get library path: %w", err)
+		return err
 	}
-
-	personaPath := filepath.Join(libPath, "personas", personaName+".md")
 	if _, err := os.Stat(personaPath); os.IsNotExist(err) {
 		return fmt.Errorf("persona '%s' not found at path %s", personaName, personaPath)
 	}
 
 	// Load only the local config file to preserve structure
-	configPath := ".ddx/config.yaml"
-	if workingDir != "" {
-		configPath = filepath.Join(workingDir, ".ddx/config.yaml")
-	}
-
-	// Read current config as raw YAML node to preserve structure
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
-	}
-
-	var rootNode yaml.Node
-	if err := yaml.Unmarshal(data, &rootNode); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	// Find or create persona_bindings section
-	if err := addPersonaBindingToNode(&rootNode, role, personaName); err != nil {
-		return fmt.Errorf("failed to add persona binding: %w", err)
-	}
-
-	// Write back to file
-	newData, err := yaml.Marshal(&rootNode)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
+	configPath := personaConfigPath(workingDir)
+	return filelock.With(configPath, func() error {
+		return bindPersonaLocked(workingDir, configPath, role, personaName, add)
+	})
+}
 
-	if err := os.WriteFile(configPath, newData, 0644); err != nil {
-		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+// bindPersonaLocked updates the persona bindings in the config file while
+// the caller holds its lock
+func bindPersonaLocked(workingDir, configPath, role, personaName string, add bool) error {
+	personaNames := []string{personaName}
+	if add {
+		cfg, err := loadPersonaConfig(workingDir)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		bound := config.SplitPersonas(cfg.PersonaBindings[role])
+		for _, name := range bound {
+			if name == personaName {
+				return fmt.Errorf("persona '%s' is already bound to role '%s'", personaName, role)
+			}
+		}
+		personaNames = append(bound, personaName)
 	}
 
-	return nil
+	// Edit the raw YAML node to preserve the file's structure
+	return editPersonaConfig(configPath, func(rootNode *yaml.Node) error {
+		if err := addPersonaBindingToNode(rootNode, role, personaNames); err != nil {
+			return fmt.Errorf("failed to add persona binding: %w", err)
+		}
+		return nil
+	})
 }
 
 // personaBindings returns the current persona bindings
@@ -489,51 +712,47 @@ func personaBindings(workingDir string) (PersonaBindings, error) {
 	return PersonaBindings(cfg.PersonaBindings), nil
 }
 
-// personaStatus returns the status of active personas
+// personaStatus returns the status of active personas, read from the first
+// configured AI target
 func personaStatus(workingDir string) (PersonaStatus, error) {
-	claudePath := "CLAUDE.md"
-	if workingDir != "" {
-		claudePath = filepath.Join(workingDir, "CLAUDE.md")
-	}
-
 	status := PersonaStatus{
 		HasCLAUDEFile: false,
+		File:          "CLAUDE.md",
 	}
+	if cfg, err := loadPersonaConfig(workingDir); err == nil {
+		if targetList, _, err := aiTargets(cfg); err == nil && len(targetList) > 0 {
+			status.File = targetList[0].File
+		}
+	}
+	claudePath := filepath.Join(workingDir, status.File)
 
-	// Check if CLAUDE.md exists
+	// Check if the target file exists
 	if _, err := os.Stat(claudePath); os.IsNotExist(err) {
 		return status, nil
 	}
 
 	status.HasCLAUDEFile = true
 
-	// Read CLAUDE.md
+	// Read the target file
 	content, err := os.ReadFile(claudePath)
 	if err != nil {
-		return status, fmt.Errorf("failed to read CLAUDE.md: %w", err)
+		return status, fmt.Errorf("failed to read %s: %w", status.File, err)
 	}
 
 	// Parse loaded personas
-	claudeStr := string(content)
-	if strings.Contains(claudeStr, "<!-- PERSONAS:START -->") &&
-		strings.Contains(claudeStr, "<!-- PERSONAS:END -->") {
-		// Extract persona section
-		startIdx := strings.Index(claudeStr, "<!-- PERSONAS:START -->")
-		endIdx := strings.Index(claudeStr, "<!-- PERSONAS:END -->")
-		if startIdx != -1 && endIdx != -1 && endIdx > startIdx {
-			personaSection := claudeStr[startIdx:endIdx]
-
-			// Parse loaded personas
-			lines := strings.Split(personaSection, "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "### ") {
-					// Parse role and persona from header
-					header := strings.TrimPrefix(line, "### ")
-					parts := strings.Split(header, ": ")
-					if len(parts) == 2 {
-						status.LoadedRoles = append(status.LoadedRoles, parts[0])
-						status.LoadedPersonas = append(status.LoadedPersonas, parts[1])
-					}
+	doc, err := sections.Parse(string(content))
+	if err != nil {
+		return status, fmt.Errorf("%s: %w", status.File, err)
+	}
+	if section, found := doc.Get(persona.PersonasSection); found {
+		for _, line := range strings.Split(section.Content, "\n") {
+			if strings.HasPrefix(line, "### ") {
+				// Parse role and persona from header
+				header := strings.TrimPrefix(line, "### ")
+				parts := strings.Split(header, ": ")
+				if len(parts) == 2 {
+					status.LoadedRoles = append(status.LoadedRoles, parts[0])
+					status.LoadedPersonas = append(status.LoadedPersonas, parts[1])
 				}
 			}
 		}
@@ -548,8 +767,21 @@ func personaStatus(workingDir string) (PersonaStatus, error) {
 	return status, nil
 }
 
-// personaLoad loads personas into CLAUDE.md
-func personaLoad(workingDir string, personas ...string) ([]string, error) {
+// personaLoadPlan is the personas section content a load would write
+type personaLoadPlan struct {
+	Section string
+	Loaded  []string
+	// Blocks maps each loaded persona to its text in the section
+	Blocks map[string]string
+	// Paths maps each loaded persona to the file it was read from
+	Paths map[string]string
+	// Files are the AI target files, relative to the project
+	Files []string
+}
+
+// planPersonaLoad builds the personas section for the named personas, or
+// for every bound persona in role order, without writing anything
+func planPersonaLoad(workingDir string, personas ...string) (*personaLoadPlan, error) {
 	// Always check if config file exists (new format)
 	configPath := ".ddx/config.yaml"
 	if workingDir != "" {
@@ -564,95 +796,160 @@ func personaLoad(workingDir string, personas ...string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
-
-	// Get library path
-	libPath, err := getPersonaLibraryPath(workingDir)
+	targetList, disabled, err := aiTargets(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get library path: %w", err)
-	}
-
-	// Read CLAUDE.md if it exists
-	claudePath := "CLAUDE.md"
-	if workingDir != "" {
-		claudePath = filepath.Join(workingDir, "CLAUDE.md")
+		return nil, err
 	}
-
-	var claudeContent string
-	if data, err := os.ReadFile(claudePath); err == nil {
-		claudeContent = string(data)
-	} else {
-		// Create new CLAUDE.md
-		claudeContent = "# CLAUDE.md\n\nProject guidance for my application."
-	}
-
-	// Remove existing persona section if present
-	startMarker := "<!-- PERSONAS:START -->"
-	endMarker := "<!-- PERSONAS:END -->"
-	startIdx := strings.Index(claudeContent, startMarker)
-	if startIdx != -1 {
-		endIdx := strings.Index(claudeContent, endMarker)
-		if endIdx != -1 {
-			claudeContent = claudeContent[:startIdx] + claudeContent[endIdx+len(endMarker):]
-		}
+	if len(targetList) == 0 {
+		return nil, errInjectionDisabled(disabled)
 	}
 
 	// Build persona content
+	plan := &personaLoadPlan{Loaded: []string{}, Blocks: map[string]string{}, Paths: map[string]string{}}
 	var personaSection strings.Builder
-	personaSection.WriteString("\n" + startMarker + "\n")
 	personaSection.WriteString("## Active Personas\n\n")
-
-	// Track loaded personas
-	loadedPersonas := []string{}
+	add := func(name, block string) {
+		personaSection.WriteString(block)
+		plan.Blocks[name] = block
+		plan.Loaded = append(plan.Loaded, name)
+	}
 
 	// If specific personas requested, load those; otherwise load all bound personas
 	if len(personas) > 0 {
 		// Load specific personas
 		for _, personaName := range personas {
-			personaPath := filepath.Join(libPath, "personas", personaName+".md")
+			personaPath, _, err := findPersona(workingDir, personaName)
+			if err != nil {
+				return nil, err
+			}
 			if content, err := os.ReadFile(personaPath); err == nil {
 				// Validate persona content if it has frontmatter
 				if err := validatePersonaContent(string(content), personaName); err != nil {
 					return nil, err
 				}
 				// Just add the content - personas have their own titles
-				personaSection.WriteString(string(content) + "\n")
-				loadedPersonas = append(loadedPersonas, personaName)
+				add(personaName, string(content)+"\n")
+				plan.Paths[personaName] = personaPath
 			} else if os.IsNotExist(err) {
 				return nil, fmt.Errorf("persona '%s' not found", personaName)
 			}
 		}
 	} else {
-		// Load all bound personas from config
-		if cfg.PersonaBindings != nil {
-			for role, personaName := range cfg.PersonaBindings {
-				personaPath := filepath.Join(libPath, "personas", personaName+".md")
-				if content, err := os.ReadFile(personaPath); err == nil {
-					// Validate persona content if it has frontmatter
-					if err := validatePersonaContent(string(content), personaName); err != nil {
-						return nil, err
-					}
-					// Add role header with proper capitalization
-					caser := cases.Title(language.English)
-					capitalizedRole := caser.String(strings.ReplaceAll(role, "-", " "))
-					personaSection.WriteString(fmt.Sprintf("### %s: %s\n", capitalizedRole, personaName))
-					personaSection.WriteString(string(content) + "\n")
-					loadedPersonas = append(loadedPersonas, personaName)
+		// Load all bound personas from config, in role order so the
+		// section only changes when the personas do
+		roles := make([]string, 0, len(cfg.PersonaBindings))
+		for role := range cfg.PersonaBindings {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+		caser := cases.Title(language.English)
+		// declared maps each persona's frontmatter name to its file so two
+		// personas cannot share a name in the loaded section
+		declared := map[string]string{}
+		for _, role := range roles {
+			capitalizedRole := caser.String(strings.ReplaceAll(role, "-", " "))
+			seen := map[string]bool{}
+			for _, personaName := range config.SplitPersonas(cfg.PersonaBindings[role]) {
+				if seen[personaName] {
+					return nil, fmt.Errorf("role '%s' lists persona '%s' more than once", role, personaName)
+				}
+				seen[personaName] = true
+				personaPath, _, err := findPersona(workingDir, personaName)
+				if err != nil {
+					return nil, err
+				}
+				content, err := os.ReadFile(personaPath)
+				if err != nil {
+					continue
+				}
+				if err := validatePersonaContent(string(content), personaName); err != nil {
+					return nil, err
+				}
+				name := personaName
+				if metadata := parsePersonaMetadata(string(content)); metadata != nil && metadata.Name != "" {
+					name = metadata.Name
+				}
+				if other, ok := declared[name]; ok && other != personaName {
+					return nil, fmt.Errorf("personas '%s' and '%s' are both named '%s'; rename one before loading them together", other, personaName, name)
 				}
+				declared[name] = personaName
+				// Each persona gets its own header, in the order the role lists them
+				block := fmt.Sprintf("### %s: %s\n", capitalizedRole, personaName) + string(content) + "\n"
+				if _, loaded := plan.Blocks[personaName]; loaded {
+					// A persona bound to several roles appears under each
+					personaSection.WriteString(block)
+					plan.Blocks[personaName] += block
+					continue
+				}
+				add(personaName, block)
+				plan.Paths[personaName] = personaPath
 			}
 		}
 	}
 
-	personaSection.WriteString(endMarker + "\n")
+	plan.Section = strings.Trim(personaSection.String(), "\n") + "\n"
+	for _, target := range targetList {
+		plan.Files = append(plan.Files, target.File)
+	}
+	return plan, nil
+}
 
-	// Append persona section to CLAUDE.md
-	claudeContent += personaSection.String()
+// personaLoad loads personas into each configured AI target file, returning
+// the loaded personas and the files written
+func personaLoad(workingDir string, personas ...string) ([]string, []string, error) {
+	plan, err := planPersonaLoad(workingDir, personas...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := backupAIFiles(workingDir, "persona load", plan.Files); err != nil {
+		return nil, nil, err
+	}
 
-	// Write updated CLAUDE.md
-	if err := os.WriteFile(claudePath, []byte(claudeContent), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write CLAUDE.md: %w", err)
+	// The target files are locked together, in a fixed order, and written
+	// as one transaction so a failure leaves every one of them unchanged
+	files := slices.Sorted(slices.Values(plan.Files))
+	for _, file := range files {
+		lock, err := filelock.Acquire(filepath.Join(workingDir, file))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() { _ = lock.Release() }()
+	}
+	err = transaction.Run(workingDir, "persona load", func(tx *transaction.Tx) error {
+		for _, file := range files {
+			content, err := personaSectionContent(filepath.Join(workingDir, file), plan.Section)
+			if err != nil {
+				return err
+			}
+			if err := tx.WriteFile(filepath.Join(workingDir, file), []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, transactionError(err)
+	}
+	if err := persona.RecordLoads(workingDir, plan.Paths, time.Now()); err != nil {
+		slog.Debug("could not record persona usage", "error", err)
 	}
+	return plan.Loaded, plan.Files, nil
+}
 
-	return loadedPersonas, nil
+// personaSectionContent returns a target file with its personas section
+// replaced in place, starting from the target header when the file does
+// not exist
+func personaSectionContent(path, section string) (string, error) {
+	content := targets.Header(path)
+	if data, err := os.ReadFile(path); err == nil {
+		content = string(data)
+	}
+	doc, err := sections.Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	doc.Set(sections.Section{Name: persona.PersonasSection, Order: sections.DefaultOrder, Content: section})
+	return doc.String(), nil
 }
 
 // =============================================================================
@@ -759,11 +1056,11 @@ func savePersonaConfig(workingDir string, cfg *config.Config) error {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	return atomicfile.WriteFile(configPath, data, 0644)
 }
 
 // addPersonaBindingToNode adds or updates a persona binding in a YAML node tree
-func addPersonaBindingToNode(rootNode *yaml.Node, role, personaName string) error {
+func addPersonaBindingToNode(rootNode *yaml.Node, role string, personaNames []string) error {
 	// Find the document node
 	var docNode *yaml.Node
 	if rootNode.Kind == yaml.DocumentNode && len(rootNode.Content) > 0 {
@@ -789,6 +1086,10 @@ func addPersonaBindingToNode(rootNode *yaml.Node, role, personaName string) erro
 				valueNode.Kind = yaml.MappingNode
 				valueNode.Content = []*yaml.Node{}
 			}
+			if len(valueNode.Content) == 0 {
+				// An empty "{}" mapping is written out in block style
+				valueNode.Style = 0
+			}
 
 			// Look for existing role or add new one
 			found := false
@@ -796,7 +1097,7 @@ func addPersonaBindingToNode(rootNode *yaml.Node, role, personaName string) erro
 				roleKeyNode := valueNode.Content[j]
 				if roleKeyNode.Value == role {
 					// Update existing role
-					valueNode.Content[j+1].Value = personaName
+					valueNode.Content[j+1] = personaBindingNode(personaNames)
 					found = true
 					break
 				}
@@ -808,11 +1109,7 @@ func addPersonaBindingToNode(rootNode *yaml.Node, role, personaName string) erro
 					Kind:  yaml.ScalarNode,
 					Value: role,
 				}
-				personaValueNode := &yaml.Node{
-					Kind:  yaml.ScalarNode,
-					Value: personaName,
-				}
-				valueNode.Content = append(valueNode.Content, roleKeyNode, personaValueNode)
+				valueNode.Content = append(valueNode.Content, roleKeyNode, personaBindingNode(personaNames))
 			}
 			return nil
 		}
@@ -828,17 +1125,25 @@ func addPersonaBindingToNode(rootNode *yaml.Node, role, personaName string) erro
 		Kind:  yaml.ScalarNode,
 		Value: role,
 	}
-	personaValueNode := &yaml.Node{
-		Kind:  yaml.ScalarNode,
-		Value: personaName,
-	}
-
 	bindingsValueNode := &yaml.Node{
 		Kind:    yaml.MappingNode,
-		Content: []*yaml.Node{roleKeyNode, personaValueNode},
+		Content: []*yaml.Node{roleKeyNode, personaBindingNode(personaNames)},
 	}
 
 	docNode.Content = append(docNode.Content, bindingsKeyNode, bindingsValueNode)
 
 	return nil
 }
+
+// personaBindingNode returns the YAML value for a role's personas: a
+// single name, or a list when the role has several
+func personaBindingNode(personaNames []string) *yaml.Node {
+	if len(personaNames) == 1 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: personaNames[0]}
+	}
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, name := range personaNames {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name})
+	}
+	return node
+}
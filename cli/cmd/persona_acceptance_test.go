@@ -250,8 +250,8 @@ You think in terms of system boundaries, data flow, and long-term evolution.
 				claudeStr := string(content)
 
 				// Should contain persona markers
-				assert.Contains(t, claudeStr, "<!-- PERSONAS:START -->")
-				assert.Contains(t, claudeStr, "<!-- PERSONAS:END -->")
+				assert.Contains(t, claudeStr, `<!-- DDX:START name="personas"`)
+				assert.Contains(t, claudeStr, `<!-- DDX:END name="personas" -->`)
 
 				// Should contain all three personas
 				assert.Contains(t, claudeStr, "Strict Code Reviewer")
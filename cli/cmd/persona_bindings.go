@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// personaConfigPath returns the project configuration file bindings are
+// written to
+func personaConfigPath(workingDir string) string {
+	if workingDir == "" {
+		return ".ddx/config.yaml"
+	}
+	return filepath.Join(workingDir, ".ddx/config.yaml")
+}
+
+// editPersonaConfig applies edit to the YAML tree of the project
+// configuration and writes it back, keeping the file's structure. The
+// caller holds the config file's lock.
+func editPersonaConfig(configPath string, edit func(rootNode *yaml.Node) error) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	var rootNode yaml.Node
+	if err := yaml.Unmarshal(data, &rootNode); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := edit(&rootNode); err != nil {
+		return err
+	}
+
+	newData, err := yaml.Marshal(&rootNode)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := atomicfile.WriteFile(configPath, newData, 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// removePersonaBindingFromNode removes a role from persona_bindings in a
+// YAML node tree, returning the personas it was bound to, or nil when the
+// role is not bound there
+func removePersonaBindingFromNode(rootNode *yaml.Node, role string) ([]string, error) {
+	docNode := rootNode
+	if rootNode.Kind == yaml.DocumentNode && len(rootNode.Content) > 0 {
+		docNode = rootNode.Content[0]
+	}
+	if docNode.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("root node is not a mapping")
+	}
+
+	for i := 0; i+1 < len(docNode.Content); i += 2 {
+		if docNode.Content[i].Value != "persona_bindings" {
+			continue
+		}
+		valueNode := docNode.Content[i+1]
+		if valueNode.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		for j := 0; j+1 < len(valueNode.Content); j += 2 {
+			if valueNode.Content[j].Value != role {
+				continue
+			}
+			var bindings config.PersonaBindings
+			wrapper := &yaml.Node{Kind: yaml.MappingNode, Content: valueNode.Content[j : j+2]}
+			if err := wrapper.Decode(&bindings); err != nil {
+				return nil, err
+			}
+			valueNode.Content = append(valueNode.Content[:j], valueNode.Content[j+2:]...)
+			return config.SplitPersonas(bindings[role]), nil
+		}
+	}
+	return nil, nil
+}
+
+// personaUnbind removes a role's binding from .ddx/config.yaml, returning
+// the personas it was bound to
+func personaUnbind(workingDir, role string) ([]string, error) {
+	configPath := personaConfigPath(workingDir)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("No .ddx/config.yaml configuration found")
+	}
+
+	var removed []string
+	err := filelock.With(configPath, func() error {
+		return editPersonaConfig(configPath, func(rootNode *yaml.Node) error {
+			var err error
+			if removed, err = removePersonaBindingFromNode(rootNode, role); err != nil {
+				return fmt.Errorf("failed to remove persona binding: %w", err)
+			}
+			if removed == nil {
+				return NewExitError(ExitCodeMissingArg, fmt.Sprintf("role '%s' is not bound in .ddx/config.yaml", role))
+			}
+			return nil
+		})
+	})
+	return removed, err
+}
+
+// unbindWarning describes the workflows whose phases require a role and
+// do not bind it in their own overrides, or returns "" when none do
+func unbindWarning(workingDir, role string) string {
+	cfg, err := loadPersonaConfig(workingDir)
+	if err != nil {
+		return ""
+	}
+	var workflows []string
+	for _, req := range workflowRequirements(workingDir)[role] {
+		if cfg.Overrides[req.Workflow][role] == "" {
+			workflows = append(workflows, req.Workflow)
+		}
+	}
+	if len(workflows) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠️  Role '%s' is required by workflow %s; its phases will run without a persona until the role is bound again", role, strings.Join(workflows, ", "))
+}
+
+// unbindPersona removes a role's binding and reports the result, warning
+// when an installed workflow requires the role
+func unbindPersona(cmd *cobra.Command, workingDir, role string) error {
+	warning := unbindWarning(workingDir, role)
+	removed, err := personaUnbind(workingDir, role)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "🗑️  Unbound role '%s' (was %s)\n", role, strings.Join(removed, ", "))
+	if warning != "" {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), warning)
+	}
+	return nil
+}
+
+// readBindingsFile reads role bindings from a YAML file, or from r when
+// path is "-". The file maps roles to a persona or a list of personas,
+// either at the top level or under persona_bindings, so a project's
+// .ddx/config.yaml can be imported as it is.
+func readBindingsFile(path string, r io.Reader) (config.PersonaBindings, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(r)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bindings file: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse bindings file %s: %w", path, err)
+	}
+	if len(node.Content) == 0 {
+		return config.PersonaBindings{}, nil
+	}
+	mapping := node.Content[0]
+	if mapping.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == "persona_bindings" {
+				mapping = mapping.Content[i+1]
+				break
+			}
+		}
+	}
+	var bindings config.PersonaBindings
+	if err := mapping.Decode(&bindings); err != nil {
+		return nil, fmt.Errorf("invalid bindings file %s: %w", path, err)
+	}
+	return bindings, nil
+}
+
+// personaBindFile binds every role in a bindings file in a single write.
+// Each persona must exist and each role passes the same check as a single
+// bind; nothing is written when one fails. With add, the file's personas
+// are appended to those already bound to a role.
+func personaBindFile(cmd *cobra.Command, workingDir, path string) error {
+	newRole, _ := cmd.Flags().GetBool("new-role")
+	add, _ := cmd.Flags().GetBool("add")
+
+	bindings, err := readBindingsFile(path, cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	if len(bindings) == 0 {
+		return NewExitError(ExitCodeMissingArg, fmt.Sprintf("no bindings in %s", path))
+	}
+	roles := make([]string, 0, len(bindings))
+	for role := range bindings {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	var warnings []string
+	for _, role := range roles {
+		names := config.SplitPersonas(bindings[role])
+		if len(names) == 0 {
+			return fmt.Errorf("role '%s' in %s has no personas", role, path)
+		}
+		for _, name := range names {
+			personaPath, _, err := findPersona(workingDir, name)
+			if err != nil {
+				return fmt.Errorf("role '%s': %w", role, err)
+			}
+			if _, err := os.Stat(personaPath); os.IsNotExist(err) {
+				return fmt.Errorf("role '%s': persona '%s' not found", role, name)
+			}
+		}
+		warning, err := checkBindRole(workingDir, role, newRole)
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	configPath := personaConfigPath(workingDir)
+	err = filelock.With(configPath, func() error {
+		var current config.PersonaBindings
+		if add {
+			cfg, err := loadPersonaConfig(workingDir)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			current = cfg.PersonaBindings
+		}
+		return editPersonaConfig(configPath, func(rootNode *yaml.Node) error {
+			for _, role := range roles {
+				names := config.SplitPersonas(current[role])
+				for _, name := range config.SplitPersonas(bindings[role]) {
+					if !slices.Contains(names, name) {
+						names = append(names, name)
+					}
+				}
+				if err := addPersonaBindingToNode(rootNode, role, names); err != nil {
+					return fmt.Errorf("failed to add persona binding: %w", err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), warning)
+	}
+	for _, role := range roles {
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Bound role '%s' to %s\n", role, strings.Join(config.SplitPersonas(bindings[role]), ", "))
+	}
+	return nil
+}
+
+// completeBoundRoles completes the roles bound in the project for --unbind
+func (f *CommandFactory) completeBoundRoles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	bindings, err := personaBindings(f.WorkingDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var roles []string
+	for role, personas := range bindings {
+		if strings.HasPrefix(role, toComplete) {
+			roles = append(roles, role+"\t"+personas)
+		}
+	}
+	sort.Strings(roles)
+	return roles, cobra.ShellCompDirectiveNoFileComp
+}
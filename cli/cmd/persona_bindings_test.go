@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaBindingsEdit(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\n---\n# Strict Reviewer\n")
+	env.CreateFile(".ddx/library/personas/security-analyst.md", "---\nname: security-analyst\nroles: [code-reviewer]\n---\n# Security Analyst\n")
+	env.CreateFile(".ddx/library/personas/simple-architect.md", "---\nname: simple-architect\nroles: [architect]\n---\n# Simple Architect\n")
+	env.CreateFile(".ddx/library/workflows/helix/workflow.yml", `name: helix
+version: 1.0.0
+description: test
+phases:
+  - id: design
+    order: 1
+    name: Design
+    description: Design it
+    required_role: architect
+`)
+	readConfig := func() string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, ".ddx/config.yaml"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	env.CreateFile("bindings.yaml", "architect: simple-architect\ncode-reviewer:\n  - strict-reviewer\n  - security-analyst\n")
+	output, err := env.RunCommand("persona", "bind", "--file", filepath.Join(env.Dir, "bindings.yaml"))
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Bound role 'architect' to simple-architect")
+	assert.Contains(t, output, "Bound role 'code-reviewer' to strict-reviewer, security-analyst")
+	bindings, err := personaBindings(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, "simple-architect", bindings["architect"])
+	assert.Equal(t, "strict-reviewer, security-analyst", bindings["code-reviewer"])
+
+	t.Run("nothing is bound when a persona is missing", func(t *testing.T) {
+		before := readConfig()
+		env.CreateFile("bad.yaml", "persona_bindings:\n  architect: strict-reviewer\n  code-reviewer: missing-persona\n")
+		_, err := env.RunCommand("persona", "bind", "--file", filepath.Join(env.Dir, "bad.yaml"))
+		assert.EqualError(t, err, "role 'code-reviewer': persona 'missing-persona' not found")
+		assert.Equal(t, before, readConfig())
+	})
+
+	t.Run("misspelled roles are refused", func(t *testing.T) {
+		env.CreateFile("typo.yaml", "architet: simple-architect\n")
+		_, err := env.RunCommand("persona", "bind", "--file", filepath.Join(env.Dir, "typo.yaml"))
+		assert.ErrorContains(t, err, "unknown role 'architet' (did you mean 'architect'?)")
+	})
+
+	output, err = env.RunCommand("persona", "bindings", "--unbind", "code-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Unbound role 'code-reviewer' (was strict-reviewer, security-analyst)")
+	assert.NotContains(t, output, "⚠️")
+	assert.NotContains(t, readConfig(), "code-reviewer")
+
+	output, err = env.RunCommand("persona", "bindings", "--unbind", "architect")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Role 'architect' is required by workflow helix")
+	bindings, err = personaBindings(env.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, bindings)
+
+	_, err = env.RunCommand("persona", "bindings", "--unbind", "architect")
+	assert.EqualError(t, err, "role 'architect' is not bound in .ddx/config.yaml")
+
+	t.Run("stdin with --add", func(t *testing.T) {
+		_, err := env.RunCommand("persona", "bind", "code-reviewer", "strict-reviewer")
+		require.NoError(t, err)
+		root := NewCommandFactory(env.Dir).NewRootCommand()
+		root.SetIn(strings.NewReader("code-reviewer: [strict-reviewer, security-analyst]\n"))
+		output, err := executeCommand(root, "persona", "bind", "--file", "-", "--add")
+		require.NoError(t, err, output)
+		bindings, err := personaBindings(env.Dir)
+		require.NoError(t, err)
+		assert.Equal(t, "strict-reviewer, security-analyst", bindings["code-reviewer"])
+	})
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// RoleSummary describes a role across the library and the project
+type RoleSummary struct {
+	Role string
+	// Personas are the library personas that declare the role
+	Personas []string
+	// Workflows are the installed workflows with a phase requiring the role
+	Workflows []string
+	// Bound are the personas the project binds to the role
+	Bound []string
+}
+
+// Unbound reports whether a workflow requires the role but nothing is
+// bound to it
+func (r RoleSummary) Unbound() bool {
+	return len(r.Workflows) > 0 && len(r.Bound) == 0
+}
+
+// RoleSuggestion is the persona suggested for an unbound role
+type RoleSuggestion struct {
+	Role string
+	// Persona is empty when no persona declares the role
+	Persona string
+	// Tags are the persona tags that matched the role's workflows
+	Tags []string
+}
+
+// personaRoles returns every role declared by a library persona, required
+// by a workflow or bound in the project, sorted by name
+func personaRoles(workingDir string) ([]RoleSummary, error) {
+	personas, err := personaList(workingDir, "", "")
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]*RoleSummary)
+	summary := func(role string) *RoleSummary {
+		if summaries[role] == nil {
+			summaries[role] = &RoleSummary{Role: role}
+		}
+		return summaries[role]
+	}
+	for _, p := range personas {
+		for _, role := range p.Roles {
+			summary(role).Personas = append(summary(role).Personas, p.Name)
+		}
+	}
+	for role, reqs := range workflowRequirements(workingDir) {
+		for _, req := range reqs {
+			summary(role).Workflows = append(summary(role).Workflows, req.Workflow)
+		}
+	}
+	if bindings, err := personaBindings(workingDir); err == nil {
+		for role, value := range bindings {
+			summary(role).Bound = config.SplitPersonas(value)
+		}
+	}
+
+	roles := make([]RoleSummary, 0, len(summaries))
+	for _, s := range summaries {
+		sort.Strings(s.Personas)
+		roles = append(roles, *s)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Role < roles[j].Role })
+	return roles, nil
+}
+
+// suggestRoleBindings picks a persona for each role a workflow requires
+// but nothing is bound to. Candidates are the personas declaring the role,
+// ranked by how many of their tags match the requiring workflows' tags and
+// the words of the role name; ties go to the first name alphabetically.
+func suggestRoleBindings(workingDir string) ([]RoleSuggestion, error) {
+	roles, err := personaRoles(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	personas, err := personaList(workingDir, "", "")
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]PersonaInfo, len(personas))
+	for _, p := range personas {
+		byName[p.Name] = p
+	}
+	requirements := workflowRequirements(workingDir)
+
+	var suggestions []RoleSuggestion
+	for _, role := range roles {
+		if !role.Unbound() {
+			continue
+		}
+		wanted := make(map[string]bool)
+		for _, word := range strings.Split(role.Role, "-") {
+			wanted[word] = true
+		}
+		for _, req := range requirements[role.Role] {
+			for _, tag := range req.Tags {
+				wanted[tag] = true
+			}
+		}
+
+		suggestion := RoleSuggestion{Role: role.Role}
+		best := -1
+		for _, name := range role.Personas {
+			var matched []string
+			for _, tag := range byName[name].Tags {
+				if wanted[tag] {
+					matched = append(matched, tag)
+				}
+			}
+			if len(matched) > best {
+				best = len(matched)
+				suggestion.Persona, suggestion.Tags = name, matched
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// runPersonaRoles lists the role catalog, or binds suggested personas to
+// unbound roles with --suggest
+func runPersonaRoles(cmd *cobra.Command, workingDir string) error {
+	if suggest, _ := cmd.Flags().GetBool("suggest"); suggest {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return applyRoleSuggestions(cmd, workingDir, dryRun)
+	}
+
+	roles, err := personaRoles(workingDir)
+	if err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No roles found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ROLE\tPERSONAS\tREQUIRED BY\tBOUND TO")
+	_, _ = fmt.Fprintln(w, "----\t--------\t-----------\t--------")
+	unbound := 0
+	for _, role := range roles {
+		requiredBy, bound := "-", "-"
+		if len(role.Workflows) > 0 {
+			requiredBy = strings.Join(role.Workflows, ", ")
+		}
+		if len(role.Bound) > 0 {
+			bound = strings.Join(role.Bound, ", ")
+		}
+		if role.Unbound() {
+			bound = "⚠️  unbound"
+			unbound++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", role.Role, len(role.Personas), requiredBy, bound)
+	}
+	_ = w.Flush()
+
+	if unbound > 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%d role(s) required by workflows have no binding; run 'ddx persona roles --suggest' to bind the best match\n", unbound)
+	}
+	return nil
+}
+
+// applyRoleSuggestions binds the suggested persona to each unbound role
+func applyRoleSuggestions(cmd *cobra.Command, workingDir string, dryRun bool) error {
+	suggestions, err := suggestRoleBindings(workingDir)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(suggestions) == 0 {
+		_, _ = fmt.Fprintln(out, "Every role required by a workflow is bound")
+		return nil
+	}
+	for _, s := range suggestions {
+		if s.Persona == "" {
+			_, _ = fmt.Fprintf(out, "⚠️  No persona declares role '%s'; bind one with 'ddx persona bind %s <persona>'\n", s.Role, s.Role)
+			continue
+		}
+		reason := "declares the role"
+		if len(s.Tags) > 0 {
+			reason = "matching tags: " + strings.Join(s.Tags, ", ")
+		}
+		if dryRun {
+			_, _ = fmt.Fprintf(out, "Would bind role '%s' to persona '%s' (%s)\n", s.Role, s.Persona, reason)
+			continue
+		}
+		if err := personaBind(workingDir, s.Role, s.Persona, false); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "✅ Bound role '%s' to persona '%s' (%s)\n", s.Role, s.Persona, reason)
+	}
+	if dryRun {
+		_, _ = fmt.Fprintln(out, "🔍 Dry run: no bindings were changed")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaRoles(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\ntags: [quality]\n---\n# Strict Reviewer\n")
+	env.CreateFile(".ddx/library/personas/systems-architect.md", "---\nname: systems-architect\nroles: [architect]\ntags: [design]\n---\n# Systems Architect\n")
+	env.CreateFile(".ddx/library/personas/api-architect.md", "---\nname: api-architect\nroles: [architect]\ntags: [api]\n---\n# API Architect\n")
+	env.CreateFile(".ddx/library/workflows/helix/workflow.yml", `name: helix
+version: 1.0.0
+description: test
+tags: [design]
+phases:
+  - id: design
+    order: 1
+    name: Design
+    description: Design it
+    required_role: architect
+  - id: test
+    order: 2
+    name: Test
+    description: Test it
+    required_role: test-engineer
+`)
+
+	roles, err := personaRoles(env.Dir)
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	assert.Equal(t, RoleSummary{Role: "architect", Personas: []string{"api-architect", "systems-architect"}, Workflows: []string{"helix"}}, roles[0])
+	assert.Equal(t, "code-reviewer", roles[1].Role)
+	assert.False(t, roles[1].Unbound())
+	assert.True(t, roles[2].Unbound())
+
+	output, err := env.RunCommand("persona", "roles")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "2 role(s) required by workflows have no binding")
+
+	output, err = env.RunCommand("persona", "roles", "--suggest", "--dry-run")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Would bind role 'architect' to persona 'systems-architect' (matching tags: design)")
+	cfg, err := env.LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.PersonaBindings)
+
+	output, err = env.RunCommand("persona", "roles", "--suggest")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Bound role 'architect' to persona 'systems-architect'")
+	assert.Contains(t, output, "No persona declares role 'test-engineer'")
+	cfg, err = env.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "systems-architect", cfg.PersonaBindings["architect"])
+}
+
+func TestPersonaShowRawAndStdin(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\ndescription: Strict\n---\n\n# Strict Reviewer\nBe thorough.\n")
+
+	output, err := env.RunCommand("persona", "show", "strict-reviewer", "--raw")
+	require.NoError(t, err, output)
+	assert.Equal(t, "# Strict Reviewer\nBe thorough.\n", output)
+
+	draft := "---\nname: draft\nroles: [architect]\ndescription: A draft\n---\n# Draft\nDesign first.\n"
+	root := NewCommandFactory(env.Dir).NewRootCommand()
+	root.SetIn(strings.NewReader(draft))
+	output, err = executeCommand(root, "persona", "show", "-")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Persona draft is valid")
+	assert.Contains(t, output, "Roles: architect")
+	assert.NoFileExists(t, filepath.Join(env.LibraryPath, "personas", "draft.md"))
+
+	root = NewCommandFactory(env.Dir).NewRootCommand()
+	root.SetIn(strings.NewReader(draft))
+	output, err = executeCommand(root, "persona", "show", "-", "--raw")
+	require.NoError(t, err, output)
+	assert.Equal(t, "# Draft\nDesign first.\n", output)
+
+	root = NewCommandFactory(env.Dir).NewRootCommand()
+	root.SetIn(strings.NewReader("---\nname: broken\n---\nNo roles.\n"))
+	_, err = executeCommand(root, "persona", "show", "-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid persona on stdin: persona must have at least one role")
+}
+
+func TestPersonaProjectDirectory(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\ndescription: Library reviewer\n---\n# Library Reviewer\n")
+	env.CreateFile(".ddx/library/personas/systems-architect.md", "---\nname: systems-architect\nroles: [architect]\ndescription: Library architect\n---\n# Systems Architect\n")
+	env.CreateFile(".ddx/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\ndescription: Our reviewer\n---\n# Our Reviewer\n")
+	env.CreateFile(".ddx/personas/team-lead.md", "---\nname: team-lead\nroles: [lead]\ndescription: Our lead\n---\n# Team Lead\n")
+
+	output, err := env.RunCommand("persona", "list")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `strict-reviewer\s+code-reviewer\s+project\s+Our reviewer`, output)
+	assert.Regexp(t, `systems-architect\s+architect\s+library\s+Library architect`, output)
+	assert.Regexp(t, `team-lead\s+lead\s+project\s+Our lead`, output)
+	assert.NotContains(t, output, "Library reviewer")
+
+	output, err = env.RunCommand("persona", "list", "--role", "lead")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "team-lead")
+	assert.NotContains(t, output, "strict-reviewer")
+
+	output, err = env.RunCommand("persona", "show", "strict-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Source: project ("+filepath.Join(env.Dir, ".ddx", "personas", "strict-reviewer.md")+")")
+	assert.Contains(t, output, "# Our Reviewer")
+
+	output, err = env.RunCommand("persona", "bind", "lead", "team-lead")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("persona", "load")
+	require.NoError(t, err, output)
+	claude, err := os.ReadFile(filepath.Join(env.Dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(claude), "# Team Lead")
+}
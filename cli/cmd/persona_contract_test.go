@@ -717,8 +717,8 @@ You follow TDD practices.`
 				require.NoError(t, err)
 
 				claudeStr := string(content)
-				assert.Contains(t, claudeStr, "<!-- PERSONAS:START -->")
-				assert.Contains(t, claudeStr, "<!-- PERSONAS:END -->")
+				assert.Contains(t, claudeStr, `<!-- DDX:START name="personas"`)
+				assert.Contains(t, claudeStr, `<!-- DDX:END name="personas" -->`)
 				assert.Contains(t, claudeStr, "Strict Code Reviewer")
 				assert.Contains(t, claudeStr, "TDD Engineer")
 			},
@@ -793,7 +793,7 @@ You are a strict code reviewer.`
 				require.NoError(t, err)
 
 				claudeStr := string(content)
-				assert.Contains(t, claudeStr, "<!-- PERSONAS:START -->")
+				assert.Contains(t, claudeStr, `<!-- DDX:START name="personas"`)
 				assert.Contains(t, claudeStr, "Strict Code Reviewer")
 			},
 		},
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/spf13/cobra"
+)
+
+// personaHeaderPattern matches the role header written above each bound
+// persona in the personas section
+var personaHeaderPattern = regexp.MustCompile(`(?m)^### [^\n]+: [A-Za-z0-9][A-Za-z0-9._-]*\n`)
+
+// PersonaFileDiff is the difference between the personas injected into
+// one AI target file and what would be written now
+type PersonaFileDiff struct {
+	File string
+	// Diff is a unified diff, empty when nothing would change
+	Diff string
+}
+
+// injectedPersonaSection returns the content of the personas section of a
+// file, or an empty string when the file has none
+func injectedPersonaSection(path string) string {
+	doc, err := sections.Load(path)
+	if err != nil {
+		return ""
+	}
+	section, found := doc.Get(persona.PersonasSection)
+	if !found {
+		return ""
+	}
+	return section.Content + "\n"
+}
+
+// personaBlock returns the text of one bound persona within a personas
+// section, from its role header to the next persona or the section end. A
+// persona bound to several roles has a block under each.
+func personaBlock(section, name string) string {
+	var block strings.Builder
+	headers := personaHeaderPattern.FindAllStringIndex(section, -1)
+	for i, loc := range headers {
+		if !strings.HasSuffix(section[loc[0]:loc[1]], ": "+name+"\n") {
+			continue
+		}
+		end := len(section)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		block.WriteString(section[loc[0]:end])
+	}
+	return block.String()
+}
+
+// trimBlock ends a persona block with a single newline, as the last block
+// of a section is written
+func trimBlock(block string) string {
+	if block == "" {
+		return ""
+	}
+	return strings.Trim(block, "\n") + "\n"
+}
+
+// personaDiff compares the personas injected into each AI target file with
+// the bound personas in the library, optionally for one persona only
+func personaDiff(workingDir, name string) ([]PersonaFileDiff, error) {
+	plan, err := planPersonaLoad(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	want := plan.Section
+	if name != "" {
+		block, ok := plan.Blocks[name]
+		if !ok {
+			return nil, fmt.Errorf("persona '%s' is not bound to a role", name)
+		}
+		want = trimBlock(block)
+	}
+
+	diffs := make([]PersonaFileDiff, 0, len(plan.Files))
+	for _, file := range plan.Files {
+		current := injectedPersonaSection(filepath.Join(workingDir, file))
+		if name != "" {
+			current = trimBlock(personaBlock(current, name))
+		}
+		diffs = append(diffs, PersonaFileDiff{File: file, Diff: unifiedDiff(current, want, file+" (injected)", "library")})
+	}
+	return diffs, nil
+}
+
+// previewPersonaLoad shows what 'persona load' would change in each file
+func previewPersonaLoad(workingDir string, personas ...string) ([]PersonaFileDiff, error) {
+	plan, err := planPersonaLoad(workingDir, personas...)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]PersonaFileDiff, 0, len(plan.Files))
+	for _, file := range plan.Files {
+		current := injectedPersonaSection(filepath.Join(workingDir, file))
+		diffs = append(diffs, PersonaFileDiff{File: file, Diff: unifiedDiff(current, plan.Section, file, file+" (after load)")})
+	}
+	return diffs, nil
+}
+
+// displayPersonaDiffs prints each file's diff, or that it is up to date
+func displayPersonaDiffs(cmd *cobra.Command, diffs []PersonaFileDiff, upToDate string) {
+	out := cmd.OutOrStdout()
+	for _, d := range diffs {
+		if d.Diff == "" {
+			_, _ = fmt.Fprintf(out, "✓ %s: %s\n", d.File, upToDate)
+			continue
+		}
+		writeDiff(out, d.Diff)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaDiff(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: ` + env.TestLibraryURL + `
+    branch: master
+persona_bindings:
+  code-reviewer: strict-reviewer
+  architect: systems-architect
+`)
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "# Strict Reviewer\n\nReject untested code.\n")
+	env.CreateFile(".ddx/library/personas/systems-architect.md", "# Systems Architect\n\nPrefer simple designs.\n")
+
+	output, err := env.RunCommand("persona", "load")
+	require.NoError(t, err, output)
+	claude := filepath.Join(env.Dir, "CLAUDE.md")
+	before, err := os.ReadFile(claude)
+	require.NoError(t, err)
+
+	output, err = env.RunCommand("persona", "diff")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "✓ CLAUDE.md: injected personas match the library")
+
+	// The library changes upstream
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "# Strict Reviewer\n\nReject untested or undocumented code.\n")
+
+	output, err = env.RunCommand("persona", "diff", "strict-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "--- CLAUDE.md (injected)")
+	assert.Contains(t, output, "-Reject untested code.")
+	assert.Contains(t, output, "+Reject untested or undocumented code.")
+	assert.NotContains(t, output, "Prefer simple designs")
+
+	output, err = env.RunCommand("persona", "diff", "systems-architect")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "✓ CLAUDE.md")
+
+	_, err = env.RunCommand("persona", "diff", "unbound")
+	assert.EqualError(t, err, "persona 'unbound' is not bound to a role")
+
+	output, err = env.RunCommand("persona", "load", "--dry-run")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "+Reject untested or undocumented code.")
+	assert.Contains(t, output, "Dry run: no files were written")
+	after, err := os.ReadFile(claude)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// knownRoles returns the role names in use across the project: roles the
+// library's personas declare, roles workflow phases require, and roles
+// that are already bound
+func knownRoles(workingDir string) map[string]bool {
+	roles := definedRoles(workingDir)
+	for role := range workflowRequirements(workingDir) {
+		roles[role] = true
+	}
+	return roles
+}
+
+// definedRoles returns the roles a persona can fill: those the library's
+// personas declare and those the project binds
+func definedRoles(workingDir string) map[string]bool {
+	roles := make(map[string]bool)
+	if personas, err := personaList(workingDir, "", ""); err == nil {
+		for _, p := range personas {
+			for _, role := range p.Roles {
+				roles[role] = true
+			}
+		}
+	}
+	if bindings, err := personaBindings(workingDir); err == nil {
+		for role := range bindings {
+			roles[role] = true
+		}
+	}
+	return roles
+}
+
+// workflowRequirement is a workflow whose phases require a role
+type workflowRequirement struct {
+	Workflow string
+	Tags     []string
+}
+
+// workflowRequirements maps each role required by a workflow phase in the
+// library to the workflows requiring it, in workflow name order
+func workflowRequirements(workingDir string) map[string][]workflowRequirement {
+	required := make(map[string][]workflowRequirement)
+	libPath, err := getPersonaLibraryPath(workingDir)
+	if err != nil {
+		return required
+	}
+	entries, _ := os.ReadDir(filepath.Join(libPath, "workflows"))
+	loader := workflow.NewLoader(libPath)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		def, err := loader.Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, phase := range def.Phases {
+			if phase.RequiredRole != "" && !seen[phase.RequiredRole] {
+				seen[phase.RequiredRole] = true
+				required[phase.RequiredRole] = append(required[phase.RequiredRole], workflowRequirement{Workflow: entry.Name(), Tags: def.Tags})
+			}
+		}
+	}
+	return required
+}
+
+// similarRoles returns the known roles within a small edit distance of role
+func similarRoles(role string, known map[string]bool) []string {
+	var similar []string
+	for candidate := range known {
+		if editDistance(role, candidate) <= 2 {
+			similar = append(similar, candidate)
+		}
+	}
+	sort.Strings(similar)
+	return similar
+}
+
+// checkBindRole guards against binding a misspelled role. A role no
+// persona, workflow or binding uses is refused when it is close to a known
+// role, unless newRole is set; other new roles are allowed with a warning.
+func checkBindRole(workingDir, role string, newRole bool) (string, error) {
+	known := knownRoles(workingDir)
+	if newRole || len(known) == 0 || known[role] {
+		return "", nil
+	}
+	if similar := similarRoles(role, known); len(similar) > 0 {
+		return "", fmt.Errorf("unknown role '%s' (did you mean '%s'?); pass --new-role to bind it anyway", role, similar[0])
+	}
+	return fmt.Sprintf("⚠️  Role '%s' is not used by any persona, workflow or binding; nothing will pick up this binding until one does", role), nil
+}
+
+// bindPersona checks the role name, binds it and reports the result
+func bindPersona(cmd *cobra.Command, workingDir, role, personaName string) error {
+	newRole, _ := cmd.Flags().GetBool("new-role")
+	add, _ := cmd.Flags().GetBool("add")
+	warning, err := checkBindRole(workingDir, role, newRole)
+	if err != nil {
+		return err
+	}
+	if err := personaBind(workingDir, role, personaName, add); err != nil {
+		return err
+	}
+	if warning != "" {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), warning)
+	}
+	if add {
+		_, _ = fmt.Fprintf(statusOut(cmd), "✅ Added persona '%s' to role '%s'\n", personaName, role)
+		return nil
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Bound role '%s' to persona '%s'\n", role, personaName)
+	return nil
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaBindRoleCheck(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\n---\n# Strict Reviewer\n")
+	env.CreateFile(".ddx/library/workflows/helix/workflow.yml", `name: helix
+version: 1.0.0
+description: test
+phases:
+  - id: design
+    order: 1
+    name: Design
+    description: Design it
+    required_role: architect
+`)
+
+	known := knownRoles(env.Dir)
+	assert.True(t, known["code-reviewer"])
+	assert.True(t, known["architect"])
+
+	_, err := env.RunCommand("persona", "bind", "code-reviwer", "strict-reviewer")
+	assert.EqualError(t, err, "unknown role 'code-reviwer' (did you mean 'code-reviewer'?); pass --new-role to bind it anyway")
+
+	output, err := env.RunCommand("persona", "bind", "code-reviewer", "strict-reviewer")
+	require.NoError(t, err, output)
+	assert.NotContains(t, output, "⚠️")
+
+	output, err = env.RunCommand("persona", "bind", "release-manager", "strict-reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Role 'release-manager' is not used by any persona, workflow or binding")
+
+	output, err = env.RunCommand("persona", "bind", "code-reviwer", "strict-reviewer", "--new-role")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Bound role 'code-reviwer'")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("architect", "architect"))
+	assert.Equal(t, 1, editDistance("code-reviwer", "code-reviewer"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
+
+func TestPersonaLoadSeveralPersonasPerRole(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/strict-reviewer.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\n---\n# Strict Reviewer\n")
+	env.CreateFile(".ddx/library/personas/security-analyst.md", "---\nname: security-analyst\nroles: [code-reviewer]\n---\n# Security Analyst\n")
+	env.CreateFile(".ddx/library/personas/copycat.md", "---\nname: strict-reviewer\nroles: [code-reviewer]\n---\n# Copycat\n")
+
+	output, err := env.RunCommand("persona", "bind", "code-reviewer", "strict-reviewer")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("persona", "bind", "code-reviewer", "security-analyst", "--add")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Added persona 'security-analyst' to role 'code-reviewer'")
+
+	_, err = env.RunCommand("persona", "bind", "code-reviewer", "security-analyst", "--add")
+	assert.EqualError(t, err, "persona 'security-analyst' is already bound to role 'code-reviewer'")
+
+	data, err := os.ReadFile(filepath.Join(env.Dir, ".ddx/config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "code-reviewer:\n        - strict-reviewer\n        - security-analyst")
+
+	plan, err := planPersonaLoad(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"strict-reviewer", "security-analyst"}, plan.Loaded)
+	strict := strings.Index(plan.Section, "### Code Reviewer: strict-reviewer\n")
+	security := strings.Index(plan.Section, "### Code Reviewer: security-analyst\n")
+	require.NotEqual(t, -1, strict)
+	assert.Greater(t, security, strict)
+
+	output, err = env.RunCommand("persona", "bind", "code-reviewer", "copycat", "--add")
+	require.NoError(t, err, output)
+	_, err = planPersonaLoad(env.Dir)
+	assert.EqualError(t, err, "personas 'strict-reviewer' and 'copycat' are both named 'strict-reviewer'; rename one before loading them together")
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/persona"
+	"github.com/spf13/cobra"
+)
+
+// States of a persona in 'ddx persona status --stats'
+const (
+	// PersonaCurrent personas were last loaded as the library has them now
+	PersonaCurrent = "current"
+	// PersonaStale personas changed in the library since they were loaded
+	PersonaStale = "stale"
+	// PersonaNotLoaded personas are bound but were never loaded
+	PersonaNotLoaded = "not loaded"
+	// PersonaMissing personas were loaded but are no longer in the project
+	// or the library
+	PersonaMissing = "missing"
+)
+
+// PersonaUsage is when a persona was loaded and whether the loaded copy
+// still matches the library
+type PersonaUsage struct {
+	Name       string
+	Roles      []string
+	LastLoaded time.Time
+	Loads      int
+	State      string
+}
+
+// OrphanedBinding is a binding to a persona that no longer exists
+type OrphanedBinding struct {
+	// Key is the binding's configuration key, such as
+	// persona_bindings.code-reviewer
+	Key     string
+	Persona string
+}
+
+// PersonaUsageReport is the result of 'ddx persona status --stats'
+type PersonaUsageReport struct {
+	Personas []PersonaUsage
+	Orphaned []OrphanedBinding
+}
+
+// personaUsage reports every persona that was loaded or is bound: when it
+// was last loaded, and whether that copy is stale. Bindings to personas
+// that cannot be found are reported as orphaned.
+func personaUsage(workingDir string) (*PersonaUsageReport, error) {
+	cfg, err := loadPersonaConfig(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	sources, err := personaSources(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library path: %w", err)
+	}
+	log, err := persona.LoadUsage(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PersonaUsageReport{}
+	roles := map[string][]string{}
+	bindings := map[string]string{}
+	for role, value := range cfg.PersonaBindings {
+		bindings["persona_bindings."+role] = value
+	}
+	for workflow, overrides := range cfg.Overrides {
+		for role, value := range overrides {
+			bindings["overrides."+workflow+"."+role] = value
+		}
+	}
+	keys := make([]string, 0, len(bindings))
+	for key := range bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, name := range config.SplitPersonas(bindings[key]) {
+			if path, _, err := resolvePersona(workingDir, sources, name); err != nil || !fileExists(path) {
+				report.Orphaned = append(report.Orphaned, OrphanedBinding{Key: key, Persona: name})
+			}
+			if role, ok := strings.CutPrefix(key, "persona_bindings."); ok {
+				roles[name] = append(roles[name], role)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(log)+len(roles))
+	for name := range log {
+		names = append(names, name)
+	}
+	for name := range roles {
+		if _, ok := log[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		usage, loaded := log[name]
+		info := PersonaUsage{Name: name, Roles: roles[name], LastLoaded: usage.LastLoaded, Loads: usage.Loads}
+		path, _, err := resolvePersona(workingDir, sources, name)
+		var content []byte
+		if err == nil {
+			content, err = os.ReadFile(path)
+		}
+		switch {
+		case err != nil && loaded:
+			info.State = PersonaMissing
+		case err != nil:
+			// Reported with the orphaned bindings
+			continue
+		case !loaded:
+			info.State = PersonaNotLoaded
+		case persona.ContentHash(content) != usage.Hash:
+			info.State = PersonaStale
+		default:
+			info.State = PersonaCurrent
+		}
+		report.Personas = append(report.Personas, info)
+	}
+	return report, nil
+}
+
+// displayPersonaUsage prints the usage report after the persona status
+func displayPersonaUsage(cmd *cobra.Command, report *PersonaUsageReport) {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintln(out, "\nPersona Usage:")
+	if len(report.Personas) == 0 {
+		_, _ = fmt.Fprintln(out, "  No personas loaded or bound yet")
+	} else {
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "  PERSONA\tROLES\tLAST LOADED\tLOADS\tSTATE")
+		for _, p := range report.Personas {
+			last := "never"
+			if !p.LastLoaded.IsZero() {
+				last = p.LastLoaded.Local().Format("2006-01-02 15:04")
+			}
+			roles := strings.Join(p.Roles, ", ")
+			if roles == "" {
+				roles = "-"
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\t%s\n", p.Name, roles, last, p.Loads, p.State)
+		}
+		_ = tw.Flush()
+	}
+	for _, p := range report.Personas {
+		if p.State == PersonaStale || p.State == PersonaNotLoaded {
+			_, _ = fmt.Fprintln(statusOut(cmd), "💡 Run 'ddx persona load' to load the current personas")
+			break
+		}
+	}
+
+	if len(report.Orphaned) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(out, "\nOrphaned Bindings:")
+	for _, o := range report.Orphaned {
+		_, _ = fmt.Fprintf(out, "  ❌ %s → %s (not in the project or the library)\n", o.Key, o.Persona)
+	}
+	_, _ = fmt.Fprintln(statusOut(cmd), "💡 Rebind each role with 'ddx persona bind <role> <persona>'")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaStatusStats(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	config := `version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+persona_bindings:
+  code-reviewer: strict-reviewer
+  architect: systems-architect
+`
+	env.CreateConfig(config)
+	lib := env.Library().
+		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}}).
+		Persona(testutil.PersonaSpec{Name: "systems-architect", Roles: []string{"architect"}}).
+		Persona(testutil.PersonaSpec{Name: "test-engineer", Roles: []string{"tester"}})
+
+	output, err := env.RunCommand("persona", "status", "--stats")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `strict-reviewer\s+code-reviewer\s+never\s+0\s+not loaded`, output)
+	assert.Contains(t, output, "ddx persona load")
+
+	output, err = env.RunCommand("persona", "load")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("persona", "load", "test-engineer")
+	require.NoError(t, err, output)
+	usage, err := persona.LoadUsage(env.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage["strict-reviewer"].Loads)
+	assert.Equal(t, ".ddx/library/personas/test-engineer.md", usage["test-engineer"].Path)
+
+	output, err = env.RunCommand("persona", "status", "--stats")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `strict-reviewer\s+code-reviewer\s+\d{4}-\d\d-\d\d \d\d:\d\d\s+1\s+current`, output)
+	assert.Regexp(t, `test-engineer\s+-\s+\S+ \S+\s+1\s+current`, output)
+	assert.NotContains(t, output, "Orphaned Bindings")
+
+	// The library changes one persona and drops another, and a role is
+	// bound to a persona that was never there
+	lib.Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Body: "# Stricter\n"})
+	require.NoError(t, os.Remove(filepath.Join(lib.Dir(), "personas", "test-engineer.md")))
+	env.CreateConfig(config + "  security-analyst: sec-reviewer\n")
+
+	output, err = env.RunCommand("persona", "status", "--stats")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `strict-reviewer\s+code-reviewer\s+\S+ \S+\s+1\s+stale`, output)
+	assert.Regexp(t, `systems-architect\s+architect\s+\S+ \S+\s+1\s+current`, output)
+	assert.Regexp(t, `test-engineer\s+-\s+\S+ \S+\s+1\s+missing`, output)
+	assert.Contains(t, output, "Orphaned Bindings:\n  ❌ persona_bindings.security-analyst → sec-reviewer")
+	assert.NotRegexp(t, `sec-reviewer\s+security-analyst`, output)
+
+	output, err = env.RunCommand("persona", "status")
+	require.NoError(t, err, output)
+	assert.NotContains(t, output, "Persona Usage")
+}
@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"github.com/easel/ddx/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+// newProgress returns the indicator a command shows during long
+// operations: a spinner on stderr when it is a terminal, and nothing when
+// it is not or the command is quiet
+func newProgress(cmd *cobra.Command) *progress.Indicator {
+	return progress.New(cmd.ErrOrStderr(), !quiet(cmd) && isTerminal(cmd.ErrOrStderr()))
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// Statuses reported when managed prompt sections are synced
+const (
+	SectionUpdated   = "updated"
+	SectionUnchanged = "unchanged"
+	SectionModified  = "modified"
+	SectionMissing   = "missing"
+)
+
+// PromptSectionSync describes what happened to one managed section
+type PromptSectionSync struct {
+	Name   string
+	Source string
+	Status string
+}
+
+// promptSectionName derives a section name from a prompt path: its file
+// name, or its directory for README.md prompts
+func promptSectionName(prompt string) string {
+	prompt = strings.TrimSuffix(filepath.ToSlash(prompt), "/")
+	base := strings.TrimSuffix(filepath.Base(prompt), ".md")
+	if strings.EqualFold(base, "README") {
+		base = filepath.Base(filepath.Dir(prompt))
+	}
+	return base
+}
+
+// renderPromptSection reads a prompt and fills its placeholders with the
+// project's recorded variables
+func renderPromptSection(workingDir, prompt string) (string, error) {
+	path, err := resolvePromptPath(workingDir, prompt)
+	if err != nil {
+		return "", err
+	}
+	content, err := readPrompt(workingDir, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt %s: %w", prompt, err)
+	}
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return "", err
+	}
+	rendered, _ := variables.Render(content, registry.Values)
+	return strings.TrimSpace(rendered), nil
+}
+
+// injectPromptSection writes a library prompt into a managed section of
+// file, reporting whether the file changed. A section edited by hand is
+// only overwritten with force. An order below zero keeps the section's
+// current position.
+func injectPromptSection(workingDir, file, prompt, name string, order int, force bool) (bool, error) {
+	if name == "" {
+		name = promptSectionName(prompt)
+	}
+	if err := sections.ValidateName(name); err != nil {
+		return false, err
+	}
+	content, err := renderPromptSection(workingDir, prompt)
+	if err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(workingDir, file)
+	doc, err := sections.Load(path)
+	if err != nil {
+		return false, err
+	}
+	existing, found := doc.Get(name)
+	if found && existing.Modified() && !force {
+		return false, fmt.Errorf("section '%s' in %s was edited by hand; use --force to overwrite it", name, file)
+	}
+	if order < 0 {
+		order = sections.DefaultOrder
+		if found {
+			order = existing.Order
+		}
+	}
+
+	if !doc.Set(sections.Section{Name: name, Source: prompt, Order: order, Content: content}) {
+		return false, nil
+	}
+	return true, doc.Save(path)
+}
+
+// syncPromptSections refreshes every managed section in file from its
+// source prompt. Sections edited by hand are left alone unless forced.
+func syncPromptSections(workingDir, file string, force bool) ([]PromptSectionSync, error) {
+	path := filepath.Join(workingDir, file)
+	doc, err := sections.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PromptSectionSync
+	changed := false
+	for _, section := range doc.Sorted() {
+		result := PromptSectionSync{Name: section.Name, Source: section.Source}
+		var content string
+		var err error
+		if workflowName, ok := strings.CutPrefix(section.Source, phaseContextSource); ok {
+			if content, err = phaseContext(workingDir, workflowName); err == nil && content == "" {
+				doc.Remove(section.Name)
+				result.Status = SectionUpdated
+				changed = true
+				results = append(results, result)
+				continue
+			}
+		} else {
+			content, err = renderPromptSection(workingDir, section.Source)
+		}
+		switch {
+		case section.Source == "" || err != nil:
+			result.Status = SectionMissing
+		case section.Modified() && !force:
+			result.Status = SectionModified
+		default:
+			section.Content = content
+			if doc.Set(section) {
+				result.Status = SectionUpdated
+				changed = true
+			} else {
+				result.Status = SectionUnchanged
+			}
+		}
+		results = append(results, result)
+	}
+
+	if changed {
+		if err := doc.Save(path); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// removePromptSection deletes a managed section from file
+func removePromptSection(workingDir, file, name string) error {
+	path := filepath.Join(workingDir, file)
+	doc, err := sections.Load(path)
+	if err != nil {
+		return err
+	}
+	if !doc.Remove(name) {
+		return fmt.Errorf("no section named '%s' in %s", name, file)
+	}
+	return doc.Save(path)
+}
+
+// promptSectionFiles returns the --file flag, or the configured AI target
+// files when it is not given
+func promptSectionFiles(cmd *cobra.Command, workingDir string) ([]string, error) {
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if file, _ := cmd.Flags().GetString("file"); file != "" {
+		if injectionRules(cfg).Disabled(file) {
+			return nil, fmt.Errorf("%s is under injection.disabled_paths", file)
+		}
+		return []string{file}, nil
+	}
+	targetList, disabled, err := aiTargets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(targetList) == 0 {
+		return nil, errInjectionDisabled(disabled)
+	}
+	return targets.Files(targetList), nil
+}
+
+// runPromptInject implements the prompts inject command
+func (f *CommandFactory) runPromptInject(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	order, _ := cmd.Flags().GetInt("order")
+	force, _ := cmd.Flags().GetBool("force")
+	if !cmd.Flags().Changed("order") {
+		order = -1
+	}
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+	if len(args) > 1 && name != "" {
+		return fmt.Errorf("--name can only be used with a single prompt")
+	}
+	files, err := promptSectionFiles(cmd, f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if isInitializedInDir(f.WorkingDir) {
+		cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if _, err := takeSnapshot(f.WorkingDir, cfg, "prompts inject"); err != nil {
+			return err
+		}
+	}
+	if err := backupAIFiles(f.WorkingDir, "prompts inject", files); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if len(args) == 0 {
+			err = syncPromptFile(out, f.WorkingDir, file, force)
+		} else {
+			err = injectPromptFile(statusOut(cmd), f.WorkingDir, file, args, name, order, force)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPromptFile refreshes the managed sections of one file and reports
+// what happened to each
+func syncPromptFile(out io.Writer, workingDir, file string, force bool) error {
+	var results []PromptSectionSync
+	err := filelock.With(filepath.Join(workingDir, file), func() (err error) {
+		results, err = syncPromptSections(workingDir, file, force)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		_, _ = fmt.Fprintf(out, "No managed sections in %s\n", file)
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "%s:\n", file)
+	for _, r := range results {
+		switch r.Status {
+		case SectionUpdated:
+			_, _ = fmt.Fprintf(out, "  ↻ %s updated from %s\n", r.Name, r.Source)
+		case SectionUnchanged:
+			_, _ = fmt.Fprintf(out, "  ✓ %s is up to date\n", r.Name)
+		case SectionModified:
+			_, _ = fmt.Fprintf(out, "  ⚠️  %s was edited by hand; skipped (use --force)\n", r.Name)
+		case SectionMissing:
+			_, _ = fmt.Fprintf(out, "  ⚠️  %s: source prompt '%s' not found\n", r.Name, r.Source)
+		}
+	}
+	return nil
+}
+
+// injectPromptFile writes prompts into one file and reports each section
+func injectPromptFile(out io.Writer, workingDir, file string, prompts []string, name string, order int, force bool) error {
+	for _, prompt := range prompts {
+		var changed bool
+		err := filelock.With(filepath.Join(workingDir, file), func() (err error) {
+			changed, err = injectPromptSection(workingDir, file, prompt, name, order, force)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		sectionName := name
+		if sectionName == "" {
+			sectionName = promptSectionName(prompt)
+		}
+		if changed {
+			_, _ = fmt.Fprintf(out, "✅ Injected %s into %s as section '%s'\n", prompt, file, sectionName)
+		} else {
+			_, _ = fmt.Fprintf(out, "✓ Section '%s' in %s is up to date\n", sectionName, file)
+		}
+	}
+	return nil
+}
+
+// runPromptRemove implements the prompts remove command. Without --file a
+// section only has to be present in one of the target files.
+func (f *CommandFactory) runPromptRemove(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	files, err := promptSectionFiles(cmd, f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if err := backupAIFiles(f.WorkingDir, "prompts remove", files); err != nil {
+		return err
+	}
+	for _, name := range args {
+		var removed []string
+		var lastErr error
+		for _, file := range files {
+			err := filelock.With(filepath.Join(f.WorkingDir, file), func() error {
+				return removePromptSection(f.WorkingDir, file, name)
+			})
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			removed = append(removed, file)
+		}
+		if len(removed) == 0 {
+			if len(files) > 1 {
+				return fmt.Errorf("no section named '%s' in %s", name, strings.Join(files, ", "))
+			}
+			return lastErr
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "🗑️  Removed section '%s' from %s\n", name, strings.Join(removed, ", "))
+	}
+	return nil
+}
+
+// completePromptSections completes the names of managed sections in the
+// command's files
+func (f *CommandFactory) completePromptSections(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	files, err := promptSectionFiles(cmd, f.WorkingDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		doc, err := sections.Load(filepath.Join(f.WorkingDir, file))
+		if err != nil {
+			continue
+		}
+		for _, section := range doc.Sorted() {
+			if !seen[section.Name] {
+				seen[section.Name] = true
+				names = append(names, section.Name)
+			}
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptInject(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n")
+	env.CreateFile(".ddx/library/prompts/common/review.md", "Review {{project_name}} carefully.\n")
+	env.CreateFile(".ddx/library/prompts/claude/focus/README.md", "Stay focused.\n")
+	env.CreateFile(".ddx/variables.yml", "variables:\n  project_name: demo\n")
+	env.CreateFile("CLAUDE.md", "# CLAUDE.md\n\nHouse rules.\n\n<!-- PERSONAS:START -->\npersonas\n<!-- PERSONAS:END -->\n")
+	claudePath := filepath.Join(env.Dir, "CLAUDE.md")
+	read := func() string {
+		data, err := os.ReadFile(claudePath)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	output, err := env.RunCommand("prompt", "inject", "common/review.md")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Injected common/review.md into CLAUDE.md as section 'review'")
+
+	output, err = env.RunCommand("prompt", "inject", "claude/focus", "--order", "10")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "as section 'focus'")
+
+	content := read()
+	assert.True(t, strings.HasPrefix(content, "# CLAUDE.md\n\nHouse rules.\n\n"), "existing content is kept")
+	assert.NotContains(t, content, "PERSONAS:START", "the legacy personas block is migrated")
+	assert.Less(t, strings.Index(content, `name="focus"`), strings.Index(content, `name="personas"`), "a new section goes by its order")
+	assert.Less(t, strings.Index(content, `name="personas"`), strings.Index(content, `name="review"`))
+	assert.Contains(t, content, "Review demo carefully.")
+	assert.Less(t, strings.Index(content, `name="focus"`), strings.Index(content, `name="review"`), "lower orders come first")
+
+	// Injecting again is a no-op
+	output, err = env.RunCommand("prompt", "inject", "common/review.md")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Section 'review' in CLAUDE.md is up to date")
+	assert.Equal(t, content, read())
+
+	// A refresh picks up library changes but keeps hand edits
+	env.CreateFile(".ddx/library/prompts/common/review.md", "Review {{project_name}} twice.\n")
+	env.CreateFile("CLAUDE.md", strings.Replace(content, "Stay focused.", "Stay very focused.", 1))
+	output, err = env.RunCommand("prompt", "inject")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "review updated from common/review.md")
+	assert.Contains(t, output, "focus was edited by hand; skipped")
+	assert.Contains(t, read(), "Review demo twice.")
+	assert.Contains(t, read(), "Stay very focused.")
+
+	_, err = env.RunCommand("prompt", "inject", "claude/focus")
+	assert.ErrorContains(t, err, "section 'focus' in CLAUDE.md was edited by hand")
+	output, err = env.RunCommand("prompt", "inject", "claude/focus", "--force")
+	require.NoError(t, err, output)
+	assert.NotContains(t, read(), "Stay very focused.")
+	assert.Contains(t, read(), `name="focus" source="claude/focus" order="10"`, "the order is kept")
+
+	output, err = env.RunCommand("prompt", "remove", "focus", "review")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Removed section 'focus' from CLAUDE.md")
+	assert.Equal(t, "# CLAUDE.md\n\nHouse rules.\n\n<!-- DDX:START name=\"personas\" order=\"50\" -->\npersonas\n<!-- DDX:END name=\"personas\" -->\n", read())
+
+	_, err = env.RunCommand("prompt", "remove", "review")
+	assert.ErrorContains(t, err, "no section named 'review' in CLAUDE.md")
+
+	t.Run("other files", func(t *testing.T) {
+		output, err := env.RunCommand("prompt", "inject", "common/review.md", "--file", "AGENTS.md", "--name", "code-review")
+		require.NoError(t, err, output)
+		data, err := os.ReadFile(filepath.Join(env.Dir, "AGENTS.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `<!-- DDX:START name="code-review" source="common/review.md"`)
+	})
+
+	t.Run("unknown prompt", func(t *testing.T) {
+		_, err := env.RunCommand("prompt", "inject", "missing")
+		assert.ErrorContains(t, err, "prompt not found: missing")
+	})
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/promptsync"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// SyncedRemoved is reported for synced files that were removed
+const SyncedRemoved = "removed"
+
+// PromptFileSync describes what happened to one synced prompt file. Its
+// Status is one of the Section statuses or SyncedRemoved.
+type PromptFileSync struct {
+	File   string
+	Source string
+	Status string
+}
+
+// syncedPromptFile returns the file a source is synced to, relative to
+// dir: workflow commands go in a directory named after their workflow,
+// which Claude Code shows as the command's namespace
+func syncedPromptFile(dir, source, name string) string {
+	if name == "" {
+		if workflowName, command, ok := strings.Cut(source, ":"); ok {
+			name = workflowName + "/" + command
+		} else {
+			name = promptSectionName(source)
+		}
+	}
+	return path.Join(filepath.ToSlash(dir), name+".md")
+}
+
+// syncedPromptContent renders a source as a command file. The description
+// and argument hint come from the source's frontmatter, or its first
+// heading; placeholders are filled from .ddx/variables.yml. Workflow
+// commands receive their arguments the way 'ddx workflow execute' passes
+// them.
+func syncedPromptContent(workingDir, source string) ([]byte, error) {
+	var content string
+	workflowName, command, isCommand := strings.Cut(source, ":")
+	if isCommand {
+		data, err := os.ReadFile(filepath.Join(workflowLibraryPath(workingDir), "workflows", workflowName, "commands", command+".md"))
+		if err != nil {
+			return nil, fmt.Errorf("command '%s' not found in workflow '%s'", command, workflowName)
+		}
+		content = string(data)
+	} else {
+		promptPath, err := resolvePromptPath(workingDir, source)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = readPrompt(workingDir, promptPath); err != nil {
+			return nil, fmt.Errorf("failed to read prompt %s: %w", source, err)
+		}
+	}
+
+	spec, body, err := workflow.ParseCommandPrompt(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	body, _ = variables.Render(body, registry.Values)
+	if isCommand && !strings.Contains(body, "$ARGUMENTS") {
+		body = strings.TrimRight(body, "\n") + "\n\nCommand Arguments: $ARGUMENTS\n"
+	}
+
+	meta := promptsync.Frontmatter{Description: spec.Description, ArgumentHint: spec.Usage()}
+	if meta.Description == "" {
+		for _, line := range strings.Split(body, "\n") {
+			if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+				meta.Description = heading
+				break
+			}
+		}
+	}
+	return promptsync.Document(meta, body)
+}
+
+// syncPromptFiles writes sources into dir as command files and records
+// them in the manifest. Without sources, every recorded file is refreshed
+// from its source; those edited by hand are left alone unless forced.
+func syncPromptFiles(workingDir, dir string, sources []string, name string, force bool) ([]PromptFileSync, error) {
+	var results []PromptFileSync
+	err := filelock.With(promptsync.Path(workingDir), func() error {
+		results = nil
+		return transaction.Run(workingDir, "prompts sync", func(tx *transaction.Tx) error {
+			manifest, err := promptsync.Load(workingDir)
+			if err != nil {
+				return err
+			}
+			targets := make([]PromptFileSync, 0, len(sources))
+			for _, file := range manifest.Files() {
+				if len(sources) == 0 {
+					targets = append(targets, PromptFileSync{File: file, Source: manifest[file].Source})
+				}
+			}
+			for _, source := range sources {
+				targets = append(targets, PromptFileSync{File: syncedPromptFile(dir, source, name), Source: source})
+			}
+
+			changed := false
+			for _, result := range targets {
+				content, err := syncedPromptContent(workingDir, result.Source)
+				if err != nil && len(sources) > 0 {
+					return err
+				}
+				existing, readErr := os.ReadFile(filepath.Join(workingDir, filepath.FromSlash(result.File)))
+				entry, recorded := manifest[result.File]
+				edited := readErr == nil && (!recorded || promptsync.Checksum(existing) != entry.Checksum)
+				switch {
+				case err != nil:
+					result.Status = SectionMissing
+				case readErr == nil && string(existing) == string(content):
+					result.Status = SectionUnchanged
+				case edited && !force && len(sources) > 0 && !recorded:
+					return fmt.Errorf("%s already exists; use --force to overwrite it", result.File)
+				case edited && !force && len(sources) > 0:
+					return fmt.Errorf("%s was edited by hand; use --force to overwrite it", result.File)
+				case edited && !force:
+					result.Status = SectionModified
+				default:
+					if err := tx.WriteFile(result.File, content, 0644); err != nil {
+						return err
+					}
+					result.Status = SectionUpdated
+				}
+				if result.Status == SectionUpdated || result.Status == SectionUnchanged {
+					next := promptsync.Entry{Source: result.Source, Checksum: promptsync.Checksum(content)}
+					if entry != next {
+						manifest[result.File] = next
+						changed = true
+					}
+				}
+				results = append(results, result)
+			}
+			if !changed {
+				return nil
+			}
+			data, err := manifest.Encode()
+			if err != nil {
+				return err
+			}
+			return tx.WriteFile(promptsync.ManifestFile, data, 0644)
+		})
+	})
+	return results, err
+}
+
+// removePromptFiles deletes synced files matching each of names, by their
+// source, their file or their command name, or every synced file without
+// names. Files edited by hand are kept unless forced.
+func removePromptFiles(workingDir string, names []string, force bool) ([]PromptFileSync, error) {
+	var results []PromptFileSync
+	err := filelock.With(promptsync.Path(workingDir), func() error {
+		results = nil
+		return transaction.Run(workingDir, "prompts sync --remove", func(tx *transaction.Tx) error {
+			manifest, err := promptsync.Load(workingDir)
+			if err != nil {
+				return err
+			}
+			selected := make(map[string]bool)
+			for _, name := range names {
+				found := false
+				for file, entry := range manifest {
+					if name == entry.Source || name == file || name == strings.TrimSuffix(path.Base(file), ".md") {
+						selected[file] = true
+						found = true
+					}
+				}
+				if !found {
+					return fmt.Errorf("no synced prompt matches '%s'", name)
+				}
+			}
+
+			for _, file := range manifest.Files() {
+				if len(names) > 0 && !selected[file] {
+					continue
+				}
+				result := PromptFileSync{File: file, Source: manifest[file].Source, Status: SyncedRemoved}
+				abs := filepath.Join(workingDir, filepath.FromSlash(file))
+				existing, err := os.ReadFile(abs)
+				switch {
+				case errors.Is(err, os.ErrNotExist):
+				case err != nil:
+					return err
+				case promptsync.Checksum(existing) != manifest[file].Checksum && !force:
+					result.Status = SectionModified
+					results = append(results, result)
+					continue
+				default:
+					if err := tx.Track(abs); err != nil {
+						return err
+					}
+					if err := os.Remove(abs); err != nil {
+						return err
+					}
+				}
+				delete(manifest, file)
+				results = append(results, result)
+			}
+
+			if err := tx.Track(promptsync.ManifestFile); err != nil {
+				return err
+			}
+			if len(manifest) == 0 {
+				if err := os.Remove(promptsync.Path(workingDir)); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				return nil
+			}
+			data, err := manifest.Encode()
+			if err != nil {
+				return err
+			}
+			return tx.WriteFile(promptsync.ManifestFile, data, 0644)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Directories are emptied only once the backups beside the removed
+	// files are gone
+	for _, r := range results {
+		if r.Status == SyncedRemoved {
+			removeEmptyDirs(workingDir, filepath.Dir(filepath.Join(workingDir, filepath.FromSlash(r.File))))
+		}
+	}
+	return results, nil
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty,
+// stopping at the project root
+func removeEmptyDirs(workingDir, dir string) {
+	root := filepath.Clean(workingDir)
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// runPromptSync implements the prompts sync command
+func (f *CommandFactory) runPromptSync(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	name, _ := cmd.Flags().GetString("name")
+	force, _ := cmd.Flags().GetBool("force")
+	remove, _ := cmd.Flags().GetBool("remove")
+	cmd.SilenceUsage = true
+	if len(args) > 1 && name != "" {
+		return fmt.Errorf("--name can only be used with a single prompt")
+	}
+
+	var results []PromptFileSync
+	var err error
+	if remove {
+		results, err = removePromptFiles(f.WorkingDir, args, force)
+	} else {
+		results, err = syncPromptFiles(f.WorkingDir, dir, args, name, force)
+	}
+	if err != nil {
+		return transactionError(err)
+	}
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No synced prompts; add one with 'ddx prompts sync <prompt>'")
+		return nil
+	}
+	printPromptFileSyncs(cmd.OutOrStdout(), results)
+	return nil
+}
+
+// printPromptFileSyncs reports what happened to each synced file
+func printPromptFileSyncs(out io.Writer, results []PromptFileSync) {
+	for _, r := range results {
+		switch r.Status {
+		case SectionUpdated:
+			_, _ = fmt.Fprintf(out, "✅ Synced %s to %s\n", r.Source, r.File)
+		case SectionUnchanged:
+			_, _ = fmt.Fprintf(out, "✓ %s is up to date\n", r.File)
+		case SectionModified:
+			_, _ = fmt.Fprintf(out, "⚠️  %s was edited by hand; skipped (use --force)\n", r.File)
+		case SectionMissing:
+			_, _ = fmt.Fprintf(out, "⚠️  %s: source '%s' not found\n", r.File, r.Source)
+		case SyncedRemoved:
+			_, _ = fmt.Fprintf(out, "🗑️  Removed %s\n", r.File)
+		}
+	}
+}
+
+// completeSyncedPrompts completes the sources of synced files for --remove
+func (f *CommandFactory) completeSyncedPrompts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if remove, _ := cmd.Flags().GetBool("remove"); !remove {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	manifest, err := promptsync.Load(f.WorkingDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var sources []string
+	for _, file := range manifest.Files() {
+		if strings.HasPrefix(manifest[file].Source, toComplete) {
+			sources = append(sources, manifest[file].Source+"\t"+file)
+		}
+	}
+	return sources, cobra.ShellCompDirectiveNoFileComp
+}
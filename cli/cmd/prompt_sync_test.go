@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptSync(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n")
+	env.CreateFile(".ddx/library/prompts/common/review.md", "# Code Review\n\nReview {{project_name}} carefully.\n")
+	env.CreateFile(".ddx/library/workflows/helix/commands/build-story.md", `---
+description: Implement a user story
+args:
+  - name: story-id
+    required: true
+    pattern: US-[0-9]+
+---
+# Build Story
+
+Implement the story.
+`)
+	env.CreateFile(".ddx/variables.yml", "variables:\n  project_name: demo\n")
+	read := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, file))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	output, err := env.RunCommand("prompts", "sync", "common/review.md", "helix:build-story")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Synced common/review.md to .claude/commands/review.md")
+	assert.Contains(t, output, "Synced helix:build-story to .claude/commands/helix/build-story.md")
+	assert.Equal(t, "---\ndescription: Code Review\n---\n# Code Review\n\nReview demo carefully.\n", read(".claude/commands/review.md"))
+	assert.Equal(t, "---\ndescription: Implement a user story\nargument-hint: <story-id>\n---\n# Build Story\n\nImplement the story.\n\nCommand Arguments: $ARGUMENTS\n",
+		read(".claude/commands/helix/build-story.md"))
+	assert.Contains(t, read(".ddx/prompt-sync.json"), `"source": "helix:build-story"`)
+
+	// A refresh picks up library changes but keeps hand edits
+	env.CreateFile(".ddx/library/prompts/common/review.md", "# Code Review\n\nReview {{project_name}} twice.\n")
+	env.CreateFile(".claude/commands/helix/build-story.md", "My own version.\n")
+	output, err = env.RunCommand("prompts", "sync")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Synced common/review.md to .claude/commands/review.md")
+	assert.Contains(t, output, ".claude/commands/helix/build-story.md was edited by hand; skipped")
+	assert.Contains(t, read(".claude/commands/review.md"), "Review demo twice.")
+	assert.Equal(t, "My own version.\n", read(".claude/commands/helix/build-story.md"))
+
+	_, err = env.RunCommand("prompts", "sync", "helix:build-story")
+	assert.ErrorContains(t, err, ".claude/commands/helix/build-story.md was edited by hand")
+
+	output, err = env.RunCommand("prompts", "sync", "--remove")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Removed .claude/commands/review.md")
+	assert.Contains(t, output, "build-story.md was edited by hand; skipped")
+	assert.NoFileExists(t, filepath.Join(env.Dir, ".claude/commands/review.md"))
+
+	output, err = env.RunCommand("prompts", "sync", "--remove", "build-story", "--force")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Removed .claude/commands/helix/build-story.md")
+	assert.NoDirExists(t, filepath.Join(env.Dir, ".claude"), "emptied directories are removed")
+	assert.NoFileExists(t, filepath.Join(env.Dir, ".ddx/prompt-sync.json"))
+
+	t.Run("existing files are not overwritten", func(t *testing.T) {
+		env.CreateFile(".claude/commands/review.md", "Mine.\n")
+		_, err := env.RunCommand("prompts", "sync", "common/review.md")
+		assert.ErrorContains(t, err, ".claude/commands/review.md already exists")
+		assert.Equal(t, "Mine.\n", read(".claude/commands/review.md"))
+	})
+
+	t.Run("unknown sources", func(t *testing.T) {
+		_, err := env.RunCommand("prompts", "sync", "missing")
+		assert.ErrorContains(t, err, "prompt not found: missing")
+		_, err = env.RunCommand("prompts", "sync", "--remove", "missing")
+		assert.ErrorContains(t, err, "no synced prompt matches 'missing'")
+	})
+}
@@ -2,11 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/match"
+	"github.com/easel/ddx/internal/prompts"
+	"github.com/easel/ddx/internal/variables"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +39,10 @@ func runPromptsList(cmd *cobra.Command, args []string) error {
 	}
 
 	promptsDir := filepath.Join(libPath, "prompts")
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return err
+	}
 
 	// Check if prompts directory exists
 	if _, err := os.Stat(promptsDir); os.IsNotExist(err) {
@@ -44,6 +54,14 @@ func runPromptsList(cmd *cobra.Command, args []string) error {
 	searchFilter, _ := cmd.Flags().GetString("search")
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
+	var searchMatcher *match.Matcher
+	if searchFilter != "" {
+		searchMatcher, err = match.Compile(searchFilter)
+		if err != nil {
+			return fmt.Errorf("invalid search pattern: %w", err)
+		}
+	}
+
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Available prompts:")
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 
@@ -73,7 +91,12 @@ func runPromptsList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Apply search filter if provided
-		if searchFilter != "" && !strings.Contains(strings.ToLower(path), strings.ToLower(searchFilter)) {
+		if searchMatcher != nil && !searchMatcher.Match(relPath) {
+			return nil
+		}
+
+		// Hide prompts outside library.include/exclude
+		if !sel.Selected("prompts/" + filepath.ToSlash(relPath)) {
 			return nil
 		}
 
@@ -98,51 +121,265 @@ func runPromptsList(cmd *cobra.Command, args []string) error {
 }
 
 // runPromptsShow implements the prompts show command
-func runPromptsShow(cmd *cobra.Command, args []string) error {
-	promptName := args[0]
+func runPromptsShow(cmd *cobra.Command, args []string, workingDir string) error {
+	promptPath, err := resolvePromptPath(workingDir, args[0])
+	if err != nil {
+		return err
+	}
 
-	// Get working directory from command factory context
-	workingDir := "."
-	if factory, ok := cmd.Context().Value("factory").(*CommandFactory); ok {
-		workingDir = factory.WorkingDir
+	// Read and display the prompt
+	content, err := readPrompt(workingDir, promptPath)
+	if err != nil {
+		return err
 	}
 
-	// Get library path using working directory
+	rendered, err := renderWithVariables(cmd, workingDir, content)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), rendered)
+	return nil
+}
+
+// runPromptsRender prints a prompt with its includes expanded, or with
+// --includes the prompts it includes
+func runPromptsRender(cmd *cobra.Command, args []string, workingDir string) error {
+	showIncludes, _ := cmd.Flags().GetBool("includes")
+	out := cmd.OutOrStdout()
+
+	promptPath := args[0]
+	if !filepath.IsAbs(promptPath) {
+		promptPath = filepath.Join(workingDir, promptPath)
+	}
+	if info, err := os.Stat(promptPath); err != nil || info.IsDir() {
+		if promptPath, err = resolvePromptPath(workingDir, args[0]); err != nil {
+			return err
+		}
+	}
+
+	if showIncludes {
+		tree, err := prompts.Tree(promptPath, promptLookup(workingDir))
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(out, promptPath)
+		printIncludes(out, tree, "  ")
+		return nil
+	}
+
+	content, err := readPrompt(workingDir, promptPath)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderWithVariables(cmd, workingDir, content)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(out, rendered)
+	return nil
+}
+
+// printIncludes prints an include tree, one prompt per line
+func printIncludes(out io.Writer, tree []prompts.Include, indent string) {
+	for _, include := range tree {
+		_, _ = fmt.Fprintf(out, "%s%s (%s)\n", indent, include.Name, include.Path)
+		printIncludes(out, include.Includes, indent+"  ")
+	}
+}
+
+// readPrompt reads a prompt file, expanding its {{> name}} includes from
+// the project's prompts
+func readPrompt(workingDir, path string) (string, error) {
+	return prompts.ExpandFile(path, promptLookup(workingDir))
+}
+
+// promptLookup resolves include names the way prompts are resolved by name
+func promptLookup(workingDir string) prompts.Lookup {
+	return func(name string) (string, error) {
+		return resolvePromptPath(workingDir, name)
+	}
+}
+
+// resolvePromptPath finds a prompt by name, with or without the .md
+// extension, or as a directory with a README.md. Project overrides in
+// .ddx/prompts take precedence over the library.
+func resolvePromptPath(workingDir, promptName string) (string, error) {
+	r, err := projectResolver(workingDir)
+	if err != nil {
+		return "", err
+	}
+	match, err := r.Resolve("prompts", promptName)
+	if err != nil {
+		return "", err
+	}
+	if !match.Found() {
+		return "", fmt.Errorf("prompt not found: %s", promptName)
+	}
+	return match.Path, nil
+}
+
+// runPromptShortcut renders the prompt behind a shortcut from the
+// project's prompts configuration, or lists the shortcuts
+func runPromptShortcut(cmd *cobra.Command, args []string, workingDir string) error {
+	if len(args) == 0 {
+		return listPromptShortcuts(cmd, workingDir)
+	}
+	copyFlag, _ := cmd.Flags().GetBool("copy")
+	// Lookup failures are about the alias, not how the command was used
+	cmd.SilenceUsage = true
+
+	promptPath, err := resolvePromptShortcut(workingDir, args[0])
+	if err != nil {
+		return err
+	}
+	content, err := readPrompt(workingDir, promptPath)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderWithVariables(cmd, workingDir, content)
+	if err != nil {
+		return err
+	}
+
+	if !copyFlag {
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), rendered)
+		return nil
+	}
+	if err := copyToClipboard(rendered); err != nil {
+		return fmt.Errorf("failed to copy prompt: %w", err)
+	}
+	lines, unit := strings.Count(strings.TrimRight(rendered, "\n"), "\n")+1, "lines"
+	if lines == 1 {
+		unit = "line"
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "📋 Copied %s to the clipboard (%d %s)\n", args[0], lines, unit)
+	return nil
+}
+
+// listPromptShortcuts prints the configured shortcuts and their files
+func listPromptShortcuts(cmd *cobra.Command, workingDir string) error {
 	cfg, err := config.LoadWithWorkingDir(workingDir)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	out := cmd.OutOrStdout()
+	if len(cfg.Prompts) == 0 {
+		_, _ = fmt.Fprintln(out, "No prompt shortcuts configured")
+		_, _ = fmt.Fprintln(out, "Add one with 'ddx config set prompts.<alias> <path>'")
+		return nil
+	}
 
-	var libPath string
-	if cfg.Library != nil {
-		libPath = cfg.Library.Path
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, alias := range promptAliases(cfg) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", alias, cfg.Prompts[alias])
 	}
+	return w.Flush()
+}
 
-	// Try different paths for the prompt
-	possiblePaths := []string{
-		filepath.Join(libPath, "prompts", promptName+".md"),
-		filepath.Join(libPath, "prompts", promptName),
-		filepath.Join(libPath, "prompts", promptName, "README.md"),
+// promptAliases returns the configured shortcut names in sorted order
+func promptAliases(cfg *config.Config) []string {
+	aliases := make([]string, 0, len(cfg.Prompts))
+	for alias := range cfg.Prompts {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// resolvePromptShortcut finds the file behind a prompt shortcut. Targets
+// are resolved against the library, then the project, then as a library
+// prompt name. Names that are not shortcuts are looked up as library
+// prompts so that 'ddx p' also works for prompts without a shortcut.
+func resolvePromptShortcut(workingDir, alias string) (string, error) {
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
 	}
 
-	var promptPath string
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			promptPath = path
-			break
+	target, ok := cfg.Prompts[alias]
+	if !ok {
+		if path, err := resolvePromptPath(workingDir, alias); err == nil {
+			return path, nil
 		}
+		if len(cfg.Prompts) == 0 {
+			return "", fmt.Errorf("unknown prompt shortcut %q; add one with 'ddx config set prompts.%s <path>'", alias, alias)
+		}
+		return "", fmt.Errorf("unknown prompt shortcut %q (available: %s)", alias, strings.Join(promptAliases(cfg), ", "))
 	}
 
-	if promptPath == "" {
-		return fmt.Errorf("prompt not found: %s", promptName)
+	candidates := []string{target}
+	if !filepath.IsAbs(target) {
+		libPath := ""
+		if cfg.Library != nil {
+			libPath = cfg.Library.Path
+		}
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(workingDir, libPath)
+		}
+		candidates = []string{filepath.Join(libPath, target), filepath.Join(workingDir, target)}
 	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	if path, err := resolvePromptPath(workingDir, target); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("prompt shortcut %s points to %s, which was not found in the library or project", alias, target)
+}
 
-	// Read and display the prompt
-	content, err := os.ReadFile(promptPath)
-	if err != nil {
-		return fmt.Errorf("failed to read prompt: %w", err)
+// completePromptShortcuts completes shortcut names with their targets
+func completePromptShortcuts(workingDir string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.LoadWithWorkingDir(workingDir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []string
+		for _, alias := range promptAliases(cfg) {
+			if strings.HasPrefix(alias, toComplete) {
+				completions = append(completions, alias+"\t"+cfg.Prompts[alias])
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
 	}
+}
 
-	_, _ = fmt.Fprint(cmd.OutOrStdout(), string(content))
-	return nil
+// completePromptVariables completes --var with the placeholders of the
+// prompt named by the first argument, offering recorded values
+func completePromptVariables(workingDir string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		path, err := resolvePromptShortcut(workingDir, args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		content, err := readPrompt(workingDir, path)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		registry, err := variables.Load(workingDir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var completions []string
+		for _, name := range variables.Placeholders(content) {
+			completion := name + "="
+			if value, ok := registry.Get(name); ok {
+				completion += value
+			}
+			if strings.HasPrefix(completion, toComplete) {
+				completions = append(completions, completion)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
 }
@@ -276,6 +276,9 @@ persona_bindings: {}`
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			// The cases write .ddx relative to the working directory, so
+			// each runs in its own empty project
+			t.Chdir(t.TempDir())
 			cleanup := tt.setup(t)
 			defer cleanup()
 
@@ -311,3 +314,130 @@ func TestPromptsCommand_Help(t *testing.T) {
 	assert.Contains(t, output, "list")
 	assert.Contains(t, output, "show")
 }
+
+func TestPromptsShow_Variables(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateDefaultConfig()
+	promptsDir := filepath.Join(env.LibraryPath, "prompts")
+	require.NoError(t, os.MkdirAll(promptsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "readme.md"),
+		[]byte("# {{project_name}}\nModule: {{ module_path }}\nToken: {{api_token}}\n"), 0644))
+
+	// First render records the values given with --var, except secrets
+	output, err := env.RunCommand("prompts", "show", "readme",
+		"--var", "project_name=demo", "--var", "module_path=example.com/demo", "--var", "api_token=s3cret")
+	require.NoError(t, err)
+	assert.Contains(t, output, "# demo")
+	assert.Contains(t, output, "Module: example.com/demo")
+	assert.Contains(t, output, "Token: s3cret")
+
+	content, err := os.ReadFile(filepath.Join(env.Dir, ".ddx", "variables.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "module_path: example.com/demo")
+	assert.NotContains(t, string(content), "s3cret")
+
+	// Later renders reuse the recorded values
+	output, err = env.RunCommand("prompts", "show", "readme")
+	require.NoError(t, err)
+	assert.Contains(t, output, "# demo")
+	assert.Contains(t, output, "Module: example.com/demo")
+	assert.Contains(t, output, "Token: {{api_token}}")
+	assert.Contains(t, output, "Unset variables: api_token")
+
+	// --no-record leaves the registry untouched
+	_, err = env.RunCommand("prompts", "show", "readme", "--var", "project_name=other", "--no-record")
+	require.NoError(t, err)
+	content, err = os.ReadFile(filepath.Join(env.Dir, ".ddx", "variables.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "project_name: demo")
+}
+
+func TestPromptsRender_Includes(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateDefaultConfig()
+	promptsDir := filepath.Join(env.LibraryPath, "prompts")
+	require.NoError(t, os.MkdirAll(filepath.Join(promptsDir, "common"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "common", "security-guidelines.md"),
+		[]byte("Never log secrets in {{language}}.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "review.md"),
+		[]byte("# Review\n\n{{> common/security-guidelines}}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "loop.md"), []byte("{{> loop}}\n"), 0644))
+
+	output, err := env.RunCommand("prompts", "render", "review", "--var", "language=go", "--no-record")
+	require.NoError(t, err)
+	assert.Equal(t, "# Review\n\nNever log secrets in go.\n", output)
+
+	output, err = env.RunCommand("prompts", "render", "review", "--includes")
+	require.NoError(t, err)
+	assert.Contains(t, output, "  common/security-guidelines (")
+
+	// Includes are expanded wherever prompts are read
+	output, err = env.RunCommand("prompts", "show", "review", "--var", "language=go", "--no-record")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Never log secrets in go.")
+
+	_, err = env.RunCommand("prompts", "render", "loop")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle")
+}
+
+func TestPromptShortcut(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+prompts:
+  review: prompts/code-review/strict.md
+  plan: docs/planning.md
+  broken: prompts/missing.md
+`)
+	promptsDir := filepath.Join(env.LibraryPath, "prompts", "code-review")
+	require.NoError(t, os.MkdirAll(promptsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "strict.md"),
+		[]byte("Review {{project_name}} strictly.\n"), 0644))
+	env.CreateFile("docs/planning.md", "Plan the next release.\n")
+	env.CreateFile(".ddx/variables.yml", "variables:\n  project_name: demo\n")
+
+	output, err := env.RunCommand("p")
+	require.NoError(t, err)
+	assert.Contains(t, output, "review  prompts/code-review/strict.md")
+
+	// Library targets are rendered with recorded variables
+	output, err = env.RunCommand("p", "review")
+	require.NoError(t, err)
+	assert.Equal(t, "Review demo strictly.\n", output)
+
+	// Targets may also be project files
+	output, err = env.RunCommand("p", "plan")
+	require.NoError(t, err)
+	assert.Equal(t, "Plan the next release.\n", output)
+
+	var copied string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	t.Cleanup(func() { copyToClipboard = original })
+
+	output, err = env.RunCommand("p", "review", "--copy", "--var", "project_name=other", "--no-record")
+	require.NoError(t, err)
+	assert.Equal(t, "Review other strictly.\n", copied)
+	assert.Contains(t, output, "Copied review to the clipboard (1 line)")
+
+	_, err = env.RunCommand("p", "broken")
+	assert.ErrorContains(t, err, "points to prompts/missing.md")
+	_, err = env.RunCommand("p", "nope")
+	assert.ErrorContains(t, err, "available: broken, plan, review")
+
+	t.Run("completion", func(t *testing.T) {
+		output, err := env.RunCommand("__complete", "p", "re")
+		require.NoError(t, err)
+		assert.Contains(t, output, "review\tprompts/code-review/strict.md")
+		assert.NotContains(t, output, "plan")
+
+		output, err = env.RunCommand("__complete", "p", "review", "--var", "")
+		require.NoError(t, err)
+		assert.Contains(t, output, "project_name=demo")
+	})
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/onboarding"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/easel/ddx/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// QuickstartStep is one step of the first-run guide
+type QuickstartStep struct {
+	ID      string
+	Title   string
+	Command string
+	// Help explains the step when it is the next one
+	Help string
+	Done bool
+}
+
+// welcomeSkip are the commands that never show the first-run welcome
+var welcomeSkip = map[string]bool{
+	"quickstart": true, "version": true, "completion": true, "help": true,
+	cobra.ShellCompRequestCmd: true, cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// quickstartSteps returns the steps of the guide, each marked done when
+// the project or machine shows it was carried out
+func quickstartSteps(workingDir string) []QuickstartStep {
+	_, inPath := lookPathBinary()
+	project := workspace.IsProject(workingDir)
+	steps := []QuickstartStep{
+		{
+			ID: "install", Title: "Verify the installation", Command: "ddx doctor",
+			Help: "Check that ddx is on your PATH and git is installed.",
+			Done: inPath && checkGit(),
+		},
+		{
+			ID: "init", Title: "Initialize a project", Command: "ddx init",
+			Help: "Run this in your repository to create .ddx/config.yaml and add the library.",
+			Done: project,
+		},
+		{
+			ID: "persona", Title: "Bind a persona to a role", Command: "ddx persona bind code-reviewer strict-code-reviewer",
+			Help: "Personas give AI assistants a consistent character; 'ddx persona list' shows them.",
+		},
+		{
+			ID: "workflow", Title: "Run a workflow command", Command: "ddx workflow activate helix && ddx workflow helix commands",
+			Help: "Activate a workflow, then run one of its commands with 'ddx workflow helix execute <command>'.",
+		},
+	}
+	if !project {
+		return steps
+	}
+	if cfg, err := loadPersonaConfig(workingDir); err == nil && len(cfg.PersonaBindings) > 0 {
+		steps[2].Done = true
+	}
+	if cfg, err := loadConfigFrom(workingDir); err == nil && cfg != nil {
+		for _, name := range cfg.Workflows.Active {
+			if _, err := os.Stat(filepath.Join(workingDir, workflow.StateFile(name))); err != nil {
+				continue
+			}
+			state, err := workflow.LoadStateFrom(workingDir, name)
+			if err != nil {
+				continue
+			}
+			for _, entry := range state.Audit {
+				if entry.Action == workflow.AuditExecute {
+					steps[3].Done = true
+				}
+			}
+		}
+	}
+	return steps
+}
+
+// runQuickstart implements the quickstart command
+func (f *CommandFactory) runQuickstart(cmd *cobra.Command, args []string) error {
+	file, err := onboarding.Path()
+	if err != nil {
+		return err
+	}
+	progress, err := onboarding.Load(file)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if reset, _ := cmd.Flags().GetBool("reset"); reset {
+		progress.Reset()
+		if err := progress.Save(); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(out, "Onboarding progress reset")
+		return nil
+	}
+
+	now := f.now()
+	steps := quickstartSteps(f.WorkingDir)
+	done := 0
+	var next *QuickstartStep
+	for i := range steps {
+		if steps[i].Done {
+			progress.Complete(steps[i].ID, now)
+		}
+		steps[i].Done = progress.Done(steps[i].ID)
+		if steps[i].Done {
+			done++
+		} else if next == nil {
+			next = &steps[i]
+		}
+	}
+	if progress.Welcomed == nil {
+		progress.Welcome(now)
+	}
+	if err := progress.Save(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "🚀 DDx quickstart (%d of %d done)\n\n", done, len(steps))
+	for i, step := range steps {
+		mark := "⬜"
+		switch {
+		case step.Done:
+			mark = "✅"
+		case next != nil && step.ID == next.ID:
+			mark = "➡️ "
+		}
+		_, _ = fmt.Fprintf(out, "  %s %d. %-26s %s\n", mark, i+1, step.Title, step.Command)
+	}
+	if next == nil {
+		_, _ = fmt.Fprintln(out, "\n🎉 You're all set. Run 'ddx --help' to see everything ddx can do.")
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "\nNext: %s\n  %s\n", next.Help, next.Command)
+	return nil
+}
+
+// firstRun reports whether this looks like the user's first run of ddx:
+// no project at or above the working directory, no global configuration,
+// and no welcome shown before
+func firstRun(workingDir string, progress *onboarding.Progress) bool {
+	if progress.Welcomed != nil || workspace.IsProject(workingDir) {
+		return false
+	}
+	global, err := config.GlobalConfigPath()
+	return err == nil && !fileExists(global)
+}
+
+// welcomeFirstRun points a new user at 'ddx quickstart', once. It only
+// speaks to a terminal, so scripts never see it.
+func (f *CommandFactory) welcomeFirstRun(cmd *cobra.Command) {
+	if welcomeSkip[cmd.Name()] || quiet(cmd) || !isTerminal(cmd.ErrOrStderr()) {
+		return
+	}
+	file, err := onboarding.Path()
+	if err != nil {
+		return
+	}
+	progress, err := onboarding.Load(file)
+	if err != nil || !firstRun(f.WorkingDir, progress) {
+		return
+	}
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), `👋 Welcome to DDx! This looks like your first run.
+   'ddx quickstart' walks you through checking the install, setting up a
+   project, binding a persona and running your first workflow command.`)
+	progress.Welcome(f.now())
+	if err := progress.Save(); err != nil {
+		slog.Debug("could not record the first-run welcome", "error", err)
+	}
+}
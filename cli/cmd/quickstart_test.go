@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/onboarding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickstart(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	env := NewTestEnvironment(t, WithGitInit(false))
+
+	output, err := env.RunCommand("quickstart")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "DDx quickstart")
+	assert.Contains(t, output, "2. Initialize a project")
+	assert.Contains(t, output, "⬜ 3. Bind a persona to a role")
+
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+workflows:
+  active: [helix]
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+`)
+	env.CreateFile(".helix-state.yml", `workflow: helix
+current_phase: frame
+audit:
+  - time: "2026-10-17T09:00:00Z"
+    action: execute
+    command: frame-request
+`)
+	output, err = env.RunCommand("quickstart")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "✅ 2. Initialize a project")
+	assert.Contains(t, output, "✅ 3. Bind a persona to a role")
+	assert.Contains(t, output, "✅ 4. Run a workflow command")
+
+	progress, err := onboarding.Load(filepath.Join(home, ".local", "share", "ddx", onboarding.FileName))
+	require.NoError(t, err)
+	assert.NotNil(t, progress.Welcomed)
+	assert.True(t, progress.Done("persona"))
+
+	output, err = env.RunCommand("quickstart", "--reset")
+	require.NoError(t, err, output)
+	progress, err = onboarding.Load(filepath.Join(home, ".local", "share", "ddx", onboarding.FileName))
+	require.NoError(t, err)
+	assert.Nil(t, progress.Welcomed)
+	assert.False(t, progress.Done("persona"))
+}
+
+func TestFirstRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+
+	progress, err := onboarding.Load(filepath.Join(home, ".ddx", onboarding.FileName))
+	require.NoError(t, err)
+	assert.True(t, firstRun(dir, progress))
+
+	progress.Welcome(time.Now())
+	assert.False(t, firstRun(dir, progress), "the welcome is shown once")
+
+	progress.Reset()
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	assert.False(t, firstRun(env.Dir, progress), "not in a project")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/easel/ddx/internal/release"
+	"github.com/spf13/cobra"
+)
+
+// runReleaseManifest implements the release manifest command. Checksums
+// come from a local checksums file when the release is being built, or
+// from the published release otherwise.
+func runReleaseManifest(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+	checksumsFile, _ := cmd.Flags().GetString("checksums")
+	output, _ := cmd.Flags().GetString("output")
+	formatNames, _ := cmd.Flags().GetStringSlice("format")
+
+	formats := release.ManifestFormats
+	if len(formatNames) > 0 {
+		formats = nil
+		for _, name := range formatNames {
+			format, err := release.ParseManifestFormat(name)
+			if err != nil {
+				return NewExitError(2, err.Error())
+			}
+			formats = append(formats, format)
+		}
+	}
+
+	var checksums map[string]string
+	if checksumsFile != "" {
+		data, err := os.ReadFile(checksumsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read checksums: %w", err)
+		}
+		checksums = release.ParseChecksums(data)
+	} else {
+		downloader, err := newReleaseDownloader()
+		if err != nil {
+			return err
+		}
+		tag := version
+		if tag != "" && tag[0] != 'v' {
+			tag = "v" + tag
+		}
+		if checksums, err = downloader.FetchChecksums(cmd.Context(), tag); err != nil {
+			return fmt.Errorf("failed to fetch release checksums: %w", err)
+		}
+	}
+
+	info, err := release.NewReleaseInfo(version, release.BaseURL(), checksums)
+	if err != nil {
+		return err
+	}
+	written, err := info.GenerateManifests(output, formats)
+	out := cmd.OutOrStdout()
+	for _, path := range written {
+		_, _ = fmt.Fprintf(out, "📦 %s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "✅ Generated manifests for ddx %s\n", info.Version)
+	return nil
+}
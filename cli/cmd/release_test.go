@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReleaseManifest tests generating package manager manifests from a
+// local checksums file
+func TestReleaseManifest(t *testing.T) {
+	dir := t.TempDir()
+	checksums := filepath.Join(dir, release.ChecksumsFile)
+	var lines string
+	for _, p := range release.Platforms() {
+		lines += fmt.Sprintf("%064x  %s\n", len(lines), p.ArchiveName())
+	}
+	require.NoError(t, os.WriteFile(checksums, []byte(lines), 0644))
+	output := filepath.Join(dir, "packaging")
+
+	out, err := executeCommand(NewCommandFactory(dir).NewRootCommand(), "release", "manifest",
+		"--version", "v2.0.1", "--checksums", checksums, "--output", output, "--format", "brew,scoop")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Generated manifests for ddx 2.0.1")
+	assert.FileExists(t, filepath.Join(output, "homebrew", "ddx.rb"))
+	assert.FileExists(t, filepath.Join(output, "scoop", "ddx.json"))
+	assert.NoDirExists(t, filepath.Join(output, "chocolatey"))
+
+	_, err = executeCommand(NewCommandFactory(dir).NewRootCommand(), "release", "manifest",
+		"--version", "v2.0.1", "--checksums", checksums, "--format", "apt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown package manager")
+}
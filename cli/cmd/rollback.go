@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/snapshot"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/spf13/cobra"
+)
+
+// takeSnapshot records the project state before an operation changes it
+func takeSnapshot(workingDir string, cfg *config.Config, operation string) (*snapshot.Snapshot, error) {
+	libPath := cfg.Library.Path
+	if filepath.IsAbs(libPath) {
+		rel, err := filepath.Rel(workingDir, libPath)
+		if err != nil {
+			return nil, err
+		}
+		libPath = rel
+	}
+	list, _, err := aiTargets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Create(workingDir, libPath, operation, projectLibraryRevision(workingDir, cfg), targets.Files(list))
+}
+
+// describeSnapshot summarizes a snapshot in one line
+func describeSnapshot(s *snapshot.Snapshot) string {
+	line := fmt.Sprintf("%s  %s  before %-14s %d asset(s)", s.ID, s.Created.Local().Format("2006-01-02 15:04"), s.Operation, len(s.Assets))
+	if s.Library.Commit != "" {
+		line += " at " + shortCommit(s.Library.Commit)
+	}
+	return line
+}
+
+// runRollback implements the rollback command
+func (f *CommandFactory) runRollback(cmd *cobra.Command, args []string) error {
+	list, _ := cmd.Flags().GetBool("list")
+	to, _ := cmd.Flags().GetString("to")
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+
+	snapshots, err := snapshot.List(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if list {
+		if len(snapshots) == 0 {
+			_, _ = fmt.Fprintln(out, "No snapshots yet; one is taken before each 'ddx update' and 'ddx prompts inject'")
+			return nil
+		}
+		_, _ = fmt.Fprintln(out, "Snapshots (newest first):")
+		for _, s := range snapshots {
+			_, _ = fmt.Fprintf(out, "  %s\n", describeSnapshot(s))
+		}
+		return nil
+	}
+
+	var target *snapshot.Snapshot
+	switch {
+	case to != "":
+		if target, err = snapshot.Load(f.WorkingDir, to); err != nil {
+			return err
+		}
+	case len(snapshots) > 0:
+		target = snapshots[0]
+	default:
+		return fmt.Errorf("no snapshots to roll back to")
+	}
+
+	if err := target.Restore(f.WorkingDir); err != nil {
+		return fmt.Errorf("rollback to %s failed: %w", target.ID, err)
+	}
+	problems, err := target.Verify(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return NewExitError(1, fmt.Sprintf("rollback to %s left %d difference(s): %v", target.ID, len(problems), problems))
+	}
+
+	// Regenerate the meta-prompt from the restored library and config
+	if cfg, err := config.LoadWithWorkingDir(f.WorkingDir); err == nil && os.Getenv("CI") == "" {
+		if _, statErr := os.Stat(filepath.Join(projectLibraryDir(f.WorkingDir, cfg), "prompts")); statErr == nil {
+			if err := syncMetaPrompt(cfg, f.WorkingDir); err != nil {
+				slog.Warn("failed to sync meta-prompt", "error", err)
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "⏪ Rolled back to snapshot %s (before %s)\n", target.ID, target.Operation)
+	_, _ = fmt.Fprintf(out, "✅ Verified %d library file(s), %d project file(s) and %d AI target section(s)\n",
+		len(target.Assets), len(target.Files), target.SectionCount())
+	return nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackCommand(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+
+	output, err := env.RunCommand("rollback", "--list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "No snapshots yet")
+	_, err = env.RunCommand("rollback")
+	assert.EqualError(t, err, "no snapshots to roll back to")
+
+	output, err = env.RunCommand("update")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "run 'ddx rollback' to undo this update")
+
+	// Simulate what the update brought in
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Changed upstream\n")
+	env.CreateFile(".ddx/library/personas/extra.md", "# Extra\n")
+
+	output, err = env.RunCommand("rollback", "--list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "before update")
+
+	output, err = env.RunCommand("rollback")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Rolled back to snapshot")
+	assert.Contains(t, output, "Verified 1 library file(s)")
+	data, err := os.ReadFile(filepath.Join(env.Dir, ".ddx", "library", "personas", "reviewer.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Reviewer\n", string(data))
+	assert.NoFileExists(t, filepath.Join(env.Dir, ".ddx", "library", "personas", "extra.md"))
+
+	_, err = env.RunCommand("rollback", "--to", "20000101-000000")
+	assert.EqualError(t, err, "snapshot 20000101-000000 not found")
+	list, err := snapshot.List(env.Dir)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/easel/ddx/internal/exitcodes"
 	"github.com/spf13/cobra"
 )
 
@@ -27,14 +31,31 @@ Document-Driven Development eXperience
 // Tests should use NewRootCommand() from command_factory.go instead.
 var rootCmd *cobra.Command
 
+// rootFactory built rootCmd
+var rootFactory *CommandFactory
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute(workingDir string) error {
 	// Initialize the global root command for the main executable
 	if rootCmd == nil {
-		factory := NewCommandFactory(workingDir)
-		rootCmd = factory.NewRootCommand()
+		migrateUserDirs(os.Stderr)
+		rootFactory = NewCommandFactory(workingDir)
+		rootCmd = rootFactory.NewRootCommandFor(os.Args[1:])
+	}
+
+	// Ctrl-C cancels the command's context so downloads, git and
+	// subprocesses stop and clean up; a second one kills ddx outright
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	err := rootCmd.ExecuteContext(ctx)
+	if rootFactory != nil {
+		rootFactory.reportTimings(rootCmd)
+	}
+	if err != nil && ctx.Err() != nil {
+		return NewExitError(exitcodes.Interrupted, "interrupted")
 	}
-	return rootCmd.Execute()
+	return err
 }
 
 // Helper functions for other commands
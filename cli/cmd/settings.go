@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/settings"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsPassphraseEnv supplies the settings passphrase without a prompt
+const settingsPassphraseEnv = "DDX_SETTINGS_PASSPHRASE"
+
+// settingsRemote returns the repository and branch settings are synced
+// through, from flags or the settings_sync configuration
+func settingsRemote(cmd *cobra.Command, workingDir string) (string, string, error) {
+	repo, _ := cmd.Flags().GetString("repository")
+	branch, _ := cmd.Flags().GetString("branch")
+	if repo == "" || branch == "" {
+		if cfg, err := config.LoadWithWorkingDir(workingDir); err == nil && cfg.SettingsSync != nil {
+			if repo == "" {
+				repo = cfg.SettingsSync.Repository
+			}
+			if branch == "" {
+				branch = cfg.SettingsSync.Branch
+			}
+		}
+	}
+	if repo == "" {
+		return "", "", fmt.Errorf("no settings repository configured; pass --repository or run 'ddx config set settings_sync.repository <url> --global'")
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	return repo, branch, nil
+}
+
+// settingsPassphrase reads the passphrase from the environment or, in a
+// terminal, asks for it without echoing. Pushing asks twice.
+func settingsPassphrase(cmd *cobra.Command, confirm bool) (string, error) {
+	return readPassphrase(cmd, settingsPassphraseEnv, "settings", confirm)
+}
+
+// readPassphrase reads a passphrase from env or, in a terminal, asks for
+// it without echoing. confirm asks twice.
+func readPassphrase(cmd *cobra.Command, env, purpose string, confirm bool) (string, error) {
+	if passphrase := os.Getenv(env); passphrase != "" {
+		return passphrase, nil
+	}
+	in, ok := cmd.InOrStdin().(*os.File)
+	if !ok || !term.IsTerminal(int(in.Fd())) {
+		return "", fmt.Errorf("set %s or run in a terminal to enter the %s passphrase", env, purpose)
+	}
+	read := func(label string) (string, error) {
+		_, _ = fmt.Fprint(cmd.ErrOrStderr(), label)
+		value, err := term.ReadPassword(int(in.Fd()))
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+		return strings.TrimSpace(string(value)), err
+	}
+	passphrase, err := read(strings.ToUpper(purpose[:1]) + purpose[1:] + " passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	if confirm {
+		again, err := read("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// settingsDir is the directory personal settings are synced from
+func settingsDir() (string, error) {
+	path, err := config.GlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// runSettingsPush implements the settings sync push command
+func (f *CommandFactory) runSettingsPush(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+	repo, branch, err := settingsRemote(cmd, f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	dir, err := settingsDir()
+	if err != nil {
+		return err
+	}
+	bundle, err := settings.Collect(dir)
+	if err != nil {
+		return err
+	}
+	passphrase, err := settingsPassphrase(cmd, true)
+	if err != nil {
+		return err
+	}
+	data, err := bundle.Encrypt(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt settings: %w", err)
+	}
+	if err := settings.Push(cmd.Context(), repo, branch, data); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "🔐 Encrypted %s\n", strings.Join(bundle.Names(), ", "))
+	_, _ = fmt.Fprintf(out, "✅ Pushed settings to %s (%s)\n", repo, branch)
+	return nil
+}
+
+// runSettingsPull implements the settings sync pull command
+func (f *CommandFactory) runSettingsPull(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+	repo, branch, err := settingsRemote(cmd, f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	data, err := settings.Pull(cmd.Context(), repo, branch)
+	if err != nil {
+		return err
+	}
+	passphrase, err := settingsPassphrase(cmd, false)
+	if err != nil {
+		return err
+	}
+	bundle, err := settings.Decrypt(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if content, ok := bundle.Files["config.yaml"]; ok {
+		var cfg config.NewConfig
+		if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+			return fmt.Errorf("synced config.yaml is invalid: %w", err)
+		}
+	}
+	dir, err := settingsDir()
+	if err != nil {
+		return err
+	}
+	changed, err := bundle.Apply(dir)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		_, _ = fmt.Fprintln(out, "✅ Settings are already up to date")
+		return nil
+	}
+	for _, name := range changed {
+		_, _ = fmt.Fprintf(out, "  ↻ %s\n", filepath.Join(dir, name))
+	}
+	_, _ = fmt.Fprintf(out, "✅ Pulled settings from %s (%s); previous versions were kept as .bak\n", repo, branch)
+	return nil
+}
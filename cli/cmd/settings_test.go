@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsSync(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	remote := filepath.Join(t.TempDir(), "settings.git")
+	require.NoError(t, exec.Command("git", "init", "-q", "--bare", remote).Run())
+
+	_, err := env.RunCommand("settings", "sync", "push")
+	assert.ErrorContains(t, err, "no settings repository configured")
+
+	global := filepath.Join(home, ".ddx", "config.yaml")
+	original := "settings_sync:\n  repository: " + remote + "\nprompts:\n  review: code-review\n"
+	require.NoError(t, os.MkdirAll(filepath.Dir(global), 0755))
+	require.NoError(t, os.WriteFile(global, []byte(original), 0644))
+
+	t.Setenv(settingsPassphraseEnv, "")
+	_, err = env.RunCommand("settings", "sync", "push")
+	assert.ErrorContains(t, err, "set DDX_SETTINGS_PASSPHRASE")
+
+	t.Setenv(settingsPassphraseEnv, "correct horse")
+	output, err := env.RunCommand("settings", "sync", "push")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Encrypted config.yaml")
+
+	// Another machine with different settings pulls them
+	require.NoError(t, os.WriteFile(global, []byte("settings_sync:\n  repository: "+remote+"\n"), 0644))
+	output, err = env.RunCommand("settings", "sync", "pull")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Pulled settings from")
+	data, err := os.ReadFile(global)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+	assert.FileExists(t, global+".bak")
+
+	t.Setenv(settingsPassphraseEnv, "wrong")
+	_, err = env.RunCommand("settings", "sync", "pull")
+	assert.EqualError(t, err, "wrong passphrase or corrupted settings")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runStartup builds a root command for args the way the executable does
+// and runs it, discarding the output
+func runStartup(workingDir string, args ...string) error {
+	f := NewCommandFactory(workingDir)
+	f.LogOutput = io.Discard
+	root := f.NewRootCommandFor(args)
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SetArgs(args)
+	return root.Execute()
+}
+
+// BenchmarkStartup measures building the command tree and running the
+// commands most often run on their own
+func BenchmarkStartup(b *testing.B) {
+	b.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	dir := b.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ddx"), 0755); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".ddx", "config.yaml"), []byte("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n"), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("tree", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewCommandFactory(dir).NewRootCommand()
+		}
+	})
+	for _, args := range [][]string{
+		{"version", "--no-check"},
+		{"--help"},
+		{"config", "get", "library.path"},
+		{"persona", "list"},
+		{"workflow", "list"},
+	} {
+		name := args[0]
+		if len(args) > 1 && args[0] != "version" {
+			name += "_" + args[1]
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := runStartup(dir, args...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStartupBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	for _, args := range [][]string{{"version", "--no-check"}, {"--help"}} {
+		times := make([]time.Duration, 0, 11)
+		for i := 0; i < cap(times); i++ {
+			start := time.Now()
+			err := runStartup(env.Dir, args...)
+			require.NoError(t, err)
+			times = append(times, time.Since(start))
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		median := times[len(times)/2]
+		assert.Less(t, median, startupBudget, "ddx %v took %s, over the %s budget", args, median, startupBudget)
+	}
+}
+
+func TestTopLevelCommands(t *testing.T) {
+	root := NewCommandFactory(t.TempDir()).NewRootCommand()
+	var built [][]string
+	for _, c := range root.Commands() {
+		if c.Name() != "help" {
+			built = append(built, append([]string{c.Name()}, c.Aliases...))
+		}
+	}
+	var listed [][]string
+	for _, c := range NewCommandFactory(t.TempDir()).topLevelCommands(root) {
+		listed = append(listed, c.Names)
+	}
+	sortNames := func(names [][]string) {
+		sort.Slice(names, func(i, j int) bool { return names[i][0] < names[j][0] })
+	}
+	sortNames(built)
+	sortNames(listed)
+	assert.Equal(t, built, listed, "topLevelCommands must list each command's name and aliases")
+}
+
+func TestCommandName(t *testing.T) {
+	root := NewCommandFactory(t.TempDir()).NewRootCommand()
+	cases := map[string][]string{
+		"persona": {"persona", "list"},
+		"list":    {"-v", "--project", "web", "list"},
+		"config":  {"--profile=ci", "--quiet", "config"},
+		"ls":      {"--library-base-path", "/lib", "ls"},
+		"update":  {"--timeout", "10s", "update", "--check"},
+		"":        {"--help"},
+	}
+	for want, args := range cases {
+		assert.Equal(t, want, commandName(root, args), args)
+	}
+	assert.Equal(t, "", commandName(root, []string{"--unknown", "list"}))
+}
+
+func TestNewRootCommandFor(t *testing.T) {
+	dir := t.TempDir()
+	names := func(args ...string) []string {
+		var names []string
+		for _, c := range NewCommandFactory(dir).NewRootCommandFor(args).Commands() {
+			names = append(names, c.Name())
+		}
+		return names
+	}
+	assert.Equal(t, []string{"persona"}, names("persona", "list"))
+	assert.Equal(t, []string{"list"}, names("ls"))
+	assert.Greater(t, len(names("--help")), 30)
+	assert.Greater(t, len(names("contracts")), 30, "commands walking the tree get all of it")
+	assert.True(t, slices.Contains(names("no-such-command"), "persona"), "unknown commands get suggestions from every command")
+}
+
+func TestDebugTimings(t *testing.T) {
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	f := NewCommandFactory(t.TempDir())
+	root := f.NewRootCommandFor([]string{"version", "--debug-timings"})
+	var out bytes.Buffer
+	root.SetOut(io.Discard)
+	root.SetErr(&out)
+	root.SetArgs([]string{"version", "--debug-timings"})
+	require.NoError(t, root.Execute())
+	f.reportTimings(root)
+
+	for _, phase := range []string{"PHASE", "build commands", "parse arguments", "load config", "run version", "total"} {
+		assert.Contains(t, out.String(), phase)
+	}
+
+	out.Reset()
+	f = NewCommandFactory(t.TempDir())
+	root = f.NewRootCommandFor([]string{"version"})
+	root.SetOut(io.Discard)
+	root.SetErr(&out)
+	root.SetArgs([]string{"version"})
+	require.NoError(t, root.Execute())
+	f.reportTimings(root)
+	assert.Empty(t, out.String(), "nothing is reported without the flag")
+}
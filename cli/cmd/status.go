@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -23,6 +24,9 @@ var statusCmd = &cobra.Command{
 This command displays:
 - Current DDX version and commit hash
 - Last update timestamp
+- Project overview: configuration and profile, library commit and drift
+  from ddx.lock, loaded personas, active workflow phases, installed MCP
+  servers and library changes waiting to be contributed
 - Local modifications to DDX resources
 - Available upstream updates
 - Change history and differences
@@ -33,7 +37,8 @@ Examples:
   ddx status --check-upstream         # Check for updates
   ddx status --changes                # List changed files
   ddx status --diff                   # Show differences
-  ddx status --export manifest.yml    # Export version manifest`,
+  ddx status --export manifest.yml    # Export version manifest
+  ddx status --json                   # Print the report as JSON`,
 	RunE: runStatus,
 }
 
@@ -59,6 +64,9 @@ func (f *CommandFactory) runStatus(cmd *cobra.Command, args []string) error {
 	if exportPath != "" {
 		return exportStatusManifest(cmd, status, exportPath)
 	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return writeStatusJSON(cmd, status)
+	}
 
 	// Display results
 	displayStatus(cmd, status, showChanges, showDiff)
@@ -67,6 +75,7 @@ func (f *CommandFactory) runStatus(cmd *cobra.Command, args []string) error {
 
 type StatusInfo struct {
 	Version       string               `yaml:"version" json:"version"`
+	Project       *ProjectOverview     `yaml:"project,omitempty" json:"project,omitempty"`
 	CommitHash    string               `yaml:"commit_hash" json:"commit_hash"`
 	LastUpdated   time.Time            `yaml:"last_updated" json:"last_updated"`
 	UpstreamInfo  *UpstreamInfo        `yaml:"upstream,omitempty" json:"upstream,omitempty"`
@@ -133,6 +142,7 @@ func checkStatus(workingDir string, checkUpstream, showChanges, showDiff bool) (
 		return nil, err
 	}
 	status.LastUpdated = lastUpdated
+	status.Project = collectProjectOverview(workingDir)
 
 	// Check for local modifications
 	modifications, err := getLocalModificationsFromDir(workingDir)
@@ -399,6 +409,11 @@ func displayStatus(cmd *cobra.Command, status *StatusInfo, showChanges, showDiff
 	}
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 
+	if status.Project != nil {
+		displayProjectOverview(cmd.OutOrStdout(), status.Project)
+		_, _ = fmt.Fprintln(cmd.OutOrStdout())
+	}
+
 	// Show modifications
 	if len(status.Modifications) > 0 {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Modified Resources:")
@@ -469,7 +484,7 @@ func exportStatusManifest(cmd *cobra.Command, status *StatusInfo, path string) e
 		return fmt.Errorf("failed to marshal status data: %w", err)
 	}
 
-	err = os.WriteFile(path, data, 0644)
+	err = atomicfile.WriteFile(path, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write manifest file: %w", err)
 	}
@@ -478,6 +493,16 @@ func exportStatusManifest(cmd *cobra.Command, status *StatusInfo, path string) e
 	return nil
 }
 
+// writeStatusJSON prints the status report as JSON
+func writeStatusJSON(cmd *cobra.Command, status *StatusInfo) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status data: %w", err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
 func isDDXProject() bool {
 	return isDDXProjectInDir("")
 }
@@ -504,6 +529,9 @@ func runStatusWithWorkingDir(cmd *cobra.Command, args []string, workingDir strin
 	if exportPath != "" {
 		return exportStatusManifest(cmd, status, exportPath)
 	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return writeStatusJSON(cmd, status)
+	}
 
 	// Display results
 	displayStatus(cmd, status, showChanges, showDiff)
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/easel/ddx/internal/catalog"
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/lock"
+	"github.com/easel/ddx/internal/mcp"
+	"github.com/easel/ddx/internal/workflow"
+)
+
+// ProjectOverview summarizes the state of a DDx project on one screen
+type ProjectOverview struct {
+	Initialized bool `yaml:"initialized" json:"initialized"`
+	// ConfigFile is the project configuration, relative to the project
+	ConfigFile    string `yaml:"config_file,omitempty" json:"config_file,omitempty"`
+	ConfigVersion string `yaml:"config_version,omitempty" json:"config_version,omitempty"`
+	Profile       string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	// LegacyConfig is set while the project still uses .ddx.yml
+	LegacyConfig bool               `yaml:"legacy_config,omitempty" json:"legacy_config,omitempty"`
+	Library      LibraryOverview    `yaml:"library" json:"library"`
+	Personas     []LoadedPersona    `yaml:"personas,omitempty" json:"personas,omitempty"`
+	Workflows    []WorkflowOverview `yaml:"workflows,omitempty" json:"workflows,omitempty"`
+	MCPServers   []string           `yaml:"mcp_servers,omitempty" json:"mcp_servers,omitempty"`
+}
+
+// LibraryOverview describes the project's library and how it differs from
+// ddx.lock and from upstream
+type LibraryOverview struct {
+	Path   string `yaml:"path,omitempty" json:"path,omitempty"`
+	Commit string `yaml:"commit,omitempty" json:"commit,omitempty"`
+	// Pinned is the tag or commit the library is pinned to
+	Pinned string `yaml:"pinned,omitempty" json:"pinned,omitempty"`
+	Locked bool   `yaml:"locked" json:"locked"`
+	// Drift lists library files that no longer match ddx.lock
+	Drift []lock.Drift `yaml:"drift,omitempty" json:"drift,omitempty"`
+	// PendingChanges are uncommitted library edits that could be
+	// contributed upstream, as git status lines
+	PendingChanges []string `yaml:"pending_changes,omitempty" json:"pending_changes,omitempty"`
+}
+
+// LoadedPersona is a persona loaded into the AI target file for a role
+type LoadedPersona struct {
+	Role    string `yaml:"role" json:"role"`
+	Persona string `yaml:"persona" json:"persona"`
+}
+
+// WorkflowOverview is an active workflow and the phase it is in
+type WorkflowOverview struct {
+	Name  string `yaml:"name" json:"name"`
+	Phase string `yaml:"phase,omitempty" json:"phase,omitempty"`
+}
+
+// collectProjectOverview gathers the overview for a project. Sections that
+// cannot be read, such as a library outside git, are left empty.
+func collectProjectOverview(workingDir string) *ProjectOverview {
+	overview := &ProjectOverview{Profile: config.ActiveProfile()}
+
+	projectConfig := filepath.Join(".ddx", "config.yaml")
+	switch {
+	case fileExists(filepath.Join(workingDir, projectConfig)):
+		overview.ConfigFile = projectConfig
+	case fileExists(filepath.Join(workingDir, config.LegacyConfigFile)):
+		overview.ConfigFile = config.LegacyConfigFile
+		overview.LegacyConfig = true
+	}
+	overview.Initialized = overview.ConfigFile != ""
+
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil || cfg == nil {
+		return overview
+	}
+	overview.ConfigVersion = cfg.Version
+	if cfg.Library != nil {
+		overview.Library = libraryOverview(workingDir, cfg)
+	}
+
+	if status, err := personaStatus(workingDir); err == nil {
+		for i, role := range status.LoadedRoles {
+			overview.Personas = append(overview.Personas, LoadedPersona{Role: role, Persona: status.LoadedPersonas[i]})
+		}
+	}
+
+	for _, name := range cfg.Workflows.Active {
+		entry := WorkflowOverview{Name: name}
+		if state, err := workflow.LoadStateFrom(workingDir, name); err == nil {
+			entry.Phase = state.CurrentPhase
+		}
+		overview.Workflows = append(overview.Workflows, entry)
+	}
+
+	servers, _ := mcp.ConfiguredServers(
+		filepath.Join(workingDir, mcp.ProjectConfigFile),
+		filepath.Join(workingDir, ".claude", "settings.local.json"))
+	for _, server := range servers {
+		if !slices.Contains(overview.MCPServers, server.Name) {
+			overview.MCPServers = append(overview.MCPServers, server.Name)
+		}
+	}
+	return overview
+}
+
+// libraryOverview reports the library's upstream commit, its drift from
+// ddx.lock and any edits waiting to be contributed
+func libraryOverview(workingDir string, cfg *config.Config) LibraryOverview {
+	libDir := projectLibraryDir(workingDir, cfg)
+	library := LibraryOverview{Path: cfg.Library.Path}
+	if cfg.Library.Repository != nil {
+		library.Pinned = cfg.Library.Repository.Ref
+	}
+	prefix, err := filepath.Rel(workingDir, libDir)
+	if err != nil || strings.HasPrefix(prefix, "..") {
+		return library
+	}
+	prefix = filepath.ToSlash(prefix)
+	library.Commit = git.LastSubtreeSync(workingDir, prefix)
+	library.PendingChanges = pendingLibraryChanges(workingDir, prefix)
+
+	if l, err := lock.Load(workingDir); err == nil {
+		library.Locked = true
+		library.Drift, _ = l.Verify(libDir)
+	}
+	return library
+}
+
+// pendingLibraryChanges returns git status lines for uncommitted changes
+// under the library prefix
+func pendingLibraryChanges(workingDir, prefix string) []string {
+	// The resource index is generated locally and never committed
+	output, err := exec.Command("git", "-C", workingDir, "status", "--porcelain", "--",
+		prefix, ":!"+prefix+"/"+catalog.FileName).Output()
+	if err != nil {
+		return nil
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes
+}
+
+// displayProjectOverview writes the overview section of the status report
+func displayProjectOverview(out io.Writer, overview *ProjectOverview) {
+	configLine := "not initialized - run 'ddx init'"
+	if overview.Initialized {
+		configLine = overview.ConfigFile
+		if overview.ConfigVersion != "" {
+			configLine += " (version " + overview.ConfigVersion + ")"
+		}
+		if overview.LegacyConfig {
+			configLine += " - legacy format, run 'ddx config migrate'"
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Config:       %s\n", configLine)
+	if overview.Profile != "" {
+		_, _ = fmt.Fprintf(out, "Profile:      %s\n", overview.Profile)
+	}
+
+	library := overview.Library
+	if library.Path != "" {
+		commit := library.Commit
+		if commit == "" {
+			commit = "not synced"
+		} else if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if library.Pinned != "" {
+			commit += ", pinned to " + library.Pinned
+		}
+		_, _ = fmt.Fprintf(out, "Library:      %s (%s)\n", library.Path, commit)
+		switch {
+		case !library.Locked:
+			_, _ = fmt.Fprintf(out, "Lock:         no %s\n", lock.FileName)
+		case len(library.Drift) > 0:
+			_, _ = fmt.Fprintf(out, "Lock:         %d file(s) drifted: %s\n", len(library.Drift), lock.Summary(library.Drift))
+		default:
+			_, _ = fmt.Fprintln(out, "Lock:         up to date")
+		}
+		if n := len(library.PendingChanges); n > 0 {
+			_, _ = fmt.Fprintf(out, "Contribute:   %d uncommitted library change(s) - share with 'ddx contribute'\n", n)
+		}
+	}
+
+	personas := "none loaded"
+	if len(overview.Personas) > 0 {
+		parts := make([]string, len(overview.Personas))
+		for i, p := range overview.Personas {
+			parts[i] = p.Role + ": " + p.Persona
+		}
+		personas = strings.Join(parts, ", ")
+	}
+	_, _ = fmt.Fprintf(out, "Personas:     %s\n", personas)
+
+	workflows := "none active"
+	if len(overview.Workflows) > 0 {
+		parts := make([]string, len(overview.Workflows))
+		for i, w := range overview.Workflows {
+			parts[i] = w.Name
+			if w.Phase != "" {
+				parts[i] += " (" + w.Phase + ")"
+			}
+		}
+		workflows = strings.Join(parts, ", ")
+	}
+	_, _ = fmt.Fprintf(out, "Workflows:    %s\n", workflows)
+
+	servers := "none"
+	if len(overview.MCPServers) > 0 {
+		servers = strings.Join(overview.MCPServers, ", ")
+	}
+	_, _ = fmt.Fprintf(out, "MCP servers:  %s\n", servers)
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
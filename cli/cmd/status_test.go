@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/easel/ddx/internal/lock"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -339,3 +341,47 @@ func TestLogCommand_Contract(t *testing.T) {
 		assert.Less(t, duration, 3*time.Second, "Log command took too long: %v", duration)
 	})
 }
+
+func TestStatusCommand_Overview(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+workflows:
+  active: [helix]
+`)
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+	env.CreateFile("CLAUDE.md", "<!-- PERSONAS:START -->\n### code-reviewer: strict-reviewer\n<!-- PERSONAS:END -->\n")
+	env.CreateFile(".helix-state.yml", "workflow: helix\ncurrent_phase: design\n")
+	env.CreateFile(".mcp.json", `{"mcpServers": {"github": {"command": "npx"}}}`)
+
+	t.Run("text", func(t *testing.T) {
+		output, err := env.RunCommand("status")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Config:       "+filepath.Join(".ddx", "config.yaml")+" (version 1.0)")
+		assert.Contains(t, output, "Library:      .ddx/library (not synced)")
+		assert.Contains(t, output, "Lock:         no ddx.lock")
+		assert.Contains(t, output, "Personas:     code-reviewer: strict-reviewer")
+		assert.Contains(t, output, "Workflows:    helix (design)")
+		assert.Contains(t, output, "MCP servers:  github")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		_, err := env.RunCommand("lock")
+		require.NoError(t, err)
+		env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer, edited\n")
+
+		output, err := env.RunCommand("status", "--json")
+		require.NoError(t, err)
+		var status StatusInfo
+		require.NoError(t, json.Unmarshal([]byte(output), &status))
+		require.NotNil(t, status.Project)
+		assert.True(t, status.Project.Initialized)
+		assert.Equal(t, "1.0", status.Project.ConfigVersion)
+		assert.True(t, status.Project.Library.Locked)
+		assert.Equal(t, []lock.Drift{{Path: "personas/reviewer.md", Status: lock.DriftModified}}, status.Project.Library.Drift)
+		assert.Equal(t, []LoadedPersona{{Role: "code-reviewer", Persona: "strict-reviewer"}}, status.Project.Personas)
+		assert.Equal(t, []WorkflowOverview{{Name: "helix", Phase: "design"}}, status.Project.Workflows)
+		assert.Equal(t, []string{"github"}, status.Project.MCPServers)
+	})
+}
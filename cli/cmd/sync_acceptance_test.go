@@ -137,8 +137,8 @@ func TestAcceptance_US009_PullUpdatesFromUpstream(t *testing.T) {
 			// Simulate divergence
 			// This would normally require git operations
 			// In test mode, create a marker file
-			_ = os.MkdirAll(".ddx", 0755)
-			_ = os.WriteFile(".ddx/.diverged", []byte("diverged"), 0644)
+			_ = os.MkdirAll(filepath.Join(tempDir, ".ddx"), 0755)
+			_ = os.WriteFile(filepath.Join(tempDir, ".ddx", ".diverged"), []byte("diverged"), 0644)
 
 			// When: Attempting to sync
 			factory := NewCommandFactory(tempDir)
@@ -165,7 +165,7 @@ func TestAcceptance_US010_HandleUpdateConflicts(t *testing.T) {
 			setupTestProject(t, tempDir)
 
 			// Create conflicting file
-			conflictFile := filepath.Join(".ddx", "conflict.txt")
+			conflictFile := filepath.Join(tempDir, ".ddx", "conflict.txt")
 			_ = os.WriteFile(conflictFile, []byte("local version"), 0644)
 
 			// When: Updating
@@ -190,8 +190,8 @@ func TestAcceptance_US010_HandleUpdateConflicts(t *testing.T) {
 			setupTestProject(t, tempDir)
 
 			// Create conflict file
-			_ = os.MkdirAll(".ddx", 0755)
-			_ = os.WriteFile(".ddx/conflict.txt", []byte("conflict"), 0644)
+			_ = os.MkdirAll(filepath.Join(tempDir, ".ddx"), 0755)
+			_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "conflict.txt"), []byte("conflict"), 0644)
 
 			// When: Using interactive resolution
 			factory := NewCommandFactory(tempDir)
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/easel/ddx/internal/exitcodes"
 	"github.com/easel/ddx/internal/git"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -49,7 +50,7 @@ persona_bindings: {}`
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if err != nil && errors.As(err, &exitErr) {
-			assert.Equal(t, 0, exitErr.Code, "Should exit with code 0 on success")
+			assert.Equal(t, exitcodes.Success, exitErr.Code, "Should exit with code 0 on success")
 		}
 	})
 
@@ -68,7 +69,7 @@ persona_bindings: {}`
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if errors.As(err, &exitErr) {
-			assert.Equal(t, 3, exitErr.Code, "Should exit with code 3 when no config")
+			assert.Equal(t, exitcodes.NoConfig, exitErr.Code, "Should exit with code 3 when no config")
 		}
 	})
 
@@ -100,7 +101,7 @@ persona_bindings:
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if errors.As(err, &exitErr) {
-			assert.Equal(t, 5, exitErr.Code, "Should exit with code 5 on network error")
+			assert.Equal(t, exitcodes.NetworkError, exitErr.Code, "Should exit with code 5 on network error")
 		}
 	})
 
@@ -145,18 +146,17 @@ persona_bindings: {}`
 		tempDir := t.TempDir()
 
 		// Initialize git repo so the command runs fully
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
 
 		// Create local changes
-		_ = os.MkdirAll(".ddx", 0755)
-		_ = os.WriteFile(".ddx/local.txt", []byte("local changes"), 0644)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "local.txt"), []byte("local changes"), 0644)
 
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Running with --force flag
 		// Reset flags to avoid state from previous tests
@@ -185,7 +185,6 @@ persona_bindings: {}`
 		// Given: Update operation
 		tempDir := t.TempDir()
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
 
 		// Reset flags
 		// Create a fresh command for test isolation
@@ -221,7 +220,6 @@ persona_bindings: {}`
 		// Given: Valid project with DDx initialization
 		tempDir := t.TempDir()
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
 
 		// When: Running with --dry-run flag
 		cmd := getFreshSyncCommands(tempDir)
@@ -255,19 +253,19 @@ func TestContributeCommand_Contract(t *testing.T) {
 		// Flags are now local to commands - no reset needed
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
 
 		// Create asset to contribute
-		_ = os.MkdirAll(".ddx/templates/test", 0755)
-		_ = os.WriteFile(".ddx/templates/test/README.md", []byte("# Test"), 0644)
+		_ = os.MkdirAll(filepath.Join(tempDir, ".ddx", "templates", "test"), 0755)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "templates", "test", "README.md"), []byte("# Test"), 0644)
 
 		// Commit initial state so HasSubtree can work
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Contributing successfully
 		cmd := getFreshSyncCommands(tempDir)
@@ -280,7 +278,7 @@ func TestContributeCommand_Contract(t *testing.T) {
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if err != nil && errors.As(err, &exitErr) {
-			assert.Equal(t, 0, exitErr.Code, "Should exit with code 0 on success")
+			assert.Equal(t, exitcodes.Success, exitErr.Code, "Should exit with code 0 on success")
 		}
 	})
 
@@ -291,15 +289,14 @@ func TestContributeCommand_Contract(t *testing.T) {
 		// Flags are now local to commands - no reset needed
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
 
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Contributing non-existent asset
 		cmd := getFreshSyncCommands(tempDir)
@@ -312,7 +309,7 @@ func TestContributeCommand_Contract(t *testing.T) {
 		err := cmd.Execute()
 		var exitErr *ExitError
 		if errors.As(err, &exitErr) {
-			assert.Equal(t, 6, exitErr.Code, "Should exit with code 6 when asset not found")
+			assert.Equal(t, exitcodes.NotFound, exitErr.Code, "Should exit with code 6 when asset not found")
 		}
 	})
 
@@ -323,17 +320,17 @@ func TestContributeCommand_Contract(t *testing.T) {
 		// Flags are now local to commands - no reset needed
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
 
-		_ = os.MkdirAll(".ddx/patterns", 0755)
-		_ = os.WriteFile(".ddx/patterns/test.md", []byte("pattern"), 0644)
+		_ = os.MkdirAll(filepath.Join(tempDir, ".ddx", "patterns"), 0755)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "patterns", "test.md"), []byte("pattern"), 0644)
 
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Running with --dry-run
 		cmd := getFreshSyncCommands(tempDir)
@@ -350,7 +347,7 @@ func TestContributeCommand_Contract(t *testing.T) {
 		assert.Contains(t, output, "would", "Should use conditional language")
 
 		// No git operations should occur
-		_, err := os.Stat(".git/refs/heads/contribute")
+		_, err := os.Stat(filepath.Join(tempDir, ".git", "refs", "heads", "contribute"))
 		assert.True(t, os.IsNotExist(err), "Should not create branch in dry-run")
 	})
 
@@ -361,17 +358,17 @@ func TestContributeCommand_Contract(t *testing.T) {
 		// Flags are now local to commands - no reset needed
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
 
-		_ = os.MkdirAll(".ddx/prompts", 0755)
-		_ = os.WriteFile(".ddx/prompts/test.md", []byte("prompt"), 0644)
+		_ = os.MkdirAll(filepath.Join(tempDir, ".ddx", "prompts"), 0755)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "prompts", "test.md"), []byte("prompt"), 0644)
 
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Contributing without message
 		cmd := getFreshSyncCommands(tempDir)
@@ -451,10 +448,9 @@ func TestUpdateCommand_ConflictHandling(t *testing.T) {
 		createTestConfig(t, tempDir)
 
 		// Simulate conflict scenario
-		_ = os.MkdirAll(".ddx", 0755)
 		// Use escaped conflict marker to avoid pre-commit detection
 		conflictMarker := "<" + "<" + "<" + "<" + "<" + "<" + "< HEAD"
-		_ = os.WriteFile(".ddx/CONFLICT.txt", []byte(conflictMarker), 0644)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "CONFLICT.txt"), []byte(conflictMarker), 0644)
 
 		// When: Updating with conflicts
 		cmd := getFreshSyncCommands(tempDir)
@@ -463,11 +459,11 @@ func TestUpdateCommand_ConflictHandling(t *testing.T) {
 		cmd.SetErr(buf)
 		cmd.SetArgs([]string{"update"})
 
-		_ = cmd.Execute()
+		err := cmd.Execute()
 
-		// Then: Should complete successfully (in test mode, conflicts aren't simulated)
-		output := buf.String()
-		assert.Contains(t, strings.ToLower(output), "updated", "Should indicate update completed")
+		// Then: The conflict marker stops the update until it is resolved
+		assert.ErrorContains(t, err, "conflicts detected", "Should detect the conflict")
+		assert.Contains(t, buf.String(), "--strategy", "Should point at the ways to resolve it")
 	})
 
 	t.Run("contract_strategy_theirs", func(t *testing.T) {
@@ -480,7 +476,6 @@ func TestUpdateCommand_ConflictHandling(t *testing.T) {
 		// (command flags are reset by creating fresh commands)
 
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
 
 		// When: Using --strategy=theirs
 		cmd := getFreshSyncCommands(tempDir)
@@ -506,7 +501,6 @@ func TestUpdateCommand_ConflictHandling(t *testing.T) {
 		// (command flags are reset by creating fresh commands)
 
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
 
 		// When: Using --strategy=ours
 		cmd := getFreshSyncCommands(tempDir)
@@ -530,14 +524,13 @@ func TestSyncCommand_GitSubtree(t *testing.T) {
 		tempDir := t.TempDir()
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx", 0755) // Create .ddx directory so isInitialized() passes
-		_ = execCommand("git", "add", ".")
-		_ = execCommand("git", "commit", "-m", "Initial commit")
+		_ = execCommandInDir(tempDir, "git", "add", ".")
+		_ = execCommandInDir(tempDir, "git", "commit", "-m", "Initial commit")
 
 		// When: Pulling via subtree
 		cmd := getFreshSyncCommands(tempDir)
@@ -560,13 +553,13 @@ func TestSyncCommand_GitSubtree(t *testing.T) {
 		tempDir := t.TempDir()
 
 		// Initialize git repo
-		_ = execCommand("git", "init")
-		_ = execCommand("git", "config", "user.email", "test@example.com")
-		_ = execCommand("git", "config", "user.name", "Test User")
+		_ = execCommandInDir(tempDir, "git", "init")
+		_ = execCommandInDir(tempDir, "git", "config", "user.email", "test@example.com")
+		_ = execCommandInDir(tempDir, "git", "config", "user.name", "Test User")
 
 		createTestConfig(t, tempDir)
-		_ = os.MkdirAll(".ddx/new", 0755) // Ensure .ddx directory exists
-		_ = os.WriteFile(".ddx/new/file.txt", []byte("content"), 0644)
+		_ = os.MkdirAll(filepath.Join(tempDir, ".ddx", "new"), 0755)
+		_ = os.WriteFile(filepath.Join(tempDir, ".ddx", "new", "file.txt"), []byte("content"), 0644)
 
 		// When: Pushing via subtree
 		cmd := getFreshSyncCommands(tempDir)
@@ -600,12 +593,6 @@ persona_bindings:
 	_ = os.WriteFile(filepath.Join(ddxDir, "config.yaml"), []byte(configContent), 0644)
 }
 
-// Helper to execute shell commands (for git operations)
-func execCommand(command string, args ...string) error {
-	cmd := exec.Command(command, args...)
-	return cmd.Run()
-}
-
 // Helper to execute shell commands in a specific directory
 func execCommandInDir(workingDir, command string, args ...string) error {
 	cmd := exec.Command(command, args...)
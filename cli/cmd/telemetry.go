@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/network"
+	"github.com/easel/ddx/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// telemetryTimeout keeps a slow endpoint from holding up the command that
+// triggered a send
+const telemetryTimeout = 3 * time.Second
+
+// recordTelemetry counts a finished invocation when telemetry is enabled
+// and sends the pending batch once a day. Telemetry never fails or warns
+// about the command; problems are only logged at debug level.
+func (f *CommandFactory) recordTelemetry(cmd *cobra.Command, runErr error) {
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil || !cfg.Telemetry.IsEnabled() {
+		return
+	}
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		return
+	}
+	store, err := telemetry.Load(path)
+	if err != nil {
+		slog.Debug("could not load telemetry", "error", err)
+		return
+	}
+
+	now := time.Now()
+	message := ""
+	if runErr != nil {
+		message = runErr.Error()
+	}
+	store.Record(f.Version, cmd.CommandPath(), message, now)
+	if cfg.Telemetry.Endpoint != "" && store.Due(now) {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryTimeout)
+		defer cancel()
+		if err := store.Send(ctx, network.Client(), cfg.Telemetry.Endpoint, now); err != nil {
+			slog.Debug("could not send telemetry", "error", err)
+		}
+	}
+	if err := store.Save(); err != nil {
+		slog.Debug("could not save telemetry", "error", err)
+	}
+}
+
+// runTelemetryStatus shows whether telemetry is enabled and what is
+// waiting to be sent
+func (f *CommandFactory) runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithWorkingDir(f.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		return err
+	}
+	store, err := telemetry.Load(path)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		data, err := json.MarshalIndent(store.Payload(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode telemetry: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	enabled := cfg.Telemetry.IsEnabled()
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	endpoint := "not set; metrics stay on this machine"
+	if cfg.Telemetry != nil && cfg.Telemetry.Endpoint != "" {
+		endpoint = cfg.Telemetry.Endpoint
+	}
+	lastSent := "never"
+	if !store.LastSent.IsZero() {
+		lastSent = store.LastSent.Local().Format("2006-01-02 15:04")
+	}
+
+	_, _ = fmt.Fprintf(out, "Telemetry: %s\n", state)
+	_, _ = fmt.Fprintf(out, "Endpoint:  %s\n", endpoint)
+	if pending := store.Pending(); pending > 0 {
+		_, _ = fmt.Fprintf(out, "Pending:   %d invocation(s) since %s\n", pending, store.Batch.Since.Local().Format("2006-01-02"))
+	} else {
+		_, _ = fmt.Fprintln(out, "Pending:   none")
+	}
+	_, _ = fmt.Fprintf(out, "Last sent: %s\n", lastSent)
+	_, _ = fmt.Fprintf(out, "Stored in: %s\n", path)
+	_, _ = fmt.Fprintln(out, "\nOnly command names, error categories, the ddx version and the platform are")
+	_, _ = fmt.Fprintln(out, "collected. Paths, tokens and repository URLs are never recorded; see exactly")
+	_, _ = fmt.Fprintln(out, "what would be sent with 'ddx telemetry status --json'.")
+	if !enabled {
+		_, _ = fmt.Fprintln(out, "\nTurn it on with 'ddx telemetry enable'.")
+	}
+	return nil
+}
+
+// runTelemetryEnable turns telemetry on in the global configuration
+func (f *CommandFactory) runTelemetryEnable(cmd *cobra.Command, args []string) error {
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid endpoint '%s': use an http or https URL", endpoint)
+		}
+		if err := configSet(f.WorkingDir, "telemetry.endpoint", endpoint, true); err != nil {
+			return err
+		}
+	}
+	if err := configSet(f.WorkingDir, "telemetry.enabled", "true", true); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "✅ Telemetry enabled. Thank you for helping improve DDx.")
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Only command names and error categories are counted; see 'ddx telemetry status'.")
+	return nil
+}
+
+// runTelemetryDisable turns telemetry off and deletes unsent metrics
+func (f *CommandFactory) runTelemetryDisable(cmd *cobra.Command, args []string) error {
+	if err := configSet(f.WorkingDir, "telemetry.enabled", "false", true); err != nil {
+		return err
+	}
+	if path, err := telemetry.DefaultPath(); err == nil {
+		store, err := telemetry.Load(path)
+		if err == nil {
+			if err := store.Remove(); err != nil {
+				return err
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "✅ Telemetry disabled and unsent metrics deleted")
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTelemetry tests enabling telemetry, counting commands and disabling it
+func TestTelemetry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	store := filepath.Join(cache, "ddx", "telemetry.json")
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	// Nothing is counted until telemetry is enabled
+	_, err := env.RunCommand("config", "get", "library.path")
+	require.NoError(t, err)
+	assert.NoFileExists(t, store)
+	output, err := env.RunCommand("telemetry", "status")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Telemetry: disabled")
+
+	_, err = env.RunCommand("telemetry", "enable", "--endpoint", "ftp://example.com")
+	require.Error(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	output, err = env.RunCommand("telemetry", "enable", "--endpoint", server.URL)
+	require.NoError(t, err, output)
+
+	_, err = env.RunCommand("config", "get", "library.path")
+	require.NoError(t, err)
+	_, err = env.RunCommand("workflow", "activate", filepath.Join(env.Dir, "missing"))
+	require.Error(t, err)
+
+	output, err = env.RunCommand("telemetry", "status", "--json")
+	require.NoError(t, err)
+	var batch telemetry.Batch
+	require.NoError(t, json.Unmarshal([]byte(output), &batch), output)
+	assert.Equal(t, 1, batch.Commands["ddx config"])
+	assert.Equal(t, 1, batch.Errors["ddx workflow"][telemetry.CategoryNotFound])
+	data, err := os.ReadFile(store)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), env.Dir, "paths are never stored")
+
+	output, err = env.RunCommand("telemetry", "status")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Telemetry: enabled")
+	assert.Contains(t, output, "Endpoint:  "+server.URL)
+
+	output, err = env.RunCommand("telemetry", "disable")
+	require.NoError(t, err, output)
+	assert.NoFileExists(t, store)
+	_, err = env.RunCommand("config", "get", "library.path")
+	require.NoError(t, err)
+	assert.NoFileExists(t, store)
+}
@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/library"
+	"github.com/easel/ddx/internal/templates"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/easel/ddx/internal/userdirs"
+	"github.com/easel/ddx/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// templateSources returns the libraries templates are found in, highest
+// priority first: the project's libraries, or the global library outside a
+// project
+func templateSources(workingDir string) (library.Sources, error) {
+	if !isInitializedInDir(workingDir) {
+		dir, err := userdirs.Path(userdirs.Data, "library")
+		if err != nil {
+			return nil, err
+		}
+		return library.Sources{{Name: config.MainSource, Path: dir, Dir: dir}}, nil
+	}
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return library.ProjectSources(workingDir, cfg), nil
+}
+
+// findTemplate returns the directory of a template and the library it is
+// in. A namespaced name such as internal:service is looked up in that
+// source only; otherwise the highest priority source that has it wins.
+func findTemplate(workingDir, name string) (string, library.Source, error) {
+	sources, err := templateSources(workingDir)
+	if err != nil {
+		return "", library.Source{}, err
+	}
+	namespace, rel := library.SplitRef(name)
+	ref := "templates/" + rel
+	if namespace != "" {
+		ref = namespace + ":" + ref
+	}
+	source, dir, err := sources.Find(ref)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", library.Source{}, err
+	}
+	if _, err := templates.LoadManifest(dir); err != nil {
+		return "", library.Source{}, fmt.Errorf("template '%s' not found: %w", name, err)
+	}
+	return dir, source, nil
+}
+
+// libraryTemplate is a template as listed by 'ddx templates list' and
+// shown by 'ddx templates show'
+type libraryTemplate struct {
+	Name        string               `json:"name"`
+	Source      string               `json:"source"`
+	Description string               `json:"description,omitempty"`
+	Path        string               `json:"path"`
+	Variables   []templates.Variable `json:"variables"`
+	// Files, Requires and Hooks are only filled in by show
+	Files    []string `json:"files,omitempty"`
+	Requires []string `json:"requires,omitempty"`
+	Hooks    []string `json:"hooks,omitempty"`
+}
+
+// newLibraryTemplate describes a template from its manifest
+func newLibraryTemplate(name string, source library.Source, dir string, manifest *templates.Manifest) libraryTemplate {
+	t := libraryTemplate{
+		Name:        name,
+		Source:      source.Name,
+		Description: manifest.Description,
+		Path:        dir,
+		Variables:   manifest.Variables,
+	}
+	if t.Variables == nil {
+		t.Variables = []templates.Variable{}
+	}
+	return t
+}
+
+// listTemplates returns the templates of every library source, sorted by
+// name. A template shadowed by one of the same name in a higher priority
+// source is left out, as it is when applying.
+func listTemplates(workingDir string) ([]libraryTemplate, error) {
+	sources, err := templateSources(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	found := []libraryTemplate{}
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		available, err := templates.Available(filepath.Join(source.Dir, "templates"))
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range available {
+			if seen[t.Name] {
+				continue
+			}
+			seen[t.Name] = true
+			found = append(found, newLibraryTemplate(t.Name, source, t.Dir, t.Manifest))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, nil
+}
+
+// runTemplatesList lists the templates in the project's libraries
+func (f *CommandFactory) runTemplatesList(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	found, err := listTemplates(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, err := json.MarshalIndent(found, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode templates: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+	if len(found) == 0 {
+		_, _ = fmt.Fprintln(out, "No templates found in the library")
+		return nil
+	}
+
+	anyRequired := false
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tSOURCE\tDESCRIPTION\tVARIABLES")
+	for _, t := range found {
+		names := make([]string, 0, len(t.Variables))
+		for _, v := range t.Variables {
+			name := v.Name
+			if v.NeedsValue() {
+				name += "*"
+				anyRequired = true
+			}
+			names = append(names, name)
+		}
+		vars := strings.Join(names, ", ")
+		if vars == "" {
+			vars = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.Name, t.Source, t.Description, vars)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if anyRequired {
+		_, _ = fmt.Fprintln(out, "\n* required; see 'ddx templates show <template>'")
+	}
+	return nil
+}
+
+// runTemplateShow prints a template's files and the variables it needs,
+// marking required ones that have no value yet
+func (f *CommandFactory) runTemplateShow(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	dir, source, err := findTemplate(f.WorkingDir, name)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	manifest, err := templates.LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	files, err := templates.Files(dir)
+	if err != nil {
+		return err
+	}
+	_, rel := library.SplitRef(name)
+	t := newLibraryTemplate(rel, source, dir, manifest)
+	t.Files = files
+	for _, r := range manifest.Requires {
+		t.Requires = append(t.Requires, r.String())
+	}
+	for _, hook := range manifest.Hooks.PostApply {
+		label := hook.Run
+		if hook.Name != "" {
+			label = hook.Name + ": " + hook.Run
+		}
+		t.Hooks = append(t.Hooks, label)
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode template: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	registry, err := variables.Load(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "📦 %s (%s)\n", t.Name, t.Source)
+	if t.Description != "" {
+		_, _ = fmt.Fprintln(out, t.Description)
+	}
+	_, _ = fmt.Fprintf(out, "Path: %s\n", t.Path)
+
+	_, _ = fmt.Fprintf(out, "\nFiles (%d):\n", len(t.Files))
+	writeFileTree(out, t.Files, "  ")
+
+	var missing []string
+	if len(t.Variables) > 0 {
+		_, _ = fmt.Fprintln(out, "\nVariables:")
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, v := range t.Variables {
+			need := "optional"
+			switch {
+			case v.Default != "":
+				need = "default " + v.Default
+			case v.Required:
+				need = "required"
+			}
+			description := v.Description
+			if set, ok := registry.Get(v.Name); ok {
+				description = strings.TrimSpace(fmt.Sprintf("%s (✓ %s in .ddx/variables.yml)", description, set))
+			} else if v.NeedsValue() {
+				missing = append(missing, v.Name)
+			}
+			if description == "" {
+				_, _ = fmt.Fprintf(tw, "  %s\t%s\n", v.Name, need)
+				continue
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", v.Name, need, description)
+		}
+		_ = tw.Flush()
+	}
+	if len(t.Requires) > 0 {
+		_, _ = fmt.Fprintf(out, "\nRequires: %s\n", strings.Join(t.Requires, ", "))
+	}
+	if len(t.Hooks) > 0 {
+		_, _ = fmt.Fprintln(out, "\nPost-apply hooks:")
+		for _, hook := range t.Hooks {
+			_, _ = fmt.Fprintf(out, "  • %s\n", hook)
+		}
+	}
+
+	apply := "ddx templates apply " + name
+	for _, v := range missing {
+		apply += fmt.Sprintf(" --var %s=<value>", v)
+	}
+	_, _ = fmt.Fprintf(out, "\nApply with: %s --dry-run\n", apply)
+	return nil
+}
+
+// writeFileTree prints slash-separated paths as a tree, each line starting
+// with indent
+func writeFileTree(out io.Writer, paths []string, indent string) {
+	type node struct {
+		children map[string]*node
+	}
+	root := &node{children: map[string]*node{}}
+	for _, path := range paths {
+		current := root
+		for _, part := range strings.Split(path, "/") {
+			next, ok := current.children[part]
+			if !ok {
+				next = &node{children: map[string]*node{}}
+				current.children[part] = next
+			}
+			current = next
+		}
+	}
+
+	var walk func(n *node, prefix string)
+	walk = func(n *node, prefix string) {
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			connector, nextPrefix := "├── ", prefix+"│   "
+			if i == len(names)-1 {
+				connector, nextPrefix = "└── ", prefix+"    "
+			}
+			child := n.children[name]
+			if len(child.children) > 0 {
+				name += "/"
+			}
+			_, _ = fmt.Fprintf(out, "%s%s%s%s\n", indent, prefix, connector, name)
+			walk(child, nextPrefix)
+		}
+	}
+	walk(root, "")
+}
+
+// completeTemplates completes template names for show and apply
+func (f *CommandFactory) completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	found, err := listTemplates(f.WorkingDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, t := range found {
+		if strings.HasPrefix(t.Name, toComplete) {
+			names = append(names, t.Name+"\t"+t.Description)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// runTemplateApply applies a library template to the project, or previews
+// it with --dry-run
+func (f *CommandFactory) runTemplateApply(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	showDiff, _ := cmd.Flags().GetBool("diff")
+	force, _ := cmd.Flags().GetBool("force")
+	pairs, _ := cmd.Flags().GetStringArray("var")
+	noRecord, _ := cmd.Flags().GetBool("no-record")
+
+	provided, err := variables.ParseAssignments(pairs)
+	if err != nil {
+		return err
+	}
+	dir, _, err := findTemplate(f.WorkingDir, name)
+	if err != nil {
+		return err
+	}
+
+	registry, err := variables.Load(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+	plan, err := templates.Plan(dir, f.WorkingDir, registry.Merge(provided), force)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	out := cmd.OutOrStdout()
+	if dryRun {
+		_, _ = fmt.Fprintf(out, "Template '%s' would change the project (dry run):\n", name)
+		displayTemplatePlan(out, plan, showDiff)
+		return nil
+	}
+
+	// The files, the recorded variables and the post-apply hooks succeed
+	// together; when a hook fails the files are taken back out
+	tx := transaction.Begin(f.WorkingDir)
+	ind := newProgress(cmd)
+	ind.Start(fmt.Sprintf("Applying template '%s'...", name))
+	result, err := plan.Stage(cmd.Context(), tx)
+	if err == nil {
+		err = tx.Apply()
+	}
+	ind.Stop()
+	if err == nil && !noRecord && registry.Record(provided) {
+		if err = tx.Track(variables.Path(f.WorkingDir)); err == nil {
+			err = registry.Save()
+		}
+	}
+	if err != nil {
+		return transactionError(tx.Fail(fmt.Sprintf("applying template '%s'", name), err))
+	}
+	_, _ = fmt.Fprintf(out, "Applied template '%s': %d created, %d modified, %d skipped\n",
+		name, len(result.Created), len(result.Modified), len(result.Skipped))
+	displayTemplatePlan(out, plan, showDiff)
+	if err := templates.NewHookRunner(out).RunPostApply(cmd.Context(), plan.Manifest, f.WorkingDir); err != nil {
+		return transactionError(tx.Fail(fmt.Sprintf("applying template '%s'", name), err))
+	}
+	return tx.Commit()
+}
+
+// displayTemplatePlan lists what a template does to each file, followed by
+// the file's diff when showDiff is set
+func displayTemplatePlan(out io.Writer, plan *templates.ApplyPlan, showDiff bool) {
+	for _, file := range plan.Files {
+		note := ""
+		if file.Status == templates.FileSkipped {
+			note = " (unchanged)"
+			if file.Differs {
+				note = " (differs; use --force to overwrite)"
+			}
+		}
+		_, _ = fmt.Fprintf(out, "  %-9s %s%s\n", file.Status, file.Path, note)
+		if showDiff && file.Status != templates.FileSkipped {
+			writeTemplateDiff(out, file)
+		}
+	}
+}
+
+// writeTemplateDiff prints the change a planned file makes to the project
+func writeTemplateDiff(out io.Writer, file templates.PlannedFile) {
+	from := file.Path
+	if file.Status == templates.FileCreated {
+		from = "/dev/null"
+	}
+	switch {
+	case file.Asset != nil:
+		_, _ = fmt.Fprintf(out, "Asset %s (sha256 %s) differs\n", file.Path, file.Asset.SHA256)
+	case strings.ContainsRune(file.Current, 0) || strings.ContainsRune(file.Content, 0):
+		_, _ = fmt.Fprintf(out, "Binary files %s and %s (template) differ\n", from, file.Path)
+	default:
+		writeDiff(out, unifiedDiff(file.Current, file.Content, from, file.Path+" (template)"))
+	}
+}
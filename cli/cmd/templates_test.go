@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateApply(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/templates/service/template.yml", "name: service\nvariables:\n  - name: project_name\n    required: true\n")
+	env.CreateFile(".ddx/library/templates/service/README.md", "# {{project_name}}\n")
+	env.CreateFile(".ddx/library/templates/service/Makefile", "build:\n\tgo build ./cmd/{{project_name}}\n")
+	env.CreateFile("Makefile", "build:\n\tmake all\n")
+
+	t.Run("dry run with diff writes nothing", func(t *testing.T) {
+		output, err := env.RunCommand("templates", "apply", "service", "--dry-run", "--diff", "--force", "--var", "project_name=demo", "--no-record")
+		require.NoError(t, err)
+		assert.Contains(t, output, "created   README.md")
+		assert.Contains(t, output, "modified  Makefile")
+		assert.Contains(t, output, "+++ README.md (template)")
+		assert.Contains(t, output, "+# demo")
+		assert.Contains(t, output, "-\tmake all")
+		assert.Contains(t, output, "+\tgo build ./cmd/demo")
+		assert.NoFileExists(t, filepath.Join(env.Dir, "README.md"))
+	})
+
+	t.Run("existing files are kept", func(t *testing.T) {
+		output, err := env.RunCommand("template", "apply", "service", "--var", "project_name=demo", "--no-record")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Applied template 'service': 1 created, 0 modified, 1 skipped")
+		assert.Contains(t, output, "skipped   Makefile (differs; use --force to overwrite)")
+
+		readme, err := os.ReadFile(filepath.Join(env.Dir, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# demo\n", string(readme))
+		makefile, err := os.ReadFile(filepath.Join(env.Dir, "Makefile"))
+		require.NoError(t, err)
+		assert.Equal(t, "build:\n\tmake all\n", string(makefile))
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := env.RunCommand("templates", "apply", "missing")
+		assert.ErrorContains(t, err, "template 'missing' not found")
+	})
+
+	t.Run("a failing hook rolls the files back", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("hooks run through a POSIX shell")
+		}
+		env.CreateFile(".ddx/library/templates/hooked/template.yml", "name: hooked\nhooks:\n  post_apply:\n    - name: Fail\n      run: exit 3\n")
+		env.CreateFile(".ddx/library/templates/hooked/docs/guide.md", "# Guide\n")
+		env.CreateFile(".ddx/library/templates/hooked/Makefile", "build:\n\ttrue\n")
+
+		_, err := env.RunCommand("templates", "apply", "hooked", "--force", "--var", "team=core")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "applying template 'hooked' failed and its changes were rolled back")
+		assert.NoDirExists(t, filepath.Join(env.Dir, "docs"))
+		makefile, err := os.ReadFile(filepath.Join(env.Dir, "Makefile"))
+		require.NoError(t, err)
+		assert.Equal(t, "build:\n\tmake all\n", string(makefile))
+		assert.NoFileExists(t, filepath.Join(env.Dir, ".ddx", "variables.yml"))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		root := NewCommandFactory(env.Dir).NewRootCommand()
+		root.SetContext(ctx)
+		_, err := executeCommand(root, "templates", "apply", "service", "--var", "project_name=demo", "--force", "--no-record")
+		assert.ErrorIs(t, err, context.Canceled)
+		makefile, err := os.ReadFile(filepath.Join(env.Dir, "Makefile"))
+		require.NoError(t, err)
+		assert.Equal(t, "build:\n\tmake all\n", string(makefile))
+	})
+}
+
+// createTemplateLibraries creates a project with a main library and an
+// internal source, both with a service template
+func createTemplateLibraries(t *testing.T) *TestEnvironment {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+  sources:
+    - name: internal
+      path: .ddx/internal
+      priority: 10
+`)
+	env.CreateFile(".ddx/library/templates/service/template.yml", `name: service
+description: Public service skeleton
+`)
+	env.CreateFile(".ddx/library/templates/service/README.md", "# Service\n")
+	env.CreateFile(".ddx/library/templates/cli/template.yml", `name: cli
+description: Command-line tool
+variables:
+  - name: binary
+    description: Name of the binary
+    default: app
+`)
+	env.CreateFile(".ddx/library/templates/cli/main.go", "package main\n")
+	env.CreateFile(".ddx/internal/templates/service/template.yml", `name: service
+description: Internal service skeleton
+variables:
+  - name: project_name
+    description: Name of the project
+    required: true
+  - name: team
+    description: Owning team
+    required: true
+  - name: port
+    default: "8080"
+requires:
+  - go >= 1.21
+hooks:
+  post_apply:
+    - name: Tidy
+      run: go mod tidy
+`)
+	env.CreateFile(".ddx/internal/templates/service/README.md", "# {{project_name}}\n")
+	env.CreateFile(".ddx/internal/templates/service/cmd/{{project_name}}/main.go", "package main\n")
+	env.CreateFile(".ddx/internal/templates/service/Makefile", "build:\n")
+	return env
+}
+
+func TestTemplatesList(t *testing.T) {
+	env := createTemplateLibraries(t)
+
+	output, err := env.RunCommand("templates", "list")
+	require.NoError(t, err)
+	assert.Regexp(t, `cli\s+library\s+Command-line tool\s+binary\n`, output)
+	assert.Regexp(t, `service\s+internal\s+Internal service skeleton\s+project_name\*, team\*, port\n`, output)
+	assert.NotContains(t, output, "Public service skeleton", "shadowed templates are left out")
+	assert.Contains(t, output, "* required")
+
+	output, err = env.RunCommand("templates", "list", "--json")
+	require.NoError(t, err)
+	var listed []libraryTemplate
+	require.NoError(t, json.Unmarshal([]byte(output), &listed))
+	require.Len(t, listed, 2)
+	assert.Equal(t, "service", listed[1].Name)
+	assert.Equal(t, "internal", listed[1].Source)
+	assert.Len(t, listed[1].Variables, 3)
+
+	empty := NewTestEnvironment(t, WithGitInit(false))
+	empty.CreateDefaultConfig()
+	output, err = empty.RunCommand("templates", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "No templates found in the library")
+}
+
+func TestTemplateShow(t *testing.T) {
+	env := createTemplateLibraries(t)
+	env.CreateFile(".ddx/variables.yml", "variables:\n  team: platform\n")
+
+	output, err := env.RunCommand("templates", "show", "service")
+	require.NoError(t, err)
+	assert.Contains(t, output, "📦 service (internal)")
+	assert.Contains(t, output, "Internal service skeleton")
+	assert.Contains(t, output, "Files (3):")
+	assert.Contains(t, output, `  ├── Makefile
+  ├── README.md
+  └── cmd/
+      └── {{project_name}}/
+          └── main.go
+`)
+	assert.NotContains(t, output, "template.yml")
+	assert.Regexp(t, `project_name\s+required\s+Name of the project\n`, output)
+	assert.Regexp(t, `team\s+required\s+Owning team \(✓ platform in .ddx/variables.yml\)\n`, output)
+	assert.Regexp(t, `port\s+default 8080\n`, output)
+	assert.Contains(t, output, "Requires: go >= 1.21")
+	assert.Contains(t, output, "• Tidy: go mod tidy")
+	assert.Contains(t, output, "Apply with: ddx templates apply service --var project_name=<value> --dry-run")
+
+	output, err = env.RunCommand("templates", "show", "library:service")
+	require.NoError(t, err)
+	assert.Contains(t, output, "📦 service (library)")
+	assert.Contains(t, output, "Apply with: ddx templates apply library:service --dry-run")
+	assert.Contains(t, output, "Public service skeleton")
+
+	output, err = env.RunCommand("templates", "show", "cli", "--json")
+	require.NoError(t, err)
+	var shown libraryTemplate
+	require.NoError(t, json.Unmarshal([]byte(output), &shown))
+	assert.Equal(t, []string{"main.go"}, shown.Files)
+	assert.Equal(t, "app", shown.Variables[0].Default)
+
+	_, err = env.RunCommand("templates", "show", "missing")
+	assert.ErrorContains(t, err, "template 'missing' not found")
+}
+
+func TestTemplateApplyFromSource(t *testing.T) {
+	env := createTemplateLibraries(t)
+
+	output, err := env.RunCommand("templates", "apply", "library:service", "--no-record")
+	require.NoError(t, err)
+	assert.Contains(t, output, "created   README.md")
+	readme, err := os.ReadFile(filepath.Join(env.Dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Service\n", string(readme))
+}
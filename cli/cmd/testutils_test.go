@@ -9,8 +9,10 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/testutil"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
@@ -362,6 +364,22 @@ func (te *TestEnvironment) CreateFile(relativePath, content string) {
 	require.NoError(te.t, os.WriteFile(fullPath, []byte(content), 0644))
 }
 
+// fixedClock is a Clock that always tells the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+// Library returns a builder for a library at a path in the test
+// environment, .ddx/library when none is given
+func (te *TestEnvironment) Library(path ...string) *testutil.LibraryBuilder {
+	te.t.Helper()
+	dir := filepath.Join(te.Dir, ".ddx", "library")
+	if len(path) > 0 {
+		dir = filepath.Join(te.Dir, path[0])
+	}
+	return testutil.NewLibrary(te.t, dir)
+}
+
 // NewTestRootCommand creates a fresh root command for tests using isolated temp directory
 // This is the preferred way to create test commands - it ensures test isolation.
 func NewTestRootCommand(t *testing.T) *CommandFactory {
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// startupBudget is how long 'ddx version' and 'ddx --help' may take, as
+// measured by the startup benchmarks and the budget test
+const startupBudget = 50 * time.Millisecond
+
+// phaseTiming is how long one phase of an invocation took
+type phaseTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// phaseTimings records the phases of an invocation for --debug-timings.
+// Each mark closes the phase that began at the previous one.
+type phaseTimings struct {
+	start  time.Time
+	last   time.Time
+	phases []phaseTiming
+}
+
+// newPhaseTimings starts timing an invocation
+func newPhaseTimings() *phaseTimings {
+	now := time.Now()
+	return &phaseTimings{start: now, last: now}
+}
+
+// mark ends the current phase, naming it
+func (t *phaseTimings) mark(name string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.phases = append(t.phases, phaseTiming{Name: name, Duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// total is the time from the start of the invocation to the last mark
+func (t *phaseTimings) total() time.Duration {
+	return t.last.Sub(t.start)
+}
+
+// report writes a table of the phases and their share of the total
+func (t *phaseTimings) report(w io.Writer) {
+	total := t.total()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PHASE\tTIME\tSHARE")
+	for _, p := range t.phases {
+		share := 0.0
+		if total > 0 {
+			share = float64(p.Duration) / float64(total) * 100
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.0f%%\n", p.Name, p.Duration.Round(time.Microsecond), share)
+	}
+	_, _ = fmt.Fprintf(tw, "total\t%s\n", total.Round(time.Microsecond))
+	_ = tw.Flush()
+}
+
+// reportTimings writes the phase timings to stderr when --debug-timings
+// was given
+func (f *CommandFactory) reportTimings(root *cobra.Command) {
+	if f.timings == nil {
+		return
+	}
+	if enabled, _ := root.PersistentFlags().GetBool("debug-timings"); enabled {
+		f.timings.mark("finish")
+		f.timings.report(root.ErrOrStderr())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/easel/ddx/internal/userdirs"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -68,10 +69,17 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 				_ = os.Remove(configPath)
 			}
 
-			ddxDir := filepath.Join(home, ".ddx")
-			if _, err := os.Stat(ddxDir); err == nil {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removing directory: %s\n", ddxDir)
-				_ = os.RemoveAll(ddxDir)
+			dirs := []string{filepath.Join(home, userdirs.LegacyDirName)}
+			for _, kind := range userdirs.Kinds {
+				if dir, err := userdirs.Dir(kind); err == nil {
+					dirs = append(dirs, dir)
+				}
+			}
+			for _, dir := range dirs {
+				if _, err := os.Stat(dir); err == nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removing directory: %s\n", dir)
+					_ = os.RemoveAll(dir)
+				}
 			}
 		}
 	}
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/easel/ddx/internal/catalog"
 	"github.com/easel/ddx/internal/config"
-	"github.com/easel/ddx/internal/metaprompt"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/lock"
+	"github.com/easel/ddx/internal/progress"
+	"github.com/easel/ddx/internal/promptsync"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +31,13 @@ type UpdateOptions struct {
 	Interactive bool
 	Abort       bool
 	DryRun      bool
-	Resource    string // selective update resource
+	JSON        bool
+	// Format is a Go template applied to each change in the --check report
+	Format   string
+	Frozen   bool
+	Resource string // selective update resource
+	// Source names the library source to update, empty for the main library
+	Source string
 }
 
 // ConflictInfo represents information about a detected conflict
@@ -44,6 +57,15 @@ type UpdateResult struct {
 	UpdatedFiles []string
 	Conflicts    []ConflictInfo
 	BackupPath   string
+	AuthNotice   string
+	Check        *UpdateCheck
+	// LockUpdated is set when ddx.lock was refreshed
+	LockUpdated bool
+	// Snapshot is the ID of the state recorded before the update
+	Snapshot string
+	// Pinned is the ref the library is pinned to when the update left it
+	// there
+	Pinned string
 }
 
 // CommandFactory method - CLI interface layer
@@ -53,9 +75,12 @@ func (f *CommandFactory) runUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	announceUpdateCheck(cmd, opts)
 
 	// Call pure business logic
-	result, err := performUpdate(f.WorkingDir, opts)
+	ind := updateProgress(cmd, opts)
+	result, err := performUpdate(cmd.Context(), f.WorkingDir, opts)
+	ind.Stop()
 	if err != nil {
 		return err
 	}
@@ -65,7 +90,7 @@ func (f *CommandFactory) runUpdate(cmd *cobra.Command, args []string) error {
 }
 
 // Pure business logic function
-func performUpdate(workingDir string, opts *UpdateOptions) (*UpdateResult, error) {
+func performUpdate(ctx context.Context, workingDir string, opts *UpdateOptions) (*UpdateResult, error) {
 	result := &UpdateResult{}
 
 	// Check if we're in a DDx project
@@ -84,14 +109,54 @@ func performUpdate(workingDir string, opts *UpdateOptions) (*UpdateResult, error
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// A frozen update only verifies the library against ddx.lock
+	if opts.Frozen {
+		l, err := verifyProjectLock(workingDir, cfg)
+		if err != nil {
+			return nil, err
+		}
+		message := fmt.Sprintf("Library matches %s (%d assets)", lock.FileName, len(l.Assets))
+		if l.Library.Commit != "" {
+			message = fmt.Sprintf("Library matches %s at %s (%d assets)", lock.FileName, shortCommit(l.Library.Commit), len(l.Assets))
+		}
+		return &UpdateResult{Success: true, Message: message}, nil
+	}
+
+	// Another library source is updated like the main library, apart
+	// from the lockfile and meta-prompt that only follow the main one
+	mainLibrary := opts.Source == "" || opts.Source == config.MainSource
+	if cfg, err = sourceConfig(cfg, opts.Source); err != nil {
+		return nil, err
+	}
+
+	// A pinned library stays at its ref unless the update is forced
+	if ref := cfg.Library.Repository.Ref; ref != "" && !opts.Check && !opts.Force {
+		return &UpdateResult{
+			Success: true,
+			Message: fmt.Sprintf("Library is pinned to %s - not updating past it", ref),
+			Pinned:  ref,
+		}, nil
+	}
+
 	// Handle dry-run mode - preview changes without applying
 	if opts.DryRun {
 		return previewUpdateInDir(workingDir, cfg, opts)
 	}
 
+	// Select SSH or HTTPS access to the library before any network operation
+	remote, err := resolveLibraryRemote(ctx, workingDir, cfg.Library.Repository.URL)
+	if err != nil {
+		return nil, err
+	}
+	authNotice := libraryRemoteNotice(ctx, cfg.Library.Repository.URL, remote)
+
 	// Handle check flag - just check for updates
 	if opts.Check {
-		return checkForUpdatesInDir(workingDir, cfg, opts)
+		checkResult, err := checkForUpdatesInDir(ctx, workingDir, cfg, remote.URL, opts)
+		if checkResult != nil {
+			checkResult.AuthNotice = authNotice
+		}
+		return checkResult, err
 	}
 
 	// Validate strategy flags
@@ -101,7 +166,11 @@ func performUpdate(workingDir string, opts *UpdateOptions) (*UpdateResult, error
 
 	// Handle sync flag
 	if opts.Sync {
-		return synchronizeWithUpstreamInDir(workingDir, cfg, opts)
+		syncResult, err := synchronizeWithUpstreamInDir(workingDir, cfg, opts, remote.URL)
+		if syncResult != nil {
+			syncResult.AuthNotice = authNotice
+		}
+		return syncResult, err
 	}
 
 	// Check for conflicts before updating
@@ -116,18 +185,62 @@ func performUpdate(workingDir string, opts *UpdateOptions) (*UpdateResult, error
 		return handleInteractiveResolutionInDir(workingDir, conflicts, opts)
 	}
 
+	// Refuse a library selection that drops assets the project uses
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	libDir := projectLibraryDir(workingDir, cfg)
+	if err := checkSelectionReferences(cfg, libDir, sel); err != nil {
+		return nil, err
+	}
+
+	// Record the current state so 'ddx rollback' can restore it
+	snap, err := takeSnapshot(workingDir, cfg, "update")
+	if err != nil {
+		return nil, err
+	}
+
 	// Perform the actual update
-	updateResult, err := executeUpdateInDir(workingDir, cfg, opts)
+	updateResult, err := executeUpdateInDir(workingDir, cfg, opts, remote.URL)
 	if err != nil {
 		return nil, err
 	}
+	updateResult.AuthNotice = authNotice
+	updateResult.Snapshot = snap.ID
+
+	// Index the updated library so listing does not have to scan it
+	if _, err := catalog.Build(libDir); err != nil {
+		slog.Warn("failed to index library", "error", err)
+	}
+
+	// Keep an existing lockfile in step with the library
+	if _, err := os.Stat(lock.Path(workingDir)); err == nil && mainLibrary {
+		if _, err := writeProjectLock(workingDir, cfg); err != nil {
+			return nil, err
+		}
+		updateResult.LockUpdated = true
+	}
+
+	// Keep the ddx block of .gitignore in step with the configuration
+	if mainLibrary {
+		if _, err := syncGitignore(workingDir, cfg); err != nil {
+			slog.Warn("failed to update .gitignore", "error", err)
+		}
+	}
 
 	// Always sync meta-prompt after update (even if no library changes), unless in CI mode
-	if os.Getenv("CI") == "" {
+	if os.Getenv("CI") == "" && mainLibrary {
 		if err := syncMetaPrompt(cfg, workingDir); err != nil {
 			// Warn but don't fail - only if prompts directory exists
 			if _, statErr := os.Stat(filepath.Join(workingDir, cfg.Library.Path, "prompts")); statErr == nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to sync meta-prompt: %v\n", err)
+				slog.Warn("failed to sync meta-prompt", "error", err)
+			}
+		}
+		// Refresh prompt files written by 'ddx prompts sync'
+		if _, err := os.Stat(promptsync.Path(workingDir)); err == nil {
+			if _, err := syncPromptFiles(workingDir, promptsync.DefaultDir, nil, "", false); err != nil {
+				slog.Warn("failed to refresh synced prompts", "error", err)
 			}
 		}
 	}
@@ -149,6 +262,10 @@ func extractUpdateOptions(cmd *cobra.Command, args []string) (*UpdateOptions, er
 	opts.Interactive, _ = cmd.Flags().GetBool("interactive")
 	opts.Abort, _ = cmd.Flags().GetBool("abort")
 	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.JSON, _ = cmd.Flags().GetBool("json")
+	opts.Format, _ = cmd.Flags().GetString("format")
+	opts.Frozen, _ = cmd.Flags().GetBool("frozen")
+	opts.Source, _ = cmd.Flags().GetString("source")
 
 	// Handle mine/theirs flags by converting to strategy
 	updateMine, _ := cmd.Flags().GetBool("mine")
@@ -186,12 +303,8 @@ func loadConfigFromWorkingDirForUpdate(workingDir string) (*config.Config, error
 		return config.Load()
 	}
 
-	configPath := filepath.Join(workingDir, ".ddx/config.yaml")
-	if _, err := os.Stat(configPath); err == nil {
-		return config.LoadFromFile(configPath)
-	}
-
-	return config.Load()
+	// Load through the standard chain so the active profile is honored
+	return config.LoadWithWorkingDir(workingDir)
 }
 
 func validateUpdateStrategy(opts *UpdateOptions) error {
@@ -216,15 +329,49 @@ func validateUpdateStrategy(opts *UpdateOptions) error {
 	return nil
 }
 
-func checkForUpdatesInDir(workingDir string, cfg *config.Config, opts *UpdateOptions) (*UpdateResult, error) {
-	result := &UpdateResult{
+// checkForUpdatesInDir compares the library with upstream without applying
+// anything
+func checkForUpdatesInDir(ctx context.Context, workingDir string, cfg *config.Config, remoteURL string, opts *UpdateOptions) (*UpdateResult, error) {
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// A forced update goes past the pin, so compare with the branch
+	revision := cfg.Library.Repository.Revision()
+	if opts.Force {
+		revision = cfg.Library.Repository.Branch
+	}
+	check, err := checkLibraryUpdates(ctx, workingDir, cfg.Library.Path, remoteURL, revision, opts.Resource, sel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	check.Pinned = revision == cfg.Library.Repository.Ref
+	return &UpdateResult{
 		Success: true,
 		Message: "Update check completed",
+		Check:   check,
+	}, nil
+}
+
+// announceUpdateCheck reports progress before the upstream fetch, which
+// can take a while
+func announceUpdateCheck(cmd *cobra.Command, opts *UpdateOptions) {
+	if !opts.Check || opts.JSON || opts.Format != "" {
+		return
 	}
+	_, _ = fmt.Fprintln(statusOut(cmd), "Checking for updates...")
+	_, _ = fmt.Fprintln(statusOut(cmd), "Fetching latest changes from master repository...")
+}
 
-	// In a real implementation, this would check git remote for actual updates
-	// For now, provide basic output
-	return result, nil
+// updateProgress starts the spinner shown while the library is updated,
+// unless the update may ask how to resolve conflicts
+func updateProgress(cmd *cobra.Command, opts *UpdateOptions) *progress.Indicator {
+	if opts.Interactive || opts.JSON || opts.Format != "" {
+		return nil
+	}
+	ind := newProgress(cmd)
+	ind.Start("Updating the library...")
+	return ind
 }
 
 func previewUpdateInDir(workingDir string, cfg *config.Config, opts *UpdateOptions) (*UpdateResult, error) {
@@ -243,16 +390,64 @@ func previewUpdateInDir(workingDir string, cfg *config.Config, opts *UpdateOptio
 	return result, nil
 }
 
-func synchronizeWithUpstreamInDir(workingDir string, cfg *config.Config, opts *UpdateOptions) (*UpdateResult, error) {
+func synchronizeWithUpstreamInDir(workingDir string, cfg *config.Config, opts *UpdateOptions, remoteURL string) (*UpdateResult, error) {
 	result := &UpdateResult{
 		Success: true,
 		Message: "Synchronized with upstream",
 	}
 
-	// In real implementation, would perform git synchronization
+	if _, err := pullLibrary(workingDir, cfg, opts, remoteURL); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// pullLibrary pulls the upstream library into its git subtree through
+// remoteURL, the URL resolveLibraryRemote chose, and reports whether it
+// did. A library that is not a subtree of the project is left alone, and
+// a project with uncommitted changes to tracked files is refused because
+// the pull resets the working tree.
+func pullLibrary(workingDir string, cfg *config.Config, opts *UpdateOptions, remoteURL string) (bool, error) {
+	prefix := filepath.ToSlash(cfg.Library.Path)
+	if git.SubtreeSyncCommit(workingDir, prefix) == "" {
+		return false, nil
+	}
+	dir := workingDir
+	if dir == "" {
+		dir = "."
+	}
+	status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check git status: %w", err)
+	}
+	if len(strings.TrimSpace(string(status))) > 0 {
+		return false, fmt.Errorf("uncommitted changes in the project - commit or stash them before updating the library")
+	}
+
+	// A forced update goes past the pin, so pull the branch
+	revision := cfg.Library.Repository.Revision()
+	if opts.Force {
+		revision = cfg.Library.Repository.Branch
+	}
+
+	// Subtree operations run in the project directory
+	if workingDir != "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return false, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		defer func() { _ = os.Chdir(currentDir) }()
+		if err := os.Chdir(workingDir); err != nil {
+			return false, fmt.Errorf("failed to change to working directory: %w", err)
+		}
+	}
+	if err := git.SubtreePull(prefix, remoteURL, revision); err != nil {
+		// Name the URL as configured so a stored secret is not printed
+		return false, fmt.Errorf("failed to pull library from %s: %w", cfg.Library.Repository.URL, err)
+	}
+	return true, nil
+}
+
 func detectConflictsInDir(workingDir string) []ConflictInfo {
 	var conflicts []ConflictInfo
 
@@ -378,7 +573,7 @@ func handleInteractiveResolutionInDir(workingDir string, conflicts []ConflictInf
 	return result, nil
 }
 
-func executeUpdateInDir(workingDir string, cfg *config.Config, opts *UpdateOptions) (*UpdateResult, error) {
+func executeUpdateInDir(workingDir string, cfg *config.Config, opts *UpdateOptions, remoteURL string) (*UpdateResult, error) {
 	result := &UpdateResult{
 		Success: true,
 		Message: "DDx updated successfully!",
@@ -398,6 +593,14 @@ func executeUpdateInDir(workingDir string, cfg *config.Config, opts *UpdateOptio
 		result.Message += fmt.Sprintf(" Conflicts resolved using '%s' strategy.", opts.Strategy)
 	}
 
+	// A subtree pull brings in the whole library, so it is skipped when a
+	// single resource is updated
+	if opts.Resource == "" {
+		if _, err := pullLibrary(workingDir, cfg, opts, remoteURL); err != nil {
+			return nil, err
+		}
+	}
+
 	// Simulate the update process
 	if opts.Resource != "" {
 		result.UpdatedFiles = []string{opts.Resource}
@@ -491,21 +694,34 @@ func displayUpdateResult(cmd *cobra.Command, result *UpdateResult, opts *UpdateO
 	out := cmd.OutOrStdout()
 	writer := out.(io.Writer)
 
+	// Handle check mode
+	if opts.Check && result.Check != nil {
+		if result.AuthNotice != "" && !opts.JSON && opts.Format == "" {
+			_, _ = yellow.Fprintf(writer, "⚠️  %s\n\n", result.AuthNotice)
+		}
+		return displayUpdateCheck(writer, result.Check, opts.JSON, opts.Format)
+	}
+
 	// Display initial message based on operation type
+	status := statusOut(cmd)
 	if opts.Resource != "" {
 		if opts.DryRun {
-			_, _ = cyan.Fprintf(writer, "🔍 Preview update for DDx toolkit: %s...\n", opts.Resource)
+			_, _ = cyan.Fprintf(status, "🔍 Preview update for DDx toolkit: %s...\n", opts.Resource)
 		} else {
-			_, _ = cyan.Fprintf(writer, "🔄 Updating DDx toolkit: %s...\n", opts.Resource)
+			_, _ = cyan.Fprintf(status, "🔄 Updating DDx toolkit: %s...\n", opts.Resource)
 		}
 	} else {
 		if opts.DryRun {
-			_, _ = cyan.Fprintln(writer, "🔍 Preview update for DDx toolkit...")
+			_, _ = cyan.Fprintln(status, "🔍 Preview update for DDx toolkit...")
 		} else {
-			_, _ = cyan.Fprintln(writer, "🔄 Updating DDx toolkit...")
+			_, _ = cyan.Fprintln(status, "🔄 Updating DDx toolkit...")
 		}
 	}
-	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(status)
+
+	if result.AuthNotice != "" {
+		_, _ = yellow.Fprintf(writer, "⚠️  %s\n\n", result.AuthNotice)
+	}
 
 	// Handle error cases
 	if !result.Success {
@@ -516,12 +732,9 @@ func displayUpdateResult(cmd *cobra.Command, result *UpdateResult, opts *UpdateO
 		return nil
 	}
 
-	// Handle check mode
-	if opts.Check {
-		_, _ = fmt.Fprintln(writer, "Checking for updates...")
-		_, _ = fmt.Fprintln(writer, "Fetching latest changes from master repository...")
-		_, _ = fmt.Fprintln(writer, "Available updates:")
-		_, _ = fmt.Fprintln(writer, "Changes since last update:")
+	if result.Pinned != "" {
+		_, _ = yellow.Fprintln(writer, "📌", result.Message)
+		_, _ = fmt.Fprintln(status, "💡 'ddx update --force' updates past the pin; 'ddx library unpin' removes it")
 		return nil
 	}
 
@@ -530,6 +743,11 @@ func displayUpdateResult(cmd *cobra.Command, result *UpdateResult, opts *UpdateO
 		return displayDryRunResult(out, result, opts)
 	}
 
+	if opts.Frozen {
+		_, _ = green.Fprintln(writer, "✅", result.Message)
+		return nil
+	}
+
 	// Display success message
 	_, _ = green.Fprintln(writer, "✅", result.Message)
 	_, _ = fmt.Fprintln(out)
@@ -543,6 +761,14 @@ func displayUpdateResult(cmd *cobra.Command, result *UpdateResult, opts *UpdateO
 		_, _ = fmt.Fprintln(out)
 	}
 
+	if result.LockUpdated {
+		_, _ = fmt.Fprintf(writer, "🔒 Updated %s\n\n", lock.FileName)
+	}
+
+	if result.Snapshot != "" {
+		_, _ = fmt.Fprintf(writer, "📸 Saved snapshot %s; run 'ddx rollback' to undo this update\n\n", result.Snapshot)
+	}
+
 	// Show backup info
 	if result.BackupPath != "" {
 		_, _ = yellow.Fprintf(out, "💾 Backup created at: %s\n", result.BackupPath)
@@ -550,11 +776,11 @@ func displayUpdateResult(cmd *cobra.Command, result *UpdateResult, opts *UpdateO
 	}
 
 	// Show next steps
-	_, _ = fmt.Fprintln(out)
-	_, _ = green.Fprintln(writer, "💡 Next steps:")
-	_, _ = fmt.Fprintln(writer, "  • Review updated resources in .ddx/")
-	_, _ = fmt.Fprintln(writer, "  • Run 'ddx diagnose' to check your project health")
-	_, _ = fmt.Fprintln(writer, "  • Apply new patterns with 'ddx apply <pattern>'")
+	_, _ = fmt.Fprintln(status)
+	_, _ = green.Fprintln(status, "💡 Next steps:")
+	_, _ = fmt.Fprintln(status, "  • Review updated resources in .ddx/")
+	_, _ = fmt.Fprintln(status, "  • Run 'ddx diagnose' to check your project health")
+	_, _ = fmt.Fprintln(status, "  • Apply new patterns with 'ddx apply <pattern>'")
 
 	return nil
 }
@@ -683,9 +909,12 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	announceUpdateCheck(cmd, opts)
 
 	// Call pure business logic
-	result, err := performUpdate("", opts)
+	ind := updateProgress(cmd, opts)
+	result, err := performUpdate(cmd.Context(), "", opts)
+	ind.Stop()
 	if err != nil {
 		return err
 	}
@@ -694,26 +923,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	return displayUpdateResult(cmd, result, opts)
 }
 
-// syncMetaPrompt syncs the meta-prompt from library to CLAUDE.md
+// syncMetaPrompt syncs the meta-prompt from library to the AI target files
 func syncMetaPrompt(cfg *config.Config, workingDir string) error {
-	// Get meta-prompt path from config
-	promptPath := cfg.GetMetaPrompt()
-	if promptPath == "" {
-		// Disabled - remove meta-prompt section if exists
-		injector := metaprompt.NewMetaPromptInjectorWithPaths(
-			"CLAUDE.md",
-			cfg.Library.Path,
-			workingDir,
-		)
-		return injector.RemoveMetaPrompt()
-	}
-
-	// Create injector and sync
-	injector := metaprompt.NewMetaPromptInjectorWithPaths(
-		"CLAUDE.md",
-		cfg.Library.Path,
-		workingDir,
-	)
-
-	return injector.InjectMetaPrompt(promptPath)
+	return syncMetaPromptWithConfig(cfg, workingDir)
 }
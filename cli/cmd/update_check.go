@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/library"
+	"github.com/easel/ddx/internal/lock"
+)
+
+// LibraryChange is an upstream library file that differs from the project
+type LibraryChange struct {
+	Path string `json:"path"`
+	// Type is the asset type, the top-level library directory
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// UpdateCheck compares the project's library with its upstream repository
+type UpdateCheck struct {
+	Repository string `json:"repository"`
+	// Branch is the branch, tag or commit the library was compared with
+	Branch string `json:"branch"`
+	// Pinned is set when Branch is the ref the library is pinned to
+	Pinned bool `json:"pinned,omitempty"`
+	// Synced is the upstream commit of the last library sync, if recorded
+	Synced   string          `json:"synced_commit,omitempty"`
+	Upstream string          `json:"upstream_commit"`
+	UpToDate bool            `json:"up_to_date"`
+	Changes  []LibraryChange `json:"changes"`
+}
+
+// checkLibraryUpdates fetches the upstream revision and lists the library
+// files that changed since the last recorded sync. Without a recorded sync
+// the upstream files are compared with the local library instead. Only
+// changes kept by the selection, and under resource when set, are listed.
+func checkLibraryUpdates(ctx context.Context, workingDir, libPath, repoURL, branch, resource string, sel *library.Selection) (*UpdateCheck, error) {
+	snapshot, err := git.FetchSnapshot(ctx, repoURL, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = snapshot.Close() }()
+
+	check := &UpdateCheck{Repository: repoURL, Branch: branch, Upstream: snapshot.Commit, Changes: []LibraryChange{}}
+	libDir := libPath
+	if !filepath.IsAbs(libDir) {
+		libDir = filepath.Join(workingDir, libPath)
+	}
+
+	// Prefer the subtree sync record, then the commit pinned in ddx.lock
+	synced := git.LastSubtreeSync(workingDir, filepath.ToSlash(libPath))
+	if l, err := lock.Load(workingDir); synced == "" && err == nil {
+		synced = l.Library.Commit
+	}
+
+	var changes []git.FileChange
+	if synced != "" && snapshot.HasCommit(synced) {
+		check.Synced = synced
+		if changes, err = snapshot.Diff(synced); err != nil {
+			return nil, err
+		}
+	} else if changes, err = compareWithLibrary(snapshot, libDir); err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Trim(filepath.ToSlash(resource), "/")
+	for _, change := range changes {
+		if prefix != "" && change.Path != prefix && !strings.HasPrefix(change.Path, prefix+"/") {
+			continue
+		}
+		if !sel.Selected(change.Path) {
+			continue
+		}
+		check.Changes = append(check.Changes, LibraryChange{Path: change.Path, Type: assetType(change.Path), Status: change.Status})
+	}
+	sort.Slice(check.Changes, func(i, j int) bool { return check.Changes[i].Path < check.Changes[j].Path })
+	check.UpToDate = len(check.Changes) == 0
+	return check, nil
+}
+
+// compareWithLibrary lists the upstream files that are missing from or
+// differ in the local library. Files that only exist locally are treated as
+// project additions and not listed.
+func compareWithLibrary(snapshot *git.Snapshot, libDir string) ([]git.FileChange, error) {
+	upstream, err := snapshot.Files()
+	if err != nil {
+		return nil, err
+	}
+	var changes []git.FileChange
+	for path, hash := range upstream {
+		data, err := os.ReadFile(filepath.Join(libDir, filepath.FromSlash(path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR):
+			changes = append(changes, git.FileChange{Path: path, Status: git.ChangeAdded})
+		case err != nil:
+			return nil, err
+		case git.BlobHash(data) != hash:
+			changes = append(changes, git.FileChange{Path: path, Status: git.ChangeModified})
+		}
+	}
+	return changes, nil
+}
+
+// assetType returns the asset type of a library path, its top-level
+// directory
+func assetType(path string) string {
+	if dir, _, ok := strings.Cut(path, "/"); ok {
+		return dir
+	}
+	return "library"
+}
+
+// changeMarks prefix each change in the update check report
+var changeMarks = map[string]string{
+	git.ChangeAdded:    "+",
+	git.ChangeModified: "~",
+	git.ChangeDeleted:  "-",
+}
+
+// displayUpdateCheck writes the update check report, as JSON or one line
+// per change formatted with a Go template when asked
+func displayUpdateCheck(w io.Writer, check *UpdateCheck, asJSON bool, format string) error {
+	if format != "" {
+		return renderFormat(w, format, check.Changes)
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(check)
+	}
+
+	if check.Pinned {
+		_, _ = fmt.Fprintf(w, "Library:  %s (pinned to %s)\n", check.Repository, check.Branch)
+	} else {
+		_, _ = fmt.Fprintf(w, "Library:  %s (%s)\n", check.Repository, check.Branch)
+	}
+	if check.Synced != "" {
+		_, _ = fmt.Fprintf(w, "Synced:   %s\n", shortCommit(check.Synced))
+	} else {
+		_, _ = fmt.Fprintln(w, "Synced:   not recorded, comparing with the local library")
+	}
+	_, _ = fmt.Fprintf(w, "Upstream: %s\n\n", shortCommit(check.Upstream))
+
+	_, _ = fmt.Fprintf(w, "Available updates: %d changed file(s)\n", len(check.Changes))
+	_, _ = fmt.Fprintln(w, "Changes since last update:")
+	if check.UpToDate {
+		_, _ = fmt.Fprintln(w, "  none")
+		_, _ = fmt.Fprintln(w, "\n✅ Library is up to date")
+		return nil
+	}
+	lastType := ""
+	for _, change := range check.Changes {
+		if change.Type != lastType {
+			_, _ = fmt.Fprintf(w, "  %s\n", change.Type)
+			lastType = change.Type
+		}
+		_, _ = fmt.Fprintf(w, "    %s %s\n", changeMarks[change.Status], change.Path)
+	}
+	_, _ = fmt.Fprintln(w, "\n💡 Run 'ddx update' to apply them")
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
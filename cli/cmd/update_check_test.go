@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGitIn runs git in dir and returns its trimmed output
+func runGitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// commitUpstream writes files into the upstream repository (removing those
+// with empty content) and commits them, returning the commit hash
+func commitUpstream(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if content == "" {
+			require.NoError(t, os.Remove(path))
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	runGitIn(t, dir, "add", "-A")
+	runGitIn(t, dir, "commit", "-q", "-m", "library change")
+	return runGitIn(t, dir, "rev-parse", "HEAD")
+}
+
+func TestUpdateCheck(t *testing.T) {
+	upstream := t.TempDir()
+	runGitIn(t, upstream, "init", "-q", "-b", "main")
+	first := commitUpstream(t, upstream, map[string]string{
+		"personas/reviewer.md":         "# Reviewer\n",
+		"workflows/helix/workflow.yml": "name: helix\n",
+		"prompts/old.md":               "old\n",
+	})
+	config := fmt.Sprintf("version: \"1.0\"\nlibrary:\n  path: .ddx/library\n  repository:\n    url: file://%s\n    branch: main\n", upstream)
+
+	t.Run("compares with the local library without a recorded sync", func(t *testing.T) {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig(config)
+		env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+		env.CreateFile(".ddx/library/workflows/helix/workflow.yml", "name: helix-local\n")
+		env.CreateFile(".ddx/library/personas/mine.md", "# Mine\n")
+
+		output, err := env.RunCommand("update", "--check")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Synced:   not recorded")
+		assert.Contains(t, output, "Available updates: 2 changed file(s)")
+		assert.Contains(t, output, "  prompts\n    + prompts/old.md\n  workflows\n    ~ workflows/helix/workflow.yml\n")
+		assert.NotContains(t, output, "mine.md", "local additions are not upstream changes")
+
+		env.CreateConfig(config + "  exclude: [prompts]\n")
+		output, err = env.RunCommand("update", "--check")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Available updates: 1 changed file(s)")
+		assert.NotContains(t, output, "prompts/old.md", "excluded paths are not reported")
+	})
+
+	t.Run("lists upstream changes since the last sync", func(t *testing.T) {
+		env := NewTestEnvironment(t)
+		env.CreateConfig(config)
+		runGitIn(t, env.Dir, "commit", "-q", "--allow-empty", "-m",
+			"Squashed '.ddx/library' content\n\ngit-subtree-dir: .ddx/library\ngit-subtree-split: "+first)
+		second := commitUpstream(t, upstream, map[string]string{
+			"personas/security.md":         "# Security\n",
+			"workflows/helix/workflow.yml": "name: helix\nversion: 2\n",
+			"prompts/old.md":               "",
+		})
+
+		output, err := env.RunCommand("update", "--check")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Synced:   "+first[:7])
+		assert.Contains(t, output, "Upstream: "+second[:7])
+		assert.Contains(t, output, "    + personas/security.md")
+		assert.Contains(t, output, "    - prompts/old.md")
+		assert.Contains(t, output, "    ~ workflows/helix/workflow.yml")
+		assert.NoDirExists(t, env.LibraryPath, "check must not apply anything")
+
+		output, err = env.RunCommand("update", "--check", "personas", "--json")
+		require.NoError(t, err, output)
+		var check UpdateCheck
+		require.NoError(t, json.Unmarshal([]byte(output), &check), output)
+		assert.Equal(t, first, check.Synced)
+		assert.Equal(t, second, check.Upstream)
+		assert.False(t, check.UpToDate)
+		assert.Equal(t, []LibraryChange{{Path: "personas/security.md", Type: "personas", Status: "added"}}, check.Changes)
+
+		output, err = env.RunCommand("update", "--check", "--format", `{{.Status}}\t{{.Path}}`)
+		require.NoError(t, err, output)
+		assert.Equal(t, "added\tpersonas/security.md\ndeleted\tprompts/old.md\nmodified\tworkflows/helix/workflow.yml\n", output)
+	})
+}
+
+func TestPullLibraryUsesResolvedURL(t *testing.T) {
+	upstream := t.TempDir()
+	runGitIn(t, upstream, "init", "-q", "-b", "main")
+	first := commitUpstream(t, upstream, map[string]string{"personas/reviewer.md": "# Reviewer\n"})
+	commitUpstream(t, upstream, map[string]string{"personas/reviewer.md": "# Reviewer v2\n"})
+
+	env := NewTestEnvironment(t)
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+	runGitIn(t, env.Dir, "add", "-A")
+	runGitIn(t, env.Dir, "commit", "-q", "-m",
+		"Squashed '.ddx/library' content\n\ngit-subtree-dir: .ddx/library\ngit-subtree-split: "+first)
+
+	// The configured SSH URL is unreachable; the pull must go through the
+	// URL resolveLibraryRemote chose instead
+	cfg := config.DefaultNewConfig()
+	cfg.Library.Path = ".ddx/library"
+	cfg.Library.Repository = &config.RepositoryConfig{URL: "git@example.invalid:org/library.git", Branch: "main"}
+
+	t.Run("refuses uncommitted changes", func(t *testing.T) {
+		env.CreateFile(".ddx/library/personas/reviewer.md", "# Edited\n")
+		_, err := pullLibrary(env.Dir, cfg, &UpdateOptions{}, "file://"+upstream)
+		assert.ErrorContains(t, err, "uncommitted changes")
+		runGitIn(t, env.Dir, "checkout", "--", ".ddx/library")
+	})
+
+	t.Run("pulls through the resolved URL", func(t *testing.T) {
+		pulled, err := pullLibrary(env.Dir, cfg, &UpdateOptions{}, "file://"+upstream)
+		require.NoError(t, err)
+		assert.True(t, pulled)
+		data, err := os.ReadFile(filepath.Join(env.Dir, ".ddx", "library", "personas", "reviewer.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Reviewer v2\n", string(data))
+	})
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateNotification(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	env := NewTestEnvironment(t, WithGitInit(false))
+
+	// A check earlier today found a newer release
+	data, err := json.Marshal(update.CacheData{
+		LastCheck:       time.Now().Add(-time.Hour),
+		CurrentVersion:  "v1.0.0",
+		LatestVersion:   "v1.2.0",
+		UpdateAvailable: true,
+	})
+	require.NoError(t, err)
+	cache := filepath.Join(home, ".ddx", "state", "update-check.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(cache), 0755))
+	require.NoError(t, os.WriteFile(cache, data, 0644))
+
+	run := func(args ...string) (string, string) {
+		factory := NewCommandFactory(env.Dir)
+		factory.Version = "v1.0.0"
+		root := factory.NewRootCommand()
+		var stdout, stderr bytes.Buffer
+		root.SetOut(&stdout)
+		root.SetErr(&stderr)
+		root.SetArgs(args)
+		require.NoError(t, root.Execute())
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := run("quickstart")
+	assert.NotContains(t, stdout, "v1.2.0")
+	assert.Equal(t, "⬆️  ddx v1.2.0 is available (you have v1.0.0); run 'ddx upgrade'\n", stderr)
+
+	_, stderr = run("quickstart", "--quiet")
+	assert.Empty(t, stderr)
+
+	env.CreateConfig(`version: "1.0"
+updates:
+  check: never
+`)
+	_, stderr = run("quickstart")
+	assert.Empty(t, stderr)
+}
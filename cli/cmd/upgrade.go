@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 
+	"github.com/easel/ddx/internal/network"
 	"github.com/easel/ddx/internal/update"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -26,8 +28,8 @@ func (f *CommandFactory) runUpgrade(cmd *cobra.Command, args []string) error {
 
 	out := cmd.OutOrStdout()
 
-	_, _ = cyan.Fprintln(out, "🔍 Checking for DDx updates...")
-	_, _ = fmt.Fprintln(out)
+	_, _ = cyan.Fprintln(statusOut(cmd), "🔍 Checking for DDx updates...")
+	_, _ = fmt.Fprintln(statusOut(cmd))
 
 	// Get current version
 	currentVersion := f.Version
@@ -36,7 +38,7 @@ func (f *CommandFactory) runUpgrade(cmd *cobra.Command, args []string) error {
 	}
 
 	// Fetch latest release from GitHub
-	latestRelease, err := update.FetchLatestRelease()
+	latestRelease, err := update.FetchLatestRelease(cmd.Context(), newGitHubClient())
 	if err != nil {
 		return fmt.Errorf("failed to check for updates: %w", err)
 	}
@@ -78,7 +80,7 @@ func (f *CommandFactory) runUpgrade(cmd *cobra.Command, args []string) error {
 	_, _ = fmt.Fprintln(out)
 
 	// Download and execute install script
-	if err := executeUpgrade(out); err != nil {
+	if err := executeUpgrade(cmd.Context(), out); err != nil {
 		return fmt.Errorf("upgrade failed: %w", err)
 	}
 
@@ -91,9 +93,13 @@ func (f *CommandFactory) runUpgrade(cmd *cobra.Command, args []string) error {
 }
 
 // executeUpgrade downloads and executes the install script
-func executeUpgrade(out io.Writer) error {
+func executeUpgrade(ctx context.Context, out io.Writer) error {
 	// Download install script
-	resp, err := http.Get(installScriptURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, installScriptURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := network.Client().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download install script: %w", err)
 	}
@@ -127,7 +133,7 @@ func executeUpgrade(out io.Writer) error {
 	_ = tmpFile.Close()
 
 	// Execute install script
-	cmd := exec.Command("bash", tmpFile.Name())
+	cmd := exec.CommandContext(ctx, "bash", tmpFile.Name())
 	cmd.Stdout = out
 	cmd.Stderr = out
 	cmd.Stdin = os.Stdin
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// noMigrateEnvVar keeps user files in ~/.ddx, for machines that share it
+// with an older ddx
+const noMigrateEnvVar = "DDX_NO_MIGRATE"
+
+// migrateUserDirs moves the files older versions kept in ~/.ddx to the XDG
+// directories and says what moved. A failure is reported but does not stop
+// the command: files that could not be moved are used where they are.
+func migrateUserDirs(w io.Writer) {
+	if os.Getenv(noMigrateEnvVar) != "" {
+		return
+	}
+	moves, err := userdirs.Migrate()
+	if len(moves) > 0 {
+		_, _ = fmt.Fprintln(w, "📦 Moved user files from ~/.ddx to the XDG directories:")
+		legacyDir, _ := userdirs.LegacyDir()
+		for _, m := range moves {
+			name, err := filepath.Rel(legacyDir, m.From)
+			if err != nil {
+				name = m.From
+			}
+			_, _ = fmt.Fprintf(w, "   %s → %s\n", name, m.To)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "⚠️  Some files were left in ~/.ddx and are used from there: %v\n", err)
+	}
+}
+
+// checkUserDirs reports where user files are kept for 'ddx doctor': the
+// status line, and an issue when files are split between ~/.ddx and the
+// XDG directories
+func checkUserDirs(layout *userdirs.Layout) (string, *DiagnosticIssue) {
+	if !layout.Mixed() {
+		return "✅ XDG Layout", nil
+	}
+
+	issue := &DiagnosticIssue{
+		Type:        "user_directories",
+		Description: fmt.Sprintf("User files are split between %s and the XDG directories", layout.Legacy),
+		SystemInfo:  map[string]string{"legacy": layout.Legacy},
+	}
+	for _, kind := range userdirs.Kinds {
+		issue.SystemInfo[string(kind)] = layout.Dirs[kind]
+	}
+	if len(layout.Pending) > 0 {
+		var names []string
+		for _, e := range layout.Pending {
+			names = append(names, e.Legacy)
+		}
+		issue.Remediation = append(issue.Remediation,
+			fmt.Sprintf("%s could not be moved and are used from %s; make sure the XDG directories are writable, and ddx moves them on its next run", strings.Join(names, ", "), layout.Legacy))
+	}
+	for _, e := range layout.Conflicts {
+		issue.Remediation = append(issue.Remediation,
+			fmt.Sprintf("%s is ignored because %s exists; merge it and remove it", filepath.Join(layout.Legacy, e.Legacy), filepath.Join(layout.Dirs[e.Kind], e.Name)))
+	}
+	if len(layout.Unknown) > 0 {
+		issue.Remediation = append(issue.Remediation,
+			fmt.Sprintf("ddx no longer uses %s in %s; remove them when you no longer need them", strings.Join(layout.Unknown, ", "), layout.Legacy))
+	}
+	if os.Getenv(noMigrateEnvVar) != "" {
+		issue.Remediation = append(issue.Remediation,
+			fmt.Sprintf("Unset %s to move the files", noMigrateEnvVar))
+	}
+
+	status := "⚠️  Files in both ~/.ddx and the XDG directories"
+	if len(layout.Conflicts) == 0 && len(layout.Unknown) == 0 {
+		status = "⚠️  Legacy ~/.ddx Layout"
+	}
+	return status, issue
+}
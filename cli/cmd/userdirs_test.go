@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/userdirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xdgHome points the home directory at a temporary one without XDG
+// variables, returning it
+func xdgHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", noMigrateEnvVar} {
+		t.Setenv(name, "")
+	}
+	return home
+}
+
+func TestMigrateUserDirs(t *testing.T) {
+	home := xdgHome(t)
+	legacy := filepath.Join(home, ".ddx")
+	require.NoError(t, os.MkdirAll(legacy, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("version: \"1.0\"\n"), 0644))
+
+	t.Setenv(noMigrateEnvVar, "1")
+	var out bytes.Buffer
+	migrateUserDirs(&out)
+	assert.Empty(t, out.String())
+	assert.FileExists(t, filepath.Join(legacy, "config.yaml"), "nothing moves when migration is turned off")
+
+	t.Setenv(noMigrateEnvVar, "")
+	migrateUserDirs(&out)
+	assert.Contains(t, out.String(), "Moved user files from ~/.ddx")
+	assert.Contains(t, out.String(), "config.yaml → "+filepath.Join(home, ".config", "ddx", "config.yaml"))
+	assert.NoDirExists(t, legacy)
+
+	out.Reset()
+	migrateUserDirs(&out)
+	assert.Empty(t, out.String(), "a migrated home is left alone")
+}
+
+func TestGlobalConfigFollowsMigration(t *testing.T) {
+	home := xdgHome(t)
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	legacy := filepath.Join(home, ".ddx", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0755))
+	require.NoError(t, os.WriteFile(legacy, []byte("version: \"1.0\"\nlibrary:\n  path: ./legacy-lib\n"), 0644))
+
+	output, err := env.RunCommand("config", "--show-files")
+	require.NoError(t, err)
+	assert.Contains(t, output, legacy, "an unmigrated global config is read in place")
+
+	migrateUserDirs(&bytes.Buffer{})
+	output, err = env.RunCommand("config", "--show-files")
+	require.NoError(t, err)
+	assert.Contains(t, output, filepath.Join(home, ".config", "ddx", "config.yaml"))
+}
+
+func TestCheckUserDirs(t *testing.T) {
+	home := xdgHome(t)
+
+	layout, err := userdirs.Inspect()
+	require.NoError(t, err)
+	status, issue := checkUserDirs(layout)
+	assert.Equal(t, "✅ XDG Layout", status)
+	assert.Nil(t, issue)
+
+	legacy := filepath.Join(home, ".ddx")
+	require.NoError(t, os.MkdirAll(filepath.Join(legacy, "library"), 0755))
+	layout, err = userdirs.Inspect()
+	require.NoError(t, err)
+	status, issue = checkUserDirs(layout)
+	assert.Contains(t, status, "Legacy ~/.ddx Layout")
+	require.NotNil(t, issue)
+	assert.Equal(t, "user_directories", issue.Type)
+	assert.Contains(t, issue.Remediation[0], "library could not be moved")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".local", "share", "ddx", "library"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "notes.txt"), []byte("x"), 0644))
+	layout, err = userdirs.Inspect()
+	require.NoError(t, err)
+	status, issue = checkUserDirs(layout)
+	assert.Contains(t, status, "Files in both ~/.ddx and the XDG directories")
+	require.NotNil(t, issue)
+	require.Len(t, issue.Remediation, 2)
+	assert.Contains(t, issue.Remediation[0], filepath.Join(legacy, "library")+" is ignored")
+	assert.Contains(t, issue.Remediation[1], "notes.txt")
+	assert.Equal(t, filepath.Join(home, ".cache", "ddx"), issue.SystemInfo["cache"])
+}
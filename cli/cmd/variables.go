@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easel/ddx/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// renderWithVariables fills {{name}} placeholders in content from --var
+// flags and the project's variable registry. Values given with --var are
+// recorded for reuse unless --no-record is set.
+func renderWithVariables(cmd *cobra.Command, workingDir, content string) (string, error) {
+	pairs, _ := cmd.Flags().GetStringArray("var")
+	noRecord, _ := cmd.Flags().GetBool("no-record")
+
+	provided, err := variables.ParseAssignments(pairs)
+	if err != nil {
+		return "", err
+	}
+
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, missing := variables.Render(content, registry.Merge(provided))
+	if len(missing) > 0 {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unset variables: %s (use --var name=value)\n", strings.Join(missing, ", "))
+	}
+
+	if !noRecord && registry.Record(provided) {
+		if err := registry.Save(); err != nil {
+			return "", err
+		}
+	}
+	return rendered, nil
+}
+
+// addVariableFlags adds the flags read by renderWithVariables
+func addVariableFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("var", nil, "Set a template variable (name=value); repeatable")
+	cmd.Flags().Bool("no-record", false, "Do not record --var values in .ddx/variables.yml")
+}
+
+// recordProjectVariables seeds the variable registry with values known at
+// init time so templates and prompts do not ask for them again
+func recordProjectVariables(workingDir string, values map[string]string) error {
+	registry, err := variables.Load(workingDir)
+	if err != nil {
+		return err
+	}
+	for name := range values {
+		if _, ok := registry.Get(name); ok {
+			delete(values, name)
+		}
+	}
+	if !registry.Record(values) {
+		return nil
+	}
+	return registry.Save()
+}
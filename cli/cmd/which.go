@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/resolve"
+	"github.com/spf13/cobra"
+)
+
+// projectResolver returns the asset resolver for a project. Projects
+// without .ddx/config.yaml resolve against the global library.
+func projectResolver(workingDir string) (*resolve.Resolver, error) {
+	if _, err := os.Stat(filepath.Join(workingDir, ".ddx", "config.yaml")); os.IsNotExist(err) {
+		return resolve.New(workingDir, ""), nil
+	}
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	sel, err := librarySelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r := resolve.New(workingDir, cfg.Library.Path)
+	r.Selected = sel.Selected
+	return r, nil
+}
+
+// whichType normalizes an asset type, accepting singular forms
+func whichType(name string) (string, bool) {
+	for _, candidate := range []string{name, name + "s"} {
+		if resolve.IsType(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// runWhich implements the which command
+func (f *CommandFactory) runWhich(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	cmd.SilenceUsage = true
+	out := cmd.OutOrStdout()
+
+	r, err := projectResolver(f.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	query := strings.Trim(filepath.ToSlash(args[0]), "/")
+	assetType, name, qualified := strings.Cut(query, "/")
+	if t, ok := whichType(assetType); ok {
+		if !qualified {
+			return displaySearchOrder(out, r, t, asJSON)
+		}
+		match, err := r.Resolve(t, name)
+		if err != nil {
+			return err
+		}
+		if err := displayMatches(out, []*resolve.Match{match}, asJSON); err != nil {
+			return err
+		}
+		if !match.Found() {
+			return NewExitError(1, fmt.Sprintf("%s not found: %s", strings.TrimSuffix(t, "s"), name))
+		}
+		return nil
+	}
+
+	// A bare name is looked up in every type
+	var matches []*resolve.Match
+	for _, t := range resolve.Types {
+		match, err := r.Resolve(t, query)
+		if err != nil {
+			return err
+		}
+		if match.Found() {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		if asJSON {
+			_, _ = fmt.Fprintln(out, "[]")
+		}
+		return NewExitError(1, fmt.Sprintf("no asset named %s; use <type>/<name> to see where it was looked for", query))
+	}
+	return displayMatches(out, matches, asJSON)
+}
+
+// displaySearchOrder prints the directories searched for an asset type
+func displaySearchOrder(w io.Writer, r *resolve.Resolver, assetType string, asJSON bool) error {
+	layers, err := r.Layers(assetType)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(layers, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, string(data))
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%s are looked up in:\n", assetType)
+	for i, layer := range layers {
+		_, _ = fmt.Fprintf(w, "  %d. %-16s %s\n", i+1, layer.Name, layer.Dir)
+		_, _ = fmt.Fprintf(w, "     %s\n", layer.Reason)
+	}
+	return nil
+}
+
+// displayMatches prints where each asset resolved and the candidates it
+// took precedence over
+func displayMatches(w io.Writer, matches []*resolve.Match, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, string(data))
+		return nil
+	}
+	for i, match := range matches {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		if match.Found() {
+			_, _ = fmt.Fprintf(w, "%s/%s → %s\n", match.Type, match.Name, match.Path)
+			_, _ = fmt.Fprintf(w, "  from %s (%s)\n", match.Layer, match.Reason)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s/%s not found\n", match.Type, match.Name)
+		}
+		_, _ = fmt.Fprintln(w, "  Search order:")
+		for _, c := range match.Candidates {
+			mark, note := "·", "not found"
+			switch {
+			case c.Found && c.Path == match.Path:
+				mark, note = "✓", "used"
+			case c.Found:
+				mark, note = "~", "shadowed"
+			}
+			_, _ = fmt.Fprintf(w, "    %s %-16s %s (%s)\n", mark, c.Layer, c.Path, note)
+		}
+	}
+	return nil
+}
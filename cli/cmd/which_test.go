@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/resolve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhichCommand(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+	env.CreateFile(".ddx/library/prompts/review.md", "# Library review\n")
+	env.CreateFile(".ddx/prompts/review.md", "# Project review\n")
+	env.CreateFile(".ddx/library/personas/reviewer.md", "# Reviewer\n")
+
+	output, err := env.RunCommand("which", "prompts/review")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "prompts/review → "+filepath.Join(env.Dir, ".ddx", "prompts", "review.md"))
+	assert.Contains(t, output, "from project override")
+	assert.Contains(t, output, filepath.Join(env.Dir, ".ddx", "library", "prompts", "review.md")+" (shadowed)")
+
+	output, err = env.RunCommand("which", "persona/reviewer", "--json")
+	require.NoError(t, err, output)
+	var matches []resolve.Match
+	require.NoError(t, json.Unmarshal([]byte(output), &matches))
+	require.Len(t, matches, 1)
+	assert.Equal(t, resolve.LayerLibrary, matches[0].Layer)
+
+	output, err = env.RunCommand("which", "prompts")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "1. project override")
+	assert.Contains(t, output, "2. project library")
+
+	output, err = env.RunCommand("which", "reviewer")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "personas/reviewer → ")
+
+	output, err = env.RunCommand("which", "personas/missing")
+	assert.EqualError(t, err, "persona not found: missing")
+	assert.Contains(t, output, "(not found)")
+}
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
 	"github.com/easel/ddx/internal/workflow"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -38,7 +41,33 @@ func runWorkflowWithDir(cmd *cobra.Command, args []string, workingDir string) er
 	case "status":
 		return showWorkflowStatusWithDir(cmd, workingDir)
 	case "list":
-		return listWorkflows(cmd)
+		return listWorkflows(cmd, workingDir)
+	case "bindings":
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return showWorkflowBindingsWithDir(cmd, name, workingDir)
+	case "lint":
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return lintWorkflowsWithDir(cmd, name, workingDir)
+	case "run":
+		if len(args) < 2 {
+			return fmt.Errorf("workflow name required")
+		}
+		pipeline := ""
+		if len(args) > 2 {
+			pipeline = args[2]
+		}
+		return runWorkflowPipelineWithDir(cmd, args[1], pipeline, workingDir)
+	case "create":
+		if len(args) < 2 {
+			return fmt.Errorf("workflow name required")
+		}
+		return createWorkflowWithDir(cmd, args[1], workingDir)
 	case "activate":
 		if len(args) < 2 {
 			return fmt.Errorf("workflow name required")
@@ -50,12 +79,22 @@ func runWorkflowWithDir(cmd *cobra.Command, args []string, workingDir string) er
 		}
 		return deactivateWorkflowWithDir(cmd, args[1], workingDir)
 	case "advance":
-		return advanceWorkflow(cmd)
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return advanceWorkflowWithDir(cmd, name, workingDir)
+	case "report":
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return showWorkflowReport(cmd, name, workingDir)
 	default:
 		// If not a generic command, treat as workflow name
 		if len(args) > 1 {
-			if isKnownWorkflow(firstArg) {
-				return handleWorkflowSpecificCommand(cmd, firstArg, args[1:])
+			if isKnownWorkflow(firstArg, workingDir) {
+				return handleWorkflowSpecificCommand(cmd, firstArg, args[1:], workingDir)
 			} else {
 				return fmt.Errorf("workflow '%s' not found", firstArg)
 			}
@@ -65,45 +104,66 @@ func runWorkflowWithDir(cmd *cobra.Command, args []string, workingDir string) er
 	}
 }
 
-func listWorkflows(cmd *cobra.Command) error {
+// listWorkflows lists the workflows in the library with their descriptions
+func listWorkflows(cmd *cobra.Command, workingDir string) error {
+	libPath := workflowLibraryPath(workingDir)
+	entries, err := os.ReadDir(filepath.Join(libPath, "workflows"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read workflows: %w", err)
+	}
+
+	loader := workflow.NewLoader(libPath)
+	sel := projectSelection(workingDir)
+	var lines []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !sel.Selected("workflows/"+entry.Name()) {
+			continue
+		}
+		description := "invalid workflow.yml"
+		if def, err := loader.Load(entry.Name()); err == nil {
+			description = def.Description
+		}
+		lines = append(lines, fmt.Sprintf("  • %s - %s", entry.Name(), description))
+	}
+	if len(lines) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No workflows found; create one with 'ddx workflow create <name>'")
+		return nil
+	}
+
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Available workflows:")
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  • helix - HELIX development methodology")
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  • agile - Agile/Scrum workflow")
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  • kanban - Kanban board workflow")
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), line)
+	}
 	return nil
 }
 
-func advanceWorkflow(cmd *cobra.Command) error {
-	// Simplified implementation for now
-	// In a real implementation, this would use the workflow package
-	// wf, err := workflow.LoadCurrent()
-	// if err != nil {
-	//     return fmt.Errorf("no active workflow found")
-	// }
-	// if err := wf.Advance(); err != nil {
-	//     return fmt.Errorf("failed to advance workflow: %w", err)
-	// }
-
-	// Check if workflow is active
-	if _, err := os.Stat(".workflow-state.yml"); os.IsNotExist(err) {
-		return fmt.Errorf("no active workflow found")
+// workflowLibraryPath returns the library directory workflows are read from.
+// The configured library path (including any active profile) is preferred;
+// the legacy library directory in the project is used when it has no workflows.
+func workflowLibraryPath(workingDir string) string {
+	if cfg, err := config.LoadWithWorkingDir(workingDir); err == nil && cfg.Library != nil {
+		libPath := cfg.Library.Path
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(workingDir, libPath)
+		}
+		if stat, err := os.Stat(filepath.Join(libPath, "workflows")); err == nil && stat.IsDir() {
+			return libPath
+		}
 	}
-
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Advanced to next phase")
-	return nil
+	return filepath.Join(workingDir, "library")
 }
 
 // isKnownWorkflow checks if the given name is a known workflow
-func isKnownWorkflow(name string) bool {
-	workflowDir := filepath.Join("library", "workflows", name)
+func isKnownWorkflow(name string, workingDir string) bool {
+	workflowDir := filepath.Join(workflowLibraryPath(workingDir), "workflows", name)
 	if stat, err := os.Stat(workflowDir); err == nil && stat.IsDir() {
-		return true
+		return projectSelection(workingDir).Selected("workflows/" + name)
 	}
 	return false
 }
 
 // handleWorkflowSpecificCommand routes workflow-specific subcommands
-func handleWorkflowSpecificCommand(cmd *cobra.Command, workflow string, args []string) error {
+func handleWorkflowSpecificCommand(cmd *cobra.Command, workflow string, args []string, workingDir string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("subcommand required for workflow %s", workflow)
 	}
@@ -111,20 +171,25 @@ func handleWorkflowSpecificCommand(cmd *cobra.Command, workflow string, args []s
 	subcommand := strings.ToLower(args[0])
 	switch subcommand {
 	case "commands":
-		return listWorkflowCommands(cmd, workflow)
+		return listWorkflowCommands(cmd, workflow, workingDir)
 	case "execute":
 		if len(args) < 2 {
 			return fmt.Errorf("command name required for execute")
 		}
-		return executeWorkflowCommand(cmd, workflow, args[1], args[2:])
+		return executeWorkflowCommand(cmd, workflow, args[1], args[2:], workingDir)
+	case "artifact":
+		if len(args) < 2 {
+			return fmt.Errorf("artifact name required")
+		}
+		return generateWorkflowArtifact(cmd, workflow, strings.Join(args[1:], " "), workingDir)
 	default:
 		return fmt.Errorf("unknown subcommand '%s' for workflow '%s'", subcommand, workflow)
 	}
 }
 
 // listWorkflowCommands lists available commands for a workflow
-func listWorkflowCommands(cmd *cobra.Command, workflow string) error {
-	commandsDir := filepath.Join("library", "workflows", workflow, "commands")
+func listWorkflowCommands(cmd *cobra.Command, workflow string, workingDir string) error {
+	commandsDir := filepath.Join(workflowLibraryPath(workingDir), "workflows", workflow, "commands")
 
 	// Check if commands directory exists
 	if _, err := os.Stat(commandsDir); os.IsNotExist(err) {
@@ -145,6 +210,9 @@ func listWorkflowCommands(cmd *cobra.Command, workflow string) error {
 			// Try to read the first line for description
 			description := getCommandDescription(filepath.Join(commandsDir, entry.Name()))
 
+			if usage := commandArgsUsage(filepath.Join(commandsDir, entry.Name())); usage != "" {
+				commandName += " " + usage
+			}
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %-15s %s\n", commandName, description)
 		}
 	}
@@ -159,7 +227,14 @@ func getCommandDescription(filePath string) string {
 		return "No description available"
 	}
 
-	lines := strings.Split(string(content), "\n")
+	spec, body, err := workflow.ParseCommandPrompt(string(content))
+	if err == nil && spec.Description != "" {
+		return spec.Description
+	}
+	if err != nil {
+		body = string(content)
+	}
+	lines := strings.Split(body, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "# ") {
@@ -170,9 +245,24 @@ func getCommandDescription(filePath string) string {
 	return "No description available"
 }
 
-// executeWorkflowCommand loads and displays a workflow command
-func executeWorkflowCommand(cmd *cobra.Command, workflow, command string, args []string) error {
-	commandPath := filepath.Join("library", "workflows", workflow, "commands", command+".md")
+// commandArgsUsage returns the arguments a command file declares, as they
+// are written on the command line
+func commandArgsUsage(filePath string) string {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	spec, _, err := workflow.ParseCommandPrompt(string(content))
+	if err != nil {
+		return ""
+	}
+	return spec.Usage()
+}
+
+// executeWorkflowCommand renders a workflow command and displays it or
+// sends it to the destinations given by --out, --copy and --exec
+func executeWorkflowCommand(cmd *cobra.Command, workflow, command string, args []string, workingDir string) error {
+	commandPath := filepath.Join(workflowLibraryPath(workingDir), "workflows", workflow, "commands", command+".md")
 
 	// Check if command file exists
 	if _, err := os.Stat(commandPath); os.IsNotExist(err) {
@@ -185,14 +275,49 @@ func executeWorkflowCommand(cmd *cobra.Command, workflow, command string, args [
 		return fmt.Errorf("failed to read command file: %w", err)
 	}
 
-	// Display command content
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Executing %s workflow command: %s\n\n", workflow, command)
+	spec, prompt, err := commandPrompt(workflow, command, string(content), args)
+	if err != nil {
+		return err
+	}
+	refs := commandReferences(cmd, workingDir, workflow, commandPath, spec, prompt)
+	if mode, _ := cmd.Flags().GetString("refs"); mode == RefsJSON {
+		return printReferencesJSON(cmd, workingDir, workflow, command, refs)
+	}
+	references, err := referencesSection(cmd, workingDir, refs)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderWithVariables(cmd, workingDir, prompt)
+	if err != nil {
+		return err
+	}
+
+	// Personas come from persona_bindings with the workflow's overrides
+	personas := workflowPersonaContext(workingDir, workflow)
 
-	if len(args) > 0 {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Command Arguments: %v\n\n", args)
+	sent, err := sendExecutePrompt(cmd, workingDir, executePrompt(rendered, references, args, personas))
+	if err != nil {
+		return err
+	}
+	if !sent {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Executing %s workflow command: %s\n\n", workflow, command)
+		if len(args) > 0 {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Command Arguments: %v\n\n", args)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", rendered)
+		if references != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%s", references)
+		}
+		if personas != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%s", personas)
+		}
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", string(content))
+	// The run is recorded after it is shown; a failure to record is not
+	// worth failing the command over
+	if err := recordWorkflowExecution(workingDir, workflow, command, args); err != nil {
+		slog.Warn("could not record workflow activity", "workflow", workflow, "error", err)
+	}
 
 	return nil
 }
@@ -213,7 +338,7 @@ func saveConfigWithDir(cfg *config.NewConfig, workingDir string) error {
 	}
 
 	// Write file
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -276,6 +401,19 @@ func showWorkflowStatusWithDir(cmd *cobra.Command, workingDir string) error {
 }
 
 func activateWorkflowWithDir(cmd *cobra.Command, name string, force bool, workingDir string) error {
+	err := filelock.With(filepath.Join(workingDir, ".ddx", "config.yaml"), func() error {
+		return activateWorkflowLocked(cmd, name, force, workingDir)
+	})
+	if err != nil {
+		return err
+	}
+	refreshPhaseContext(workingDir, name)
+	return nil
+}
+
+// activateWorkflowLocked adds a workflow to the active list while the
+// caller holds the config lock
+func activateWorkflowLocked(cmd *cobra.Command, name string, force bool, workingDir string) error {
 	// Load config
 	cfg, err := loadConfigFrom(workingDir)
 	if err != nil {
@@ -293,9 +431,12 @@ func activateWorkflowWithDir(cmd *cobra.Command, name string, force bool, workin
 		libraryPath = filepath.Join(workingDir, libraryPath)
 	}
 
-	// Verify workflow exists
+	// Verify workflow exists and is inside library.include/exclude
 	loader := workflow.NewLoader(libraryPath)
 	_, err = loader.Load(name)
+	if sel, selErr := librarySelection(cfg); err == nil && selErr == nil && !sel.Selected("workflows/"+name) {
+		err = fmt.Errorf("excluded by library.include/exclude")
+	}
 	if err != nil {
 		errMsg := fmt.Sprintf("workflow '%s' not found: %v", name, err)
 		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), errMsg)
@@ -325,6 +466,19 @@ func activateWorkflowWithDir(cmd *cobra.Command, name string, force bool, workin
 }
 
 func deactivateWorkflowWithDir(cmd *cobra.Command, name string, workingDir string) error {
+	err := filelock.With(filepath.Join(workingDir, ".ddx", "config.yaml"), func() error {
+		return deactivateWorkflowLocked(cmd, name, workingDir)
+	})
+	if err != nil {
+		return err
+	}
+	refreshPhaseContext(workingDir, name)
+	return nil
+}
+
+// deactivateWorkflowLocked removes a workflow from the active list while
+// the caller holds the config lock
+func deactivateWorkflowLocked(cmd *cobra.Command, name string, workingDir string) error {
 	// Load config
 	cfg, err := loadConfigFrom(workingDir)
 	if err != nil {
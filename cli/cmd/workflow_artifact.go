@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// generateWorkflowArtifact writes the stub of a workflow artifact to the
+// docs directory, records it in the workflow's state and shows or sends
+// the prompt that completes it, with the personas bound to the role that
+// writes it
+func generateWorkflowArtifact(cmd *cobra.Command, name, artifactName string, workingDir string) error {
+	workflowDir := filepath.Join(workflowLibraryPath(workingDir), "workflows", name)
+	def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name)
+	if err != nil {
+		return fmt.Errorf("workflow '%s' not found: %w", name, err)
+	}
+	artifact, phase := def.FindArtifact(artifactName)
+	if artifact == nil {
+		var names []string
+		for _, p := range def.Phases {
+			for _, a := range p.Artifacts {
+				names = append(names, a.Name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("workflow '%s' declares no artifacts", name)
+		}
+		return fmt.Errorf("artifact '%s' not found in workflow '%s'; available: %s", artifactName, name, strings.Join(names, ", "))
+	}
+
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	rel := path.Join(cfg.Workflows.Docs(), artifact.OutputPath(name))
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return fmt.Errorf("artifact path '%s' is outside the project", rel)
+	}
+	target := filepath.Join(workingDir, filepath.FromSlash(rel))
+	if force, _ := cmd.Flags().GetBool("force"); !force {
+		if _, err := os.Stat(target); err == nil {
+			return NewExitError(2, fmt.Sprintf("%s already exists. Use --force to overwrite.", rel))
+		}
+	}
+
+	var personas []string
+	if artifact.RequiredRole != "" {
+		bindings, err := workflowBindings(workingDir, name)
+		if err != nil {
+			return err
+		}
+		for _, b := range bindings {
+			if b.Role == artifact.RequiredRole {
+				personas = b.Personas
+			}
+		}
+		if len(personas) == 0 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  No persona is bound to role '%s'; bind one with 'ddx persona bind %s <persona>'\n", artifact.RequiredRole, artifact.RequiredRole)
+		}
+	}
+
+	stub := fmt.Sprintf("# %s\n", artifact.Name)
+	if artifact.Description != "" {
+		stub += "\n" + artifact.Description + "\n"
+	}
+	if artifact.Template != "" {
+		content, err := os.ReadFile(filepath.Join(workflowDir, filepath.FromSlash(artifact.Template)))
+		if err != nil {
+			return fmt.Errorf("failed to read artifact template: %w", err)
+		}
+		if stub, err = renderWithVariables(cmd, workingDir, string(content)); err != nil {
+			return err
+		}
+	}
+	prompt := fmt.Sprintf("Write the %s for the %s workflow.\n", artifact.Name, name)
+	if artifact.Prompt != "" {
+		content, err := os.ReadFile(filepath.Join(workflowDir, filepath.FromSlash(artifact.Prompt)))
+		if err != nil {
+			return fmt.Errorf("failed to read artifact prompt: %w", err)
+		}
+		if prompt, err = renderWithVariables(cmd, workingDir, string(content)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(rel), err)
+	}
+	if err := atomicfile.WriteFile(target, []byte(stub), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	err = filelock.With(filepath.Join(workingDir, workflow.StateFile(name)), func() error {
+		state, err := loadOrStartWorkflowState(workingDir, name)
+		if err != nil {
+			return err
+		}
+		if state.Artifacts == nil {
+			state.Artifacts = make(map[string]workflow.ArtifactRecord)
+		}
+		state.Artifacts[artifact.Name] = workflow.ArtifactRecord{
+			Path:      rel,
+			Phase:     phase.ID,
+			Role:      artifact.RequiredRole,
+			Personas:  personas,
+			Generated: time.Now().UTC().Format(time.RFC3339),
+		}
+		user, email := git.UserIdentity(workingDir)
+		state.Record(workflow.AuditEntry{User: user, Email: email, Action: workflow.AuditArtifact, Artifact: artifact.Name, Phase: phase.ID})
+		return workflow.SaveStateTo(workingDir, state)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record artifact: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(prompt, "\n"))
+	_, _ = fmt.Fprintf(&b, "\n\nComplete the document at %s, keeping its structure.\n", rel)
+	b.WriteString(rolePersonaContent(workingDir, artifact.RequiredRole, personas))
+
+	sent, err := sendExecutePrompt(cmd, workingDir, b.String())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(statusOut(cmd), "✅ Created %s\n", rel)
+	if !sent {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%s", b.String())
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowArtifact(t *testing.T) {
+	env := NewTestEnvironment(t)
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+workflows:
+  active: [helix]
+  docs_path: documentation
+persona_bindings:
+  architect: systems-thinker
+`)
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "workflow.yml"), `name: helix
+version: 1.0.0
+phases:
+  - id: frame
+    order: 1
+    name: Frame
+  - id: design
+    order: 2
+    name: Design
+    required_role: architect
+    artifacts:
+      - name: architecture-doc
+        template: templates/architecture.md
+        prompt: prompts/architecture.md
+      - name: test-plan
+        required_role: test-engineer
+`)
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "templates", "architecture.md"), "# {{project}} Architecture\n\n## Components\n")
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "prompts", "architecture.md"), "Describe the architecture of {{project}}.\n")
+	env.CreateFile(filepath.Join(".ddx", "library", "personas", "systems-thinker.md"), "# Systems Thinker\n\nSees the whole system.\n")
+
+	output, err := env.RunCommand("workflow", "helix", "artifact", "architecture-doc", "--var", "project=Billing", "--no-record")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Created documentation/helix/architecture-doc.md")
+	assert.Contains(t, output, "Describe the architecture of Billing.")
+	assert.Contains(t, output, "Complete the document at documentation/helix/architecture-doc.md")
+	assert.Contains(t, output, "### Architect: systems-thinker\n# Systems Thinker")
+
+	data, err := os.ReadFile(filepath.Join(env.Dir, "documentation", "helix", "architecture-doc.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Billing Architecture\n\n## Components\n", string(data))
+
+	state, err := workflow.LoadStateFrom(env.Dir, "helix")
+	require.NoError(t, err)
+	record := state.Artifacts["architecture-doc"]
+	assert.Equal(t, "documentation/helix/architecture-doc.md", record.Path)
+	assert.Equal(t, "design", record.Phase)
+	assert.Equal(t, "architect", record.Role)
+	assert.Equal(t, []string{"systems-thinker"}, record.Personas)
+	require.Len(t, state.Audit, 1)
+	assert.Equal(t, workflow.AuditArtifact, state.Audit[0].Action)
+	assert.Equal(t, "architecture-doc", state.Audit[0].Artifact)
+
+	_, err = env.RunCommand("workflow", "helix", "artifact", "architecture-doc")
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.Code)
+	assert.Contains(t, exitErr.Message, "already exists")
+	output, err = env.RunCommand("workflow", "helix", "artifact", "architecture-doc", "--force", "--out", "prompt.md")
+	require.NoError(t, err, output)
+	assert.FileExists(t, filepath.Join(env.Dir, "prompt.md"))
+
+	output, err = env.RunCommand("workflow", "helix", "artifact", "test-plan")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "No persona is bound to role 'test-engineer'")
+	data, err = os.ReadFile(filepath.Join(env.Dir, "documentation", "helix", "test-plan.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# test-plan\n", string(data))
+
+	output, err = env.RunCommand("workflow", "report", "helix")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "generated architecture-doc (design phase)")
+
+	_, err = env.RunCommand("workflow", "helix", "artifact", "runbook")
+	assert.ErrorContains(t, err, "available: architecture-doc, test-plan")
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// loadOrStartWorkflowState loads a workflow's state, starting it at the
+// first phase of its definition when it has none. A workflow without a
+// definition gets a state with no phases.
+func loadOrStartWorkflowState(workingDir, name string) (*workflow.State, error) {
+	if _, err := os.Stat(filepath.Join(workingDir, workflow.StateFile(name))); err == nil {
+		return workflow.LoadStateFrom(workingDir, name)
+	}
+
+	def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name)
+	if err == nil && len(def.Phases) > 0 {
+		return workflow.InitializeState(name, def)
+	}
+	now := time.Now().Format("2006-01-02 15:04:05")
+	return &workflow.State{Workflow: name, PhasesCompleted: []string{}, StartedAt: now, LastUpdated: now}, nil
+}
+
+// recordWorkflowExecution appends a command run, attributed to the git
+// user, to the workflow's audit trail
+func recordWorkflowExecution(workingDir, name, command string, args []string) error {
+	return filelock.With(filepath.Join(workingDir, workflow.StateFile(name)), func() error {
+		return recordWorkflowExecutionLocked(workingDir, name, command, args)
+	})
+}
+
+// recordWorkflowExecutionLocked implements recordWorkflowExecution while
+// the caller holds the state file's lock
+func recordWorkflowExecutionLocked(workingDir, name, command string, args []string) error {
+	state, err := loadOrStartWorkflowState(workingDir, name)
+	if err != nil {
+		return err
+	}
+	user, email := git.UserIdentity(workingDir)
+	state.Record(workflow.AuditEntry{
+		User:    user,
+		Email:   email,
+		Action:  workflow.AuditExecute,
+		Command: command,
+		Args:    append([]string(nil), args...),
+		Phase:   state.CurrentPhase,
+	})
+	return workflow.SaveStateTo(workingDir, state)
+}
+
+// advanceWorkflowWithDir moves a workflow to its next phase and records the
+// transition. Without a name the highest priority active workflow is used.
+func advanceWorkflowWithDir(cmd *cobra.Command, name string, workingDir string) error {
+	if name == "" {
+		cfg, err := loadConfigFrom(workingDir)
+		if err != nil || cfg == nil || len(cfg.Workflows.Active) == 0 {
+			return fmt.Errorf("no active workflow found")
+		}
+		name = cfg.Workflows.Active[0]
+	}
+
+	def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name)
+	if err != nil {
+		return fmt.Errorf("workflow '%s' not found: %w", name, err)
+	}
+	var state *workflow.State
+	var from string
+	err = filelock.With(filepath.Join(workingDir, workflow.StateFile(name)), func() error {
+		if state, err = loadOrStartWorkflowState(workingDir, name); err != nil {
+			return err
+		}
+		from = state.CurrentPhase
+		if err := state.AdvancePhase(def); err != nil {
+			return err
+		}
+		user, email := git.UserIdentity(workingDir)
+		state.Record(workflow.AuditEntry{User: user, Email: email, Action: workflow.AuditAdvance, Phase: from, To: state.CurrentPhase})
+		return workflow.SaveStateTo(workingDir, state)
+	})
+	if err != nil {
+		return err
+	}
+	refreshPhaseContext(workingDir, name)
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Advancing %s from %s to %s phase\n", name, from, state.CurrentPhase)
+	return nil
+}
+
+// showWorkflowReport prints the state and audit trail of a workflow, or of
+// every active workflow that has state when no name is given
+func showWorkflowReport(cmd *cobra.Command, name string, workingDir string) error {
+	names := []string{name}
+	if name == "" {
+		names = nil
+		if cfg, err := loadConfigFrom(workingDir); err == nil && cfg != nil {
+			for _, active := range cfg.Workflows.Active {
+				if _, err := os.Stat(filepath.Join(workingDir, workflow.StateFile(active))); err == nil {
+					names = append(names, active)
+				}
+			}
+		}
+		if len(names) == 0 {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No workflow state found")
+			return nil
+		}
+	}
+
+	for i, name := range names {
+		state, err := workflow.LoadStateFrom(workingDir, name)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout())
+		}
+		writeWorkflowReport(cmd.OutOrStdout(), state)
+	}
+	return nil
+}
+
+// writeWorkflowReport writes one workflow's report
+func writeWorkflowReport(out io.Writer, state *workflow.State) {
+	_, _ = fmt.Fprintf(out, "📋 %s workflow\n", state.Workflow)
+	_, _ = fmt.Fprintf(out, "   Started:       %s\n", state.StartedAt)
+	if state.CurrentPhase != "" {
+		_, _ = fmt.Fprintf(out, "   Current phase: %s\n", state.CurrentPhase)
+	}
+	if len(state.PhasesCompleted) > 0 {
+		_, _ = fmt.Fprintf(out, "   Completed:     %s\n", strings.Join(state.PhasesCompleted, ", "))
+	}
+
+	if len(state.Audit) == 0 {
+		_, _ = fmt.Fprintln(out, "\nNo recorded activity")
+		return
+	}
+	_, _ = fmt.Fprintln(out, "\nActivity:")
+	for _, entry := range state.Audit {
+		who := entry.User
+		if entry.Email != "" {
+			who += " <" + entry.Email + ">"
+		}
+		var what string
+		switch entry.Action {
+		case workflow.AuditAdvance:
+			what = fmt.Sprintf("advanced %s → %s", entry.Phase, entry.To)
+		case workflow.AuditExecute:
+			what = strings.TrimSpace("executed " + entry.Command + " " + strings.Join(entry.Args, " "))
+			if entry.Phase != "" {
+				what += fmt.Sprintf(" (%s phase)", entry.Phase)
+			}
+		case workflow.AuditArtifact:
+			what = fmt.Sprintf("generated %s (%s phase)", entry.Artifact, entry.Phase)
+		default:
+			what = entry.Action
+		}
+		_, _ = fmt.Fprintf(out, "  %s  %s  %s\n", entry.Time, who, what)
+	}
+}
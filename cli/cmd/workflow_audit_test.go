@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowAuditTrail(t *testing.T) {
+	env := NewTestEnvironment(t)
+	createConfigWithWorkflow(t, env, "helix")
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "workflow.yml"), `name: helix
+version: 1.0.0
+phases:
+  - id: frame
+    order: 1
+    name: Frame
+  - id: design
+    order: 2
+    name: Design
+`)
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "commands", "build-story.md"), "# Build Story\n")
+
+	output, err := env.RunCommand("workflow", "report")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "No workflow state found")
+
+	output, err = env.RunCommand("workflow", "helix", "execute", "build-story", "US-001")
+	require.NoError(t, err, output)
+	output, err = env.RunCommand("workflow", "advance")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Advancing helix from frame to design phase")
+
+	state, err := workflow.LoadStateFrom(env.Dir, "helix")
+	require.NoError(t, err)
+	assert.Equal(t, "design", state.CurrentPhase)
+	require.Len(t, state.Audit, 2)
+	assert.Equal(t, workflow.AuditEntry{
+		Time: state.Audit[0].Time, User: "Test User", Email: "test@example.com",
+		Action: workflow.AuditExecute, Command: "build-story", Args: []string{"US-001"}, Phase: "frame",
+	}, state.Audit[0])
+	assert.Equal(t, workflow.AuditAdvance, state.Audit[1].Action)
+	assert.Equal(t, "frame", state.Audit[1].Phase)
+	assert.Equal(t, "design", state.Audit[1].To)
+
+	output, err = env.RunCommand("workflow", "report", "helix")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Current phase: design")
+	assert.Contains(t, output, "Completed:     frame")
+	assert.Contains(t, output, "Test User <test@example.com>  executed build-story US-001 (frame phase)")
+	assert.Contains(t, output, "Test User <test@example.com>  advanced frame → design")
+
+	_, err = env.RunCommand("workflow", "advance", "helix")
+	assert.ErrorContains(t, err, "no phase after 'design'")
+	state, err = workflow.LoadStateFrom(env.Dir, "helix")
+	require.NoError(t, err)
+	assert.Len(t, state.Audit, 2, "a failed transition is not recorded")
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// WorkflowBinding is a role's effective persona while a workflow runs
+type WorkflowBinding struct {
+	Role     string
+	Personas []string
+	// Override is set when overrides.<workflow> replaces persona_bindings
+	Override bool
+	// Phases are the workflow phases requiring the role
+	Phases []string
+}
+
+// workflowBindings returns the effective persona bindings for a workflow,
+// including roles its phases require that nothing is bound to, sorted by
+// role
+func workflowBindings(workingDir, name string) ([]WorkflowBinding, error) {
+	cfg, err := loadPersonaConfig(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	effective, overridden := cfg.WorkflowBindings(name)
+
+	byRole := make(map[string]*WorkflowBinding)
+	for role, value := range effective {
+		byRole[role] = &WorkflowBinding{Role: role, Personas: config.SplitPersonas(value), Override: overridden[role]}
+	}
+	if def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name); err == nil {
+		for _, phase := range def.Phases {
+			if phase.RequiredRole == "" {
+				continue
+			}
+			if byRole[phase.RequiredRole] == nil {
+				byRole[phase.RequiredRole] = &WorkflowBinding{Role: phase.RequiredRole}
+			}
+			byRole[phase.RequiredRole].Phases = append(byRole[phase.RequiredRole].Phases, phase.ID)
+		}
+	}
+
+	bindings := make([]WorkflowBinding, 0, len(byRole))
+	for _, b := range byRole {
+		bindings = append(bindings, *b)
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Role < bindings[j].Role })
+	return bindings, nil
+}
+
+// showWorkflowBindingsWithDir prints the persona each role resolves to
+// while a workflow runs. Without a name the highest priority active
+// workflow is used.
+func showWorkflowBindingsWithDir(cmd *cobra.Command, name string, workingDir string) error {
+	if name == "" {
+		cfg, err := loadConfigFrom(workingDir)
+		if err != nil || cfg == nil || len(cfg.Workflows.Active) == 0 {
+			return fmt.Errorf("workflow name required")
+		}
+		name = cfg.Workflows.Active[0]
+	}
+	bindings, err := workflowBindings(workingDir, name)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(bindings) == 0 {
+		_, _ = fmt.Fprintf(out, "No persona bindings apply to workflow '%s'\n", name)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Persona bindings for workflow '%s':\n\n", name)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ROLE\tPERSONA\tSOURCE\tPHASES")
+	_, _ = fmt.Fprintln(w, "----\t-------\t------\t------")
+	for _, b := range bindings {
+		persona, source, phases := strings.Join(b.Personas, ", "), "persona_bindings", "-"
+		switch {
+		case b.Override:
+			source = "overrides." + name
+		case len(b.Personas) == 0:
+			persona, source = "⚠️  unbound", "-"
+		}
+		if len(b.Phases) > 0 {
+			phases = strings.Join(b.Phases, ", ")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", b.Role, persona, source, phases)
+	}
+	return w.Flush()
+}
+
+// workflowPersonaContext describes the personas a workflow command runs
+// with: every effective binding, and the content of the personas bound to
+// the role the current phase requires
+func workflowPersonaContext(workingDir, name string) string {
+	bindings, err := workflowBindings(workingDir, name)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, binding := range bindings {
+		if len(binding.Personas) == 0 {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("Personas:\n")
+		}
+		note := ""
+		if binding.Override {
+			note = fmt.Sprintf(" (overrides.%s)", name)
+		}
+		_, _ = fmt.Fprintf(&b, "  • %s → %s%s\n", binding.Role, strings.Join(binding.Personas, ", "), note)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+
+	state, err := loadOrStartWorkflowState(workingDir, name)
+	if err != nil || state.CurrentPhase == "" {
+		return b.String()
+	}
+	def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name)
+	if err != nil {
+		return b.String()
+	}
+	phase := def.GetPhaseByID(state.CurrentPhase)
+	if phase == nil || phase.RequiredRole == "" {
+		return b.String()
+	}
+	for _, binding := range bindings {
+		if binding.Role == phase.RequiredRole {
+			b.WriteString(rolePersonaContent(workingDir, binding.Role, binding.Personas))
+		}
+	}
+	return b.String()
+}
+
+// rolePersonaContent returns the content of the personas bound to a role,
+// each under a heading naming the role and persona. Personas that cannot
+// be read are left out.
+func rolePersonaContent(workingDir, role string, personas []string) string {
+	title := cases.Title(language.English).String(strings.ReplaceAll(role, "-", " "))
+	var b strings.Builder
+	for _, personaName := range personas {
+		personaPath, _, err := findPersona(workingDir, personaName)
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(personaPath)
+		if err != nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "\n### %s: %s\n%s\n", title, personaName, strings.TrimRight(string(content), "\n"))
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// createWorkflowWithDir scaffolds a new workflow in the project library so
+// it can be listed, activated and executed straight away
+func createWorkflowWithDir(cmd *cobra.Command, name string, workingDir string) error {
+	phases, _ := cmd.Flags().GetStringSlice("phases")
+	description, _ := cmd.Flags().GetString("description")
+
+	libPath := workflowLibraryPath(workingDir)
+	if cfg, err := config.LoadWithWorkingDir(workingDir); err == nil && cfg.Library != nil {
+		libPath = projectLibraryDir(workingDir, cfg)
+	}
+	author, _ := git.UserIdentity(workingDir)
+
+	def, files, err := workflow.Scaffold(libPath, workflow.ScaffoldOptions{
+		Name:        name,
+		Description: description,
+		Author:      author,
+		Phases:      phases,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	dir := filepath.Join(libPath, "workflows", def.Name)
+	if rel, err := filepath.Rel(workingDir, dir); err == nil {
+		dir = rel
+	}
+	_, _ = fmt.Fprintf(out, "✅ Created workflow '%s' with %d phase(s) in %s\n", def.Name, len(def.Phases), dir)
+	for _, file := range files {
+		_, _ = fmt.Fprintf(out, "  %s\n", file)
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintf(out, "Next: edit workflow.yml and the phase docs, then run 'ddx workflow %s commands' or 'ddx workflow activate %s'\n", def.Name, def.Name)
+	return nil
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// addExecuteOutputFlags adds the flags that send an executed workflow
+// command's prompt somewhere other than stdout
+func addExecuteOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("copy", false, "With execute, copy the prompt to the clipboard")
+	cmd.Flags().String("out", "", "With execute, write the prompt to a file")
+	cmd.Flags().String("exec", "", "With execute, pipe the prompt into a command, e.g. \"claude -p\"")
+}
+
+// Ways of handling the files a workflow command refers to, set with --refs
+const (
+	RefsList   = "list"
+	RefsInline = "inline"
+	RefsJSON   = "json"
+	RefsNone   = "none"
+)
+
+// DefaultRefsLimit is how many bytes of referenced files are inlined into
+// a prompt at most
+const DefaultRefsLimit = 64 * 1024
+
+// addExecuteRefsFlags adds the flags that choose how the files a command
+// refers to are given to the AI tool
+func addExecuteRefsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("refs", RefsList, "With execute and run, referenced library files: list, inline, json or none")
+	cmd.Flags().Int("refs-limit", DefaultRefsLimit, "With --refs inline, the most bytes of files to inline")
+}
+
+// commandPrompt checks a workflow command's arguments against those its
+// frontmatter declares and returns the frontmatter and the prompt without
+// it. Arguments that do not fit are a usage error listing the declared
+// ones.
+func commandPrompt(workflowName, command, content string, args []string) (*workflow.CommandSpec, string, error) {
+	spec, body, err := workflow.ParseCommandPrompt(content)
+	if err != nil {
+		return nil, "", NewExitError(ExitCodeInvalidConfig, fmt.Sprintf("command '%s' in workflow '%s': %v", command, workflowName, err))
+	}
+	if err := spec.ValidateArgs(args); err != nil {
+		return nil, "", NewExitError(ExitCodeMissingArg, fmt.Sprintf("%v\n\n%s", err, commandUsage(workflowName, command, spec)))
+	}
+	return spec, body, nil
+}
+
+// commandReferences resolves the library files a command's prompt refers
+// to. Those that cannot be found are reported on stderr and left out.
+func commandReferences(cmd *cobra.Command, workingDir, workflowName, promptPath string, spec *workflow.CommandSpec, body string) []workflow.Reference {
+	libPath := workflowLibraryPath(workingDir)
+	refs := workflow.References(spec, body, filepath.Dir(promptPath), filepath.Join(libPath, "workflows", workflowName), libPath)
+	found := refs[:0]
+	for _, ref := range refs {
+		if ref.Path == "" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  Reference '%s' in %s is not a file in the library\n", ref.Link, displayPath(workingDir, promptPath))
+			continue
+		}
+		found = append(found, ref)
+	}
+	return found
+}
+
+// referencesSection describes the referenced files at the end of a prompt
+// as --refs asks: a list of their paths or, with inline, their contents up
+// to --refs-limit bytes in all, listing the files that do not fit
+func referencesSection(cmd *cobra.Command, workingDir string, refs []workflow.Reference) (string, error) {
+	mode, _ := cmd.Flags().GetString("refs")
+	limit, _ := cmd.Flags().GetInt("refs-limit")
+	switch mode {
+	case RefsList, RefsInline, RefsJSON:
+	case RefsNone:
+		return "", nil
+	default:
+		return "", NewExitError(ExitCodeMissingArg, fmt.Sprintf("invalid --refs '%s': use list, inline, json or none", mode))
+	}
+	if len(refs) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Referenced Files:\n")
+	var listed []string
+	for _, ref := range refs {
+		path := displayPath(workingDir, ref.Path)
+		if mode != RefsInline || ref.Size > int64(limit) {
+			listed = append(listed, path)
+			continue
+		}
+		content, err := os.ReadFile(ref.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		limit -= len(content)
+		fmt.Fprintf(&b, "\n<file path=\"%s\">\n%s\n</file>\n", path, strings.TrimRight(string(content), "\n"))
+	}
+	if len(listed) > 0 {
+		if mode == RefsInline {
+			b.WriteString("\nNot inlined, over the --refs-limit:\n")
+		}
+		for _, path := range listed {
+			fmt.Fprintf(&b, "- %s\n", path)
+		}
+	}
+	return b.String(), nil
+}
+
+// printReferencesJSON prints the files a command refers to, for --refs json
+func printReferencesJSON(cmd *cobra.Command, workingDir, workflowName, command string, refs []workflow.Reference) error {
+	shown := make([]workflow.Reference, len(refs))
+	for i, ref := range refs {
+		shown[i] = ref
+		shown[i].Path = displayPath(workingDir, ref.Path)
+	}
+	data, err := json.MarshalIndent(map[string]any{"workflow": workflowName, "command": command, "references": shown}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// displayPath shows a path relative to the project when it is inside it
+func displayPath(workingDir, path string) string {
+	if rel, err := filepath.Rel(workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// commandUsage describes how to run a workflow command and the arguments
+// it declares
+func commandUsage(workflowName, command string, spec *workflow.CommandSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: ddx workflow %s execute %s %s\n\nArguments:\n", workflowName, command, spec.Usage())
+	for _, arg := range spec.Args {
+		var notes []string
+		if arg.Required {
+			notes = append(notes, "required")
+		}
+		if len(arg.Values) > 0 {
+			notes = append(notes, "one of "+strings.Join(arg.Values, ", "))
+		} else if arg.Pattern != "" {
+			notes = append(notes, "matches "+arg.Pattern)
+		}
+		line := arg.Description
+		if len(notes) > 0 {
+			line = strings.TrimSpace(fmt.Sprintf("%s (%s)", line, strings.Join(notes, "; ")))
+		}
+		fmt.Fprintf(&b, "  %-15s %s\n", arg.Name, line)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// completeWorkflow completes the workflow command line down to the
+// arguments of an executed command, offering declared values and
+// describing the argument expected next
+func (f *CommandFactory) completeWorkflow(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	libPath := workflowLibraryPath(f.WorkingDir)
+	switch {
+	case len(args) == 0:
+		names := []string{"status", "list", "bindings", "lint", "run", "create", "activate", "deactivate", "advance", "report"}
+		entries, _ := os.ReadDir(filepath.Join(libPath, "workflows"))
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	case len(args) == 1:
+		if !isKnownWorkflow(args[0], f.WorkingDir) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return []string{"commands", "execute", "artifact"}, cobra.ShellCompDirectiveNoFileComp
+	case !strings.EqualFold(args[1], "execute"):
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	commandsDir := filepath.Join(libPath, "workflows", args[0], "commands")
+	if len(args) == 2 {
+		entries, _ := os.ReadDir(commandsDir)
+		var commands []string
+		for _, entry := range entries {
+			if name, ok := strings.CutSuffix(entry.Name(), ".md"); ok && !entry.IsDir() {
+				commands = append(commands, name+"\t"+getCommandDescription(filepath.Join(commandsDir, entry.Name())))
+			}
+		}
+		return commands, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	content, err := os.ReadFile(filepath.Join(commandsDir, args[2]+".md"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	spec, _, err := workflow.ParseCommandPrompt(string(content))
+	if err != nil || len(args)-3 >= len(spec.Args) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	arg := spec.Args[len(args)-3]
+	if len(arg.Values) > 0 {
+		var values []string
+		for _, value := range arg.Values {
+			if strings.HasPrefix(value, toComplete) {
+				values = append(values, value)
+			}
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+	help := "<" + arg.Name + ">"
+	if arg.Description != "" {
+		help += " " + arg.Description
+	}
+	if arg.Pattern != "" {
+		help += " (matches " + arg.Pattern + ")"
+	}
+	return cobra.AppendActiveHelp(nil, help), cobra.ShellCompDirectiveNoFileComp
+}
+
+// executePrompt assembles the prompt an executed workflow command sends to
+// an AI tool: the rendered command, the files it refers to, its arguments
+// and the bound personas
+func executePrompt(rendered, references string, args []string, personas string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(rendered, "\n"))
+	b.WriteString("\n")
+	if references != "" {
+		fmt.Fprintf(&b, "\n%s", references)
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(&b, "\nCommand Arguments: %s\n", strings.Join(args, " "))
+	}
+	if personas != "" {
+		fmt.Fprintf(&b, "\n%s", personas)
+	}
+	return b.String()
+}
+
+// sendExecutePrompt delivers the prompt to each destination named by the
+// --out, --copy and --exec flags. It reports whether any was given, in
+// which case the prompt is not printed.
+func sendExecutePrompt(cmd *cobra.Command, workingDir, prompt string) (bool, error) {
+	copyFlag, _ := cmd.Flags().GetBool("copy")
+	out, _ := cmd.Flags().GetString("out")
+	command, _ := cmd.Flags().GetString("exec")
+	if !copyFlag && out == "" && command == "" {
+		return false, nil
+	}
+
+	if out != "" {
+		path := out
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		if err := atomicfile.WriteFile(path, []byte(prompt), 0644); err != nil {
+			return true, fmt.Errorf("failed to write prompt: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "📝 Wrote prompt to %s\n", out)
+	}
+	if copyFlag {
+		if err := copyToClipboard(prompt); err != nil {
+			return true, fmt.Errorf("failed to copy prompt: %w", err)
+		}
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "📋 Copied prompt to the clipboard")
+	}
+	if command != "" {
+		if err := pipePrompt(cmd, workingDir, command, prompt); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// pipePrompt runs a command line through the platform shell with the prompt
+// on its stdin, passing its output through. A failing command's exit code
+// becomes ddx's.
+func pipePrompt(cmd *cobra.Command, workingDir, command, prompt string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var tool *exec.Cmd
+	if runtime.GOOS == "windows" {
+		tool = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		tool = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	tool.Dir = workingDir
+	tool.Env = os.Environ()
+	tool.Stdin = strings.NewReader(prompt)
+	tool.Stdout = cmd.OutOrStdout()
+	tool.Stderr = cmd.ErrOrStderr()
+
+	if err := tool.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return NewExitError(exitErr.ExitCode(), fmt.Sprintf("'%s' exited with status %d", command, exitErr.ExitCode()))
+		}
+		return fmt.Errorf("failed to run '%s': %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowExecuteOutputs(t *testing.T) {
+	setup := func(t *testing.T) *TestEnvironment {
+		env := NewTestEnvironment(t)
+		createConfigWithWorkflow(t, env, "helix")
+		env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "workflow.yml"), "name: helix\nversion: 1.0.0\nphases:\n  - id: frame\n    order: 1\n    name: Frame\n")
+		env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "commands", "build-story.md"), "# Build Story\n\nImplement the story.\n")
+		return env
+	}
+
+	t.Run("out", func(t *testing.T) {
+		env := setup(t)
+		output, err := env.RunCommand("workflow", "helix", "execute", "build-story", "US-001", "--out", "prompt.md")
+		require.NoError(t, err, output)
+		assert.NotContains(t, output, "Executing helix workflow command")
+
+		data, err := os.ReadFile(filepath.Join(env.Dir, "prompt.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Build Story\n\nImplement the story.\n\nCommand Arguments: US-001\n", string(data))
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		env := setup(t)
+		var copied string
+		saved := copyToClipboard
+		copyToClipboard = func(text string) error { copied = text; return nil }
+		defer func() { copyToClipboard = saved }()
+
+		output, err := env.RunCommand("workflow", "helix", "execute", "build-story", "--copy")
+		require.NoError(t, err, output)
+		assert.Equal(t, "# Build Story\n\nImplement the story.\n", copied)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses a POSIX shell")
+		}
+		env := setup(t)
+		output, err := env.RunCommand("workflow", "helix", "execute", "build-story", "US-001", "--exec", "tr a-z A-Z")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "IMPLEMENT THE STORY.")
+		assert.Contains(t, output, "COMMAND ARGUMENTS: US-001")
+
+		_, err = env.RunCommand("workflow", "helix", "execute", "build-story", "--exec", "cat >/dev/null; exit 4")
+		var exitErr *ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 4, exitErr.Code)
+	})
+}
+
+func TestWorkflowExecuteArgs(t *testing.T) {
+	env := NewTestEnvironment(t)
+	createConfigWithWorkflow(t, env, "helix")
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "workflow.yml"), "name: helix\nversion: 1.0.0\nphases:\n  - id: frame\n    order: 1\n    name: Frame\n")
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "commands", "build-story.md"), `---
+description: Implement a user story
+args:
+  - name: story-id
+    description: The story to build
+    required: true
+    pattern: US-[0-9]{3,}
+  - name: depth
+    values: [quick, full]
+---
+# Build Story
+
+Implement the story.
+`)
+
+	output, err := env.RunCommand("workflow", "helix", "execute", "build-story", "US-001", "--out", "prompt.md")
+	require.NoError(t, err, output)
+	data, err := os.ReadFile(filepath.Join(env.Dir, "prompt.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Build Story\n\nImplement the story.\n\nCommand Arguments: US-001\n", string(data))
+
+	_, err = env.RunCommand("workflow", "helix", "execute", "build-story", "story-1")
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, ExitCodeMissingArg, exitErr.Code)
+	assert.Contains(t, exitErr.Message, "<story-id> must match US-[0-9]{3,}, got 'story-1'")
+	assert.Contains(t, exitErr.Message, "Usage: ddx workflow helix execute build-story <story-id> [depth]")
+	assert.Contains(t, exitErr.Message, "The story to build (required; matches US-[0-9]{3,})")
+	assert.Contains(t, exitErr.Message, "one of quick, full")
+
+	_, err = env.RunCommand("workflow", "helix", "execute", "build-story")
+	assert.ErrorContains(t, err, "missing required argument <story-id>")
+
+	output, err = env.RunCommand("workflow", "helix", "commands")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "build-story <story-id> [depth] Implement a user story")
+
+	t.Run("completion", func(t *testing.T) {
+		output, err := env.RunCommand("__complete", "workflow", "helix", "execute", "")
+		require.NoError(t, err)
+		assert.Contains(t, output, "build-story\tImplement a user story")
+
+		output, err = env.RunCommand("__complete", "workflow", "helix", "execute", "build-story", "")
+		require.NoError(t, err)
+		assert.Contains(t, output, "<story-id> The story to build (matches US-[0-9]{3,})")
+
+		output, err = env.RunCommand("__complete", "workflow", "helix", "execute", "build-story", "US-001", "f")
+		require.NoError(t, err)
+		assert.Contains(t, output, "full")
+		assert.NotContains(t, output, "quick")
+	})
+}
+
+func TestWorkflowExecuteRefs(t *testing.T) {
+	env := NewTestEnvironment(t)
+	createConfigWithWorkflow(t, env, "helix")
+	helix := filepath.Join(".ddx", "library", "workflows", "helix")
+	env.CreateFile(filepath.Join(helix, "workflow.yml"), "name: helix\nversion: 1.0.0\nphases:\n  - id: frame\n    order: 1\n    name: Frame\n")
+	env.CreateFile(filepath.Join(helix, "phases", "04-build", "README.md"), "# Build Phase\n\nWrite tests first.\n")
+	env.CreateFile(filepath.Join(helix, "conventions.md"), strings.Repeat("x", 100)+"\n")
+	env.CreateFile(filepath.Join(helix, "commands", "build-story.md"), `---
+references:
+  - ../phases/04-build/README.md
+---
+# Build Story
+
+Follow the [conventions](../conventions.md) and the [checklist](checklist.md).
+`)
+	execute := func(args ...string) string {
+		t.Helper()
+		output, err := env.RunCommand(append([]string{"workflow", "helix", "execute", "build-story", "--out", "prompt.md"}, args...)...)
+		require.NoError(t, err, output)
+		data, err := os.ReadFile(filepath.Join(env.Dir, "prompt.md"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	prompt := execute()
+	assert.Contains(t, prompt, "Referenced Files:\n- .ddx/library/workflows/helix/phases/04-build/README.md\n- .ddx/library/workflows/helix/conventions.md\n")
+
+	prompt = execute("--refs", "inline", "--refs-limit", "60")
+	assert.Contains(t, prompt, "<file path=\".ddx/library/workflows/helix/phases/04-build/README.md\">\n# Build Phase\n\nWrite tests first.\n</file>\n")
+	assert.Contains(t, prompt, "Not inlined, over the --refs-limit:\n- .ddx/library/workflows/helix/conventions.md\n")
+
+	assert.NotContains(t, execute("--refs", "none"), "Referenced Files")
+
+	output, err := env.RunCommand("workflow", "helix", "execute", "build-story", "--refs", "json")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Reference 'checklist.md' in .ddx/library/workflows/helix/commands/build-story.md is not a file in the library")
+	assert.Contains(t, output, `"link": "../conventions.md"`)
+	assert.Contains(t, output, `"path": ".ddx/library/workflows/helix/conventions.md"`)
+
+	_, err = env.RunCommand("workflow", "helix", "execute", "build-story", "--refs", "all")
+	assert.ErrorContains(t, err, "invalid --refs 'all'")
+}
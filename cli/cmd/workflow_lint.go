@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// lintWorkflowsWithDir checks one workflow, or every workflow in the
+// library, against the workflow schema. Active workflows missing from the
+// library are reported too. It fails when any error is found.
+func lintWorkflowsWithDir(cmd *cobra.Command, name string, workingDir string) error {
+	cmd.SilenceUsage = true
+	libPath := workflowLibraryPath(workingDir)
+	loader := workflow.NewLoader(libPath)
+	out := cmd.OutOrStdout()
+
+	names := []string{name}
+	if name == "" {
+		names = nil
+		entries, err := os.ReadDir(filepath.Join(libPath, "workflows"))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read workflows: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		if cfg, err := loadConfigFrom(workingDir); err == nil && cfg != nil {
+			for _, active := range cfg.Workflows.Active {
+				if !isKnownWorkflow(active, workingDir) {
+					names = append(names, active)
+				}
+			}
+		}
+	} else if !isKnownWorkflow(name, workingDir) {
+		return fmt.Errorf("workflow '%s' not found", name)
+	}
+	if len(names) == 0 {
+		_, _ = fmt.Fprintln(out, "No workflows found")
+		return nil
+	}
+
+	opts := workflow.LintOptions{Roles: definedRoles(workingDir)}
+	failed := 0
+	for _, n := range names {
+		var issues []workflow.Issue
+		if isKnownWorkflow(n, workingDir) {
+			var err error
+			if issues, err = loader.Lint(n, opts); err != nil {
+				return err
+			}
+		} else {
+			issues = []workflow.Issue{{Severity: workflow.SeverityError, Field: "workflows.active", Message: "is active in .ddx/config.yaml but not in the library"}}
+		}
+
+		if len(issues) == 0 {
+			_, _ = fmt.Fprintf(out, "✅ %s\n", n)
+			continue
+		}
+		hasErrors := false
+		for _, issue := range issues {
+			hasErrors = hasErrors || issue.Severity == workflow.SeverityError
+		}
+		if hasErrors {
+			failed++
+			_, _ = fmt.Fprintf(out, "❌ %s\n", n)
+		} else {
+			_, _ = fmt.Fprintf(out, "⚠️  %s\n", n)
+		}
+		for _, issue := range issues {
+			_, _ = fmt.Fprintf(out, "   %s: %s\n", issue.Severity, issue)
+		}
+	}
+
+	if failed > 0 {
+		return NewExitError(1, fmt.Sprintf("%d workflow(s) have errors", failed))
+	}
+	return nil
+}
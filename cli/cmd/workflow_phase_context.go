@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/filelock"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/targets"
+	"github.com/easel/ddx/internal/workflow"
+)
+
+// phaseContextSource marks the managed sections holding a workflow's
+// current phase; the workflow name follows it
+const phaseContextSource = "workflow:"
+
+// phaseContextSectionName names the managed section for a workflow's phase
+func phaseContextSectionName(name string) string {
+	return name + "-phase"
+}
+
+// phaseContext renders the section describing a workflow's current phase:
+// its name and description, principles and exit criteria. It is empty when
+// the workflow is not active or has no current phase.
+func phaseContext(workingDir, name string) (string, error) {
+	cfg, err := loadConfigFrom(workingDir)
+	if err != nil || cfg == nil {
+		return "", err
+	}
+	if !slices.Contains(cfg.Workflows.Active, name) {
+		return "", nil
+	}
+	state, err := loadOrStartWorkflowState(workingDir, name)
+	if err != nil || state.CurrentPhase == "" {
+		return "", err
+	}
+	def, err := workflow.NewLoader(workflowLibraryPath(workingDir)).Load(name)
+	if err != nil {
+		return "", err
+	}
+	phase := def.GetPhaseByID(state.CurrentPhase)
+	if phase == nil {
+		return "", nil
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "## Current Workflow Phase\n\nThe %s workflow is in the **%s** phase (%s).", name, phase.Name, phase.ID)
+	if phase.Description != "" {
+		_, _ = fmt.Fprintf(&b, " %s.", strings.TrimSuffix(phase.Description, "."))
+	}
+	b.WriteString("\n")
+	if len(phase.Principles) > 0 {
+		b.WriteString("\nWork by these principles:\n")
+		for _, p := range phase.Principles {
+			_, _ = fmt.Fprintf(&b, "- %s\n", p)
+		}
+	}
+	if len(phase.ExitCriteria) > 0 {
+		b.WriteString("\nThe phase is complete when:\n")
+		for _, c := range phase.ExitCriteria {
+			_, _ = fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// updatePhaseContext brings the phase section of a workflow up to date in
+// each AI target file that exists, removing it when the workflow is no
+// longer active. Sections edited by hand are left alone.
+func updatePhaseContext(workingDir, name string) error {
+	content, err := phaseContext(workingDir, name)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadWithWorkingDir(workingDir)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	targetList, _, err := aiTargets(cfg)
+	if err != nil {
+		return err
+	}
+
+	section := phaseContextSectionName(name)
+	for _, file := range targets.Files(targetList) {
+		path := filepath.Join(workingDir, file)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		err := filelock.With(path, func() error {
+			doc, err := sections.Load(path)
+			if err != nil {
+				return err
+			}
+			existing, found := doc.Get(section)
+			if found && existing.Modified() {
+				slog.Warn("phase context was edited by hand; not updating it", "file", file, "section", section)
+				return nil
+			}
+			changed := false
+			if content == "" {
+				changed = doc.Remove(section)
+			} else {
+				changed = doc.Set(sections.Section{Name: section, Source: phaseContextSource + name, Order: sections.DefaultOrder, Content: content})
+			}
+			if !changed {
+				return nil
+			}
+			return doc.Save(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshPhaseContext updates a workflow's phase section after its state
+// or activation changed. Failing to do so is not worth failing the command
+// over.
+func refreshPhaseContext(workingDir, name string) {
+	if err := updatePhaseContext(workingDir, name); err != nil {
+		slog.Warn("could not update workflow phase context", "workflow", name, "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowPhaseContext(t *testing.T) {
+	env := NewTestEnvironment(t)
+	createConfigWithWorkflows(t, env, nil)
+	env.CreateFile(filepath.Join(".ddx", "library", "workflows", "helix", "workflow.yml"), `name: helix
+version: 1.0.0
+phases:
+  - id: frame
+    order: 1
+    name: Frame
+    description: Define the problem
+    principles:
+      - Understand the problem first
+  - id: test
+    order: 2
+    name: Write Tests First
+    principles:
+      - "Tests first: write failing tests before any implementation"
+    exit_criteria:
+      - All contract tests written
+`)
+	env.CreateFile("CLAUDE.md", "# Project\n\nOur own notes.\n")
+	claude := func() string {
+		data, err := os.ReadFile(filepath.Join(env.Dir, "CLAUDE.md"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	output, err := env.RunCommand("workflow", "activate", "helix")
+	require.NoError(t, err, output)
+	content := claude()
+	assert.Contains(t, content, `<!-- DDX:START name="helix-phase" source="workflow:helix"`)
+	assert.Contains(t, content, "The helix workflow is in the **Frame** phase (frame). Define the problem.")
+	assert.Contains(t, content, "- Understand the problem first")
+	assert.Contains(t, content, "Our own notes.")
+
+	output, err = env.RunCommand("workflow", "advance", "helix")
+	require.NoError(t, err, output)
+	content = claude()
+	assert.Contains(t, content, "**Write Tests First** phase (test)")
+	assert.Contains(t, content, "- Tests first: write failing tests before any implementation")
+	assert.Contains(t, content, "The phase is complete when:\n- All contract tests written")
+	assert.NotContains(t, content, "Understand the problem first")
+
+	output, err = env.RunCommand("prompts", "inject")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "helix-phase is up to date")
+
+	output, err = env.RunCommand("workflow", "deactivate", "helix")
+	require.NoError(t, err, output)
+	content = claude()
+	assert.NotContains(t, content, "helix-phase")
+	assert.Contains(t, content, "Our own notes.")
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/agent"
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// runWorkflowPipelineWithDir runs the steps of a workflow pipeline through
+// the configured agent without prompting. Each step's prompt and output are
+// saved under .ddx/runs/<timestamp>/ with a summary.json; the run stops at
+// the first failing step and exits with its exit code.
+func runWorkflowPipelineWithDir(cmd *cobra.Command, name, pipeline string, workingDir string) error {
+	loader := workflow.NewLoader(workflowLibraryPath(workingDir))
+	def, err := loader.Load(name)
+	if err != nil {
+		return fmt.Errorf("workflow '%s' not found", name)
+	}
+	if pipeline == "" {
+		pipeline = workflow.DefaultPipeline
+	}
+	steps, err := def.Pipeline(pipeline)
+	if err != nil {
+		return err
+	}
+
+	settings := &config.AgentsConfig{}
+	if cfg, err := config.LoadWithWorkingDir(workingDir); err == nil && cfg.Agents != nil {
+		settings = cfg.Agents
+	}
+	resolved, err := agent.Resolve(settings)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	runDir, err := createRunDir(workingDir, started)
+	if err != nil {
+		return err
+	}
+	rel, _ := filepath.Rel(workingDir, runDir)
+	summary := workflow.RunSummary{
+		Workflow: name,
+		Pipeline: pipeline,
+		Dir:      filepath.ToSlash(rel),
+		Started:  started,
+		Status:   workflow.RunPassed,
+	}
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+	out := cmd.OutOrStdout()
+	if !asJSON {
+		_, _ = fmt.Fprintf(out, "Running %s pipeline '%s' (%d steps)\n\n", name, pipeline, len(steps))
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for i, step := range steps {
+		if summary.Status == workflow.RunFailed {
+			summary.Steps = append(summary.Steps, workflow.StepResult{Command: step.Command, Args: step.Args, Status: workflow.RunSkipped})
+			continue
+		}
+		result := runPipelineStep(ctx, cmd, resolved, def, step, i+1, runDir, workingDir)
+		summary.Steps = append(summary.Steps, result)
+		if result.Status == workflow.RunFailed {
+			summary.Status = workflow.RunFailed
+			summary.ExitCode = result.ExitCode
+		}
+		if !asJSON {
+			mark := "✅"
+			if result.Status == workflow.RunFailed {
+				mark = "❌"
+			}
+			_, _ = fmt.Fprintf(out, "%s %s (%s)\n", mark, step.Command, time.Duration(result.DurationMS)*time.Millisecond)
+			if result.Error != "" {
+				_, _ = fmt.Fprintf(out, "   %s\n", result.Error)
+			}
+		}
+	}
+	summary.Finished = time.Now()
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run summary: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(runDir, "summary.json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run summary: %w", err)
+	}
+
+	if asJSON {
+		_, _ = fmt.Fprintln(out, string(data))
+	} else {
+		_, _ = fmt.Fprintf(out, "\nOutput saved to %s\n", summary.Dir)
+	}
+	if summary.Status == workflow.RunFailed {
+		cmd.SilenceUsage = true
+		if asJSON {
+			cmd.SilenceErrors = true
+			return NewExitError(summary.ExitCode, "")
+		}
+		return NewExitError(summary.ExitCode, fmt.Sprintf("pipeline '%s' failed", pipeline))
+	}
+	return nil
+}
+
+// runPipelineStep renders a step's command prompt, saves it and runs it
+// through the agent with the output captured to the run directory
+func runPipelineStep(ctx context.Context, cmd *cobra.Command, resolved *agent.Agent, def *workflow.Definition, step workflow.Step, n int, runDir, workingDir string) workflow.StepResult {
+	result := workflow.StepResult{Command: step.Command, Args: step.Args, Status: workflow.RunFailed, ExitCode: 1}
+	start := time.Now()
+
+	prompt, err := pipelinePrompt(cmd, def, step, workingDir)
+	if err != nil {
+		result.Error = err.Error()
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result
+	}
+	base := fmt.Sprintf("%02d-%s", n, step.Command)
+	result.Prompt, result.Output = base+".prompt.md", base+".out"
+	if err := atomicfile.WriteFile(filepath.Join(runDir, result.Prompt), []byte(prompt), 0644); err != nil {
+		result.Error = fmt.Sprintf("failed to save prompt: %v", err)
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result
+	}
+	output, err := os.Create(filepath.Join(runDir, result.Output))
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create output file: %v", err)
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result
+	}
+	defer func() { _ = output.Close() }()
+
+	inv, err := resolved.Prepare(prompt, true)
+	if err != nil {
+		result.Error = err.Error()
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result
+	}
+	defer inv.Cleanup()
+
+	err = inv.Run(ctx, workingDir, strings.NewReader(""), output, output)
+	result.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		result.Error = err.Error()
+		return result
+	}
+	result.Status, result.ExitCode = workflow.RunPassed, 0
+
+	if err := recordWorkflowExecution(workingDir, def.Name, step.Command, step.Args); err != nil {
+		slog.Warn("could not record workflow activity", "workflow", def.Name, "error", err)
+	}
+	return result
+}
+
+// pipelinePrompt builds the prompt of a step the way 'execute' shows it:
+// the rendered command, the files it refers to, its arguments and the
+// workflow's personas
+func pipelinePrompt(cmd *cobra.Command, def *workflow.Definition, step workflow.Step, workingDir string) (string, error) {
+	path := filepath.Join(workflowLibraryPath(workingDir), "workflows", def.Name, filepath.FromSlash(def.CommandPromptPath(step.Command)))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("command '%s' not found in workflow '%s'", step.Command, def.Name)
+	}
+	spec, body, err := commandPrompt(def.Name, step.Command, string(content), step.Args)
+	if err != nil {
+		return "", err
+	}
+	references, err := referencesSection(cmd, workingDir, commandReferences(cmd, workingDir, def.Name, path, spec, body))
+	if err != nil {
+		return "", err
+	}
+	prompt, err := renderWithVariables(cmd, workingDir, body)
+	if err != nil {
+		return "", err
+	}
+	if references != "" {
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + references
+	}
+	if len(step.Args) > 0 {
+		prompt += fmt.Sprintf("\n\nCommand Arguments: %s\n", strings.Join(step.Args, " "))
+	}
+	if personas := workflowPersonaContext(workingDir, def.Name); personas != "" {
+		prompt += "\n\n" + personas
+	}
+	return prompt, nil
+}
+
+// createRunDir creates a directory for a run's output named after its
+// start time. Run output is kept out of version control.
+func createRunDir(workingDir string, started time.Time) (string, error) {
+	root := filepath.Join(workingDir, filepath.FromSlash(workflow.RunsDir))
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", workflow.RunsDir, err)
+	}
+	ignore := filepath.Join(root, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	stamp := started.Format("20060102-150405")
+	for i := 1; ; i++ {
+		dir := filepath.Join(root, stamp)
+		if i > 1 {
+			dir = fmt.Sprintf("%s-%d", dir, i)
+		}
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create run directory: %w", err)
+		}
+	}
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/easel/ddx/internal/workflow"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -219,12 +221,12 @@ func TestIsKnownWorkflow(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			//	// originalDir, _ := os.Getwd() // REMOVED: Using CommandFactory injection // REMOVED: Using CommandFactory injection
-			_ = tt.setup(t)
+			workDir := tt.setup(t)
 			defer func() {
 			}()
 
 			// This will fail initially - function doesn't exist
-			result := isKnownWorkflow(tt.workflow)
+			result := isKnownWorkflow(tt.workflow, workDir)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -274,3 +276,169 @@ func loadWorkflowCommandContent(workflow, command string, args []string) (string
 
 	return string(content), nil
 }
+
+// TestWorkflowCreate tests that a created workflow is usable right away
+func TestWorkflowCreate(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateDefaultConfig()
+
+	output, err := env.RunCommand("workflow", "create", "review-cycle", "--phases", "draft,review", "--description", "Draft and review documents")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "Created workflow 'review-cycle' with 2 phase(s) in .ddx/library/workflows/review-cycle")
+	assert.FileExists(t, filepath.Join(env.Dir, ".ddx/library/workflows/review-cycle/phases/02-review/README.md"))
+
+	output, err = env.RunCommand("workflow", "review-cycle", "commands")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "start")
+	assert.Contains(t, output, "Start the review-cycle workflow")
+
+	output, err = env.RunCommand("workflow", "list")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "review-cycle - Draft and review documents")
+
+	_, err = env.RunCommand("workflow", "create", "review-cycle")
+	assert.ErrorContains(t, err, "workflow 'review-cycle' already exists")
+}
+
+// TestWorkflowLint tests linting library and active workflows
+func TestWorkflowLint(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+workflows:
+  active: [ghost]
+`)
+	_, err := env.RunCommand("workflow", "create", "review-cycle")
+	require.NoError(t, err)
+	env.CreateFile(".ddx/library/workflows/broken/workflow.yml", "name: broken\nversion: 1.0.0\nphases:\n  - id: one\n    order: 1\n    name: One\n    owner: me\n")
+
+	output, err := env.RunCommand("workflow", "lint", "review-cycle")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "✅ review-cycle")
+
+	output, err = env.RunCommand("workflow", "lint")
+	assert.EqualError(t, err, "2 workflow(s) have errors")
+	assert.Contains(t, output, "❌ broken\n   error: phases[0].owner: unknown field")
+	assert.Contains(t, output, "❌ ghost\n   error: workflows.active: is active in .ddx/config.yaml but not in the library")
+	assert.Contains(t, output, "✅ review-cycle")
+}
+
+// TestWorkflowPersonaOverrides tests that overrides.<workflow> replaces
+// persona bindings when the workflow's commands run
+func TestWorkflowPersonaOverrides(t *testing.T) {
+	env := NewTestEnvironment(t, WithGitInit(false))
+	env.CreateConfig(`version: "1.0"
+library:
+  path: .ddx/library
+persona_bindings:
+  test-engineer: test-engineer-tdd
+  architect: systems-architect
+overrides:
+  performance-workflow:
+    test-engineer: test-engineer-bdd
+`)
+	env.CreateFile(".ddx/library/personas/test-engineer-tdd.md", "---\nname: test-engineer-tdd\nroles: [test-engineer]\n---\n# TDD Engineer\n")
+	env.CreateFile(".ddx/library/personas/test-engineer-bdd.md", "---\nname: test-engineer-bdd\nroles: [test-engineer]\n---\n# BDD Engineer\n")
+	_, err := env.RunCommand("workflow", "create", "performance-workflow", "--phases", "measure,tune")
+	require.NoError(t, err)
+	env.CreateFile(".ddx/library/workflows/performance-workflow/workflow.yml", `name: performance-workflow
+version: 1.0.0
+phases:
+  - id: measure
+    order: 1
+    name: Measure
+    required_role: test-engineer
+  - id: tune
+    order: 2
+    name: Tune
+    required_role: performance-engineer
+`)
+
+	output, err := env.RunCommand("workflow", "bindings", "performance-workflow")
+	require.NoError(t, err, output)
+	assert.Regexp(t, `test-engineer\s+test-engineer-bdd\s+overrides.performance-workflow\s+measure`, output)
+	assert.Regexp(t, `architect\s+systems-architect\s+persona_bindings`, output)
+	assert.Regexp(t, `performance-engineer\s+⚠️  unbound`, output)
+
+	output, err = env.RunCommand("workflow", "performance-workflow", "execute", "start", "--no-record")
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "• test-engineer → test-engineer-bdd (overrides.performance-workflow)")
+	assert.Contains(t, output, "### Test Engineer: test-engineer-bdd\n---\nname: test-engineer-bdd")
+	assert.NotContains(t, output, "TDD Engineer")
+}
+
+// TestWorkflowRun tests running a pipeline through the agent
+func TestWorkflowRun(t *testing.T) {
+	setup := func(t *testing.T, agents string) *TestEnvironment {
+		env := NewTestEnvironment(t, WithGitInit(false))
+		env.CreateConfig("version: \"1.0\"\nlibrary:\n  path: .ddx/library\nagents:\n" + agents)
+		_, err := env.RunCommand("workflow", "create", "review-cycle")
+		require.NoError(t, err)
+		env.CreateFile(".ddx/library/workflows/review-cycle/commands/review.md", "# Review\n\nReview the changes.\n")
+		env.CreateFile(".ddx/library/workflows/review-cycle/workflow.yml", `name: review-cycle
+version: 1.0.0
+phases:
+  - id: plan
+    order: 1
+    name: Plan
+pipelines:
+  default:
+    - command: start
+    - command: review
+      args: [--strict]
+`)
+		return env
+	}
+	readSummary := func(t *testing.T, env *TestEnvironment) workflow.RunSummary {
+		matches, _ := filepath.Glob(filepath.Join(env.Dir, ".ddx", "runs", "*", "summary.json"))
+		require.Len(t, matches, 1)
+		data, err := os.ReadFile(matches[0])
+		require.NoError(t, err)
+		var summary workflow.RunSummary
+		require.NoError(t, json.Unmarshal(data, &summary))
+		return summary
+	}
+
+	t.Run("passes", func(t *testing.T) {
+		env := setup(t, "  command: cat\n")
+		output, err := env.RunCommand("workflow", "run", "review-cycle")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "✅ start")
+		assert.Contains(t, output, "✅ review")
+
+		summary := readSummary(t, env)
+		assert.Equal(t, workflow.RunPassed, summary.Status)
+		require.Len(t, summary.Steps, 2)
+		out, err := os.ReadFile(filepath.Join(env.Dir, summary.Dir, summary.Steps[1].Output))
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "Review the changes.")
+		assert.Contains(t, string(out), "Command Arguments: --strict")
+		assert.FileExists(t, filepath.Join(env.Dir, ".ddx", "runs", ".gitignore"))
+	})
+
+	t.Run("stops on failure", func(t *testing.T) {
+		env := setup(t, "  command: sh\n  args: [\"-c\", \"exit 3\"]\n")
+		output, err := env.RunCommand("workflow", "run", "review-cycle", "--json")
+		var exitErr *ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.Code)
+
+		var printed workflow.RunSummary
+		require.NoError(t, json.Unmarshal([]byte(output), &printed), output)
+		summary := readSummary(t, env)
+		assert.Equal(t, printed.Dir, summary.Dir)
+		assert.Equal(t, workflow.RunFailed, summary.Status)
+		assert.Equal(t, 3, summary.ExitCode)
+		require.Len(t, summary.Steps, 2)
+		assert.Equal(t, workflow.RunFailed, summary.Steps[0].Status)
+		assert.Equal(t, workflow.RunSkipped, summary.Steps[1].Status)
+	})
+
+	t.Run("unknown pipeline", func(t *testing.T) {
+		env := setup(t, "  command: cat\n")
+		_, err := env.RunCommand("workflow", "run", "review-cycle", "nightly")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pipeline 'nightly' not found")
+	})
+}
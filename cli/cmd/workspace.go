@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/easel/ddx/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// projectAnnotation marks commands that do not look for a project above
+// the working directory, such as init, which creates one there
+const (
+	projectAnnotation = "ddx.project"
+	projectHere       = "here"
+)
+
+// WorkspaceProject is a sub-project in 'ddx workspace status'
+type WorkspaceProject struct {
+	Path     string           `json:"path"`
+	Current  bool             `json:"current"`
+	Overview *ProjectOverview `json:"overview"`
+}
+
+// selectProject points the factory at the project a command works on: the
+// one given with --project, or else the nearest project at or above the
+// working directory
+func (f *CommandFactory) selectProject(cmd *cobra.Command) error {
+	name, _ := cmd.Flags().GetString("project")
+	if name == "" {
+		if cmd.Annotations[projectAnnotation] == projectHere || workspace.IsProject(f.WorkingDir) {
+			return nil
+		}
+		if dir, ok := workspace.FindProject(f.WorkingDir); ok {
+			slog.Debug("using project above the working directory", "project", dir)
+			f.WorkingDir = dir
+		}
+		return nil
+	}
+
+	dir, err := projectDir(f.WorkingDir, name)
+	if err != nil {
+		return NewExitError(1, err.Error())
+	}
+	slog.Debug("using project from --project", "project", dir)
+	f.WorkingDir = dir
+	return nil
+}
+
+// projectDir resolves --project: a directory, relative to the working
+// directory unless absolute, or else a project of the enclosing workspace
+// named by its path or last path element
+func projectDir(workingDir, name string) (string, error) {
+	dir := name
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workingDir, dir)
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir, nil
+	}
+	ws, err := workspace.Find(workingDir)
+	if err != nil {
+		return "", err
+	}
+	if ws == nil {
+		return "", fmt.Errorf("project %s not found", name)
+	}
+	project, err := ws.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return project.Dir, nil
+}
+
+// currentWorkspace finds the workspace around the working directory
+func (f *CommandFactory) currentWorkspace() (*workspace.Workspace, []workspace.Project, error) {
+	ws, err := workspace.Find(f.WorkingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ws == nil {
+		return nil, nil, fmt.Errorf("no workspace found: list the sub-projects in .ddx/%s at the repository root", workspace.FileName)
+	}
+	projects, err := ws.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ws, projects, nil
+}
+
+// runWorkspaceList implements 'ddx workspace list'
+func (f *CommandFactory) runWorkspaceList(cmd *cobra.Command, args []string) error {
+	_, projects, err := f.currentWorkspace()
+	if err != nil {
+		return err
+	}
+	if format, _ := cmd.Flags().GetString("format"); format != "" {
+		return renderFormat(cmd.OutOrStdout(), format, projects)
+	}
+	if len(projects) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No projects in the workspace")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  PROJECT\tSTATUS")
+	for _, project := range projects {
+		marker := " "
+		if project.Dir == f.WorkingDir {
+			marker = "*"
+		}
+		status := "initialized"
+		if !project.Initialized {
+			status = "not initialized"
+		}
+		_, _ = fmt.Fprintf(w, "%s %s\t%s\n", marker, project.Path, status)
+	}
+	return w.Flush()
+}
+
+// runWorkspaceStatus implements 'ddx workspace status', the project
+// overview of 'ddx status' for every sub-project
+func (f *CommandFactory) runWorkspaceStatus(cmd *cobra.Command, args []string) error {
+	ws, projects, err := f.currentWorkspace()
+	if err != nil {
+		return err
+	}
+
+	report := make([]WorkspaceProject, 0, len(projects))
+	for _, project := range projects {
+		report = append(report, WorkspaceProject{
+			Path:     project.Path,
+			Current:  project.Dir == f.WorkingDir,
+			Overview: collectProjectOverview(project.Dir),
+		})
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode workspace status: %w", err)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Workspace: %s (%d project(s))\n", ws.Root, len(report))
+	for _, project := range report {
+		title := project.Path
+		if project.Current {
+			title += " (current)"
+		}
+		_, _ = fmt.Fprintf(out, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+		displayProjectOverview(out, project.Overview)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace(t *testing.T) {
+	t.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(".ddx/workspace.yaml", "projects:\n  - services/*\n  - tools/cli\n")
+	write("services/api/.ddx/config.yaml", "version: \"1.0\"\nlibrary:\n  path: .ddx/library\npersona_bindings:\n  code-reviewer: api-reviewer\n")
+	write("services/web/.ddx/config.yaml", "version: \"1.0\"\nlibrary:\n  path: .ddx/library\npersona_bindings:\n  code-reviewer: web-reviewer\n")
+	apiSrc := filepath.Join(root, "services", "api", "src")
+	require.NoError(t, os.MkdirAll(apiSrc, 0755))
+	run := func(dir string, args ...string) (string, error) {
+		return executeCommand(NewCommandFactory(dir).NewRootCommand(), args...)
+	}
+
+	t.Run("nearest project", func(t *testing.T) {
+		output, err := run(apiSrc, "persona", "bindings")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "api-reviewer")
+	})
+
+	t.Run("project flag", func(t *testing.T) {
+		output, err := run(apiSrc, "--project", "web", "persona", "bindings")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "web-reviewer")
+
+		output, err = run(root, "--project", "services/api", "persona", "bindings")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "api-reviewer")
+
+		_, err = run(root, "--project", "billing", "persona", "bindings")
+		assert.ErrorContains(t, err, `project "billing" is not in the workspace`)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		output, err := run(apiSrc, "workspace", "list")
+		require.NoError(t, err, output)
+		assert.Regexp(t, `\* services/api\s+initialized`, output)
+		assert.Regexp(t, `  services/web\s+initialized`, output)
+		assert.Regexp(t, `  tools/cli\s+not initialized`, output)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		output, err := run(root, "workspace", "status")
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "(3 project(s))")
+		assert.Contains(t, output, "services/web\n------------\nConfig:       .ddx/config.yaml (version 1.0)")
+		assert.Contains(t, output, "tools/cli\n---------\nConfig:       not initialized")
+
+		output, err = run(apiSrc, "workspace", "status", "--json")
+		require.NoError(t, err, output)
+		var report []WorkspaceProject
+		require.NoError(t, json.Unmarshal([]byte(output), &report))
+		require.Len(t, report, 3)
+		assert.True(t, report[0].Current)
+		assert.True(t, report[0].Overview.Initialized)
+		assert.False(t, report[2].Overview.Initialized)
+	})
+
+	t.Run("no workspace", func(t *testing.T) {
+		_, err := run(t.TempDir(), "workspace", "list")
+		assert.ErrorContains(t, err, "no workspace found")
+	})
+}
@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+)
+
+// DefaultPreset is used when the configuration names no agent
+const DefaultPreset = "claude"
+
+// Agent is a resolved agent invocation
+type Agent struct {
+	Preset Preset
+	Model  string
+	// Args are configured arguments appended after the preset's
+	Args []string
+}
+
+// Resolve builds the agent described by the agents configuration. Explicit
+// command, args and input settings override the preset; a command without
+// a preset defines a custom agent.
+func Resolve(cfg *config.AgentsConfig) (*Agent, error) {
+	if cfg == nil {
+		cfg = &config.AgentsConfig{}
+	}
+
+	var preset Preset
+	switch {
+	case cfg.Preset != "":
+		p, err := LookupPreset(cfg.Preset)
+		if err != nil {
+			return nil, err
+		}
+		preset = p
+	case cfg.Command != "":
+		preset = Preset{Name: "custom", Description: "Custom agent command", Input: InputStdin}
+	default:
+		preset, _ = LookupPreset(DefaultPreset)
+	}
+
+	if cfg.Command != "" {
+		preset.Command = cfg.Command
+	}
+	if cfg.Input != "" {
+		mode, err := ParseInputMode(cfg.Input)
+		if err != nil {
+			return nil, err
+		}
+		preset.Input = mode
+	}
+
+	return &Agent{Preset: preset, Model: cfg.Model, Args: append([]string(nil), cfg.Args...)}, nil
+}
+
+// Invocation is a prepared agent command line
+type Invocation struct {
+	Command string
+	Args    []string
+	// Env holds variables added to the agent's environment
+	Env []string
+	// Stdin is the prompt when the agent reads it from standard input
+	Stdin string
+	// PromptFile is the temporary prompt file, removed by Cleanup
+	PromptFile string
+}
+
+// Cleanup removes the temporary prompt file, if any
+func (inv *Invocation) Cleanup() {
+	if inv.PromptFile != "" {
+		_ = os.Remove(inv.PromptFile)
+	}
+}
+
+// String renders the command line for display; a prompt sent on stdin is
+// shown as a redirect
+func (inv *Invocation) String() string {
+	parts := []string{inv.Command}
+	for _, arg := range inv.Args {
+		if strings.ContainsAny(arg, " \t\n\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts = append(parts, arg)
+	}
+	line := strings.Join(append(inv.Env, parts...), " ")
+	if inv.Stdin != "" {
+		line += " < prompt"
+	}
+	return line
+}
+
+// Prepare builds the command line for sending prompt to the agent. When
+// plain is set, the preset's arguments for non-terminal output are added.
+func (a *Agent) Prepare(prompt string, plain bool) (*Invocation, error) {
+	if a.Preset.Command == "" {
+		return nil, fmt.Errorf("agent has no command; set agents.command or agents.preset")
+	}
+
+	inv := &Invocation{Command: a.Preset.Command}
+	usesPlaceholder := false
+	for _, arg := range append(append([]string(nil), a.Preset.Args...), a.Args...) {
+		if strings.Contains(arg, PromptFilePlaceholder) {
+			usesPlaceholder = true
+			if inv.PromptFile == "" {
+				path, err := writePromptFile(prompt)
+				if err != nil {
+					return nil, err
+				}
+				inv.PromptFile = path
+			}
+			arg = strings.ReplaceAll(arg, PromptFilePlaceholder, inv.PromptFile)
+		}
+		if strings.Contains(arg, PromptPlaceholder) {
+			usesPlaceholder = true
+			arg = strings.ReplaceAll(arg, PromptPlaceholder, prompt)
+		}
+		inv.Args = append(inv.Args, arg)
+	}
+
+	if a.Model != "" {
+		switch {
+		case a.Preset.ModelFlag != "":
+			inv.Args = append(inv.Args, a.Preset.ModelFlag, a.Model)
+		case a.Preset.ModelEnv != "":
+			inv.Env = append(inv.Env, a.Preset.ModelEnv+"="+a.Model)
+		}
+	}
+	if plain {
+		inv.Args = append(inv.Args, a.Preset.PlainArgs...)
+	}
+
+	// Deliver the prompt by the input mode unless an argument carries it
+	if !usesPlaceholder {
+		switch a.Preset.Input {
+		case InputArg:
+			inv.Args = append(inv.Args, prompt)
+		case InputFile:
+			path, err := writePromptFile(prompt)
+			if err != nil {
+				return nil, err
+			}
+			inv.PromptFile = path
+			inv.Args = append(inv.Args, path)
+		default:
+			inv.Stdin = prompt
+		}
+	}
+	return inv, nil
+}
+
+// writePromptFile saves the prompt to a temporary markdown file
+func writePromptFile(prompt string) (string, error) {
+	file, err := os.CreateTemp("", "ddx-prompt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := file.WriteString(prompt); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// Run executes the invocation in dir. Unless the prompt is sent on stdin,
+// the agent reads from in so that it can ask the user questions.
+func (inv *Invocation) Run(ctx context.Context, dir string, in io.Reader, out, errOut io.Writer) error {
+	if _, err := exec.LookPath(inv.Command); err != nil {
+		return fmt.Errorf("agent command %q not found on PATH: %w", inv.Command, err)
+	}
+
+	slog.Debug("running agent", "command", inv.String(), "dir", dir)
+	cmd := exec.CommandContext(ctx, inv.Command, inv.Args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), inv.Env...)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	if inv.Stdin != "" {
+		cmd.Stdin = strings.NewReader(inv.Stdin)
+	} else {
+		cmd.Stdin = in
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"os"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	a, err := Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPreset, a.Preset.Name)
+
+	a, err = Resolve(&config.AgentsConfig{Preset: "Aider", Command: "/opt/aider/bin/aider", Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.Equal(t, "aider", a.Preset.Name)
+	assert.Equal(t, "/opt/aider/bin/aider", a.Preset.Command)
+	assert.Equal(t, "gpt-4o", a.Model)
+
+	a, err = Resolve(&config.AgentsConfig{Command: "my-agent", Input: "arg"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", a.Preset.Name)
+	assert.Equal(t, InputArg, a.Preset.Input)
+
+	_, err = Resolve(&config.AgentsConfig{Preset: "cursor"})
+	assert.ErrorContains(t, err, "available: aider, claude, goose, llm")
+
+	_, err = Resolve(&config.AgentsConfig{Preset: "llm", Input: "pipe"})
+	assert.Error(t, err)
+}
+
+func TestPrepare(t *testing.T) {
+	prepare := func(t *testing.T, cfg *config.AgentsConfig, plain bool) *Invocation {
+		t.Helper()
+		a, err := Resolve(cfg)
+		require.NoError(t, err)
+		inv, err := a.Prepare("Review this", plain)
+		require.NoError(t, err)
+		t.Cleanup(inv.Cleanup)
+		return inv
+	}
+
+	t.Run("claude reads stdin", func(t *testing.T) {
+		inv := prepare(t, &config.AgentsConfig{Preset: "claude", Model: "sonnet"}, false)
+		assert.Equal(t, "claude", inv.Command)
+		assert.Equal(t, []string{"-p", "--model", "sonnet"}, inv.Args)
+		assert.Equal(t, "Review this", inv.Stdin)
+		assert.Equal(t, "claude -p --model sonnet < prompt", inv.String())
+	})
+
+	t.Run("aider gets a message file", func(t *testing.T) {
+		inv := prepare(t, &config.AgentsConfig{Preset: "aider", Args: []string{"--no-auto-commits"}}, true)
+		require.NotEmpty(t, inv.PromptFile)
+		assert.Equal(t, []string{"--message-file", inv.PromptFile, "--no-auto-commits", "--no-pretty"}, inv.Args)
+		assert.Empty(t, inv.Stdin)
+
+		content, err := os.ReadFile(inv.PromptFile)
+		require.NoError(t, err)
+		assert.Equal(t, "Review this", string(content))
+
+		inv.Cleanup()
+		assert.NoFileExists(t, inv.PromptFile)
+	})
+
+	t.Run("goose takes the model from the environment", func(t *testing.T) {
+		inv := prepare(t, &config.AgentsConfig{Preset: "goose", Model: "gpt-4o"}, false)
+		assert.Equal(t, []string{"GOOSE_MODEL=gpt-4o"}, inv.Env)
+		assert.Equal(t, []string{"run", "--instructions", inv.PromptFile}, inv.Args)
+	})
+
+	t.Run("custom agent with prompt placeholder", func(t *testing.T) {
+		inv := prepare(t, &config.AgentsConfig{Command: "ask", Args: []string{"--question={{prompt}}"}}, false)
+		assert.Equal(t, []string{"--question=Review this"}, inv.Args)
+		assert.Empty(t, inv.Stdin)
+	})
+
+	t.Run("custom agent with arg input", func(t *testing.T) {
+		inv := prepare(t, &config.AgentsConfig{Command: "ask", Input: "arg"}, false)
+		assert.Equal(t, []string{"Review this"}, inv.Args)
+	})
+}
+
+// TestPresetsMatchConfigSchema keeps the schema's preset list in sync
+func TestPresetsMatchConfigSchema(t *testing.T) {
+	validator, err := config.NewValidator()
+	require.NoError(t, err)
+
+	for _, name := range PresetNames() {
+		content := "version: \"1.0\"\nlibrary:\n  path: .ddx/library\nagents:\n  preset: " + name + "\n"
+		assert.NoError(t, validator.Validate([]byte(content)), name)
+	}
+	assert.Error(t, validator.Validate([]byte("version: \"1.0\"\nagents:\n  preset: cursor\n")))
+}
@@ -0,0 +1,130 @@
+// Package agent invokes AI coding agents such as the Claude CLI, aider, llm
+// and goose with a rendered prompt.
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// InputMode is how a prompt is handed to an agent
+type InputMode string
+
+const (
+	// InputArg passes the prompt as a command-line argument
+	InputArg InputMode = "arg"
+	// InputStdin writes the prompt to the agent's standard input
+	InputStdin InputMode = "stdin"
+	// InputFile writes the prompt to a temporary file passed as an argument
+	InputFile InputMode = "file"
+)
+
+// ParseInputMode validates an input mode name
+func ParseInputMode(value string) (InputMode, error) {
+	switch mode := InputMode(strings.ToLower(value)); mode {
+	case InputArg, InputStdin, InputFile:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown agent input %q (valid: arg, stdin, file)", value)
+}
+
+// Placeholders substituted in preset arguments
+const (
+	PromptPlaceholder     = "{{prompt}}"
+	PromptFilePlaceholder = "{{prompt_file}}"
+)
+
+// Preset describes how to invoke an agent tool
+type Preset struct {
+	Name        string
+	Description string
+	// Command is the executable
+	Command string
+	// Args are passed before any configured arguments and may contain the
+	// {{prompt}} or {{prompt_file}} placeholders
+	Args  []string
+	Input InputMode
+	// ModelFlag selects the model, e.g. --model; empty when the tool takes
+	// the model from ModelEnv instead
+	ModelFlag string
+	// ModelEnv is the environment variable the tool reads its model from
+	ModelEnv string
+	// PlainArgs are added when output is not a terminal, for tools that
+	// otherwise emit colors or spinners
+	PlainArgs []string
+	// Notes explain quirks of the tool's invocation
+	Notes string
+}
+
+// presets are the built-in agent presets
+var presets = map[string]Preset{
+	"claude": {
+		Name:        "claude",
+		Description: "Claude Code CLI in print mode",
+		Command:     "claude",
+		Args:        []string{"-p"},
+		Input:       InputStdin,
+		ModelFlag:   "--model",
+		Notes:       "Print mode reads the prompt from stdin, so long prompts are not limited by argument length.",
+	},
+	"aider": {
+		Name:        "aider",
+		Description: "aider pair programmer, one message per run",
+		Command:     "aider",
+		Args:        []string{"--message-file", PromptFilePlaceholder},
+		Input:       InputFile,
+		ModelFlag:   "--model",
+		PlainArgs:   []string{"--no-pretty"},
+		Notes:       "aider does not read prompts from stdin; the prompt goes through --message-file and stdin stays attached for confirmations.",
+	},
+	"llm": {
+		Name:        "llm",
+		Description: "Simon Willison's llm CLI",
+		Command:     "llm",
+		Args:        []string{"prompt"},
+		Input:       InputStdin,
+		ModelFlag:   "-m",
+		Notes:       "With no prompt argument llm uses stdin as the prompt.",
+	},
+	"goose": {
+		Name:        "goose",
+		Description: "Block's goose agent, headless run",
+		Command:     "goose",
+		Args:        []string{"run", "--instructions", PromptFilePlaceholder},
+		Input:       InputFile,
+		ModelEnv:    "GOOSE_MODEL",
+		Notes:       "goose run takes instructions from a file and its model from GOOSE_MODEL.",
+	},
+}
+
+// LookupPreset returns a built-in preset by name
+func LookupPreset(name string) (Preset, error) {
+	preset, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown agent preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	preset.Args = append([]string(nil), preset.Args...)
+	preset.PlainArgs = append([]string(nil), preset.PlainArgs...)
+	return preset, nil
+}
+
+// PresetNames returns the built-in preset names in sorted order
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Presets returns the built-in presets sorted by name
+func Presets() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, name := range PresetNames() {
+		preset, _ := LookupPreset(name)
+		list = append(list, preset)
+	}
+	return list
+}
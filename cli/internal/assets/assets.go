@@ -0,0 +1,323 @@
+// Package assets keeps large binary library files out of git. The library
+// holds small pointer files naming each asset's SHA-256 and download URL;
+// the content is fetched on demand into a content-addressed cache and
+// verified against the hash.
+package assets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/network"
+	"github.com/easel/ddx/internal/userdirs"
+	"gopkg.in/yaml.v3"
+)
+
+// PointerSuffix marks a pointer file; the asset is named by the pointer's
+// library path without it
+const PointerSuffix = ".ddx-asset"
+
+// hashPattern matches a lowercase hex SHA-256
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Pointer is a content-addressed reference to an asset
+type Pointer struct {
+	// Name is the asset's slash-separated path in the library
+	Name   string `yaml:"-" json:"name"`
+	SHA256 string `yaml:"sha256" json:"sha256"`
+	URL    string `yaml:"url" json:"url"`
+	// Size in bytes, checked before the hash when set
+	Size int64 `yaml:"size,omitempty" json:"size,omitempty"`
+	// Path is the pointer file
+	Path string `yaml:"-" json:"-"`
+}
+
+// IsPointer reports whether path names a pointer file
+func IsPointer(path string) bool {
+	return strings.HasSuffix(path, PointerSuffix)
+}
+
+// Validate checks that the pointer can be fetched and verified
+func (p *Pointer) Validate() error {
+	if !hashPattern.MatchString(p.SHA256) {
+		return fmt.Errorf("asset %s: sha256 must be 64 lowercase hex characters", p.Name)
+	}
+	if p.URL == "" {
+		return fmt.Errorf("asset %s: url is required", p.Name)
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "file") {
+		return fmt.Errorf("asset %s: url must be an http, https or file URL", p.Name)
+	}
+	if p.Size < 0 {
+		return fmt.Errorf("asset %s: size cannot be negative", p.Name)
+	}
+	return nil
+}
+
+// LoadPointer reads the pointer file at path, naming the asset name
+func LoadPointer(path, name string) (*Pointer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset pointer %s: %w", path, err)
+	}
+	p := &Pointer{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse asset pointer %s: %w", path, err)
+	}
+	p.Name, p.Path = name, path
+	p.SHA256 = strings.ToLower(p.SHA256)
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Find returns the pointers under root, sorted by name
+func Find(root string) ([]*Pointer, error) {
+	var pointers []*Pointer
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsPointer(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		p, err := LoadPointer(path, strings.TrimSuffix(filepath.ToSlash(rel), PointerSuffix))
+		if err != nil {
+			return err
+		}
+		pointers = append(pointers, p)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sort.Slice(pointers, func(i, j int) bool { return pointers[i].Name < pointers[j].Name })
+	return pointers, nil
+}
+
+// Lookup returns the pointer for an asset name under root. The name may
+// include the pointer suffix.
+func Lookup(root, name string) (*Pointer, error) {
+	name = strings.TrimSuffix(filepath.ToSlash(name), PointerSuffix)
+	path := filepath.Join(root, filepath.FromSlash(name)+PointerSuffix)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("asset not found: %s", name)
+	}
+	return LoadPointer(path, name)
+}
+
+// DefaultDir returns the asset cache directory, in the ddx cache directory
+func DefaultDir() (string, error) {
+	cacheDir, err := userdirs.Dir(userdirs.Cache)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "assets"), nil
+}
+
+// Store is a content-addressed cache of assets
+type Store struct {
+	Dir string
+	// Client downloads http and https assets; nil uses the network
+	// configuration
+	Client *http.Client
+}
+
+// NewStore returns a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// DefaultStore returns a store in the default cache directory
+func DefaultStore() (*Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(dir), nil
+}
+
+// Path returns where content with the given hash is cached
+func (s *Store) Path(hash string) string {
+	return filepath.Join(s.Dir, "sha256", hash[:2], hash)
+}
+
+// Has reports whether the asset is cached, without verifying its content
+func (s *Store) Has(p *Pointer) bool {
+	_, err := os.Stat(s.Path(p.SHA256))
+	return err == nil
+}
+
+// Verify re-hashes the cached copy of an asset. A copy that does not match
+// is removed so the next fetch downloads it again.
+func (s *Store) Verify(p *Pointer) error {
+	path := s.Path(p.SHA256)
+	hash, _, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if hash != p.SHA256 {
+		_ = os.Remove(path)
+		return fmt.Errorf("asset %s: cached copy is corrupt (sha256 %s) and was removed", p.Name, hash)
+	}
+	return nil
+}
+
+// Fetch makes sure the asset is cached, downloading and verifying it when
+// it is not. It returns the cached path and whether it was downloaded.
+func (s *Store) Fetch(p *Pointer) (string, bool, error) {
+	path := s.Path(p.SHA256)
+	if s.Has(p) {
+		return path, false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create asset cache: %w", err)
+	}
+	tmp, err := s.download(context.Background(), p, filepath.Dir(path))
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+	if err := os.Rename(tmp, path); err != nil {
+		return "", false, fmt.Errorf("failed to store asset %s: %w", p.Name, err)
+	}
+	return path, true, nil
+}
+
+// download fetches an asset into a temporary file in dir and verifies its
+// size and hash, returning the file's path
+func (s *Store) download(ctx context.Context, p *Pointer, dir string) (string, error) {
+	body, err := s.open(ctx, p.URL)
+	if err != nil {
+		return "", fmt.Errorf("asset %s: %w", p.Name, err)
+	}
+	defer func() { _ = body.Close() }()
+
+	tmp, err := os.CreateTemp(dir, p.SHA256+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create asset cache file: %w", err)
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	var reader io.Reader = body
+	if p.Size > 0 {
+		// Read one byte past the expected size to detect oversized content
+		reader = io.LimitReader(body, p.Size+1)
+	}
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hasher), reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("asset %s: download failed: %w", p.Name, err)
+	}
+
+	if p.Size > 0 && written != p.Size {
+		return "", fmt.Errorf("asset %s: expected %d bytes, got %d", p.Name, p.Size, written)
+	}
+	if hash := hex.EncodeToString(hasher.Sum(nil)); hash != p.SHA256 {
+		return "", fmt.Errorf("asset %s: integrity check failed: expected sha256 %s, got %s", p.Name, p.SHA256, hash)
+	}
+	keep = true
+	return tmp.Name(), nil
+}
+
+// CopyTo fetches the asset and copies it to dest with the given mode
+func (s *Store) CopyTo(p *Pointer, dest string, mode os.FileMode) (bool, error) {
+	path, fetched, err := s.Fetch(p)
+	if err != nil {
+		return false, err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return fetched, err
+	}
+	defer func() { _ = src.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fetched, fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fetched, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		_ = out.Close()
+		return fetched, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return fetched, out.Close()
+}
+
+// open starts reading the content at rawURL
+func (s *Store) open(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "file" {
+		return os.Open(filepath.FromSlash(u.Path))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = network.Client()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download failed: %s returned %s", rawURL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// hashFile hashes a file's content
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -0,0 +1,209 @@
+package assets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sum returns the hex SHA-256 of content
+func sum(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+// writePointer writes a pointer file for name under root
+func writePointer(t *testing.T, root, name, hash, url string, size int) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name)+PointerSuffix)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	content := fmt.Sprintf("sha256: %s\nurl: %s\nsize: %d\n", hash, url, size)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFindAndLookup(t *testing.T) {
+	root := t.TempDir()
+	writePointer(t, root, "templates/brand/logo.psd", sum("logo"), "https://example.com/logo.psd", 4)
+	writePointer(t, root, "assets/font.ttf", sum("font"), "https://example.com/font.ttf", 4)
+	writePointer(t, root, ".git/ignored.bin", sum("x"), "https://example.com/x", 1)
+
+	pointers, err := Find(root)
+	require.NoError(t, err)
+	require.Len(t, pointers, 2)
+	assert.Equal(t, "assets/font.ttf", pointers[0].Name)
+	assert.Equal(t, "templates/brand/logo.psd", pointers[1].Name)
+
+	p, err := Lookup(root, "templates/brand/logo.psd.ddx-asset")
+	require.NoError(t, err)
+	assert.Equal(t, sum("logo"), p.SHA256)
+	assert.Equal(t, int64(4), p.Size)
+
+	_, err = Lookup(root, "missing.bin")
+	assert.EqualError(t, err, "asset not found: missing.bin")
+
+	writePointer(t, root, "bad.bin", "abc", "ftp://example.com/bad", 0)
+	_, err = Lookup(root, "bad.bin")
+	assert.ErrorContains(t, err, "sha256 must be 64 lowercase hex characters")
+}
+
+func TestStoreFetch(t *testing.T) {
+	content := "binary asset content"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	store := NewStore(t.TempDir())
+
+	t.Run("downloads once and verifies", func(t *testing.T) {
+		p := &Pointer{Name: "a.bin", SHA256: sum(content), URL: server.URL, Size: int64(len(content))}
+		path, downloaded, err := store.Fetch(p)
+		require.NoError(t, err)
+		assert.True(t, downloaded)
+		assert.Equal(t, store.Path(p.SHA256), path)
+
+		_, downloaded, err = store.Fetch(p)
+		require.NoError(t, err)
+		assert.False(t, downloaded)
+		assert.Equal(t, 1, requests)
+		assert.NoError(t, store.Verify(p))
+
+		dest := filepath.Join(t.TempDir(), "out", "a.bin")
+		_, err = store.CopyTo(p, dest, 0644)
+		require.NoError(t, err)
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("rejects wrong hash", func(t *testing.T) {
+		p := &Pointer{Name: "b.bin", SHA256: sum("other"), URL: server.URL}
+		_, _, err := store.Fetch(p)
+		assert.ErrorContains(t, err, "asset b.bin: integrity check failed: expected sha256 "+sum("other"))
+		assert.False(t, store.Has(p), "unverified content must not be cached")
+	})
+
+	t.Run("rejects wrong size", func(t *testing.T) {
+		p := &Pointer{Name: "c.bin", SHA256: sum(content), URL: server.URL, Size: 4}
+		store := NewStore(t.TempDir())
+		_, _, err := store.Fetch(p)
+		assert.EqualError(t, err, "asset c.bin: expected 4 bytes, got 5")
+	})
+
+	t.Run("file URL", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "local.bin")
+		require.NoError(t, os.WriteFile(src, []byte("local"), 0644))
+		p := &Pointer{Name: "local.bin", SHA256: sum("local"), URL: "file://" + filepath.ToSlash(src)}
+		_, downloaded, err := NewStore(t.TempDir()).Fetch(p)
+		require.NoError(t, err)
+		assert.True(t, downloaded)
+	})
+
+	t.Run("verify removes corrupt copy", func(t *testing.T) {
+		p := &Pointer{Name: "a.bin", SHA256: sum(content), URL: server.URL}
+		require.NoError(t, os.WriteFile(store.Path(p.SHA256), []byte("tampered"), 0644))
+		assert.ErrorContains(t, store.Verify(p), "cached copy is corrupt")
+		assert.False(t, store.Has(p))
+	})
+}
+
+func TestStoreFetchAll(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	pointer := func(name string) *Pointer {
+		return &Pointer{Name: name, SHA256: sum("/" + name), URL: server.URL + "/" + name}
+	}
+
+	t.Run("bounded parallel downloads", func(t *testing.T) {
+		store := NewStore(t.TempDir())
+		pointers := []*Pointer{pointer("a"), pointer("b"), pointer("c"), pointer("d"), pointer("e")}
+		// A second pointer to the same content is downloaded once
+		pointers = append(pointers, &Pointer{Name: "a-copy", SHA256: pointers[0].SHA256, URL: pointers[0].URL})
+		require.NoError(t, os.MkdirAll(filepath.Dir(store.Path(pointers[1].SHA256)), 0755))
+		require.NoError(t, os.WriteFile(store.Path(pointers[1].SHA256), []byte("/b"), 0644))
+
+		var progress []Progress
+		results, err := store.FetchAll(context.Background(), pointers, FetchOptions{
+			Workers:  2,
+			Progress: func(p Progress) { progress = append(progress, p) },
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 6)
+		downloaded := 0
+		for _, result := range results {
+			assert.FileExists(t, result.Path)
+			if result.Downloaded {
+				downloaded++
+			}
+		}
+		assert.Equal(t, 4, downloaded, "cached and duplicate assets are not downloaded")
+		assert.False(t, results[1].Downloaded)
+		assert.LessOrEqual(t, maxInFlight, 2)
+		require.Len(t, progress, 6)
+		assert.Equal(t, 6, progress[5].Done)
+		assert.Equal(t, 6, progress[5].Total)
+	})
+
+	t.Run("a failure stores nothing", func(t *testing.T) {
+		store := NewStore(t.TempDir())
+		good := pointer("good")
+		bad := &Pointer{Name: "bad", SHA256: sum("x"), URL: server.URL + "/missing"}
+		_, err := store.FetchAll(context.Background(), []*Pointer{good, bad}, FetchOptions{Workers: 1})
+		assert.ErrorContains(t, err, "asset bad: download failed")
+		assert.False(t, store.Has(good), "downloads are only committed when all succeed")
+		entries, err := os.ReadDir(store.Dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "staging is cleaned up")
+	})
+
+	t.Run("every failure is reported", func(t *testing.T) {
+		// Both requests are in flight before either fails
+		var arrived sync.WaitGroup
+		arrived.Add(2)
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived.Done()
+			arrived.Wait()
+			http.NotFound(w, r)
+		}))
+		defer failing.Close()
+
+		store := NewStore(t.TempDir())
+		first := &Pointer{Name: "first", SHA256: sum("1"), URL: failing.URL + "/first"}
+		second := &Pointer{Name: "second", SHA256: sum("2"), URL: failing.URL + "/second"}
+		_, err := store.FetchAll(context.Background(), []*Pointer{first, second}, FetchOptions{Workers: 2})
+		assert.ErrorContains(t, err, "asset first: download failed")
+		assert.ErrorContains(t, err, "asset second: download failed")
+	})
+}
@@ -0,0 +1,153 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultWorkers bounds concurrent downloads when FetchOptions.Workers is
+// not set
+const DefaultWorkers = 4
+
+// Progress describes one asset finishing during FetchAll
+type Progress struct {
+	Asset *Pointer
+	// Downloaded is false when the asset was already cached
+	Downloaded bool
+	Err        error
+	// Done counts finished assets out of Total
+	Done  int
+	Total int
+}
+
+// FetchOptions configure FetchAll
+type FetchOptions struct {
+	// Workers bounds concurrent downloads; zero uses DefaultWorkers
+	Workers int
+	// Progress is called as each asset finishes. Calls are serialized.
+	Progress func(Progress)
+}
+
+// FetchResult is the outcome for one asset of FetchAll
+type FetchResult struct {
+	Asset      *Pointer
+	Path       string
+	Downloaded bool
+}
+
+// FetchAll makes sure every asset is cached, downloading missing ones in
+// parallel. Downloads are staged and only moved into the cache once all of
+// them have been verified, so a failure leaves the cache as it was. The
+// first failure cancels downloads that have not finished; every download
+// that failed for another reason is reported in the returned error.
+// Results are in the order of pointers.
+func (s *Store) FetchAll(ctx context.Context, pointers []*Pointer, opts FetchOptions) ([]FetchResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	// Group pointers by hash so shared content is downloaded once
+	results := make([]FetchResult, len(pointers))
+	pending := make(map[string][]int)
+	var missing []*Pointer
+	for i, p := range pointers {
+		results[i] = FetchResult{Asset: p, Path: s.Path(p.SHA256)}
+		if _, ok := pending[p.SHA256]; !ok && !s.Has(p) {
+			missing = append(missing, p)
+			pending[p.SHA256] = nil
+		}
+		if _, ok := pending[p.SHA256]; ok {
+			pending[p.SHA256] = append(pending[p.SHA256], i)
+		}
+	}
+
+	var mu sync.Mutex
+	done := 0
+	// report must be called with mu held
+	report := func(i int, err error) {
+		done++
+		if opts.Progress != nil {
+			opts.Progress(Progress{Asset: pointers[i], Downloaded: results[i].Downloaded, Err: err, Done: done, Total: len(pointers)})
+		}
+	}
+	for i, p := range pointers {
+		if _, ok := pending[p.SHA256]; !ok {
+			report(i, nil)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create asset cache: %w", err)
+	}
+	staging, err := os.MkdirTemp(s.Dir, "staging-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	staged := make(map[string]string, len(missing))
+	var errs []error
+	jobs := make(chan *Pointer)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(missing); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				tmp, err := s.download(ctx, p, staging)
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					staged[p.SHA256] = tmp
+					for n, i := range pending[p.SHA256] {
+						results[i].Downloaded = n == 0
+						report(i, nil)
+					}
+				case !errors.Is(err, context.Canceled):
+					// Downloads interrupted by the cancellation are not failures
+					errs = append(errs, err)
+					cancel()
+					report(pending[p.SHA256][0], err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range missing {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, p := range missing {
+		path := s.Path(p.SHA256)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create asset cache: %w", err)
+		}
+		if err := os.Rename(staged[p.SHA256], path); err != nil {
+			return nil, fmt.Errorf("failed to store asset %s: %w", p.Name, err)
+		}
+	}
+	return results, nil
+}
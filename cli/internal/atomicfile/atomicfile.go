@@ -0,0 +1,106 @@
+// Package atomicfile writes files so that readers, and the file left behind
+// after a crash, see either the old content or the new content and never a
+// truncated mix of the two.
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// tempPattern names the temporary file a write goes to before it replaces
+// the target; it lives beside the target so the rename stays on one
+// filesystem
+const tempPattern = ".%s.*.tmp"
+
+// WriteFile writes data to path the way os.WriteFile does, but through a
+// temporary file that is synced and renamed over path. An existing file
+// keeps its permissions; perm applies to new files.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// CopyFile atomically copies src to dst, creating dst's directory. A new
+// dst gets the permissions of src.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return Write(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+// Write atomically replaces path with the content fn writes. If fn fails
+// the temporary file is removed and path is left untouched.
+func Write(path string, perm os.FileMode, fn func(w io.Writer) error) (err error) {
+	// Write through symlinks, such as a CLAUDE.md linked from elsewhere,
+	// rather than replacing the link with a regular file
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, fmt.Sprintf(tempPattern, filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = fn(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry created by the rename. It is best
+// effort: the content is already safe, and some platforms cannot sync
+// directories at all.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
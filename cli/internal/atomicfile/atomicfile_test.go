@@ -0,0 +1,144 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CLAUDE.md")
+
+	require.NoError(t, WriteFile(path, []byte("first\n"), 0644))
+	require.NoError(t, WriteFile(path, []byte("second\n"), 0644))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(data))
+	assertNoTempFiles(t, dir)
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "script.sh")
+	require.NoError(t, os.WriteFile(src, []byte("#!/bin/sh\n"), 0755))
+
+	dst := filepath.Join(dir, "nested", "copy.sh")
+	require.NoError(t, CopyFile(src, dst))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(data))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(dst))
+
+	assert.Error(t, CopyFile(filepath.Join(dir, "missing"), dst))
+}
+
+func TestWriteFile_KeepsExistingMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0755))
+
+	require.NoError(t, WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0644))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestWriteFile_FollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared.md")
+	link := filepath.Join(dir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(target, []byte("old\n"), 0644))
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	require.NoError(t, WriteFile(link, []byte("new\n"), 0644))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "link should be kept")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+}
+
+// TestWrite_Interrupted simulates a write that fails partway through: the
+// original file must be untouched and no temporary file left behind
+func TestWrite_Interrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: \"1.0\"\n"), 0644))
+
+	failure := errors.New("disk full")
+	err := Write(path, 0644, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "vers")
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"1.0\"\n", string(data))
+	assertNoTempFiles(t, dir)
+}
+
+// TestWrite_InterruptedNewFile checks that a failed first write does not
+// leave an empty or partial file behind
+func TestWrite_InterruptedNewFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.yml")
+
+	err := Write(path, 0644, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "phase:")
+		return errors.New("interrupted")
+	})
+	assert.Error(t, err)
+	assert.NoFileExists(t, path)
+	assertNoTempFiles(t, dir)
+}
+
+// TestWriteFile_AfterCrash checks that the temporary file of a process that
+// died mid-write neither replaces the target nor blocks the next write
+func TestWriteFile_AfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(path, []byte("intact\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".CLAUDE.md.123.tmp"), []byte("trunc"), 0644))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "intact\n", string(data))
+
+	require.NoError(t, WriteFile(path, []byte("updated\n"), 0644))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "updated\n", string(data))
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "file.txt")
+	assert.Error(t, WriteFile(path, []byte("x"), 0644))
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// Audited actions
+const (
+	AuditAuthenticate      = "authenticate"
+	AuditValidate          = "validate"
+	AuditCredentialAccess  = "credential_access"
+	AuditCredentialStore   = "credential_store"
+	AuditCredentialDelete  = "credential_delete"
+	AuditCredentialRefresh = "credential_refresh"
+	AuditCredentialExport  = "credential_export"
+	AuditCredentialImport  = "credential_import"
+)
+
+// Outcomes of an audited action
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	// AuditNotFound is a lookup of credentials that are not stored
+	AuditNotFound = "not_found"
+	// AuditDenied is an attempt refused by rate limiting
+	AuditDenied = "denied"
+)
+
+// Failed authentication attempts are rate limited: after MaxFailedAttempts
+// failures within FailureWindow, with no success since, further attempts
+// for the same platform and repository are refused until the window has
+// passed since the last failure
+const (
+	MaxFailedAttempts = 5
+	FailureWindow     = 15 * time.Minute
+)
+
+// maxAuditLogSize is the size at which the audit log is rotated to
+// <path>.1
+const maxAuditLogSize = 5 * 1024 * 1024
+
+// AuditEvent is one entry of the audit log. Tokens are never recorded.
+type AuditEvent struct {
+	Time       time.Time  `json:"time"`
+	Action     string     `json:"action"`
+	Platform   Platform   `json:"platform,omitempty"`
+	Repository string     `json:"repository,omitempty"`
+	Method     AuthMethod `json:"method,omitempty"`
+	Outcome    string     `json:"outcome"`
+	Code       string     `json:"code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	User       string     `json:"user,omitempty"`
+}
+
+// AuditLog is an append-only JSON lines log of authentication events,
+// kept apart from the project command history because it is per user and
+// may reveal which hosts a user holds credentials for
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewAuditLog returns an audit log writing to path
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path, now: time.Now}
+}
+
+// DefaultAuditLogPath returns the user's audit log location
+func DefaultAuditLogPath() (string, error) {
+	return userdirs.Path(userdirs.Data, "auth-audit.jsonl")
+}
+
+// Path returns where the log is written
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Record appends an event, filling in its time and user
+func (l *AuditLog) Record(event AuditEvent) error {
+	if l == nil {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = l.now().UTC()
+	}
+	if event.User == "" {
+		event.User = os.Getenv("USER")
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(l.path), err)
+	}
+	if info, err := os.Stat(l.path); err == nil && info.Size() > maxAuditLogSize {
+		_ = os.Rename(l.path, l.path+".1")
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// Read returns the most recent events, oldest first. A limit of zero or
+// less returns every event; lines that do not decode are skipped.
+func (l *AuditLog) Read(limit int) ([]AuditEvent, error) {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
+
+// CheckRateLimit refuses an authentication attempt for a platform and
+// repository after MaxFailedAttempts recent failed attempts
+func (l *AuditLog) CheckRateLimit(platform Platform, repository string) error {
+	if l == nil {
+		return nil
+	}
+	events, err := l.Read(0)
+	if err != nil {
+		// An unreadable log must not lock users out
+		return nil
+	}
+
+	now := l.now()
+	failures := 0
+	var last time.Time
+	for _, event := range events {
+		if event.Platform != platform || event.Repository != repository {
+			continue
+		}
+		if event.Action != AuditAuthenticate {
+			continue
+		}
+		switch event.Outcome {
+		case AuditSuccess:
+			failures = 0
+		case AuditFailure:
+			if now.Sub(event.Time) <= FailureWindow {
+				failures++
+				last = event.Time
+			}
+		}
+	}
+	if failures < MaxFailedAttempts {
+		return nil
+	}
+	wait := FailureWindow - now.Sub(last)
+	return &AuthError{
+		Type:    ErrorTypeRateLimited,
+		Message: fmt.Sprintf("Too many failed authentication attempts for %s/%s", platform, repository),
+		Code:    "AUTH_RATE_LIMITED",
+		Hint:    fmt.Sprintf("Try again in %s; see 'ddx auth audit --failures'", wait.Round(time.Second)),
+	}
+}
+
+// auditEvent builds an event for an action and its error, if any
+func auditEvent(action string, platform Platform, repository string, err error) AuditEvent {
+	event := AuditEvent{Action: action, Platform: platform, Repository: repository, Outcome: AuditSuccess}
+	if err == nil {
+		return event
+	}
+	event.Outcome = AuditFailure
+	event.Error = err.Error()
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		event.Code = authErr.Code
+		event.Error = authErr.Message
+		switch authErr.Code {
+		case "AUTH_CREDENTIALS_NOT_FOUND":
+			event.Outcome = AuditNotFound
+		case "AUTH_RATE_LIMITED":
+			event.Outcome = AuditDenied
+		}
+	}
+	return event
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingAuthenticator rejects every authentication attempt
+type failingAuthenticator struct {
+	GitHubAuthenticator
+	attempts int
+}
+
+func (a *failingAuthenticator) Authenticate(ctx context.Context, req *AuthRequest) (*AuthResult, error) {
+	a.attempts++
+	return nil, &AuthError{Type: ErrorTypeInvalidCredentials, Message: "bad credentials", Code: "TEST_BAD_CREDENTIALS"}
+}
+
+func TestAuditLogRecordsEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := NewAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	manager := NewDefaultManager()
+	manager.RegisterStore(NewFileStore(filepath.Join(t.TempDir(), "auth"), "test-pass"))
+	manager.SetAuditLog(log)
+
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Token: "ghp_secret"}))
+	_, err := manager.GetCredential(ctx, PlatformGitHub, "github.com")
+	require.NoError(t, err)
+	_, err = manager.GetCredential(ctx, PlatformGitHub, "gitlab.com")
+	require.Error(t, err)
+
+	events, err := log.Read(0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, AuditCredentialStore, events[0].Action)
+	assert.Equal(t, AuthMethodToken, events[0].Method)
+	assert.Equal(t, AuditCredentialAccess, events[1].Action)
+	assert.Equal(t, AuditSuccess, events[1].Outcome)
+	assert.Equal(t, AuditNotFound, events[2].Outcome)
+	assert.False(t, events[0].Time.IsZero())
+
+	data, err := os.ReadFile(log.Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ghp_secret")
+	info, err := os.Stat(log.Path())
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	last, err := log.Read(1)
+	require.NoError(t, err)
+	assert.Equal(t, events[2:], last)
+}
+
+func TestAuditLogRateLimitsFailures(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	log := NewAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	log.now = func() time.Time { return now }
+	authenticator := &failingAuthenticator{}
+	manager := NewDefaultManager()
+	manager.RegisterAuthenticator(authenticator)
+	manager.SetAuditLog(log)
+	req := &AuthRequest{Platform: PlatformGitHub, Repository: "github.com"}
+
+	for i := 0; i < MaxFailedAttempts; i++ {
+		_, err := manager.Authenticate(ctx, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bad credentials")
+	}
+
+	_, err := manager.Authenticate(ctx, req)
+	var authErr *AuthError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, ErrorTypeRateLimited, authErr.Type)
+	assert.True(t, strings.HasPrefix(authErr.Hint, "Try again in 15m0s"))
+	assert.Equal(t, MaxFailedAttempts, authenticator.attempts)
+
+	// Other repositories are not affected
+	_, err = manager.Authenticate(ctx, &AuthRequest{Platform: PlatformGitHub, Repository: "ghe.example.com"})
+	require.ErrorAs(t, err, &authErr)
+	assert.NotEqual(t, ErrorTypeRateLimited, authErr.Type)
+
+	// Attempts are allowed again once the failures have aged out
+	now = now.Add(FailureWindow + time.Second)
+	_, err = manager.Authenticate(ctx, req)
+	require.ErrorAs(t, err, &authErr)
+	assert.NotEqual(t, ErrorTypeRateLimited, authErr.Type)
+
+	events, err := log.Read(0)
+	require.NoError(t, err)
+	denied := 0
+	for _, event := range events {
+		if event.Outcome == AuditDenied {
+			denied++
+		}
+	}
+	assert.Equal(t, 1, denied)
+}
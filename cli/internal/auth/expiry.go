@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Credential metadata keys
+const (
+	// MetadataExpiresAt holds an RFC 3339 expiry for credentials stored
+	// without ExpiresAt
+	MetadataExpiresAt = "expires_at"
+	// MetadataRefreshToken holds the token RefreshToken exchanges for a
+	// new credential, for platforms that issue one
+	MetadataRefreshToken = "refresh_token"
+)
+
+// RefreshWindow is how long before expiry GetCredential refreshes a
+// credential on its own
+const RefreshWindow = 5 * time.Minute
+
+// ExpiryWarning is how long before expiry commands warn that a credential
+// may expire while they run
+const ExpiryWarning = 24 * time.Hour
+
+// Expiry returns when the credential expires, from ExpiresAt or the
+// expires_at metadata. Credentials without an expiry never expire.
+func (c *Credential) Expiry() (time.Time, bool) {
+	if c.ExpiresAt != nil {
+		return *c.ExpiresAt, true
+	}
+	if value, ok := c.Metadata[MetadataExpiresAt]; ok {
+		if expiry, err := time.Parse(time.RFC3339, value); err == nil {
+			return expiry, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ExpiresWithin reports whether the credential expires, or has expired,
+// within d of now
+func (c *Credential) ExpiresWithin(now time.Time, d time.Duration) bool {
+	expiry, ok := c.Expiry()
+	return ok && expiry.Sub(now) <= d
+}
+
+// expiredError reports a credential that expired and could not be
+// refreshed
+func expiredError(cred *Credential, expiry time.Time) *AuthError {
+	return &AuthError{
+		Type:    ErrorTypeExpiredToken,
+		Message: fmt.Sprintf("Credentials for %s/%s expired at %s", cred.Platform, cred.ID, expiry.Format(time.RFC3339)),
+		Code:    "AUTH_CREDENTIALS_EXPIRED",
+		Hint:    fmt.Sprintf("Run 'ddx auth refresh %s' or 'ddx auth login %s'", cred.ID, cred.ID),
+	}
+}
+
+// checkExpiry refreshes a credential that expires within RefreshWindow
+// when its platform supports refreshing. An expired credential that could
+// not be refreshed is an error; one that is only about to expire is
+// returned as is.
+func (m *DefaultManager) checkExpiry(ctx context.Context, cred *Credential) (*Credential, error) {
+	if !cred.ExpiresWithin(m.clock(), RefreshWindow) {
+		return cred, nil
+	}
+	refreshed, err := m.refresh(ctx, cred)
+	if err == nil {
+		slog.Debug("refreshed credentials", "platform", cred.Platform, "id", cred.ID)
+		return refreshed, nil
+	}
+	if expiry, _ := cred.Expiry(); !expiry.After(m.clock()) {
+		return nil, expiredError(cred, expiry)
+	}
+	return cred, nil
+}
+
+// refresh exchanges a credential for a new one through its platform's
+// authenticator, without prompting, and stores the result
+func (m *DefaultManager) refresh(ctx context.Context, cred *Credential) (*Credential, error) {
+	m.mu.RLock()
+	authenticator, exists := m.authenticators[cred.Platform]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, &AuthError{
+			Type:    ErrorTypeNotFound,
+			Message: fmt.Sprintf("No authenticator available for platform: %s", cred.Platform),
+			Code:    "AUTH_PLATFORM_NOT_SUPPORTED",
+		}
+	}
+
+	token := cred.Token
+	if refreshToken := cred.Metadata[MetadataRefreshToken]; refreshToken != "" {
+		token = refreshToken
+	}
+	refreshed, err := authenticator.RefreshToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticators return the new token; the identity stays the same
+	if refreshed.ID == "" {
+		refreshed.ID = cred.ID
+	}
+	if refreshed.Platform == "" {
+		refreshed.Platform = cred.Platform
+	}
+	if refreshed.Method == "" {
+		refreshed.Method = cred.Method
+	}
+	if refreshed.Username == "" {
+		refreshed.Username = cred.Username
+	}
+	if len(refreshed.Scopes) == 0 {
+		refreshed.Scopes = cred.Scopes
+	}
+	refreshed.CreatedAt = cred.CreatedAt
+	if err := m.StoreCredential(ctx, refreshed); err != nil {
+		return nil, err
+	}
+	return refreshed, nil
+}
+
+// clock returns the current time
+func (m *DefaultManager) clock() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
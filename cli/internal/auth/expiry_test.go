@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshingAuthenticator issues a new token for a refresh token, or
+// refuses when refreshToken is empty
+type refreshingAuthenticator struct {
+	GitHubAuthenticator
+	refreshToken string
+	expiresAt    time.Time
+	calls        int
+}
+
+func (a *refreshingAuthenticator) RefreshToken(ctx context.Context, refreshToken string) (*Credential, error) {
+	a.calls++
+	if a.refreshToken == "" || refreshToken != a.refreshToken {
+		return nil, &AuthError{Type: ErrorTypeExpiredToken, Message: "cannot refresh", Code: "TEST_NO_REFRESH"}
+	}
+	return &Credential{Token: "ghu_new", ExpiresAt: &a.expiresAt}, nil
+}
+
+func newExpiryManager(t *testing.T, authenticator Authenticator, now time.Time) *DefaultManager {
+	manager := NewDefaultManager()
+	manager.now = func() time.Time { return now }
+	manager.RegisterAuthenticator(authenticator)
+	manager.RegisterStore(NewFileStore(filepath.Join(t.TempDir(), "auth"), "test-pass"))
+	return manager
+}
+
+func TestCredentialExpiry(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cred := &Credential{Metadata: map[string]string{MetadataExpiresAt: "2026-05-01T12:03:00Z"}}
+	expiry, ok := cred.Expiry()
+	require.True(t, ok)
+	assert.Equal(t, now.Add(3*time.Minute), expiry)
+	assert.True(t, cred.ExpiresWithin(now, RefreshWindow))
+	assert.False(t, cred.ExpiresWithin(now, time.Minute))
+
+	_, ok = (&Credential{}).Expiry()
+	assert.False(t, ok)
+	assert.False(t, (&Credential{}).ExpiresWithin(now, ExpiryWarning))
+}
+
+func TestGetCredentialRefreshesBeforeExpiry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(2 * time.Minute)
+	authenticator := &refreshingAuthenticator{refreshToken: "ghr_refresh", expiresAt: now.Add(8 * time.Hour)}
+	manager := newExpiryManager(t, authenticator, now)
+
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{
+		ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Username: "me",
+		Token: "ghu_old", ExpiresAt: &soon, Metadata: map[string]string{MetadataRefreshToken: "ghr_refresh"},
+	}))
+
+	cred, err := manager.GetCredential(ctx, PlatformGitHub, "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghu_new", cred.Token)
+	assert.Equal(t, "me", cred.Username)
+
+	// The refreshed credential was stored and is not refreshed again
+	cred, err = manager.GetCredential(ctx, PlatformGitHub, "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghu_new", cred.Token)
+	assert.Equal(t, 1, authenticator.calls)
+}
+
+func TestGetCredentialReportsExpiry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	manager := newExpiryManager(t, &refreshingAuthenticator{}, now)
+
+	past := now.Add(-time.Hour)
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{
+		ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Token: "ghp_old", ExpiresAt: &past,
+	}))
+	_, err := manager.GetCredential(ctx, PlatformGitHub, "github.com")
+	var authErr *AuthError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, ErrorTypeExpiredToken, authErr.Type)
+	assert.Contains(t, authErr.Hint, "ddx auth refresh github.com")
+
+	// A token about to expire that cannot be refreshed is still usable
+	soon := now.Add(time.Minute)
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{
+		ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Token: "ghp_soon", ExpiresAt: &soon,
+	}))
+	cred, err := manager.GetCredential(ctx, PlatformGitHub, "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_soon", cred.Token)
+}
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/easel/ddx/internal/network"
 )
 
 // GitHubAuthenticator implements authentication for GitHub
@@ -58,7 +60,8 @@ func (a *GitHubAuthenticator) ValidateToken(ctx context.Context, token string, r
 	}
 
 	// Check token validity by calling GitHub API
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := network.Client()
+	client.Timeout = 10 * time.Second
 	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"/user", nil)
 	if err != nil {
 		return err
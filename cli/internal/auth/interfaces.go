@@ -13,6 +13,7 @@ const (
 	AuthMethodHTTPS AuthMethod = "https"
 	AuthMethodOAuth AuthMethod = "oauth"
 	AuthMethodToken AuthMethod = "token"
+	AuthMethodSSH   AuthMethod = "ssh"
 )
 
 // Platform represents different Git hosting platforms
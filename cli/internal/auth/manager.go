@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sort"
 	"sync"
 	"time"
@@ -14,6 +16,10 @@ type DefaultManager struct {
 	stores         []Store
 	helpers        []CredentialHelper
 	mu             sync.RWMutex
+	// audit records authentication events; nil disables auditing
+	audit *AuditLog
+	// now replaces time.Now in tests
+	now func() time.Time
 }
 
 // NewDefaultManager creates a new authentication manager
@@ -45,6 +51,25 @@ func (m *DefaultManager) RegisterStore(store Store) {
 	}
 }
 
+// SetAuditLog records authentication attempts and credential store access
+// in log and rate limits repeated failures
+func (m *DefaultManager) SetAuditLog(log *AuditLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audit = log
+}
+
+// record adds an event to the audit log, if one is set. Failing to audit
+// does not fail the operation.
+func (m *DefaultManager) record(event AuditEvent) {
+	m.mu.RLock()
+	log := m.audit
+	m.mu.RUnlock()
+	if err := log.Record(event); err != nil {
+		slog.Debug("failed to write auth audit log", "error", err)
+	}
+}
+
 // RegisterCredentialHelper registers a system credential helper
 func (m *DefaultManager) RegisterCredentialHelper(helper CredentialHelper) {
 	m.mu.Lock()
@@ -55,11 +80,31 @@ func (m *DefaultManager) RegisterCredentialHelper(helper CredentialHelper) {
 }
 
 // Authenticate performs authentication for a given request
-func (m *DefaultManager) Authenticate(ctx context.Context, req *AuthRequest) (*AuthResult, error) {
+func (m *DefaultManager) Authenticate(ctx context.Context, req *AuthRequest) (result *AuthResult, err error) {
 	m.mu.RLock()
 	authenticator, exists := m.authenticators[req.Platform]
+	audit := m.audit
 	m.mu.RUnlock()
 
+	if err := audit.CheckRateLimit(req.Platform, req.Repository); err != nil {
+		m.record(auditEvent(AuditAuthenticate, req.Platform, req.Repository, err))
+		return nil, err
+	}
+	defer func() {
+		outcome := err
+		if outcome == nil && !result.Success {
+			outcome = result.Error
+			if outcome == nil {
+				outcome = errors.New(result.Message)
+			}
+		}
+		event := auditEvent(AuditAuthenticate, req.Platform, req.Repository, outcome)
+		if result != nil {
+			event.Method = result.Method
+		}
+		m.record(event)
+	}()
+
 	if !exists {
 		return nil, &AuthError{
 			Type:    ErrorTypeNotFound,
@@ -100,7 +145,7 @@ func (m *DefaultManager) Authenticate(ctx context.Context, req *AuthRequest) (*A
 	}
 
 	// Perform new authentication
-	result, err := authenticator.Authenticate(ctx, req)
+	result, err = authenticator.Authenticate(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +161,8 @@ func (m *DefaultManager) Authenticate(ctx context.Context, req *AuthRequest) (*A
 }
 
 // ValidateCredentials validates existing credentials
-func (m *DefaultManager) ValidateCredentials(ctx context.Context, platform Platform, repository string) error {
+func (m *DefaultManager) ValidateCredentials(ctx context.Context, platform Platform, repository string) (err error) {
+	defer func() { m.record(auditEvent(AuditValidate, platform, repository, err)) }()
 	cred, err := m.GetCredential(ctx, platform, repository)
 	if err != nil {
 		return err
@@ -148,8 +194,20 @@ func (m *DefaultManager) ValidateCredentials(ctx context.Context, platform Platf
 	return authenticator.ValidateToken(ctx, cred.Token, cred.Scopes)
 }
 
-// GetCredential retrieves stored credentials for a platform/repository
-func (m *DefaultManager) GetCredential(ctx context.Context, platform Platform, repository string) (*Credential, error) {
+// GetCredential retrieves stored credentials for a platform/repository.
+// Credentials about to expire are refreshed when the platform supports it,
+// and expired ones that cannot be refreshed are reported as such.
+func (m *DefaultManager) GetCredential(ctx context.Context, platform Platform, repository string) (cred *Credential, err error) {
+	defer func() { m.record(auditEvent(AuditCredentialAccess, platform, repository, err)) }()
+	cred, err = m.lookupCredential(ctx, platform, repository)
+	if err != nil {
+		return nil, err
+	}
+	return m.checkExpiry(ctx, cred)
+}
+
+// lookupCredential finds stored credentials regardless of their expiry
+func (m *DefaultManager) lookupCredential(ctx context.Context, platform Platform, repository string) (*Credential, error) {
 	m.mu.RLock()
 	stores := make([]Store, len(m.stores))
 	copy(stores, m.stores)
@@ -170,7 +228,18 @@ func (m *DefaultManager) GetCredential(ctx context.Context, platform Platform, r
 }
 
 // StoreCredential securely stores authentication credentials
-func (m *DefaultManager) StoreCredential(ctx context.Context, cred *Credential) error {
+func (m *DefaultManager) StoreCredential(ctx context.Context, cred *Credential) (err error) {
+	defer func() {
+		event := auditEvent(AuditCredentialStore, cred.Platform, cred.ID, err)
+		event.Method = cred.Method
+		m.record(event)
+	}()
+	return m.storeCredential(ctx, cred, true)
+}
+
+// storeCredential writes cred to the highest priority store that accepts
+// it. touch stamps it as updated now; imports keep their own timestamps.
+func (m *DefaultManager) storeCredential(ctx context.Context, cred *Credential, touch bool) error {
 	m.mu.RLock()
 	stores := make([]Store, len(m.stores))
 	copy(stores, m.stores)
@@ -190,7 +259,9 @@ func (m *DefaultManager) StoreCredential(ctx context.Context, cred *Credential)
 	if cred.CreatedAt.IsZero() {
 		cred.CreatedAt = now
 	}
-	cred.UpdatedAt = now
+	if touch || cred.UpdatedAt.IsZero() {
+		cred.UpdatedAt = now
+	}
 
 	// Try to store in the highest priority store
 	primaryStore := stores[0]
@@ -213,7 +284,8 @@ func (m *DefaultManager) StoreCredential(ctx context.Context, cred *Credential)
 }
 
 // DeleteCredential removes stored credentials
-func (m *DefaultManager) DeleteCredential(ctx context.Context, platform Platform, repository string) error {
+func (m *DefaultManager) DeleteCredential(ctx context.Context, platform Platform, repository string) (err error) {
+	defer func() { m.record(auditEvent(AuditCredentialDelete, platform, repository, err)) }()
 	m.mu.RLock()
 	stores := make([]Store, len(m.stores))
 	copy(stores, m.stores)
@@ -277,33 +349,24 @@ func (m *DefaultManager) ListCredentials(ctx context.Context) ([]*Credential, er
 	return result, nil
 }
 
-// RefreshCredential refreshes expired credentials
-func (m *DefaultManager) RefreshCredential(ctx context.Context, platform Platform, repository string) (*Credential, error) {
-	cred, err := m.GetCredential(ctx, platform, repository)
+// RefreshCredential refreshes credentials through the platform's
+// authenticator, authenticating again when the platform cannot refresh
+func (m *DefaultManager) RefreshCredential(ctx context.Context, platform Platform, repository string) (refreshed *Credential, err error) {
+	defer func() { m.record(auditEvent(AuditCredentialRefresh, platform, repository, err)) }()
+	cred, err := m.lookupCredential(ctx, platform, repository)
 	if err != nil {
 		return nil, err
 	}
 
-	m.mu.RLock()
-	authenticator, exists := m.authenticators[platform]
-	m.mu.RUnlock()
-
-	if !exists {
-		return nil, &AuthError{
-			Type:    ErrorTypeNotFound,
-			Message: fmt.Sprintf("No authenticator available for platform: %s", platform),
-			Code:    "AUTH_PLATFORM_NOT_SUPPORTED",
-		}
-	}
-
 	// Try to refresh using the authenticator
-	if refreshed, err := authenticator.RefreshToken(ctx, cred.Token); err == nil {
-		// Store the refreshed credential
-		if err := m.StoreCredential(ctx, refreshed); err != nil {
-			return nil, err
-		}
+	refreshed, err = m.refresh(ctx, cred)
+	if err == nil {
 		return refreshed, nil
 	}
+	var authErr *AuthError
+	if errors.As(err, &authErr) && authErr.Code == "AUTH_PLATFORM_NOT_SUPPORTED" {
+		return nil, err
+	}
 
 	// If refresh fails, perform new authentication
 	req := &AuthRequest{
@@ -319,6 +382,9 @@ func (m *DefaultManager) RefreshCredential(ctx context.Context, platform Platfor
 	}
 
 	if !result.Success {
+		if result.Error != nil {
+			return nil, result.Error
+		}
 		return nil, &AuthError{
 			Type:    ErrorTypeInvalidCredentials,
 			Message: "Failed to refresh credentials",
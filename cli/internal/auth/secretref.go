@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SecretRefPrefix marks a configuration value that names a stored secret
+// instead of holding it. A reference is written secret://<store>/<key>,
+// where the store is the credential platform and the key its ID.
+const SecretRefPrefix = "secret://"
+
+// SecretGetter retrieves stored credentials; DefaultManager implements it
+type SecretGetter interface {
+	GetCredential(ctx context.Context, platform Platform, repository string) (*Credential, error)
+}
+
+// SecretRef returns the reference to a stored secret
+func SecretRef(platform Platform, id string) string {
+	return SecretRefPrefix + string(platform) + "/" + id
+}
+
+// IsSecretRef reports whether a value is a secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix)
+}
+
+// ParseSecretRef splits a secret reference into its credential platform and ID
+func ParseSecretRef(value string) (Platform, string, error) {
+	rest := strings.TrimPrefix(value, SecretRefPrefix)
+	platform, id, ok := strings.Cut(rest, "/")
+	if !IsSecretRef(value) || !ok || platform == "" || id == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q (expected %s<store>/<key>)", value, SecretRefPrefix)
+	}
+	return Platform(platform), id, nil
+}
+
+// ResolveSecretRef returns the stored secret a reference names, or value
+// itself when it is not a reference
+func ResolveSecretRef(ctx context.Context, getter SecretGetter, value string) (string, error) {
+	if !IsSecretRef(value) {
+		return value, nil
+	}
+	platform, id, err := ParseSecretRef(value)
+	if err != nil {
+		return "", err
+	}
+	cred, err := getter.GetCredential(ctx, platform, id)
+	if err != nil {
+		return "", fmt.Errorf("secret %s not found: %w", value, err)
+	}
+	if cred.Token == "" {
+		return "", fmt.Errorf("secret %s is empty", value)
+	}
+	return cred.Token, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSecrets is a SecretGetter holding credentials by platform/ID
+type staticSecrets map[string]string
+
+func (s staticSecrets) GetCredential(_ context.Context, platform Platform, id string) (*Credential, error) {
+	token, ok := s[string(platform)+"/"+id]
+	if !ok {
+		return nil, errors.New("not stored")
+	}
+	return &Credential{ID: id, Platform: platform, Token: token}, nil
+}
+
+func TestSecretRef(t *testing.T) {
+	t.Parallel()
+	ref := SecretRef("config", "library.repository.url")
+	assert.Equal(t, "secret://config/library.repository.url", ref)
+	assert.True(t, IsSecretRef(ref))
+
+	platform, id, err := ParseSecretRef("secret://mcp/github/TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, Platform("mcp"), platform)
+	assert.Equal(t, "github/TOKEN", id)
+
+	_, _, err = ParseSecretRef("secret://config")
+	assert.ErrorContains(t, err, "expected secret://<store>/<key>")
+}
+
+func TestResolveSecretRef(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	secrets := staticSecrets{"config/library.repository.url": "https://token@example.com/lib.git", "config/empty": ""}
+
+	value, err := ResolveSecretRef(ctx, secrets, "secret://config/library.repository.url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://token@example.com/lib.git", value)
+
+	value, err = ResolveSecretRef(ctx, secrets, "https://example.com/lib.git")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/lib.git", value, "plain values are returned as they are")
+
+	_, err = ResolveSecretRef(ctx, secrets, "secret://config/missing")
+	assert.ErrorContains(t, err, "secret secret://config/missing not found")
+	_, err = ResolveSecretRef(ctx, secrets, "secret://config/empty")
+	assert.ErrorContains(t, err, "is empty")
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultKeyFiles are the private keys ssh tries when no agent is running
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// IsSSHURL reports whether a repository URL uses SSH transport, either as
+// an ssh:// URL or in scp-style user@host:path form
+func IsSSHURL(repoURL string) bool {
+	lower := strings.ToLower(repoURL)
+	if strings.HasPrefix(lower, "ssh://") || strings.HasPrefix(lower, "git+ssh://") {
+		return true
+	}
+	_, _, ok := splitSCPURL(repoURL)
+	return ok
+}
+
+// SSHToHTTPS converts an SSH repository URL into its HTTPS equivalent
+func SSHToHTTPS(repoURL string) (string, error) {
+	return sshToHTTPS(repoURL, nil)
+}
+
+// sshToHTTPS converts an SSH repository URL into its HTTPS equivalent,
+// replacing the host with its real name when it is an ssh config alias
+func sshToHTTPS(repoURL string, config *SSHConfig) (string, error) {
+	host, path, ok := splitSCPURL(repoURL)
+	if !ok {
+		u, err := url.Parse(repoURL)
+		if err != nil || (u.Scheme != "ssh" && u.Scheme != "git+ssh") || u.Host == "" {
+			return "", fmt.Errorf("not an SSH repository URL: %s", repoURL)
+		}
+		host, path = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+	}
+	if config != nil {
+		host = config.Host(host).HostName
+	}
+	return fmt.Sprintf("https://%s/%s", host, path), nil
+}
+
+// RepositoryHost returns the host name of a repository URL in any form
+func RepositoryHost(repoURL string) string {
+	if host, _, ok := splitSCPURL(repoURL); ok {
+		return host
+	}
+	if u, err := url.Parse(repoURL); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
+
+// splitSCPURL splits an scp-style URL (user@host:path) into host and path
+func splitSCPURL(repoURL string) (host, path string, ok bool) {
+	if strings.Contains(repoURL, "://") {
+		return "", "", false
+	}
+	at := strings.Index(repoURL, "@")
+	colon := strings.Index(repoURL, ":")
+	if at <= 0 || colon < at+2 || colon == len(repoURL)-1 {
+		return "", "", false
+	}
+	return repoURL[at+1 : colon], strings.TrimPrefix(repoURL[colon+1:], "/"), true
+}
+
+// SSHAgent inspects the local SSH agent, key files and ssh client
+// configuration
+type SSHAgent struct {
+	socket   string
+	sshDir   string
+	config   *SSHConfig
+	listKeys func(ctx context.Context) ([]string, error)
+}
+
+// NewSSHAgent creates an SSHAgent for the current user's environment. An
+// unreadable ~/.ssh/config is ignored, as host aliases then resolve to
+// themselves.
+func NewSSHAgent() *SSHAgent {
+	homeDir, _ := os.UserHomeDir()
+	sshDir := filepath.Join(homeDir, ".ssh")
+	config, err := LoadSSHConfig(filepath.Join(sshDir, "config"))
+	if err != nil {
+		slog.Warn("ignoring ssh config", "error", err)
+	}
+	return &SSHAgent{
+		socket:   os.Getenv("SSH_AUTH_SOCK"),
+		sshDir:   sshDir,
+		config:   config,
+		listKeys: listAgentKeys,
+	}
+}
+
+// Host returns the ssh configuration that applies to a host name or alias
+func (a *SSHAgent) Host(alias string) SSHHost {
+	return a.config.Host(alias)
+}
+
+// IsAvailable checks if an SSH agent socket is reachable
+func (a *SSHAgent) IsAvailable() bool {
+	if a.socket == "" {
+		return false
+	}
+	_, err := os.Stat(a.socket)
+	return err == nil
+}
+
+// Keys returns the fingerprints of identities loaded in the agent
+func (a *SSHAgent) Keys(ctx context.Context) ([]string, error) {
+	if !a.IsAvailable() {
+		return nil, &AuthError{
+			Type:    ErrorTypeNotFound,
+			Message: "No SSH agent is running",
+			Code:    "SSH_AGENT_NOT_FOUND",
+			Hint:    "Start one with 'eval $(ssh-agent)' and add a key with 'ssh-add'",
+		}
+	}
+	return a.listKeys(ctx)
+}
+
+// KeyFiles returns the default private key files present on disk
+func (a *SSHAgent) KeyFiles() []string {
+	var files []string
+	for _, name := range defaultKeyFiles {
+		path := filepath.Join(a.sshDir, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// Validate ensures SSH authentication can be attempted, either through
+// identities loaded in the agent or default key files on disk
+func (a *SSHAgent) Validate(ctx context.Context) error {
+	keys, err := a.Keys(ctx)
+	if err == nil && len(keys) > 0 {
+		return nil
+	}
+	if len(a.KeyFiles()) > 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &AuthError{
+		Type:    ErrorTypeNotFound,
+		Message: "SSH agent has no identities loaded",
+		Code:    "SSH_NO_IDENTITIES",
+		Hint:    "Add a key with 'ssh-add' or create one with 'ssh-keygen -t ed25519'",
+	}
+}
+
+// ValidateHost ensures SSH authentication can be attempted for a host.
+// When ~/.ssh/config names keys for the host, one of them must exist if
+// IdentitiesOnly is set; otherwise the agent or default keys also do. The
+// key file found is returned, or "" when the agent or default keys are
+// relied on.
+func (a *SSHAgent) ValidateHost(ctx context.Context, alias string) (string, error) {
+	host := a.Host(alias)
+	for _, file := range host.IdentityFiles {
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	if len(host.IdentityFiles) > 0 && host.IdentitiesOnly {
+		return "", &AuthError{
+			Type:    ErrorTypeNotFound,
+			Message: fmt.Sprintf("SSH key %s configured for %s does not exist", host.IdentityFiles[0], alias),
+			Code:    "SSH_IDENTITY_MISSING",
+			Hint:    fmt.Sprintf("Fix IdentityFile for Host %s in %s", alias, filepath.Join(a.sshDir, "config")),
+		}
+	}
+	return "", a.Validate(ctx)
+}
+
+// listAgentKeys queries the running agent with ssh-add
+func listAgentKeys(ctx context.Context) ([]string, error) {
+	output, err := exec.CommandContext(ctx, "ssh-add", "-l").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// ssh-add exits 1 when the agent holds no identities
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, &AuthError{
+			Type:    ErrorTypeNotFound,
+			Message: fmt.Sprintf("Unable to query SSH agent: %v", err),
+			Code:    "SSH_AGENT_UNREACHABLE",
+			Hint:    "Check that SSH_AUTH_SOCK points to a running agent",
+		}
+	}
+
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+// MethodSelection describes how to reach a repository
+type MethodSelection struct {
+	Method AuthMethod
+	URL    string
+	// Fallback is set when an SSH URL could not be used and HTTPS was chosen
+	Fallback bool
+	Reason   string
+	// Host is the ssh configuration applied to an SSH URL's host
+	Host *SSHHost
+	// Identity is the key file ssh config selects for the host, if any
+	Identity string
+}
+
+// SelectMethod picks the authentication method for a repository URL.
+// SSH URLs use SSH when the key ssh config names for the host, the agent
+// or a default key file is usable, and otherwise fall back to the
+// equivalent HTTPS URL with token authentication. Host aliases from
+// ~/.ssh/config resolve to the real host name for the fallback.
+func SelectMethod(ctx context.Context, repoURL string, agent *SSHAgent) (*MethodSelection, error) {
+	if !IsSSHURL(repoURL) {
+		return &MethodSelection{Method: AuthMethodHTTPS, URL: repoURL}, nil
+	}
+
+	host := agent.Host(RepositoryHost(repoURL))
+	identity, err := agent.ValidateHost(ctx, host.Alias)
+	if err == nil {
+		return &MethodSelection{Method: AuthMethodSSH, URL: repoURL, Host: &host, Identity: identity}, nil
+	}
+
+	httpsURL, convErr := sshToHTTPS(repoURL, agent.config)
+	if convErr != nil {
+		return nil, convErr
+	}
+
+	reason := err.Error()
+	var authErr *AuthError
+	if errors.As(err, &authErr) && authErr.Hint != "" {
+		reason = fmt.Sprintf("%s (%s)", authErr.Message, authErr.Hint)
+	}
+
+	return &MethodSelection{
+		Method:   AuthMethodToken,
+		URL:      httpsURL,
+		Fallback: true,
+		Reason:   reason,
+		Host:     &host,
+	}, nil
+}
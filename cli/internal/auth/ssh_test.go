@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSSHURLParsing tests detection and conversion of SSH repository URLs
+func TestSSHURLParsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url   string
+		isSSH bool
+		https string
+		host  string
+	}{
+		{"git@github.com:easel/ddx-library.git", true, "https://github.com/easel/ddx-library.git", "github.com"},
+		{"ssh://git@gitlab.com/team/library.git", true, "https://gitlab.com/team/library.git", "gitlab.com"},
+		{"ssh://git@example.com:2222/lib.git", true, "https://example.com/lib.git", "example.com"},
+		{"https://github.com/easel/ddx-library", false, "", "github.com"},
+		{"/tmp/library", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.isSSH, IsSSHURL(tt.url), tt.url)
+		assert.Equal(t, tt.host, RepositoryHost(tt.url), tt.url)
+		if tt.isSSH {
+			converted, err := SSHToHTTPS(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.https, converted)
+		} else {
+			_, err := SSHToHTTPS(tt.url)
+			assert.Error(t, err)
+		}
+	}
+}
+
+// TestSelectMethod tests SSH preference and HTTPS fallback
+func TestSelectMethod(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	require.NoError(t, os.WriteFile(socket, nil, 0600))
+
+	withKeys := &SSHAgent{
+		socket:   socket,
+		sshDir:   t.TempDir(),
+		listKeys: func(context.Context) ([]string, error) { return []string{"256 SHA256:abc user (ED25519)"}, nil },
+	}
+	noAgent := &SSHAgent{sshDir: t.TempDir()}
+
+	sel, err := SelectMethod(ctx, "https://github.com/easel/ddx-library", noAgent)
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodHTTPS, sel.Method)
+	assert.False(t, sel.Fallback)
+
+	sel, err = SelectMethod(ctx, "git@github.com:easel/ddx-library.git", withKeys)
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodSSH, sel.Method)
+	assert.Equal(t, "git@github.com:easel/ddx-library.git", sel.URL)
+
+	sel, err = SelectMethod(ctx, "git@github.com:easel/ddx-library.git", noAgent)
+	require.NoError(t, err)
+	assert.True(t, sel.Fallback)
+	assert.Equal(t, AuthMethodToken, sel.Method)
+	assert.Equal(t, "https://github.com/easel/ddx-library.git", sel.URL)
+	assert.Contains(t, sel.Reason, "No SSH agent")
+
+	// A key file on disk is enough even without an agent
+	keyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(keyDir, "id_ed25519"), []byte("key"), 0600))
+	sel, err = SelectMethod(ctx, "git@github.com:easel/ddx-library.git", &SSHAgent{sshDir: keyDir})
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodSSH, sel.Method)
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth bounds nested Include directives, as ssh does
+const maxIncludeDepth = 16
+
+// SSHConfig is a parsed OpenSSH client configuration (~/.ssh/config).
+// Only the options that decide which host, user and key a connection
+// uses are kept; Match sections are skipped.
+type SSHConfig struct {
+	sections []sshSection
+	home     string
+}
+
+// sshSection is a Host section: its patterns and options in file order
+type sshSection struct {
+	patterns []string
+	// match marks a Match section, which is never applied
+	match   bool
+	options [][2]string
+}
+
+// SSHHost is the configuration ssh applies when connecting to a host
+// name or alias
+type SSHHost struct {
+	Alias    string
+	HostName string
+	User     string
+	Port     string
+	// IdentityFiles are the keys configured for the host, with ~ and
+	// tokens such as %h expanded, in the order ssh tries them
+	IdentityFiles []string
+	// IdentitiesOnly restricts ssh to the configured keys
+	IdentitiesOnly bool
+}
+
+// LoadSSHConfig reads an ssh client configuration file. A missing file
+// yields an empty configuration.
+func LoadSSHConfig(path string) (*SSHConfig, error) {
+	homeDir, _ := os.UserHomeDir()
+	config := &SSHConfig{home: homeDir}
+	if err := config.parseFile(path, filepath.Dir(path), 0); err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// ParseSSHConfig parses an ssh client configuration. Relative Include
+// paths are resolved against sshDir, and ~ expands to home.
+func ParseSSHConfig(r io.Reader, sshDir, home string) (*SSHConfig, error) {
+	config := &SSHConfig{home: home}
+	if err := config.parse(r, "ssh config", sshDir, 0); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *SSHConfig) parseFile(path, sshDir string, depth int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return c.parse(file, path, sshDir, depth)
+}
+
+func (c *SSHConfig) parse(r io.Reader, name, sshDir string, depth int) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, args := splitSSHLine(line)
+		if len(args) == 0 {
+			return fmt.Errorf("%s:%d: %s has no value", name, lineNo, keyword)
+		}
+
+		switch keyword {
+		case "host":
+			c.sections = append(c.sections, sshSection{patterns: args})
+		case "match":
+			c.sections = append(c.sections, sshSection{match: true})
+		case "include":
+			if depth >= maxIncludeDepth {
+				return fmt.Errorf("%s:%d: too many nested Include directives", name, lineNo)
+			}
+			for _, pattern := range args {
+				pattern = c.expandHome(pattern)
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(sshDir, pattern)
+				}
+				matches, _ := filepath.Glob(pattern)
+				for _, match := range matches {
+					if err := c.parseFile(match, sshDir, depth+1); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+				}
+			}
+		default:
+			if len(c.sections) == 0 {
+				// Options before the first Host apply to every host
+				c.sections = append(c.sections, sshSection{patterns: []string{"*"}})
+			}
+			section := &c.sections[len(c.sections)-1]
+			section.options = append(section.options, [2]string{keyword, strings.Join(args, " ")})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// splitSSHLine splits a line into its lowercased keyword and arguments.
+// The keyword may be followed by whitespace or '=', and arguments may be
+// double-quoted.
+func splitSSHLine(line string) (string, []string) {
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+
+	var args []string
+	for rest != "" {
+		if rest[0] == '"' {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				args = append(args, rest[1:])
+				break
+			}
+			args = append(args, rest[1:closing+1])
+			rest = strings.TrimLeft(rest[closing+2:], " \t")
+			continue
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			args = append(args, rest)
+			break
+		}
+		args = append(args, rest[:end])
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return keyword, args
+}
+
+// Host returns the configuration ssh applies to a host name or alias. As
+// in ssh, the first value found for an option wins, except IdentityFile,
+// which accumulates across matching sections.
+func (c *SSHConfig) Host(alias string) SSHHost {
+	host := SSHHost{Alias: alias}
+	seen := map[string]bool{}
+	if c != nil {
+		for _, section := range c.sections {
+			if section.match || !matchHostPatterns(section.patterns, alias) {
+				continue
+			}
+			for _, option := range section.options {
+				keyword, value := option[0], option[1]
+				if keyword == "identityfile" {
+					host.IdentityFiles = append(host.IdentityFiles, value)
+					continue
+				}
+				if seen[keyword] {
+					continue
+				}
+				seen[keyword] = true
+				switch keyword {
+				case "hostname":
+					host.HostName = value
+				case "user":
+					host.User = value
+				case "port":
+					host.Port = value
+				case "identitiesonly":
+					host.IdentitiesOnly = strings.EqualFold(value, "yes")
+				}
+			}
+		}
+	}
+
+	if host.HostName == "" {
+		host.HostName = alias
+	} else {
+		host.HostName = strings.ReplaceAll(host.HostName, "%h", alias)
+	}
+	for i, file := range host.IdentityFiles {
+		host.IdentityFiles[i] = c.expandIdentity(file, host)
+	}
+	return host
+}
+
+// matchHostPatterns applies a Host line: some pattern must match and no
+// negated (!) pattern may
+func matchHostPatterns(patterns []string, host string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		ok, _ := filepath.Match(strings.ToLower(strings.TrimPrefix(pattern, "!")), strings.ToLower(host))
+		if ok && negated {
+			return false
+		}
+		if ok {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// expandIdentity expands ~ and the %d, %h, %r, %u and %% tokens of an
+// IdentityFile value
+func (c *SSHConfig) expandIdentity(path string, host SSHHost) string {
+	home := ""
+	if c != nil {
+		home = c.home
+	}
+	localUser := ""
+	if current, err := user.Current(); err == nil {
+		localUser = current.Username
+	}
+	remoteUser := host.User
+	if remoteUser == "" {
+		remoteUser = localUser
+	}
+	replacer := strings.NewReplacer("%%", "%", "%d", home, "%h", host.HostName, "%r", remoteUser, "%u", localUser)
+	return replacer.Replace(c.expandHome(path))
+}
+
+// expandHome expands a leading ~/ to the home directory
+func (c *SSHConfig) expandHome(path string) string {
+	if c == nil || c.home == "" {
+		return path
+	}
+	if path == "~" {
+		return c.home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(c.home, path[2:])
+	}
+	return path
+}
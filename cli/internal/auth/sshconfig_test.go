@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSSHConfig = `# Work and personal GitHub accounts
+IdentityFile ~/.ssh/id_global
+
+Host github-work
+    HostName github.com
+    User git
+    IdentityFile ~/.ssh/id_work
+    IdentitiesOnly yes
+
+Host github-* !github-old
+  IdentityFile="%d/.ssh/id_%h"
+  Port 443
+
+Match host *.internal
+  User nobody
+
+Host *
+  User someone
+  HostName %h.example.net
+`
+
+// TestSSHConfigHost tests resolving host aliases from an ssh config
+func TestSSHConfigHost(t *testing.T) {
+	t.Parallel()
+	config, err := ParseSSHConfig(strings.NewReader(testSSHConfig), "/home/me/.ssh", "/home/me")
+	require.NoError(t, err)
+
+	work := config.Host("github-work")
+	assert.Equal(t, "github.com", work.HostName)
+	assert.Equal(t, "git", work.User)
+	assert.Equal(t, "443", work.Port)
+	assert.True(t, work.IdentitiesOnly)
+	assert.Equal(t, []string{"/home/me/.ssh/id_global", "/home/me/.ssh/id_work", "/home/me/.ssh/id_github.com"}, work.IdentityFiles)
+
+	old := config.Host("github-old")
+	assert.Equal(t, "someone", old.User)
+	assert.Equal(t, "github-old.example.net", old.HostName)
+	assert.Empty(t, old.Port)
+
+	// Match sections are not applied
+	internal := config.Host("build.internal")
+	assert.Equal(t, "someone", internal.User)
+
+	var empty *SSHConfig
+	assert.Equal(t, "gitlab.com", empty.Host("gitlab.com").HostName)
+}
+
+// TestSSHConfigInclude tests Include directives relative to ~/.ssh
+func TestSSHConfigInclude(t *testing.T) {
+	t.Parallel()
+	sshDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sshDir, "config.d"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config.d", "work"), []byte("Host gl-work\n  HostName gitlab.com\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config"), []byte("Include config.d/*\n"), 0600))
+
+	config, err := LoadSSHConfig(filepath.Join(sshDir, "config"))
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab.com", config.Host("gl-work").HostName)
+
+	config, err = LoadSSHConfig(filepath.Join(sshDir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, "gl-work", config.Host("gl-work").HostName)
+}
+
+// TestSelectMethodWithAlias tests that SSH host aliases pick their
+// configured key and fall back to the real host over HTTPS
+func TestSelectMethodWithAlias(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	config, err := ParseSSHConfig(strings.NewReader("Host github-work\n  HostName github.com\n  IdentityFile ~/.ssh/id_work\n  IdentitiesOnly yes\n"), sshDir, home)
+	require.NoError(t, err)
+	// A default key exists, but IdentitiesOnly restricts ssh to id_work
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "id_ed25519"), []byte("key"), 0600))
+	agent := &SSHAgent{sshDir: sshDir, config: config}
+
+	sel, err := SelectMethod(ctx, "git@github-work:org/repo.git", agent)
+	require.NoError(t, err)
+	assert.True(t, sel.Fallback)
+	assert.Equal(t, "https://github.com/org/repo.git", sel.URL)
+	assert.Contains(t, sel.Reason, "id_work")
+
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "id_work"), []byte("key"), 0600))
+	sel, err = SelectMethod(ctx, "git@github-work:org/repo.git", agent)
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodSSH, sel.Method)
+	assert.Equal(t, filepath.Join(sshDir, "id_work"), sel.Identity)
+	assert.Equal(t, "github.com", sel.Host.HostName)
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -355,7 +356,7 @@ func (s *FileStore) saveCredentials(credentials map[string]*Credential) error {
 		}
 	}
 
-	if err := os.WriteFile(s.filePath, encryptedData, 0600); err != nil {
+	if err := atomicfile.WriteFile(s.filePath, encryptedData, 0600); err != nil {
 		return &AuthError{
 			Type:    ErrorTypeStorageError,
 			Message: "Failed to write credential file",
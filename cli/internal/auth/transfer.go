@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/easel/ddx/internal/sealed"
+)
+
+// bundleVersion is the credential bundle format version
+const bundleVersion = 1
+
+// bundleHeader starts an exported credential bundle
+const bundleHeader = "# DDx credentials, encrypted with a passphrase. Import with 'ddx auth import'.\n"
+
+// ErrBundlePassphrase is returned when a bundle cannot be decrypted
+var ErrBundlePassphrase = errors.New("wrong passphrase or corrupted credential bundle")
+
+// credentialBundle is the plaintext sealed inside an export
+type credentialBundle struct {
+	Version     int           `json:"version"`
+	Credentials []*Credential `json:"credentials"`
+}
+
+// EncryptCredentials seals credentials for moving them to another machine,
+// encrypted with a key derived from passphrase
+func EncryptCredentials(credentials []*Credential, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(credentialBundle{Version: bundleVersion, Credentials: credentials})
+	if err != nil {
+		return nil, err
+	}
+	return sealed.Seal(bundleHeader, plaintext, passphrase)
+}
+
+// DecryptCredentials opens a bundle made by EncryptCredentials
+func DecryptCredentials(data []byte, passphrase string) ([]*Credential, error) {
+	plaintext, err := sealed.Open(bundleHeader, data, passphrase)
+	if errors.Is(err, sealed.ErrOpen) {
+		return nil, ErrBundlePassphrase
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bundle credentialBundle
+	if err := json.Unmarshal(plaintext, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse credential bundle: %w", err)
+	}
+	if bundle.Version != bundleVersion {
+		return nil, fmt.Errorf("credential bundle has unsupported version %d (expected %d)", bundle.Version, bundleVersion)
+	}
+	return bundle.Credentials, nil
+}
+
+// ExportCredentials seals every stored credential into a bundle for
+// ImportCredentials on another machine
+func (m *DefaultManager) ExportCredentials(ctx context.Context, passphrase string) ([]byte, int, error) {
+	credentials, err := m.ListCredentials(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := EncryptCredentials(credentials, passphrase)
+	for _, cred := range credentials {
+		m.record(auditEvent(AuditCredentialExport, cred.Platform, cred.ID, err))
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, len(credentials), nil
+}
+
+// ImportResult lists what an import did with each credential, as
+// platform/id
+type ImportResult struct {
+	Added   []string
+	Updated []string
+	// Skipped credentials are stored here already, as recently or more
+	// recently updated than in the bundle
+	Skipped []string
+}
+
+// ImportCredentials merges imported credentials into the stores. A
+// credential replaces a stored one only when it was updated more recently,
+// and keeps its original timestamps.
+func (m *DefaultManager) ImportCredentials(ctx context.Context, credentials []*Credential) (*ImportResult, error) {
+	result := &ImportResult{}
+	for _, cred := range credentials {
+		if cred == nil || cred.ID == "" || cred.Platform == "" {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", cred.Platform, cred.ID)
+		existing, err := m.lookupCredential(ctx, cred.Platform, cred.ID)
+		if err == nil && !cred.UpdatedAt.After(existing.UpdatedAt) {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+		if err := m.storeCredential(ctx, cred, false); err != nil {
+			m.record(auditEvent(AuditCredentialImport, cred.Platform, cred.ID, err))
+			return result, err
+		}
+		m.record(auditEvent(AuditCredentialImport, cred.Platform, cred.ID, nil))
+		if existing != nil {
+			result.Updated = append(result.Updated, name)
+		} else {
+			result.Added = append(result.Added, name)
+		}
+	}
+	return result, nil
+}
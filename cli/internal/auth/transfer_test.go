@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialBundleRoundTrip(t *testing.T) {
+	t.Parallel()
+	updated := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	credentials := []*Credential{{
+		ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken,
+		Token: "ghp_secret_token_value", UpdatedAt: updated,
+	}}
+
+	data, err := EncryptCredentials(credentials, "correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ghp_secret_token_value")
+	assert.NotContains(t, string(data), "github.com")
+
+	decrypted, err := DecryptCredentials(data, "correct horse")
+	require.NoError(t, err)
+	require.Len(t, decrypted, 1)
+	assert.Equal(t, "ghp_secret_token_value", decrypted[0].Token)
+	assert.True(t, decrypted[0].UpdatedAt.Equal(updated))
+
+	_, err = DecryptCredentials(data, "wrong")
+	assert.ErrorIs(t, err, ErrBundlePassphrase)
+	_, err = DecryptCredentials([]byte("not a bundle"), "correct horse")
+	assert.ErrorIs(t, err, ErrBundlePassphrase)
+	_, err = EncryptCredentials(credentials, "")
+	assert.Error(t, err)
+}
+
+func TestImportCredentialsKeepsNewer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	manager := NewDefaultManager()
+	manager.RegisterStore(NewFileStore(filepath.Join(t.TempDir(), "auth"), "test-pass"))
+
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{
+		ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Token: "ghp_current",
+	}))
+	require.NoError(t, manager.StoreCredential(ctx, &Credential{
+		ID: "gitlab.com", Platform: PlatformGitLab, Method: AuthMethodToken, Token: "glpat_old",
+	}))
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	result, err := manager.ImportCredentials(ctx, []*Credential{
+		{ID: "github.com", Platform: PlatformGitHub, Method: AuthMethodToken, Token: "ghp_stale", UpdatedAt: past},
+		{ID: "gitlab.com", Platform: PlatformGitLab, Method: AuthMethodToken, Token: "glpat_new", UpdatedAt: future},
+		{ID: "bitbucket.org", Platform: PlatformBitbucket, Method: AuthMethodToken, Token: "bb_token", UpdatedAt: past},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bitbucket/bitbucket.org"}, result.Added)
+	assert.Equal(t, []string{"gitlab/gitlab.com"}, result.Updated)
+	assert.Equal(t, []string{"github/github.com"}, result.Skipped)
+
+	cred, err := manager.lookupCredential(ctx, PlatformGitHub, "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_current", cred.Token)
+	cred, err = manager.lookupCredential(ctx, PlatformGitLab, "gitlab.com")
+	require.NoError(t, err)
+	assert.Equal(t, "glpat_new", cred.Token)
+	assert.True(t, cred.UpdatedAt.Equal(future), "imported credentials keep their timestamps")
+}
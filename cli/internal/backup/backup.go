@@ -0,0 +1,238 @@
+// Package backup keeps copies of AI configuration files such as CLAUDE.md
+// before ddx rewrites them. Each backup holds whole files, so anything
+// lost in a rewrite, including text outside the managed sections, can be
+// restored.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// Dir holds backups, relative to the project root
+const Dir = ".ddx/backups"
+
+// DefaultKeep is the number of backups retained when the configuration
+// sets no limit
+const DefaultKeep = 20
+
+// manifestName is the backup metadata file
+const manifestName = "backup.json"
+
+// Retention decides which backups are removed after a new one is made
+type Retention struct {
+	// Keep is the number of backups retained; zero keeps all
+	Keep int
+	// MaxAge removes backups older than this; zero keeps them regardless
+	// of age
+	MaxAge time.Duration
+}
+
+// Backup is a set of files copied before an operation changed them
+type Backup struct {
+	ID        string    `json:"id"`
+	Created   time.Time `json:"created"`
+	Operation string    `json:"operation"`
+	// Files maps slash-separated project paths to content hashes
+	Files map[string]string `json:"files"`
+
+	dir string
+}
+
+// Root returns the backup directory for a project
+func Root(workingDir string) string {
+	return filepath.Join(workingDir, filepath.FromSlash(Dir))
+}
+
+// Paths returns the backed-up files, sorted
+func (b *Backup) Paths() []string {
+	paths := make([]string, 0, len(b.Files))
+	for rel := range b.Files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Create backs up the given files, relative to workingDir, before an
+// operation rewrites them. Files that do not exist yet are skipped. It
+// returns nil when there is nothing to back up or the newest backup
+// already holds the same content. Old backups are then pruned.
+func Create(workingDir, operation string, files []string, retention Retention) (*Backup, error) {
+	now := time.Now().UTC()
+	b := &Backup{
+		ID:        now.Format("20060102-150405"),
+		Created:   now,
+		Operation: operation,
+		Files:     map[string]string{},
+	}
+	for _, rel := range files {
+		rel = filepath.ToSlash(filepath.Clean(rel))
+		hash, err := hashFile(filepath.Join(workingDir, filepath.FromSlash(rel)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+		b.Files[rel] = hash
+	}
+	if len(b.Files) == 0 {
+		return nil, nil
+	}
+	list, err := List(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 && maps.Equal(list[0].Files, b.Files) {
+		return nil, nil
+	}
+
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(Root(workingDir), b.ID)); os.IsNotExist(err) {
+			break
+		}
+		b.ID = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+	b.dir = filepath.Join(Root(workingDir), b.ID)
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	// Backups are local state and stay out of version control
+	ignore := filepath.Join(Root(workingDir), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	for rel := range b.Files {
+		if err := atomicfile.CopyFile(filepath.Join(workingDir, filepath.FromSlash(rel)), filepath.Join(b.dir, "files", filepath.FromSlash(rel))); err != nil {
+			_ = os.RemoveAll(b.dir)
+			return nil, fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+	}
+	if err := b.save(); err != nil {
+		_ = os.RemoveAll(b.dir)
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	if err := Prune(workingDir, retention, now); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// save writes the backup manifest
+func (b *Backup) save() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(b.dir, manifestName), data, 0644)
+}
+
+// List returns the project's backups, newest first
+func List(workingDir string) ([]*Backup, error) {
+	entries, err := os.ReadDir(Root(workingDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+	var list []*Backup
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		b, err := Load(workingDir, entry.Name())
+		if err != nil {
+			continue
+		}
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.After(list[j].Created)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// Load reads a backup by ID
+func Load(workingDir, id string) (*Backup, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid backup id %q", id)
+	}
+	dir := filepath.Join(Root(workingDir), id)
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("backup %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b := &Backup{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", id, err)
+	}
+	for rel := range b.Files {
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil, fmt.Errorf("backup %s holds a path outside the project: %s", id, rel)
+		}
+	}
+	b.dir = dir
+	return b, nil
+}
+
+// Restore copies the backed-up files back into the project
+func (b *Backup) Restore(workingDir string) error {
+	for _, rel := range b.Paths() {
+		src := filepath.Join(b.dir, "files", filepath.FromSlash(rel))
+		if hash, err := hashFile(src); err != nil || hash != b.Files[rel] {
+			return fmt.Errorf("backup copy of %s is damaged", rel)
+		}
+		if err := atomicfile.CopyFile(src, filepath.Join(workingDir, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// Prune removes backups beyond the retention limits, measuring age from now
+func Prune(workingDir string, retention Retention, now time.Time) error {
+	list, err := List(workingDir)
+	if err != nil {
+		return err
+	}
+	for i, b := range list {
+		tooMany := retention.Keep > 0 && i >= retention.Keep
+		tooOld := retention.MaxAge > 0 && now.Sub(b.Created) > retention.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.RemoveAll(b.dir); err != nil {
+			return fmt.Errorf("failed to remove backup %s: %w", b.ID, err)
+		}
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 of a file
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
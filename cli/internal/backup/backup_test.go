@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCreateRestore(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "CLAUDE.md"), "# My project\n")
+	writeFile(t, filepath.Join(dir, ".github", "copilot-instructions.md"), "Use tabs.\n")
+
+	b, err := Create(dir, "persona load", []string{"CLAUDE.md", ".github/copilot-instructions.md", "AGENTS.md"}, Retention{})
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Equal(t, []string{".github/copilot-instructions.md", "CLAUDE.md"}, b.Paths())
+	assert.FileExists(t, filepath.Join(Root(dir), ".gitignore"))
+
+	// Unchanged files are not backed up again
+	same, err := Create(dir, "persona load", []string{"CLAUDE.md", ".github/copilot-instructions.md"}, Retention{})
+	require.NoError(t, err)
+	assert.Nil(t, same)
+
+	writeFile(t, filepath.Join(dir, "CLAUDE.md"), "overwritten\n")
+	loaded, err := Load(dir, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "persona load", loaded.Operation)
+	require.NoError(t, loaded.Restore(dir))
+	data, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# My project\n", string(data))
+
+	writeFile(t, filepath.Join(loaded.dir, "files", "CLAUDE.md"), "tampered\n")
+	assert.ErrorContains(t, loaded.Restore(dir), "backup copy of CLAUDE.md is damaged")
+
+	_, err = Load(dir, "../escape")
+	assert.ErrorContains(t, err, "invalid backup id")
+	_, err = Load(dir, "missing")
+	assert.ErrorContains(t, err, "backup missing not found")
+}
+
+func TestCreateNothingToBackUp(t *testing.T) {
+	b, err := Create(t.TempDir(), "persona load", []string{"CLAUDE.md"}, Retention{})
+	require.NoError(t, err)
+	assert.Nil(t, b)
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 4; i++ {
+		writeFile(t, filepath.Join(dir, "CLAUDE.md"), string(rune('a'+i)))
+		_, err := Create(dir, "prompts inject", []string{"CLAUDE.md"}, Retention{Keep: 3})
+		require.NoError(t, err)
+	}
+	list, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.False(t, list[0].Created.Before(list[2].Created))
+
+	require.NoError(t, Prune(dir, Retention{MaxAge: time.Hour}, time.Now().Add(2*time.Hour)))
+	list, err = List(dir)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchLibrary creates a library with many personas, each with a long
+// body after its frontmatter, and template directories with READMEs
+func benchLibrary(b *testing.B, personas, templates int) string {
+	b.Helper()
+	lib := b.TempDir()
+	body := strings.Repeat("Review every change for correctness and clarity.\n", 2000)
+	for i := 0; i < personas; i++ {
+		path := filepath.Join(lib, "personas", fmt.Sprintf("persona-%04d.md", i))
+		content := fmt.Sprintf("---\nname: persona-%04d\nroles: [code-reviewer]\ntags: [go, review]\n---\n%s", i, body)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for i := 0; i < templates; i++ {
+		dir := filepath.Join(lib, "templates", fmt.Sprintf("template-%04d", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Template\n\nA starter project\n"+body), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return lib
+}
+
+// BenchmarkBuild measures a full scan of the library
+func BenchmarkBuild(b *testing.B) {
+	lib := benchLibrary(b, 500, 200)
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			saved := scanWorkers
+			scanWorkers = workers
+			defer func() { scanWorkers = saved }()
+			for i := 0; i < b.N; i++ {
+				if _, err := Build(lib); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoad measures loading an index that is up to date, the common
+// case for 'ddx list'
+func BenchmarkLoad(b *testing.B) {
+	lib := benchLibrary(b, 500, 200)
+	if _, err := Build(lib); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Load(lib); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPersonaFrontmatter measures parsing the frontmatter of a persona
+// with a long body, which is not read
+func BenchmarkPersonaFrontmatter(b *testing.B) {
+	lib := benchLibrary(b, 1, 0)
+	path := filepath.Join(lib, "personas", "persona-0000.md")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if personaFrontmatter(path) == nil {
+			b.Fatal("no frontmatter")
+		}
+	}
+}
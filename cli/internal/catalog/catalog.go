@@ -0,0 +1,271 @@
+// Package catalog keeps an index of the resources in a library so that
+// listing and discovery do not walk and parse the library on every
+// command. The index is stored in the library as .index.json and checked
+// against modification times and sizes when loaded: resource types whose
+// directory changed are rescanned, and entries that changed are described
+// again.
+package catalog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/resolve"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the index file at the library root
+const FileName = ".index.json"
+
+// Version is the index format version; indexes of other versions are
+// rebuilt
+const Version = 1
+
+// maxFrontmatterSize bounds the frontmatter read from a persona
+const maxFrontmatterSize = 64 * 1024
+
+// Entry is a resource at the top level of a type directory
+type Entry struct {
+	Type string `json:"type"`
+	// Name is the resource's path within its type directory
+	Name        string   `json:"name"`
+	IsDir       bool     `json:"is_dir"`
+	Size        int64    `json:"size,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Persona is the frontmatter of a persona file, when it has one
+	Persona *PersonaMeta `json:"persona,omitempty"`
+	// Stamp identifies the version of the files the entry was built from
+	Stamp string `json:"stamp"`
+}
+
+// PersonaMeta is the frontmatter of a persona
+type PersonaMeta struct {
+	Name        string   `yaml:"name" json:"name"`
+	Roles       []string `yaml:"roles" json:"roles,omitempty"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Tags        []string `yaml:"tags" json:"tags,omitempty"`
+}
+
+// typeIndex holds the entries of one type directory
+type typeIndex struct {
+	Stamp   string  `json:"stamp"`
+	Entries []Entry `json:"entries"`
+}
+
+// Index is the resource index of a library
+type Index struct {
+	Version int                   `json:"version"`
+	Types   map[string]*typeIndex `json:"types"`
+
+	root string
+}
+
+// Path returns the index file of the library at libPath
+func Path(libPath string) string {
+	return filepath.Join(libPath, FileName)
+}
+
+// Load returns the index of the library at libPath, bringing it up to date
+// with the files first. A missing or unreadable index is rebuilt. The
+// index is saved when it changed; failing to save it, as in a read-only
+// library, is not an error.
+func Load(libPath string) (*Index, error) {
+	idx := &Index{root: libPath}
+	if data, err := os.ReadFile(Path(libPath)); err == nil {
+		if err := json.Unmarshal(data, idx); err != nil || idx.Version != Version {
+			idx = &Index{root: libPath}
+		}
+	}
+	changed, err := idx.refresh(false)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		idx.save()
+	}
+	return idx, nil
+}
+
+// Build rescans the whole library at libPath and saves a new index
+func Build(libPath string) (*Index, error) {
+	idx := &Index{root: libPath}
+	if _, err := idx.refresh(true); err != nil {
+		return nil, err
+	}
+	idx.save()
+	return idx, nil
+}
+
+// Entries returns the resources of a type in name order
+func (idx *Index) Entries(resourceType string) []Entry {
+	if t := idx.Types[resourceType]; t != nil {
+		return t.Entries
+	}
+	return nil
+}
+
+// EntryPath returns the file or directory of an entry
+func (idx *Index) EntryPath(e Entry) string {
+	return filepath.Join(idx.root, e.Type, filepath.FromSlash(e.Name))
+}
+
+// refresh brings the index up to date, rescanning everything when full is
+// set, and reports whether anything changed. Files are checked and
+// described in parallel.
+func (idx *Index) refresh(full bool) (bool, error) {
+	if idx.Types == nil || full {
+		idx.Types = make(map[string]*typeIndex)
+	}
+	idx.Version = Version
+	changed := false
+	var jobs []scanJob
+	for _, resourceType := range resolve.Types {
+		dir := filepath.Join(idx.root, resourceType)
+		stamp := ""
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			stamp = fileStamp(info)
+		}
+
+		current := idx.Types[resourceType]
+		if current == nil || current.Stamp != stamp {
+			names, err := readNames(dir)
+			if err != nil {
+				return false, err
+			}
+			current = &typeIndex{Stamp: stamp, Entries: make([]Entry, len(names))}
+			idx.Types[resourceType] = current
+			changed = true
+			for i, name := range names {
+				jobs = append(jobs, scanJob{resourceType: resourceType, path: filepath.Join(dir, name), entry: &current.Entries[i]})
+			}
+			continue
+		}
+		for i := range current.Entries {
+			e := &current.Entries[i]
+			jobs = append(jobs, scanJob{resourceType: resourceType, path: idx.EntryPath(*e), entry: e, stamp: e.Stamp})
+		}
+	}
+
+	missing := false
+	for _, r := range runScan(jobs) {
+		changed = changed || r.changed || r.missing
+		missing = missing || r.missing
+	}
+	if missing {
+		// Entries removed since the directory was read or indexed
+		for _, t := range idx.Types {
+			kept := t.Entries[:0]
+			for _, e := range t.Entries {
+				if e.Stamp != "" {
+					kept = append(kept, e)
+				}
+			}
+			t.Entries = kept
+		}
+	}
+	return changed, nil
+}
+
+// save writes the index, logging rather than failing when it cannot
+func (idx *Index) save() {
+	data, err := json.Marshal(idx)
+	if err == nil {
+		err = atomicfile.WriteFile(Path(idx.root), data, 0644)
+	}
+	if err != nil {
+		slog.Debug("could not save library index", "path", Path(idx.root), "error", err)
+	}
+}
+
+// readNames lists the names in a type directory, which may not exist
+func readNames(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	names := make([]string, len(dirEntries))
+	for i, d := range dirEntries {
+		names[i] = d.Name()
+	}
+	return names, nil
+}
+
+// describe builds the entry for a resource
+func describe(resourceType, path string, info fs.FileInfo) Entry {
+	e := Entry{
+		Type:        resourceType,
+		Name:        info.Name(),
+		IsDir:       info.IsDir(),
+		Description: describeResource(path, info),
+		Tags:        nameTags(info.Name()),
+		Stamp:       entryStamp(path, info),
+	}
+	if !info.IsDir() {
+		e.Size = info.Size()
+		if resourceType == "personas" && strings.HasSuffix(e.Name, ".md") {
+			e.Persona = personaFrontmatter(path)
+		}
+	}
+	return e
+}
+
+// fileStamp identifies a version of a file by its size and modification time
+func fileStamp(info fs.FileInfo) string {
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// entryStamp identifies a version of a resource. A directory's description
+// comes from its README, so the README's stamp is included.
+func entryStamp(path string, info fs.FileInfo) string {
+	stamp := fileStamp(info)
+	if info.IsDir() {
+		if readme, err := os.Stat(filepath.Join(path, "README.md")); err == nil {
+			stamp += "/" + fileStamp(readme)
+		}
+	}
+	return stamp
+}
+
+// personaFrontmatter parses the YAML frontmatter of a persona file,
+// reading no further than its closing delimiter
+func personaFrontmatter(path string) *PersonaMeta {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), maxFrontmatterSize)
+	if !scanner.Scan() || scanner.Text() != "---" {
+		return nil
+	}
+	var frontmatter strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "---" {
+			var meta PersonaMeta
+			if err := yaml.Unmarshal([]byte(frontmatter.String()), &meta); err != nil {
+				return nil
+			}
+			return &meta
+		}
+		if frontmatter.Len()+len(line) > maxFrontmatterSize {
+			return nil
+		}
+		frontmatter.WriteString(line)
+		frontmatter.WriteByte('\n')
+	}
+	return nil
+}
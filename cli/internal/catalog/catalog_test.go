@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func names(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	lib := t.TempDir()
+	writeFile(t, filepath.Join(lib, "personas", "reviewer.md"), "---\nname: reviewer\nroles: [code-reviewer]\ntags: [go]\n---\n# Reviewer\n")
+	writeFile(t, filepath.Join(lib, "personas", "plain.md"), "# Plain\n")
+	writeFile(t, filepath.Join(lib, "templates", "react-app", "README.md"), "# React\n\nA React starter\n")
+
+	idx, err := Load(lib)
+	require.NoError(t, err)
+	assert.FileExists(t, Path(lib))
+	assert.Equal(t, []string{"plain.md", "reviewer.md"}, names(idx.Entries("personas")))
+	reviewer := idx.Entries("personas")[1]
+	require.NotNil(t, reviewer.Persona)
+	assert.Equal(t, []string{"code-reviewer"}, reviewer.Persona.Roles)
+	assert.Nil(t, idx.Entries("personas")[0].Persona)
+
+	templates := idx.Entries("templates")
+	require.Len(t, templates, 1)
+	assert.True(t, templates[0].IsDir)
+	assert.Equal(t, "A React starter", templates[0].Description)
+	assert.Equal(t, []string{"react"}, templates[0].Tags)
+	assert.Equal(t, filepath.Join(lib, "templates", "react-app"), idx.EntryPath(templates[0]))
+
+	t.Run("changed files are described again", func(t *testing.T) {
+		readme := filepath.Join(lib, "templates", "react-app", "README.md")
+		writeFile(t, readme, "# React\n\nA React and Vite starter\n")
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(readme, later, later))
+
+		idx, err := Load(lib)
+		require.NoError(t, err)
+		assert.Equal(t, "A React and Vite starter", idx.Entries("templates")[0].Description)
+	})
+
+	t.Run("added and removed entries", func(t *testing.T) {
+		writeFile(t, filepath.Join(lib, "personas", "architect.md"), "---\nname: architect\nroles: [architect]\n---\n")
+		require.NoError(t, os.Remove(filepath.Join(lib, "personas", "plain.md")))
+		later := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(lib, "personas"), later, later))
+
+		idx, err := Load(lib)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"architect.md", "reviewer.md"}, names(idx.Entries("personas")))
+	})
+
+	t.Run("corrupt index is rebuilt", func(t *testing.T) {
+		writeFile(t, Path(lib), "{not json")
+		idx, err := Load(lib)
+		require.NoError(t, err)
+		assert.Len(t, idx.Entries("personas"), 2)
+	})
+}
+
+func TestBuildRescans(t *testing.T) {
+	lib := t.TempDir()
+	path := filepath.Join(lib, "personas", "reviewer.md")
+	writeFile(t, path, "---\nroles: [tester]\n---\n")
+	_, err := Load(lib)
+	require.NoError(t, err)
+
+	// An edit that keeps the size and modification time goes unnoticed
+	// until the index is rebuilt
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	writeFile(t, path, "---\nroles: [author]\n---\n")
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+
+	idx, err := Load(lib)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tester"}, idx.Entries("personas")[0].Persona.Roles)
+
+	idx, err = Build(lib)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"author"}, idx.Entries("personas")[0].Persona.Roles)
+}
@@ -0,0 +1,121 @@
+package catalog
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// describeResource returns descriptive information about a resource
+func describeResource(path string, info fs.FileInfo) string {
+	// Try to read description from README
+	if info.IsDir() {
+		if line := readmeSummary(filepath.Join(path, "README.md")); line != "" {
+			if len(line) > 60 {
+				return line[:60] + "..."
+			}
+			return line
+		}
+
+		// Count items in directory
+		if entries, err := os.ReadDir(path); err == nil {
+			return fmt.Sprintf("%d items", len(entries))
+		}
+		return ""
+	}
+
+	// For files, show the type or size
+	switch strings.ToLower(filepath.Ext(info.Name())) {
+	case ".md":
+		return "Markdown document"
+	case ".yml", ".yaml":
+		return "Configuration file"
+	case ".sh":
+		return "Shell script"
+	case ".py":
+		return "Python script"
+	case ".js":
+		return "JavaScript file"
+	case ".go":
+		return "Go source"
+	}
+	switch size := info.Size(); {
+	case size < 1024:
+		return fmt.Sprintf("%d bytes", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// readmeSummary returns the first line of a README that is not a heading,
+// reading no further than that line
+func readmeSummary(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+// nameTags derives technology tags from a resource name
+func nameTags(name string) []string {
+	var tags []string
+	name = strings.ToLower(name)
+
+	// Common technology tags
+	if strings.Contains(name, "react") || strings.Contains(name, "jsx") {
+		tags = append(tags, "react")
+	}
+	if strings.Contains(name, "vue") {
+		tags = append(tags, "vue")
+	}
+	if strings.Contains(name, "angular") {
+		tags = append(tags, "angular")
+	}
+	if strings.Contains(name, "nextjs") || strings.Contains(name, "next") {
+		tags = append(tags, "nextjs")
+	}
+	if strings.Contains(name, "python") || strings.Contains(name, "py") {
+		tags = append(tags, "python")
+	}
+	if strings.Contains(name, "go") || strings.Contains(name, "golang") {
+		tags = append(tags, "go")
+	}
+	if strings.Contains(name, "javascript") || strings.Contains(name, "js") {
+		tags = append(tags, "javascript")
+	}
+	if strings.Contains(name, "typescript") || strings.Contains(name, "ts") {
+		tags = append(tags, "typescript")
+	}
+	if strings.Contains(name, "docker") {
+		tags = append(tags, "docker")
+	}
+	if strings.Contains(name, "api") || strings.Contains(name, "rest") {
+		tags = append(tags, "api")
+	}
+	if strings.Contains(name, "auth") {
+		tags = append(tags, "authentication")
+	}
+	if strings.Contains(name, "test") {
+		tags = append(tags, "testing")
+	}
+	if strings.Contains(name, "claude") || strings.Contains(name, "ai") {
+		tags = append(tags, "ai")
+	}
+
+	return tags
+}
@@ -0,0 +1,60 @@
+package catalog
+
+import (
+	"os"
+	"runtime"
+	"sync"
+)
+
+// scanWorkers bounds the resources checked and described in parallel
+var scanWorkers = min(runtime.NumCPU(), 8)
+
+// scanJob describes one resource into entry, unless stamp is still current
+type scanJob struct {
+	resourceType string
+	path         string
+	entry        *Entry
+	// stamp is the entry's recorded stamp, empty for new entries
+	stamp string
+}
+
+// scanResult is the outcome of a scanJob
+type scanResult struct {
+	changed bool
+	// missing resources no longer exist; their entry is cleared
+	missing bool
+}
+
+// runScan runs the jobs on a pool of workers. Every job writes to its own
+// entry, so results are in the order of jobs.
+func runScan(jobs []scanJob) []scanResult {
+	results := make([]scanResult, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < scanWorkers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				job := jobs[i]
+				info, err := os.Stat(job.path)
+				if err != nil {
+					*job.entry = Entry{}
+					results[i].missing = true
+					continue
+				}
+				if job.stamp != "" && entryStamp(job.path, info) == job.stamp {
+					continue
+				}
+				*job.entry = describe(job.resourceType, job.path, info)
+				results[i].changed = true
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// personaSeparator joins the personas of a role bound to several
+const personaSeparator = ", "
+
+// PersonaBindings maps roles to the personas that fill them. A role can be
+// bound to several personas, written in YAML as a list and kept in memory
+// in order, joined with ", ". Use SplitPersonas to read a binding.
+type PersonaBindings map[string]string
+
+// UnmarshalYAML accepts a persona name or a list of names for each role
+func (b *PersonaBindings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("persona_bindings must be a mapping of roles to personas")
+	}
+	bindings := make(PersonaBindings, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		role, value := node.Content[i].Value, node.Content[i+1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			bindings[role] = value.Value
+		case yaml.SequenceNode:
+			var names []string
+			if err := value.Decode(&names); err != nil {
+				return fmt.Errorf("persona_bindings.%s: %w", role, err)
+			}
+			bindings[role] = JoinPersonas(names)
+		default:
+			return fmt.Errorf("persona_bindings.%s must be a persona name or a list of names", role)
+		}
+	}
+	*b = bindings
+	return nil
+}
+
+// MarshalYAML writes roles bound to several personas as lists
+func (b PersonaBindings) MarshalYAML() (interface{}, error) {
+	out := make(map[string]interface{}, len(b))
+	for role, value := range b {
+		if names := SplitPersonas(value); len(names) > 1 {
+			out[role] = names
+		} else {
+			out[role] = value
+		}
+	}
+	return out, nil
+}
+
+// SplitPersonas returns the personas of a binding, in order
+func SplitPersonas(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// JoinPersonas returns the binding value for a list of personas
+func JoinPersonas(names []string) string {
+	return strings.Join(names, personaSeparator)
+}
+
+// WorkflowBindings returns the persona bindings in effect while a workflow
+// runs: persona_bindings with the workflow's overrides applied, and the
+// roles the overrides replaced or added
+func (c *NewConfig) WorkflowBindings(workflow string) (PersonaBindings, map[string]bool) {
+	bindings := make(PersonaBindings, len(c.PersonaBindings))
+	for role, persona := range c.PersonaBindings {
+		bindings[role] = persona
+	}
+	overridden := make(map[string]bool)
+	for role, persona := range c.Overrides[workflow] {
+		bindings[role] = persona
+		overridden[role] = true
+	}
+	return bindings, overridden
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPersonaBindingsYAML(t *testing.T) {
+	var cfg struct {
+		Bindings PersonaBindings `yaml:"persona_bindings"`
+	}
+	input := `persona_bindings:
+  architect: systems-architect
+  code-reviewer: [strict-reviewer, security-analyst]
+`
+	require.NoError(t, yaml.Unmarshal([]byte(input), &cfg))
+	assert.Equal(t, "systems-architect", cfg.Bindings["architect"])
+	assert.Equal(t, "strict-reviewer, security-analyst", cfg.Bindings["code-reviewer"])
+	assert.Equal(t, []string{"strict-reviewer", "security-analyst"}, SplitPersonas(cfg.Bindings["code-reviewer"]))
+
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "architect: systems-architect")
+	assert.Contains(t, string(data), "code-reviewer:\n        - strict-reviewer\n        - security-analyst")
+
+	err = yaml.Unmarshal([]byte("persona_bindings:\n  architect: {name: x}\n"), &cfg)
+	assert.EqualError(t, err, "persona_bindings.architect must be a persona name or a list of names")
+}
+
+func TestWorkflowBindings(t *testing.T) {
+	cfg := &NewConfig{
+		PersonaBindings: PersonaBindings{"test-engineer": "test-engineer-tdd", "architect": "systems-architect"},
+		Overrides: map[string]PersonaBindings{
+			"performance-workflow": {"test-engineer": "test-engineer-bdd"},
+		},
+	}
+
+	bindings, overridden := cfg.WorkflowBindings("performance-workflow")
+	assert.Equal(t, PersonaBindings{"test-engineer": "test-engineer-bdd", "architect": "systems-architect"}, bindings)
+	assert.Equal(t, map[string]bool{"test-engineer": true}, overridden)
+	assert.Equal(t, "test-engineer-tdd", cfg.PersonaBindings["test-engineer"])
+
+	bindings, overridden = cfg.WorkflowBindings("helix")
+	assert.Equal(t, "test-engineer-tdd", bindings["test-engineer"])
+	assert.Empty(t, overridden)
+}
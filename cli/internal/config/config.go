@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 // Type aliases for smooth transition
@@ -44,8 +43,16 @@ func Load() (*Config, error) {
 	return LoadWithWorkingDir(workingDir)
 }
 
-// LoadWithWorkingDir loads configuration from a specific working directory
+// LoadWithWorkingDir loads configuration from a specific working directory,
+// resolving the active profile from the environment
 func LoadWithWorkingDir(workingDir string) (*Config, error) {
+	return LoadWithProfile(workingDir, ActiveProfile())
+}
+
+// LoadWithProfile loads the layered configuration for a working directory
+// with the named profile merged over the project configuration. An empty
+// profile name skips the profile layer. See LoadLayers for the precedence.
+func LoadWithProfile(workingDir, profile string) (*Config, error) {
 	if workingDir == "" {
 		var err error
 		workingDir, err = os.Getwd()
@@ -54,30 +61,15 @@ func LoadWithWorkingDir(workingDir string) (*Config, error) {
 		}
 	}
 
-	// Use the new ConfigLoader to load from .ddx/config.yaml only
-	loader, err := NewConfigLoaderWithWorkingDir(workingDir)
+	layers, err := LoadLayers(workingDir, profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create config loader: %w", err)
-	}
-
-	config, err := loader.LoadConfig()
-	if err != nil {
-		// If no config file exists, return default config
-		if os.IsNotExist(err) || strings.Contains(err.Error(), "no configuration file found") {
-			config = DefaultNewConfig()
-		} else {
-			return nil, err
-		}
+		return nil, err
 	}
+	config := MergeLayers(layers)
 
 	// Apply defaults to ensure complete configuration
 	config.ApplyDefaults()
 
-	// Override library path with environment variable if set
-	if envLibraryPath := os.Getenv("DDX_LIBRARY_BASE_PATH"); envLibraryPath != "" {
-		config.Library.Path = envLibraryPath
-	}
-
 	// Validate the final configuration
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -117,6 +109,34 @@ func (c *Config) Validate() error {
 			})
 		}
 	}
+	if c.Library != nil {
+		seen := map[string]bool{MainSource: true}
+		for i, source := range c.Library.Sources {
+			field := fmt.Sprintf("library.sources[%d]", i)
+			switch {
+			case !IsSourceName(source.Name):
+				errors = append(errors, &ConfigError{
+					Field:      field + ".name",
+					Message:    fmt.Sprintf("invalid library source name %q", source.Name),
+					Suggestion: "use lowercase letters, digits and dashes, such as 'internal'",
+				})
+			case seen[source.Name]:
+				errors = append(errors, &ConfigError{
+					Field:      field + ".name",
+					Message:    fmt.Sprintf("library source name %q is already used", source.Name),
+					Suggestion: fmt.Sprintf("give each source its own name; %q is the main library", MainSource),
+				})
+			}
+			seen[source.Name] = true
+			if source.Path == "" {
+				errors = append(errors, &ConfigError{
+					Field:      field + ".path",
+					Message:    "library source path is required",
+					Suggestion: "add the directory the source is kept in, such as '.ddx/libraries/" + source.Name + "'",
+				})
+			}
+		}
+	}
 
 	if len(errors) > 0 {
 		return &ValidationError{Errors: errors}
@@ -128,26 +148,143 @@ func (c *Config) Validate() error {
 // Merge combines this config with another, with the other taking precedence
 func (c *Config) Merge(other *Config) *Config {
 	result := &Config{
-		Version: c.Version,
+		Version:       c.Version,
+		Interpolation: c.Interpolation,
+		Workflows: WorkflowsConfig{
+			Active:   append([]string(nil), c.Workflows.Active...),
+			SafeWord: c.Workflows.SafeWord,
+			DocsPath: c.Workflows.DocsPath,
+		},
 	}
 
 	// Copy library configuration from base
 	if c.Library != nil {
 		result.Library = &LibraryConfig{
-			Path: c.Library.Path,
+			Path:    c.Library.Path,
+			Include: append([]string(nil), c.Library.Include...),
+			Exclude: append([]string(nil), c.Library.Exclude...),
+			Sources: copySources(c.Library.Sources),
 		}
 		if c.Library.Repository != nil {
 			result.Library.Repository = &RepositoryConfig{
 				URL:    c.Library.Repository.URL,
 				Branch: c.Library.Repository.Branch,
+				Ref:    c.Library.Repository.Ref,
 			}
 		}
 	}
 
+	// Copy remaining sections from base
+	if c.System != nil {
+		result.System = &SystemConfig{MetaPrompt: c.System.MetaPrompt}
+	}
+	if c.UpdateCheck != nil {
+		result.UpdateCheck = copyUpdateCheck(c.UpdateCheck)
+	}
+	if c.Updates != nil {
+		updates := *c.Updates
+		result.Updates = &updates
+	}
+	if c.Agents != nil {
+		agents := *c.Agents
+		agents.Args = append([]string(nil), c.Agents.Args...)
+		result.Agents = &agents
+	}
+	if c.AI != nil {
+		result.AI = &AIConfig{Targets: append([]string(nil), c.AI.Targets...)}
+	}
+	if c.Injection != nil {
+		injection := *c.Injection
+		injection.DisabledPaths = append([]string(nil), c.Injection.DisabledPaths...)
+		result.Injection = &injection
+	}
+	if c.PersonaBindings != nil {
+		result.PersonaBindings = make(map[string]string, len(c.PersonaBindings))
+		for role, persona := range c.PersonaBindings {
+			result.PersonaBindings[role] = persona
+		}
+	}
+	if c.Overrides != nil {
+		result.Overrides = make(map[string]PersonaBindings, len(c.Overrides))
+		for workflow, bindings := range c.Overrides {
+			result.Overrides[workflow] = make(PersonaBindings, len(bindings))
+			for role, persona := range bindings {
+				result.Overrides[workflow][role] = persona
+			}
+		}
+	}
+	if c.Source != nil {
+		source := *c.Source
+		result.Source = &source
+	}
+	if c.SettingsSync != nil {
+		settingsSync := *c.SettingsSync
+		result.SettingsSync = &settingsSync
+	}
+	if c.History != nil {
+		history := *c.History
+		if c.History.Enabled != nil {
+			enabled := *c.History.Enabled
+			history.Enabled = &enabled
+		}
+		result.History = &history
+	}
+	if c.Telemetry != nil {
+		telemetry := *c.Telemetry
+		if c.Telemetry.Enabled != nil {
+			enabled := *c.Telemetry.Enabled
+			telemetry.Enabled = &enabled
+		}
+		result.Telemetry = &telemetry
+	}
+	if c.Network != nil {
+		network := *c.Network
+		if c.Network.Retries != nil {
+			retries := *c.Network.Retries
+			network.Retries = &retries
+		}
+		if c.Network.Offline != nil {
+			offline := *c.Network.Offline
+			network.Offline = &offline
+		}
+		result.Network = &network
+	}
+	if c.Backups != nil {
+		result.Backups = copyBackups(c.Backups)
+	}
+	if c.Gitignore != nil {
+		result.Gitignore = mergeGitignore(nil, c.Gitignore)
+	}
+	if c.Prompts != nil {
+		result.Prompts = make(map[string]string, len(c.Prompts))
+		for alias, path := range c.Prompts {
+			result.Prompts[alias] = path
+		}
+	}
+	if c.Hooks != nil {
+		result.Hooks = make(map[string]bool, len(c.Hooks))
+		for name, enabled := range c.Hooks {
+			result.Hooks[name] = enabled
+		}
+	}
+	if c.Exec != nil {
+		result.Exec = &ExecConfig{
+			Allow: append([]string(nil), c.Exec.Allow...),
+			Env:   append([]string(nil), c.Exec.Env...),
+		}
+	}
+
+	if other == nil {
+		return result
+	}
+
 	// Override with other's values
 	if other.Version != "" {
 		result.Version = other.Version
 	}
+	if other.Interpolation != "" {
+		result.Interpolation = other.Interpolation
+	}
 	if other.Library != nil {
 		if result.Library == nil {
 			result.Library = &LibraryConfig{}
@@ -165,9 +302,264 @@ func (c *Config) Merge(other *Config) *Config {
 			if other.Library.Repository.Branch != "" {
 				result.Library.Repository.Branch = other.Library.Repository.Branch
 			}
+			if other.Library.Repository.Ref != "" {
+				result.Library.Repository.Ref = other.Library.Repository.Ref
+			}
+		}
+		if len(other.Library.Include) > 0 {
+			result.Library.Include = append([]string(nil), other.Library.Include...)
+		}
+		if len(other.Library.Exclude) > 0 {
+			result.Library.Exclude = append([]string(nil), other.Library.Exclude...)
+		}
+		if len(other.Library.Sources) > 0 {
+			result.Library.Sources = copySources(other.Library.Sources)
+		}
+	}
+	if len(other.Workflows.Active) > 0 {
+		result.Workflows.Active = append([]string(nil), other.Workflows.Active...)
+	}
+	if other.Workflows.SafeWord != "" {
+		result.Workflows.SafeWord = other.Workflows.SafeWord
+	}
+	if other.Workflows.DocsPath != "" {
+		result.Workflows.DocsPath = other.Workflows.DocsPath
+	}
+	if other.System != nil && other.System.MetaPrompt != nil {
+		if result.System == nil {
+			result.System = &SystemConfig{}
 		}
+		result.System.MetaPrompt = other.System.MetaPrompt
 	}
+	if other.UpdateCheck != nil {
+		if result.UpdateCheck == nil {
+			result.UpdateCheck = &UpdateCheckConfig{}
+		}
+		if other.UpdateCheck.Enabled != nil {
+			enabled := *other.UpdateCheck.Enabled
+			result.UpdateCheck.Enabled = &enabled
+		}
+		if other.UpdateCheck.Frequency != "" {
+			result.UpdateCheck.Frequency = other.UpdateCheck.Frequency
+		}
+	}
+	if other.Updates != nil && other.Updates.Check != "" {
+		result.Updates = &UpdatesConfig{Check: other.Updates.Check}
+	}
+	if other.Agents != nil {
+		if result.Agents == nil {
+			result.Agents = &AgentsConfig{}
+		}
+		if other.Agents.Preset != "" {
+			result.Agents.Preset = other.Agents.Preset
+		}
+		if other.Agents.Model != "" {
+			result.Agents.Model = other.Agents.Model
+		}
+		if other.Agents.Command != "" {
+			result.Agents.Command = other.Agents.Command
+		}
+		if len(other.Agents.Args) > 0 {
+			result.Agents.Args = append([]string(nil), other.Agents.Args...)
+		}
+		if other.Agents.Input != "" {
+			result.Agents.Input = other.Agents.Input
+		}
+	}
+	if other.AI != nil && len(other.AI.Targets) > 0 {
+		result.AI = &AIConfig{Targets: append([]string(nil), other.AI.Targets...)}
+	}
+	if other.Injection != nil {
+		if result.Injection == nil {
+			result.Injection = &InjectionConfig{}
+		}
+		if other.Injection.ClaudeFile != "" {
+			result.Injection.ClaudeFile = other.Injection.ClaudeFile
+		}
+		if len(other.Injection.DisabledPaths) > 0 {
+			result.Injection.DisabledPaths = append([]string(nil), other.Injection.DisabledPaths...)
+		}
+	}
+	if len(other.PersonaBindings) > 0 {
+		if result.PersonaBindings == nil {
+			result.PersonaBindings = make(map[string]string, len(other.PersonaBindings))
+		}
+		for role, persona := range other.PersonaBindings {
+			result.PersonaBindings[role] = persona
+		}
+	}
+	if len(other.Overrides) > 0 {
+		if result.Overrides == nil {
+			result.Overrides = make(map[string]PersonaBindings, len(other.Overrides))
+		}
+		for workflow, bindings := range other.Overrides {
+			if result.Overrides[workflow] == nil {
+				result.Overrides[workflow] = make(PersonaBindings, len(bindings))
+			}
+			for role, persona := range bindings {
+				result.Overrides[workflow][role] = persona
+			}
+		}
+	}
+	if other.Source != nil {
+		source := *other.Source
+		result.Source = &source
+	}
+	if other.SettingsSync != nil {
+		if result.SettingsSync == nil {
+			result.SettingsSync = &SettingsSyncConfig{}
+		}
+		if other.SettingsSync.Repository != "" {
+			result.SettingsSync.Repository = other.SettingsSync.Repository
+		}
+		if other.SettingsSync.Branch != "" {
+			result.SettingsSync.Branch = other.SettingsSync.Branch
+		}
+	}
+	if other.History != nil {
+		if result.History == nil {
+			result.History = &HistoryConfig{}
+		}
+		if other.History.Enabled != nil {
+			enabled := *other.History.Enabled
+			result.History.Enabled = &enabled
+		}
+	}
+	if other.Telemetry != nil {
+		if result.Telemetry == nil {
+			result.Telemetry = &TelemetryConfig{}
+		}
+		if other.Telemetry.Enabled != nil {
+			enabled := *other.Telemetry.Enabled
+			result.Telemetry.Enabled = &enabled
+		}
+		if other.Telemetry.Endpoint != "" {
+			result.Telemetry.Endpoint = other.Telemetry.Endpoint
+		}
+	}
+	if other.Network != nil {
+		if result.Network == nil {
+			result.Network = &NetworkConfig{}
+		}
+		if other.Network.CAFile != "" {
+			result.Network.CAFile = other.Network.CAFile
+		}
+		if other.Network.Timeout != "" {
+			result.Network.Timeout = other.Network.Timeout
+		}
+		if other.Network.Retries != nil {
+			retries := *other.Network.Retries
+			result.Network.Retries = &retries
+		}
+		if other.Network.Backoff != "" {
+			result.Network.Backoff = other.Network.Backoff
+		}
+		if other.Network.Offline != nil {
+			offline := *other.Network.Offline
+			result.Network.Offline = &offline
+		}
+	}
+	if other.Backups != nil {
+		if result.Backups == nil {
+			result.Backups = &BackupsConfig{}
+		}
+		if other.Backups.Keep != nil {
+			keep := *other.Backups.Keep
+			result.Backups.Keep = &keep
+		}
+		if other.Backups.MaxAge != "" {
+			result.Backups.MaxAge = other.Backups.MaxAge
+		}
+	}
+	if other.Gitignore != nil {
+		result.Gitignore = mergeGitignore(result.Gitignore, other.Gitignore)
+	}
+	if len(other.Prompts) > 0 {
+		if result.Prompts == nil {
+			result.Prompts = make(map[string]string, len(other.Prompts))
+		}
+		for alias, path := range other.Prompts {
+			result.Prompts[alias] = path
+		}
+	}
+	if len(other.Hooks) > 0 {
+		if result.Hooks == nil {
+			result.Hooks = make(map[string]bool, len(other.Hooks))
+		}
+		for name, enabled := range other.Hooks {
+			result.Hooks[name] = enabled
+		}
+	}
+	if other.Exec != nil {
+		if result.Exec == nil {
+			result.Exec = &ExecConfig{}
+		}
+		if len(other.Exec.Allow) > 0 {
+			result.Exec.Allow = append([]string(nil), other.Exec.Allow...)
+		}
+		if len(other.Exec.Env) > 0 {
+			result.Exec.Env = append([]string(nil), other.Exec.Env...)
+		}
+	}
+
+	return result
+}
+
+// copyUpdateCheck returns a deep copy of the update_check section
+func copyUpdateCheck(u *UpdateCheckConfig) *UpdateCheckConfig {
+	result := &UpdateCheckConfig{Frequency: u.Frequency}
+	if u.Enabled != nil {
+		enabled := *u.Enabled
+		result.Enabled = &enabled
+	}
+	return result
+}
 
+// copySources returns a deep copy of library sources
+func copySources(sources []LibrarySource) []LibrarySource {
+	if sources == nil {
+		return nil
+	}
+	result := make([]LibrarySource, len(sources))
+	for i, source := range sources {
+		result[i] = source
+		if source.Repository != nil {
+			repo := *source.Repository
+			result[i].Repository = &repo
+		}
+	}
+	return result
+}
+
+// copyBackups returns a deep copy of a backups section
+func copyBackups(b *BackupsConfig) *BackupsConfig {
+	backups := *b
+	if b.Keep != nil {
+		keep := *b.Keep
+		backups.Keep = &keep
+	}
+	return &backups
+}
+
+// mergeGitignore returns a copy of base with the settings of other on top;
+// ignore entries are merged by artifact
+func mergeGitignore(base, other *GitignoreConfig) *GitignoreConfig {
+	result := &GitignoreConfig{}
+	for _, g := range []*GitignoreConfig{base, other} {
+		if g == nil {
+			continue
+		}
+		if g.Manage != nil {
+			manage := *g.Manage
+			result.Manage = &manage
+		}
+		for name, ignored := range g.Ignore {
+			if result.Ignore == nil {
+				result.Ignore = make(map[string]bool, len(g.Ignore))
+			}
+			result.Ignore[name] = ignored
+		}
+	}
 	return result
 }
 
@@ -223,6 +615,10 @@ func ResolveLibraryResource(resourcePath, configPath, workingDir string) (string
 
 // LoadFromFile loads configuration from a specific file path
 func LoadFromFile(configPath string) (*Config, error) {
+	if absPath, err := filepath.Abs(configPath); err == nil {
+		configPath = absPath
+	}
+
 	// Use ConfigLoader to load the file
 	loader, err := NewConfigLoaderWithWorkingDir(filepath.Dir(configPath))
 	if err != nil {
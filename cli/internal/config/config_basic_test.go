@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -192,3 +193,42 @@ repository:
 	assert.Error(t, err)
 	assert.Nil(t, config)
 }
+
+// TestUpdateCheckInterval validates updates.check and its precedence over update_check
+func TestUpdateCheckInterval(t *testing.T) {
+	t.Parallel()
+	day := 24 * time.Hour
+	off, on := false, true
+	tests := []struct {
+		name   string
+		legacy *UpdateCheckConfig
+		check  string
+		want   time.Duration
+	}{
+		{name: "default", want: day},
+		{name: "weekly", check: UpdatesWeekly, want: 7 * day},
+		{name: "never", check: UpdatesNever, want: 0},
+		{name: "legacy disabled", legacy: &UpdateCheckConfig{Enabled: &off}, want: 0},
+		{name: "legacy frequency", legacy: &UpdateCheckConfig{Enabled: &on, Frequency: "12h"}, want: 12 * time.Hour},
+		{name: "updates wins", legacy: &UpdateCheckConfig{Enabled: &off}, check: UpdatesDaily, want: day},
+	}
+	for _, tt := range tests {
+		cfg := &NewConfig{UpdateCheck: tt.legacy}
+		if tt.check != "" {
+			cfg.Updates = &UpdatesConfig{Check: tt.check}
+		}
+		assert.Equal(t, tt.want, cfg.UpdateCheckInterval(), tt.name)
+	}
+}
+
+func TestRepositoryRevision(t *testing.T) {
+	repo := &RepositoryConfig{URL: "https://github.com/easel/ddx-library", Branch: "main"}
+	assert.Equal(t, "main", repo.Revision())
+
+	repo.Ref = "v1.4.0"
+	assert.Equal(t, "v1.4.0", repo.Revision())
+
+	merged := DefaultNewConfig().Merge(&NewConfig{Library: &LibraryConfig{Repository: repo}})
+	assert.Equal(t, "v1.4.0", merged.Library.Repository.Ref)
+	assert.Equal(t, "main", merged.Library.Repository.Branch)
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Deprecation describes a configuration field that was renamed or retired
+type Deprecation struct {
+	// Old is the dotted key of the deprecated field
+	Old string
+	// New is the dotted key that replaces it; empty when the field was removed
+	New string
+	// ScalarOnly restricts the rule to fields holding a single value, for
+	// fields whose old form was a plain string
+	ScalarOnly bool
+	// Note explains what happened to a removed field
+	Note string
+}
+
+// Deprecations lists the retired configuration fields in the order they
+// are applied. Nested fields come before their parents so that a moved
+// section no longer contains them.
+var Deprecations = []Deprecation{
+	{Old: "repository", New: "library.repository.url", ScalarOnly: true},
+	{Old: "branch", New: "library.repository.branch"},
+	{Old: "repository.subtree_prefix", New: "library.path"},
+	{Old: "repository.remote", Note: "the library is fetched from library.repository.url"},
+	{Old: "repository.protocol", Note: "the protocol is taken from library.repository.url"},
+	{Old: "repository", New: "library.repository"},
+	{Old: "library_base_path", New: "library.path"},
+	{Old: "library_path", New: "library.path"},
+	{Old: "variables", Note: "variable values are kept in .ddx/variables.yml"},
+}
+
+// Migration is a deprecated field found in a configuration document
+type Migration struct {
+	Deprecation
+	// Conflict is set when the replacement was already present, in which
+	// case the old value is dropped and the replacement kept
+	Conflict bool
+}
+
+// String describes the migration for warnings and reports
+func (m Migration) String() string {
+	switch {
+	case m.New == "":
+		return fmt.Sprintf("'%s' is no longer supported and is ignored (%s)", m.Old, m.Note)
+	case m.Conflict:
+		return fmt.Sprintf("'%s' is deprecated; use '%s' (values already set there take precedence)", m.Old, m.New)
+	default:
+		return fmt.Sprintf("'%s' is deprecated; use '%s'", m.Old, m.New)
+	}
+}
+
+// MigrateDocument rewrites deprecated fields in a YAML document to their
+// current names, returning the changes made in the order applied
+func MigrateDocument(doc *yaml.Node) []Migration {
+	if rootMapping(doc) == nil {
+		return nil
+	}
+
+	var migrations []Migration
+	for _, dep := range Deprecations {
+		old := lookupNode(doc, dep.Old)
+		if old == nil || (dep.ScalarOnly && old.Kind != yaml.ScalarNode) {
+			continue
+		}
+		removeNode(doc, dep.Old)
+
+		migration := Migration{Deprecation: dep}
+		if dep.New != "" && old.Tag != "!!null" {
+			migration.Conflict = !placeNode(doc, dep.New, old)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+// MigrateFile finds the deprecated fields in a configuration file and, when
+// write is set, saves the file with them rewritten. Comments and the order
+// of other fields are preserved. A missing file has nothing to migrate.
+func MigrateFile(path string, write bool) ([]Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML from %s: %w", path, err)
+	}
+	migrations := MigrateDocument(&doc)
+	if !write || len(migrations) == 0 {
+		return migrations, nil
+	}
+
+	if err := UpdateFile(path, func(target *yaml.Node) error {
+		*target = doc
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return migrations, nil
+}
+
+// migrateData upgrades an older format and rewrites deprecated fields in
+// raw configuration data. The data is returned unchanged when nothing is
+// outdated or it does not parse, leaving the error to the caller's own
+// parsing. outdated is the older format version that was upgraded, if any.
+func migrateData(data []byte) (migrated []byte, migrations []Migration, outdated string) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || rootMapping(&doc) == nil {
+		return data, nil, ""
+	}
+	version := documentVersion(&doc)
+	steps, _ := upgradeFormat(&doc, version)
+	migrations = MigrateDocument(&doc)
+	if len(steps) > 0 {
+		outdated = version
+	}
+	if len(migrations) == 0 && len(steps) == 0 {
+		return data, nil, ""
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return data, nil, ""
+	}
+	_ = encoder.Close()
+	return buf.Bytes(), migrations, outdated
+}
+
+// deprecationOutput receives deprecation warnings
+var deprecationOutput io.Writer = os.Stderr
+
+// warnedDeprecations records the warnings already shown by this process
+var warnedDeprecations sync.Map
+
+// MigrateDeprecated maps an older format and deprecated fields in a
+// configuration file to their replacements, warning once per file and field
+func MigrateDeprecated(path string, data []byte) []byte {
+	migrated, migrations, outdated := migrateData(data)
+	if outdated != "" {
+		if _, seen := warnedDeprecations.LoadOrStore(path+"\x00version", true); !seen {
+			_, _ = fmt.Fprintf(deprecationOutput, "⚠️  %s: uses configuration format %s (run 'ddx config migrate' to upgrade the file)\n", path, outdated)
+		}
+	}
+	for _, m := range migrations {
+		if _, seen := warnedDeprecations.LoadOrStore(path+"\x00"+m.Old, true); seen {
+			continue
+		}
+		_, _ = fmt.Fprintf(deprecationOutput, "⚠️  %s: %s (run 'ddx config migrate' to update the file)\n", path, m)
+	}
+	return migrated
+}
+
+// lookupNode returns the value node at a dotted key, or nil when unset
+func lookupNode(doc *yaml.Node, key string) *yaml.Node {
+	current := rootMapping(doc)
+	for _, segment := range strings.Split(key, ".") {
+		if current == nil || current.Kind != yaml.MappingNode {
+			return nil
+		}
+		i := mappingIndex(current, segment)
+		if i < 0 {
+			return nil
+		}
+		current = current.Content[i+1]
+	}
+	return current
+}
+
+// removeNode deletes a dotted key, pruning parent sections left empty. It
+// reports whether the key was present.
+func removeNode(doc *yaml.Node, key string) bool {
+	segments := strings.Split(key, ".")
+	parents := []*yaml.Node{rootMapping(doc)}
+	for _, segment := range segments[:len(segments)-1] {
+		current := parents[len(parents)-1]
+		i := mappingIndex(current, segment)
+		if current == nil || i < 0 || current.Content[i+1].Kind != yaml.MappingNode {
+			return false
+		}
+		parents = append(parents, current.Content[i+1])
+	}
+
+	parent := parents[len(parents)-1]
+	i := mappingIndex(parent, segments[len(segments)-1])
+	if i < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+
+	// Remove sections that no longer hold any keys
+	for depth := len(parents) - 1; depth > 0 && len(parents[depth].Content) == 0; depth-- {
+		above := parents[depth-1]
+		j := mappingIndex(above, segments[depth-1])
+		above.Content = append(above.Content[:j], above.Content[j+2:]...)
+	}
+	return true
+}
+
+// placeNode stores value at a dotted key. When the key is already set,
+// sections are merged without overwriting existing fields; the return is
+// false if any part of value was dropped because it was already set.
+func placeNode(doc *yaml.Node, key string, value *yaml.Node) bool {
+	parent, last, err := walkNode(doc, key, true)
+	if err != nil {
+		return false
+	}
+	i := mappingIndex(parent, last)
+	if i < 0 {
+		parent.Content = append(parent.Content, scalarNode(last), value)
+		return true
+	}
+
+	existing := parent.Content[i+1]
+	if existing.Tag == "!!null" {
+		parent.Content[i+1] = value
+		return true
+	}
+	if existing.Kind != yaml.MappingNode || value.Kind != yaml.MappingNode {
+		return false
+	}
+	complete := true
+	for j := 0; j+1 < len(value.Content); j += 2 {
+		if mappingIndex(existing, value.Content[j].Value) >= 0 {
+			complete = false
+			continue
+		}
+		existing.Content = append(existing.Content, value.Content[j], value.Content[j+1])
+	}
+	return complete
+}
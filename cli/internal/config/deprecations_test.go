@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// migrateString applies MigrateDocument to YAML text
+func migrateString(t *testing.T, content string) (string, []Migration) {
+	t.Helper()
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(content), &doc))
+	migrations := MigrateDocument(&doc)
+	out, err := yaml.Marshal(&doc)
+	require.NoError(t, err)
+	return string(out), migrations
+}
+
+// TestMigrateDocument tests that deprecated fields move to their replacements
+func TestMigrateDocument(t *testing.T) {
+	t.Run("repository section", func(t *testing.T) {
+		out, migrations := migrateString(t, `version: "1.0"
+repository:
+  url: https://github.com/team/library
+  branch: main
+  subtree_prefix: vendor/library
+  remote: ddx-master
+`)
+		var cfg NewConfig
+		require.NoError(t, yaml.Unmarshal([]byte(out), &cfg))
+		require.NotNil(t, cfg.Library)
+		assert.Equal(t, "https://github.com/team/library", cfg.Library.Repository.URL)
+		assert.Equal(t, "main", cfg.Library.Repository.Branch)
+		assert.Equal(t, "vendor/library", cfg.Library.Path)
+		assert.NotContains(t, out, "remote")
+
+		var old []string
+		for _, m := range migrations {
+			old = append(old, m.Old)
+		}
+		assert.Equal(t, []string{"repository.subtree_prefix", "repository.remote", "repository"}, old)
+	})
+
+	t.Run("scalar repository and branch", func(t *testing.T) {
+		out, migrations := migrateString(t, `version: "1.0"
+repository: https://github.com/team/library
+branch: develop
+`)
+		assert.Len(t, migrations, 2)
+		var cfg NewConfig
+		require.NoError(t, yaml.Unmarshal([]byte(out), &cfg))
+		assert.Equal(t, "https://github.com/team/library", cfg.Library.Repository.URL)
+		assert.Equal(t, "develop", cfg.Library.Repository.Branch)
+	})
+
+	t.Run("replacement already set", func(t *testing.T) {
+		out, migrations := migrateString(t, `version: "1.0"
+library_path: ./old
+library:
+  path: ./current
+`)
+		require.Len(t, migrations, 1)
+		assert.True(t, migrations[0].Conflict)
+		assert.Contains(t, out, "./current")
+		assert.NotContains(t, out, "./old")
+	})
+
+	t.Run("removed field", func(t *testing.T) {
+		out, migrations := migrateString(t, `version: "1.0"
+variables:
+  project_name: demo
+`)
+		require.Len(t, migrations, 1)
+		assert.Empty(t, migrations[0].New)
+		assert.Contains(t, migrations[0].String(), "no longer supported")
+		assert.NotContains(t, out, "variables")
+	})
+
+	t.Run("current configuration", func(t *testing.T) {
+		_, migrations := migrateString(t, `version: "1.0"
+library:
+  path: .ddx/library
+`)
+		assert.Empty(t, migrations)
+	})
+}
+
+// TestLoadWithWorkingDir_DeprecatedFields tests that deprecated fields load
+// with a single warning instead of failing validation
+func TestLoadWithWorkingDir_DeprecatedFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+library_base_path: ./library
+repository:
+  url: https://github.com/team/library
+  branch: stable
+`)
+
+	var warnings bytes.Buffer
+	original := deprecationOutput
+	deprecationOutput = &warnings
+	t.Cleanup(func() { deprecationOutput = original })
+
+	for i := 0; i < 2; i++ {
+		cfg, err := LoadWithWorkingDir(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, "./library", cfg.Library.Path)
+		assert.Equal(t, "https://github.com/team/library", cfg.Library.Repository.URL)
+		assert.Equal(t, "stable", cfg.Library.Repository.Branch)
+	}
+
+	assert.Equal(t, 1, strings.Count(warnings.String(), "'repository' is deprecated"))
+	assert.Equal(t, 1, strings.Count(warnings.String(), "'library_base_path' is deprecated"))
+	assert.Contains(t, warnings.String(), "ddx config migrate")
+}
+
+// TestMigrateFile tests that writing a migration keeps comments and
+// produces a valid file
+func TestMigrateFile(t *testing.T) {
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `# team settings
+version: "1.0"
+repository:
+  url: https://github.com/team/library # shared library
+  branch: main
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+`)
+	path := filepath.Join(projectDir, ".ddx", "config.yaml")
+
+	migrations, err := MigrateFile(path, false)
+	require.NoError(t, err)
+	assert.Len(t, migrations, 1)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\nrepository:", "a preview must not change the file")
+
+	_, err = MigrateFile(path, true)
+	require.NoError(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# team settings")
+	assert.Contains(t, string(data), "# shared library")
+
+	validator, err := NewValidator()
+	require.NoError(t, err)
+	assert.NoError(t, validator.Validate(data))
+
+	migrations, err = MigrateFile(path, false)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+
+	migrations, err = MigrateFile(filepath.Join(projectDir, "missing.yaml"), true)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceFile describes one source of the layered configuration as
+// written, so that a file which fails to load can still be explained
+type SourceFile struct {
+	Source string `json:"source"`
+	// Path is the file, or the environment variable for env sources
+	Path  string `json:"path"`
+	Found bool   `json:"found"`
+	// Error is why the file could not be parsed or does not match the
+	// schema, apart from its unknown keys
+	Error string `json:"error,omitempty"`
+	// Unknown are keys that are not part of the configuration
+	Unknown []UnknownKey `json:"unknown,omitempty"`
+	// Deprecated describes the older format and renamed fields that are
+	// mapped when the file is loaded
+	Deprecated []string `json:"deprecated,omitempty"`
+}
+
+// UnknownKey is a key in a configuration file that ddx does not read
+type UnknownKey struct {
+	Key  string `json:"key"`
+	Line int    `json:"line"`
+}
+
+// InspectSources examines every configuration source that applies to the
+// working directory, ordered from lowest to highest precedence like
+// LoadLayers. Files that do not exist are included with Found unset.
+func InspectSources(workingDir, profile string) ([]SourceFile, error) {
+	loader, err := NewConfigLoaderWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config loader: %w", err)
+	}
+
+	sources := []SourceFile{{Source: SourceDefault, Found: true}}
+	projectPath := filepath.Join(workingDir, ".ddx", "config.yaml")
+	if globalPath, err := GlobalConfigPath(); err == nil && !samePath(globalPath, projectPath) {
+		sources = append(sources, loader.inspectFile(SourceGlobal, globalPath, true))
+	}
+	sources = append(sources, loader.inspectFile(SourceProject, projectPath, false))
+	if profile != "" {
+		sources = append(sources, loader.inspectFile(SourceProfile, ProfilePath(workingDir, profile), true))
+	}
+	sources = append(sources, loader.inspectFile(SourceLocal, LocalConfigPath(workingDir), true))
+	for _, env := range envOverrides {
+		if os.Getenv(env.Name) != "" {
+			sources = append(sources, SourceFile{Source: SourceEnv, Path: env.Name, Found: true})
+		}
+	}
+	return sources, nil
+}
+
+// inspectFile reads one configuration file. Partial files, the global and
+// override files, may leave out the version.
+func (cl *ConfigLoader) inspectFile(source, path string, partial bool) SourceFile {
+	file := SourceFile{Source: source, Path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file
+	}
+	file.Found = true
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	migrated, migrations, outdated := migrateData(data)
+	if outdated != "" && !partial {
+		file.Deprecated = append(file.Deprecated, fmt.Sprintf("uses configuration format %s; the current format is %s", outdated, CurrentVersion))
+	}
+	for _, m := range migrations {
+		file.Deprecated = append(file.Deprecated, m.String())
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(migrated, &doc); err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	if rootMapping(&doc) == nil {
+		return file
+	}
+	collectUnknown(rootMapping(&doc), reflect.TypeOf(NewConfig{}), "", &file.Unknown)
+	if len(migrations) > 0 || outdated != "" {
+		// Line numbers refer to the file as written, not the migrated copy
+		var original yaml.Node
+		_ = yaml.Unmarshal(data, &original)
+		for i := range file.Unknown {
+			file.Unknown[i].Line = keyLine(&original, file.Unknown[i].Key)
+		}
+	}
+
+	// Unknown keys are reported on their own, so validate the rest
+	for _, unknown := range file.Unknown {
+		removeNode(&doc, unknown.Key)
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	if err := encoder.Encode(&doc); err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	_ = encoder.Close()
+	if partial {
+		err = cl.validatePartial(buf.Bytes())
+	} else {
+		err = cl.validator.Validate(buf.Bytes())
+	}
+	if err != nil {
+		file.Error = err.Error()
+	}
+	return file
+}
+
+// collectUnknown records the keys of a mapping that the configuration type
+// t has no field for, descending into the sections it does have
+func collectUnknown(mapping *yaml.Node, t reflect.Type, prefix string, unknown *[]UnknownKey) {
+	t = derefType(t)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, value := mapping.Content[i], mapping.Content[i+1]
+		key := joinKey(prefix, keyNode.Value)
+		var next reflect.Type
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByYAMLName(t, keyNode.Value)
+			if !ok {
+				*unknown = append(*unknown, UnknownKey{Key: key, Line: keyNode.Line})
+				continue
+			}
+			next = field.Type
+		case reflect.Map:
+			next = t.Elem()
+		default:
+			continue
+		}
+		if value.Kind == yaml.MappingNode {
+			collectUnknown(value, next, key, unknown)
+		}
+	}
+}
+
+// keyLine returns the line of a dotted key in a document, 0 when absent
+func keyLine(doc *yaml.Node, key string) int {
+	segments := strings.Split(key, ".")
+	current := rootMapping(doc)
+	for i, segment := range segments {
+		if current == nil || current.Kind != yaml.MappingNode {
+			return 0
+		}
+		j := mappingIndex(current, segment)
+		if j < 0 {
+			return 0
+		}
+		if i == len(segments)-1 {
+			return current.Content[j].Line
+		}
+		current = current.Content[j+1]
+	}
+	return 0
+}
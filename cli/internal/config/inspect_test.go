@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInspectSources tests that unknown, deprecated and invalid fields are
+// reported per file without the files having to load
+func TestInspectSources(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("DDX_LIBRARY_BASE_PATH", "")
+
+	writeProjectConfig(t, projectDir, `version: "1.0"
+repository: https://github.com/acme/library
+library:
+  path: .ddx/library
+  colour: red
+librar:
+  path: lib
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+`)
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte("interpolation: loose\n"), 0644))
+
+	sources, err := InspectSources(projectDir, "")
+	require.NoError(t, err)
+	require.Len(t, sources, 4)
+
+	assert.Equal(t, SourceDefault, sources[0].Source)
+	assert.Equal(t, SourceGlobal, sources[1].Source)
+	assert.False(t, sources[1].Found)
+
+	project := sources[2]
+	assert.Equal(t, filepath.Join(projectDir, ".ddx", "config.yaml"), project.Path)
+	assert.True(t, project.Found)
+	assert.Empty(t, project.Error)
+	assert.Equal(t, []UnknownKey{{Key: "library.colour", Line: 5}, {Key: "librar", Line: 6}}, project.Unknown)
+	require.Len(t, project.Deprecated, 1)
+	assert.Contains(t, project.Deprecated[0], "'repository' is deprecated")
+
+	local := sources[3]
+	assert.Equal(t, SourceLocal, local.Source)
+	assert.Empty(t, local.Unknown)
+	assert.Contains(t, local.Error, "interpolation")
+}
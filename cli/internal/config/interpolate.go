@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Interpolation modes, set with the top-level 'interpolation' key
+const (
+	// InterpolationLenient replaces references to unset variables with
+	// an empty string and warns
+	InterpolationLenient = "lenient"
+	// InterpolationStrict fails to load a configuration that refers to an
+	// unset variable without a default
+	InterpolationStrict = "strict"
+)
+
+// envReference matches ${NAME} and ${NAME:-default}; a reference written
+// as $${NAME} is kept literally as ${NAME}
+var envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Expand replaces the environment variable references in value using
+// lookup, and returns the names of referenced variables that are unset and
+// have no default. Unset variables expand to an empty string.
+func Expand(value string, lookup func(string) (string, bool)) (string, []string) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		match := envReference.FindStringSubmatch(ref)
+		if v, ok := lookup(match[1]); ok && (v != "" || match[2] == "") {
+			return v
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		missing = append(missing, match[1])
+		return ""
+	})
+	return expanded, missing
+}
+
+// HasReferences reports whether value refers to an environment variable
+func HasReferences(value string) bool {
+	for _, match := range envReference.FindAllString(value, -1) {
+		if !strings.HasPrefix(match, "$$") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnsetVariablesError reports the variables a strict configuration refers
+// to that are not set
+type UnsetVariablesError struct {
+	Path string
+	// Keys maps each configuration key to the unset variables it refers to
+	Keys map[string][]string
+}
+
+func (e *UnsetVariablesError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+	for key := range e.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s (${%s})", key, strings.Join(e.Keys[key], "}, ${")))
+	}
+	return fmt.Sprintf("unset environment variables in %s: %s; set them or give a default with ${NAME:-default}", e.Path, strings.Join(parts, ", "))
+}
+
+// warnedUnset records the unset-variable warnings already shown
+var warnedUnset sync.Map
+
+// interpolationMode returns the mode set by the highest layer that sets one
+func interpolationMode(layers []Layer) string {
+	mode := InterpolationLenient
+	for _, layer := range layers {
+		if layer.Config != nil && layer.Config.Interpolation != "" {
+			mode = layer.Config.Interpolation
+		}
+	}
+	return mode
+}
+
+// interpolateLayers expands environment variable references in the file
+// layers. Environment layers are taken literally.
+func interpolateLayers(layers []Layer) error {
+	mode := interpolationMode(layers)
+	for _, layer := range layers {
+		if layer.Source == SourceDefault || layer.Source == SourceEnv {
+			continue
+		}
+		unset := make(map[string][]string)
+		layer.Config.expandValues(func(key, value string) string {
+			expanded, missing := Expand(value, os.LookupEnv)
+			if len(missing) > 0 {
+				unset[key] = missing
+			}
+			return expanded
+		})
+		if len(unset) == 0 {
+			continue
+		}
+		err := &UnsetVariablesError{Path: layer.Path, Keys: unset}
+		if mode == InterpolationStrict {
+			return err
+		}
+		if _, seen := warnedUnset.LoadOrStore(err.Error(), true); !seen {
+			slog.Warn(err.Error())
+		}
+	}
+	return nil
+}
+
+// ResolvedValue is an effective configuration value after interpolation
+type ResolvedValue struct {
+	EffectiveValue
+	// Template is the value as written when it referred to environment
+	// variables, and empty otherwise
+	Template string
+	// Unset lists the referenced variables that are not set
+	Unset []string
+}
+
+// Resolution is the effective configuration with environment variable
+// references expanded
+type Resolution struct {
+	Mode   string
+	Values []ResolvedValue
+}
+
+// Resolve expands the environment variable references in every layer and
+// reports the effective values, keeping the written form of those that had
+// references. Unlike loading, it does not fail on unset variables in strict
+// mode; they are listed for the caller to report.
+func Resolve(workingDir, profile string) (*Resolution, error) {
+	layers, err := loadLayers(workingDir, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]ResolvedValue)
+	for _, layer := range layers {
+		templates := flattenConfig(layer.Config)
+		if layer.Source != SourceDefault && layer.Source != SourceEnv {
+			layer.Config.expandValues(func(_, value string) string {
+				expanded, _ := Expand(value, os.LookupEnv)
+				return expanded
+			})
+		}
+		for key, value := range flattenConfig(layer.Config) {
+			v := ResolvedValue{EffectiveValue: EffectiveValue{Key: key, Value: value, Source: layer.Source, Path: layer.Path}}
+			if template := templates[key]; template != value {
+				v.Template = template
+				_, v.Unset = Expand(template, os.LookupEnv)
+			}
+			resolved[key] = v
+		}
+	}
+
+	values := make([]ResolvedValue, 0, len(resolved))
+	for _, v := range resolved {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return &Resolution{Mode: interpolationMode(layers), Values: values}, nil
+}
+
+// expandValues replaces each string value that may refer to environment
+// variables with the result of expand, which receives its dotted key
+func (c *NewConfig) expandValues(expand func(key, value string) string) {
+	field := func(key string, value *string) {
+		if *value != "" {
+			*value = expand(key, *value)
+		}
+	}
+	if c.Library != nil {
+		field("library.path", &c.Library.Path)
+		if c.Library.Repository != nil {
+			field("library.repository.url", &c.Library.Repository.URL)
+			field("library.repository.branch", &c.Library.Repository.Branch)
+			field("library.repository.ref", &c.Library.Repository.Ref)
+		}
+		for i := range c.Library.Sources {
+			source := &c.Library.Sources[i]
+			field("library.sources."+source.Name+".path", &source.Path)
+			if source.Repository != nil {
+				field("library.sources."+source.Name+".repository.url", &source.Repository.URL)
+			}
+		}
+	}
+	for role, persona := range c.PersonaBindings {
+		c.PersonaBindings[role] = expand("persona_bindings."+role, persona)
+	}
+	for workflow, bindings := range c.Overrides {
+		for role, persona := range bindings {
+			bindings[role] = expand("overrides."+workflow+"."+role, persona)
+		}
+	}
+	for alias, path := range c.Prompts {
+		c.Prompts[alias] = expand("prompts."+alias, path)
+	}
+	if c.Agents != nil {
+		field("agents.command", &c.Agents.Command)
+		field("agents.model", &c.Agents.Model)
+		for i := range c.Agents.Args {
+			field(fmt.Sprintf("agents.args[%d]", i), &c.Agents.Args[i])
+		}
+	}
+	if c.Network != nil {
+		field("network.ca_file", &c.Network.CAFile)
+	}
+	if c.SettingsSync != nil {
+		field("settings_sync.repository", &c.SettingsSync.Repository)
+	}
+	if c.Telemetry != nil {
+		field("telemetry.endpoint", &c.Telemetry.Endpoint)
+	}
+}
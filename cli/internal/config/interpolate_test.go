@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"HOME_LIB": "/opt/ddx", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		value    string
+		expected string
+		missing  []string
+	}{
+		{"./library", "./library", nil},
+		{"${HOME_LIB}/library", "/opt/ddx/library", nil},
+		{"${UNSET:-main}", "main", nil},
+		{"${EMPTY:-fallback}", "fallback", nil},
+		{"${EMPTY}", "", nil},
+		{"${UNSET}/lib", "/lib", []string{"UNSET"}},
+		{"$${HOME_LIB}", "${HOME_LIB}", nil},
+		{"${HOME_LIB}-${OTHER}-${THIRD}", "/opt/ddx--", []string{"OTHER", "THIRD"}},
+		{"$HOME_LIB", "$HOME_LIB", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			expanded, missing := Expand(tt.value, lookup)
+			assert.Equal(t, tt.expected, expanded)
+			assert.Equal(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestLoadWithWorkingDir_Interpolation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("DDX_TEST_LIB", "/srv/ddx")
+	t.Setenv("DDX_TEST_REVIEWER", "strict-code-reviewer")
+
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+library:
+  path: ${DDX_TEST_LIB}/library
+  repository:
+    url: ${DDX_TEST_REPO:-https://github.com/easel/ddx-library}
+    branch: main
+persona_bindings:
+  code-reviewer: ${DDX_TEST_REVIEWER}
+workflows:
+  safe_word: ${NOT_EXPANDED}
+`)
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/ddx/library", cfg.Library.Path)
+	assert.Equal(t, "https://github.com/easel/ddx-library", cfg.Library.Repository.URL)
+	assert.Equal(t, "strict-code-reviewer", cfg.PersonaBindings["code-reviewer"])
+	assert.Equal(t, "${NOT_EXPANDED}", cfg.Workflows.SafeWord)
+}
+
+func TestLoadWithWorkingDir_InterpolationModes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+library:
+  path: ${DDX_TEST_UNSET}/library
+`)
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err, "lenient mode expands unset variables to nothing")
+	assert.Equal(t, "/library", cfg.Library.Path)
+
+	// A local override can make the project strict
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, LocalConfigFile), []byte("interpolation: strict\n"), 0644))
+	_, err = LoadWithWorkingDir(projectDir)
+	var unset *UnsetVariablesError
+	require.ErrorAs(t, err, &unset)
+	assert.Equal(t, map[string][]string{"library.path": {"DDX_TEST_UNSET"}}, unset.Keys)
+	assert.Contains(t, err.Error(), "library.path (${DDX_TEST_UNSET})")
+
+	t.Setenv("DDX_TEST_UNSET", "/home/dev")
+	cfg, err = LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/dev/library", cfg.Library.Path)
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("DDX_TEST_BRANCH", "release")
+
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+interpolation: strict
+library:
+  path: ${DDX_TEST_UNSET}/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: ${DDX_TEST_BRANCH}
+`)
+
+	resolution, err := Resolve(projectDir, "")
+	require.NoError(t, err, "resolve reports unset variables instead of failing")
+	assert.Equal(t, InterpolationStrict, resolution.Mode)
+
+	byKey := make(map[string]ResolvedValue)
+	for _, v := range resolution.Values {
+		byKey[v.Key] = v
+	}
+	assert.Equal(t, "release", byKey["library.repository.branch"].Value)
+	assert.Equal(t, "${DDX_TEST_BRANCH}", byKey["library.repository.branch"].Template)
+	assert.Empty(t, byKey["library.repository.branch"].Unset)
+	assert.Equal(t, "/library", byKey["library.path"].Value)
+	assert.Equal(t, []string{"DDX_TEST_UNSET"}, byKey["library.path"].Unset)
+	assert.Empty(t, byKey["library.repository.url"].Template)
+}
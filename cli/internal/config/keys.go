@@ -0,0 +1,406 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/filelock"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys returns every configuration key, using <name> for map entries
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(NewConfig{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// collectKeys walks a config type and records the dotted key of each leaf
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			name := yamlName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			collectKeys(t.Field(i).Type, joinKey(prefix, name), keys)
+		}
+	case reflect.Map:
+		collectKeys(t.Elem(), joinKey(prefix, "<name>"), keys)
+	default:
+		*keys = append(*keys, prefix)
+	}
+}
+
+// resolveKey validates a dotted key against the config structure and
+// returns the type of the value it addresses
+func resolveKey(key string) (reflect.Type, error) {
+	if key == "" {
+		return nil, fmt.Errorf("configuration key cannot be empty")
+	}
+
+	t := reflect.TypeOf(NewConfig{})
+	for _, segment := range strings.Split(key, ".") {
+		t = derefType(t)
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByYAMLName(t, segment)
+			if !ok {
+				return nil, unknownKeyError(key)
+			}
+			t = field.Type
+		case reflect.Map:
+			if segment == "" {
+				return nil, unknownKeyError(key)
+			}
+			t = t.Elem()
+		default:
+			return nil, unknownKeyError(key)
+		}
+	}
+	return derefType(t), nil
+}
+
+// unknownKeyError reports an invalid key along with the valid ones
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown configuration key: %s\nValid keys: %s", key, strings.Join(Keys(), ", "))
+}
+
+// GetValue returns the value at a dotted key formatted for display.
+// Sections, lists and maps are rendered as YAML.
+func GetValue(cfg *NewConfig, key string) (string, error) {
+	if _, err := resolveKey(key); err != nil {
+		return "", err
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	for _, segment := range strings.Split(key, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field, _ := fieldByYAMLName(v.Type(), segment)
+			v = v.FieldByIndex(field.Index)
+		case reflect.Map:
+			entry := v.MapIndex(reflect.ValueOf(segment))
+			if !entry.IsValid() {
+				return "", fmt.Errorf("configuration key not found: %s", key)
+			}
+			v = entry
+		}
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		data, err := yaml.Marshal(v.Interface())
+		if err != nil {
+			return "", fmt.Errorf("failed to format %s: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\n"), nil
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
+
+// SetValue sets a scalar or list value at a dotted key in a YAML document.
+// Lists are given as comma-separated values.
+func SetValue(doc *yaml.Node, key, value string) error {
+	t, err := resolveKey(key)
+	if err != nil {
+		return err
+	}
+
+	node, err := valueNode(t, key, value)
+	if err != nil {
+		return err
+	}
+
+	parent, last, err := walkNode(doc, key, true)
+	if err != nil {
+		return err
+	}
+	if i := mappingIndex(parent, last); i >= 0 {
+		old := parent.Content[i+1]
+		node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		parent.Content[i+1] = node
+	} else {
+		parent.Content = append(parent.Content, scalarNode(last), node)
+	}
+	return nil
+}
+
+// UnsetValue removes a dotted key from a YAML document, pruning parent
+// sections left empty
+func UnsetValue(doc *yaml.Node, key string) error {
+	if _, err := resolveKey(key); err != nil {
+		return err
+	}
+
+	if !removeNode(doc, key) {
+		return fmt.Errorf("configuration key not set: %s", key)
+	}
+	return nil
+}
+
+// AppendValue adds an item to the list at a dotted key
+func AppendValue(doc *yaml.Node, key, item string) error {
+	list, err := listNode(doc, key, true)
+	if err != nil {
+		return err
+	}
+	for _, existing := range list.Content {
+		if existing.Value == item {
+			return fmt.Errorf("%s already contains %q", key, item)
+		}
+	}
+	list.Content = append(list.Content, scalarNode(item))
+	return nil
+}
+
+// RemoveValue removes an item from the list at a dotted key
+func RemoveValue(doc *yaml.Node, key, item string) error {
+	list, err := listNode(doc, key, false)
+	if err != nil {
+		return err
+	}
+	for i, existing := range list.Content {
+		if existing.Value == item {
+			list.Content = append(list.Content[:i], list.Content[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not contain %q", key, item)
+}
+
+// UpdateFile applies an edit to the YAML document in a config file,
+// creating the file if needed. Comments and ordering are preserved and the
+// result is validated against the schema before it is written. The file
+// is locked against other ddx processes for the whole update.
+func UpdateFile(path string, edit func(doc *yaml.Node) error) error {
+	return filelock.With(path, func() error {
+		return updateFile(path, edit)
+	})
+}
+
+// updateFile implements UpdateFile while holding the file's lock
+func updateFile(path string, edit func(doc *yaml.Node) error) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse config YAML from %s: %w", path, err)
+		}
+	case os.IsNotExist(err):
+		// Start a new file with the required version
+	default:
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if rootMapping(&doc) == nil {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+		doc.Content[0].Content = []*yaml.Node{scalarNode("version"), {Kind: yaml.ScalarNode, Tag: "!!str", Value: "1.0"}}
+	}
+
+	if err := edit(&doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	_ = encoder.Close()
+
+	loader, err := NewConfigLoaderWithWorkingDir(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to create config loader: %w", err)
+	}
+	// Deprecated fields left in the file are mapped when it is loaded
+	validated, _, _ := migrateData(buf.Bytes())
+	if err := loader.validatePartial(validated); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	return nil
+}
+
+// listNode returns the sequence node at a dotted key, optionally creating it
+func listNode(doc *yaml.Node, key string, create bool) (*yaml.Node, error) {
+	t, err := resolveKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%s is not a list", key)
+	}
+
+	parent, last, err := walkNode(doc, key, create)
+	if err != nil {
+		return nil, err
+	}
+	if i := mappingIndex(parent, last); i >= 0 {
+		if parent.Content[i+1].Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("%s is not a list in the config file", key)
+		}
+		return parent.Content[i+1], nil
+	}
+	if !create {
+		return nil, fmt.Errorf("configuration key not set: %s", key)
+	}
+	list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	parent.Content = append(parent.Content, scalarNode(last), list)
+	return list, nil
+}
+
+// walkNode descends to the mapping that holds the last segment of key,
+// creating intermediate sections when create is set
+func walkNode(doc *yaml.Node, key string, create bool) (*yaml.Node, string, error) {
+	current := rootMapping(doc)
+	if current == nil {
+		return nil, "", fmt.Errorf("configuration document is not a mapping")
+	}
+
+	segments := strings.Split(key, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		i := mappingIndex(current, segment)
+		if i < 0 {
+			if !create {
+				return nil, "", fmt.Errorf("configuration key not set: %s", key)
+			}
+			next := &yaml.Node{Kind: yaml.MappingNode}
+			current.Content = append(current.Content, scalarNode(segment), next)
+			current = next
+			continue
+		}
+		next := current.Content[i+1]
+		if next.Kind != yaml.MappingNode {
+			if !create || next.Tag != "!!null" {
+				return nil, "", fmt.Errorf("%s is not a section in the config file", segment)
+			}
+			*next = yaml.Node{Kind: yaml.MappingNode}
+		}
+		current = next
+	}
+	return current, segments[len(segments)-1], nil
+}
+
+// valueNode builds the YAML node for a value of the given type
+func valueNode(t reflect.Type, key, value string) (*yaml.Node, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: expected true or false", key)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}, nil
+	case reflect.Int, reflect.Int64:
+		if _, err := strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: expected an integer", key)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}, nil
+	case reflect.Slice:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list.Content = append(list.Content, scalarNode(item))
+			}
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%s is a section - set individual keys such as %s.<name>", key, key)
+	}
+}
+
+// rootMapping returns the top-level mapping of a document
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		return doc.Content[0]
+	}
+	if doc.Kind == yaml.MappingNode {
+		return doc
+	}
+	return nil
+}
+
+// mappingIndex returns the index of key within a mapping node, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// scalarNode creates a plain string scalar
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// fieldByYAMLName finds the struct field with the given yaml tag name
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// yamlName returns the yaml key of a struct field
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// derefType strips pointer indirection from a type
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// joinKey appends a segment to a dotted key
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestKeys lists leaf keys including map placeholders
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	assert.Contains(t, keys, "library.repository.url")
+	assert.Contains(t, keys, "persona_bindings.<name>")
+	assert.Contains(t, keys, "workflows.active")
+	assert.Contains(t, keys, "update_check.enabled")
+}
+
+// TestGetValue tests dotted-path lookup across structs, pointers and maps
+func TestGetValue(t *testing.T) {
+	cfg := DefaultNewConfig()
+	cfg.PersonaBindings["code-reviewer"] = "strict-code-reviewer"
+	cfg.Workflows.Active = []string{"helix"}
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr string
+	}{
+		{key: "library.repository.branch", want: "main"},
+		{key: "persona_bindings.code-reviewer", want: "strict-code-reviewer"},
+		{key: "update_check.enabled", want: "true"},
+		{key: "workflows.active", want: "- helix"},
+		{key: "system.meta_prompt", want: ""},
+		{key: "persona_bindings.architect", wantErr: "not found"},
+		{key: "library.missing", wantErr: "unknown configuration key"},
+		{key: "version.extra", wantErr: "unknown configuration key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := GetValue(cfg, tt.key)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// parseDoc parses YAML into a document node
+func parseDoc(t *testing.T, content string) *yaml.Node {
+	t.Helper()
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(content), &doc))
+	return &doc
+}
+
+// encodeDoc renders a document node back to YAML
+func encodeDoc(t *testing.T, doc *yaml.Node) string {
+	t.Helper()
+	data, err := yaml.Marshal(doc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestSetAndUnsetValue tests editing nested keys while keeping comments
+func TestSetAndUnsetValue(t *testing.T) {
+	doc := parseDoc(t, `# project config
+version: "1.0"
+library:
+  path: .ddx/library # vendored
+`)
+
+	require.NoError(t, SetValue(doc, "persona_bindings.code-reviewer", "strict-code-reviewer"))
+	require.NoError(t, SetValue(doc, "update_check.enabled", "false"))
+	require.NoError(t, SetValue(doc, "library.path", "lib"))
+
+	out := encodeDoc(t, doc)
+	assert.Contains(t, out, "# project config")
+	assert.Contains(t, out, "# vendored")
+	assert.Contains(t, out, "code-reviewer: strict-code-reviewer")
+	assert.Contains(t, out, "enabled: false")
+	assert.Contains(t, out, "path: lib")
+
+	assert.ErrorContains(t, SetValue(doc, "update_check.enabled", "maybe"), "true or false")
+	assert.ErrorContains(t, SetValue(doc, "persona_bindings", "x"), "section")
+
+	require.NoError(t, UnsetValue(doc, "persona_bindings.code-reviewer"))
+	out = encodeDoc(t, doc)
+	assert.NotContains(t, out, "persona_bindings")
+	assert.ErrorContains(t, UnsetValue(doc, "persona_bindings.code-reviewer"), "not set")
+}
+
+// TestAppendAndRemoveValue tests list operations
+func TestAppendAndRemoveValue(t *testing.T) {
+	doc := parseDoc(t, "version: \"1.0\"\n")
+
+	require.NoError(t, AppendValue(doc, "workflows.active", "helix"))
+	require.NoError(t, AppendValue(doc, "workflows.active", "kanban"))
+	assert.ErrorContains(t, AppendValue(doc, "workflows.active", "helix"), "already contains")
+	assert.ErrorContains(t, AppendValue(doc, "library.path", "x"), "not a list")
+	assert.Contains(t, encodeDoc(t, doc), "active: [helix, kanban]")
+
+	require.NoError(t, RemoveValue(doc, "workflows.active", "helix"))
+	assert.ErrorContains(t, RemoveValue(doc, "workflows.active", "helix"), "does not contain")
+	assert.Contains(t, encodeDoc(t, doc), "active: [kanban]")
+}
+
+// TestUpdateFile tests that edits are validated before being written
+func TestUpdateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ddx", "config.yaml")
+
+	require.NoError(t, UpdateFile(path, func(doc *yaml.Node) error {
+		return SetValue(doc, "library.repository.branch", "develop")
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `version: "1.0"`)
+	assert.Contains(t, string(data), "branch: develop")
+
+	err = UpdateFile(path, func(doc *yaml.Node) error {
+		return SetValue(doc, "library.repository.url", "not a url")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid")
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "not a url")
+}
@@ -0,0 +1,458 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/easel/ddx/internal/userdirs"
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigFile is the uncommitted per-developer override file
+const LocalConfigFile = ".ddx.local.yml"
+
+// Configuration sources in increasing order of precedence
+const (
+	SourceDefault = "default"
+	SourceGlobal  = "global"
+	SourceProject = "project"
+	SourceProfile = "profile"
+	SourceLocal   = "local"
+	SourceEnv     = "env"
+)
+
+// envOverrides maps environment variables to the configuration keys they set
+var envOverrides = []struct {
+	Name string
+	Key  string
+}{
+	{"DDX_LIBRARY_BASE_PATH", "library.path"},
+	{"DDX_LIBRARY_URL", "library.repository.url"},
+	{"DDX_LIBRARY_BRANCH", "library.repository.branch"},
+}
+
+// Layer is a single source of configuration in the precedence chain
+type Layer struct {
+	Source string
+	// Path is the file the layer was read from, or the environment
+	// variable name for env layers. Empty for defaults.
+	Path   string
+	Config *NewConfig
+}
+
+// EffectiveValue is a resolved configuration value with its source attribution
+type EffectiveValue struct {
+	Key    string
+	Value  string
+	Source string
+	Path   string
+	// Shadowed are the values lower layers set for the key, lowest first
+	Shadowed []EffectiveValue
+}
+
+// GlobalConfigPath returns the location of the user-wide configuration file,
+// in the XDG config directory or in ~/.ddx until it is migrated
+func GlobalConfigPath() (string, error) {
+	return userdirs.Path(userdirs.Config, "config.yaml")
+}
+
+// LocalConfigPath returns the location of the local override file
+func LocalConfigPath(workingDir string) string {
+	return filepath.Join(workingDir, LocalConfigFile)
+}
+
+// LoadLayers reads every configuration source that applies to the working
+// directory, ordered from lowest to highest precedence:
+// defaults < global < project < profile < local < env.
+// Missing files are skipped, with a warning for a missing profile; an empty
+// profile name skips the profile layer.
+// References to environment variables in the files are expanded.
+func LoadLayers(workingDir, profile string) ([]Layer, error) {
+	layers, err := loadLayers(workingDir, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := interpolateLayers(layers); err != nil {
+		return nil, err
+	}
+
+	for _, layer := range layers[1:] {
+		slog.Debug("loaded config layer", "source", layer.Source, "path", layer.Path)
+	}
+	return layers, nil
+}
+
+// warnedProfiles records the missing profiles already warned about
+var warnedProfiles sync.Map
+
+// loadLayers reads the configuration layers as written, without expanding
+// environment variable references
+func loadLayers(workingDir, profile string) ([]Layer, error) {
+	loader, err := NewConfigLoaderWithWorkingDir(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config loader: %w", err)
+	}
+
+	defaults := DefaultNewConfig()
+	defaults.ApplyDefaults()
+	layers := []Layer{{Source: SourceDefault, Config: defaults}}
+
+	projectPath := filepath.Join(workingDir, ".ddx", "config.yaml")
+
+	if globalPath, err := GlobalConfigPath(); err == nil && !samePath(globalPath, projectPath) {
+		cfg, err := loader.loadLayerFile(globalPath, true)
+		if err != nil {
+			return nil, err
+		}
+		if cfg != nil {
+			layers = append(layers, Layer{Source: SourceGlobal, Path: globalPath, Config: cfg})
+		}
+	}
+
+	cfg, err := loader.loadLayerFile(projectPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		layers = append(layers, Layer{Source: SourceProject, Path: projectPath, Config: cfg})
+	}
+
+	if profile != "" {
+		profilePath := ProfilePath(workingDir, profile)
+		if _, err := os.Stat(profilePath); os.IsNotExist(err) && profile == ActiveProfile() && ValidateProfileName(profile) == nil {
+			// A stale DDX_ENV must not break every command, doctor included;
+			// a profile asked for by name must exist
+			if _, seen := warnedProfiles.LoadOrStore(profilePath, true); !seen {
+				slog.Warn(fmt.Sprintf("profile '%s' not found at %s; using the configuration without it", profile, profilePath))
+			}
+		} else {
+			cfg, err := loader.LoadProfile(profile)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, Layer{Source: SourceProfile, Path: profilePath, Config: cfg})
+		}
+	}
+
+	localPath := LocalConfigPath(workingDir)
+	cfg, err = loader.loadLayerFile(localPath, true)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		layers = append(layers, Layer{Source: SourceLocal, Path: localPath, Config: cfg})
+	}
+
+	for _, env := range envOverrides {
+		value := os.Getenv(env.Name)
+		if value == "" {
+			continue
+		}
+		cfg := &NewConfig{Library: &LibraryConfig{}}
+		switch env.Key {
+		case "library.path":
+			cfg.Library.Path = value
+		case "library.repository.url":
+			cfg.Library.Repository = &RepositoryConfig{URL: value}
+		case "library.repository.branch":
+			cfg.Library.Repository = &RepositoryConfig{Branch: value}
+		}
+		layers = append(layers, Layer{Source: SourceEnv, Path: env.Name, Config: cfg})
+	}
+	return layers, nil
+}
+
+// MergeLayers folds the layers into a single configuration, later layers
+// taking precedence
+func MergeLayers(layers []Layer) *Config {
+	result := &Config{}
+	for _, layer := range layers {
+		result = result.Merge(layer.Config)
+	}
+	return result
+}
+
+// Effective resolves every configuration value for the working directory
+// and reports which layer supplied it. Values are sorted by key.
+func Effective(workingDir, profile string) ([]EffectiveValue, error) {
+	layers, err := LoadLayers(workingDir, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]EffectiveValue)
+	for _, layer := range layers {
+		for key, value := range flattenConfig(layer.Config) {
+			v := EffectiveValue{Key: key, Value: value, Source: layer.Source, Path: layer.Path}
+			if previous, ok := resolved[key]; ok {
+				v.Shadowed = append(previous.Shadowed, EffectiveValue{Key: key, Value: previous.Value, Source: previous.Source, Path: previous.Path})
+			}
+			resolved[key] = v
+		}
+	}
+
+	values := make([]EffectiveValue, 0, len(resolved))
+	for _, v := range resolved {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values, nil
+}
+
+// flattenConfig returns the values explicitly set in a configuration,
+// keyed by their dotted path
+func flattenConfig(c *NewConfig) map[string]string {
+	values := make(map[string]string)
+	if c == nil {
+		return values
+	}
+
+	if c.Version != "" {
+		values["version"] = c.Version
+	}
+	if c.Interpolation != "" {
+		values["interpolation"] = c.Interpolation
+	}
+	if c.Library != nil {
+		if c.Library.Path != "" {
+			values["library.path"] = c.Library.Path
+		}
+		if c.Library.Repository != nil {
+			if c.Library.Repository.URL != "" {
+				values["library.repository.url"] = c.Library.Repository.URL
+			}
+			if c.Library.Repository.Branch != "" {
+				values["library.repository.branch"] = c.Library.Repository.Branch
+			}
+			if c.Library.Repository.Ref != "" {
+				values["library.repository.ref"] = c.Library.Repository.Ref
+			}
+		}
+		if len(c.Library.Include) > 0 {
+			values["library.include"] = "[" + strings.Join(c.Library.Include, ", ") + "]"
+		}
+		if len(c.Library.Exclude) > 0 {
+			values["library.exclude"] = "[" + strings.Join(c.Library.Exclude, ", ") + "]"
+		}
+		if len(c.Library.Sources) > 0 {
+			names := make([]string, len(c.Library.Sources))
+			for i, source := range c.Library.Sources {
+				names[i] = source.Name
+			}
+			values["library.sources"] = "[" + strings.Join(names, ", ") + "]"
+		}
+	}
+	if len(c.Workflows.Active) > 0 {
+		values["workflows.active"] = "[" + strings.Join(c.Workflows.Active, ", ") + "]"
+	}
+	if c.Workflows.SafeWord != "" {
+		values["workflows.safe_word"] = c.Workflows.SafeWord
+	}
+	if c.Workflows.DocsPath != "" {
+		values["workflows.docs_path"] = c.Workflows.DocsPath
+	}
+	if c.System != nil && c.System.MetaPrompt != nil {
+		values["system.meta_prompt"] = *c.System.MetaPrompt
+	}
+	if c.UpdateCheck != nil {
+		values["update_check.enabled"] = strconv.FormatBool(c.UpdateCheck.IsEnabled())
+		if c.UpdateCheck.Frequency != "" {
+			values["update_check.frequency"] = c.UpdateCheck.Frequency
+		}
+	}
+	if c.Updates != nil && c.Updates.Check != "" {
+		values["updates.check"] = c.Updates.Check
+	}
+	if c.Agents != nil {
+		if c.Agents.Preset != "" {
+			values["agents.preset"] = c.Agents.Preset
+		}
+		if c.Agents.Model != "" {
+			values["agents.model"] = c.Agents.Model
+		}
+		if c.Agents.Command != "" {
+			values["agents.command"] = c.Agents.Command
+		}
+		if len(c.Agents.Args) > 0 {
+			values["agents.args"] = "[" + strings.Join(c.Agents.Args, ", ") + "]"
+		}
+		if c.Agents.Input != "" {
+			values["agents.input"] = c.Agents.Input
+		}
+	}
+	if c.AI != nil && len(c.AI.Targets) > 0 {
+		values["ai.targets"] = "[" + strings.Join(c.AI.Targets, ", ") + "]"
+	}
+	if c.Injection != nil {
+		if c.Injection.ClaudeFile != "" {
+			values["injection.claude_file"] = c.Injection.ClaudeFile
+		}
+		if len(c.Injection.DisabledPaths) > 0 {
+			values["injection.disabled_paths"] = "[" + strings.Join(c.Injection.DisabledPaths, ", ") + "]"
+		}
+	}
+	for role, persona := range c.PersonaBindings {
+		if names := SplitPersonas(persona); len(names) > 1 {
+			persona = "[" + strings.Join(names, ", ") + "]"
+		}
+		values["persona_bindings."+role] = persona
+	}
+	for workflow, bindings := range c.Overrides {
+		for role, persona := range bindings {
+			if names := SplitPersonas(persona); len(names) > 1 {
+				persona = "[" + strings.Join(names, ", ") + "]"
+			}
+			values["overrides."+workflow+"."+role] = persona
+		}
+	}
+	for alias, path := range c.Prompts {
+		values["prompts."+alias] = path
+	}
+	for name, enabled := range c.Hooks {
+		values["hooks."+name] = strconv.FormatBool(enabled)
+	}
+	if c.Exec != nil {
+		if len(c.Exec.Allow) > 0 {
+			values["exec.allow"] = "[" + strings.Join(c.Exec.Allow, ", ") + "]"
+		}
+		if len(c.Exec.Env) > 0 {
+			values["exec.env"] = "[" + strings.Join(c.Exec.Env, ", ") + "]"
+		}
+	}
+	if c.Source != nil {
+		values["source.url"] = c.Source.URL
+		if c.Source.Ref != "" {
+			values["source.ref"] = c.Source.Ref
+		}
+		if c.Source.Commit != "" {
+			values["source.commit"] = c.Source.Commit
+		}
+	}
+	if c.SettingsSync != nil {
+		if c.SettingsSync.Repository != "" {
+			values["settings_sync.repository"] = c.SettingsSync.Repository
+		}
+		if c.SettingsSync.Branch != "" {
+			values["settings_sync.branch"] = c.SettingsSync.Branch
+		}
+	}
+	if c.History != nil && c.History.Enabled != nil {
+		values["history.enabled"] = strconv.FormatBool(*c.History.Enabled)
+	}
+	if c.Telemetry != nil {
+		if c.Telemetry.Enabled != nil {
+			values["telemetry.enabled"] = strconv.FormatBool(*c.Telemetry.Enabled)
+		}
+		if c.Telemetry.Endpoint != "" {
+			values["telemetry.endpoint"] = c.Telemetry.Endpoint
+		}
+	}
+	if c.Gitignore != nil {
+		if c.Gitignore.Manage != nil {
+			values["gitignore.manage"] = strconv.FormatBool(*c.Gitignore.Manage)
+		}
+		for name, ignored := range c.Gitignore.Ignore {
+			values["gitignore.ignore."+name] = strconv.FormatBool(ignored)
+		}
+	}
+	if c.Backups != nil {
+		if c.Backups.Keep != nil {
+			values["backups.keep"] = strconv.Itoa(*c.Backups.Keep)
+		}
+		if c.Backups.MaxAge != "" {
+			values["backups.max_age"] = c.Backups.MaxAge
+		}
+	}
+	if c.Network != nil {
+		if c.Network.CAFile != "" {
+			values["network.ca_file"] = c.Network.CAFile
+		}
+		if c.Network.Timeout != "" {
+			values["network.timeout"] = c.Network.Timeout
+		}
+		if c.Network.Retries != nil {
+			values["network.retries"] = strconv.Itoa(*c.Network.Retries)
+		}
+		if c.Network.Backoff != "" {
+			values["network.backoff"] = c.Network.Backoff
+		}
+		if c.Network.Offline != nil {
+			values["network.offline"] = strconv.FormatBool(*c.Network.Offline)
+		}
+	}
+
+	return values
+}
+
+// loadLayerFile reads a configuration layer without applying defaults so
+// that only the values present in the file take part in merging. Partial
+// layers may omit the version field. A missing file yields nil.
+func (cl *ConfigLoader) loadLayerFile(path string, partial bool) (*NewConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML from %s: %w", path, err)
+	}
+	if raw == nil {
+		return &NewConfig{}, nil
+	}
+	data = MigrateDeprecated(path, data)
+
+	if partial {
+		err = cl.validatePartial(data)
+	} else {
+		err = cl.validator.Validate(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configuration validation failed for %s: %w", path, err)
+	}
+
+	var config NewConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML from %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// validatePartial validates a file that may omit the version, as global
+// and local override files do
+func (cl *ConfigLoader) validatePartial(data []byte) error {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+	if raw == nil {
+		return nil
+	}
+	if _, ok := raw["version"]; !ok {
+		raw["version"] = "1.0"
+		var err error
+		if data, err = yaml.Marshal(raw); err != nil {
+			return fmt.Errorf("failed to prepare config for validation: %w", err)
+		}
+	}
+	return cl.validator.Validate(data)
+}
+
+// samePath reports whether two paths refer to the same location
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// effectiveByKey indexes resolved values by key
+func effectiveByKey(values []EffectiveValue) map[string]EffectiveValue {
+	byKey := make(map[string]EffectiveValue, len(values))
+	for _, v := range values {
+		byKey[v.Key] = v
+	}
+	return byKey
+}
+
+// TestLoadWithWorkingDir_LayerPrecedence tests defaults < global < project < local < env
+func TestLoadWithWorkingDir_LayerPrecedence(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+library:
+  repository:
+    url: https://github.com/global/library
+    branch: global
+persona_bindings:
+  architect: global-architect
+  code-reviewer: global-reviewer
+`), 0644))
+
+	writeProjectConfig(t, projectDir, `version: "1.0"
+library:
+  repository:
+    branch: project
+persona_bindings:
+  code-reviewer: project-reviewer
+`)
+
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte(`persona_bindings:
+  code-reviewer: local-reviewer
+`), 0644))
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/global/library", cfg.Library.Repository.URL)
+	assert.Equal(t, "project", cfg.Library.Repository.Branch)
+	assert.Equal(t, ".ddx/library", cfg.Library.Path)
+	assert.Equal(t, "global-architect", cfg.PersonaBindings["architect"])
+	assert.Equal(t, "local-reviewer", cfg.PersonaBindings["code-reviewer"])
+
+	t.Setenv("DDX_LIBRARY_BRANCH", "from-env")
+	cfg, err = LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Library.Repository.Branch)
+}
+
+// TestEffective_ReportsSources tests that each value is attributed to the layer that set it
+func TestEffective_ReportsSources(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("DDX_LIBRARY_BASE_PATH", "/opt/ddx/library")
+
+	writeProjectConfig(t, projectDir, `version: "1.0"
+library:
+  repository:
+    branch: develop
+`)
+	require.NoError(t, os.WriteFile(ProfilePath(projectDir, "ci"), []byte(`version: "1.0"
+workflows:
+  active: [helix]
+`), 0644))
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte(`persona_bindings:
+  override: local
+`), 0644))
+
+	values, err := Effective(projectDir, "ci")
+	require.NoError(t, err)
+	byKey := effectiveByKey(values)
+
+	assert.Equal(t, SourceDefault, byKey["library.repository.url"].Source)
+	assert.Equal(t, SourceProject, byKey["library.repository.branch"].Source)
+	assert.Equal(t, "develop", byKey["library.repository.branch"].Value)
+	assert.Equal(t, SourceProfile, byKey["workflows.active"].Source)
+	assert.Equal(t, "[helix]", byKey["workflows.active"].Value)
+	assert.Equal(t, SourceLocal, byKey["persona_bindings.override"].Source)
+	assert.Equal(t, LocalConfigPath(projectDir), byKey["persona_bindings.override"].Path)
+	assert.Equal(t, SourceEnv, byKey["library.path"].Source)
+	assert.Equal(t, "DDX_LIBRARY_BASE_PATH", byKey["library.path"].Path)
+	assert.Equal(t, []EffectiveValue{{Key: "library.path", Value: ".ddx/library", Source: SourceDefault}}, byKey["library.path"].Shadowed)
+	assert.Empty(t, byKey["persona_bindings.override"].Shadowed)
+}
+
+// TestLoadLayers_InvalidLocalOverride tests that schema errors in override files are reported
+func TestLoadLayers_InvalidLocalOverride(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte("unknown_field: true\n"), 0644))
+
+	_, err := LoadLayers(projectDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), LocalConfigFile)
+}
+
+// TestLoadWithWorkingDir_GitignoreLayers tests that gitignore entries merge by artifact
+func TestLoadWithWorkingDir_GitignoreLayers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+gitignore:
+  ignore:
+    history: false
+`)
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte(`gitignore:
+  ignore:
+    helix-state: true
+`), 0644))
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.ManagesGitignore())
+	assert.Equal(t, map[string]bool{"history": false, "helix-state": true}, cfg.GitignoreOverrides())
+
+	values, err := Effective(projectDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "false", effectiveByKey(values)["gitignore.ignore.history"].Value)
+}
+
+// TestLoadWithWorkingDir_NetworkLayers tests that a project setting one
+// network field keeps the others from the global configuration
+func TestLoadWithWorkingDir_NetworkLayers(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+network:
+  ca_file: /etc/ssl/corp.pem
+  retries: 5
+  offline: true
+`), 0644))
+	writeProjectConfig(t, projectDir, `version: "1.0"
+network:
+  timeout: 1m
+  offline: false
+`)
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Network)
+	assert.Equal(t, "/etc/ssl/corp.pem", cfg.Network.CAFile)
+	require.NotNil(t, cfg.Network.Retries)
+	assert.Equal(t, 5, *cfg.Network.Retries)
+	assert.Equal(t, "1m", cfg.Network.Timeout)
+	require.NotNil(t, cfg.Network.Offline)
+	assert.False(t, *cfg.Network.Offline)
+
+	values, err := Effective(projectDir, "")
+	require.NoError(t, err)
+	byKey := effectiveByKey(values)
+	assert.Equal(t, "/etc/ssl/corp.pem", byKey["network.ca_file"].Value)
+	assert.Equal(t, SourceGlobal, byKey["network.ca_file"].Source)
+	assert.Equal(t, "1m", byKey["network.timeout"].Value)
+	assert.Equal(t, "false", byKey["network.offline"].Value)
+}
+
+// TestLoadWithWorkingDir_BackupsLayers tests that a project setting one
+// backups field keeps the other from the global configuration
+func TestLoadWithWorkingDir_BackupsLayers(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+backups:
+  keep: 5
+  max_age: 720h
+`), 0644))
+	writeProjectConfig(t, projectDir, `version: "1.0"
+backups:
+  keep: 10
+`)
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Backups)
+	require.NotNil(t, cfg.Backups.Keep)
+	assert.Equal(t, 10, *cfg.Backups.Keep)
+	assert.Equal(t, "720h", cfg.Backups.MaxAge)
+}
+
+// TestLoadWithWorkingDir_TelemetryLayers tests that a project setting the
+// endpoint does not turn off telemetry enabled globally
+func TestLoadWithWorkingDir_TelemetryLayers(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+telemetry:
+  enabled: true
+`), 0644))
+	writeProjectConfig(t, projectDir, `version: "1.0"
+telemetry:
+  endpoint: https://metrics.example.com
+`)
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.Telemetry.IsEnabled())
+	assert.Equal(t, "https://metrics.example.com", cfg.Telemetry.Endpoint)
+
+	values, err := Effective(projectDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceGlobal, effectiveByKey(values)["telemetry.enabled"].Source)
+}
+
+// TestLoadWithWorkingDir_HistoryLayers tests that an empty history section
+// in a later layer keeps history enabled by an earlier one
+func TestLoadWithWorkingDir_HistoryLayers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	projectDir := t.TempDir()
+	writeProjectConfig(t, projectDir, `version: "1.0"
+history:
+  enabled: true
+`)
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte("history: {}\n"), 0644))
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.History.IsEnabled())
+
+	require.NoError(t, os.WriteFile(LocalConfigPath(projectDir), []byte("history:\n  enabled: false\n"), 0644))
+	cfg, err = LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	assert.False(t, cfg.History.IsEnabled())
+}
+
+// TestLoadWithWorkingDir_SettingsSyncLayers tests that a project choosing a
+// branch keeps the repository from the global configuration
+func TestLoadWithWorkingDir_SettingsSyncLayers(t *testing.T) {
+	homeDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".ddx", "config.yaml"), []byte(`version: "1.0"
+settings_sync:
+  repository: git@github.com:me/settings.git
+`), 0644))
+	writeProjectConfig(t, projectDir, `version: "1.0"
+settings_sync:
+  branch: work
+`)
+
+	cfg, err := LoadWithWorkingDir(projectDir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.SettingsSync)
+	assert.Equal(t, "git@github.com:me/settings.git", cfg.SettingsSync.Repository)
+	assert.Equal(t, "work", cfg.SettingsSync.Branch)
+}
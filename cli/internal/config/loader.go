@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/filelock"
 	"gopkg.in/yaml.v3"
 )
 
@@ -64,6 +66,7 @@ func (cl *ConfigLoader) loadNewFormat(path string) (*NewConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 	}
+	data = MigrateDeprecated(path, data)
 
 	// Validate using two-phase validation
 	if err := cl.validator.Validate(data); err != nil {
@@ -107,11 +110,12 @@ func (cl *ConfigLoader) SaveConfig(config *NewConfig, path string) error {
 	}
 
 	// Write file with secure permissions
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file %s: %w", path, err)
-	}
-
-	return nil
+	return filelock.With(path, func() error {
+		if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write config file %s: %w", path, err)
+		}
+		return nil
+	})
 }
 
 // DetectConfigFormat determines if .ddx/config.yaml exists in working directory
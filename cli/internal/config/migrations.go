@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the configuration format written by this version of ddx
+const CurrentVersion = "1.0"
+
+// LegacyConfigFile is where configuration was kept before .ddx/config.yaml,
+// in the project root or the home directory
+const LegacyConfigFile = ".ddx.yml"
+
+// legacyVersion is the format of files at the legacy location, whatever
+// their version field says: it described the old layout
+const legacyVersion = "0"
+
+// FormatStep upgrades configuration documents older than its version
+type FormatStep struct {
+	// Version is the format the step produces
+	Version string
+	// Description summarizes the step for reports
+	Description string
+	// Apply rewrites the document, returning the changes made
+	Apply func(doc *yaml.Node) []string
+}
+
+// FormatSteps are applied in order to documents whose version is older
+// than the step's. Renamed fields are handled by Deprecations in every
+// version.
+var FormatSteps = []FormatStep{
+	{Version: "1.0", Description: "adopt the .ddx/config.yaml layout", Apply: upgradeLegacyLayout},
+}
+
+// AppliedStep is a format step that changed a document
+type AppliedStep struct {
+	Version     string
+	Description string
+	Changes     []string
+}
+
+// MigrationPlan brings one configuration file up to date. Source is read
+// and Target written; they differ when a legacy .ddx.yml is moved.
+type MigrationPlan struct {
+	Source      string
+	Target      string
+	FromVersion string
+	ToVersion   string
+	Steps       []AppliedStep
+	Renames     []Migration
+
+	doc yaml.Node
+}
+
+// Empty reports whether the file is already current
+func (p *MigrationPlan) Empty() bool {
+	return !p.Outdated() && len(p.Renames) == 0
+}
+
+// Outdated reports whether the file uses an older format, as opposed to
+// only deprecated field names
+func (p *MigrationPlan) Outdated() bool {
+	return p.Source != p.Target || p.FromVersion != p.ToVersion
+}
+
+// Backup is where Apply keeps the original file
+func (p *MigrationPlan) Backup() string {
+	return p.Source + ".bak"
+}
+
+// PlanMigration works out how to bring the configuration at source up to
+// date and save it at target. It returns nil when source does not exist.
+func PlanMigration(source, target string) (*MigrationPlan, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", source, err)
+	}
+	if source != target {
+		if _, err := os.Stat(target); err == nil {
+			return nil, fmt.Errorf("both %s and %s exist: copy any settings still needed from the legacy file and remove it", source, target)
+		}
+	}
+
+	plan := &MigrationPlan{Source: source, Target: target}
+	if err := yaml.Unmarshal(data, &plan.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML from %s: %w", source, err)
+	}
+	if rootMapping(&plan.doc) == nil {
+		plan.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	plan.FromVersion = documentVersion(&plan.doc)
+	if filepath.Base(source) == LegacyConfigFile {
+		plan.FromVersion = legacyVersion
+	}
+	plan.Steps, plan.ToVersion = upgradeFormat(&plan.doc, plan.FromVersion)
+	plan.Renames = MigrateDocument(&plan.doc)
+
+	// Files without a version, such as local overrides, only get one when
+	// they needed upgrading
+	stamp := lookupNode(&plan.doc, "version") != nil || source != target || len(plan.Steps) > 0
+	if plan.ToVersion != plan.FromVersion && stamp {
+		setVersion(&plan.doc, plan.ToVersion)
+	} else {
+		plan.ToVersion = plan.FromVersion
+	}
+	return plan, nil
+}
+
+// Apply saves the migrated configuration at the target, keeping the
+// original next to the source as a .bak file. A legacy source is removed
+// once the target is written.
+func (p *MigrationPlan) Apply() error {
+	if p.Empty() {
+		return nil
+	}
+	original, err := os.ReadFile(p.Source)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", p.Source, err)
+	}
+	if err := atomicfile.WriteFile(p.Backup(), original, 0644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", p.Source, err)
+	}
+
+	if err := UpdateFile(p.Target, func(doc *yaml.Node) error {
+		*doc = p.doc
+		return nil
+	}); err != nil {
+		return err
+	}
+	if p.Source != p.Target {
+		if err := os.Remove(p.Source); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", p.Source, err)
+		}
+	}
+	return nil
+}
+
+// upgradeFormat applies the format steps newer than from, returning the
+// steps that changed the document and the version it now has
+func upgradeFormat(doc *yaml.Node, from string) ([]AppliedStep, string) {
+	var applied []AppliedStep
+	version := from
+	for _, step := range FormatSteps {
+		if compareVersions(from, step.Version) >= 0 {
+			continue
+		}
+		if changes := step.Apply(doc); len(changes) > 0 {
+			applied = append(applied, AppliedStep{Version: step.Version, Description: step.Description, Changes: changes})
+		}
+		version = step.Version
+	}
+	return applied, version
+}
+
+// upgradeLegacyLayout converts the fields of the .ddx.yml layout that have
+// no direct replacement
+func upgradeLegacyLayout(doc *yaml.Node) []string {
+	var changes []string
+	if includes := lookupNode(doc, "includes"); includes != nil {
+		removeNode(doc, "includes")
+		if includes.Kind == yaml.SequenceNode && len(includes.Content) > 0 {
+			if placeNode(doc, "library.include", includes) {
+				changes = append(changes, "moved 'includes' to 'library.include'")
+			} else {
+				changes = append(changes, "dropped 'includes' ('library.include' is already set)")
+			}
+		} else {
+			changes = append(changes, "removed empty 'includes'")
+		}
+	}
+
+	// Legacy overrides mapped library files to project files; overrides
+	// now hold persona bindings per workflow
+	if overrides := lookupNode(doc, "overrides"); overrides != nil && overrides.Kind == yaml.MappingNode {
+		for i := 1; i < len(overrides.Content); i += 2 {
+			if overrides.Content[i].Kind == yaml.ScalarNode {
+				removeNode(doc, "overrides")
+				changes = append(changes, "removed file 'overrides' (keep customized files in the project instead)")
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// documentVersion returns a document's version field, or legacyVersion
+// when it has none
+func documentVersion(doc *yaml.Node) string {
+	node := lookupNode(doc, "version")
+	if node == nil || node.Kind != yaml.ScalarNode || node.Value == "" {
+		return legacyVersion
+	}
+	return node.Value
+}
+
+// setVersion stores the version as a quoted string, as ddx writes it
+func setVersion(doc *yaml.Node, version string) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version, Style: yaml.DoubleQuotedStyle}
+	root := rootMapping(doc)
+	if i := mappingIndex(root, "version"); i >= 0 {
+		root.Content[i+1] = value
+		return
+	}
+	root.Content = append([]*yaml.Node{scalarNode("version"), value}, root.Content...)
+}
+
+// compareVersions orders major.minor versions numerically; unparsable
+// parts count as zero
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanMigration(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("current file", func(t *testing.T) {
+		path := write("current.yaml", "version: \"1.0\"\nlibrary:\n  path: .ddx/library\n")
+		plan, err := PlanMigration(path, path)
+		require.NoError(t, err)
+		assert.True(t, plan.Empty())
+	})
+
+	t.Run("newer format is left alone", func(t *testing.T) {
+		path := write("newer.yaml", "version: \"2.0\"\nincludes: [prompts]\n")
+		plan, err := PlanMigration(path, path)
+		require.NoError(t, err)
+		assert.True(t, plan.Empty())
+	})
+
+	t.Run("unversioned override only gets renames", func(t *testing.T) {
+		path := write("local.yml", "library_path: lib\n")
+		plan, err := PlanMigration(path, path)
+		require.NoError(t, err)
+		assert.False(t, plan.Outdated())
+		require.Len(t, plan.Renames, 1)
+		require.NoError(t, plan.Apply())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "library:\n  path: lib\n", string(data))
+	})
+
+	t.Run("older format", func(t *testing.T) {
+		path := write("old.yaml", `version: "0.9"
+# shared with the team
+includes:
+  - prompts/claude
+overrides:
+  prompts/custom.md: local/custom.md
+`)
+		plan, err := PlanMigration(path, path)
+		require.NoError(t, err)
+		assert.Equal(t, "0.9", plan.FromVersion)
+		assert.Equal(t, CurrentVersion, plan.ToVersion)
+		require.Len(t, plan.Steps, 1)
+		assert.Equal(t, []string{
+			"moved 'includes' to 'library.include'",
+			"removed file 'overrides' (keep customized files in the project instead)",
+		}, plan.Steps[0].Changes)
+
+		require.NoError(t, plan.Apply())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `version: "1.0"`)
+		assert.Contains(t, string(data), "library:\n  include:\n    - prompts/claude")
+		assert.NotContains(t, string(data), "overrides")
+		backup, err := os.ReadFile(plan.Backup())
+		require.NoError(t, err)
+		assert.Contains(t, string(backup), "includes:")
+	})
+
+	t.Run("legacy file moves", func(t *testing.T) {
+		legacy := write(LegacyConfigFile, "version: 1.0\nbranch: stable\n")
+		target := filepath.Join(dir, ".ddx", "config.yaml")
+		plan, err := PlanMigration(legacy, target)
+		require.NoError(t, err)
+		assert.Equal(t, legacyVersion, plan.FromVersion)
+		assert.True(t, plan.Outdated())
+
+		require.NoError(t, plan.Apply())
+		assert.NoFileExists(t, legacy)
+		assert.FileExists(t, legacy+".bak")
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "branch: stable")
+
+		// A second legacy file would overwrite the migrated one
+		legacy = write(LegacyConfigFile, "version: 1.0\n")
+		_, err = PlanMigration(legacy, target)
+		assert.ErrorContains(t, err, "both")
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, -1, compareVersions("0", "1.0"))
+	assert.Equal(t, -1, compareVersions("0.9", "1.0"))
+	assert.Equal(t, 0, compareVersions("1", "1.0"))
+	assert.Equal(t, 1, compareVersions("1.10", "1.9"))
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnvVar is the environment variable that selects the active profile
+const ProfileEnvVar = "DDX_ENV"
+
+// ActiveProfile returns the name of the active environment profile, or an
+// empty string when no profile is selected
+func ActiveProfile() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnvVar))
+}
+
+// ProfilePath returns the location of the profile file for the given name
+func ProfilePath(workingDir, name string) string {
+	return filepath.Join(workingDir, fmt.Sprintf(".ddx.%s.yml", name))
+}
+
+// ValidateProfileName ensures a profile name can be safely used in a file name
+func ValidateProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name: cannot contain path separators")
+	}
+	return nil
+}
+
+// CheckProfile reports an error when the named profile is invalid or has
+// no file in workingDir
+func CheckProfile(workingDir, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	path := ProfilePath(workingDir, name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("profile '%s' not found at %s", name, path)
+	}
+	return nil
+}
+
+// LoadProfile loads the profile overlay for the given name.
+// Defaults are not applied so that only values set in the profile
+// override the base configuration when merged.
+func (cl *ConfigLoader) LoadProfile(name string) (*NewConfig, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+
+	path := ProfilePath(cl.workingDir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("profile '%s' not found at %s", name, path)
+		}
+		return nil, fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+	data = MigrateDeprecated(path, data)
+
+	if err := cl.validator.Validate(data); err != nil {
+		return nil, fmt.Errorf("profile '%s' is invalid: %w", name, err)
+	}
+
+	var profile NewConfig
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile YAML from %s: %w", path, err)
+	}
+
+	return &profile, nil
+}
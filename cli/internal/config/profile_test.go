@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProjectConfig writes .ddx/config.yaml into the given directory
+func writeProjectConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	ddxDir := filepath.Join(dir, ".ddx")
+	require.NoError(t, os.MkdirAll(ddxDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(ddxDir, "config.yaml"), []byte(content), 0644))
+}
+
+// TestLoadWithProfile_MergesOverlay tests that profile values override the project config
+func TestLoadWithProfile_MergesOverlay(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	writeProjectConfig(t, tempDir, `version: "1.0"
+library:
+  path: .ddx/library
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+persona_bindings:
+  code-reviewer: strict-code-reviewer
+  architect: simplicity-architect
+`)
+
+	profile := `version: "1.0"
+library:
+  repository:
+    branch: staging
+persona_bindings:
+  code-reviewer: pragmatic-implementer
+workflows:
+  active: [helix]
+`
+	require.NoError(t, os.WriteFile(ProfilePath(tempDir, "staging"), []byte(profile), 0644))
+
+	cfg, err := LoadWithProfile(tempDir, "staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.Library.Repository.Branch)
+	assert.Equal(t, "https://github.com/easel/ddx-library", cfg.Library.Repository.URL)
+	assert.Equal(t, ".ddx/library", cfg.Library.Path)
+	assert.Equal(t, "pragmatic-implementer", cfg.PersonaBindings["code-reviewer"])
+	assert.Equal(t, "simplicity-architect", cfg.PersonaBindings["architect"])
+	assert.Equal(t, []string{"helix"}, cfg.Workflows.Active)
+}
+
+// TestLoadWithProfile_MergesUpdateCheckFields tests that a layer setting
+// only the frequency keeps the check disabled by an earlier layer
+func TestLoadWithProfile_MergesUpdateCheckFields(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	writeProjectConfig(t, tempDir, `version: "1.0"
+update_check:
+  enabled: false
+`)
+	require.NoError(t, os.WriteFile(ProfilePath(tempDir, "ci"), []byte("version: \"1.0\"\nupdate_check:\n  frequency: 12h\n"), 0644))
+
+	cfg, err := LoadWithProfile(tempDir, "ci")
+	require.NoError(t, err)
+	assert.False(t, cfg.UpdateCheck.IsEnabled())
+	assert.Equal(t, "12h", cfg.UpdateCheck.Frequency)
+	assert.Zero(t, cfg.UpdateCheckInterval())
+}
+
+// TestLoadWithWorkingDir_HonorsProfileEnv tests that DDX_ENV selects the profile
+func TestLoadWithWorkingDir_HonorsProfileEnv(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	writeProjectConfig(t, tempDir, `version: "1.0"
+library:
+  repository:
+    url: https://github.com/easel/ddx-library
+    branch: main
+`)
+	require.NoError(t, os.WriteFile(ProfilePath(tempDir, "dev"), []byte(`version: "1.0"
+library:
+  repository:
+    branch: develop
+`), 0644))
+
+	cfg, err := LoadWithWorkingDir(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, "main", cfg.Library.Repository.Branch)
+
+	t.Setenv(ProfileEnvVar, "dev")
+	cfg, err = LoadWithWorkingDir(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, "develop", cfg.Library.Repository.Branch)
+}
+
+// TestLoadWithProfile_MissingProfile tests that a missing profile asked
+// for by name fails, while one selected by a stale DDX_ENV is skipped
+func TestLoadWithProfile_MissingProfile(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	_, err := LoadWithProfile(tempDir, "missing")
+	assert.ErrorContains(t, err, "profile 'missing' not found")
+	assert.ErrorContains(t, CheckProfile(tempDir, "missing"), "profile 'missing' not found")
+
+	t.Setenv(ProfileEnvVar, "missing")
+	cfg, err := LoadWithWorkingDir(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNewConfig().Library.Repository.Branch, cfg.Library.Repository.Branch)
+
+	t.Setenv(ProfileEnvVar, "../missing")
+	_, err = LoadWithWorkingDir(tempDir)
+	assert.ErrorContains(t, err, "invalid profile name")
+}
+
+// TestValidateProfileName tests profile name validation
+func TestValidateProfileName(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, ValidateProfileName("staging"))
+	assert.Error(t, ValidateProfileName(""))
+	assert.Error(t, ValidateProfileName("../prod"))
+	assert.Error(t, ValidateProfileName(`a\b`))
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // NewConfig represents the simplified DDx configuration structure
@@ -12,8 +15,43 @@ type NewConfig struct {
 	Library         *LibraryConfig     `yaml:"library" json:"library"`
 	Workflows       WorkflowsConfig    `yaml:"workflows,omitempty" json:"workflows,omitempty"`
 	System          *SystemConfig      `yaml:"system,omitempty" json:"system,omitempty"`
-	PersonaBindings map[string]string  `yaml:"persona_bindings,omitempty" json:"persona_bindings,omitempty"`
+	PersonaBindings PersonaBindings    `yaml:"persona_bindings,omitempty" json:"persona_bindings,omitempty"`
 	UpdateCheck     *UpdateCheckConfig `yaml:"update_check,omitempty" json:"update_check,omitempty"`
+	Agents          *AgentsConfig      `yaml:"agents,omitempty" json:"agents,omitempty"`
+	AI              *AIConfig          `yaml:"ai,omitempty" json:"ai,omitempty"`
+	// Injection renames the Claude target file and keeps AI target files in
+	// some directories from being written
+	Injection *InjectionConfig `yaml:"injection,omitempty" json:"injection,omitempty"`
+	// Prompts maps shortcut names to prompt files used with 'ddx p'
+	Prompts map[string]string `yaml:"prompts,omitempty" json:"prompts,omitempty"`
+	// Overrides replaces persona bindings while a workflow runs, keyed by
+	// workflow name
+	Overrides map[string]PersonaBindings `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+	// Source records the team configuration the project was initialized from
+	Source *SourceConfig `yaml:"source,omitempty" json:"source,omitempty"`
+	// SettingsSync is where 'ddx settings sync' keeps personal settings
+	SettingsSync *SettingsSyncConfig `yaml:"settings_sync,omitempty" json:"settings_sync,omitempty"`
+	// History turns on the local log of ddx invocations
+	History *HistoryConfig `yaml:"history,omitempty" json:"history,omitempty"`
+	// Telemetry turns on anonymous usage metrics
+	Telemetry *TelemetryConfig `yaml:"telemetry,omitempty" json:"telemetry,omitempty"`
+	// Network configures proxies, certificates and timeouts for downloads
+	Network *NetworkConfig `yaml:"network,omitempty" json:"network,omitempty"`
+	// Hooks enables or disables library git hooks by name for
+	// 'ddx hooks install'
+	Hooks map[string]bool `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	// Exec lists the library scripts 'ddx exec' may run
+	Exec *ExecConfig `yaml:"exec,omitempty" json:"exec,omitempty"`
+	// Backups sets how long copies of AI configuration files are kept
+	Backups *BackupsConfig `yaml:"backups,omitempty" json:"backups,omitempty"`
+	// Interpolation is "strict" to reject references to unset environment
+	// variables, or "lenient" (the default) to expand them to nothing
+	Interpolation string `yaml:"interpolation,omitempty" json:"interpolation,omitempty"`
+	// Updates sets how often ddx looks for a newer release
+	Updates *UpdatesConfig `yaml:"updates,omitempty" json:"updates,omitempty"`
+	// Gitignore chooses which ddx files .gitignore keeps out of version
+	// control
+	Gitignore *GitignoreConfig `yaml:"gitignore,omitempty" json:"gitignore,omitempty"`
 }
 
 // SystemConfig represents system-level configuration settings
@@ -25,20 +63,213 @@ type SystemConfig struct {
 type LibraryConfig struct {
 	Path       string            `yaml:"path,omitempty" json:"path,omitempty"`
 	Repository *RepositoryConfig `yaml:"repository" json:"repository"`
+	// Include and Exclude select the library paths the project uses, as
+	// filter patterns (globs, re: or substrings) matched against a path
+	// or any of its parents
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	// Sources are further libraries, such as an organization's internal
+	// one, whose resources are addressed as <name>:<path>
+	Sources []LibrarySource `yaml:"sources,omitempty" json:"sources,omitempty"`
+}
+
+// MainSource names the library at library.path among the library sources
+const MainSource = "library"
+
+// LibrarySource is a library used alongside the main one
+type LibrarySource struct {
+	// Name is the namespace of the source's resources
+	Name       string            `yaml:"name" json:"name"`
+	Path       string            `yaml:"path" json:"path"`
+	Repository *RepositoryConfig `yaml:"repository,omitempty" json:"repository,omitempty"`
+	// Priority decides which source an unqualified name resolves to when
+	// several have it; higher wins and the main library has priority 0
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// sourceName is the form of a library source name
+var sourceName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// IsSourceName reports whether name can name a library source
+func IsSourceName(name string) bool {
+	return sourceName.MatchString(name)
 }
 
 // RepositoryConfig represents repository settings for the new format
 type RepositoryConfig struct {
 	URL    string `yaml:"url" json:"url"`
 	Branch string `yaml:"branch" json:"branch"`
+	// Ref pins the library to a tag or commit; update does not move past
+	// it unless forced
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+}
+
+// Revision returns what the library is synced from: the pinned ref, or
+// the branch when it is not pinned
+func (r *RepositoryConfig) Revision() string {
+	if r.Ref != "" {
+		return r.Ref
+	}
+	return r.Branch
 }
 
 // UpdateCheckConfig represents update checking settings
 type UpdateCheckConfig struct {
-	Enabled   bool   `yaml:"enabled"`
+	// Enabled is false to turn the check off (default: true)
+	Enabled   *bool  `yaml:"enabled,omitempty"`
 	Frequency string `yaml:"frequency"` // Duration: "24h", "12h", etc.
 }
 
+// IsEnabled reports whether the check is on; it is unless turned off
+func (u *UpdateCheckConfig) IsEnabled() bool {
+	return u.Enabled == nil || *u.Enabled
+}
+
+// boolPtr returns a pointer to b, for optional settings
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// Update check frequencies, set with 'updates.check'
+const (
+	UpdatesNever  = "never"
+	UpdatesDaily  = "daily"
+	UpdatesWeekly = "weekly"
+)
+
+// UpdatesConfig controls the check for new ddx releases
+type UpdatesConfig struct {
+	// Check is never, daily (the default) or weekly
+	Check string `yaml:"check,omitempty" json:"check,omitempty"`
+}
+
+// AgentsConfig selects the AI agent tool prompts are sent to
+type AgentsConfig struct {
+	// Preset names a built-in agent: claude, aider, llm or goose
+	Preset string `yaml:"preset,omitempty" json:"preset,omitempty"`
+	// Model is passed with the preset's model selection flag
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Command overrides the preset's executable or defines a custom agent
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Args are appended to the preset's arguments
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+	// Input overrides how the prompt is delivered: arg, stdin or file
+	Input string `yaml:"input,omitempty" json:"input,omitempty"`
+}
+
+// AIConfig selects the assistant configuration files personas and
+// meta-prompts are written to
+type AIConfig struct {
+	// Targets are built-in assistants (claude, cursor, copilot, windsurf) or
+	// file paths relative to the project; empty means claude
+	Targets []string `yaml:"targets,omitempty" json:"targets,omitempty"`
+}
+
+// InjectionConfig limits where personas, meta-prompts and prompt sections
+// are written
+type InjectionConfig struct {
+	// ClaudeFile replaces CLAUDE.md as the claude target's file, relative
+	// to the project
+	ClaudeFile string `yaml:"claude_file,omitempty" json:"claude_file,omitempty"`
+	// DisabledPaths are directories, or slash-separated globs, whose AI
+	// target files are never written; "." turns injection off entirely
+	DisabledPaths []string `yaml:"disabled_paths,omitempty" json:"disabled_paths,omitempty"`
+}
+
+// ExecConfig controls the library scripts run with 'ddx exec'
+type ExecConfig struct {
+	// Allow are script names, or globs such as "release-*", that may run
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	// Env names environment variables passed to scripts in addition to
+	// PATH, HOME and the other basics
+	Env []string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// SettingsSyncConfig names the git repository personal settings are
+// synced through, usually set in the global configuration
+type SettingsSyncConfig struct {
+	// Repository is a git URL; a gist's clone URL works too
+	Repository string `yaml:"repository,omitempty" json:"repository,omitempty"`
+	// Branch defaults to main
+	Branch string `yaml:"branch,omitempty" json:"branch,omitempty"`
+}
+
+// HistoryConfig controls the audit log of ddx invocations kept in
+// .ddx/history.jsonl
+type HistoryConfig struct {
+	// Enabled is true to record invocations (default: false)
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether history was turned on; it is off unless
+// enabled
+func (h *HistoryConfig) IsEnabled() bool {
+	return h != nil && h.Enabled != nil && *h.Enabled
+}
+
+// TelemetryConfig controls the anonymous usage metrics sent by
+// 'ddx telemetry enable'
+type TelemetryConfig struct {
+	// Enabled is true to count usage (default: false)
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Endpoint receives batches of metrics; without one they stay local
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+}
+
+// IsEnabled reports whether telemetry was turned on; it is off unless
+// enabled
+func (t *TelemetryConfig) IsEnabled() bool {
+	return t != nil && t.Enabled != nil && *t.Enabled
+}
+
+// NetworkConfig adapts network operations to corporate networks. Proxies
+// come from the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables.
+type NetworkConfig struct {
+	// CAFile is a PEM bundle of extra certificate authorities to trust,
+	// such as the root of a TLS-inspecting proxy
+	CAFile string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+	// Timeout bounds connecting and waiting for a response, e.g. "30s"
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Retries is how often failed requests are retried
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// Backoff is the wait before the first retry, doubling after each,
+	// e.g. "500ms"
+	Backoff string `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	// Offline skips network checks, for machines that get the library
+	// from a mirror or bundle
+	Offline *bool `yaml:"offline,omitempty" json:"offline,omitempty"`
+}
+
+// BackupsConfig is the retention policy for the backups of CLAUDE.md and
+// other AI configuration files kept in .ddx/backups
+type BackupsConfig struct {
+	// Keep is the number of backups retained; 0 keeps all (default: 20)
+	Keep *int `yaml:"keep,omitempty" json:"keep,omitempty"`
+	// MaxAge removes backups older than this, e.g. "720h"
+	MaxAge string `yaml:"max_age,omitempty" json:"max_age,omitempty"`
+}
+
+// GitignoreConfig controls the block ddx manages in the project's
+// .gitignore
+type GitignoreConfig struct {
+	// Manage is false to leave .gitignore alone (default: true)
+	Manage *bool `yaml:"manage,omitempty" json:"manage,omitempty"`
+	// Ignore overrides, by artifact name such as backups or helix-state,
+	// whether ddx's files are ignored
+	Ignore map[string]bool `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+}
+
+// SourceConfig records where a shared team configuration came from so the
+// project can be compared with it or updated later
+type SourceConfig struct {
+	// URL is the git repository holding the team configuration
+	URL string `yaml:"url" json:"url"`
+	// Ref is the branch or tag that was requested, empty for the default branch
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	// Commit is the revision the project was initialized from
+	Commit string `yaml:"commit,omitempty" json:"commit,omitempty"`
+}
+
 // WorkflowsConfig represents workflow activation and settings
 type WorkflowsConfig struct {
 	// Active workflows in priority order (first match wins)
@@ -47,6 +278,23 @@ type WorkflowsConfig struct {
 	// SafeWord prefix to bypass workflow engagement
 	// Default: "NODDX"
 	SafeWord string `yaml:"safe_word,omitempty" json:"safe_word,omitempty"`
+
+	// DocsPath is the directory, relative to the project, that workflow
+	// artifacts are generated into
+	// Default: "docs"
+	DocsPath string `yaml:"docs_path,omitempty" json:"docs_path,omitempty"`
+}
+
+// DefaultDocsPath is where workflow artifacts go unless docs_path is set
+const DefaultDocsPath = "docs"
+
+// Docs returns the directory workflow artifacts are generated into,
+// relative to the project
+func (w WorkflowsConfig) Docs() string {
+	if w.DocsPath == "" {
+		return DefaultDocsPath
+	}
+	return w.DocsPath
 }
 
 // ApplyDefaults sets default values for workflow configuration
@@ -78,6 +326,10 @@ func (w *WorkflowsConfig) Validate() error {
 		seen[name] = true
 	}
 
+	if w.DocsPath != "" && !filepath.IsLocal(filepath.FromSlash(w.DocsPath)) {
+		return fmt.Errorf("docs_path must be a relative path inside the project")
+	}
+
 	return nil
 }
 
@@ -94,7 +346,7 @@ func DefaultNewConfig() *NewConfig {
 		},
 		PersonaBindings: make(map[string]string),
 		UpdateCheck: &UpdateCheckConfig{
-			Enabled:   true,
+			Enabled:   boolPtr(true),
 			Frequency: "24h",
 		},
 	}
@@ -114,6 +366,52 @@ func (c *NewConfig) GetMetaPrompt() string {
 	return *c.System.MetaPrompt
 }
 
+// UpdateCheckInterval returns how long to wait between checks for a new
+// release, 0 when checks are off. 'updates.check' takes precedence over
+// the older update_check section.
+func (c *NewConfig) UpdateCheckInterval() time.Duration {
+	if c.Updates != nil && c.Updates.Check != "" {
+		switch c.Updates.Check {
+		case UpdatesNever:
+			return 0
+		case UpdatesWeekly:
+			return 7 * 24 * time.Hour
+		default:
+			return 24 * time.Hour
+		}
+	}
+	if c.UpdateCheck != nil {
+		if !c.UpdateCheck.IsEnabled() {
+			return 0
+		}
+		if d, err := time.ParseDuration(c.UpdateCheck.Frequency); err == nil && d > 0 {
+			return d
+		}
+	}
+	return 24 * time.Hour
+}
+
+// ManagesGitignore reports whether ddx keeps a block of .gitignore
+func (c *NewConfig) ManagesGitignore() bool {
+	return c.Gitignore == nil || c.Gitignore.Manage == nil || *c.Gitignore.Manage
+}
+
+// GitignoreOverrides returns the artifacts whose ignoring is configured
+func (c *NewConfig) GitignoreOverrides() map[string]bool {
+	if c.Gitignore == nil {
+		return nil
+	}
+	return c.Gitignore.Ignore
+}
+
+// GetAITargets returns the configured AI targets, nil for the default
+func (c *NewConfig) GetAITargets() []string {
+	if c.AI == nil {
+		return nil
+	}
+	return c.AI.Targets
+}
+
 // ApplyDefaults ensures all required fields have default values
 func (c *NewConfig) ApplyDefaults() {
 	if c.Version == "" {
@@ -144,16 +442,24 @@ func (c *NewConfig) ApplyDefaults() {
 				c.Library.Repository.Branch = "main"
 			}
 		}
+		for i := range c.Library.Sources {
+			if repo := c.Library.Sources[i].Repository; repo != nil && repo.Branch == "" {
+				repo.Branch = "main"
+			}
+		}
 	}
 	if c.PersonaBindings == nil {
 		c.PersonaBindings = make(map[string]string)
 	}
 	if c.UpdateCheck == nil {
 		c.UpdateCheck = &UpdateCheckConfig{
-			Enabled:   true,
+			Enabled:   boolPtr(true),
 			Frequency: "24h",
 		}
 	} else {
+		if c.UpdateCheck.Enabled == nil {
+			c.UpdateCheck.Enabled = boolPtr(true)
+		}
 		if c.UpdateCheck.Frequency == "" {
 			c.UpdateCheck.Frequency = "24h"
 		}
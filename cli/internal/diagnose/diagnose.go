@@ -0,0 +1,109 @@
+// Package diagnose builds support bundles: a tar.gz of the facts a bug
+// report needs, such as versions, configuration and recent history. Every
+// file passes through a Redactor first, so credentials and the user's home
+// directory do not end up attached to a public issue.
+package diagnose
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces removed values
+const Redacted = "[REDACTED]"
+
+// sensitiveName matches configuration keys, flags and environment
+// variables whose values are credentials
+var sensitiveName = regexp.MustCompile(`(?i)token|passw(or)?d|secret|api[_-]?key|credential|private[_-]?key`)
+
+// assignment matches a sensitive name set to a value, as in YAML, JSON,
+// environment listings and flags
+var assignment = regexp.MustCompile(`(?i)([\w.-]*(?:token|passw(?:or)?d|secret|api[_-]?key|credential|private[_-]?key)[\w.-]*["']?\s*[:=]\s*["']?)([^"'\s,}]+)`)
+
+// tokenPatterns match credentials by their shape wherever they appear
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+}
+
+// bearer matches an Authorization header value
+var bearer = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`)
+
+// userinfo matches credentials embedded in a URL
+var userinfo = regexp.MustCompile(`([a-z][a-z0-9+.-]*://)[^/\s:@]+:[^/\s@]+@`)
+
+// File is one file of a bundle
+type File struct {
+	// Name is the slash-separated path inside the bundle
+	Name string
+	Data []byte
+}
+
+// Redactor removes credentials and home directory paths from text
+type Redactor struct {
+	// Home is replaced by ~; nothing is replaced when it is empty
+	Home string
+}
+
+// Sensitive reports whether a key, flag or variable name holds credentials
+func Sensitive(name string) bool {
+	return sensitiveName.MatchString(name)
+}
+
+// Redact returns text with credentials and the home directory removed
+func (r Redactor) Redact(text string) string {
+	text = assignment.ReplaceAllString(text, "${1}"+Redacted)
+	for _, pattern := range tokenPatterns {
+		text = pattern.ReplaceAllString(text, Redacted)
+	}
+	text = bearer.ReplaceAllString(text, "${1} "+Redacted)
+	text = userinfo.ReplaceAllString(text, "${1}"+Redacted+"@")
+	if home := strings.TrimRight(r.Home, `/\`); home != "" {
+		homePath := regexp.MustCompile(regexp.QuoteMeta(home) + `([/\\"'\s:;,]|$)`)
+		text = homePath.ReplaceAllString(text, "~${1}")
+		// JSON escapes the backslashes of Windows paths
+		if escaped := strings.ReplaceAll(home, `\`, `\\`); escaped != home {
+			text = strings.ReplaceAll(text, escaped, "~")
+		}
+	}
+	return text
+}
+
+// Write writes files as a gzipped tar archive with every file redacted and
+// placed under dir
+func Write(w io.Writer, dir string, files []File, r Redactor, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		data := []byte(r.Redact(string(file.Data)))
+		header := &tar.Header{
+			Name:    path.Join(dir, file.Name),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
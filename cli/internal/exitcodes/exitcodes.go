@@ -0,0 +1,53 @@
+// Package exitcodes defines the exit codes of the ddx command line, the
+// part of its contract scripts and test harnesses depend on. Codes are
+// never renumbered; new ones are added at the end.
+package exitcodes
+
+const (
+	Success       = 0
+	GeneralError  = 1
+	MissingArg    = 2
+	NoConfig      = 3
+	InvalidConfig = 4
+	NetworkError  = 5
+	NotFound      = 6
+	BindingExists = 7
+	NoBindings    = 8
+	// Interrupted follows the shell convention of 128 plus SIGINT
+	Interrupted = 130
+)
+
+// Code describes an exit code
+type Code struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var codes = []Code{
+	{Success, "success", "The command succeeded"},
+	{GeneralError, "general_error", "The command failed"},
+	{MissingArg, "missing_arg", "An argument is missing or invalid, or the command would overwrite without --force"},
+	{NoConfig, "no_config", "No .ddx/config.yaml was found"},
+	{InvalidConfig, "invalid_config", "The configuration or an input file is invalid"},
+	{NetworkError, "network_error", "A repository or service could not be reached"},
+	{NotFound, "not_found", "A persona, asset, MCP server or other named resource was not found"},
+	{BindingExists, "binding_exists", "A persona is already bound to the role"},
+	{NoBindings, "no_bindings", "The project has no persona bindings"},
+	{Interrupted, "interrupted", "The command was cancelled with Ctrl-C or SIGTERM"},
+}
+
+// All returns every exit code in numeric order
+func All() []Code {
+	return append([]Code(nil), codes...)
+}
+
+// Lookup returns the description of code
+func Lookup(code int) (Code, bool) {
+	for _, c := range codes {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return Code{}, false
+}
@@ -0,0 +1,33 @@
+package exitcodes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	all := All()
+	assert.Equal(t, Success, all[0].Code)
+	names := map[string]bool{}
+	for i, c := range all {
+		if i > 0 {
+			assert.Greater(t, c.Code, all[i-1].Code, "codes are ordered")
+		}
+		assert.NotEmpty(t, c.Description)
+		assert.False(t, names[c.Name], "duplicate name %s", c.Name)
+		names[c.Name] = true
+	}
+
+	all[0].Name = "changed"
+	assert.Equal(t, "success", All()[0].Name)
+}
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup(NoConfig)
+	assert.True(t, ok)
+	assert.Equal(t, "no_config", c.Name)
+
+	_, ok = Lookup(127)
+	assert.False(t, ok)
+}
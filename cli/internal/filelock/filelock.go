@@ -0,0 +1,190 @@
+// Package filelock serializes read-modify-write updates of project files
+// such as .ddx/config.yaml and CLAUDE.md between ddx processes. A lock is
+// a <file>.lock file created exclusively next to the file it guards,
+// naming the process that holds it.
+package filelock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Suffix is appended to a file's path to name its lock
+const Suffix = ".lock"
+
+// TimeoutEnvVar overrides DefaultTimeout with a duration such as "30s"
+const TimeoutEnvVar = "DDX_LOCK_TIMEOUT"
+
+// DefaultTimeout is how long Acquire waits for a lock held by another
+// process
+const DefaultTimeout = 10 * time.Second
+
+// StaleAfter is the age at which a lock is taken over even though its
+// holder may still run; ddx holds locks for well under a second
+const StaleAfter = 5 * time.Minute
+
+// pollInterval is how often a held lock is retried
+const pollInterval = 50 * time.Millisecond
+
+// Holder describes the process holding a lock
+type Holder struct {
+	PID      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// String describes the holder for error messages
+func (h Holder) String() string {
+	return fmt.Sprintf("pid %d on %s (%s) since %s", h.PID, h.Host, h.Command, h.Acquired.Local().Format("15:04:05"))
+}
+
+// LockedError reports a lock that could not be acquired in time
+type LockedError struct {
+	Path    string
+	Timeout time.Duration
+	// Holder is nil when the lock file could not be read
+	Holder *Holder
+}
+
+func (e *LockedError) Error() string {
+	holder := "another process"
+	if e.Holder != nil {
+		holder = e.Holder.String()
+	}
+	return fmt.Sprintf("timed out after %s waiting for %s: locked by %s; if that process is gone, remove %s",
+		e.Timeout, filepath.Base(e.Path), holder, e.Path+Suffix)
+}
+
+// Lock is a held lock
+type Lock struct {
+	path string
+}
+
+// Acquire locks path, waiting up to the timeout from TimeoutEnvVar or
+// DefaultTimeout
+func Acquire(path string) (*Lock, error) {
+	timeout := DefaultTimeout
+	if value := os.Getenv(TimeoutEnvVar); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': use a duration such as 30s", TimeoutEnvVar, value)
+		}
+		timeout = parsed
+	}
+	return AcquireTimeout(path, timeout)
+}
+
+// AcquireTimeout locks path, waiting up to timeout for another holder to
+// release it. Locks whose holder has exited, or that are older than
+// StaleAfter, are taken over.
+func AcquireTimeout(path string, timeout time.Duration) (*Lock, error) {
+	lockPath := path + Suffix
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", lockPath, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			err = json.NewEncoder(f).Encode(currentHolder())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write %s: %w", lockPath, err)
+			}
+			return &Lock{path: lockPath}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		holder := readHolder(lockPath)
+		if stale(lockPath, holder) {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, &LockedError{Path: path, Timeout: timeout, Holder: holder}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release removes the lock
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// With runs fn while holding the lock for path
+func With(path string, fn func() error) error {
+	l, err := Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+	return fn()
+}
+
+// currentHolder describes this process
+func currentHolder() Holder {
+	host, _ := os.Hostname()
+	command := filepath.Base(os.Args[0])
+	if len(os.Args) > 1 {
+		command += " " + strings.Join(os.Args[1:], " ")
+	}
+	return Holder{PID: os.Getpid(), Host: host, Command: command, Acquired: time.Now()}
+}
+
+// readHolder reads a lock file, returning nil when it is unreadable, for
+// instance while its holder is still writing it
+func readHolder(lockPath string) *Holder {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil
+	}
+	var holder Holder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil
+	}
+	return &holder
+}
+
+// stale reports whether a lock can be taken over: its holder on this host
+// has exited, or the lock is older than StaleAfter
+func stale(lockPath string, holder *Holder) bool {
+	if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > StaleAfter {
+		return true
+	}
+	if holder == nil {
+		return false
+	}
+	host, _ := os.Hostname()
+	return holder.Host == host && !processAlive(holder.PID)
+}
+
+// processAlive reports whether a process with the pid is running
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// FindProcess only succeeds for running processes on Windows, which
+	// does not support signal 0
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return !errors.Is(proc.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
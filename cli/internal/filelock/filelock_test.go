@@ -0,0 +1,94 @@
+package filelock
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	l, err := AcquireTimeout(path, time.Second)
+	require.NoError(t, err)
+	holder := readHolder(path + Suffix)
+	require.NotNil(t, holder)
+	assert.Equal(t, os.Getpid(), holder.PID)
+
+	_, err = AcquireTimeout(path, 100*time.Millisecond)
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Contains(t, err.Error(), "timed out after 100ms waiting for config.yaml: locked by pid")
+	assert.Contains(t, err.Error(), "remove "+path+Suffix)
+
+	require.NoError(t, l.Release())
+	assert.NoFileExists(t, path+Suffix)
+	l, err = AcquireTimeout(path, 100*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, l.Release())
+}
+
+func TestWithSerializesUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	require.NoError(t, os.WriteFile(path, []byte("0"), 0644))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, With(path, func() error {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				var n int
+				_ = json.Unmarshal(data, &n)
+				time.Sleep(time.Millisecond)
+				out, _ := json.Marshal(n + 1)
+				return os.WriteFile(path, out, 0644)
+			}))
+		}()
+	}
+	wg.Wait()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "20", string(data))
+}
+
+func TestAcquireTakesOverStaleLocks(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+
+	// A holder on this host that has exited
+	exited := filepath.Join(dir, "CLAUDE.md")
+	data, _ := json.Marshal(Holder{PID: 999999999, Host: host, Command: "ddx persona load", Acquired: time.Now()})
+	require.NoError(t, os.WriteFile(exited+Suffix, data, 0644))
+	l, err := AcquireTimeout(exited, 100*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, l.Release())
+
+	// An abandoned lock from another host
+	abandoned := filepath.Join(dir, "config.yaml")
+	data, _ = json.Marshal(Holder{PID: 1, Host: "elsewhere", Command: "ddx config set", Acquired: time.Now()})
+	require.NoError(t, os.WriteFile(abandoned+Suffix, data, 0644))
+	_, err = AcquireTimeout(abandoned, 50*time.Millisecond)
+	assert.ErrorContains(t, err, "locked by pid 1 on elsewhere (ddx config set)")
+	old := time.Now().Add(-2 * StaleAfter)
+	require.NoError(t, os.Chtimes(abandoned+Suffix, old, old))
+	l, err = AcquireTimeout(abandoned, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, l.Release())
+}
+
+func TestAcquireTimeoutFromEnvironment(t *testing.T) {
+	t.Setenv(TimeoutEnvVar, "soon")
+	_, err := Acquire(filepath.Join(t.TempDir(), "config.yaml"))
+	assert.ErrorContains(t, err, "invalid DDX_LOCK_TIMEOUT 'soon'")
+}
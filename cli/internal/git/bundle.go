@@ -0,0 +1,165 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IsLocalURL reports whether a repository URL refers to the filesystem: a
+// file:// URL or a path to a repository, mirror or bundle file
+func IsLocalURL(repoURL string) bool {
+	if repoURL == "" {
+		return false
+	}
+	if strings.HasPrefix(repoURL, "file://") || filepath.IsAbs(repoURL) {
+		return true
+	}
+	return !strings.Contains(repoURL, "://") && !scpURLPattern.MatchString(repoURL)
+}
+
+// LocalPath returns the filesystem path of a local repository URL
+func LocalPath(repoURL string) string {
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	return repoURL
+}
+
+// BundleRef is a branch or tag carried by a bundle
+type BundleRef struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+// CreateBundle fetches branch and the tags of repoURL into a temporary
+// repository and writes them to a git bundle at file. A non-empty since
+// leaves out the history up to that commit or tag, which the receiving
+// side must then already have.
+func CreateBundle(ctx context.Context, repoURL, branch, since, file string) ([]BundleRef, error) {
+	if err := validateRepoURL(repoURL); err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if err := validateBranchName(branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name: %w", err)
+	}
+	if since != "" {
+		if err := validateBranchName(since); err != nil {
+			return nil, fmt.Errorf("invalid revision %q: %w", since, err)
+		}
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "ddx-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary repository: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if _, err := gitIn(ctx, dir, "init", "--bare", "--quiet"); err != nil {
+		return nil, err
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 300*time.Second) // 5 minutes for network operations
+	defer cancel()
+	head := "refs/heads/" + sanitizeInput(branch)
+	if _, err := gitIn(fetchCtx, dir, "fetch", "--quiet", "--tags", repoURL, "+"+head+":"+head); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from %s: %w", branch, repoURL, err)
+	}
+
+	args := []string{"bundle", "create", "--quiet", file, head, "--tags"}
+	if since != "" {
+		args = append(args, "^"+sanitizeInput(since))
+	}
+	if _, err := gitIn(ctx, dir, args...); err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	return BundleHeads(ctx, file)
+}
+
+// BundleHeads lists the branches and tags in a bundle
+func BundleHeads(ctx context.Context, file string) ([]BundleRef, error) {
+	output, err := gitIn(ctx, "", "bundle", "list-heads", file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", file, err)
+	}
+	var refs []BundleRef
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if commit, name, ok := strings.Cut(line, " "); ok {
+			refs = append(refs, BundleRef{Name: name, Commit: commit})
+		}
+	}
+	return refs, nil
+}
+
+// ImportBundle verifies a bundle against the bare repository at mirror,
+// creating the repository when it does not exist, and fetches the bundle's
+// branches and tags into it. Branches only move forward.
+func ImportBundle(ctx context.Context, file, mirror string) ([]BundleRef, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("bundle not found: %w", err)
+	}
+	if _, err := os.Stat(mirror); os.IsNotExist(err) {
+		if err := os.MkdirAll(mirror, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create mirror: %w", err)
+		}
+		refs, err := importBundle(ctx, file, mirror)
+		if err != nil {
+			_ = os.RemoveAll(mirror)
+		}
+		return refs, err
+	}
+	return importBundle(ctx, file, mirror)
+}
+
+// importBundle fetches a bundle into the repository at mirror, which is
+// initialized first when empty
+func importBundle(ctx context.Context, file, mirror string) ([]BundleRef, error) {
+	if entries, _ := os.ReadDir(mirror); len(entries) == 0 {
+		if _, err := gitIn(ctx, mirror, "init", "--bare", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := gitIn(ctx, mirror, "bundle", "verify", "--quiet", file); err != nil {
+		return nil, fmt.Errorf("bundle %s does not apply to %s: %w", file, mirror, err)
+	}
+	if _, err := gitIn(ctx, mirror, "fetch", "--quiet", file, "refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"); err != nil {
+		return nil, fmt.Errorf("failed to import bundle: %w", err)
+	}
+	return BundleHeads(ctx, file)
+}
+
+// gitIn runs a git command in dir, or the current directory when dir is
+// empty, returning its output or an error carrying git's message
+func gitIn(ctx context.Context, dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", filepath.Clean(dir)}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		name := args[0]
+		if dir != "" {
+			name = args[2]
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", name, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", name, err)
+	}
+	return string(output), nil
+}
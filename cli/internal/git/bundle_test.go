@@ -0,0 +1,69 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLocalURL(t *testing.T) {
+	for url, local := range map[string]bool{
+		"/srv/mirrors/ddx-library.git":           true,
+		"file:///srv/mirrors/ddx-library.git":    true,
+		"mirrors/ddx-library.bundle":             true,
+		"https://git.corp.example/ddx-library":   false,
+		"ssh://git@git.corp.example/ddx-library": false,
+		"git@git.corp.example:tools/ddx-library": false,
+		"":                                       false,
+	} {
+		assert.Equal(t, local, IsLocalURL(url), url)
+	}
+	assert.Equal(t, "/srv/ddx.git", LocalPath("file:///srv/ddx.git"))
+	assert.Equal(t, "/srv/ddx.git", LocalPath("/srv/ddx.git"))
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestGitRepo(t)
+	run := func(args ...string) string {
+		output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	branch := run("rev-parse", "--abbrev-ref", "HEAD")
+	run("tag", "v1.0.0")
+
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.bundle")
+	refs, err := CreateBundle(ctx, repo, branch, "", full)
+	require.NoError(t, err)
+	assert.Contains(t, refs, BundleRef{Name: "refs/heads/" + branch, Commit: run("rev-parse", "HEAD")})
+	assert.Contains(t, refs, BundleRef{Name: "refs/tags/v1.0.0", Commit: run("rev-parse", "HEAD")})
+
+	mirror := filepath.Join(dir, "mirror.git")
+	_, err = ImportBundle(ctx, full, mirror)
+	require.NoError(t, err)
+
+	// An incremental bundle applies to the mirror, but not to an empty one
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "NEW.md"), []byte("new\n"), 0644))
+	run("add", "NEW.md")
+	run("commit", "-m", "Add a file")
+	incremental := filepath.Join(dir, "incremental.bundle")
+	_, err = CreateBundle(ctx, "file://"+repo, branch, "v1.0.0", incremental)
+	require.NoError(t, err)
+
+	_, err = ImportBundle(ctx, incremental, filepath.Join(dir, "empty.git"))
+	assert.ErrorContains(t, err, "does not apply")
+	assert.NoDirExists(t, filepath.Join(dir, "empty.git"))
+	_, err = ImportBundle(ctx, incremental, mirror)
+	require.NoError(t, err)
+	head, err := exec.Command("git", "-C", mirror, "rev-parse", "refs/heads/"+branch).Output()
+	require.NoError(t, err)
+	assert.Equal(t, run("rev-parse", "HEAD"), strings.TrimSpace(string(head)))
+}
@@ -110,14 +110,13 @@ func SubtreeAdd(prefix, repoURL, branch string) error {
 	defer cancel()
 
 	// Step 1: Fetch the remote branch
-	cmd := exec.CommandContext(ctx, "git", "fetch", repoURL, sanitizedBranch)
-	output, err := cmd.CombinedOutput()
+	output, err := runRemote(ctx, "", "fetch", repoURL, sanitizedBranch)
 	if err != nil {
 		return fmt.Errorf("failed to fetch remote: %w\nOutput: %s", err, string(output))
 	}
 
 	// Step 2: Get the commit hash of FETCH_HEAD
-	cmd = exec.CommandContext(ctx, "git", "rev-parse", "FETCH_HEAD")
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "FETCH_HEAD")
 	output, err = cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get FETCH_HEAD: %w", err)
@@ -220,14 +219,13 @@ func SubtreePull(prefix, repoURL, branch string) error {
 	defer cancel()
 
 	// Step 1: Fetch the remote branch
-	cmd := exec.CommandContext(ctx, "git", "fetch", repoURL, sanitizedBranch)
-	output, err := cmd.CombinedOutput()
+	output, err := runRemote(ctx, "", "fetch", repoURL, sanitizedBranch)
 	if err != nil {
 		return fmt.Errorf("failed to fetch remote: %w\nOutput: %s", err, string(output))
 	}
 
 	// Step 2: Get the commit hash of FETCH_HEAD
-	cmd = exec.CommandContext(ctx, "git", "rev-parse", "FETCH_HEAD")
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "FETCH_HEAD")
 	output, err = cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get FETCH_HEAD: %w", err)
@@ -242,8 +240,9 @@ func SubtreePull(prefix, repoURL, branch string) error {
 	}
 	headCommit := strings.TrimSpace(string(output))
 
-	// Step 4: Remove existing subtree directory from index
-	cmd = exec.CommandContext(ctx, "git", "rm", "-rf", "--cached", sanitizedPrefix)
+	// Step 4: Remove the existing subtree from the index and working tree,
+	// so reading the fetched tree does not collide with the old files
+	cmd = exec.CommandContext(ctx, "git", "rm", "-rf", "-q", "--", sanitizedPrefix)
 	_, _ = cmd.CombinedOutput() // Ignore errors - directory might not exist in index
 
 	// Step 5: Read the fetched tree into the index at the prefix
@@ -334,8 +333,7 @@ func SubtreePush(prefix, repoURL, branch string) error {
 	splitCommit := strings.TrimSpace(string(output))
 
 	// Push the split commit to the remote branch
-	cmd = exec.CommandContext(ctx, "git", "push", repoURL, splitCommit+":refs/heads/"+sanitizedBranch)
-	output, err = cmd.CombinedOutput()
+	output, err = runRemote(ctx, "", "push", repoURL, splitCommit+":refs/heads/"+sanitizedBranch)
 	if err != nil {
 		return fmt.Errorf("failed to push: %w\nOutput: %s", err, string(output))
 	}
@@ -428,13 +426,12 @@ func CheckBehind(prefix, repoURL, branch string) (int, error) {
 	defer cancel()
 
 	// Fetch the latest from remote
-	cmd := exec.CommandContext(ctx, "git", "fetch", repoURL, sanitizedBranch)
-	if err := cmd.Run(); err != nil {
+	if _, err := runRemote(ctx, "", "fetch", repoURL, sanitizedBranch); err != nil {
 		return 0, fmt.Errorf("failed to fetch from remote")
 	}
 
 	// Get the commit count difference
-	cmd = exec.CommandContext(ctx, "git", "rev-list", "--count", "HEAD..FETCH_HEAD", "--", sanitizedPrefix)
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--count", "HEAD..FETCH_HEAD", "--", sanitizedPrefix)
 	output, err := cmd.Output()
 	if err != nil {
 		// If this fails, assume we're up to date
@@ -514,6 +511,40 @@ func GetCurrentBranch() (string, error) {
 	return branch, nil
 }
 
+// UserIdentity returns the git user.name and user.email configured for the
+// repository at path, falling back to the OS user name when git has none
+func UserIdentity(path string) (name, email string) {
+	if path == "" {
+		path = "."
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, key := range []string{"user.name", "user.email"} {
+		output, err := exec.CommandContext(ctx, "git", "-C", filepath.Clean(path), "config", "--get", key).Output()
+		if err != nil {
+			continue
+		}
+		if key == "user.name" {
+			name = strings.TrimSpace(string(output))
+		} else {
+			email = strings.TrimSpace(string(output))
+		}
+	}
+
+	if name == "" {
+		for _, env := range []string{"USER", "USERNAME"} {
+			if name = os.Getenv(env); name != "" {
+				break
+			}
+		}
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return name, email
+}
+
 // CommitChanges commits changes with a message
 func CommitChanges(message string) error {
 	// Check if we're in a git repository
@@ -565,6 +596,7 @@ var (
 	// Regex patterns for validation
 	validBranchName = regexp.MustCompile(`^[a-zA-Z0-9._/-]+$`)
 	validPrefix     = regexp.MustCompile(`^[a-zA-Z0-9._/-]+$`)
+	scpURLPattern   = regexp.MustCompile(`^[a-zA-Z0-9._-]+@[a-zA-Z0-9.-]+:[a-zA-Z0-9._/~-]+$`)
 )
 
 // isValidPath validates a file system path
@@ -644,6 +676,17 @@ func validateRepoURL(repoURL string) error {
 		return fmt.Errorf("repository URL too long (max 2048 characters)")
 	}
 
+	// scp-style SSH URLs (git@github.com:owner/repo.git) are not valid URLs
+	if scpURLPattern.MatchString(repoURL) {
+		return nil
+	}
+
+	// Mirrors and bundles on the filesystem serve machines without access
+	// to the upstream repository
+	if filepath.IsAbs(repoURL) {
+		return nil
+	}
+
 	// Parse URL to validate format
 	u, err := url.Parse(repoURL)
 	if err != nil {
@@ -658,14 +701,11 @@ func validateRepoURL(repoURL string) error {
 		"ssh":   true,
 	}
 
-	// Allow file:// URLs for testing (when running in test environments)
-	// This is detected by checking if we're in a temp directory used by tests
-	if u.Scheme == "file" && (strings.Contains(repoURL, "/tmp/") || strings.Contains(repoURL, os.TempDir())) {
-		allowedSchemes["file"] = true
-	}
+	// file:// URLs refer to a mirror or bundle like an absolute path
+	allowedSchemes["file"] = true
 
 	if !allowedSchemes[u.Scheme] {
-		return fmt.Errorf("unsupported URL scheme: %s (allowed: http, https, git, ssh)", u.Scheme)
+		return fmt.Errorf("unsupported URL scheme: %s (allowed: http, https, git, ssh, file or an absolute path)", u.Scheme)
 	}
 
 	// Additional validation for git URLs
@@ -353,3 +353,11 @@ func TestSubtreeOperations_EdgeCases(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not a git repository")
 }
+
+// TestValidateRepoURL_SSH tests that SSH repository URLs are accepted
+func TestValidateRepoURL_SSH(t *testing.T) {
+	assert.NoError(t, validateRepoURL("git@github.com:easel/ddx-library.git"))
+	assert.NoError(t, validateRepoURL("ssh://git@github.com/easel/ddx-library.git"))
+	assert.Error(t, validateRepoURL("git@github.com:easel/ddx;rm -rf"))
+	assert.Error(t, validateRepoURL("ftp://example.com/repo.git"))
+}
@@ -0,0 +1,193 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/easel/ddx/internal/network"
+)
+
+// errStalled is returned when a git command talking to a remote stops
+// making progress
+var errStalled = errors.New("no progress from the remote")
+
+// transientErrors are git messages of failures that may go away when
+// tried again, and permanentErrors those that will not; both lowercase
+var (
+	transientErrors = []string{
+		"could not resolve host",
+		"temporary failure in name resolution",
+		"connection timed out",
+		"connection refused",
+		"connection reset",
+		"operation timed out",
+		"failed to connect",
+		"early eof",
+		"the remote end hung up unexpectedly",
+		"rpc failed",
+		"the requested url returned error: 429",
+		"the requested url returned error: 5",
+	}
+	permanentErrors = []string{
+		"authentication failed",
+		"permission denied",
+		"repository not found",
+		"not found",
+		"couldn't find remote ref",
+		"rejected",
+	}
+)
+
+// runRemote runs a git command that talks to a remote, such as fetch or
+// push, in dir or the current directory when dir is empty, and returns its
+// combined output. The network settings bound it: the command is stopped
+// when the remote shows no progress for the timeout, so an unreachable
+// remote fails fast while a large transfer may take as long as it needs,
+// and failures that look transient are retried with backoff.
+func runRemote(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	settings := network.Current()
+	wait := settings.Backoff
+	for attempt := 0; ; attempt++ {
+		output, err := runWatched(ctx, settings.Timeout, dir, args)
+		if err == nil || attempt >= settings.Retries || ctx.Err() != nil || !isTransient(output, err) {
+			return output, err
+		}
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// runWatched runs git once, stopping it when it writes nothing for timeout.
+// Progress is requested so that a working transfer keeps writing.
+func runWatched(ctx context.Context, timeout time.Duration, dir string, args []string) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = network.DefaultTimeout
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", withProgress(args)...)
+	if dir != "" {
+		cmd.Dir = filepath.Clean(dir)
+	}
+	out := &watchedOutput{last: time.Now()}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Helpers like ssh may keep the output open after git is stopped
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ticker := time.NewTicker(max(timeout/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return out.Bytes(), err
+		case <-ticker.C:
+			if out.Idle() < timeout {
+				continue
+			}
+			cancel()
+			<-done
+			return out.Bytes(), fmt.Errorf("%w for %s", errStalled, timeout)
+		}
+	}
+}
+
+// withProgress adds --progress to the commands that transfer objects
+func withProgress(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	switch args[0] {
+	case "fetch", "push", "clone":
+		return append([]string{args[0], "--progress"}, args[1:]...)
+	}
+	return args
+}
+
+// isTransient reports whether a failed remote command may succeed when
+// run again
+func isTransient(output []byte, err error) bool {
+	if errors.Is(err, errStalled) {
+		return true
+	}
+	message := strings.ToLower(string(output))
+	for _, s := range permanentErrors {
+		if strings.Contains(message, s) {
+			return false
+		}
+	}
+	for _, s := range transientErrors {
+		if strings.Contains(message, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchedOutput collects a command's output and when it last wrote. The
+// progress lines git redraws with carriage returns are reduced to their
+// final state.
+type watchedOutput struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	last time.Time
+}
+
+func (w *watchedOutput) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.last = time.Now()
+	return w.buf.Write(p)
+}
+
+// Idle returns how long ago the command last wrote
+func (w *watchedOutput) Idle() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return time.Since(w.last)
+}
+
+// Bytes returns the output with progress redraws collapsed
+func (w *watchedOutput) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := strings.Split(w.buf.String(), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// remoteMessage picks git's error messages out of a remote command's
+// output, leaving out its progress
+func remoteMessage(output []byte) string {
+	var messages []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			messages = append(messages, line)
+		}
+	}
+	return strings.Join(messages, "\n")
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withNetwork configures the network settings for the rest of the test
+func withNetwork(t *testing.T, s network.Settings) {
+	previous := network.Current()
+	require.NoError(t, network.Configure(s))
+	t.Cleanup(func() { _ = network.Configure(previous) })
+}
+
+func TestRunRemoteStopsStalledRemote(t *testing.T) {
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		t.Setenv(name, "")
+	}
+	// A server that accepts connections and never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	var connections atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			defer func() { _ = conn.Close() }()
+		}
+	}()
+
+	withNetwork(t, network.Settings{Timeout: 300 * time.Millisecond, Retries: 1, Backoff: time.Millisecond})
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", dir).Run())
+
+	start := time.Now()
+	_, err = runRemote(context.Background(), dir, "fetch", "http://"+listener.Addr().String()+"/library.git", "main")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errStalled), "got %v", err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, int32(2), connections.Load(), "a stalled fetch is retried")
+}
+
+func TestRunRemoteLocalRepository(t *testing.T) {
+	withNetwork(t, network.Settings{Timeout: 5 * time.Second, Retries: 2, Backoff: time.Millisecond})
+	source := setupTestGitRepo(t)
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--quiet", dir).Run())
+
+	_, err := runRemote(context.Background(), dir, "fetch", source, "HEAD")
+	require.NoError(t, err)
+
+	output, err := runRemote(context.Background(), dir, "fetch", source, "no-such-branch")
+	require.Error(t, err)
+	assert.Contains(t, remoteMessage(output), "couldn't find remote ref")
+	assert.False(t, isTransient(output, err))
+}
+
+func TestIsTransient(t *testing.T) {
+	failed := errors.New("exit status 128")
+	assert.True(t, isTransient(nil, errStalled))
+	assert.True(t, isTransient([]byte("fatal: unable to access 'https://example.com/x.git/': Could not resolve host: example.com"), failed))
+	assert.True(t, isTransient([]byte("error: RPC failed; HTTP 503 curl 22 The requested URL returned error: 503"), failed))
+	assert.False(t, isTransient([]byte("git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository."), failed))
+	assert.False(t, isTransient([]byte("fatal: Authentication failed for 'https://example.com/x.git/'\nfatal: the remote end hung up unexpectedly"), failed))
+	assert.False(t, isTransient([]byte("! [rejected]        main -> main (non-fast-forward)"), failed))
+}
+
+func TestWatchedOutputCollapsesProgress(t *testing.T) {
+	out := &watchedOutput{}
+	_, _ = out.Write([]byte("Receiving objects:  50% (1/2)\rReceiving objects: 100% (2/2), done.\nfatal: oops\n"))
+	assert.Equal(t, "Receiving objects: 100% (2/2), done.\nfatal: oops\n", string(out.Bytes()))
+	assert.Equal(t, "fatal: oops", remoteMessage(out.Bytes()))
+}
@@ -0,0 +1,247 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Change statuses reported by Snapshot.Diff
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+// FileChange is a file that differs between two library revisions
+type FileChange struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// Snapshot is an upstream branch fetched into a temporary bare repository,
+// so it can be inspected without touching the project's repository
+type Snapshot struct {
+	Dir    string
+	Commit string
+}
+
+// FetchSnapshot fetches branch from repoURL into a temporary repository.
+// Close removes it.
+func FetchSnapshot(ctx context.Context, repoURL, branch string) (*Snapshot, error) {
+	if err := validateRepoURL(repoURL); err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if err := validateBranchName(branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "ddx-upstream-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary repository: %w", err)
+	}
+	s := &Snapshot{Dir: dir}
+	if _, err := s.git(ctx, "init", "--bare", "--quiet"); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 300*time.Second) // 5 minutes for network operations
+	defer cancel()
+	if output, err := runRemote(ctx, s.Dir, "fetch", repoURL, sanitizeInput(branch)); err != nil {
+		_ = s.Close()
+		if msg := remoteMessage(output); msg != "" {
+			err = fmt.Errorf("git fetch: %s", msg)
+		}
+		return nil, fmt.Errorf("failed to fetch %s from %s: %w", branch, repoURL, err)
+	}
+	output, err := s.git(ctx, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	s.Commit = strings.TrimSpace(output)
+	return s, nil
+}
+
+// Close removes the temporary repository
+func (s *Snapshot) Close() error {
+	return os.RemoveAll(s.Dir)
+}
+
+// HasCommit reports whether commit is part of the fetched history
+func (s *Snapshot) HasCommit(commit string) bool {
+	_, err := s.git(context.Background(), "cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// Files returns the blob hash of every file in the fetched commit, keyed by
+// slash-separated path
+func (s *Snapshot) Files() (map[string]string, error) {
+	output, err := s.git(context.Background(), "ls-tree", "-r", "-z", s.Commit)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, entry := range strings.Split(output, "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) == 3 && fields[1] == "blob" {
+			files[path] = fields[2]
+		}
+	}
+	return files, nil
+}
+
+// Diff lists the files changed between base and the fetched commit
+func (s *Snapshot) Diff(base string) ([]FileChange, error) {
+	output, err := s.git(context.Background(), "diff", "--name-status", "--no-renames", base, s.Commit)
+	if err != nil {
+		return nil, err
+	}
+	var changes []FileChange
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		status, path, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		change := FileChange{Path: path, Status: ChangeModified}
+		switch status {
+		case "A":
+			change.Status = ChangeAdded
+		case "D":
+			change.Status = ChangeDeleted
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// git runs a git command in the snapshot repository
+func (s *Snapshot) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", s.Dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
+
+// BlobHash returns the git object hash of a file's content, as listed by
+// Snapshot.Files
+func BlobHash(data []byte) string {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "blob %d\x00", len(data))
+	_, _ = h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LastSubtreeSync returns the upstream commit the subtree at prefix was last
+// synced to, read from the git-subtree-split trailer of the repository at
+// dir. It returns an empty string when no sync is recorded.
+func LastSubtreeSync(dir, prefix string) string {
+	if err := validatePrefix(prefix); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "git", "-C", filepath.Clean(dir), "log", "-1",
+		"--grep=git-subtree-dir: "+sanitizeInput(prefix), "--format=%B").Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if split, ok := strings.CutPrefix(strings.TrimSpace(line), "git-subtree-split:"); ok {
+			return strings.TrimSpace(split)
+		}
+	}
+	return ""
+}
+
+// SubtreeSyncCommit returns the commit of the repository at dir that
+// recorded the last sync of the subtree at prefix, by pull or by a
+// rejoined push. Commits after it are the ones a subtree push sends. It
+// returns an empty string when no sync is recorded.
+func SubtreeSyncCommit(dir, prefix string) string {
+	if err := validatePrefix(prefix); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "git", "-C", filepath.Clean(dir), "log", "-1",
+		"--grep=git-subtree-dir: "+sanitizeInput(prefix), "--format=%H").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// SubtreeFiles lists the files the subtree at prefix had upstream when it
+// was last synced, as slash-separated paths within the subtree. It reads
+// the split commit of the last sync, or the sync commit itself when the
+// subtree was squashed, from the repository at dir.
+func SubtreeFiles(dir, prefix string) (map[string]bool, error) {
+	if err := validatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	run := func(args ...string) (string, error) {
+		output, err := exec.CommandContext(ctx, "git", append([]string{"-C", filepath.Clean(dir)}, args...)...).Output()
+		return string(output), err
+	}
+
+	output, err := run("log", "-1", "--grep=git-subtree-dir: "+sanitizeInput(prefix), "--format=%H%n%B")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil, fmt.Errorf("no upstream sync recorded for %s", prefix)
+	}
+	syncCommit, body, _ := strings.Cut(output, "\n")
+	var trees []string
+	for _, line := range strings.Split(body, "\n") {
+		if split, ok := strings.CutPrefix(strings.TrimSpace(line), "git-subtree-split:"); ok {
+			trees = append(trees, strings.TrimSpace(split))
+		}
+	}
+	trees = append(trees, strings.TrimSpace(syncCommit))
+
+	subtreeDir := strings.Trim(prefix, "/") + "/"
+	for _, tree := range trees {
+		listing, err := run("ls-tree", "-r", "-z", "--name-only", tree)
+		if err != nil {
+			continue
+		}
+		all := strings.Split(strings.TrimRight(listing, "\x00"), "\x00")
+		files := make(map[string]bool)
+		// A merge commit holds the whole project; keep the subtree
+		for _, path := range all {
+			if rel, ok := strings.CutPrefix(path, subtreeDir); ok {
+				files[rel] = true
+			}
+		}
+		if len(files) == 0 {
+			for _, path := range all {
+				files[path] = true
+			}
+		}
+		return files, nil
+	}
+	return nil, fmt.Errorf("upstream commit of the last sync of %s is not in the repository", prefix)
+}
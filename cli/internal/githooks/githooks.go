@@ -0,0 +1,104 @@
+// Package githooks installs git hooks from the library. Library hooks are
+// copied into a ddx directory inside the repository's hooks directory and
+// run by a small dispatcher script per git event, which first runs any
+// hook that was there before ddx.
+package githooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest at the root of a library hook
+const ManifestFile = "hook.yml"
+
+// DefaultScript is the hook's executable when the manifest names none
+const DefaultScript = "hook.sh"
+
+// Events are the git hooks a library hook can be installed as
+var Events = []string{
+	"applypatch-msg", "pre-applypatch", "post-applypatch",
+	"pre-commit", "pre-merge-commit", "prepare-commit-msg", "commit-msg", "post-commit",
+	"pre-rebase", "post-checkout", "post-merge", "pre-push", "post-rewrite",
+}
+
+// Manifest describes a library hook from its hook.yml
+type Manifest struct {
+	Description string `yaml:"description"`
+	// Event is the git hook the script runs as, such as commit-msg
+	Event string `yaml:"event"`
+	// Script is the executable in the hook's directory
+	Script string `yaml:"script,omitempty"`
+}
+
+// LoadManifest reads the manifest of the hook in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if manifest.Script == "" {
+		manifest.Script = DefaultScript
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hook manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks that the manifest names a known event and a script
+// inside the hook's directory
+func (m *Manifest) Validate() error {
+	if !slices.Contains(Events, m.Event) {
+		return fmt.Errorf("unsupported git hook event %q", m.Event)
+	}
+	if !filepath.IsLocal(m.Script) {
+		return fmt.Errorf("script %q must be inside the hook directory", m.Script)
+	}
+	return nil
+}
+
+// Hook is a git hook found in a library, named by its directory
+type Hook struct {
+	Name     string
+	Dir      string
+	Manifest *Manifest
+}
+
+// Available lists the hooks in a library's hooks directory, sorted by
+// name. Directories without a manifest are not hooks.
+func Available(dir string) ([]Hook, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var found []Hook
+	for _, entry := range entries {
+		hookDir := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(hookDir, ManifestFile)); err != nil {
+			continue
+		}
+		manifest, err := LoadManifest(hookDir)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, Hook{Name: entry.Name(), Dir: hookDir, Manifest: manifest})
+	}
+	return found, nil
+}
@@ -0,0 +1,118 @@
+package githooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHook creates a library hook that runs script as event
+func writeHook(t *testing.T, libDir, name, event, script string) Hook {
+	t.Helper()
+	dir := filepath.Join(libDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFile), []byte("description: test hook\nevent: "+event+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DefaultScript), []byte(script), 0755))
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	return Hook{Name: name, Dir: dir, Manifest: manifest}
+}
+
+// runHook runs an installed git hook with a commit message file
+func runHook(t *testing.T, hooksDir, event, message string) error {
+	t.Helper()
+	msg := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(msg, []byte(message), 0644))
+	cmd := exec.Command("sh", filepath.Join(hooksDir, event), msg)
+	cmd.Stdin = strings.NewReader("")
+	return cmd.Run()
+}
+
+func TestAvailable(t *testing.T) {
+	libDir := t.TempDir()
+	writeHook(t, libDir, "conventional-commits", "commit-msg", "#!/bin/sh\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(libDir, "notes"), 0755))
+
+	hooks, err := Available(libDir)
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "conventional-commits", hooks[0].Name)
+	assert.Equal(t, "commit-msg", hooks[0].Manifest.Event)
+	assert.Equal(t, DefaultScript, hooks[0].Manifest.Script)
+
+	require.NoError(t, os.WriteFile(filepath.Join(libDir, "notes", ManifestFile), []byte("event: on-save\n"), 0644))
+	_, err = Available(libDir)
+	assert.ErrorContains(t, err, `unsupported git hook event "on-save"`)
+}
+
+func TestInstallChainsExistingHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	libDir, hooksDir := t.TempDir(), t.TempDir()
+	log := filepath.Join(t.TempDir(), "ran")
+	existing := "#!/bin/sh\necho existing >> " + log + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "commit-msg"), []byte(existing), 0755))
+
+	hook := writeHook(t, libDir, "conventional-commits", "commit-msg",
+		"#!/bin/sh\necho conventional >> "+log+"\ngrep -qE '^(feat|fix): ' \"$1\"\n")
+	require.NoError(t, Install(hooksDir, hook))
+
+	installed, err := Installed(hooksDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"conventional-commits": "commit-msg"}, installed)
+	kept, err := os.ReadFile(filepath.Join(hooksDir, "commit-msg"+BackupSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(kept))
+
+	assert.NoError(t, runHook(t, hooksDir, "commit-msg", "feat: add hooks\n"))
+	assert.Error(t, runHook(t, hooksDir, "commit-msg", "added hooks\n"))
+	ran, _ := os.ReadFile(log)
+	assert.Equal(t, "existing\nconventional\nexisting\nconventional\n", string(ran))
+
+	// Reinstalling keeps the original hook chained exactly once
+	require.NoError(t, Install(hooksDir, hook))
+	kept, err = os.ReadFile(filepath.Join(hooksDir, "commit-msg"+BackupSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(kept))
+
+	removed, err := Uninstall(hooksDir, "conventional-commits")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	restored, err := os.ReadFile(filepath.Join(hooksDir, "commit-msg"))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(restored))
+	assert.NoFileExists(t, filepath.Join(hooksDir, "commit-msg"+BackupSuffix))
+	assert.NoDirExists(t, filepath.Join(hooksDir, InstallDir))
+
+	removed, err = Uninstall(hooksDir, "conventional-commits")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestInstallPassesStdin(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	libDir, hooksDir := t.TempDir(), t.TempDir()
+	out := filepath.Join(t.TempDir(), "refs")
+	for _, name := range []string{"first", "second"} {
+		require.NoError(t, Install(hooksDir, writeHook(t, libDir, name, "pre-push", "#!/bin/sh\ncat >> "+out+"\n")))
+	}
+
+	cmd := exec.Command("sh", filepath.Join(hooksDir, "pre-push"), "origin", "url")
+	cmd.Stdin = strings.NewReader("refs/heads/main abc refs/heads/main def\n")
+	require.NoError(t, cmd.Run())
+	refs, _ := os.ReadFile(out)
+	assert.Equal(t, strings.Repeat("refs/heads/main abc refs/heads/main def\n", 2), string(refs))
+
+	removed, err := Uninstall(hooksDir, "first")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.FileExists(t, filepath.Join(hooksDir, "pre-push"), "dispatcher stays while hooks remain")
+}
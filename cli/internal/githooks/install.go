@@ -0,0 +1,189 @@
+package githooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// InstallDir is the directory inside the hooks directory that holds the
+// installed library hooks, one subdirectory per event
+const InstallDir = "ddx"
+
+// BackupSuffix is appended to a hook that existed before ddx installed its
+// dispatcher; the dispatcher keeps running it
+const BackupSuffix = ".pre-ddx"
+
+// dispatcherMarker identifies hook files written by ddx
+const dispatcherMarker = "# ddx:managed-hook"
+
+// dispatcherScript runs the previous hook, then each installed library
+// hook for an event, stopping at the first failure. Hooks such as pre-push
+// read stdin, so it is buffered and handed to each of them.
+const dispatcherScript = `#!/bin/sh
+` + dispatcherMarker + ` - generated by 'ddx hooks install', do not edit.
+# Runs %[1]s%[2]s if it exists, then each hook in %[3]s/%[1]s.
+hook_dir=$(dirname "$0")
+if [ -t 0 ]; then input=""; else input=$(cat); fi
+
+run_hook() {
+	if [ -n "$input" ]; then
+		printf '%%s\n' "$input" | "$@"
+	else
+		"$@" </dev/null
+	fi
+}
+
+if [ -x "$hook_dir/%[1]s%[2]s" ]; then
+	run_hook "$hook_dir/%[1]s%[2]s" "$@" || exit $?
+fi
+for hook in "$hook_dir/%[3]s/%[1]s"/*; do
+	[ -x "$hook" ] || continue
+	run_hook "$hook" "$@" || exit $?
+done
+`
+
+// HooksDir returns the hooks directory of the git repository containing
+// dir, honouring core.hooksPath
+func HooksDir(dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git repository", dir)
+	}
+	hooksDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// Installed maps the names of the library hooks installed in hooksDir to
+// their events
+func Installed(hooksDir string) (map[string]string, error) {
+	installed := map[string]string{}
+	events, err := os.ReadDir(filepath.Join(hooksDir, InstallDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return installed, nil
+		}
+		return nil, err
+	}
+	for _, event := range events {
+		if !event.IsDir() {
+			continue
+		}
+		hooks, err := os.ReadDir(filepath.Join(hooksDir, InstallDir, event.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range hooks {
+			installed[hook.Name()] = event.Name()
+		}
+	}
+	return installed, nil
+}
+
+// Install copies a library hook into hooksDir and makes sure the event's
+// dispatcher runs it. A hook already installed for another event is moved.
+func Install(hooksDir string, hook Hook) error {
+	script, err := os.ReadFile(filepath.Join(hook.Dir, hook.Manifest.Script))
+	if err != nil {
+		return fmt.Errorf("failed to read hook %s: %w", hook.Name, err)
+	}
+	if _, err := Uninstall(hooksDir, hook.Name); err != nil {
+		return err
+	}
+
+	event := hook.Manifest.Event
+	eventDir := filepath.Join(hooksDir, InstallDir, event)
+	if err := os.MkdirAll(eventDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", eventDir, err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(eventDir, hook.Name), script, 0755); err != nil {
+		return fmt.Errorf("failed to install hook %s: %w", hook.Name, err)
+	}
+	return installDispatcher(hooksDir, event)
+}
+
+// Uninstall removes an installed library hook, reporting whether it was
+// installed. The event's dispatcher is removed with its last hook, putting
+// back any hook that was there before.
+func Uninstall(hooksDir, name string) (bool, error) {
+	installed, err := Installed(hooksDir)
+	if err != nil {
+		return false, err
+	}
+	event, ok := installed[name]
+	if !ok {
+		return false, nil
+	}
+
+	eventDir := filepath.Join(hooksDir, InstallDir, event)
+	if err := os.Remove(filepath.Join(eventDir, name)); err != nil {
+		return false, fmt.Errorf("failed to uninstall hook %s: %w", name, err)
+	}
+	if remaining, err := os.ReadDir(eventDir); err != nil || len(remaining) > 0 {
+		return true, err
+	}
+	if err := os.Remove(eventDir); err != nil {
+		return true, err
+	}
+	_ = os.Remove(filepath.Join(hooksDir, InstallDir))
+	return true, removeDispatcher(hooksDir, event)
+}
+
+// installDispatcher writes the event's dispatcher, keeping a hook that was
+// already there as <event>.pre-ddx
+func installDispatcher(hooksDir, event string) error {
+	path := filepath.Join(hooksDir, event)
+	if existing, err := os.ReadFile(path); err == nil && !isDispatcher(existing) {
+		backup := path + BackupSuffix
+		if _, err := os.Stat(backup); err == nil {
+			return fmt.Errorf("cannot chain %s: both %s and %s exist", event, path, backup)
+		}
+		if err := os.Rename(path, backup); err != nil {
+			return fmt.Errorf("failed to keep existing %s hook: %w", event, err)
+		}
+	}
+	script := fmt.Sprintf(dispatcherScript, event, BackupSuffix, InstallDir)
+	if err := atomicfile.WriteFile(path, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write %s hook: %w", event, err)
+	}
+	return nil
+}
+
+// removeDispatcher deletes the event's dispatcher and restores the hook
+// it kept
+func removeDispatcher(hooksDir, event string) error {
+	path := filepath.Join(hooksDir, event)
+	if existing, err := os.ReadFile(path); err == nil {
+		if !isDispatcher(existing) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s hook: %w", event, err)
+		}
+	}
+	backup := path + BackupSuffix
+	if _, err := os.Stat(backup); err != nil {
+		return nil
+	}
+	if err := os.Rename(backup, path); err != nil {
+		return fmt.Errorf("failed to restore %s hook: %w", event, err)
+	}
+	return nil
+}
+
+// isDispatcher reports whether a hook file was written by ddx
+func isDispatcher(content []byte) bool {
+	return bytes.Contains(content, []byte(dispatcherMarker))
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// cacheEntry is a cached API response and the ETag it was served with
+type cacheEntry struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+// cacheKey names the cache file of a URL fetched with a token
+func cacheKey(url, token string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + token))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *Client) loadCache(key string) (*cacheEntry, error) {
+	if c.CacheDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.CacheDir, key+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// saveCache writes an entry through a temporary file so a concurrent ddx
+// never reads half of it
+func (c *Client) saveCache(key string, entry *cacheEntry) error {
+	if c.CacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.CacheDir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.CacheDir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.CacheDir, key+".json"))
+}
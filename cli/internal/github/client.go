@@ -0,0 +1,210 @@
+// Package github is the GitHub REST API client shared by the update check,
+// release queries and contribution commands. Requests are authenticated
+// with the stored github.com token when there is one, responses are cached
+// by ETag so repeated queries do not count against the rate limit, and an
+// exhausted rate limit is reported with when it resets and how to raise it.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/auth"
+	"github.com/easel/ddx/internal/network"
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// DefaultBaseURL is the GitHub REST API
+const DefaultBaseURL = "https://api.github.com"
+
+// TokenHost is the credential ID the API token is stored under, as with
+// 'ddx auth token github.com <token>'
+const TokenHost = "github.com"
+
+// CredentialStore looks up stored tokens; auth.DefaultManager implements it
+type CredentialStore interface {
+	GetCredential(ctx context.Context, platform auth.Platform, repository string) (*auth.Credential, error)
+}
+
+// Client queries the GitHub REST API
+type Client struct {
+	// BaseURL is the API root
+	BaseURL string
+	// HTTP sends the requests; nil uses the network configuration
+	HTTP *http.Client
+	// Credentials supplies the token when GITHUB_TOKEN and GH_TOKEN are
+	// unset; nil makes unauthenticated requests
+	Credentials CredentialStore
+	// CacheDir holds responses by ETag; "" disables caching
+	CacheDir string
+}
+
+// NewClient returns a client for api.github.com that caches responses in
+// the ddx cache directory
+func NewClient(credentials CredentialStore) *Client {
+	c := &Client{BaseURL: DefaultBaseURL, Credentials: credentials}
+	if dir, err := userdirs.Path(userdirs.Cache, "github"); err == nil {
+		c.CacheDir = dir
+	}
+	return c
+}
+
+// RateLimitError reports an exhausted API rate limit
+type RateLimitError struct {
+	Limit         int
+	Reset         time.Time
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "GitHub API rate limit exceeded"
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(" (%d requests per hour)", e.Limit)
+	}
+	if !e.Reset.IsZero() {
+		msg += fmt.Sprintf("; it resets at %s", e.Reset.Local().Format("15:04"))
+	}
+	if !e.Authenticated {
+		msg += "; set GITHUB_TOKEN or run 'ddx auth token github.com <token>' for a higher limit"
+	}
+	return msg
+}
+
+// APIError is an unsuccessful API response
+type APIError struct {
+	StatusCode int
+	Message    string
+	URL        string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitHub API returned status %d for %s", e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("GitHub API returned status %d for %s: %s", e.StatusCode, e.URL, e.Message)
+}
+
+// Get fetches an API path such as /repos/easel/ddx/releases/latest and
+// decodes the JSON response into v. When the rate limit is exhausted a
+// cached response is used if there is one.
+func (c *Client) Get(ctx context.Context, path string, v any) error {
+	body, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse GitHub API response for %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	url := strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	token := c.token(ctx)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	// Entries are kept per token so a private response is never served to
+	// another identity
+	key := cacheKey(url, token)
+	cached, _ := c.loadCache(key)
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = network.Client()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub API response: %w", err)
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			if err := c.saveCache(key, &cacheEntry{URL: url, ETag: etag, Body: body}); err != nil {
+				slog.Debug("could not cache GitHub API response", "url", url, "error", err)
+			}
+		}
+		return body, nil
+	}
+
+	if rateErr := rateLimit(resp, token != ""); rateErr != nil {
+		if cached != nil {
+			slog.Debug("using cached GitHub API response", "url", url, "error", rateErr)
+			return cached.Body, nil
+		}
+		return nil, rateErr
+	}
+	return nil, apiError(resp, url)
+}
+
+// token returns the API token from the environment or the credential store
+func (c *Client) token(ctx context.Context) string {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	if c.Credentials == nil {
+		return ""
+	}
+	cred, err := c.Credentials.GetCredential(ctx, auth.PlatformGitHub, TokenHost)
+	if err != nil {
+		return ""
+	}
+	return cred.Token
+}
+
+// rateLimit returns the error for a rate limited response, or nil when the
+// response failed for another reason
+func rateLimit(resp *http.Response, authenticated bool) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining != "0" && resp.Header.Get("Retry-After") == "" {
+		return nil
+	}
+	err := &RateLimitError{Authenticated: authenticated}
+	err.Limit, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if reset, convErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); convErr == nil {
+		err.Reset = time.Unix(reset, 0)
+	} else if after, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+		err.Reset = time.Now().Add(time.Duration(after) * time.Second)
+	}
+	return err
+}
+
+// apiError builds the error for an unsuccessful response from the message
+// GitHub includes in the body
+func apiError(resp *http.Response, url string) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	return &APIError{StatusCode: resp.StatusCode, Message: body.Message, URL: url}
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/easel/ddx/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore map[string]string
+
+func (s fakeStore) GetCredential(ctx context.Context, platform auth.Platform, repository string) (*auth.Credential, error) {
+	if token, ok := s[repository]; ok && platform == auth.PlatformGitHub {
+		return &auth.Credential{Token: token}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type release struct {
+	TagName string `json:"tag_name"`
+}
+
+func TestGetCachesByETag(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Bearer stored-token", r.Header.Get("Authorization"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"tag_name":"v1.2.0"}`))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL, HTTP: server.Client(), CacheDir: t.TempDir(),
+		Credentials: fakeStore{TokenHost: "stored-token"}}
+	for i := 0; i < 2; i++ {
+		var r release
+		require.NoError(t, c.Get(context.Background(), "/repos/easel/ddx/releases/latest", &r))
+		assert.Equal(t, "v1.2.0", r.TagName)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, int32(1), notModified.Load(), "the second request is conditional")
+}
+
+func TestGetRateLimited(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	var limited atomic.Bool
+	reset := time.Now().Add(20 * time.Minute).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited.Load() {
+			w.Header().Set("X-RateLimit-Limit", "60")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"tag_name":"v1.2.0"}`))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL, HTTP: server.Client(), CacheDir: t.TempDir()}
+	var r release
+	require.NoError(t, c.Get(context.Background(), "/cached", &r))
+
+	err := c.Get(context.Background(), "/missing", &r)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Contains(t, err.Error(), "Not Found")
+
+	// A rate limited request falls back to the cached response
+	limited.Store(true)
+	r = release{}
+	require.NoError(t, c.Get(context.Background(), "/cached", &r))
+	assert.Equal(t, "v1.2.0", r.TagName)
+
+	err = c.Get(context.Background(), "/uncached", &r)
+	var rateErr *RateLimitError
+	require.ErrorAs(t, err, &rateErr)
+	assert.Equal(t, 60, rateErr.Limit)
+	assert.True(t, reset.Equal(rateErr.Reset))
+	assert.Contains(t, err.Error(), "rate limit exceeded (60 requests per hour)")
+	assert.Contains(t, err.Error(), "ddx auth token github.com")
+}
+
+func TestTokenFromEnvironment(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "env-token")
+	c := &Client{Credentials: fakeStore{TokenHost: "stored-token"}}
+	assert.Equal(t, "env-token", c.token(context.Background()))
+
+	t.Setenv("GH_TOKEN", "")
+	assert.Equal(t, "stored-token", c.token(context.Background()))
+	assert.Empty(t, (&Client{}).token(context.Background()))
+}
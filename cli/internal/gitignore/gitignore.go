@@ -0,0 +1,183 @@
+// Package gitignore manages a marked block of a project's .gitignore that
+// keeps transient ddx files, such as backups and caches, out of version
+// control. Lines outside the block are never touched.
+package gitignore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// File is the ignore file ddx manages, relative to the project root
+const File = ".gitignore"
+
+// Markers around the managed block
+const (
+	BeginMarker = "# BEGIN DDX (managed by ddx; choose entries with gitignore.ignore in .ddx/config.yaml)"
+	EndMarker   = "# END DDX"
+)
+
+// Artifact is something ddx writes into a project
+type Artifact struct {
+	Name    string
+	Pattern string
+	// Ignored is whether the artifact is left out of version control
+	// unless the configuration says otherwise
+	Ignored     bool
+	Description string
+}
+
+// Artifacts are the files and directories the managed block can ignore,
+// in the order they are written
+var Artifacts = []Artifact{
+	{Name: "backups", Pattern: ".ddx/backups/", Ignored: true, Description: "copies of AI configuration files made before ddx rewrites them"},
+	{Name: "snapshots", Pattern: ".ddx/snapshots/", Ignored: true, Description: "snapshots 'ddx rollback' restores"},
+	{Name: "runs", Pattern: ".ddx/runs/", Ignored: true, Description: "output of workflow pipeline runs"},
+	{Name: "cache", Pattern: ".ddx/cache/", Ignored: true, Description: "cached downloads"},
+	{Name: "locks", Pattern: ".ddx/**/*.lock", Ignored: true, Description: "locks held while ddx updates a file"},
+	{Name: "local-config", Pattern: ".ddx.local.yml", Ignored: true, Description: "per-developer configuration overrides"},
+	{Name: "history", Pattern: ".ddx/history.jsonl", Ignored: true, Description: "log of ddx invocations"},
+	{Name: "persona-usage", Pattern: ".ddx/persona-usage.json", Ignored: true, Description: "when each persona was last loaded"},
+	{Name: "prompt-sync", Pattern: ".ddx/prompt-sync.json", Description: "files written by 'ddx prompts sync'"},
+	{Name: "helix-state", Pattern: ".helix-state.yml", Description: "HELIX workflow progress"},
+	{Name: "library", Pattern: ".ddx/library/", Description: "the library subtree"},
+}
+
+// Patterns returns the patterns of the ignored artifacts, with overrides
+// mapping artifact names to whether they are ignored
+func Patterns(overrides map[string]bool) ([]string, error) {
+	known := make(map[string]bool, len(Artifacts))
+	for _, a := range Artifacts {
+		known[a.Name] = true
+	}
+	var unknown []string
+	for name := range overrides {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown ddx artifact(s) in gitignore.ignore: %s (known: %s)", strings.Join(unknown, ", "), strings.Join(Names(), ", "))
+	}
+
+	var patterns []string
+	for _, a := range Artifacts {
+		ignored, ok := overrides[a.Name]
+		if !ok {
+			ignored = a.Ignored
+		}
+		if ignored {
+			patterns = append(patterns, a.Pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// Names returns the artifact names
+func Names() []string {
+	names := make([]string, len(Artifacts))
+	for i, a := range Artifacts {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// Update returns content with its managed block holding patterns. The
+// block replaces an existing one or is appended; without patterns it is
+// removed.
+func Update(content string, patterns []string) string {
+	before, after, found := cut(content)
+	var block string
+	if len(patterns) > 0 {
+		block = BeginMarker + "\n" + strings.Join(patterns, "\n") + "\n" + EndMarker + "\n"
+	}
+	if found {
+		if block == "" {
+			// Drop the blank line that separated the block
+			if strings.HasSuffix(before, "\n\n") && (after == "" || strings.HasPrefix(after, "\n")) {
+				before = strings.TrimSuffix(before, "\n")
+			}
+			return before + after
+		}
+		return before + block + after
+	}
+	if block == "" {
+		return content
+	}
+	switch {
+	case content == "":
+	case strings.HasSuffix(content, "\n\n"):
+	case strings.HasSuffix(content, "\n"):
+		content += "\n"
+	default:
+		content += "\n\n"
+	}
+	return content + block
+}
+
+// cut splits content around its managed block
+func cut(content string) (before, after string, found bool) {
+	start := strings.Index(content, BeginMarker)
+	if start < 0 || (start > 0 && content[start-1] != '\n') {
+		return content, "", false
+	}
+	rest := content[start:]
+	end := strings.Index(rest, "\n"+EndMarker)
+	if end < 0 {
+		return content, "", false
+	}
+	after = rest[end+len("\n"+EndMarker):]
+	after = strings.TrimPrefix(after, "\n")
+	return content[:start], after, true
+}
+
+// Sync writes the managed block of a project's .gitignore, reporting
+// whether the file changed. A .gitignore is only created when there is
+// something to ignore.
+func Sync(workingDir string, patterns []string) (bool, error) {
+	path := filepath.Join(workingDir, File)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", File, err)
+	}
+	updated := Update(string(data), patterns)
+	if updated == string(data) {
+		return false, nil
+	}
+	if err := atomicfile.WriteFile(path, []byte(updated), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", File, err)
+	}
+	return true, nil
+}
+
+// Current returns the patterns in a project's managed block, and whether
+// there is one
+func Current(workingDir string) ([]string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(workingDir, File))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", File, err)
+	}
+	content := string(data)
+	start := strings.Index(content, BeginMarker)
+	if _, _, found := cut(content); !found {
+		return nil, false, nil
+	}
+	block := content[start+len(BeginMarker):]
+	block = block[:strings.Index(block, "\n"+EndMarker)]
+	var patterns []string
+	for _, line := range strings.Split(block, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, true, nil
+}
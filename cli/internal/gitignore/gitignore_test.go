@@ -0,0 +1,72 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatterns(t *testing.T) {
+	patterns, err := Patterns(nil)
+	require.NoError(t, err)
+	assert.Contains(t, patterns, ".ddx/backups/")
+	assert.NotContains(t, patterns, ".helix-state.yml")
+
+	patterns, err = Patterns(map[string]bool{"backups": false, "helix-state": true})
+	require.NoError(t, err)
+	assert.NotContains(t, patterns, ".ddx/backups/")
+	assert.Contains(t, patterns, ".helix-state.yml")
+
+	_, err = Patterns(map[string]bool{"bogus": true})
+	assert.ErrorContains(t, err, "unknown ddx artifact(s) in gitignore.ignore: bogus")
+}
+
+func TestUpdate(t *testing.T) {
+	block := BeginMarker + "\n.ddx/backups/\n" + EndMarker + "\n"
+	tests := []struct {
+		name     string
+		content  string
+		patterns []string
+		want     string
+	}{
+		{name: "new file", patterns: []string{".ddx/backups/"}, want: block},
+		{name: "appended", content: "node_modules/", patterns: []string{".ddx/backups/"}, want: "node_modules/\n\n" + block},
+		{name: "replaced in place", content: "a\n\n" + BeginMarker + "\nold\n" + EndMarker + "\nb\n", patterns: []string{".ddx/backups/"}, want: "a\n\n" + block + "b\n"},
+		{name: "removed at the end", content: "a\n\n" + block, want: "a\n"},
+		{name: "removed in the middle", content: "a\n\n" + block + "\nb\n", want: "a\n\nb\n"},
+		{name: "removed alone", content: block, want: ""},
+		{name: "nothing to remove", content: "a\n", want: "a\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Update(tt.content, tt.patterns), tt.name)
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, File), []byte("bin/\n"), 0644))
+
+	changed, err := Sync(dir, []string{".ddx/backups/", ".ddx/cache/"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = Sync(dir, []string{".ddx/backups/", ".ddx/cache/"})
+	require.NoError(t, err)
+	assert.False(t, changed, "a second sync changes nothing")
+
+	patterns, found, err := Current(dir)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []string{".ddx/backups/", ".ddx/cache/"}, patterns)
+
+	_, err = Sync(dir, nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, File))
+	require.NoError(t, err)
+	assert.Equal(t, "bin/\n", string(data), "lines outside the block are kept")
+	_, found, err = Current(dir)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
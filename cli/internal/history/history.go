@@ -0,0 +1,94 @@
+// Package history keeps a local audit log of ddx invocations so a team can
+// find out which command changed a project file and when.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the history log, relative to the project root
+const FileName = ".ddx/history.jsonl"
+
+// Results of an invocation
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Entry records one ddx invocation
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Command is the full command path, e.g. "ddx persona bind"
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// Profile is the configuration profile that was active, if any
+	Profile    string `json:"profile,omitempty"`
+	User       string `json:"user,omitempty"`
+	Result     string `json:"result"`
+	ExitCode   int    `json:"exit_code"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Path returns the history log of a project
+func Path(workingDir string) string {
+	return filepath.Join(workingDir, filepath.FromSlash(FileName))
+}
+
+// Append adds an entry to the project's history log
+func Append(workingDir string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	path := Path(workingDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(FileName), err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", FileName, err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return file.Close()
+}
+
+// Read returns the most recent entries of the history log, oldest first.
+// A limit of zero or less returns every entry. Lines that do not decode
+// are skipped; a missing log has no entries.
+func Read(workingDir string, limit int) ([]Entry, error) {
+	file, err := os.Open(Path(workingDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", FileName, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
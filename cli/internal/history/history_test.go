@@ -0,0 +1,44 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	project := t.TempDir()
+
+	entries, err := Read(project, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, command := range []string{"ddx init", "ddx persona bind", "ddx update"} {
+		require.NoError(t, Append(project, Entry{Time: now, Command: command, Result: ResultOK}))
+	}
+	require.NoError(t, Append(project, Entry{Time: now, Command: "ddx update", Result: ResultError, ExitCode: 2, Error: "conflict"}))
+
+	// Lines that do not decode are skipped
+	file, err := os.OpenFile(Path(project), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries, err = Read(project, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "ddx init", entries[0].Command)
+	assert.True(t, entries[0].Time.Equal(now))
+
+	entries, err = Read(project, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ddx update", entries[0].Command)
+	assert.Equal(t, ResultError, entries[1].Result)
+	assert.Equal(t, 2, entries[1].ExitCode)
+}
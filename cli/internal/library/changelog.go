@@ -0,0 +1,250 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// ChangelogDir holds changelog fragments, relative to the library root
+const ChangelogDir = "changelog"
+
+// ChangelogFile is the assembled changelog at the library root
+const ChangelogFile = "CHANGELOG.md"
+
+// Change types, in the order release notes list them
+const (
+	ChangeAdded      = "added"
+	ChangeChanged    = "changed"
+	ChangeDeprecated = "deprecated"
+	ChangeRemoved    = "removed"
+	ChangeFixed      = "fixed"
+)
+
+// ChangeTypes lists the valid fragment types in release note order
+var ChangeTypes = []string{ChangeAdded, ChangeChanged, ChangeDeprecated, ChangeRemoved, ChangeFixed}
+
+// Fragment is the changelog entry for one contributed asset
+type Fragment struct {
+	Type    string `yaml:"type" json:"type"`
+	Name    string `yaml:"name" json:"name"`
+	Summary string `yaml:"summary" json:"summary"`
+	// Path is the fragment file relative to the library root, set when
+	// the fragment is written or loaded
+	Path string `yaml:"-" json:"path,omitempty"`
+}
+
+// CheckChangeType returns an error unless changeType is one of ChangeTypes
+func CheckChangeType(changeType string) error {
+	for _, t := range ChangeTypes {
+		if changeType == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown change type %q (expected one of %s)", changeType, strings.Join(ChangeTypes, ", "))
+}
+
+// Validate checks that the fragment has a known type, a name and a summary
+func (f Fragment) Validate() error {
+	if err := CheckChangeType(f.Type); err != nil {
+		return err
+	}
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("changelog fragment needs a name")
+	}
+	if strings.TrimSpace(f.Summary) == "" {
+		return fmt.Errorf("changelog fragment for %s needs a summary", f.Name)
+	}
+	return nil
+}
+
+// dirAssets are library directories whose assets are whole subdirectories
+var dirAssets = map[string]bool{
+	"templates":    true,
+	"workflows":    true,
+	"environments": true,
+	"tools":        true,
+}
+
+// AssetName returns the asset a library file belongs to: the directory for
+// templates and workflows, the file without its extension otherwise. Files
+// outside an asset directory, and changelog fragments, have no asset.
+func AssetName(rel string) string {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	if len(parts) < 2 || parts[0] == ChangelogDir {
+		return ""
+	}
+	if dirAssets[parts[0]] && len(parts) > 2 {
+		return parts[0] + "/" + parts[1]
+	}
+	return strings.TrimSuffix(rel, filepath.Ext(rel))
+}
+
+// conventionalPrefix matches a conventional commit type such as "feat:" or "fix(scope):"
+var conventionalPrefix = regexp.MustCompile(`^([a-z]+)(\([^)]*\))?!?:\s*`)
+
+// SummaryType derives a summary and change type from a contribution
+// message written as a conventional commit
+func SummaryType(message string) (string, string) {
+	message = strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	match := conventionalPrefix.FindStringSubmatch(message)
+	if match == nil {
+		return message, ChangeChanged
+	}
+	summary := strings.TrimSpace(message[len(match[0]):])
+	switch match[1] {
+	case "feat":
+		return summary, ChangeAdded
+	case "fix":
+		return summary, ChangeFixed
+	default:
+		return summary, ChangeChanged
+	}
+}
+
+// fragmentSlug turns an asset name into a file name component
+var fragmentSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// WriteFragment validates the fragment and writes it to the library's
+// changelog directory, named by date and asset. It returns the fragment
+// with Path set.
+func WriteFragment(root string, fragment Fragment, now time.Time) (Fragment, error) {
+	if err := fragment.Validate(); err != nil {
+		return fragment, err
+	}
+	dir := filepath.Join(root, ChangelogDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fragment, fmt.Errorf("failed to create changelog directory: %w", err)
+	}
+
+	slug := strings.Trim(fragmentSlug.ReplaceAllString(strings.ToLower(fragment.Name), "-"), "-")
+	base := now.Format("20060102") + "-" + slug
+	name := base + ".yml"
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d.yml", base, i)
+	}
+
+	data, err := yaml.Marshal(fragment)
+	if err != nil {
+		return fragment, fmt.Errorf("failed to encode changelog fragment: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fragment, fmt.Errorf("failed to write changelog fragment: %w", err)
+	}
+	fragment.Path = ChangelogDir + "/" + name
+	return fragment, nil
+}
+
+// LoadFragments reads the fragments in the library's changelog directory
+// in file name order. Invalid fragments are an error naming the file.
+func LoadFragments(root string) ([]Fragment, error) {
+	entries, err := os.ReadDir(filepath.Join(root, ChangelogDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changelog directory: %w", err)
+	}
+
+	var fragments []Fragment
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		rel := ChangelogDir + "/" + entry.Name()
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		var fragment Fragment
+		if err := yaml.Unmarshal(data, &fragment); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", rel, err)
+		}
+		if err := fragment.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		fragment.Path = rel
+		fragments = append(fragments, fragment)
+	}
+	return fragments, nil
+}
+
+// ReleaseNotes renders fragments as a release section grouped by change
+// type, with entries sorted by asset name
+func ReleaseNotes(version string, date time.Time, fragments []Fragment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s - %s\n", version, date.Format("2006-01-02"))
+	for _, changeType := range ChangeTypes {
+		var entries []Fragment
+		for _, f := range fragments {
+			if f.Type == changeType {
+				entries = append(entries, f)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+		fmt.Fprintf(&b, "\n### %s\n\n", strings.ToUpper(changeType[:1])+changeType[1:])
+		for _, f := range entries {
+			fmt.Fprintf(&b, "- **%s**: %s\n", f.Name, strings.TrimSpace(f.Summary))
+		}
+	}
+	return b.String()
+}
+
+// AssembleChangelog rolls the library's fragments into a release section
+// of CHANGELOG.md, newest first, and removes the fragment files. It returns
+// the fragments that were assembled, none when there were no fragments.
+func AssembleChangelog(root, version string, date time.Time) ([]Fragment, error) {
+	fragments, err := LoadFragments(root)
+	if err != nil || len(fragments) == 0 {
+		return nil, err
+	}
+
+	path := filepath.Join(root, ChangelogFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", ChangelogFile, err)
+	}
+	if bytes.Contains(existing, []byte("## "+version+" ")) {
+		return nil, fmt.Errorf("%s already has a %s section", ChangelogFile, version)
+	}
+
+	// Keep the title and any introduction above the newest release
+	header, previous := "# Changelog\n\n", string(existing)
+	if strings.HasPrefix(previous, "# ") {
+		if i := strings.Index(previous, "\n## "); i >= 0 {
+			header, previous = previous[:i+1], previous[i+1:]
+		} else {
+			header, previous = strings.TrimRight(previous, "\n")+"\n\n", ""
+		}
+	}
+	content := header + ReleaseNotes(version, date, fragments)
+	if previous != "" {
+		content += "\n" + previous
+	}
+	if err := atomicfile.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ChangelogFile, err)
+	}
+
+	for _, f := range fragments {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(f.Path))); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", f.Path, err)
+		}
+	}
+	return fragments, nil
+}
@@ -0,0 +1,131 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetName(t *testing.T) {
+	cases := map[string]string{
+		"prompts/common/review.md":           "prompts/common/review",
+		"personas/strict-code-reviewer.md":   "personas/strict-code-reviewer",
+		"templates/service/README.md":        "templates/service",
+		"workflows/helix/phases/01-frame.md": "workflows/helix",
+		"templates/README.md":                "templates/README",
+		"changelog/20250101-x.yml":           "",
+		"README.md":                          "",
+	}
+	for path, want := range cases {
+		assert.Equal(t, want, AssetName(path), path)
+	}
+}
+
+func TestSummaryType(t *testing.T) {
+	cases := []struct{ message, summary, changeType string }{
+		{"feat: add review prompt", "add review prompt", ChangeAdded},
+		{"fix(personas): correct roles", "correct roles", ChangeFixed},
+		{"docs: clarify usage\n\nlonger body", "clarify usage", ChangeChanged},
+		{"Improve the architect persona", "Improve the architect persona", ChangeChanged},
+	}
+	for _, c := range cases {
+		summary, changeType := SummaryType(c.message)
+		assert.Equal(t, c.summary, summary, c.message)
+		assert.Equal(t, c.changeType, changeType, c.message)
+	}
+}
+
+func TestChangelogFragments(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := WriteFragment(root, Fragment{Type: ChangeAdded, Name: "prompts/common/review", Summary: "Add a review prompt"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "changelog/20250301-prompts-common-review.yml", first.Path)
+
+	second, err := WriteFragment(root, Fragment{Type: ChangeFixed, Name: "prompts/common/review", Summary: "Fix typos"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "changelog/20250301-prompts-common-review-2.yml", second.Path)
+
+	_, err = WriteFragment(root, Fragment{Type: "improved", Name: "x", Summary: "y"}, now)
+	assert.ErrorContains(t, err, `unknown change type "improved"`)
+
+	fragments, err := LoadFragments(root)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Fragment{first, second}, fragments)
+
+	t.Run("invalid fragment", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{"changelog/bad.yml": "type: added\nname: personas/x\n"})
+		_, err := LoadFragments(root)
+		assert.ErrorContains(t, err, "changelog/bad.yml: changelog fragment for personas/x needs a summary")
+	})
+}
+
+func TestAssembleChangelog(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"CHANGELOG.md":    "# Changelog\n\nAll notable changes.\n\n## 1.0.0 - 2025-01-01\n\n### Added\n\n- **personas/a**: First\n",
+		"changelog/1.yml": "type: fixed\nname: prompts/z\nsummary: Fix z\n",
+		"changelog/2.yml": "type: added\nname: templates/service\nsummary: Add service template\n",
+		"changelog/3.yml": "type: added\nname: personas/b\nsummary: Add b\n",
+	})
+
+	fragments, err := AssembleChangelog(root, "1.1.0", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, fragments, 3)
+
+	content, err := os.ReadFile(filepath.Join(root, ChangelogFile))
+	require.NoError(t, err)
+	assert.Equal(t, `# Changelog
+
+All notable changes.
+
+## 1.1.0 - 2025-02-01
+
+### Added
+
+- **personas/b**: Add b
+- **templates/service**: Add service template
+
+### Fixed
+
+- **prompts/z**: Fix z
+
+## 1.0.0 - 2025-01-01
+
+### Added
+
+- **personas/a**: First
+`, string(content))
+
+	remaining, err := LoadFragments(root)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	t.Run("nothing to assemble", func(t *testing.T) {
+		fragments, err := AssembleChangelog(t.TempDir(), "1.0.0", time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, fragments)
+	})
+
+	t.Run("version already released", func(t *testing.T) {
+		writeFiles(t, root, map[string]string{"changelog/4.yml": "type: changed\nname: personas/b\nsummary: Tweak b\n"})
+		_, err := AssembleChangelog(root, "1.1.0", time.Now())
+		assert.ErrorContains(t, err, "already has a 1.1.0 section")
+	})
+
+	t.Run("new changelog", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{"changelog/1.yml": "type: removed\nname: personas/old\nsummary: Remove old\n"})
+		_, err := AssembleChangelog(root, "2.0.0", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(root, ChangelogFile))
+		require.NoError(t, err)
+		assert.Equal(t, "# Changelog\n\n## 2.0.0 - 2025-05-01\n\n### Removed\n\n- **personas/old**: Remove old\n", string(content))
+	})
+}
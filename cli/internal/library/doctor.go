@@ -0,0 +1,399 @@
+// Package library inspects a DDx library checkout for maintenance problems.
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Priority ranks how urgently a problem should be fixed
+type Priority int
+
+const (
+	// PriorityHigh problems break the asset for users
+	PriorityHigh Priority = iota
+	// PriorityMedium problems degrade the asset or its discoverability
+	PriorityMedium
+	// PriorityLow problems are housekeeping
+	PriorityLow
+)
+
+// String returns the priority name
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// MarshalText encodes the priority by name
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Check names
+const (
+	CheckFrontmatter     = "frontmatter"
+	CheckReadme          = "category-readme"
+	CheckWorkflow        = "workflow-definition"
+	CheckSize            = "oversized-file"
+	CheckDeprecation     = "stale-deprecation"
+	CheckOrphan          = "orphaned-file"
+	CheckWorkflowCommand = "workflow-command"
+	CheckLink            = "broken-link"
+	CheckDuplicate       = "duplicate-name"
+)
+
+// Problem is a single finding in a library
+type Problem struct {
+	Check    string   `json:"check"`
+	Priority Priority `json:"priority"`
+	// Path is relative to the library root, using forward slashes
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// Options configure a library inspection
+type Options struct {
+	// MaxFileSize is the size in bytes above which files are reported
+	MaxFileSize int64
+	// DeprecationWindow is how long deprecated assets may remain
+	DeprecationWindow time.Duration
+	// Now is the reference time for deprecation checks
+	Now time.Time
+}
+
+// DefaultOptions returns the standard inspection thresholds
+func DefaultOptions() Options {
+	return Options{
+		MaxFileSize:       1 << 20,
+		DeprecationWindow: 90 * 24 * time.Hour,
+		Now:               time.Now(),
+	}
+}
+
+// Report is the result of inspecting a library
+type Report struct {
+	Path     string    `json:"path"`
+	Files    int       `json:"files"`
+	Problems []Problem `json:"problems"`
+}
+
+// Count returns the number of problems with the given priority
+func (r *Report) Count(priority Priority) int {
+	count := 0
+	for _, p := range r.Problems {
+		if p.Priority == priority {
+			count++
+		}
+	}
+	return count
+}
+
+// ByCheck groups problems by the check that found them
+func (r *Report) ByCheck() map[string][]Problem {
+	groups := make(map[string][]Problem)
+	for _, p := range r.Problems {
+		groups[p.Check] = append(groups[p.Check], p)
+	}
+	return groups
+}
+
+// assetDirs are library directories whose markdown files are assets that
+// carry frontmatter metadata
+var assetDirs = map[string]Priority{
+	"personas": PriorityHigh,
+	"prompts":  PriorityLow,
+	"patterns": PriorityLow,
+}
+
+// categoryParents are directories whose subdirectories are categories
+var categoryParents = []string{"prompts", "patterns", "templates"}
+
+// Inspect walks the library at root and reports maintenance problems,
+// ordered by priority and then path
+func Inspect(root string, opts Options) (*Report, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("library not found: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("library path is not a directory: %s", root)
+	}
+
+	report := &Report{Path: root}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		report.Files++
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+			report.add(Problem{
+				Check:    CheckSize,
+				Priority: PriorityMedium,
+				Path:     rel,
+				Message:  fmt.Sprintf("file is %s (limit %s)", formatSize(info.Size()), formatSize(opts.MaxFileSize)),
+				Fix:      "Shrink the file or replace it with a .ddx-asset pointer fetched with 'ddx fetch'",
+			})
+		}
+
+		checkOrphan(report, rel)
+		if strings.HasSuffix(rel, ".md") {
+			checkMarkdown(report, path, rel, opts)
+			checkLinks(report, root, path, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk library: %w", err)
+	}
+
+	checkCategoryReadmes(report, root)
+	checkWorkflows(report, root)
+	checkDuplicates(report, root)
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Path < b.Path
+	})
+	return report, nil
+}
+
+// add records a problem
+func (r *Report) add(p Problem) {
+	r.Problems = append(r.Problems, p)
+}
+
+// checkMarkdown checks an asset's frontmatter and deprecation status
+func checkMarkdown(report *Report, path, rel string, opts Options) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	topDir, _, _ := strings.Cut(rel, "/")
+	priority, isAsset := assetDirs[topDir]
+	if filepath.Base(rel) == "README.md" {
+		isAsset = false
+	}
+
+	frontmatter, ok := splitFrontmatter(content)
+	if !ok {
+		if isAsset {
+			report.add(Problem{
+				Check:    CheckFrontmatter,
+				Priority: priority,
+				Path:     rel,
+				Message:  "missing YAML frontmatter",
+				Fix:      "Add a --- delimited header with name, description and tags",
+			})
+		}
+		return
+	}
+
+	var meta map[string]interface{}
+	if err := yaml.Unmarshal(frontmatter, &meta); err != nil {
+		report.add(Problem{
+			Check:    CheckFrontmatter,
+			Priority: PriorityHigh,
+			Path:     rel,
+			Message:  fmt.Sprintf("frontmatter is not valid YAML: %v", err),
+			Fix:      "Correct the YAML between the --- delimiters",
+		})
+		return
+	}
+
+	if topDir == "personas" && isAsset {
+		name := strings.TrimSuffix(filepath.Base(rel), persona.PersonaFileExtension)
+		if _, err := persona.NewPersonaLoaderWithDir(filepath.Dir(path)).LoadPersona(name); err != nil {
+			report.add(Problem{
+				Check:    CheckFrontmatter,
+				Priority: PriorityHigh,
+				Path:     rel,
+				Message:  err.Error(),
+				Fix:      "Personas need name, roles and description in their frontmatter",
+			})
+		}
+	}
+
+	checkDeprecation(report, rel, meta, opts)
+}
+
+// checkDeprecation reports assets deprecated for longer than the window
+func checkDeprecation(report *Report, rel string, meta map[string]interface{}, opts Options) {
+	value, ok := meta["deprecated"]
+	if !ok || value == false || value == nil {
+		return
+	}
+
+	var since time.Time
+	switch v := value.(type) {
+	case time.Time:
+		since = v
+	case string:
+		since, _ = time.Parse("2006-01-02", v)
+	}
+
+	if since.IsZero() {
+		report.add(Problem{
+			Check:    CheckDeprecation,
+			Priority: PriorityLow,
+			Path:     rel,
+			Message:  "deprecated without a date",
+			Fix:      "Set deprecated: YYYY-MM-DD so the removal window can be tracked",
+		})
+		return
+	}
+
+	if opts.DeprecationWindow > 0 && opts.Now.Sub(since) > opts.DeprecationWindow {
+		fix := "Remove the asset"
+		if replacement, ok := meta["replaced_by"].(string); ok && replacement != "" {
+			fix = fmt.Sprintf("Remove the asset; users should move to %s", replacement)
+		}
+		report.add(Problem{
+			Check:    CheckDeprecation,
+			Priority: PriorityMedium,
+			Path:     rel,
+			Message:  fmt.Sprintf("deprecated since %s, past the %d day removal window", since.Format("2006-01-02"), int(opts.DeprecationWindow.Hours()/24)),
+			Fix:      fix,
+		})
+	}
+}
+
+// checkCategoryReadmes reports top-level directories and categories
+// without a README.md
+func checkCategoryReadmes(report *Report, root string) {
+	var dirs []string
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dirs = append(dirs, entry.Name())
+	}
+	for _, parent := range categoryParents {
+		children, _ := os.ReadDir(filepath.Join(root, parent))
+		for _, child := range children {
+			if child.IsDir() && !strings.HasPrefix(child.Name(), ".") {
+				dirs = append(dirs, parent+"/"+child.Name())
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), "README.md")); err == nil {
+			continue
+		}
+		priority := PriorityLow
+		if !strings.Contains(dir, "/") {
+			priority = PriorityMedium
+		}
+		report.add(Problem{
+			Check:    CheckReadme,
+			Priority: priority,
+			Path:     dir,
+			Message:  "category has no README.md",
+			Fix:      "Add a README.md describing the category and how to use its assets",
+		})
+	}
+}
+
+// checkWorkflows loads every workflow definition in the library and checks
+// its phases and commands
+func checkWorkflows(report *Report, root string) {
+	entries, _ := os.ReadDir(filepath.Join(root, "workflows"))
+	loader := workflow.NewLoader(root)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		rel := "workflows/" + entry.Name() + "/workflow.yml"
+		def, err := loader.Load(entry.Name())
+		if err != nil {
+			report.add(Problem{
+				Check:    CheckWorkflow,
+				Priority: PriorityHigh,
+				Path:     rel,
+				Message:  err.Error(),
+				Fix:      "Fix workflow.yml so the workflow can be activated",
+			})
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, phase := range def.Phases {
+			if phase.ID == "" {
+				report.add(Problem{Check: CheckWorkflow, Priority: PriorityHigh, Path: rel,
+					Message: fmt.Sprintf("phase %q has no id", phase.Name), Fix: "Give every phase a unique id"})
+				continue
+			}
+			if seen[phase.ID] {
+				report.add(Problem{Check: CheckWorkflow, Priority: PriorityHigh, Path: rel,
+					Message: fmt.Sprintf("duplicate phase id %q", phase.ID), Fix: "Give every phase a unique id"})
+			}
+			seen[phase.ID] = true
+		}
+		checkWorkflowCommands(report, root, entry.Name(), def)
+	}
+}
+
+// splitFrontmatter returns the YAML between leading --- delimiters
+func splitFrontmatter(content []byte) ([]byte, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil, false
+	}
+
+	var lines []string
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "---" {
+			return []byte(strings.Join(lines, "\n")), true
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return nil, false
+}
+
+// formatSize renders a byte count for messages
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
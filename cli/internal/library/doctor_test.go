@@ -0,0 +1,134 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files under root from a path to content map
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+}
+
+// problemFor returns the problem reported by check for path
+func problemFor(report *Report, check, path string) *Problem {
+	for i := range report.Problems {
+		if report.Problems[i].Check == check && report.Problems[i].Path == path {
+			return &report.Problems[i]
+		}
+	}
+	return nil
+}
+
+func TestInspect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"personas/README.md":   "# Personas",
+		"personas/good.md":     "---\nname: good\nroles: [reviewer]\ndescription: Good persona\n---\n\n# Good\n",
+		"personas/no-front.md": "# No frontmatter\n",
+		"personas/no-roles.md": "---\nname: no-roles\ndescription: Missing roles\n---\n\n# No roles\n",
+		"prompts/README.md":    "# Prompts",
+		"prompts/common/a.md":  "# Plain prompt\n",
+		"prompts/common/old.md": "---\ndescription: Old prompt\ndeprecated: 2024-01-01\nreplaced_by: prompts/common/new.md\n" +
+			"---\n\n# Old\n",
+		"prompts/common/undated.md":     "---\ndeprecated: true\n---\n# Undated\n",
+		"prompts/common/README.md":      "# Common",
+		"workflows/README.md":           "# Workflows",
+		"workflows/broken/workflow.yml": "name: broken\n",
+		"workflows/dupes/workflow.yml": "name: dupes\nversion: 1.0.0\nphases:\n" +
+			"  - id: frame\n    order: 1\n  - id: frame\n    order: 2\n",
+		"templates/big.bin": strings.Repeat("x", 2048),
+	})
+
+	opts := Options{
+		MaxFileSize:       1024,
+		DeprecationWindow: 90 * 24 * time.Hour,
+		Now:               time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	report, err := Inspect(root, opts)
+	require.NoError(t, err)
+
+	assert.Nil(t, problemFor(report, CheckFrontmatter, "personas/good.md"))
+	assert.Nil(t, problemFor(report, CheckFrontmatter, "personas/README.md"))
+
+	if p := problemFor(report, CheckFrontmatter, "personas/no-front.md"); assert.NotNil(t, p) {
+		assert.Equal(t, PriorityHigh, p.Priority)
+	}
+	if p := problemFor(report, CheckFrontmatter, "personas/no-roles.md"); assert.NotNil(t, p) {
+		assert.Equal(t, PriorityHigh, p.Priority)
+	}
+	if p := problemFor(report, CheckFrontmatter, "prompts/common/a.md"); assert.NotNil(t, p) {
+		assert.Equal(t, PriorityLow, p.Priority)
+	}
+	if p := problemFor(report, CheckDeprecation, "prompts/common/old.md"); assert.NotNil(t, p) {
+		assert.Equal(t, PriorityMedium, p.Priority)
+		assert.Contains(t, p.Fix, "prompts/common/new.md")
+	}
+	assert.NotNil(t, problemFor(report, CheckDeprecation, "prompts/common/undated.md"))
+	assert.NotNil(t, problemFor(report, CheckWorkflow, "workflows/broken/workflow.yml"))
+	if p := problemFor(report, CheckWorkflow, "workflows/dupes/workflow.yml"); assert.NotNil(t, p) {
+		assert.Contains(t, p.Message, `duplicate phase id "frame"`)
+	}
+	assert.NotNil(t, problemFor(report, CheckSize, "templates/big.bin"))
+	assert.NotNil(t, problemFor(report, CheckReadme, "templates"))
+	assert.Nil(t, problemFor(report, CheckReadme, "prompts/common"))
+
+	// Problems are ordered by priority
+	for i := 1; i < len(report.Problems); i++ {
+		assert.LessOrEqual(t, report.Problems[i-1].Priority, report.Problems[i].Priority)
+	}
+}
+
+func TestInspect_DeprecationWithinWindow(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"prompts/README.md": "# Prompts",
+		"prompts/recent.md": "---\ndeprecated: 2024-12-15\n---\n# Recent\n",
+	})
+
+	opts := DefaultOptions()
+	opts.Now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	report, err := Inspect(root, opts)
+	require.NoError(t, err)
+	assert.Nil(t, problemFor(report, CheckDeprecation, "prompts/recent.md"))
+}
+
+func TestInspect_MissingLibrary(t *testing.T) {
+	_, err := Inspect(filepath.Join(t.TempDir(), "missing"), DefaultOptions())
+	assert.Error(t, err)
+}
+
+func TestCreateIssues(t *testing.T) {
+	report := &Report{Problems: []Problem{
+		{Check: CheckWorkflow, Priority: PriorityHigh, Path: "workflows/x/workflow.yml", Message: "broken", Fix: "fix it"},
+		{Check: CheckReadme, Priority: PriorityMedium, Path: "tools", Message: "no readme"},
+		{Check: CheckReadme, Priority: PriorityLow, Path: "prompts/a", Message: "no readme"},
+	}}
+
+	var titles, bodies []string
+	urls, err := CreateIssues(report, func(title, body string) (string, error) {
+		titles = append(titles, title)
+		bodies = append(bodies, body)
+		return "https://example.com/issues/" + string(rune('0'+len(titles))), nil
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, urls, 2)
+	assert.Equal(t, []string{
+		"Library doctor: 1 workflow-definition problem(s)",
+		"Library doctor: 2 category-readme problem(s)",
+	}, titles)
+	assert.Contains(t, bodies[0], "- [ ] **high** `workflows/x/workflow.yml`: broken — fix it")
+	assert.Contains(t, bodies[1], "`prompts/a`")
+}
@@ -0,0 +1,284 @@
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/resolve"
+	"github.com/easel/ddx/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// leftoverSuffixes mark editor, merge and backup files that are never part
+// of an asset
+var leftoverSuffixes = []string{".orig", ".rej", ".bak", ".tmp", ".swp", "~"}
+
+// checkOrphan reports files that no part of ddx reads: leftovers from
+// editors and merges, files in directories that are not asset types, and
+// persona directory files that are not personas
+func checkOrphan(report *Report, rel string) {
+	name := path.Base(rel)
+	for _, suffix := range leftoverSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			report.add(Problem{
+				Check:    CheckOrphan,
+				Priority: PriorityLow,
+				Path:     rel,
+				Message:  "leftover editor, merge or backup file",
+				Fix:      "Delete the file",
+			})
+			return
+		}
+	}
+
+	topDir, rest, nested := strings.Cut(rel, "/")
+	if !nested {
+		return
+	}
+	switch {
+	case !isAssetType(topDir) && topDir != ChangelogDir:
+		report.add(Problem{
+			Check:    CheckOrphan,
+			Priority: PriorityLow,
+			Path:     rel,
+			Message:  fmt.Sprintf("'%s' is not a library asset type, so the file is never used", topDir),
+			Fix:      fmt.Sprintf("Move the file under one of: %s", strings.Join(resolve.Types, ", ")),
+		})
+	case topDir == "personas" && !strings.HasSuffix(rest, ".md"):
+		report.add(Problem{
+			Check:    CheckOrphan,
+			Priority: PriorityLow,
+			Path:     rel,
+			Message:  "personas are markdown files; this file is never loaded",
+			Fix:      "Convert it to a .md persona or remove it",
+		})
+	}
+}
+
+// isAssetType reports whether a top-level directory holds library assets
+func isAssetType(dir string) bool {
+	for _, t := range resolve.Types {
+		if t == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// markdownLink matches inline links and images; the target ends at the
+// first space so titles are ignored
+var markdownLink = regexp.MustCompile(`!?\[[^\]]*\]\(<?([^)\s>]+)>?(?:\s+"[^"]*")?\)`)
+
+// checkLinks reports relative links in a markdown file whose target does not
+// exist in the library. Links inside fenced code blocks are not checked, nor
+// are templates, whose links are placeholders for the generated document.
+func checkLinks(report *Report, root, file, rel string) {
+	if strings.HasPrefix(rel, "templates/") || strings.HasPrefix(path.Base(rel), "template") {
+		return
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	inFence := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, match := range markdownLink.FindAllStringSubmatch(text, -1) {
+			target := linkTarget(match[1])
+			if target == "" {
+				continue
+			}
+			resolved := path.Join(path.Dir(rel), target)
+			if resolved == ".." || strings.HasPrefix(resolved, "../") {
+				// Links out of the library cannot be checked here
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(resolved))); err == nil {
+				continue
+			}
+			report.add(Problem{
+				Check:    CheckLink,
+				Priority: PriorityMedium,
+				Path:     rel,
+				Message:  fmt.Sprintf("line %d links to %s, which does not exist", line, match[1]),
+				Fix:      "Point the link at an existing file or remove it",
+			})
+		}
+	}
+}
+
+// linkTarget returns the file part of a relative link, or an empty string
+// for URLs, anchors, globs, template placeholders and absolute paths, which
+// point into the project using the library rather than the library itself
+func linkTarget(link string) string {
+	if strings.Contains(link, "://") || strings.HasPrefix(link, "mailto:") || strings.HasPrefix(link, "#") ||
+		strings.HasPrefix(link, "/") || strings.ContainsAny(link, "*{") {
+		return ""
+	}
+	if i := strings.IndexAny(link, "#?"); i >= 0 {
+		link = link[:i]
+	}
+	return link
+}
+
+// checkWorkflowCommands reports commands a workflow declares or runs in a
+// pipeline without a prompt file
+func checkWorkflowCommands(report *Report, root, name string, def *workflow.Definition) {
+	dir := filepath.Join(root, "workflows", name)
+	rel := "workflows/" + name + "/workflow.yml"
+	missing := func(command string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(def.CommandPromptPath(command))))
+		return err != nil
+	}
+
+	for _, command := range def.Commands {
+		if missing(command.Name) {
+			report.add(Problem{
+				Check:    CheckWorkflowCommand,
+				Priority: PriorityHigh,
+				Path:     rel,
+				Message:  fmt.Sprintf("command '%s' has no prompt file %s", command.Name, command.PromptPath()),
+				Fix:      "Add the prompt file or fix the command's prompt path",
+			})
+		}
+	}
+
+	pipelines := make([]string, 0, len(def.Pipelines))
+	for pipeline := range def.Pipelines {
+		pipelines = append(pipelines, pipeline)
+	}
+	sort.Strings(pipelines)
+	for _, pipeline := range pipelines {
+		for _, step := range def.Pipelines[pipeline] {
+			if missing(step.Command) {
+				report.add(Problem{
+					Check:    CheckWorkflowCommand,
+					Priority: PriorityHigh,
+					Path:     rel,
+					Message:  fmt.Sprintf("pipeline '%s' runs unknown command '%s'", pipeline, step.Command),
+					Fix:      fmt.Sprintf("Add %s or remove the step", def.CommandPromptPath(step.Command)),
+				})
+			}
+		}
+	}
+}
+
+// fileAssetTypes are the types whose assets are single files found at any
+// depth; the assets of other types are the entries of the type directory
+var fileAssetTypes = map[string]bool{"prompts": true, "personas": true}
+
+// checkDuplicates reports assets of one type that share a name: entries
+// that differ only in case or extension, which resolve to the same asset,
+// and personas declaring the same name in their frontmatter
+func checkDuplicates(report *Report, root string) {
+	for _, assetType := range resolve.Types {
+		if !fileAssetTypes[assetType] {
+			checkDuplicateEntries(report, root, filepath.Join(root, assetType))
+			continue
+		}
+		_ = filepath.WalkDir(filepath.Join(root, assetType), func(dir string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") && dir != filepath.Join(root, assetType) {
+				return filepath.SkipDir
+			}
+			checkDuplicateEntries(report, root, dir)
+			return nil
+		})
+	}
+	checkDuplicatePersonas(report, root)
+}
+
+// checkDuplicateEntries reports entries of dir that name the same asset
+func checkDuplicateEntries(report *Report, root, dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	byName := make(map[string][]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || strings.EqualFold(name, "README.md") {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		byName[key] = append(byName[key], name)
+	}
+
+	relDir, _ := filepath.Rel(root, dir)
+	relDir = filepath.ToSlash(relDir)
+	for _, names := range byName {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		report.add(Problem{
+			Check:    CheckDuplicate,
+			Priority: PriorityHigh,
+			Path:     relDir + "/" + names[0],
+			Message:  fmt.Sprintf("%s name the same asset", strings.Join(names, ", ")),
+			Fix:      "Keep one of them; names that differ only in case also clash on macOS and Windows",
+		})
+	}
+}
+
+// checkDuplicatePersonas reports persona names declared by more than one file
+func checkDuplicatePersonas(report *Report, root string) {
+	byName := make(map[string][]string)
+	_ = filepath.WalkDir(filepath.Join(root, "personas"), func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".md") || d.Name() == "README.md" {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil
+		}
+		frontmatter, ok := splitFrontmatter(content)
+		if !ok {
+			return nil
+		}
+		var meta struct {
+			Name string `yaml:"name"`
+		}
+		if yaml.Unmarshal(frontmatter, &meta) != nil || meta.Name == "" {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, file)
+		byName[meta.Name] = append(byName[meta.Name], filepath.ToSlash(rel))
+		return nil
+	})
+
+	for name, files := range byName {
+		if len(files) < 2 {
+			continue
+		}
+		sort.Strings(files)
+		report.add(Problem{
+			Check:    CheckDuplicate,
+			Priority: PriorityHigh,
+			Path:     files[0],
+			Message:  fmt.Sprintf("persona name '%s' is also declared by %s", name, strings.Join(files[1:], ", ")),
+			Fix:      "Give each persona a unique name",
+		})
+	}
+}
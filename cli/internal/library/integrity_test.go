@@ -0,0 +1,63 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Integrity(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md":                        "# Library\n\nSee [personas](personas/README.md) and [the site](https://example.com).\n",
+		"personas/README.md":               "# Personas\n\nStart with [the reviewer](reviewer.md#usage) or [nobody](missing.md).\n",
+		"personas/reviewer.md":             "---\nname: reviewer\nroles: [code-reviewer]\ndescription: Reviews\n---\n\n# Reviewer\n",
+		"personas/team/reviewer2.md":       "---\nname: reviewer\nroles: [code-reviewer]\ndescription: Copy\n---\n\n# Copy\n",
+		"personas/notes.txt":               "scratch notes",
+		"prompts/README.md":                "# Prompts\n\n```\n[not a link](nowhere.md)\n```\n",
+		"prompts/review.md":                "# Review\n",
+		"prompts/Review.md":                "# Review again\n",
+		"prompts/review.md.orig":           "# Old review\n",
+		"drafts/idea.md":                   "# Idea\n",
+		"templates/app/README.md":          "[placeholder](link)\n",
+		"workflows/README.md":              "# Workflows\n",
+		"workflows/flow/commands/start.md": "# Start\n",
+		"workflows/flow/workflow.yml": "name: flow\nversion: 1.0.0\nphases:\n  - id: frame\n    order: 1\n" +
+			"commands:\n  - name: start\n  - name: review\npipelines:\n  default:\n    - command: start\n    - command: ship\n",
+	})
+
+	report, err := Inspect(root, DefaultOptions())
+	require.NoError(t, err)
+
+	if p := problemFor(report, CheckLink, "personas/README.md"); assert.NotNil(t, p) {
+		assert.Equal(t, "line 3 links to missing.md, which does not exist", p.Message)
+	}
+	assert.Nil(t, problemFor(report, CheckLink, "README.md"))
+	assert.Nil(t, problemFor(report, CheckLink, "prompts/README.md"), "links in code blocks are not checked")
+	assert.Nil(t, problemFor(report, CheckLink, "templates/app/README.md"), "template links are placeholders")
+
+	assert.NotNil(t, problemFor(report, CheckOrphan, "personas/notes.txt"))
+	assert.NotNil(t, problemFor(report, CheckOrphan, "prompts/review.md.orig"))
+	if p := problemFor(report, CheckOrphan, "drafts/idea.md"); assert.NotNil(t, p) {
+		assert.Contains(t, p.Message, "'drafts' is not a library asset type")
+	}
+	assert.Nil(t, problemFor(report, CheckOrphan, "README.md"))
+
+	if p := problemFor(report, CheckDuplicate, "prompts/Review.md"); assert.NotNil(t, p) {
+		assert.Equal(t, "Review.md, review.md name the same asset", p.Message)
+	}
+	if p := problemFor(report, CheckDuplicate, "personas/reviewer.md"); assert.NotNil(t, p) {
+		assert.Contains(t, p.Message, "persona name 'reviewer' is also declared by personas/team/reviewer2.md")
+	}
+
+	var commandProblems []string
+	for _, p := range report.ByCheck()[CheckWorkflowCommand] {
+		assert.Equal(t, "workflows/flow/workflow.yml", p.Path)
+		commandProblems = append(commandProblems, p.Message)
+	}
+	assert.Equal(t, []string{
+		"command 'review' has no prompt file commands/review.md",
+		"pipeline 'default' runs unknown command 'ship'",
+	}, commandProblems)
+}
@@ -0,0 +1,77 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// IssueCreator files an issue and returns its URL
+type IssueCreator func(title, body string) (string, error)
+
+// GitHubIssueCreator files issues with the GitHub CLI. An empty repo uses
+// the repository of the current directory.
+func GitHubIssueCreator(ctx context.Context, repo string) IssueCreator {
+	return func(title, body string) (string, error) {
+		if _, err := exec.LookPath("gh"); err != nil {
+			return "", fmt.Errorf("GitHub CLI (gh) is required to create issues: %w", err)
+		}
+
+		args := []string{"issue", "create", "--title", title, "--body", body}
+		if repo != "" {
+			args = append(args, "--repo", repo)
+		}
+		output, err := exec.CommandContext(ctx, "gh", args...).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("gh issue create failed: %s", strings.TrimSpace(string(output)))
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+}
+
+// CreateIssues files one issue per check that found problems, highest
+// priority checks first, and returns the created issue URLs
+func CreateIssues(report *Report, create IssueCreator) ([]string, error) {
+	groups := report.ByCheck()
+	checks := make([]string, 0, len(groups))
+	for check := range groups {
+		checks = append(checks, check)
+	}
+	// Problems are already sorted by priority, so a group's first entry
+	// carries its highest priority
+	sort.Slice(checks, func(i, j int) bool {
+		a, b := groups[checks[i]][0].Priority, groups[checks[j]][0].Priority
+		if a != b {
+			return a < b
+		}
+		return checks[i] < checks[j]
+	})
+
+	var urls []string
+	for _, check := range checks {
+		problems := groups[check]
+		title := fmt.Sprintf("Library doctor: %d %s problem(s)", len(problems), check)
+		url, err := create(title, IssueBody(check, problems))
+		if err != nil {
+			return urls, fmt.Errorf("creating issue for %s: %w", check, err)
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+// IssueBody renders problems from one check as a markdown task list
+func IssueBody(check string, problems []Problem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "`ddx library doctor` found %d %s problem(s).\n\n", len(problems), check)
+	for _, p := range problems {
+		fmt.Fprintf(&b, "- [ ] **%s** `%s`: %s", p.Priority, p.Path, p.Message)
+		if p.Fix != "" {
+			fmt.Fprintf(&b, " — %s", p.Fix)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package library
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/match"
+)
+
+// Selection chooses the parts of a library a project uses. Patterns use
+// the syntax of the CLI's filters (globs, "re:" regular expressions or
+// substrings) and are matched against a library path or any of its parent
+// directories, so "workflows/helix" selects the whole workflow and
+// "personas/security-*" matching personas. Files at the library root are
+// always selected. Paths outside the selection stay in the library
+// checkout, so it keeps matching upstream, and are hidden when assets are
+// read.
+type Selection struct {
+	set *match.Set
+}
+
+// NewSelection validates the patterns and returns a selection
+func NewSelection(include, exclude []string) (*Selection, error) {
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if pattern == "" || strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("invalid library pattern %q", pattern)
+		}
+	}
+	set, err := match.NewSet(trimSlashes(include), trimSlashes(exclude))
+	if err != nil {
+		return nil, fmt.Errorf("invalid library pattern: %w", err)
+	}
+	return &Selection{set: set}, nil
+}
+
+// IsEmpty reports whether the selection uses the whole library
+func (s *Selection) IsEmpty() bool {
+	return s == nil || s.set.Empty()
+}
+
+// Selected reports whether the slash-separated library path is used
+func (s *Selection) Selected(rel string) bool {
+	rel = strings.Trim(path.Clean(filepath.ToSlash(rel)), "/")
+	if s.IsEmpty() || !strings.Contains(rel, "/") {
+		return true
+	}
+	return s.set.MatchPath(rel)
+}
+
+// trimSlashes drops the trailing slash of patterns naming a directory
+func trimSlashes(patterns []string) []string {
+	trimmed := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		trimmed[i] = strings.TrimSuffix(pattern, "/")
+	}
+	return trimmed
+}
@@ -0,0 +1,43 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelection(t *testing.T) {
+	sel, err := NewSelection([]string{"workflows/helix", "personas/security-*"}, []string{"personas/security-legacy.md"})
+	require.NoError(t, err)
+
+	for path, want := range map[string]bool{
+		"README.md":                       true,
+		"workflows/helix/workflow.yml":    true,
+		"workflows/helix/phases/frame.md": true,
+		"workflows/kanban/workflow.yml":   false,
+		"personas/security-expert.md":     true,
+		"personas/security-legacy.md":     false,
+		"personas/architect.md":           false,
+		"prompts/common/review.md":        false,
+	} {
+		assert.Equal(t, want, sel.Selected(path), path)
+	}
+
+	assert.True(t, (&Selection{}).Selected("anything/at/all.md"))
+	_, err = NewSelection([]string{"personas/[a-"}, nil)
+	assert.ErrorContains(t, err, `invalid library pattern: invalid glob "personas/[a-"`)
+	_, err = NewSelection(nil, []string{"/personas"})
+	assert.EqualError(t, err, `invalid library pattern "/personas"`)
+
+	t.Run("filter syntax", func(t *testing.T) {
+		// The same patterns as 'ddx list' filters: ** globs, re: and case-insensitive
+		sel, err := NewSelection([]string{"prompts/**/review.md", "re:^workflows/(helix|kanban)$", "Personas/Architect.md"}, nil)
+		require.NoError(t, err)
+		assert.True(t, sel.Selected("prompts/common/review.md"))
+		assert.True(t, sel.Selected("workflows/kanban/workflow.yml"))
+		assert.True(t, sel.Selected("personas/architect.md"))
+		assert.False(t, sel.Selected("workflows/helix-lite/workflow.yml"))
+		assert.False(t, sel.Selected("prompts/common/plan.md"))
+	})
+}
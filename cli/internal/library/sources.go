@@ -0,0 +1,134 @@
+package library
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/config"
+)
+
+// Source is one of the libraries a project draws resources from: the main
+// library at library.path or one of library.sources
+type Source struct {
+	// Name is the namespace of the source, config.MainSource for the main
+	// library
+	Name string `json:"name"`
+	// Path is the directory as configured and Dir its absolute form
+	Path       string                   `json:"path"`
+	Dir        string                   `json:"-"`
+	Priority   int                      `json:"priority"`
+	Repository *config.RepositoryConfig `json:"repository,omitempty"`
+	// Selection hides the paths outside library.include/exclude; it is
+	// only set for the main library
+	Selection *Selection `json:"-"`
+}
+
+// Sources are a project's libraries, highest priority first
+type Sources []Source
+
+// ProjectSources returns the libraries of a configuration in priority
+// order. Among sources of equal priority the main library comes first and
+// the others keep their configured order.
+func ProjectSources(workingDir string, cfg *config.Config) Sources {
+	var sources Sources
+	if cfg == nil || cfg.Library == nil {
+		return sources
+	}
+	if cfg.Library.Path != "" {
+		selection, err := NewSelection(cfg.Library.Include, cfg.Library.Exclude)
+		if err != nil {
+			// 'ddx update' reports invalid patterns; until then nothing is hidden
+			selection = &Selection{}
+		}
+		sources = append(sources, Source{
+			Name:       config.MainSource,
+			Path:       cfg.Library.Path,
+			Dir:        absPath(workingDir, cfg.Library.Path),
+			Repository: cfg.Library.Repository,
+			Selection:  selection,
+		})
+	}
+	for _, s := range cfg.Library.Sources {
+		sources = append(sources, Source{
+			Name:       s.Name,
+			Path:       s.Path,
+			Dir:        absPath(workingDir, s.Path),
+			Priority:   s.Priority,
+			Repository: s.Repository,
+		})
+	}
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Priority > sources[j].Priority })
+	return sources
+}
+
+// absPath resolves a configured path against the working directory
+func absPath(workingDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workingDir, path)
+}
+
+// Get returns the source with the given name
+func (s Sources) Get(name string) (Source, bool) {
+	for _, source := range s {
+		if source.Name == name {
+			return source, true
+		}
+	}
+	return Source{}, false
+}
+
+// SplitRef splits a namespaced reference such as internal:personas/x into
+// the source name and the path. A reference without a namespace returns an
+// empty name.
+func SplitRef(ref string) (name, path string) {
+	if name, path, ok := strings.Cut(ref, ":"); ok && config.IsSourceName(name) {
+		return name, path
+	}
+	return "", ref
+}
+
+// Find resolves a slash-separated library path, optionally namespaced, to
+// a file or directory. A namespaced path is looked up in that source only;
+// otherwise the highest priority source that has it wins. A path outside
+// a source's selection is not found there. When no source has it, the path in the first source is returned with an error wrapping
+// fs.ErrNotExist.
+func (s Sources) Find(ref string) (Source, string, error) {
+	name, rel := SplitRef(ref)
+	candidates := s
+	if name != "" {
+		source, ok := s.Get(name)
+		if !ok {
+			return Source{}, "", fmt.Errorf("unknown library source %q", name)
+		}
+		candidates = Sources{source}
+	}
+	if len(candidates) == 0 {
+		return Source{}, "", fmt.Errorf("no library configured")
+	}
+	for _, source := range candidates {
+		if !source.Selection.Selected(rel) {
+			continue
+		}
+		path := filepath.Join(source.Dir, filepath.FromSlash(rel))
+		if _, err := os.Stat(path); err == nil {
+			return source, path, nil
+		}
+	}
+	first := candidates[0]
+	return first, filepath.Join(first.Dir, filepath.FromSlash(rel)), fmt.Errorf("%s: %w", ref, fs.ErrNotExist)
+}
+
+// Qualify returns the reference to a path in a source: the bare path for
+// the main library and <name>:<path> for the others
+func (s Source) Qualify(rel string) string {
+	if s.Name == config.MainSource {
+		return rel
+	}
+	return s.Name + ":" + rel
+}
@@ -0,0 +1,81 @@
+package library
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path string) {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte("# "+path+"\n"), 0644))
+	}
+	write(".ddx/library/personas/reviewer.md")
+	write(".ddx/library/personas/architect.md")
+	write("internal/personas/reviewer.md")
+	write("internal/personas/sec-reviewer.md")
+	write("legacy/personas/architect.md")
+
+	cfg := &config.Config{Library: &config.LibraryConfig{
+		Path: ".ddx/library",
+		Sources: []config.LibrarySource{
+			{Name: "legacy", Path: "legacy", Priority: -1},
+			{Name: "internal", Path: filepath.Join(dir, "internal"), Priority: 10},
+		},
+	}}
+	sources := ProjectSources(dir, cfg)
+	require.Len(t, sources, 3)
+	assert.Equal(t, []string{"internal", config.MainSource, "legacy"}, []string{sources[0].Name, sources[1].Name, sources[2].Name})
+	assert.Equal(t, filepath.Join(dir, "legacy"), sources[2].Dir)
+
+	source, path, err := sources.Find("personas/reviewer.md")
+	require.NoError(t, err)
+	assert.Equal(t, "internal", source.Name, "the higher priority source wins")
+	assert.Equal(t, filepath.Join(dir, "internal", "personas", "reviewer.md"), path)
+
+	source, _, err = sources.Find("personas/architect.md")
+	require.NoError(t, err)
+	assert.Equal(t, config.MainSource, source.Name)
+
+	source, path, err = sources.Find("legacy:personas/architect.md")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", source.Name)
+	assert.Equal(t, filepath.Join(dir, "legacy", "personas", "architect.md"), path)
+
+	_, path, err = sources.Find("personas/missing.md")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, filepath.Join(dir, "internal", "personas", "missing.md"), path)
+
+	_, _, err = sources.Find("vendor:personas/reviewer.md")
+	assert.EqualError(t, err, `unknown library source "vendor"`)
+
+	assert.Equal(t, "internal:personas/sec-reviewer.md", sources[0].Qualify("personas/sec-reviewer.md"))
+	assert.Equal(t, "personas/architect.md", sources[1].Qualify("personas/architect.md"))
+
+	// The main library's selection hides the paths outside it
+	cfg.Library.Exclude = []string{"personas/architect.md"}
+	source, path, err = ProjectSources(dir, cfg).Find("personas/architect.md")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", source.Name)
+	assert.Equal(t, filepath.Join(dir, "legacy", "personas", "architect.md"), path)
+	assert.FileExists(t, filepath.Join(dir, ".ddx", "library", "personas", "architect.md"))
+}
+
+func TestSplitRef(t *testing.T) {
+	for ref, want := range map[string][2]string{
+		"internal:personas/sec-reviewer": {"internal", "personas/sec-reviewer"},
+		"personas/reviewer":              {"", "personas/reviewer"},
+		`C:\library\personas`:            {"", `C:\library\personas`},
+	} {
+		name, path := SplitRef(ref)
+		assert.Equal(t, want, [2]string{name, path}, ref)
+	}
+}
@@ -0,0 +1,192 @@
+// Package lock pins the library a project uses. ddx.lock records the
+// upstream commit and a content hash for every library file, so other
+// machines and CI can verify they use exactly the same assets.
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the lockfile, kept at the project root
+const FileName = "ddx.lock"
+
+// Version is the lockfile format version
+const Version = 1
+
+// header is written above the lockfile content
+const header = "# Generated by 'ddx lock'. Do not edit by hand.\n"
+
+// Drift statuses reported by Verify
+const (
+	DriftModified = "modified"
+	DriftMissing  = "missing"
+	DriftUnlocked = "unlocked"
+)
+
+// Library identifies the library revision a lock was taken from
+type Library struct {
+	Repository string `yaml:"repository,omitempty" json:"repository,omitempty"`
+	Branch     string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	// Ref is the tag or commit the library is pinned to, if any
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	// Commit is the upstream commit of the last sync, when known
+	Commit string `yaml:"commit,omitempty" json:"commit,omitempty"`
+}
+
+// Lock is the content of ddx.lock
+type Lock struct {
+	Version int     `yaml:"version" json:"version"`
+	Library Library `yaml:"library" json:"library"`
+	// Assets maps slash-separated library paths to "sha256:<hex>"
+	Assets map[string]string `yaml:"assets" json:"assets"`
+}
+
+// Drift is a library file that no longer matches the lock
+type Drift struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// Path returns the lockfile path for a project
+func Path(workingDir string) string {
+	return filepath.Join(workingDir, FileName)
+}
+
+// Load reads the project's lockfile. A missing lockfile is reported with
+// an error matching fs.ErrNotExist.
+func Load(workingDir string) (*Lock, error) {
+	data, err := os.ReadFile(Path(workingDir))
+	if err != nil {
+		return nil, err
+	}
+	l := &Lock{}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if l.Version != Version {
+		return nil, fmt.Errorf("%s has unsupported version %d (expected %d)", FileName, l.Version, Version)
+	}
+	if l.Assets == nil {
+		l.Assets = map[string]string{}
+	}
+	return l, nil
+}
+
+// Save writes the lockfile to the project root
+func (l *Lock) Save(workingDir string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", FileName, err)
+	}
+	if err := atomicfile.WriteFile(Path(workingDir), append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// Generate hashes the library at libDir and returns a lock for it
+func Generate(libDir string, library Library) (*Lock, error) {
+	assets, err := HashLibrary(libDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Lock{Version: Version, Library: library, Assets: assets}, nil
+}
+
+// HashLibrary returns the content hash of every file under libDir, keyed
+// by slash-separated path
+func HashLibrary(libDir string) (map[string]string, error) {
+	assets := make(map[string]string)
+	err := filepath.WalkDir(libDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(libDir, path)
+		if err != nil {
+			return err
+		}
+		// The resource index is generated locally
+		if rel == catalog.FileName {
+			return nil
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		assets[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash library: %w", err)
+	}
+	return assets, nil
+}
+
+// Verify compares the library at libDir with the lock and returns the
+// files that drifted, sorted by path
+func (l *Lock) Verify(libDir string) ([]Drift, error) {
+	current, err := HashLibrary(libDir)
+	if err != nil {
+		return nil, err
+	}
+	var drift []Drift
+	for path, hash := range l.Assets {
+		switch got, ok := current[path]; {
+		case !ok:
+			drift = append(drift, Drift{Path: path, Status: DriftMissing})
+		case got != hash:
+			drift = append(drift, Drift{Path: path, Status: DriftModified})
+		}
+	}
+	for path := range current {
+		if _, ok := l.Assets[path]; !ok {
+			drift = append(drift, Drift{Path: path, Status: DriftUnlocked})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift, nil
+}
+
+// Summary describes drift in one line, naming the first few files
+func Summary(drift []Drift) string {
+	const shown = 3
+	parts := make([]string, 0, shown)
+	for i, d := range drift {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("and %d more", len(drift)-shown))
+			break
+		}
+		parts = append(parts, d.Path+" ("+d.Status+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hashFile returns "sha256:<hex>" for a file's content
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
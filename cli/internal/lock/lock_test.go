@@ -0,0 +1,58 @@
+package lock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	project := t.TempDir()
+	libDir := filepath.Join(project, ".ddx", "library")
+	write := func(rel, content string) {
+		path := filepath.Join(libDir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("personas/reviewer.md", "# Reviewer\n")
+	write("prompts/common/review.md", "Review.\n")
+
+	_, err := Load(project)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	l, err := Generate(libDir, Library{Repository: "https://example.com/library", Branch: "main", Commit: "abc123"})
+	require.NoError(t, err)
+	assert.Len(t, l.Assets, 2)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, l.Assets["personas/reviewer.md"])
+	require.NoError(t, l.Save(project))
+
+	data, err := os.ReadFile(Path(project))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Generated by 'ddx lock'")
+
+	loaded, err := Load(project)
+	require.NoError(t, err)
+	assert.Equal(t, l, loaded)
+
+	drift, err := loaded.Verify(libDir)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	write("personas/reviewer.md", "# Lenient Reviewer\n")
+	write("personas/new.md", "# New\n")
+	require.NoError(t, os.Remove(filepath.Join(libDir, "prompts", "common", "review.md")))
+	drift, err = loaded.Verify(libDir)
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{
+		{Path: "personas/new.md", Status: DriftUnlocked},
+		{Path: "personas/reviewer.md", Status: DriftModified},
+		{Path: "prompts/common/review.md", Status: DriftMissing},
+	}, drift)
+	assert.Equal(t, "personas/new.md (unlocked), personas/reviewer.md (modified), prompts/common/review.md (missing)", Summary(drift))
+	assert.Equal(t, "personas/new.md (unlocked), personas/reviewer.md (modified), prompts/common/review.md (missing), and 1 more",
+		Summary(append(drift, Drift{Path: "x", Status: DriftMissing})))
+}
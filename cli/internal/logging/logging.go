@@ -0,0 +1,147 @@
+// Package logging sets up the diagnostic output of ddx: warnings, detail
+// shown with --verbose and tracing shown with --debug. Diagnostics go to
+// stderr through log/slog so that stdout carries only a command's human
+// output or data. DDX_LOG_FORMAT=json writes them as JSON lines for
+// machines to consume.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FormatEnvVar selects the log format: text (default) or json
+const FormatEnvVar = "DDX_LOG_FORMAT"
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+func init() {
+	Setup(os.Stderr, slog.LevelWarn, os.Getenv(FormatEnvVar))
+}
+
+// Level returns the level selected by the root command's flags: warnings
+// by default, information with --verbose and everything with --debug
+func Level(verbose, debug bool) slog.Level {
+	switch {
+	case debug:
+		return slog.LevelDebug
+	case verbose:
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// New returns a logger writing records at or above level to w in the
+// given format. An empty or unknown format is text.
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+	if strings.EqualFold(format, FormatJSON) {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(&textHandler{w: w, level: level, mu: &sync.Mutex{}})
+}
+
+// Setup makes a new logger the default used by slog's package functions
+func Setup(w io.Writer, level slog.Level, format string) {
+	slog.SetDefault(New(w, level, format))
+}
+
+// textHandler writes records for people: the message with a level prefix
+// for anything but information, followed by its attributes
+type textHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+	group string
+	mu    *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("debug: ")
+	}
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), qualify(h.group, attrs)...)
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.group = qualifyKey(h.group, name)
+	return &clone
+}
+
+// writeAttr appends an attribute as key=value, quoting values with spaces
+func writeAttr(b *strings.Builder, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := qualifyKey(group, a.Key)
+	if a.Value.Kind() == slog.KindGroup {
+		for _, member := range a.Value.Group() {
+			writeAttr(b, key, member)
+		}
+		return
+	}
+	value := a.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(b, " %s=%s", key, value)
+}
+
+// qualify prefixes attribute keys with a group name
+func qualify(group string, attrs []slog.Attr) []slog.Attr {
+	if group == "" {
+		return attrs
+	}
+	qualified := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		qualified[i] = slog.Attr{Key: qualifyKey(group, a.Key), Value: a.Value}
+	}
+	return qualified
+}
+
+func qualifyKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
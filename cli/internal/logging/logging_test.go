@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, Level(false, false))
+	assert.Equal(t, slog.LevelInfo, Level(true, false))
+	assert.Equal(t, slog.LevelDebug, Level(false, true))
+	assert.Equal(t, slog.LevelDebug, Level(true, true))
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo, "")
+	logger.Debug("hidden")
+	logger.Info("loaded", "path", ".ddx/config.yaml")
+	logger.Warn("could not check for updates", "error", "connection refused")
+	logger.With("workflow", "helix").WithGroup("step").Error("failed", "command", "build")
+
+	assert.Equal(t, `loaded path=.ddx/config.yaml
+Warning: could not check for updates error="connection refused"
+Error: failed workflow=helix step.command=build
+`, buf.String())
+
+	buf.Reset()
+	New(&buf, slog.LevelDebug, FormatText).Debug("running agent")
+	assert.Equal(t, "debug: running agent\n", buf.String())
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, slog.LevelWarn, "JSON").Warn("skipping invalid persona", "file", "broken.md")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "skipping invalid persona", record["msg"])
+	assert.Equal(t, "broken.md", record["file"])
+}
@@ -0,0 +1,273 @@
+// Package match provides the pattern matching used by filters across the CLI.
+//
+// A pattern is interpreted in one of three modes:
+//   - Regex: patterns prefixed with "re:" are Go regular expressions
+//   - Glob: patterns containing *, ? or [ are shell-style globs; a single
+//     star or question mark stops at path separators while ** crosses them
+//   - Substring: all other patterns match anywhere in the value
+//
+// Matching is case-insensitive in every mode. Glob patterns without a
+// path separator also match against the last path component, so "*.md"
+// matches "claude/prompt.md".
+package match
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RegexPrefix marks a pattern as a regular expression
+const RegexPrefix = "re:"
+
+// Mode identifies how a pattern is interpreted
+type Mode int
+
+const (
+	// ModeSubstring matches when the pattern appears anywhere in the value
+	ModeSubstring Mode = iota
+	// ModeGlob matches shell-style wildcards against the whole value
+	ModeGlob
+	// ModeRegex matches a regular expression anywhere in the value
+	ModeRegex
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeGlob:
+		return "glob"
+	case ModeRegex:
+		return "regex"
+	default:
+		return "substring"
+	}
+}
+
+// Matcher matches strings against a single compiled pattern
+type Matcher struct {
+	pattern  string
+	mode     Mode
+	lower    string
+	re       *regexp.Regexp
+	baseName bool
+}
+
+// Compile parses a pattern into a Matcher
+func Compile(pattern string) (*Matcher, error) {
+	m := &Matcher{pattern: pattern}
+
+	switch {
+	case strings.HasPrefix(pattern, RegexPrefix):
+		re, err := regexp.Compile("(?i)" + strings.TrimPrefix(pattern, RegexPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		m.mode = ModeRegex
+		m.re = re
+	case strings.ContainsAny(pattern, "*?["):
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.mode = ModeGlob
+		m.re = re
+		m.baseName = !strings.Contains(pattern, "/")
+	default:
+		m.mode = ModeSubstring
+		m.lower = strings.ToLower(pattern)
+	}
+
+	return m, nil
+}
+
+// MustCompile is like Compile but panics if the pattern is invalid
+func MustCompile(pattern string) *Matcher {
+	m, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Match reports whether the value matches the pattern
+func (m *Matcher) Match(value string) bool {
+	switch m.mode {
+	case ModeRegex:
+		return m.re.MatchString(value)
+	case ModeGlob:
+		value = strings.ReplaceAll(value, "\\", "/")
+		if m.re.MatchString(value) {
+			return true
+		}
+		return m.baseName && m.re.MatchString(path.Base(value))
+	default:
+		return strings.Contains(strings.ToLower(value), m.lower)
+	}
+}
+
+// Mode returns how the pattern is interpreted
+func (m *Matcher) Mode() Mode {
+	return m.mode
+}
+
+// String returns the original pattern
+func (m *Matcher) String() string {
+	return m.pattern
+}
+
+// globToRegexp translates a glob pattern into an anchored regular expression
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// "**/" also matches zero directories
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Set combines include and exclude patterns.
+// A value matches when it matches any include pattern (or there are none)
+// and matches no exclude pattern.
+type Set struct {
+	include []*Matcher
+	exclude []*Matcher
+}
+
+// NewSet compiles include and exclude patterns into a Set
+func NewSet(include, exclude []string) (*Set, error) {
+	s := &Set{}
+	for _, p := range include {
+		m, err := Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		s.include = append(s.include, m)
+	}
+	for _, p := range exclude {
+		m, err := Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		s.exclude = append(s.exclude, m)
+	}
+	return s, nil
+}
+
+// Match reports whether the value is included by the set
+func (s *Set) Match(value string) bool {
+	if s == nil {
+		return true
+	}
+	for _, m := range s.exclude {
+		if m.Match(value) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, m := range s.include {
+		if m.Match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath is like Match for a slash-separated path, which also matches
+// a pattern when one of its parent directories does, so a pattern naming a
+// directory covers everything in it
+func (s *Set) MatchPath(value string) bool {
+	if s == nil {
+		return true
+	}
+	matchesAny := func(matchers []*Matcher) bool {
+		for candidate := value; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+			for _, m := range matchers {
+				if m.Match(candidate) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if matchesAny(s.exclude) {
+		return false
+	}
+	return len(s.include) == 0 || matchesAny(s.include)
+}
+
+// Empty reports whether the set has no patterns
+func (s *Set) Empty() bool {
+	return s == nil || (len(s.include) == 0 && len(s.exclude) == 0)
+}
+
+// ReadPatternFile reads patterns from an ignore-style file such as .ddxignore.
+// Blank lines and lines starting with # are skipped. A missing file yields no
+// patterns and no error.
+func ReadPatternFile(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open pattern file %s: %w", filePath, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pattern file %s: %w", filePath, err)
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,153 @@
+package match
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatcher covers substring, glob and regex semantics
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		mode    Mode
+		matches []string
+		misses  []string
+	}{
+		{
+			name:    "substring is case-insensitive",
+			pattern: "React",
+			mode:    ModeSubstring,
+			matches: []string{"react-app", "templates/nextjs-REACT"},
+			misses:  []string{"vue-app"},
+		},
+		{
+			name:    "star stays within a segment",
+			pattern: "claude/*.md",
+			mode:    ModeGlob,
+			matches: []string{"claude/focused.md", "CLAUDE/Focused.MD"},
+			misses:  []string{"claude/system/focused.md", "common/focused.md"},
+		},
+		{
+			name:    "pattern without slash matches base name",
+			pattern: "*.md",
+			mode:    ModeGlob,
+			matches: []string{"README.md", "prompts/claude/focused.md"},
+			misses:  []string{"config.yml"},
+		},
+		{
+			name:    "double star crosses segments",
+			pattern: "prompts/**/*.md",
+			mode:    ModeGlob,
+			matches: []string{"prompts/a.md", "prompts/claude/system/a.md"},
+			misses:  []string{"templates/a.md"},
+		},
+		{
+			name:    "question mark and classes",
+			pattern: "v[0-9]?",
+			mode:    ModeGlob,
+			matches: []string{"v10", "v2a"},
+			misses:  []string{"v1", "vx1"},
+		},
+		{
+			name:    "negated class",
+			pattern: "[!a]*",
+			mode:    ModeGlob,
+			matches: []string{"helix"},
+			misses:  []string{"agile"},
+		},
+		{
+			name:    "regex searches anywhere",
+			pattern: "re:^(helix|kanban)$",
+			mode:    ModeRegex,
+			matches: []string{"helix", "KANBAN"},
+			misses:  []string{"helix2", "agile"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.mode, m.Mode())
+			for _, v := range tt.matches {
+				assert.True(t, m.Match(v), "%q should match %q", tt.pattern, v)
+			}
+			for _, v := range tt.misses {
+				assert.False(t, m.Match(v), "%q should not match %q", tt.pattern, v)
+			}
+		})
+	}
+}
+
+// TestCompile_Invalid tests that malformed patterns are rejected
+func TestCompile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Compile("re:(unclosed")
+	assert.Error(t, err)
+
+	_, err = Compile("[abc")
+	assert.Error(t, err)
+}
+
+// TestSet tests include/exclude precedence
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewSet([]string{"prompts/**", "personas/*"}, []string{"*.bak", "re:draft"})
+	require.NoError(t, err)
+
+	assert.True(t, s.Match("prompts/claude/focused.md"))
+	assert.True(t, s.Match("personas/strict-code-reviewer.md"))
+	assert.False(t, s.Match("templates/nextjs"))
+	assert.False(t, s.Match("prompts/old.bak"))
+	assert.False(t, s.Match("personas/draft-reviewer.md"))
+
+	empty, err := NewSet(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+	assert.True(t, empty.Match("anything"))
+
+	var nilSet *Set
+	assert.True(t, nilSet.Match("anything"))
+}
+
+// TestSet_MatchPath tests that patterns also match a path's parent directories
+func TestSet_MatchPath(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewSet([]string{"workflows/helix", "personas/*"}, []string{"re:^personas/legacy"})
+	require.NoError(t, err)
+
+	assert.True(t, s.MatchPath("workflows/helix/phases/frame.md"))
+	assert.True(t, s.MatchPath("personas/architect.md"))
+	assert.False(t, s.MatchPath("workflows/kanban/workflow.yml"))
+	assert.False(t, s.MatchPath("personas/legacy/reviewer.md"))
+
+	var nilSet *Set
+	assert.True(t, nilSet.MatchPath("anything/at/all.md"))
+}
+
+// TestReadPatternFile tests parsing ignore-style files
+func TestReadPatternFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".ddxignore")
+	require.NoError(t, os.WriteFile(file, []byte("# comment\n\n*.bak\n  drafts/**  \n"), 0644))
+
+	patterns, err := ReadPatternFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*.bak", "drafts/**"}, patterns)
+
+	patterns, err = ReadPatternFile(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Nil(t, patterns)
+}
@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// ProjectConfigFile is the project-scoped MCP configuration read by Claude Code
+const ProjectConfigFile = ".mcp.json"
+
+// desktopConfigFile is the Claude Desktop configuration file name
+const desktopConfigFile = "claude_desktop_config.json"
+
+// ServerEntry is a server's entry in a client's mcpServers configuration
+type ServerEntry struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// ParseClients parses a --client value into the clients it names
+func ParseClients(value string) ([]ClaudeType, error) {
+	switch strings.ToLower(value) {
+	case "", "claude-code", "code":
+		return []ClaudeType{ClaudeCode}, nil
+	case "claude-desktop", "desktop":
+		return []ClaudeType{ClaudeDesktop}, nil
+	case "both":
+		return []ClaudeType{ClaudeDesktop, ClaudeCode}, nil
+	default:
+		return nil, fmt.Errorf("unknown client %q (expected claude-desktop, claude-code or both)", value)
+	}
+}
+
+// ClientName returns the display name of a client
+func ClientName(client ClaudeType) string {
+	if client == ClaudeDesktop {
+		return "Claude Desktop"
+	}
+	return "Claude Code"
+}
+
+// ClientConfigPath returns the MCP configuration file a client reads.
+// Claude Code uses the project's .mcp.json; Claude Desktop uses its
+// per-user claude_desktop_config.json.
+func ClientConfigPath(client ClaudeType, workingDir string) (string, error) {
+	if client == ClaudeCode {
+		return filepath.Join(workingDir, ProjectConfigFile), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return desktopConfigPath(runtime.GOOS, home, os.Getenv("APPDATA")), nil
+}
+
+// desktopConfigPath returns the Claude Desktop config location for a platform
+func desktopConfigPath(goos, home, appData string) string {
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Claude", desktopConfigFile)
+	case "windows":
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Claude", desktopConfigFile)
+	default:
+		return filepath.Join(home, ".config", "Claude", desktopConfigFile)
+	}
+}
+
+// BuildServerEntry creates the client entry for a server. Declared
+// variables without a provided value get their default, or a ${NAME}
+// placeholder when there is none. With placeholderSecrets set, sensitive
+// values other than secret references are written as placeholders so they
+// stay out of files that may be committed. The names of placeholder
+// variables are returned.
+func BuildServerEntry(server *Server, env map[string]string, placeholderSecrets bool) (ServerEntry, []string) {
+	entry := ServerEntry{
+		Command: server.Command.Executable,
+		Args:    server.Command.Args,
+	}
+
+	values := make(map[string]string)
+	var placeholders []string
+	for _, envVar := range server.Environment {
+		value, provided := env[envVar.Name]
+		switch {
+		case provided && (IsSecretRef(value) || !(placeholderSecrets && envVar.Sensitive)):
+			values[envVar.Name] = value
+		case !provided && envVar.Default != "" && !envVar.Sensitive:
+			values[envVar.Name] = envVar.Default
+		case provided || envVar.Required || envVar.Sensitive:
+			values[envVar.Name] = "${" + envVar.Name + "}"
+			placeholders = append(placeholders, envVar.Name)
+		}
+		// Optional settings without a value are left to the server
+	}
+
+	// Keep extra variables the user supplied explicitly
+	for name, value := range env {
+		if _, ok := values[name]; !ok {
+			values[name] = value
+		}
+	}
+
+	if len(values) > 0 {
+		entry.Env = values
+	}
+	sort.Strings(placeholders)
+	return entry, placeholders
+}
+
+// readClientConfig reads a client configuration file, returning an empty
+// configuration when the file does not exist
+func readClientConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	config := map[string]interface{}{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrConfigCorrupted, path, err)
+	}
+	return config, nil
+}
+
+// HasServerEntry reports whether a client configuration file already
+// defines the named server
+func HasServerEntry(path, name string) (bool, error) {
+	config, err := readClientConfig(path)
+	if err != nil {
+		return false, err
+	}
+	servers, _ := config["mcpServers"].(map[string]interface{})
+	_, exists := servers[name]
+	return exists, nil
+}
+
+// WriteServerEntry adds or replaces a server in a client configuration
+// file, preserving the other servers and settings it contains
+func WriteServerEntry(path, name string, entry ServerEntry) error {
+	config, err := readClientConfig(path)
+	if err != nil {
+		return err
+	}
+
+	servers, ok := config["mcpServers"].(map[string]interface{})
+	if !ok {
+		servers = map[string]interface{}{}
+	}
+	servers[name] = entry
+	config["mcpServers"] = servers
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClients(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []ClaudeType
+		wantErr bool
+	}{
+		{"", []ClaudeType{ClaudeCode}, false},
+		{"claude-code", []ClaudeType{ClaudeCode}, false},
+		{"claude-desktop", []ClaudeType{ClaudeDesktop}, false},
+		{"both", []ClaudeType{ClaudeDesktop, ClaudeCode}, false},
+		{"vscode", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseClients(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDesktopConfigPath(t *testing.T) {
+	home := filepath.Join("home", "user")
+
+	assert.Equal(t,
+		filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json"),
+		desktopConfigPath("darwin", home, ""))
+	assert.Equal(t,
+		filepath.Join("appdata", "Claude", "claude_desktop_config.json"),
+		desktopConfigPath("windows", home, "appdata"))
+	assert.Equal(t,
+		filepath.Join(home, ".config", "Claude", "claude_desktop_config.json"),
+		desktopConfigPath("linux", home, ""))
+}
+
+func TestBuildServerEntry(t *testing.T) {
+	server := &Server{
+		Name:    "github",
+		Command: CommandSpec{Executable: "npx", Args: []string{"-y", "server-github"}},
+		Environment: []EnvironmentVar{
+			{Name: "TOKEN", Required: true, Sensitive: true},
+			{Name: "API_URL", Default: "https://api.github.com"},
+			{Name: "OPTIONAL"},
+		},
+	}
+
+	t.Run("placeholders for secrets", func(t *testing.T) {
+		entry, placeholders := BuildServerEntry(server, map[string]string{"TOKEN": "secret"}, true)
+
+		assert.Equal(t, "npx", entry.Command)
+		assert.Equal(t, []string{"-y", "server-github"}, entry.Args)
+		assert.Equal(t, map[string]string{
+			"TOKEN":   "${TOKEN}",
+			"API_URL": "https://api.github.com",
+		}, entry.Env)
+		assert.Equal(t, []string{"TOKEN"}, placeholders)
+	})
+
+	t.Run("provided values", func(t *testing.T) {
+		entry, placeholders := BuildServerEntry(server, map[string]string{"TOKEN": "secret", "EXTRA": "1"}, false)
+
+		assert.Equal(t, "secret", entry.Env["TOKEN"])
+		assert.Equal(t, "1", entry.Env["EXTRA"])
+		assert.Empty(t, placeholders)
+	})
+}
+
+func TestWriteServerEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "claude_desktop_config.json")
+	existing := `{"theme": "dark", "mcpServers": {"other": {"command": "other-server"}}}`
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(existing), 0644))
+
+	exists, err := HasServerEntry(path, "github")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, WriteServerEntry(path, "github", ServerEntry{Command: "npx"}))
+
+	exists, err = HasServerEntry(path, "github")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	config, err := readClientConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", config["theme"])
+	servers := config["mcpServers"].(map[string]interface{})
+	assert.Contains(t, servers, "other")
+	assert.Contains(t, servers, "github")
+}
+
+func TestHasServerEntry_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".mcp.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	_, err := HasServerEntry(path, "github")
+	assert.ErrorIs(t, err, ErrConfigCorrupted)
+}
@@ -0,0 +1,442 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HealthState summarizes whether a configured server can start
+type HealthState string
+
+const (
+	// HealthHealthy servers passed every check
+	HealthHealthy HealthState = "healthy"
+	// HealthBroken servers will fail to start in the client
+	HealthBroken HealthState = "broken"
+)
+
+// HandshakeProtocolVersion is the MCP protocol version sent when
+// initializing a server
+const HandshakeProtocolVersion = "2024-11-05"
+
+// DefaultHandshakeTimeout bounds how long a server has to answer initialize
+const DefaultHandshakeTimeout = 10 * time.Second
+
+// ConfiguredServer is a server entry found in a client configuration file
+type ConfiguredServer struct {
+	Name       string
+	ConfigPath string
+	Entry      ServerEntry
+}
+
+// ConfiguredServers reads the servers defined in the given client
+// configuration files. Missing files are skipped; servers are returned
+// sorted by name and then config path.
+func ConfiguredServers(configPaths ...string) ([]ConfiguredServer, error) {
+	var servers []ConfiguredServer
+	for _, path := range configPaths {
+		config, err := readClientConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		entries, _ := config["mcpServers"].(map[string]interface{})
+		for name, raw := range entries {
+			data, err := json.Marshal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: server %s", ErrConfigCorrupted, path, name)
+			}
+			var entry ServerEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return nil, fmt.Errorf("%w: %s: server %s: %v", ErrConfigCorrupted, path, name, err)
+			}
+			servers = append(servers, ConfiguredServer{Name: name, ConfigPath: path, Entry: entry})
+		}
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].Name != servers[j].Name {
+			return servers[i].Name < servers[j].Name
+		}
+		return servers[i].ConfigPath < servers[j].ConfigPath
+	})
+	return servers, nil
+}
+
+// HandshakeResult describes the server that answered initialize
+type HandshakeResult struct {
+	ServerName      string `json:"server_name,omitempty"`
+	ServerVersion   string `json:"server_version,omitempty"`
+	ProtocolVersion string `json:"protocol_version,omitempty"`
+	DurationMs      int64  `json:"duration_ms"`
+}
+
+// ServerHealth is the result of checking one configured server
+type ServerHealth struct {
+	Name    string      `json:"name"`
+	Config  string      `json:"config"`
+	Command string      `json:"command"`
+	Status  HealthState `json:"status"`
+	// Problems prevent the server from starting
+	Problems []string `json:"problems,omitempty"`
+	// Warnings do not prevent startup but are worth fixing
+	Warnings    []string         `json:"warnings,omitempty"`
+	Remediation []string         `json:"remediation,omitempty"`
+	Handshake   *HandshakeResult `json:"handshake,omitempty"`
+}
+
+// Healthy reports whether the server passed every check
+func (h *ServerHealth) Healthy() bool {
+	return h.Status == HealthHealthy
+}
+
+func (h *ServerHealth) problem(message, remediation string) {
+	h.Problems = append(h.Problems, message)
+	h.remedy(remediation)
+}
+
+func (h *ServerHealth) warning(message, remediation string) {
+	h.Warnings = append(h.Warnings, message)
+	h.remedy(remediation)
+}
+
+func (h *ServerHealth) remedy(remediation string) {
+	if remediation != "" {
+		h.Remediation = appendUnique(h.Remediation, remediation)
+	}
+}
+
+// PackageProbe reports whether a package launched through a package runner
+// such as npx is installed locally
+type PackageProbe func(ctx context.Context, runner, pkg string) (installed bool, err error)
+
+// HealthOptions configure server health checks
+type HealthOptions struct {
+	// Handshake starts each server and sends an MCP initialize request
+	Handshake bool
+	// Timeout bounds each handshake; zero uses DefaultHandshakeTimeout
+	Timeout time.Duration
+	// Secrets resolves secret:// references; nil reports them unchecked
+	Secrets SecretStore
+	// LookPath finds executables; nil uses exec.LookPath
+	LookPath func(file string) (string, error)
+	// Packages checks runner packages; nil uses the global npm install
+	Packages PackageProbe
+	// Getenv reads the environment client configs expand ${NAME} from;
+	// nil uses os.Getenv
+	Getenv func(key string) string
+}
+
+// HealthChecker checks configured servers
+type HealthChecker struct {
+	opts HealthOptions
+}
+
+// NewHealthChecker creates a health checker
+func NewHealthChecker(opts HealthOptions) *HealthChecker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultHandshakeTimeout
+	}
+	if opts.LookPath == nil {
+		opts.LookPath = exec.LookPath
+	}
+	if opts.Packages == nil {
+		opts.Packages = npmGlobalPackage
+	}
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	return &HealthChecker{opts: opts}
+}
+
+// runnerInstallHints tells users how to get common server launchers
+var runnerInstallHints = map[string]string{
+	"npx":    "Install Node.js (https://nodejs.org), which provides npx",
+	"node":   "Install Node.js (https://nodejs.org)",
+	"uvx":    "Install uv (https://docs.astral.sh/uv/), which provides uvx",
+	"python": "Install Python 3",
+	"docker": "Install Docker and make sure the daemon is running",
+}
+
+// Check verifies that a configured server can be started
+func (c *HealthChecker) Check(ctx context.Context, server ConfiguredServer) ServerHealth {
+	health := ServerHealth{
+		Name:    server.Name,
+		Config:  server.ConfigPath,
+		Command: strings.TrimSpace(strings.Join(append([]string{server.Entry.Command}, server.Entry.Args...), " ")),
+	}
+
+	command, args := server.Entry.Command, server.Entry.Args
+	if command == "" {
+		health.problem("no command configured", fmt.Sprintf("Reinstall with 'ddx mcp install %s'", server.Name))
+	} else {
+		// Servers launched through 'ddx mcp exec' need both executables
+		if inner, innerArgs, ok := unwrapSecretResolver(args); ok {
+			c.checkExecutable(&health, command)
+			command, args = inner, innerArgs
+		}
+		c.checkExecutable(&health, command)
+		c.checkPackage(ctx, &health, command, args)
+	}
+
+	env := c.checkEnvironment(ctx, &health, server)
+
+	if c.opts.Handshake && len(health.Problems) == 0 {
+		result, err := c.handshake(ctx, command, args, env)
+		if err != nil {
+			health.problem(fmt.Sprintf("handshake failed: %v", err),
+				fmt.Sprintf("Run '%s' manually to see why the server does not start", strings.Join(append([]string{command}, args...), " ")))
+		} else {
+			health.Handshake = result
+		}
+	}
+
+	health.Status = HealthHealthy
+	if len(health.Problems) > 0 {
+		health.Status = HealthBroken
+	}
+	return health
+}
+
+// CheckAll checks each server in order
+func (c *HealthChecker) CheckAll(ctx context.Context, servers []ConfiguredServer) []ServerHealth {
+	results := make([]ServerHealth, 0, len(servers))
+	for _, server := range servers {
+		results = append(results, c.Check(ctx, server))
+	}
+	return results
+}
+
+// unwrapSecretResolver returns the server command wrapped by 'ddx mcp exec'
+func unwrapSecretResolver(args []string) (string, []string, bool) {
+	if len(args) < 4 || args[0] != "mcp" || args[1] != "exec" || args[2] != "--" {
+		return "", nil, false
+	}
+	return args[3], args[4:], true
+}
+
+// checkExecutable reports commands that are not on PATH
+func (c *HealthChecker) checkExecutable(health *ServerHealth, command string) {
+	if _, err := c.opts.LookPath(command); err == nil {
+		return
+	}
+	hint, ok := runnerInstallHints[filepath.Base(command)]
+	if !ok {
+		hint = fmt.Sprintf("Install %s or fix the command in %s", command, health.Config)
+	}
+	health.problem(fmt.Sprintf("command %q not found on PATH", command), hint)
+}
+
+// checkPackage reports npx packages that will be downloaded at startup
+func (c *HealthChecker) checkPackage(ctx context.Context, health *ServerHealth, command string, args []string) {
+	if filepath.Base(command) != "npx" {
+		return
+	}
+	pkg := runnerPackage(args)
+	if pkg == "" {
+		health.problem("npx is configured without a package", fmt.Sprintf("Reinstall with 'ddx mcp install %s'", health.Name))
+		return
+	}
+	installed, err := c.opts.Packages(ctx, "npx", pkg)
+	if err != nil || installed {
+		return
+	}
+	health.warning(fmt.Sprintf("package %s is not installed; npx downloads it when the server starts", pkg),
+		fmt.Sprintf("Run 'npm install -g %s' for faster, offline starts", pkg))
+}
+
+// runnerPackage returns the first non-flag argument given to a package runner
+func runnerPackage(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// npmGlobalPackage reports whether pkg is installed in the global npm prefix
+func npmGlobalPackage(ctx context.Context, runner, pkg string) (bool, error) {
+	output, err := exec.CommandContext(ctx, "npm", "root", "-g").Output()
+	if err != nil {
+		return false, err
+	}
+	name := pkg
+	// Strip a version suffix such as @1.2.3 without touching the scope
+	if at := strings.LastIndex(name, "@"); at > 0 {
+		name = name[:at]
+	}
+	_, err = os.Stat(filepath.Join(strings.TrimSpace(string(output)), filepath.FromSlash(name)))
+	return err == nil, nil
+}
+
+// checkEnvironment verifies placeholders and secret references and returns
+// the resolved environment for the server
+func (c *HealthChecker) checkEnvironment(ctx context.Context, health *ServerHealth, server ConfiguredServer) []string {
+	names := make([]string, 0, len(server.Entry.Env))
+	for name := range server.Entry.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reinstall := fmt.Sprintf("Run 'ddx mcp install %s' to set it", server.Name)
+	env := os.Environ()
+	for _, name := range names {
+		value := server.Entry.Env[name]
+		switch {
+		case IsSecretRef(value):
+			if c.opts.Secrets == nil {
+				health.warning(fmt.Sprintf("%s is a stored secret that was not checked", name), "")
+				continue
+			}
+			resolved, err := ResolveSecretEnv(ctx, c.opts.Secrets, []string{name + "=" + value})
+			if err != nil {
+				health.problem(fmt.Sprintf("%s: stored secret %s not found", name, value), reinstall)
+				continue
+			}
+			env = append(env, resolved...)
+		case strings.Contains(value, "${"):
+			expanded := os.Expand(value, c.opts.Getenv)
+			var unset []string
+			os.Expand(value, func(key string) string {
+				if c.opts.Getenv(key) == "" {
+					unset = append(unset, key)
+				}
+				return ""
+			})
+			if len(unset) > 0 {
+				health.problem(fmt.Sprintf("%s uses %s, which is not set", name, strings.Join(unset, ", ")),
+					fmt.Sprintf("Export %s or run 'ddx mcp install %s' to store it", strings.Join(unset, ", "), server.Name))
+				continue
+			}
+			env = append(env, name+"="+expanded)
+		default:
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// handshake starts the server and waits for its initialize response
+func (c *HealthChecker) handshake(ctx context.Context, command string, args, env []string) (*HandshakeResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = env
+	// Children that keep stdio open must not hold up the check
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params": map[string]interface{}{
+			"protocolVersion": HandshakeProtocolVersion,
+			"capabilities":    map[string]interface{}{},
+			"clientInfo":      map[string]string{"name": "ddx", "version": "health-check"},
+		},
+	}
+	data, _ := json.Marshal(request)
+	// A server that exits early fails the write; its output explains why
+	_, _ = stdin.Write(append(data, '\n'))
+
+	done := make(chan error, 1)
+	var result *HandshakeResult
+	go func() {
+		var err error
+		result, err = readInitializeResponse(stdout)
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no initialize response within %s", c.opts.Timeout)
+	}
+
+	_ = stdin.Close()
+	_ = cmd.Process.Kill()
+	// Wait before reading stderr so all of it has been copied
+	_ = cmd.Wait()
+	if err != nil {
+		return nil, withStderr(err, stderr.String())
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// readInitializeResponse reads JSON-RPC messages until the reply to the
+// initialize request arrives
+func readInitializeResponse(stdout io.Reader) (*HandshakeResult, error) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Result *struct {
+				ProtocolVersion string `json:"protocolVersion"`
+				ServerInfo      struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+				} `json:"serverInfo"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		// Servers may log non-JSON lines to stdout; skip them
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || string(msg.ID) != "1" {
+			continue
+		}
+		if msg.Error != nil {
+			return nil, fmt.Errorf("server rejected initialize: %s", msg.Error.Message)
+		}
+		if msg.Result == nil {
+			return nil, errors.New("initialize response has no result")
+		}
+		return &HandshakeResult{
+			ServerName:      msg.Result.ServerInfo.Name,
+			ServerVersion:   msg.Result.ServerInfo.Version,
+			ProtocolVersion: msg.Result.ProtocolVersion,
+		}, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("server exited before answering initialize")
+}
+
+// withStderr appends the last line the server wrote to stderr
+func withStderr(err error, stderr string) error {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Errorf("%w (stderr: %s)", err, last)
+	}
+	return err
+}
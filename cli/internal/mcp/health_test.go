@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguredServers(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, ".mcp.json")
+	desktop := filepath.Join(dir, "claude_desktop_config.json")
+	require.NoError(t, WriteServerEntry(project, "github", ServerEntry{Command: "npx", Args: []string{"-y", "server-github"}}))
+	require.NoError(t, WriteServerEntry(desktop, "filesystem", ServerEntry{Command: "npx"}))
+	require.NoError(t, WriteServerEntry(desktop, "github", ServerEntry{Command: "npx"}))
+
+	servers, err := ConfiguredServers(project, desktop, filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	require.Len(t, servers, 3)
+	assert.Equal(t, "filesystem", servers[0].Name)
+	assert.Equal(t, "github", servers[1].Name)
+	assert.Equal(t, project, servers[1].ConfigPath)
+	assert.Equal(t, []string{"-y", "server-github"}, servers[1].Entry.Args)
+	assert.Equal(t, desktop, servers[2].ConfigPath)
+
+	require.NoError(t, os.WriteFile(project, []byte("{not json"), 0644))
+	_, err = ConfiguredServers(project)
+	assert.ErrorIs(t, err, ErrConfigCorrupted)
+}
+
+// testHealthChecker finds only the given executables and packages
+func testHealthChecker(executables, packages []string, env map[string]string, secrets SecretStore) *HealthChecker {
+	contains := func(list []string, value string) bool {
+		for _, item := range list {
+			if item == value {
+				return true
+			}
+		}
+		return false
+	}
+	return NewHealthChecker(HealthOptions{
+		Secrets: secrets,
+		LookPath: func(file string) (string, error) {
+			if contains(executables, file) {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("not found")
+		},
+		Packages: func(ctx context.Context, runner, pkg string) (bool, error) {
+			return contains(packages, pkg), nil
+		},
+		Getenv: func(key string) string { return env[key] },
+	})
+}
+
+func TestHealthCheckerCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("healthy", func(t *testing.T) {
+		checker := testHealthChecker([]string{"npx"}, []string{"server-github"}, map[string]string{"TOKEN": "x"}, nil)
+		health := checker.Check(ctx, ConfiguredServer{Name: "github", Entry: ServerEntry{
+			Command: "npx", Args: []string{"-y", "server-github"}, Env: map[string]string{"GITHUB_TOKEN": "${TOKEN}"},
+		}})
+		assert.Equal(t, HealthHealthy, health.Status)
+		assert.Empty(t, health.Problems)
+		assert.Empty(t, health.Warnings)
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		checker := testHealthChecker(nil, nil, nil, nil)
+		health := checker.Check(ctx, ConfiguredServer{Name: "github", Entry: ServerEntry{Command: "npx", Args: []string{"server-github"}}})
+		assert.Equal(t, HealthBroken, health.Status)
+		assert.Contains(t, health.Problems, `command "npx" not found on PATH`)
+		assert.Contains(t, health.Remediation, runnerInstallHints["npx"])
+	})
+
+	t.Run("package not installed is a warning", func(t *testing.T) {
+		checker := testHealthChecker([]string{"npx"}, nil, nil, nil)
+		health := checker.Check(ctx, ConfiguredServer{Name: "github", Entry: ServerEntry{Command: "npx", Args: []string{"-y", "server-github"}}})
+		assert.Equal(t, HealthHealthy, health.Status)
+		require.Len(t, health.Warnings, 1)
+		assert.Contains(t, health.Warnings[0], "server-github")
+	})
+
+	t.Run("unset placeholder", func(t *testing.T) {
+		checker := testHealthChecker([]string{"server"}, nil, nil, nil)
+		health := checker.Check(ctx, ConfiguredServer{Name: "github", Entry: ServerEntry{
+			Command: "server", Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"},
+		}})
+		assert.Equal(t, HealthBroken, health.Status)
+		assert.Contains(t, health.Problems, "GITHUB_TOKEN uses GITHUB_TOKEN, which is not set")
+	})
+
+	t.Run("secret references", func(t *testing.T) {
+		store := memorySecretStore{}
+		ref, err := StoreSecret(ctx, store, "github", "GITHUB_TOKEN", "ghp_secret")
+		require.NoError(t, err)
+		entry := wrapWithSecretResolver(ServerEntry{
+			Command: "server",
+			Env:     map[string]string{"GITHUB_TOKEN": ref, "OTHER": SecretRef("github", "OTHER")},
+		}, "ddx")
+
+		health := testHealthChecker([]string{"ddx", "server"}, nil, nil, store).Check(ctx, ConfiguredServer{Name: "github", Entry: entry})
+		assert.Equal(t, HealthBroken, health.Status)
+		assert.Equal(t, []string{"OTHER: stored secret secret://mcp/github/OTHER not found"}, health.Problems)
+
+		// The wrapped server command must be present as well as ddx
+		health = testHealthChecker([]string{"ddx"}, nil, nil, nil).Check(ctx, ConfiguredServer{Name: "github", Entry: entry})
+		assert.Contains(t, health.Problems, `command "server" not found on PATH`)
+		assert.Len(t, health.Warnings, 2)
+	})
+}
+
+func TestHealthCheckerHandshake(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake servers are shell scripts")
+	}
+
+	dir := t.TempDir()
+	script := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755))
+		return path
+	}
+	answering := script("answering", `read line
+echo "starting up"
+echo '{"jsonrpc":"2.0","method":"notifications/message","params":{}}'
+echo '{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2024-11-05","serverInfo":{"name":"fake","version":"1.2.3"}}}'
+sleep 5
+`)
+	crashing := script("crashing", "echo 'missing API key' >&2\nexit 1\n")
+	silent := script("silent", "sleep 5\n")
+
+	checker := NewHealthChecker(HealthOptions{
+		Handshake: true,
+		Timeout:   time.Second,
+		Packages:  func(ctx context.Context, runner, pkg string) (bool, error) { return true, nil },
+	})
+	ctx := context.Background()
+
+	health := checker.Check(ctx, ConfiguredServer{Name: "fake", Entry: ServerEntry{Command: answering}})
+	assert.Equal(t, HealthHealthy, health.Status, health.Problems)
+	require.NotNil(t, health.Handshake)
+	assert.Equal(t, "fake", health.Handshake.ServerName)
+	assert.Equal(t, "1.2.3", health.Handshake.ServerVersion)
+	assert.Equal(t, "2024-11-05", health.Handshake.ProtocolVersion)
+
+	health = checker.Check(ctx, ConfiguredServer{Name: "crashing", Entry: ServerEntry{Command: crashing}})
+	assert.Equal(t, HealthBroken, health.Status)
+	require.Len(t, health.Problems, 1)
+	assert.Contains(t, health.Problems[0], "missing API key")
+
+	health = checker.Check(ctx, ConfiguredServer{Name: "silent", Entry: ServerEntry{Command: silent}})
+	assert.Equal(t, HealthBroken, health.Status)
+	assert.Contains(t, health.Problems[0], "no initialize response within 1s")
+}
@@ -1,19 +1,19 @@
 package mcp
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 )
 
 // Installer manages MCP server installation
 type Installer struct {
 	registry  *Registry
-	claude    *ClaudeWrapper
 	validator *Validator
 	out       io.Writer
 }
@@ -26,7 +26,6 @@ func NewInstaller() *Installer {
 // NewInstallerWithWriter creates a new installer with a custom output writer
 func NewInstallerWithWriter(w io.Writer) *Installer {
 	return &Installer{
-		claude:    NewClaudeWrapper(),
 		validator: NewValidator(),
 		out:       w,
 	}
@@ -60,6 +59,12 @@ func (i *Installer) InstallWithLibraryPath(serverName string, opts InstallOption
 	if err != nil {
 		return fmt.Errorf("getting server %s: %w", serverName, err)
 	}
+	if !server.SupportsPlatform(runtime.GOOS) {
+		return NewMCPError(ErrCodeValidationError,
+			fmt.Sprintf("%s is not supported on %s", server.Name, runtime.GOOS),
+			fmt.Sprintf("Supported platforms: %s", strings.Join(server.Compatibility.Platforms, ", ")),
+			nil)
+	}
 
 	// Show server information
 	_, _ = fmt.Fprintf(i.out, "📦 %s - %s\n", server.Name, server.Description)
@@ -73,38 +78,37 @@ func (i *Installer) InstallWithLibraryPath(serverName string, opts InstallOption
 		_, _ = fmt.Fprintln(i.out)
 	}
 
-	// Validate and prompt for environment variables
+	// Collect and validate environment values
 	if len(server.Environment) > 0 {
 		_, _ = fmt.Fprintf(i.out, "🔧 Configuring server environment...\n")
 	}
-	if err := i.validateAndPromptEnvironment(server, opts.Environment, opts.Yes); err != nil {
+	env, err := i.collectEnvironment(server, opts)
+	if err != nil {
+		return fmt.Errorf("configuring environment: %w", err)
+	}
+	if err := i.validateEnvironment(server, env); err != nil {
 		return fmt.Errorf("configuring environment: %w", err)
 	}
 
-	// Detect package manager and display
-	packageManager := i.detectPackageManager()
-	_, _ = fmt.Fprintf(i.out, "Using package manager: %s\n", packageManager)
-
-	// Check Claude CLI availability
-	if err := i.claude.IsAvailable(); err != nil {
-		return fmt.Errorf("Claude CLI not available: %w", err)
+	targets, err := installTargets(opts, workingDir)
+	if err != nil {
+		return err
 	}
-	_, _ = fmt.Fprintf(i.out, "📍 Detected Claude CLI available\n")
 
-	// Check if already installed
-	isInstalled := false
-	if opts.ConfigPath != "" {
-		// Check config file if path specified
-		isInstalled = i.isServerInConfigFile(serverName, opts.ConfigPath)
-	} else {
-		// Check via Claude CLI
-		if status, err := i.claude.GetServerStatus(serverName); err == nil && status.Installed {
-			isInstalled = true
+	// Skip clients that already have the server configured
+	var pending []installTarget
+	for _, target := range targets {
+		exists, err := HasServerEntry(target.path, serverName)
+		if err != nil {
+			return fmt.Errorf("checking %s config: %w", target.name, err)
 		}
+		if exists {
+			_, _ = fmt.Fprintf(i.out, "⚠️  %s is already installed for %s (%s)\n", serverName, target.name, target.path)
+			continue
+		}
+		pending = append(pending, target)
 	}
-
-	if isInstalled && !opts.DryRun {
-		_, _ = fmt.Fprintf(i.out, "⚠️  %s is already installed.\n", serverName)
+	if len(pending) == 0 {
 		_, _ = fmt.Fprintf(i.out, "💡 To upgrade or reinstall, use: ddx mcp upgrade %s\n", serverName)
 		return fmt.Errorf("%w: %s", ErrAlreadyInstalled, serverName)
 	}
@@ -113,28 +117,53 @@ func (i *Installer) InstallWithLibraryPath(serverName string, opts InstallOption
 		_, _ = fmt.Fprintf(i.out, "🔍 Dry run mode - showing what would be done:\n")
 		_, _ = fmt.Fprintf(i.out, "   - Server: %s (%s)\n", serverName, server.Description)
 		_, _ = fmt.Fprintf(i.out, "   - Command: %s %s\n", server.Command.Executable, strings.Join(server.Command.Args, " "))
-		if len(opts.Environment) > 0 {
-			_, _ = fmt.Fprintf(i.out, "   - Environment variables: %d configured\n", len(opts.Environment))
+		if len(env) > 0 {
+			_, _ = fmt.Fprintf(i.out, "   - Environment variables: %d configured\n", len(env))
+		}
+		for _, target := range pending {
+			_, _ = fmt.Fprintf(i.out, "   - %s config: %s\n", target.name, target.path)
 		}
-		_, _ = fmt.Fprintf(i.out, "   - Claude CLI: claude mcp add %s\n", serverName)
 		return nil
 	}
 
-	// Install server via Claude CLI
-	_, _ = fmt.Fprintf(i.out, "📦 Installing server via Claude CLI...\n")
-	if err := i.addServerWithConfig(serverName, server, opts); err != nil {
-		return fmt.Errorf("installing server: %w", err)
+	if opts.Secrets != nil {
+		if env, err = i.storeSecrets(server, env, opts.Secrets); err != nil {
+			return err
+		}
+	}
+
+	executable := opts.Executable
+	if executable == "" {
+		executable = "ddx"
+	}
+
+	var placeholders []string
+	for _, target := range pending {
+		entry, missing := BuildServerEntry(server, env, target.placeholderSecrets)
+		if hasSecretRefs(entry.Env) {
+			entry = wrapWithSecretResolver(entry, executable)
+		}
+		if err := WriteServerEntry(target.path, serverName, entry); err != nil {
+			return fmt.Errorf("updating %s config: %w", target.name, err)
+		}
+		_, _ = fmt.Fprintf(i.out, "📝 Updated %s config: %s\n", target.name, target.path)
+		placeholders = appendUnique(placeholders, missing...)
 	}
 
 	// Success message with next steps
 	_, _ = fmt.Fprintf(i.out, "✅ %s MCP server installed Successfully!\n\n", serverName)
+	if len(placeholders) > 0 {
+		_, _ = fmt.Fprintf(i.out, "🔐 Set these variables before starting the client (written as ${NAME} placeholders):\n")
+		for _, name := range placeholders {
+			_, _ = fmt.Fprintf(i.out, "  - %s\n", name)
+		}
+		_, _ = fmt.Fprintln(i.out)
+	}
 	_, _ = fmt.Fprintf(i.out, "🚀 Next steps:\n")
-	_, _ = fmt.Fprintf(i.out, "  1. Restart Claude Code to load the server\n")
-	_, _ = fmt.Fprintf(i.out, "  2. Look for %s in Claude Code's MCP section\n", serverName)
-	if serverName == "github" {
-		_, _ = fmt.Fprintf(i.out, "  3. Test with: \"Show my recent commits\"\n")
-	} else if serverName == "filesystem" {
-		_, _ = fmt.Fprintf(i.out, "  3. Test with: \"List files in current directory\"\n")
+	_, _ = fmt.Fprintf(i.out, "  1. Restart %s to load the server\n", pending[0].name)
+	_, _ = fmt.Fprintf(i.out, "  2. Look for %s in the client's MCP section\n", serverName)
+	if server.Verification.TestCommand != "" {
+		_, _ = fmt.Fprintf(i.out, "  3. Test with: \"%s\"\n", server.Verification.TestCommand)
 	} else {
 		_, _ = fmt.Fprintf(i.out, "  3. Test the server functionality\n")
 	}
@@ -142,32 +171,110 @@ func (i *Installer) InstallWithLibraryPath(serverName string, opts InstallOption
 	return nil
 }
 
-// validateAndPromptEnvironment validates and prompts for required environment variables
-func (i *Installer) validateAndPromptEnvironment(server *Server, env map[string]string, skipPrompts bool) error {
-	for _, envVar := range server.GetRequiredEnvironment() {
-		value, exists := env[envVar.Name]
+// installTarget is a client configuration file to install a server into
+type installTarget struct {
+	name string
+	path string
+	// placeholderSecrets keeps sensitive values out of project files
+	placeholderSecrets bool
+}
 
-		// If value is missing and not in non-interactive mode, prompt for it
-		if (!exists || value == "") && !skipPrompts {
-			prompt := fmt.Sprintf("🔐 Enter %s", envVar.Name)
-			if envVar.Description != "" {
-				prompt = fmt.Sprintf("🔐 Enter %s (%s)", envVar.Name, envVar.Description)
-			}
+// installTargets resolves the configuration files for the requested clients.
+// An explicit config path replaces client detection.
+func installTargets(opts InstallOptions, workingDir string) ([]installTarget, error) {
+	if opts.ConfigPath != "" {
+		return []installTarget{{name: "Claude", path: opts.ConfigPath}}, nil
+	}
 
-			if envVar.Sensitive {
-				prompt += " (input will be masked)"
+	clients := opts.Clients
+	if len(clients) == 0 {
+		clients = []ClaudeType{ClaudeCode}
+	}
+
+	targets := make([]installTarget, 0, len(clients))
+	for _, client := range clients {
+		path, err := ClientConfigPath(client, workingDir)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, installTarget{
+			name:               ClientName(client),
+			path:               path,
+			placeholderSecrets: client == ClaudeCode,
+		})
+	}
+	return targets, nil
+}
+
+// appendUnique appends values not already present in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
 			}
-			prompt += ": "
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
 
-			_, _ = fmt.Fprint(i.out, prompt)
+// collectEnvironment returns the provided environment values, prompting
+// for required and sensitive variables that were not given
+func (i *Installer) collectEnvironment(server *Server, opts InstallOptions) (map[string]string, error) {
+	env := make(map[string]string, len(opts.Environment))
+	for name, value := range opts.Environment {
+		env[name] = value
+	}
+	if opts.Prompt == nil || opts.Yes {
+		return env, nil
+	}
+
+	for _, envVar := range server.Environment {
+		if _, provided := env[envVar.Name]; provided || !(envVar.Required || envVar.Sensitive) {
+			continue
+		}
+		value, err := opts.Prompt(envVar)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", envVar.Name, err)
+		}
+		if value != "" {
+			env[envVar.Name] = value
+		}
+	}
+	return env, nil
+}
 
-			// For now, skip actual input reading in favor of clear error message
-			return fmt.Errorf("interactive prompting not yet implemented - please provide %s via --env %s=value", envVar.Name, envVar.Name)
+// storeSecrets moves sensitive values into the secret store, replacing them
+// with references so they are never written to client config files
+func (i *Installer) storeSecrets(server *Server, env map[string]string, store SecretStore) (map[string]string, error) {
+	ctx := context.Background()
+	for _, envVar := range server.Environment {
+		value, ok := env[envVar.Name]
+		if !envVar.Sensitive || !ok || value == "" || IsSecretRef(value) {
+			continue
 		}
+		ref, err := StoreSecret(ctx, store, server.Name, envVar.Name, value)
+		if err != nil {
+			return nil, err
+		}
+		env[envVar.Name] = ref
+		_, _ = fmt.Fprintf(i.out, "🔐 Stored %s in the credential store\n", envVar.Name)
+	}
+	return env, nil
+}
 
-		// If still missing after potential prompting, return error
-		if !exists || value == "" {
-			return fmt.Errorf("%w: %s", ErrMissingRequired, envVar.Name)
+// validateEnvironment validates provided values for the server's declared
+// variables. Missing values are written as placeholders at install time.
+func (i *Installer) validateEnvironment(server *Server, env map[string]string) error {
+	for _, envVar := range server.Environment {
+		value, exists := env[envVar.Name]
+		if !exists || value == "" || IsSecretRef(value) {
+			continue
 		}
 
 		// Validate against regex if provided
@@ -182,88 +289,13 @@ func (i *Installer) validateAndPromptEnvironment(server *Server, env map[string]
 		}
 
 		// Show validation success for sensitive variables
-		if envVar.Sensitive && value != "" {
+		if envVar.Sensitive {
 			_, _ = fmt.Fprintf(i.out, "✅ %s validated\n", envVar.Name)
 		}
 	}
 	return nil
 }
 
-// addServerWithConfig adds server and creates config file if path specified
-func (i *Installer) addServerWithConfig(serverName string, server *Server, opts InstallOptions) error {
-	// If config path is specified, create/update the Claude config file
-	if opts.ConfigPath != "" {
-		return i.createClaudeConfig(serverName, server, opts)
-	}
-
-	// Otherwise use regular Claude CLI
-	return i.claude.AddServer(serverName, server.Command.Executable, server.Command.Args, opts.Environment)
-}
-
-// createClaudeConfig creates a Claude config file at the specified path
-func (i *Installer) createClaudeConfig(serverName string, server *Server, opts InstallOptions) error {
-	configDir := filepath.Dir(opts.ConfigPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
-
-	// Create basic Claude MCP configuration
-	config := map[string]interface{}{
-		"mcpServers": map[string]interface{}{
-			serverName: map[string]interface{}{
-				"command": server.Command.Executable,
-				"args":    server.Command.Args,
-			},
-		},
-	}
-
-	// Add environment variables if present
-	if len(opts.Environment) > 0 {
-		serverConfig := config["mcpServers"].(map[string]interface{})[serverName].(map[string]interface{})
-		serverConfig["env"] = opts.Environment
-	}
-
-	// Write config file
-	jsonData, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling config: %w", err)
-	}
-
-	if err := os.WriteFile(opts.ConfigPath, jsonData, 0644); err != nil {
-		return fmt.Errorf("writing config file: %w", err)
-	}
-
-	return nil
-}
-
-// isServerInConfigFile checks if a server is already configured in the config file
-func (i *Installer) isServerInConfigFile(serverName, configPath string) bool {
-	// Check if config file exists
-	if _, err := os.Stat(configPath); err != nil {
-		return false // File doesn't exist, so server isn't installed
-	}
-
-	// Read existing config
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return false // Can't read file, assume not installed
-	}
-
-	// Parse JSON config
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return false // Invalid JSON, assume not installed
-	}
-
-	// Check if mcpServers section exists and contains our server
-	if mcpServers, ok := config["mcpServers"].(map[string]interface{}); ok {
-		_, exists := mcpServers[serverName]
-		return exists
-	}
-
-	return false
-}
-
 // detectPackageManager detects which package manager is in use
 func (i *Installer) detectPackageManager() string {
 	// Check for lock files in order of preference
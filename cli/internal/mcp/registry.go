@@ -6,11 +6,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/match"
 	"gopkg.in/yaml.v3"
 )
 
@@ -74,6 +76,9 @@ func loadRegistryFromPath(path, workingDir, libraryPath string) (*Registry, erro
 	if err := registry.validate(); err != nil {
 		return nil, fmt.Errorf("validating registry: %w", err)
 	}
+	if err := ValidateRegistryDefinition(data); err != nil {
+		return nil, fmt.Errorf("validating registry: %w", err)
+	}
 
 	// Set working directory for library path resolution
 	registry.workingDir = workingDir
@@ -122,13 +127,15 @@ func (r *Registry) Search(term string) ([]*ServerReference, error) {
 		return all, nil
 	}
 
-	lowerTerm := strings.ToLower(term)
-	var results []*ServerReference
+	matcher, err := match.Compile(term)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
 
+	var results []*ServerReference
 	for i := range r.Servers {
 		ref := &r.Servers[i]
-		if strings.Contains(strings.ToLower(ref.Name), lowerTerm) ||
-			strings.Contains(strings.ToLower(ref.Description), lowerTerm) {
+		if matcher.Match(ref.Name) || matcher.Match(ref.Description) {
 			results = append(results, ref)
 		}
 	}
@@ -194,11 +201,13 @@ func (r *Registry) ListServers(opts ListOptions) ([]*ServerReference, error) {
 
 	// Apply search filter
 	if opts.Search != "" {
+		matcher, err := match.Compile(opts.Search)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search pattern: %w", err)
+		}
 		var searched []*ServerReference
-		lowerSearch := strings.ToLower(opts.Search)
 		for _, ref := range results {
-			if strings.Contains(strings.ToLower(ref.Name), lowerSearch) ||
-				strings.Contains(strings.ToLower(ref.Description), lowerSearch) {
+			if matcher.Match(ref.Name) || matcher.Match(ref.Description) {
 				searched = append(searched, ref)
 			}
 		}
@@ -273,6 +282,10 @@ func (r *Registry) loadServerFromFile(file string) (*Server, error) {
 		return nil, fmt.Errorf("reading server file %s: %w", serverPath, err)
 	}
 
+	if err := ValidateServerDefinition(data); err != nil {
+		return nil, fmt.Errorf("validating server file %s: %w", serverPath, err)
+	}
+
 	var server Server
 	if err := yaml.Unmarshal(data, &server); err != nil {
 		return nil, fmt.Errorf("parsing server YAML %s: %w", serverPath, err)
@@ -332,6 +345,106 @@ func (s *Server) GetRequiredEnvironment() []EnvironmentVar {
 	return required
 }
 
+// InstallMethod returns how the server package is obtained, inferring it
+// from the command when the definition does not declare one
+func (s *Server) InstallMethod() InstallSpec {
+	if s.Install.Method != "" {
+		return s.Install
+	}
+
+	var pkg string
+	for _, arg := range s.Command.Args {
+		if !strings.HasPrefix(arg, "-") {
+			pkg = arg
+			break
+		}
+	}
+
+	switch filepath.Base(s.Command.Executable) {
+	case "npx", "npm", "pnpm", "yarn":
+		return InstallSpec{Method: "npm", Package: pkg}
+	case "uvx", "pip", "pipx":
+		return InstallSpec{Method: "pip", Package: pkg}
+	case "docker":
+		return InstallSpec{Method: "docker"}
+	default:
+		return InstallSpec{Method: "binary", Package: s.Command.Executable}
+	}
+}
+
+// SupportsPlatform reports whether the server runs on the given GOOS.
+// Definitions without platform constraints support every platform.
+func (s *Server) SupportsPlatform(goos string) bool {
+	if len(s.Compatibility.Platforms) == 0 {
+		return true
+	}
+	for _, platform := range s.Compatibility.Platforms {
+		if platform == "macos" {
+			platform = "darwin"
+		}
+		if strings.EqualFold(platform, goos) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check loads and validates every server definition the registry references
+// and returns all problems found, so library contributions can be verified
+// in a single pass
+func (r *Registry) Check() []error {
+	var problems []error
+	for _, ref := range r.Servers {
+		server, err := r.loadServerFromFile(ref.File)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", ref.Name, err))
+			continue
+		}
+		if !strings.EqualFold(server.Name, ref.Name) {
+			problems = append(problems, fmt.Errorf("%s: %s defines server %q", ref.Name, ref.File, server.Name))
+		}
+		if ref.Category != "" && !strings.EqualFold(server.Category, ref.Category) {
+			problems = append(problems, fmt.Errorf("%s: category %q does not match registry entry %q", ref.Name, server.Category, ref.Category))
+		}
+		if len(r.Categories) > 0 {
+			if _, ok := r.Categories[server.Category]; !ok {
+				problems = append(problems, fmt.Errorf("%s: category %q is not declared in the registry", ref.Name, server.Category))
+			}
+		}
+		for _, env := range server.Environment {
+			if env.Validation == "" {
+				continue
+			}
+			if _, err := regexp.Compile(env.Validation); err != nil {
+				problems = append(problems, fmt.Errorf("%s: invalid validation pattern for %s: %w", ref.Name, env.Name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// InstalledServers reports which servers are configured, according to the
+// Claude CLI and any of the given Claude config files
+func (r *Registry) InstalledServers(configPaths ...string) map[string]bool {
+	installed := make(map[string]bool)
+	if r.claude != nil {
+		if servers, err := r.claude.ListServers(); err == nil {
+			for name := range servers {
+				installed[name] = true
+			}
+		}
+	}
+
+	for _, configPath := range configPaths {
+		for _, server := range r.Servers {
+			if r.isServerInConfigFile(server.Name, configPath) {
+				installed[server.Name] = true
+			}
+		}
+	}
+	return installed
+}
+
 // RegistryCache provides thread-safe caching for the registry
 type RegistryCache struct {
 	mu       sync.RWMutex
@@ -426,24 +539,11 @@ func (r *Registry) formatTable(w io.Writer, servers []*ServerReference, opts Lis
 	}
 
 	// Check installed servers via Claude CLI and config files
-	installedServers := make(map[string]bool)
-	if r.claude != nil {
-		// Get list of installed servers from Claude CLI
-		if servers, err := r.claude.ListServers(); err == nil {
-			for name := range servers {
-				installedServers[name] = true
-			}
-		}
-	}
-
-	// Also check config file if provided
+	var configPaths []string
 	if opts.ConfigPath != "" {
-		for _, server := range servers {
-			if r.isServerInConfigFile(server.Name, opts.ConfigPath) {
-				installedServers[server.Name] = true
-			}
-		}
+		configPaths = append(configPaths, opts.ConfigPath)
 	}
+	installedServers := r.InstalledServers(configPaths...)
 
 	for category, categoryServers := range categories {
 		// Capitalize first letter manually (strings.Title is deprecated)
@@ -348,3 +348,72 @@ func TestListServers(t *testing.T) {
 		assert.Len(t, results, 1)
 	})
 }
+
+func TestRegistryCheck(t *testing.T) {
+	registryPath, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	registry, err := mcp.LoadRegistry(registryPath, wd)
+	require.NoError(t, err)
+	assert.Empty(t, registry.Check())
+
+	t.Run("reports mismatched and invalid definitions", func(t *testing.T) {
+		serversDir := filepath.Join(filepath.Dir(registryPath), "servers")
+		mismatched := strings.ReplaceAll(testGithubServerYAML, "category: development", "category: messaging")
+		require.NoError(t, os.WriteFile(filepath.Join(serversDir, "github.yml"), []byte(mismatched), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(serversDir, "postgres.yml"), []byte("name: postgres\n"), 0644))
+
+		registry, err := mcp.LoadRegistry(registryPath, wd)
+		require.NoError(t, err)
+		problems := registry.Check()
+		require.Len(t, problems, 3)
+		assert.Contains(t, problems[0].Error(), "does not match registry entry")
+		assert.Contains(t, problems[1].Error(), "not declared")
+		assert.ErrorIs(t, problems[2], mcp.ErrValidationFailed)
+	})
+}
+
+func TestServerInstallMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		server mcp.Server
+		want   mcp.InstallSpec
+	}{
+		{
+			name:   "declared",
+			server: mcp.Server{Install: mcp.InstallSpec{Method: "pip", Package: "mcp-server-git"}},
+			want:   mcp.InstallSpec{Method: "pip", Package: "mcp-server-git"},
+		},
+		{
+			name:   "inferred from npx",
+			server: mcp.Server{Command: mcp.CommandSpec{Executable: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github"}}},
+			want:   mcp.InstallSpec{Method: "npm", Package: "@modelcontextprotocol/server-github"},
+		},
+		{
+			name:   "inferred from uvx",
+			server: mcp.Server{Command: mcp.CommandSpec{Executable: "uvx", Args: []string{"mcp-server-time"}}},
+			want:   mcp.InstallSpec{Method: "pip", Package: "mcp-server-time"},
+		},
+		{
+			name:   "binary",
+			server: mcp.Server{Command: mcp.CommandSpec{Executable: "/usr/local/bin/my-server"}},
+			want:   mcp.InstallSpec{Method: "binary", Package: "/usr/local/bin/my-server"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.server.InstallMethod())
+		})
+	}
+}
+
+func TestServerSupportsPlatform(t *testing.T) {
+	unconstrained := mcp.Server{}
+	assert.True(t, unconstrained.SupportsPlatform("linux"))
+
+	mac := mcp.Server{Compatibility: mcp.Compatibility{Platforms: []string{"macos"}}}
+	assert.True(t, mac.SupportsPlatform("darwin"))
+	assert.False(t, mac.SupportsPlatform("windows"))
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema/server.schema.json
+var serverSchemaJSON string
+
+//go:embed schema/registry.schema.json
+var registrySchemaJSON string
+
+var (
+	schemaOnce     sync.Once
+	serverSchema   *jsonschema.Schema
+	registrySchema *jsonschema.Schema
+	schemaErr      error
+)
+
+// compileSchemas compiles the embedded schemas once
+func compileSchemas() error {
+	schemaOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource("server.schema.json", strings.NewReader(serverSchemaJSON)); err != nil {
+			schemaErr = fmt.Errorf("adding server schema: %w", err)
+			return
+		}
+		if err := compiler.AddResource("registry.schema.json", strings.NewReader(registrySchemaJSON)); err != nil {
+			schemaErr = fmt.Errorf("adding registry schema: %w", err)
+			return
+		}
+		if serverSchema, schemaErr = compiler.Compile("server.schema.json"); schemaErr != nil {
+			return
+		}
+		registrySchema, schemaErr = compiler.Compile("registry.schema.json")
+	})
+	return schemaErr
+}
+
+// ValidateServerDefinition checks server definition YAML against the schema
+func ValidateServerDefinition(data []byte) error {
+	if err := compileSchemas(); err != nil {
+		return err
+	}
+	return validateYAML(serverSchema, data)
+}
+
+// ValidateRegistryDefinition checks registry index YAML against the schema
+func ValidateRegistryDefinition(data []byte) error {
+	if err := compileSchemas(); err != nil {
+		return err
+	}
+	return validateYAML(registrySchema, data)
+}
+
+// validateYAML decodes YAML and validates it against a compiled schema
+func validateYAML(schema *jsonschema.Schema, data []byte) error {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+	if err := schema.Validate(jsonValue(raw)); err != nil {
+		if verr, ok := err.(*jsonschema.ValidationError); ok {
+			return fmt.Errorf("%w: %s", ErrValidationFailed, schemaErrorDetails(verr))
+		}
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+	return nil
+}
+
+// jsonValue converts decoded YAML into values the schema validator accepts
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = jsonValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = jsonValue(item)
+		}
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return val
+	}
+}
+
+// schemaErrorDetails flattens nested schema errors into one line per cause
+func schemaErrorDetails(err *jsonschema.ValidationError) string {
+	var details []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			details = append(details, fmt.Sprintf("%s: %s", location, e.Message))
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(err)
+	return strings.Join(details, "; ")
+}
@@ -0,0 +1,57 @@
+package mcp_test
+
+import (
+	"testing"
+
+	"github.com/easel/ddx/internal/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateServerDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "valid", yaml: testGithubServerYAML},
+		{
+			name:    "missing command",
+			yaml:    "name: github\ndescription: GitHub\ncategory: development\n",
+			wantErr: "command",
+		},
+		{
+			name:    "invalid name",
+			yaml:    "name: GitHub Server\ndescription: GitHub\ncategory: development\ncommand:\n  executable: npx\n",
+			wantErr: "/name",
+		},
+		{
+			name:    "unknown install method",
+			yaml:    "name: github\ndescription: GitHub\ncategory: development\ncommand:\n  executable: npx\ninstall:\n  method: brew\n",
+			wantErr: "/install/method",
+		},
+		{
+			name:    "unknown platform",
+			yaml:    "name: github\ndescription: GitHub\ncategory: development\ncommand:\n  executable: npx\ncompatibility:\n  platforms: [beos]\n",
+			wantErr: "/compatibility/platforms/0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mcp.ValidateServerDefinition([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, mcp.ErrValidationFailed)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateRegistryDefinition(t *testing.T) {
+	assert.NoError(t, mcp.ValidateRegistryDefinition([]byte(testRegistryYAML)))
+
+	err := mcp.ValidateRegistryDefinition([]byte("version: 1.0.0\nservers:\n  - name: github\n"))
+	assert.ErrorContains(t, err, "file")
+}
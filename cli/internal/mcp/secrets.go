@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/auth"
+)
+
+// SecretRefPrefix marks a configuration value that names a stored secret
+// instead of holding it
+const SecretRefPrefix = auth.SecretRefPrefix
+
+// SecretPlatform is the credential platform MCP server secrets are stored under
+const SecretPlatform auth.Platform = "mcp"
+
+// SecretStore stores and retrieves secrets; auth.DefaultManager implements it
+type SecretStore interface {
+	StoreCredential(ctx context.Context, cred *auth.Credential) error
+	GetCredential(ctx context.Context, platform auth.Platform, repository string) (*auth.Credential, error)
+}
+
+// SecretRef returns the reference for a server's environment variable
+func SecretRef(server, name string) string {
+	return auth.SecretRef(SecretPlatform, secretID(server, name))
+}
+
+// IsSecretRef reports whether a value is a secret reference
+func IsSecretRef(value string) bool {
+	return auth.IsSecretRef(value)
+}
+
+// ParseSecretRef splits a secret reference into its credential platform and ID
+func ParseSecretRef(value string) (auth.Platform, string, error) {
+	return auth.ParseSecretRef(value)
+}
+
+// secretID is the credential ID of a server's environment variable
+func secretID(server, name string) string {
+	return server + "/" + name
+}
+
+// StoreSecret saves a server's secret value and returns its reference
+func StoreSecret(ctx context.Context, store SecretStore, server, name, value string) (string, error) {
+	now := time.Now()
+	cred := &auth.Credential{
+		ID:        secretID(server, name),
+		Platform:  SecretPlatform,
+		Method:    auth.AuthMethodToken,
+		Token:     value,
+		Metadata:  map[string]string{"server": server, "variable": name},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := store.StoreCredential(ctx, cred); err != nil {
+		return "", fmt.Errorf("storing %s for %s: %w", name, server, err)
+	}
+	return SecretRef(server, name), nil
+}
+
+// ResolveSecretEnv replaces secret references in NAME=value environment
+// entries with the stored values. Every unresolved reference is reported.
+func ResolveSecretEnv(ctx context.Context, store SecretStore, environ []string) ([]string, error) {
+	resolved := make([]string, 0, len(environ))
+	var missing []string
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		if !IsSecretRef(value) {
+			resolved = append(resolved, entry)
+			continue
+		}
+
+		platform, id, err := ParseSecretRef(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		cred, err := store.GetCredential(ctx, platform, id)
+		if err != nil || cred.Token == "" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", name, value))
+			continue
+		}
+		resolved = append(resolved, name+"="+cred.Token)
+	}
+
+	if len(missing) > 0 {
+		return nil, NewMCPError(ErrCodeInvalidEnv,
+			"stored secrets not found: "+strings.Join(missing, ", "),
+			"Run 'ddx mcp install <server>' on this machine to store them",
+			nil)
+	}
+	return resolved, nil
+}
+
+// wrapWithSecretResolver launches the server through 'ddx mcp exec' so
+// secret references in its environment are resolved at startup
+func wrapWithSecretResolver(entry ServerEntry, executable string) ServerEntry {
+	args := append([]string{"mcp", "exec", "--", entry.Command}, entry.Args...)
+	return ServerEntry{Command: executable, Args: args, Env: entry.Env}
+}
+
+// hasSecretRefs reports whether any environment value is a secret reference
+func hasSecretRefs(env map[string]string) bool {
+	for _, value := range env {
+		if IsSecretRef(value) {
+			return true
+		}
+	}
+	return false
+}
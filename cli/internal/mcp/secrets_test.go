@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/easel/ddx/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySecretStore keeps credentials in memory for tests
+type memorySecretStore map[string]*auth.Credential
+
+func (m memorySecretStore) StoreCredential(ctx context.Context, cred *auth.Credential) error {
+	m[string(cred.Platform)+"/"+cred.ID] = cred
+	return nil
+}
+
+func (m memorySecretStore) GetCredential(ctx context.Context, platform auth.Platform, repository string) (*auth.Credential, error) {
+	if cred, ok := m[string(platform)+"/"+repository]; ok {
+		return cred, nil
+	}
+	return nil, fmt.Errorf("not found")
+}
+
+func TestSecretRef(t *testing.T) {
+	ref := SecretRef("github", "TOKEN")
+	assert.Equal(t, "secret://mcp/github/TOKEN", ref)
+	assert.True(t, IsSecretRef(ref))
+
+	platform, id, err := ParseSecretRef(ref)
+	require.NoError(t, err)
+	assert.Equal(t, SecretPlatform, platform)
+	assert.Equal(t, "github/TOKEN", id)
+
+	_, _, err = ParseSecretRef("secret://mcp")
+	assert.Error(t, err)
+	_, _, err = ParseSecretRef("plain")
+	assert.Error(t, err)
+}
+
+func TestStoreAndResolveSecrets(t *testing.T) {
+	ctx := context.Background()
+	store := memorySecretStore{}
+
+	ref, err := StoreSecret(ctx, store, "github", "TOKEN", "ghp_secret")
+	require.NoError(t, err)
+
+	environ, err := ResolveSecretEnv(ctx, store, []string{"PATH=/bin", "TOKEN=" + ref})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/bin", "TOKEN=ghp_secret"}, environ)
+
+	_, err = ResolveSecretEnv(ctx, store, []string{"OTHER=" + SecretRef("slack", "TOKEN")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OTHER")
+}
+
+func TestBuildServerEntry_SecretReferences(t *testing.T) {
+	server := &Server{
+		Name:        "github",
+		Command:     CommandSpec{Executable: "npx", Args: []string{"-y", "server-github"}},
+		Environment: []EnvironmentVar{{Name: "TOKEN", Required: true, Sensitive: true}},
+	}
+	ref := SecretRef("github", "TOKEN")
+
+	entry, placeholders := BuildServerEntry(server, map[string]string{"TOKEN": ref}, true)
+	assert.Equal(t, ref, entry.Env["TOKEN"], "references are safe to write to project config")
+	assert.Empty(t, placeholders)
+	require.True(t, hasSecretRefs(entry.Env))
+
+	wrapped := wrapWithSecretResolver(entry, "ddx")
+	assert.Equal(t, "ddx", wrapped.Command)
+	assert.Equal(t, []string{"mcp", "exec", "--", "npx", "-y", "server-github"}, wrapped.Args)
+	assert.Equal(t, entry.Env, wrapped.Env)
+}
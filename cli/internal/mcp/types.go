@@ -12,6 +12,7 @@ type Server struct {
 	Version       string           `yaml:"version" json:"version"`
 	Tags          []string         `yaml:"tags" json:"tags"`
 	Command       CommandSpec      `yaml:"command" json:"command"`
+	Install       InstallSpec      `yaml:"install,omitempty" json:"install,omitempty"`
 	Environment   []EnvironmentVar `yaml:"environment" json:"environment"`
 	Documentation Documentation    `yaml:"documentation" json:"documentation"`
 	Compatibility Compatibility    `yaml:"compatibility" json:"compatibility"`
@@ -26,6 +27,12 @@ type CommandSpec struct {
 	Args       []string `yaml:"args" json:"args"`
 }
 
+// InstallSpec describes how the server package is obtained
+type InstallSpec struct {
+	Method  string `yaml:"method" json:"method"` // npm, pip, docker or binary
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+}
+
 // EnvironmentVar defines a required or optional environment variable
 type EnvironmentVar struct {
 	Name        string `yaml:"name" json:"name"`
@@ -141,6 +148,14 @@ type InstallOptions struct {
 	DryRun      bool              // Show what would be done
 	Yes         bool              // Skip confirmations
 	ConfigPath  string            // Path to Claude config file
+	Clients     []ClaudeType      // Clients to configure (default Claude Code)
+
+	// Prompt asks for a variable that was not provided; nil disables prompting
+	Prompt func(envVar EnvironmentVar) (string, error)
+	// Secrets stores sensitive values; nil writes them to the client config
+	Secrets SecretStore
+	// Executable launches servers whose secrets are stored (default "ddx")
+	Executable string
 }
 
 // ServerStatus represents the status of an installed server
@@ -0,0 +1,94 @@
+package metaprompt
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SystemPromptsDir is the directory, below each assistant's prompts, that
+// holds meta-prompts
+const SystemPromptsDir = "system-prompts"
+
+// MetaPrompt is a meta-prompt available in the library
+type MetaPrompt struct {
+	// Path is relative to the library's prompts directory, as set in
+	// system.meta_prompt (e.g. "claude/system-prompts/focused.md")
+	Path string
+	// Name is the file name without extension (e.g. "focused")
+	Name string
+	// Title is the first heading of the prompt, if any
+	Title string
+}
+
+// Available lists the meta-prompts under promptsDir, that is every
+// markdown file in a <assistant>/system-prompts directory, sorted by path
+func Available(promptsDir string) ([]MetaPrompt, error) {
+	matches, err := filepath.Glob(filepath.Join(promptsDir, "*", SystemPromptsDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]MetaPrompt, 0, len(matches))
+	for _, match := range matches {
+		rel, err := filepath.Rel(promptsDir, match)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, MetaPrompt{
+			Path:  filepath.ToSlash(rel),
+			Name:  strings.TrimSuffix(filepath.Base(match), ".md"),
+			Title: readTitle(match),
+		})
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Path < prompts[j].Path })
+	return prompts, nil
+}
+
+// Find resolves a meta-prompt given by path or by name. A name must match
+// exactly one available meta-prompt.
+func Find(prompts []MetaPrompt, name string) (MetaPrompt, error) {
+	name = filepath.ToSlash(strings.TrimSpace(name))
+	var matches []MetaPrompt
+	for _, p := range prompts {
+		if p.Path == name {
+			return p, nil
+		}
+		if p.Name == strings.TrimSuffix(name, ".md") {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return MetaPrompt{}, fmt.Errorf("meta-prompt %q not found in the library", name)
+	case 1:
+		return matches[0], nil
+	default:
+		paths := make([]string, len(matches))
+		for i, m := range matches {
+			paths[i] = m.Path
+		}
+		return MetaPrompt{}, fmt.Errorf("meta-prompt %q is ambiguous, use one of: %s", name, strings.Join(paths, ", "))
+	}
+}
+
+// readTitle returns the first markdown heading in a file, without its
+// leading #s
+func readTitle(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package metaprompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAvailable tests listing and resolving library meta-prompts
+func TestAvailable(t *testing.T) {
+	promptsDir := t.TempDir()
+	files := map[string]string{
+		"claude/system-prompts/focused.md": "# System Instructions\n\nStay focused.\n",
+		"claude/system-prompts/strict.md":  "Be strict.\n",
+		"gemini/system-prompts/strict.md":  "# Strict\n",
+		"claude/other.md":                  "# Not a meta-prompt\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(promptsDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	prompts, err := Available(promptsDir)
+	require.NoError(t, err)
+	require.Len(t, prompts, 3)
+	assert.Equal(t, MetaPrompt{Path: "claude/system-prompts/focused.md", Name: "focused", Title: "System Instructions"}, prompts[0])
+	assert.Equal(t, "", prompts[1].Title)
+
+	found, err := Find(prompts, "focused")
+	require.NoError(t, err)
+	assert.Equal(t, "claude/system-prompts/focused.md", found.Path)
+
+	found, err = Find(prompts, "gemini/system-prompts/strict.md")
+	require.NoError(t, err)
+	assert.Equal(t, "strict", found.Name)
+
+	_, err = Find(prompts, "strict")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+
+	_, err = Find(prompts, "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	prompts, err = Available(filepath.Join(promptsDir, "none"))
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+}
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/prompts"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/targets"
 )
 
 // MetaPromptInjector manages meta-prompt injection into CLAUDE.md
@@ -33,11 +37,10 @@ type MetaPromptInjectorImpl struct {
 	workingDir     string // Working directory for relative path resolution
 }
 
-// Constants for marker handling (same pattern as persona system)
+// Constants for the managed section (same pattern as persona system)
 const (
-	MetaPromptStartMarker = "<!-- DDX-META-PROMPT:START -->"
-	MetaPromptEndMarker   = "<!-- DDX-META-PROMPT:END -->"
-	MaxMetaPromptSize     = 1024 * 512 // 512KB max
+	MetaPromptSection = "meta-prompt"
+	MaxMetaPromptSize = 1024 * 512 // 512KB max
 )
 
 // NewMetaPromptInjector creates a new injector with default paths
@@ -65,9 +68,9 @@ func (m *MetaPromptInjectorImpl) InjectMetaPrompt(promptPath string) error {
 		return fmt.Errorf("prompt path cannot be empty")
 	}
 
-	// 2. Read prompt content from library
-	promptFullPath := filepath.Join(m.workingDir, m.libraryPath, "prompts", promptPath)
-	promptContent, err := os.ReadFile(promptFullPath)
+	// 2. Read prompt content from library, expanding its includes
+	promptFullPath := filepath.Join(m.promptsDir(), promptPath)
+	promptContent, err := prompts.ExpandFile(promptFullPath, prompts.DirLookup(m.promptsDir()))
 	if err != nil {
 		return fmt.Errorf("failed to read meta-prompt from %s: %w", promptFullPath, err)
 	}
@@ -77,32 +80,18 @@ func (m *MetaPromptInjectorImpl) InjectMetaPrompt(promptPath string) error {
 		return fmt.Errorf("meta-prompt too large: %d bytes (max %d)", len(promptContent), MaxMetaPromptSize)
 	}
 
-	// 4. Read or create CLAUDE.md
-	claudeFullPath := filepath.Join(m.workingDir, m.claudeFilePath)
-	var claudeContent string
-	if fileExists(claudeFullPath) {
-		existing, err := os.ReadFile(claudeFullPath)
-		if err != nil {
-			return fmt.Errorf("failed to read CLAUDE.md: %w", err)
-		}
-		claudeContent = string(existing)
-	} else {
-		// Create default CLAUDE.md if doesn't exist
-		claudeContent = "# CLAUDE.md\n\nThis file provides guidance to Claude when working with code in this repository."
+	// 4. Read or create the target file
+	doc, err := m.loadCLAUDEFile()
+	if err != nil {
+		return err
 	}
 
-	// 5. Remove existing meta-prompt section (if any)
-	claudeContent = m.removeMetaPromptSection(claudeContent)
-
-	// 6. Build new meta-prompt section
-	metaPromptSection := m.buildMetaPromptSection(string(promptContent), promptPath)
-
-	// 7. Append meta-prompt section to CLAUDE.md
-	claudeContent = strings.TrimSpace(claudeContent) + "\n\n" + metaPromptSection
+	// 5. Replace the meta-prompt section, keeping its place in the file
+	doc.Set(sections.Section{Name: MetaPromptSection, Source: promptPath, Order: sections.DefaultOrder, Content: promptContent})
 
-	// 8. Write updated CLAUDE.md
-	if err := m.saveCLAUDEFile(claudeContent); err != nil {
-		return fmt.Errorf("failed to save CLAUDE.md: %w", err)
+	// 6. Write the updated file
+	if err := m.saveCLAUDEFile(doc.String()); err != nil {
+		return fmt.Errorf("failed to save %s: %w", m.claudeFilePath, err)
 	}
 
 	return nil
@@ -115,15 +104,15 @@ func (m *MetaPromptInjectorImpl) RemoveMetaPrompt() error {
 		return nil // Nothing to remove
 	}
 
-	content, err := os.ReadFile(claudeFullPath)
+	doc, err := m.loadCLAUDEFile()
 	if err != nil {
-		return fmt.Errorf("failed to read CLAUDE.md: %w", err)
+		return err
+	}
+	if !doc.Remove(MetaPromptSection) {
+		return nil
 	}
 
-	// Remove meta-prompt section
-	cleanContent := m.removeMetaPromptSection(string(content))
-
-	return m.saveCLAUDEFile(cleanContent)
+	return m.saveCLAUDEFile(doc.String())
 }
 
 // IsInSync checks if CLAUDE.md prompt matches library version
@@ -134,32 +123,27 @@ func (m *MetaPromptInjectorImpl) IsInSync() (bool, error) {
 		return false, fmt.Errorf("CLAUDE.md not found")
 	}
 
-	claudeContent, err := os.ReadFile(claudeFullPath)
-	if err != nil {
-		return false, fmt.Errorf("failed to read CLAUDE.md: %w", err)
-	}
-
 	// 2. Extract current meta-prompt section
-	currentContent, sourcePath, err := m.extractCurrentMetaPrompt(string(claudeContent))
+	currentContent, sourcePath, err := m.extractCurrentMetaPrompt()
 	if err != nil {
 		return false, err
 	}
 
 	// 3. Read library prompt
-	promptFullPath := filepath.Join(m.workingDir, m.libraryPath, "prompts", sourcePath)
+	promptFullPath := filepath.Join(m.promptsDir(), sourcePath)
 	if !fileExists(promptFullPath) {
 		// Library file missing - definitely out of sync
 		return false, nil
 	}
 
-	libraryContent, err := os.ReadFile(promptFullPath)
+	libraryContent, err := prompts.ExpandFile(promptFullPath, prompts.DirLookup(m.promptsDir()))
 	if err != nil {
 		return false, nil
 	}
 
 	// 4. Normalize and compare
 	currentNorm := normalizeWhitespace(currentContent)
-	libraryNorm := normalizeWhitespace(string(libraryContent))
+	libraryNorm := normalizeWhitespace(libraryContent)
 
 	return currentNorm == libraryNorm, nil
 }
@@ -171,12 +155,7 @@ func (m *MetaPromptInjectorImpl) GetCurrentMetaPrompt() (string, error) {
 		return "", fmt.Errorf("CLAUDE.md not found")
 	}
 
-	claudeContent, err := os.ReadFile(claudeFullPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read CLAUDE.md: %w", err)
-	}
-
-	_, sourcePath, err := m.extractCurrentMetaPrompt(string(claudeContent))
+	_, sourcePath, err := m.extractCurrentMetaPrompt()
 	if err != nil {
 		return "", err
 	}
@@ -184,106 +163,58 @@ func (m *MetaPromptInjectorImpl) GetCurrentMetaPrompt() (string, error) {
 	return sourcePath, nil
 }
 
-// removeMetaPromptSection removes the meta-prompt section from content
-func (m *MetaPromptInjectorImpl) removeMetaPromptSection(content string) string {
-	// Same algorithm as persona system (proven reliable)
-	startIdx := strings.Index(content, MetaPromptStartMarker)
-	if startIdx == -1 {
-		return content // No section found
-	}
+// promptsDir is the library's prompts directory, which meta-prompts and
+// their includes are read from
+func (m *MetaPromptInjectorImpl) promptsDir() string {
+	return filepath.Join(m.workingDir, m.libraryPath, "prompts")
+}
 
-	endIdx := strings.Index(content[startIdx:], MetaPromptEndMarker)
-	if endIdx == -1 {
-		// Malformed section - remove from start marker to end
-		return strings.TrimSpace(content[:startIdx])
+// loadCLAUDEFile reads the target file as sections, starting from the
+// target's header when the file does not exist
+func (m *MetaPromptInjectorImpl) loadCLAUDEFile() (*sections.Document, error) {
+	claudeFullPath := filepath.Join(m.workingDir, m.claudeFilePath)
+	content := targets.Header(m.claudeFilePath)
+	if fileExists(claudeFullPath) {
+		existing, err := os.ReadFile(claudeFullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", m.claudeFilePath, err)
+		}
+		content = string(existing)
 	}
-
-	// Calculate absolute end index
-	endIdx = startIdx + endIdx + len(MetaPromptEndMarker)
-
-	// Remove the section
-	before := strings.TrimRight(content[:startIdx], " \t\n")
-	after := strings.TrimLeft(content[endIdx:], " \t\n")
-
-	if before != "" && after != "" {
-		return before + "\n\n" + after
-	} else if before != "" {
-		return before
-	} else if after != "" {
-		return after
+	doc, err := sections.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.claudeFilePath, err)
 	}
-	return ""
-}
-
-// buildMetaPromptSection creates the meta-prompt section content
-func (m *MetaPromptInjectorImpl) buildMetaPromptSection(promptContent, sourcePath string) string {
-	var sections []string
-
-	sections = append(sections, MetaPromptStartMarker)
-	sections = append(sections, fmt.Sprintf("<!-- Source: %s -->", sourcePath))
-	sections = append(sections, promptContent)
-	sections = append(sections, MetaPromptEndMarker)
-
-	return strings.Join(sections, "\n")
+	return doc, nil
 }
 
 // extractCurrentMetaPrompt extracts the meta-prompt content and source path from CLAUDE.md
-func (m *MetaPromptInjectorImpl) extractCurrentMetaPrompt(content string) (string, string, error) {
-	// Find markers
-	startIdx := strings.Index(content, MetaPromptStartMarker)
-	if startIdx == -1 {
-		return "", "", fmt.Errorf("meta-prompt section not found")
+func (m *MetaPromptInjectorImpl) extractCurrentMetaPrompt() (string, string, error) {
+	doc, err := m.loadCLAUDEFile()
+	if err != nil {
+		return "", "", err
 	}
-
-	endIdx := strings.Index(content[startIdx:], MetaPromptEndMarker)
-	if endIdx == -1 {
-		return "", "", fmt.Errorf("malformed meta-prompt section (missing end marker)")
+	section, found := doc.Get(MetaPromptSection)
+	if !found {
+		return "", "", fmt.Errorf("meta-prompt section not found")
 	}
-
-	// Extract section
-	endIdx = startIdx + endIdx
-	section := content[startIdx:endIdx]
-
-	// Extract source path from comment
-	sourcePattern := `<!-- Source: (.+) -->`
-	re := regexp.MustCompile(sourcePattern)
-	matches := re.FindStringSubmatch(section)
-	if len(matches) < 2 {
+	if section.Source == "" {
 		return "", "", fmt.Errorf("source path not found in meta-prompt section")
 	}
-	sourcePath := strings.TrimSpace(matches[1])
-
-	// Extract content (between source comment and end marker)
-	// Find the source comment specifically (not the start marker's -->)
-	sourceComment := fmt.Sprintf("<!-- Source: %s -->", sourcePath)
-	sourceCommentIdx := strings.Index(section, sourceComment)
-	if sourceCommentIdx == -1 {
-		return "", "", fmt.Errorf("source comment not found in section")
-	}
-	contentStart := sourceCommentIdx + len(sourceComment)
-	promptContent := strings.TrimSpace(section[contentStart:])
-
-	return promptContent, sourcePath, nil
+	return section.Content, section.Source, nil
 }
 
-// saveCLAUDEFile saves content to CLAUDE.md with proper formatting
+// saveCLAUDEFile saves content to CLAUDE.md. Lines are written as they
+// are, since trimming them would change the checksums of the sections.
 func (m *MetaPromptInjectorImpl) saveCLAUDEFile(content string) error {
-	// Clean up trailing whitespace but preserve structure
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		lines[i] = strings.TrimRight(line, " \t")
-	}
-
-	// Remove trailing empty lines
-	for len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
-	}
-
-	cleanContent := strings.Join(lines, "\n")
+	cleanContent := strings.TrimRight(content, "\n")
 
 	claudeFullPath := filepath.Join(m.workingDir, m.claudeFilePath)
-	if err := os.WriteFile(claudeFullPath, []byte(cleanContent), 0644); err != nil {
-		return fmt.Errorf("failed to write CLAUDE.md: %w", err)
+	if err := os.MkdirAll(filepath.Dir(claudeFullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", m.claudeFilePath, err)
+	}
+	if err := atomicfile.WriteFile(claudeFullPath, []byte(cleanContent), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", m.claudeFilePath, err)
 	}
 
 	return nil
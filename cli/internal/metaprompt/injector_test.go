@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/easel/ddx/internal/sections"
 )
 
 // TestInjectMetaPrompt tests meta-prompt injection
@@ -23,7 +25,7 @@ func TestInjectMetaPrompt(t *testing.T) {
 			promptPath:    "claude/system-prompts/test.md",
 			promptContent: "# Test Prompt\nContent here",
 			expectError:   false,
-			expectContent: "<!-- DDX-META-PROMPT:START -->",
+			expectContent: `<!-- DDX:START name="meta-prompt" source="claude/system-prompts/test.md"`,
 		},
 		{
 			name:          "inject into existing file",
@@ -130,12 +132,16 @@ func TestInjectMetaPrompt(t *testing.T) {
 			// Verify markers are present when successful
 			if !tt.expectError {
 				content, _ := os.ReadFile(claudePath)
-				if !strings.Contains(string(content), MetaPromptStartMarker) {
+				start, end := sections.Markers(MetaPromptSection)
+				if !strings.Contains(string(content), start) {
 					t.Errorf("Missing start marker in CLAUDE.md")
 				}
-				if !strings.Contains(string(content), MetaPromptEndMarker) {
+				if !strings.Contains(string(content), end) {
 					t.Errorf("Missing end marker in CLAUDE.md")
 				}
+				if strings.Contains(string(content), "DDX-META-PROMPT") {
+					t.Errorf("Legacy markers left in CLAUDE.md:\n%s", content)
+				}
 			}
 		})
 	}
@@ -310,9 +316,9 @@ func TestRemoveMetaPrompt(t *testing.T) {
 			expectError:   false,
 		},
 		{
-			name:          "malformed section (no end marker)",
+			name:          "malformed section (no end marker) is left alone",
 			input:         "Content\n<!-- DDX-META-PROMPT:START -->\nNo end marker",
-			expectContent: "Content",
+			expectContent: "Content\n<!-- DDX-META-PROMPT:START -->\nNo end marker",
 			expectError:   false,
 		},
 		{
@@ -451,17 +457,50 @@ func TestGetCurrentMetaPrompt(t *testing.T) {
 	}
 }
 
-// Helper function to build CLAUDE.md with meta-prompt section
+// TestInjectMetaPromptKeepsPosition tests that a replaced meta-prompt,
+// including one with the legacy markers, stays where it was in the file
+func TestInjectMetaPromptKeepsPosition(t *testing.T) {
+	testDir := t.TempDir()
+	promptFile := filepath.Join(testDir, ".ddx", "library", "prompts", "claude", "new.md")
+	if err := os.MkdirAll(filepath.Dir(promptFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(promptFile, []byte("New prompt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	claudePath := filepath.Join(testDir, "CLAUDE.md")
+	if err := os.WriteFile(claudePath, []byte(buildCLAUDEWithPrompt("Old prompt", "claude/old.md")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	injector := NewMetaPromptInjectorWithPaths("CLAUDE.md", filepath.Join(".ddx", "library"), testDir)
+	if err := injector.InjectMetaPrompt("claude/new.md"); err != nil {
+		t.Fatalf("Failed to inject: %v", err)
+	}
+	content, _ := os.ReadFile(claudePath)
+	start, _ := sections.Markers(MetaPromptSection)
+	prompt := strings.Index(string(content), start)
+	more := strings.Index(string(content), "More content")
+	if prompt == -1 || more < prompt || strings.Contains(string(content), "Old prompt") {
+		t.Errorf("Expected the new meta-prompt before the text that followed the old one, got:\n%s", content)
+	}
+	if path, err := injector.GetCurrentMetaPrompt(); err != nil || path != "claude/new.md" {
+		t.Errorf("Expected source claude/new.md, got %q (%v)", path, err)
+	}
+}
+
+// Helper function to build CLAUDE.md with a meta-prompt section written
+// with the legacy markers
 func buildCLAUDEWithPrompt(promptContent, sourcePath string) string {
 	return strings.Join([]string{
 		"# CLAUDE.md",
 		"",
 		"Project content here",
 		"",
-		MetaPromptStartMarker,
+		"<!-- DDX-META-PROMPT:START -->",
 		"<!-- Source: " + sourcePath + " -->",
 		promptContent,
-		MetaPromptEndMarker,
+		"<!-- DDX-META-PROMPT:END -->",
 		"",
 		"More content",
 	}, "\n")
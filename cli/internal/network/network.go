@@ -0,0 +1,229 @@
+// Package network builds the HTTP clients ddx downloads with, so that
+// self-update, library sync and registry fetches behave the same behind a
+// corporate proxy: proxies come from HTTP_PROXY, HTTPS_PROXY and NO_PROXY,
+// extra certificate authorities from network.ca_file, and timeouts and
+// retries from the rest of the network configuration.
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/easel/ddx/internal/config"
+)
+
+// Defaults used when the configuration does not say otherwise
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 500 * time.Millisecond
+)
+
+// Settings configure the HTTP clients and git
+type Settings struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// Timeout bounds connecting and waiting for response headers; large
+	// downloads may take longer to transfer
+	Timeout time.Duration
+	// TimeoutSet records that Timeout was configured rather than the
+	// default, for operations whose own default differs
+	TimeoutSet bool
+	// Retries is how often failed idempotent requests are retried
+	Retries int
+	// Backoff is the wait before the first retry; it doubles every retry
+	Backoff time.Duration
+	// Offline skips network checks and the background update check
+	Offline bool
+
+	// proxy selects the proxy of a request; nil uses the environment
+	proxy func(*http.Request) (*url.URL, error)
+}
+
+var (
+	mu      sync.RWMutex
+	current = defaults()
+)
+
+// defaults returns the settings used without configuration
+func defaults() Settings {
+	return Settings{Timeout: DefaultTimeout, Retries: DefaultRetries, Backoff: DefaultBackoff}
+}
+
+// FromConfig reads the network section of a configuration. DDX_OFFLINE
+// turns on offline mode whatever the configuration says.
+func FromConfig(cfg *config.NewConfig) (Settings, error) {
+	s := defaults()
+	s.Offline = os.Getenv("DDX_OFFLINE") != ""
+	if cfg == nil || cfg.Network == nil {
+		return s, nil
+	}
+	s.CAFile = cfg.Network.CAFile
+	s.Offline = s.Offline || (cfg.Network.Offline != nil && *cfg.Network.Offline)
+	if cfg.Network.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Network.Timeout)
+		if err != nil || timeout <= 0 {
+			return s, fmt.Errorf("invalid network.timeout '%s': use a duration such as 30s", cfg.Network.Timeout)
+		}
+		s.Timeout = timeout
+		s.TimeoutSet = true
+	}
+	if cfg.Network.Retries != nil {
+		if *cfg.Network.Retries < 0 {
+			return s, fmt.Errorf("invalid network.retries %d: must not be negative", *cfg.Network.Retries)
+		}
+		s.Retries = *cfg.Network.Retries
+	}
+	if cfg.Network.Backoff != "" {
+		backoff, err := time.ParseDuration(cfg.Network.Backoff)
+		if err != nil || backoff < 0 {
+			return s, fmt.Errorf("invalid network.backoff '%s': use a duration such as 500ms", cfg.Network.Backoff)
+		}
+		s.Backoff = backoff
+	}
+	return s, nil
+}
+
+// Configure makes s the settings of Client and points git at the CA
+// bundle, unless GIT_SSL_CAINFO is already set
+func Configure(s Settings) error {
+	if s.CAFile != "" {
+		if _, err := certPool(s.CAFile); err != nil {
+			return err
+		}
+		if os.Getenv("GIT_SSL_CAINFO") == "" {
+			if err := os.Setenv("GIT_SSL_CAINFO", s.CAFile); err != nil {
+				return fmt.Errorf("failed to configure git certificates: %w", err)
+			}
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = s
+	return nil
+}
+
+// Current returns the configured settings
+func Current() Settings {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Client returns a client for the configured settings
+func Client() *http.Client {
+	client, err := NewClient(Current())
+	if err != nil {
+		// Configure has already checked the CA bundle
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return client
+}
+
+// NewClient returns a client that uses the proxy from the environment,
+// trusts the CA bundle and retries idempotent requests
+func NewClient(s Settings) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = s.proxy
+	if transport.Proxy == nil {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	if s.CAFile != "" {
+		pool, err := certPool(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{Transport: &retryTransport{base: transport, retries: s.Retries, backoff: s.Backoff}}, nil
+}
+
+// certPool returns the system roots with the certificates of caFile added
+func certPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network.ca_file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("network.ca_file %s contains no PEM certificates", caFile)
+	}
+	return pool, nil
+}
+
+// retryTransport retries requests without a body that fail to connect or
+// are answered with a rate limit or server error
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := req.Body == nil || req.Body == http.NoBody
+	wait := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if !retryable || attempt >= t.retries || !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// shouldRetry reports whether a failure may be transient. Proxy and
+// certificate errors are not: they fail the same way every time.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		var unknownAuthority x509.UnknownAuthorityError
+		return !errors.As(err, &certErr) && !errors.As(err, &unknownAuthority)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// ProxyFor returns the proxy requests to rawURL go through, or "" when
+// they are made directly
+func ProxyFor(rawURL string) string {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return ""
+	}
+	proxy, err := http.ProxyFromEnvironment(req)
+	if err != nil || proxy == nil {
+		return ""
+	}
+	// Never show proxy credentials
+	proxy.User = nil
+	return proxy.String()
+}
@@ -0,0 +1,94 @@
+// Package onboarding records a user's progress through the first-run guide
+// of 'ddx quickstart', so the welcome is shown once and finished steps stay
+// finished.
+package onboarding
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// FileName is the progress file, kept in the user's data directory
+const FileName = "onboarding.json"
+
+// Progress is what a user has seen and done of the guide
+type Progress struct {
+	// Welcomed is when the first-run welcome was shown
+	Welcomed *time.Time `json:"welcomed,omitempty"`
+	// Completed maps step IDs to when they were first found done
+	Completed map[string]time.Time `json:"completed,omitempty"`
+
+	path string
+}
+
+// Path returns the progress file in the user's data directory
+func Path() (string, error) {
+	return userdirs.Path(userdirs.Data, FileName)
+}
+
+// Load reads the progress in file; a missing file has none
+func Load(file string) (*Progress, error) {
+	p := &Progress{path: file, Completed: map[string]time.Time{}}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read onboarding progress: %w", err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if p.Completed == nil {
+		p.Completed = map[string]time.Time{}
+	}
+	return p, nil
+}
+
+// Done reports whether a step was completed
+func (p *Progress) Done(step string) bool {
+	_, ok := p.Completed[step]
+	return ok
+}
+
+// Complete records a step as done at now, reporting whether it was new
+func (p *Progress) Complete(step string, now time.Time) bool {
+	if p.Done(step) {
+		return false
+	}
+	p.Completed[step] = now.UTC()
+	return true
+}
+
+// Welcome records that the first-run welcome was shown at now
+func (p *Progress) Welcome(now time.Time) {
+	at := now.UTC()
+	p.Welcomed = &at
+}
+
+// Save writes the progress back to its file
+func (p *Progress) Save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("failed to save onboarding progress: %w", err)
+	}
+	if err := atomicfile.WriteFile(p.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save onboarding progress: %w", err)
+	}
+	return nil
+}
+
+// Reset forgets all progress, so the welcome is shown again
+func (p *Progress) Reset() {
+	p.Welcomed = nil
+	p.Completed = map[string]time.Time{}
+}
@@ -0,0 +1,38 @@
+package onboarding
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".ddx", FileName)
+	p, err := Load(file)
+	require.NoError(t, err)
+	assert.Nil(t, p.Welcomed)
+	assert.False(t, p.Done("init"))
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	p.Welcome(now)
+	assert.True(t, p.Complete("init", now))
+	assert.False(t, p.Complete("init", now.Add(time.Hour)), "a step is completed once")
+	require.NoError(t, p.Save())
+
+	p, err = Load(file)
+	require.NoError(t, err)
+	require.NotNil(t, p.Welcomed)
+	assert.True(t, p.Welcomed.Equal(now))
+	assert.True(t, p.Done("init"))
+	assert.True(t, p.Completed["init"].Equal(now))
+
+	p.Reset()
+	require.NoError(t, p.Save())
+	p, err = Load(file)
+	require.NoError(t, err)
+	assert.Nil(t, p.Welcomed)
+	assert.False(t, p.Done("init"))
+}
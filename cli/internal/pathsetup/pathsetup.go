@@ -0,0 +1,216 @@
+// Package pathsetup puts the directory ddx is installed in on the user's
+// PATH. On macOS and Linux the shell profile is edited; on Windows the user
+// PATH is changed in the registry and existing PowerShell profiles are
+// updated for sessions that do not reread the environment.
+package pathsetup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Marker ends every line ddx adds to a profile, so the line is recognized
+// when setup runs again
+const Marker = "# Added by ddx"
+
+// Change is one place setup added the directory to
+type Change struct {
+	// Target is the profile file or registry key that was changed
+	Target string
+	// Description says what was done, for reporting
+	Description string
+}
+
+// BinaryName returns the name of the ddx executable on the platform
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "ddx.exe"
+	}
+	return "ddx"
+}
+
+// DefaultInstallDir returns the per-user directory ddx is installed in:
+// %USERPROFILE%\bin on Windows and ~/.local/bin elsewhere
+func DefaultInstallDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "bin"), nil
+	}
+	return filepath.Join(home, ".local", "bin"), nil
+}
+
+// OnPath reports whether dir is one of the entries of a PATH value. On
+// Windows entries compare case-insensitively and environment references
+// such as %USERPROFILE% are expanded.
+func OnPath(pathList, dir string) bool {
+	return onPath(pathList, dir, runtime.GOOS == "windows")
+}
+
+func onPath(pathList, dir string, windows bool) bool {
+	separator := string(os.PathListSeparator)
+	if windows {
+		separator = ";"
+	}
+	want := normalizeDir(dir, windows)
+	for _, entry := range strings.Split(pathList, separator) {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		if windows {
+			entry = expandWindowsEnv(entry)
+		}
+		if normalizeDir(entry, windows) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDir prepares a directory for comparison
+func normalizeDir(dir string, windows bool) string {
+	dir = strings.Trim(dir, `"`)
+	if windows {
+		dir = strings.ToLower(strings.ReplaceAll(dir, "/", `\`))
+		return strings.TrimRight(dir, `\`)
+	}
+	if dir != "/" {
+		dir = strings.TrimRight(dir, "/")
+	}
+	return dir
+}
+
+// expandWindowsEnv expands %NAME% references the way the registry's
+// REG_EXPAND_SZ values are expanded
+func expandWindowsEnv(value string) string {
+	parts := strings.Split(value, "%")
+	if len(parts) < 3 {
+		return value
+	}
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if i%2 == 1 && i < len(parts)-1 {
+			if v, ok := os.LookupEnv(parts[i]); ok {
+				b.WriteString(v)
+				continue
+			}
+			b.WriteString("%" + parts[i] + "%")
+			continue
+		}
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// prependPath returns a Windows PATH value with dir first, and whether it
+// had to be added
+func prependPath(pathList, dir string) (string, bool) {
+	if onPath(pathList, dir, true) {
+		return pathList, false
+	}
+	pathList = strings.Trim(pathList, ";")
+	if pathList == "" {
+		return dir, true
+	}
+	return dir + ";" + pathList, true
+}
+
+// Options adjust Setup
+type Options struct {
+	// Shell overrides the shell detected from $SHELL on macOS and Linux:
+	// bash, zsh, fish, sh or pwsh
+	Shell string
+	// Home overrides the user's home directory
+	Home string
+}
+
+// Setup adds dir to the user's PATH persistently. It is idempotent: places
+// that already list dir are left alone, and no changes are returned when
+// nothing needed doing.
+func Setup(dir string, opts Options) ([]Change, error) {
+	home := opts.Home
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return setupWindows(home, dir)
+	}
+
+	shell := opts.Shell
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+	profile, line := shellProfile(home, shell, runtime.GOOS, dir)
+	changed, err := appendProfileLine(profile, line, dir)
+	if err != nil || !changed {
+		return nil, err
+	}
+	return []Change{{Target: profile, Description: "added to PATH for new " + shellName(shell) + " sessions"}}, nil
+}
+
+// setupWindows adds dir to the user PATH in the registry and to the
+// PowerShell profiles that exist
+func setupWindows(home, dir string) ([]Change, error) {
+	var changes []Change
+	current, err := readUserPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the user PATH: %w", err)
+	}
+	if updated, added := prependPath(current, dir); added {
+		if err := writeUserPath(updated); err != nil {
+			return nil, fmt.Errorf("failed to update the user PATH: %w", err)
+		}
+		changes = append(changes, Change{Target: `HKCU\Environment\Path`, Description: "added to the user PATH for new programs"})
+	}
+
+	line := powerShellLine(dir, true)
+	for _, profile := range powerShellProfiles(home, "windows") {
+		if _, err := os.Stat(profile); err != nil {
+			continue
+		}
+		changed, err := appendProfileLine(profile, line, dir)
+		if err != nil {
+			return changes, err
+		}
+		if changed {
+			changes = append(changes, Change{Target: profile, Description: "added to PATH for new PowerShell sessions"})
+		}
+	}
+	return changes, nil
+}
+
+// SameExecutable reports whether two paths name the same program. Symlinks
+// are resolved, and on Windows names compare case-insensitively with or
+// without the .exe extension.
+func SameExecutable(a, b string) bool {
+	return sameExecutable(resolve(a), resolve(b), runtime.GOOS == "windows")
+}
+
+func sameExecutable(a, b string, windows bool) bool {
+	if windows {
+		a, b = normalizeDir(a, true), normalizeDir(b, true)
+		return strings.TrimSuffix(a, ".exe") == strings.TrimSuffix(b, ".exe")
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// resolve returns the absolute path with symlinks resolved, or path itself
+// when it cannot be resolved
+func resolve(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
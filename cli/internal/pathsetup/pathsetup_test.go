@@ -0,0 +1,96 @@
+package pathsetup
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnPath(t *testing.T) {
+	assert.True(t, onPath("/usr/bin:/home/me/.local/bin/", "/home/me/.local/bin", false))
+	assert.False(t, onPath("/usr/bin:/home/me/.local/binx", "/home/me/.local/bin", false))
+	assert.False(t, onPath("/usr/bin:/Home/me/bin", "/home/me/bin", false))
+
+	assert.True(t, onPath(`C:\Windows;c:\users\me\BIN\`, `C:\Users\me\bin`, true))
+	assert.True(t, onPath(`C:\Windows;"C:/Users/me/bin"`, `C:\Users\me\bin`, true))
+
+	t.Setenv("DDX_TEST_PROFILE", `C:\Users\me`)
+	assert.True(t, onPath(`%SystemRoot%;%DDX_TEST_PROFILE%\bin`, `C:\Users\me\bin`, true))
+}
+
+func TestPrependPath(t *testing.T) {
+	value, added := prependPath(`%USERPROFILE%\.cargo\bin;C:\Tools;`, `C:\Users\me\bin`)
+	assert.True(t, added)
+	assert.Equal(t, `C:\Users\me\bin;%USERPROFILE%\.cargo\bin;C:\Tools`, value)
+
+	value, added = prependPath(value, `c:\users\me\bin`)
+	assert.False(t, added)
+	assert.True(t, strings.HasPrefix(value, `C:\Users\me\bin;`))
+
+	value, added = prependPath("", `C:\Users\me\bin`)
+	assert.True(t, added)
+	assert.Equal(t, `C:\Users\me\bin`, value)
+}
+
+func TestSameExecutable(t *testing.T) {
+	assert.True(t, sameExecutable(`C:\Users\me\bin\ddx.exe`, `c:\users\me\bin\DDX`, true))
+	assert.False(t, sameExecutable(`C:\Users\me\bin\ddx.exe`, `C:\Tools\ddx.exe`, true))
+	assert.True(t, sameExecutable("/usr/local/bin/../bin/ddx", "/usr/local/bin/ddx", false))
+	assert.False(t, sameExecutable("/usr/local/bin/ddx", "/usr/local/bin/DDX", false))
+}
+
+func TestShellProfile(t *testing.T) {
+	home := "/home/me"
+	tests := []struct {
+		shell, goos, profile, line string
+	}{
+		{"zsh", "linux", ".zshrc", `export PATH="/opt/ddx:$PATH"`},
+		{"bash", "linux", ".bashrc", `export PATH="/opt/ddx:$PATH"`},
+		{"bash", "darwin", ".bash_profile", `export PATH="/opt/ddx:$PATH"`},
+		{"fish", "linux", filepath.Join(".config", "fish", "conf.d", "ddx.fish"), `fish_add_path "/opt/ddx"`},
+		{"pwsh", "linux", filepath.Join(".config", "powershell", "Microsoft.PowerShell_profile.ps1"), `$env:PATH = '/opt/ddx:' + $env:PATH`},
+		{"", "linux", ".profile", `export PATH="/opt/ddx:$PATH"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell+"/"+tt.goos, func(t *testing.T) {
+			profile, line := shellProfile(home, tt.shell, tt.goos, "/opt/ddx")
+			assert.Equal(t, filepath.Join(home, tt.profile), profile)
+			assert.Equal(t, tt.line+" "+Marker, line)
+		})
+	}
+
+	assert.Equal(t, `$env:PATH = 'C:\it''s\bin;' + $env:PATH `+Marker, powerShellLine(`C:\it's\bin`, true))
+}
+
+func TestSetupIsIdempotent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("profiles are only edited for the shell on macOS and Linux")
+	}
+	home := t.TempDir()
+	profile := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.WriteFile(profile, []byte("alias ll='ls -l'"), 0644))
+
+	changes, err := Setup("/opt/ddx/bin", Options{Shell: "zsh", Home: home})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, profile, changes[0].Target)
+
+	changes, err = Setup("/opt/ddx/bin", Options{Shell: "zsh", Home: home})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	data, err := os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, "alias ll='ls -l'\nexport PATH=\"/opt/ddx/bin:$PATH\" "+Marker+"\n", string(data))
+
+	// A missing fish configuration directory is created
+	changes, err = Setup("/opt/ddx/bin", Options{Shell: "fish", Home: home})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.FileExists(t, filepath.Join(home, ".config", "fish", "conf.d", "ddx.fish"))
+}
@@ -0,0 +1,102 @@
+package pathsetup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shellProfile returns the profile file a shell reads for interactive
+// sessions and the line that puts dir on its PATH
+func shellProfile(home, shell, goos, dir string) (string, string) {
+	posix := fmt.Sprintf("export PATH=\"%s:$PATH\" %s", dir, Marker)
+	switch shell {
+	case "zsh":
+		return filepath.Join(home, ".zshrc"), posix
+	case "bash":
+		// Terminal on macOS starts login shells, which skip .bashrc
+		if goos == "darwin" {
+			return filepath.Join(home, ".bash_profile"), posix
+		}
+		return filepath.Join(home, ".bashrc"), posix
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "conf.d", "ddx.fish"), fmt.Sprintf("fish_add_path %q %s", dir, Marker)
+	case "pwsh", "powershell":
+		return powerShellProfiles(home, goos)[0], powerShellLine(dir, false)
+	default:
+		return filepath.Join(home, ".profile"), posix
+	}
+}
+
+// shellName names a shell for messages
+func shellName(shell string) string {
+	if shell == "" {
+		return "shell"
+	}
+	return shell
+}
+
+// powerShellProfiles returns the current-user profiles of PowerShell 7 and,
+// on Windows, Windows PowerShell 5
+func powerShellProfiles(home, goos string) []string {
+	if goos != "windows" {
+		return []string{filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")}
+	}
+	documents := filepath.Join(home, "Documents")
+	return []string{
+		filepath.Join(documents, "PowerShell", "Microsoft.PowerShell_profile.ps1"),
+		filepath.Join(documents, "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"),
+	}
+}
+
+// powerShellLine returns the profile line that puts dir first on PATH
+func powerShellLine(dir string, windows bool) string {
+	separator := ":"
+	if windows {
+		separator = ";"
+	}
+	return fmt.Sprintf("$env:PATH = '%s%s' + $env:PATH %s", strings.ReplaceAll(dir, "'", "''"), separator, Marker)
+}
+
+// appendProfileLine adds line to a profile unless a line ddx added before
+// already names dir. Missing profiles and their directories are created.
+func appendProfileLine(profile, line, dir string) (bool, error) {
+	if file, err := os.Open(profile); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			text := scanner.Text()
+			if strings.Contains(text, Marker) && strings.Contains(text, dir) {
+				_ = file.Close()
+				return false, nil
+			}
+		}
+		_ = file.Close()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(profile), err)
+	}
+	file, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", profile, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// Start on a new line when the profile does not end with one
+	prefix := ""
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if f, err := os.Open(profile); err == nil {
+			if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+				prefix = "\n"
+			}
+			_ = f.Close()
+		}
+	}
+	if _, err := file.WriteString(prefix + line + "\n"); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", profile, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package pathsetup
+
+import "errors"
+
+// errNoRegistry is returned where the Windows registry is needed
+var errNoRegistry = errors.New("the user PATH registry value only exists on Windows")
+
+func readUserPath() (string, error) {
+	return "", errNoRegistry
+}
+
+func writeUserPath(string) error {
+	return errNoRegistry
+}
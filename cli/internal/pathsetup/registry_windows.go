@@ -0,0 +1,66 @@
+//go:build windows
+
+package pathsetup
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// environmentKey holds the user's persistent environment variables
+const environmentKey = `Environment`
+
+// readUserPath returns the user PATH as stored, with %NAME% references
+// unexpanded. A user without a PATH value yields an empty string.
+func readUserPath() (string, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, environmentKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = key.Close() }()
+
+	value, _, err := key.GetStringValue("Path")
+	if errors.Is(err, registry.ErrNotExist) {
+		return "", nil
+	}
+	return value, err
+}
+
+// writeUserPath stores the user PATH as an expandable string and tells
+// running programs such as Explorer that the environment changed, so new
+// terminals pick it up without signing out
+func writeUserPath(value string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, environmentKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = key.Close() }()
+
+	if err := key.SetExpandStringValue("Path", value); err != nil {
+		return err
+	}
+	broadcastEnvironmentChange()
+	return nil
+}
+
+// broadcastEnvironmentChange sends WM_SETTINGCHANGE for "Environment" to
+// all top-level windows. Failures are ignored: the value is saved and is
+// read at the next sign-in regardless.
+func broadcastEnvironmentChange() {
+	const (
+		hwndBroadcast   = 0xffff
+		wmSettingChange = 0x001A
+		smtoAbortIfHung = 0x0002
+	)
+	environment, err := windows.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	proc := windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW")
+	var result uintptr
+	_, _, _ = proc.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(environment)),
+		smtoAbortIfHung, 5000, uintptr(unsafe.Pointer(&result)))
+}
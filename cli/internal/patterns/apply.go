@@ -0,0 +1,339 @@
+package patterns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/templates"
+	"github.com/easel/ddx/internal/variables"
+)
+
+// anchorPattern matches an anchor comment line, capturing its indentation,
+// the comment opener before the anchor, the anchor name and the rest
+var anchorPattern = regexp.MustCompile(`^([ \t]*)(.*?)ddx:anchor[ \t]+(\S+)(.*)$`)
+
+// markerPattern matches the comments around an inserted snippet
+var markerPattern = regexp.MustCompile(`ddx:pattern[ \t]+(\S+)[ \t]+(begin|end)\b`)
+
+// ApplyResult lists what applying a pattern changed, as slash-separated
+// paths relative to the project
+type ApplyResult struct {
+	Created []string
+	// Modified files received snippets at their anchors
+	Modified []string
+	// Skipped files already existed and were left unchanged
+	Skipped []string
+}
+
+// RemoveResult lists what removing a pattern changed
+type RemoveResult struct {
+	// Deleted files were created by the pattern and not edited since
+	Deleted []string
+	// Kept files were created by the pattern but have been edited
+	Kept []string
+	// Modified files had the pattern's snippets removed
+	Modified []string
+}
+
+// Apply applies the pattern in dir to the project in workingDir under the
+// given name: files under the pattern's files directory are created with
+// {{name}} placeholders filled, existing files are kept, and each insert
+// is placed below its anchor comment between marker comments. Nothing is
+// written unless every anchor is found. The pattern is recorded in the
+// project's state.
+func Apply(dir, name, workingDir string, values map[string]string) (*ApplyResult, error) {
+	state, err := LoadState(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if state.Find(name) != nil {
+		return nil, fmt.Errorf("pattern '%s' is already applied (remove it first with 'ddx patterns remove %s')", name, name)
+	}
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := (&templates.Manifest{Name: manifest.Name, Variables: manifest.Variables}).Values(values)
+	if err != nil {
+		return nil, err
+	}
+
+	files, skipped, err := renderFiles(filepath.Join(dir, FilesDir), workingDir, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply pattern %s: %w", name, err)
+	}
+	edits, inserted, err := planInserts(dir, name, workingDir, manifest.Inserts, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply pattern %s: %w", name, err)
+	}
+
+	result := &ApplyResult{Skipped: skipped}
+	record := Applied{Name: name, AppliedAt: time.Now().UTC().Truncate(time.Second), Inserts: inserted}
+	for _, file := range files {
+		target := filepath.Join(workingDir, filepath.FromSlash(file.path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", file.path, err)
+		}
+		if err := atomicfile.WriteFile(target, []byte(file.content), file.mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+		result.Created = append(result.Created, file.path)
+		record.Files = append(record.Files, CreatedFile{Path: file.path, Checksum: checksum(file.content)})
+	}
+	for _, edit := range edits {
+		if err := atomicfile.WriteFile(filepath.Join(workingDir, filepath.FromSlash(edit.path)), []byte(edit.content), edit.mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", edit.path, err)
+		}
+		result.Modified = append(result.Modified, edit.path)
+	}
+
+	state.Applied = append(state.Applied, record)
+	if err := state.Save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Remove undoes an applied pattern: its snippets are taken out of the
+// files they were inserted into, and the files it created are deleted
+// unless they have been edited since.
+func Remove(workingDir, name string) (*RemoveResult, error) {
+	state, err := LoadState(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	applied := state.Find(name)
+	if applied == nil {
+		return nil, fmt.Errorf("pattern '%s' is not applied", name)
+	}
+
+	result := &RemoveResult{}
+	for _, file := range applied.Files {
+		path := filepath.Join(workingDir, filepath.FromSlash(file.Path))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if checksum(string(data)) != file.Checksum {
+			result.Kept = append(result.Kept, file.Path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", file.Path, err)
+		}
+		result.Deleted = append(result.Deleted, file.Path)
+	}
+
+	done := make(map[string]bool)
+	for _, insert := range applied.Inserts {
+		if done[insert.File] {
+			continue
+		}
+		done[insert.File] = true
+		path := filepath.Join(workingDir, filepath.FromSlash(insert.File))
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", insert.File, err)
+		}
+		content, removed := removeSnippets(string(data), name)
+		if !removed {
+			continue
+		}
+		if err := atomicfile.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", insert.File, err)
+		}
+		result.Modified = append(result.Modified, insert.File)
+	}
+
+	state.remove(name)
+	if err := state.Save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fileChange is a rendered file waiting to be written
+type fileChange struct {
+	path    string
+	content string
+	mode    fs.FileMode
+}
+
+// renderFiles renders the files under dir for the project, returning the
+// files to create and the paths of those that already exist
+func renderFiles(dir, workingDir string, values map[string]string) ([]fileChange, []string, error) {
+	var files []fileChange
+	var skipped []string
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rendered, _ := variables.Render(filepath.ToSlash(rel), values)
+		if _, err := os.Stat(filepath.Join(workingDir, filepath.FromSlash(rendered))); err == nil {
+			skipped = append(skipped, rendered)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read pattern file %s: %w", rel, err)
+		}
+		content, _ := variables.Render(string(data), values)
+		files = append(files, fileChange{path: rendered, content: content, mode: info.Mode().Perm()})
+		return nil
+	})
+	return files, skipped, err
+}
+
+// planInserts places each snippet in its file, returning the edited files
+// in the order they were first named and the inserts made
+func planInserts(dir, name, workingDir string, inserts []Insert, values map[string]string) ([]fileChange, []InsertedSnippet, error) {
+	var edits []fileChange
+	var inserted []InsertedSnippet
+	index := make(map[string]int)
+	for _, insert := range inserts {
+		file, _ := variables.Render(insert.File, values)
+		file = filepath.ToSlash(filepath.Clean(file))
+		i, ok := index[file]
+		if !ok {
+			path := filepath.Join(workingDir, filepath.FromSlash(file))
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("file %s for anchor '%s' not found", file, insert.Anchor)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			if hasSnippets(string(data), name) {
+				return nil, nil, fmt.Errorf("%s already contains snippets of pattern '%s'", file, name)
+			}
+			i = len(edits)
+			index[file] = i
+			edits = append(edits, fileChange{path: file, content: string(data), mode: info.Mode().Perm()})
+		}
+
+		snippet := insert.Content
+		if insert.Source != "" {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(insert.Source)))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read snippet %s: %w", insert.Source, err)
+			}
+			snippet = string(data)
+		}
+		snippet, _ = variables.Render(snippet, values)
+
+		content, err := insertAtAnchor(edits[i].content, name, insert.Anchor, snippet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+		edits[i].content = content
+		inserted = append(inserted, InsertedSnippet{File: file, Anchor: insert.Anchor})
+	}
+	return edits, inserted, nil
+}
+
+// insertAtAnchor adds snippet below the anchor comment, after snippets
+// inserted there earlier. The snippet takes the anchor's indentation and
+// is wrapped in marker comments written in the anchor's comment style.
+func insertAtAnchor(content, pattern, anchor, snippet string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		m := anchorPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m == nil || m[3] != anchor {
+			continue
+		}
+		indent, opener, closer := m[1], m[2], strings.TrimRight(m[4], " \t")
+		if !strings.HasSuffix(line, "\n") {
+			lines[i] += "\n"
+		}
+
+		at := i + 1
+		for at < len(lines) {
+			marker := markerPattern.FindStringSubmatch(lines[at])
+			if marker == nil || marker[2] != "begin" {
+				break
+			}
+			at = blockEnd(lines, at, marker[1]) + 1
+		}
+
+		var block strings.Builder
+		block.WriteString(indent + opener + "ddx:pattern " + pattern + " begin" + closer + "\n")
+		for _, snippetLine := range strings.SplitAfter(strings.TrimRight(snippet, "\n")+"\n", "\n") {
+			if strings.TrimSpace(snippetLine) != "" {
+				block.WriteString(indent)
+			}
+			block.WriteString(snippetLine)
+		}
+		block.WriteString(indent + opener + "ddx:pattern " + pattern + " end" + closer + "\n")
+
+		return strings.Join(lines[:at], "") + block.String() + strings.Join(lines[at:], ""), nil
+	}
+	return "", fmt.Errorf("anchor '%s' not found (add a 'ddx:anchor %s' comment where the snippet belongs)", anchor, anchor)
+}
+
+// blockEnd returns the index of the end marker of the snippet beginning
+// at start, or the last line when it has none
+func blockEnd(lines []string, start int, pattern string) int {
+	for i := start + 1; i < len(lines); i++ {
+		if m := markerPattern.FindStringSubmatch(lines[i]); m != nil && m[1] == pattern && m[2] == "end" {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// hasSnippets reports whether content holds snippets of the pattern
+func hasSnippets(content, pattern string) bool {
+	for _, m := range markerPattern.FindAllStringSubmatch(content, -1) {
+		if m[1] == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSnippets deletes the pattern's snippets and their markers,
+// reporting whether any were found
+func removeSnippets(content, pattern string) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	var kept []string
+	removed := false
+	for i := 0; i < len(lines); i++ {
+		if m := markerPattern.FindStringSubmatch(lines[i]); m != nil && m[1] == pattern && m[2] == "begin" {
+			i = blockEnd(lines, i, pattern)
+			removed = true
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return strings.Join(kept, ""), removed
+}
+
+// checksum identifies the content of a created file
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
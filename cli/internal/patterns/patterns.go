@@ -0,0 +1,108 @@
+// Package patterns applies library code patterns to a project. A pattern
+// creates new files and inserts snippets into existing files at anchor
+// comments, and is recorded in the project so it can be listed and
+// removed later.
+package patterns
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest at the root of a pattern
+const ManifestFile = "pattern.yml"
+
+// FilesDir holds the files a pattern creates, laid out as in the project
+const FilesDir = "files"
+
+// Manifest describes a pattern from its pattern.yml
+type Manifest struct {
+	Name        string               `yaml:"name"`
+	Description string               `yaml:"description"`
+	Variables   []templates.Variable `yaml:"variables,omitempty"`
+	Inserts     []Insert             `yaml:"inserts,omitempty"`
+}
+
+// Insert is a snippet added to an existing project file below the line
+// marked with the anchor comment 'ddx:anchor <name>'
+type Insert struct {
+	File   string `yaml:"file"`
+	Anchor string `yaml:"anchor"`
+	// Content is the snippet, or Source names a file in the pattern
+	// holding it
+	Content string `yaml:"content,omitempty"`
+	Source  string `yaml:"source,omitempty"`
+}
+
+// LoadManifest reads the manifest of the pattern in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pattern manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pattern manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks that the manifest is complete
+func (m *Manifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("pattern name is required")
+	}
+	for i, insert := range m.Inserts {
+		switch {
+		case insert.File == "" || insert.Anchor == "":
+			return fmt.Errorf("insert %d needs a file and an anchor", i+1)
+		case (insert.Content == "") == (insert.Source == ""):
+			return fmt.Errorf("insert %d needs either content or a source", i+1)
+		}
+	}
+	return nil
+}
+
+// Pattern is a pattern found in a library, named by its directory
+type Pattern struct {
+	Name     string
+	Dir      string
+	Manifest *Manifest
+}
+
+// Available lists the patterns in a library's patterns directory, sorted
+// by name. Directories without a manifest are not patterns.
+func Available(dir string) ([]Pattern, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var found []Pattern
+	for _, entry := range entries {
+		patternDir := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(patternDir, ManifestFile)); err != nil {
+			continue
+		}
+		manifest, err := LoadManifest(patternDir)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, Pattern{Name: entry.Name(), Dir: patternDir, Manifest: manifest})
+	}
+	return found, nil
+}
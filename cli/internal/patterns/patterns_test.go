@@ -0,0 +1,146 @@
+package patterns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files in dir from a map of relative paths
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestApplyAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		ManifestFile: `name: health-check
+variables:
+  - name: route
+    default: /healthz
+inserts:
+  - file: main.go
+    anchor: routes
+    content: |
+      mux.HandleFunc("{{route}}", health.Handler)
+  - file: README.md
+    anchor: endpoints
+    source: snippets/readme.md
+`,
+		"snippets/readme.md":           "- `{{route}}` reports service health\n",
+		"files/health/handler.go":      "package health\n",
+		"files/health/handler_test.go": "package health\n",
+	})
+
+	project := t.TempDir()
+	main := "package main\n\nfunc routes(mux *http.ServeMux) {\n\t// ddx:anchor routes\n\tmux.HandleFunc(\"/\", index)\n}\n"
+	readme := "# Service\n\n<!-- ddx:anchor endpoints -->\n"
+	writeFiles(t, project, map[string]string{
+		"main.go":                main,
+		"README.md":              readme,
+		"health/handler_test.go": "package health_test\n",
+	})
+
+	result, err := Apply(dir, "health-check", project, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"health/handler.go"}, result.Created)
+	assert.Equal(t, []string{"health/handler_test.go"}, result.Skipped)
+	assert.Equal(t, []string{"main.go", "README.md"}, result.Modified)
+
+	assert.Equal(t, `package main
+
+func routes(mux *http.ServeMux) {
+	// ddx:anchor routes
+	// ddx:pattern health-check begin
+	mux.HandleFunc("/healthz", health.Handler)
+	// ddx:pattern health-check end
+	mux.HandleFunc("/", index)
+}
+`, readFile(t, filepath.Join(project, "main.go")))
+	assert.Equal(t, "# Service\n\n<!-- ddx:anchor endpoints -->\n<!-- ddx:pattern health-check begin -->\n- `/healthz` reports service health\n<!-- ddx:pattern health-check end -->\n",
+		readFile(t, filepath.Join(project, "README.md")))
+
+	state, err := LoadState(project)
+	require.NoError(t, err)
+	require.Len(t, state.Applied, 1)
+	assert.Equal(t, "health-check", state.Applied[0].Name)
+	assert.Len(t, state.Applied[0].Inserts, 2)
+
+	_, err = Apply(dir, "health-check", project, nil)
+	assert.ErrorContains(t, err, "already applied")
+
+	removed, err := Remove(project, "health-check")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"health/handler.go"}, removed.Deleted)
+	assert.Equal(t, []string{"main.go", "README.md"}, removed.Modified)
+	assert.Equal(t, main, readFile(t, filepath.Join(project, "main.go")))
+	assert.Equal(t, readme, readFile(t, filepath.Join(project, "README.md")))
+	assert.FileExists(t, filepath.Join(project, "health", "handler_test.go"), "files the pattern did not create are kept")
+
+	state, err = LoadState(project)
+	require.NoError(t, err)
+	assert.Empty(t, state.Applied)
+}
+
+func TestApplyMissingAnchorWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		ManifestFile:       "name: logging\ninserts:\n  - file: main.go\n    anchor: setup\n    content: log.Init()\n",
+		"files/log/log.go": "package log\n",
+	})
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{"main.go": "package main\n"})
+
+	_, err := Apply(dir, "logging", project, nil)
+	assert.ErrorContains(t, err, "anchor 'setup' not found")
+	assert.NoFileExists(t, filepath.Join(project, "log", "log.go"))
+	assert.NoFileExists(t, StatePath(project))
+}
+
+func TestInsertAtAnchorKeepsOrder(t *testing.T) {
+	content := "# ddx:anchor steps\nlast\n"
+	content, err := insertAtAnchor(content, "first", "steps", "one\n")
+	require.NoError(t, err)
+	content, err = insertAtAnchor(content, "second", "steps", "two")
+	require.NoError(t, err)
+	assert.Equal(t, "# ddx:anchor steps\n# ddx:pattern first begin\none\n# ddx:pattern first end\n# ddx:pattern second begin\ntwo\n# ddx:pattern second end\nlast\n", content)
+
+	content, removed := removeSnippets(content, "first")
+	assert.True(t, removed)
+	assert.Equal(t, "# ddx:anchor steps\n# ddx:pattern second begin\ntwo\n# ddx:pattern second end\nlast\n", content)
+}
+
+func TestRemoveKeepsEditedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		ManifestFile:       "name: logging\n",
+		"files/log/log.go": "package log\n",
+	})
+	project := t.TempDir()
+	_, err := Apply(dir, "logging", project, nil)
+	require.NoError(t, err)
+	writeFiles(t, project, map[string]string{"log/log.go": "package log\n\n// edited\n"})
+
+	removed, err := Remove(project, "logging")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log/log.go"}, removed.Kept)
+	assert.FileExists(t, filepath.Join(project, "log", "log.go"))
+
+	_, err = Remove(project, "logging")
+	assert.ErrorContains(t, err, "not applied")
+}
@@ -0,0 +1,107 @@
+package patterns
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// StateFile records the applied patterns in the project's .ddx directory
+const StateFile = "patterns.yml"
+
+// State lists the patterns applied to a project, oldest first
+type State struct {
+	Applied []Applied `yaml:"applied"`
+
+	path string
+}
+
+// Applied records what applying a pattern changed, so it can be removed
+type Applied struct {
+	Name      string    `yaml:"name" json:"name"`
+	AppliedAt time.Time `yaml:"applied_at" json:"applied_at"`
+	// Files were created by the pattern
+	Files []CreatedFile `yaml:"files,omitempty" json:"files,omitempty"`
+	// Inserts were added to existing files
+	Inserts []InsertedSnippet `yaml:"inserts,omitempty" json:"inserts,omitempty"`
+}
+
+// CreatedFile is a project file written by a pattern. The checksum tells
+// whether it was edited since.
+type CreatedFile struct {
+	Path     string `yaml:"path" json:"path"`
+	Checksum string `yaml:"checksum" json:"checksum"`
+}
+
+// InsertedSnippet is a snippet a pattern inserted at an anchor
+type InsertedSnippet struct {
+	File   string `yaml:"file" json:"file"`
+	Anchor string `yaml:"anchor" json:"anchor"`
+}
+
+// StatePath returns the state file for a project
+func StatePath(workingDir string) string {
+	return filepath.Join(workingDir, ".ddx", StateFile)
+}
+
+// LoadState reads the project's applied patterns. A project without a
+// state file has none.
+func LoadState(workingDir string) (*State, error) {
+	state := &State{path: StatePath(workingDir)}
+	data, err := os.ReadFile(state.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read applied patterns: %w", err)
+	}
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", state.path, err)
+	}
+	return state, nil
+}
+
+// Find returns the record of an applied pattern, or nil
+func (s *State) Find(name string) *Applied {
+	for i := range s.Applied {
+		if s.Applied[i].Name == name {
+			return &s.Applied[i]
+		}
+	}
+	return nil
+}
+
+// remove drops the record of a pattern
+func (s *State) remove(name string) {
+	kept := s.Applied[:0]
+	for _, applied := range s.Applied {
+		if applied.Name != name {
+			kept = append(kept, applied)
+		}
+	}
+	s.Applied = kept
+}
+
+// Save writes the state to the project's .ddx directory
+func (s *State) Save() error {
+	var buf bytes.Buffer
+	buf.WriteString("# Patterns applied by 'ddx patterns apply'. Do not edit by hand.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("failed to marshal applied patterns: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create .ddx directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write applied patterns: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/config"
 	"gopkg.in/yaml.v3"
 )
 
@@ -226,8 +228,18 @@ func (b *BindingManagerImpl) loadConfig() (*PersonaConfig, error) {
 		if bindingsMap, ok := bindings.(map[string]interface{}); ok {
 			personaConfig.Bindings = make(map[string]string)
 			for role, persona := range bindingsMap {
-				if personaStr, ok := persona.(string); ok {
-					personaConfig.Bindings[role] = personaStr
+				switch value := persona.(type) {
+				case string:
+					personaConfig.Bindings[role] = value
+				case []interface{}:
+					// A role bound to several personas
+					var names []string
+					for _, name := range value {
+						if nameStr, ok := name.(string); ok {
+							names = append(names, nameStr)
+						}
+					}
+					personaConfig.Bindings[role] = config.JoinPersonas(names)
 				}
 			}
 		}
@@ -270,7 +282,7 @@ func (b *BindingManagerImpl) saveConfig(config map[string]interface{}) error {
 	}
 
 	// Write file
-	if err := os.WriteFile(b.configPath, content, 0644); err != nil {
+	if err := atomicfile.WriteFile(b.configPath, content, 0644); err != nil {
 		return NewPersonaError(ErrorFileOperation,
 			fmt.Sprintf("failed to write config file %s", b.configPath), err)
 	}
@@ -3,9 +3,14 @@ package persona
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/easel/ddx/internal/targets"
 )
 
 // ClaudeInjectorImpl implements the ClaudeInjector interface
@@ -64,32 +69,20 @@ func (c *ClaudeInjectorImpl) InjectMultiple(personas map[string]*Persona) error
 		}
 	}
 
-	// Read existing content or create default if file doesn't exist
-	var content string
-	if fileExists(c.claudeFilePath) {
-		existingContent, err := os.ReadFile(c.claudeFilePath)
-		if err != nil {
-			return NewPersonaError(ErrorFileOperation,
-				fmt.Sprintf("failed to read CLAUDE.md file %s", c.claudeFilePath), err)
-		}
-		content = string(existingContent)
-	} else {
-		content = "# CLAUDE.md\n\nThis file provides guidance to Claude when working with code in this repository."
+	doc, err := c.loadClaudeFile()
+	if err != nil {
+		return err
 	}
 
-	// Remove existing personas section
-	content = c.removePersonasSection(content)
-
-	// If no personas to inject, just save and return
+	// Replace the personas section in place, or remove it when there are
+	// no personas to inject
 	if len(personas) == 0 {
-		return c.saveClaudeFile(content)
+		doc.Remove(PersonasSection)
+	} else {
+		doc.Set(sections.Section{Name: PersonasSection, Order: sections.DefaultOrder, Content: c.buildPersonasSection(personas)})
 	}
 
-	// Add personas section
-	personasSection := c.buildPersonasSection(personas)
-	content = content + "\n\n" + personasSection
-
-	return c.saveClaudeFile(content)
+	return c.saveClaudeFile(doc.String())
 }
 
 // RemovePersonas removes all personas from CLAUDE.md
@@ -98,16 +91,15 @@ func (c *ClaudeInjectorImpl) RemovePersonas() error {
 		return nil // Nothing to remove
 	}
 
-	content, err := os.ReadFile(c.claudeFilePath)
+	doc, err := c.loadClaudeFile()
 	if err != nil {
-		return NewPersonaError(ErrorFileOperation,
-			fmt.Sprintf("failed to read CLAUDE.md file %s", c.claudeFilePath), err)
+		return err
+	}
+	if !doc.Remove(PersonasSection) {
+		return nil
 	}
 
-	// Remove personas section
-	cleanContent := c.removePersonasSection(string(content))
-
-	return c.saveClaudeFile(cleanContent)
+	return c.saveClaudeFile(doc.String())
 }
 
 // GetLoadedPersonas returns the currently loaded personas as role->persona map
@@ -116,60 +108,52 @@ func (c *ClaudeInjectorImpl) GetLoadedPersonas() (map[string]string, error) {
 		return make(map[string]string), nil
 	}
 
-	content, err := os.ReadFile(c.claudeFilePath)
+	section, err := c.personasSection()
 	if err != nil {
-		return nil, NewPersonaError(ErrorFileOperation,
-			fmt.Sprintf("failed to read CLAUDE.md file %s", c.claudeFilePath), err)
+		return nil, err
 	}
 
-	return c.extractRolePersonaPairs(string(content)), nil
+	return c.extractRolePersonaPairs(section), nil
 }
 
-// removePersonasSection removes the personas section from the content
-func (c *ClaudeInjectorImpl) removePersonasSection(content string) string {
-	// Find personas section and remove it
-	startMarker := PersonasStartMarker
-	endMarker := PersonasEndMarker
-
-	// Handle both proper sections and malformed ones
-	startIdx := strings.Index(content, startMarker)
-	if startIdx == -1 {
-		return content // No personas section found
+// loadClaudeFile reads CLAUDE.md as sections, starting from the target's
+// header when the file does not exist
+func (c *ClaudeInjectorImpl) loadClaudeFile() (*sections.Document, error) {
+	content := targets.Header(c.claudeFilePath)
+	if fileExists(c.claudeFilePath) {
+		data, err := os.ReadFile(c.claudeFilePath)
+		if err != nil {
+			return nil, NewPersonaError(ErrorFileOperation,
+				fmt.Sprintf("failed to read CLAUDE.md file %s", c.claudeFilePath), err)
+		}
+		content = string(data)
 	}
-
-	endIdx := strings.Index(content[startIdx:], endMarker)
-	if endIdx == -1 {
-		// Malformed section - remove from start marker to end of file
-		return strings.TrimSpace(content[:startIdx])
+	doc, err := sections.Parse(content)
+	if err != nil {
+		return nil, NewPersonaError(ErrorFileOperation,
+			fmt.Sprintf("failed to parse CLAUDE.md file %s", c.claudeFilePath), err)
 	}
+	return doc, nil
+}
 
-	// Calculate absolute end index
-	endIdx = startIdx + endIdx + len(endMarker)
-
-	// Remove the section
-	before := content[:startIdx]
-	after := content[endIdx:]
-
-	// Clean up whitespace
-	before = strings.TrimRight(before, " \t\n")
-	after = strings.TrimLeft(after, " \t\n")
-
-	if before != "" && after != "" {
-		return before + "\n\n" + after
-	} else if before != "" {
-		return before
-	} else if after != "" {
-		return after
-	} else {
-		return ""
+// personasSection returns the content of the personas section, or an
+// empty string when CLAUDE.md has none
+func (c *ClaudeInjectorImpl) personasSection() (string, error) {
+	if !fileExists(c.claudeFilePath) {
+		return "", nil
 	}
+	doc, err := c.loadClaudeFile()
+	if err != nil {
+		return "", err
+	}
+	section, _ := doc.Get(PersonasSection)
+	return section.Content, nil
 }
 
 // buildPersonasSection creates the personas section content
 func (c *ClaudeInjectorImpl) buildPersonasSection(personas map[string]*Persona) string {
 	var sections []string
 
-	sections = append(sections, PersonasStartMarker)
 	sections = append(sections, PersonasHeader)
 	sections = append(sections, "")
 
@@ -190,30 +174,15 @@ func (c *ClaudeInjectorImpl) buildPersonasSection(personas map[string]*Persona)
 	}
 
 	sections = append(sections, PersonasFooter)
-	sections = append(sections, PersonasEndMarker)
 
 	return strings.Join(sections, "\n")
 }
 
-// extractRolePersonaPairs extracts role->persona mappings from the content
-func (c *ClaudeInjectorImpl) extractRolePersonaPairs(content string) map[string]string {
+// extractRolePersonaPairs extracts role->persona mappings from the
+// personas section
+func (c *ClaudeInjectorImpl) extractRolePersonaPairs(personasSection string) map[string]string {
 	pairs := make(map[string]string)
 
-	// Find personas section
-	startIdx := strings.Index(content, PersonasStartMarker)
-	if startIdx == -1 {
-		return pairs
-	}
-
-	endIdx := strings.Index(content[startIdx:], PersonasEndMarker)
-	if endIdx == -1 {
-		return pairs
-	}
-
-	// Extract personas section
-	endIdx = startIdx + endIdx
-	personasSection := content[startIdx:endIdx]
-
 	// Parse role and persona names using regex
 	// Format: "### Role: persona-name" - must be at start of line
 	personaPattern := regexp.MustCompile(`(?m)^###\s+([^:]+):\s+([^\n]+)`)
@@ -257,22 +226,16 @@ func formatRoleDisplay(role string) string {
 	return strings.Join(formattedWords, " ")
 }
 
-// saveClaudeFile saves content to the CLAUDE.md file
+// saveClaudeFile saves content to the CLAUDE.md file. Lines are written as
+// they are, since trimming them would change the checksums of the sections.
 func (c *ClaudeInjectorImpl) saveClaudeFile(content string) error {
-	// Clean up trailing whitespace but preserve structure
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		lines[i] = strings.TrimRight(line, " \t")
-	}
+	cleanContent := strings.TrimRight(content, "\n")
 
-	// Remove trailing empty lines
-	for len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
+	if err := os.MkdirAll(filepath.Dir(c.claudeFilePath), 0755); err != nil {
+		return NewPersonaError(ErrorFileOperation,
+			fmt.Sprintf("failed to create directory for %s", c.claudeFilePath), err)
 	}
-
-	cleanContent := strings.Join(lines, "\n")
-
-	if err := os.WriteFile(c.claudeFilePath, []byte(cleanContent), 0644); err != nil {
+	if err := atomicfile.WriteFile(c.claudeFilePath, []byte(cleanContent), 0644); err != nil {
 		return NewPersonaError(ErrorFileOperation,
 			fmt.Sprintf("failed to write CLAUDE.md file %s", c.claudeFilePath), err)
 	}
@@ -284,31 +247,11 @@ func (c *ClaudeInjectorImpl) saveClaudeFile(content string) error {
 func (c *ClaudeInjectorImpl) getExistingPersonas() map[string]*Persona {
 	personas := make(map[string]*Persona)
 
-	if !fileExists(c.claudeFilePath) {
+	personasSection, err := c.personasSection()
+	if err != nil || personasSection == "" {
 		return personas
 	}
 
-	content, err := os.ReadFile(c.claudeFilePath)
-	if err != nil {
-		return personas
-	}
-
-	// Find personas section
-	contentStr := string(content)
-	startIdx := strings.Index(contentStr, PersonasStartMarker)
-	if startIdx == -1 {
-		return personas
-	}
-
-	endIdx := strings.Index(contentStr[startIdx:], PersonasEndMarker)
-	if endIdx == -1 {
-		return personas
-	}
-
-	// Extract personas section content
-	endIdx = startIdx + endIdx
-	personasSection := contentStr[startIdx:endIdx]
-
 	// Find persona entries using regex (simpler pattern)
 	lines := strings.Split(personasSection, "\n")
 	var currentRole, currentPersona string
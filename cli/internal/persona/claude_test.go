@@ -3,8 +3,10 @@ package persona
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/easel/ddx/internal/sections"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -198,12 +200,25 @@ When responding, adopt the appropriate persona based on the task.
 				require.NoError(t, readErr)
 
 				actualContent := string(content)
-				assert.Equal(t, tt.expectedContent, actualContent)
+				assert.Equal(t, withPersonasSection(t, tt.expectedContent), actualContent)
 			}
 		})
 	}
 }
 
+// withPersonasSection rewrites the personas block of expected content,
+// written with the legacy markers for readability, as the managed section
+// the injector writes
+func withPersonasSection(t *testing.T, content string) string {
+	t.Helper()
+	doc, err := sections.Parse(content)
+	require.NoError(t, err)
+	section, found := doc.Get(PersonasSection)
+	require.True(t, found)
+	doc.Set(section)
+	return strings.TrimRight(doc.String(), "\n")
+}
+
 // TestClaudeInjector_InjectMultiple tests injecting multiple personas
 func TestClaudeInjector_InjectMultiple(t *testing.T) {
 	// Cannot use t.Parallel() with os.Chdir
@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/userdirs"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,8 +26,7 @@ func NewPersonaLoader(workingDir string) PersonaLoader {
 	var personasDir string
 	if err != nil || cfg.Library == nil || cfg.Library.Path == "" {
 		// Fallback to a reasonable default if there's an error
-		homeDir, _ := os.UserHomeDir()
-		personasDir = filepath.Join(homeDir, ".ddx", "library", "personas")
+		personasDir, _ = userdirs.Path(userdirs.Data, filepath.Join("library", "personas"))
 	} else {
 		personasDir = filepath.Join(cfg.Library.Path, "personas")
 	}
@@ -119,7 +120,7 @@ func (l *PersonaLoaderImpl) ListPersonas() ([]*Persona, error) {
 		persona, err := l.LoadPersona(personaName)
 		if err != nil {
 			// Log warning but continue processing other personas
-			_, _ = fmt.Fprintf(os.Stderr, "Warning: Skipping invalid persona %s: %v\n", entry.Name(), err)
+			slog.Warn("skipping invalid persona", "file", entry.Name(), "error", err)
 			continue
 		}
 
@@ -176,6 +177,16 @@ func (l *PersonaLoaderImpl) FindByTags(tags []string) ([]*Persona, error) {
 	return matchingPersonas, nil
 }
 
+// Parse parses and validates a persona that is not in the library, such
+// as one read from standard input
+func Parse(content []byte) (*Persona, error) {
+	if len(content) > MaxPersonaFileSize {
+		return nil, NewPersonaError(ErrorValidation,
+			fmt.Sprintf("persona is larger than %d bytes", MaxPersonaFileSize), nil)
+	}
+	return parsePersona(content)
+}
+
 // parsePersona parses a persona from markdown content with YAML frontmatter
 func parsePersona(content []byte) (*Persona, error) {
 	// Split frontmatter and content
@@ -160,11 +160,8 @@ const (
 	// ConfigFileName is the name of the DDx configuration file
 	ConfigFileName = ".ddx.yml"
 
-	// PersonasStartMarker marks the beginning of personas section in CLAUDE.md
-	PersonasStartMarker = "<!-- PERSONAS:START -->"
-
-	// PersonasEndMarker marks the end of personas section in CLAUDE.md
-	PersonasEndMarker = "<!-- PERSONAS:END -->"
+	// PersonasSection names the managed section personas are loaded into
+	PersonasSection = "personas"
 
 	// PersonasHeader is the header for the personas section
 	PersonasHeader = "## Active Personas"
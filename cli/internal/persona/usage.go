@@ -0,0 +1,94 @@
+package persona
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/filelock"
+)
+
+// UsageFile records when each persona was loaded, relative to the project
+// root. It stays on the machine; nothing is sent anywhere.
+const UsageFile = ".ddx/persona-usage.json"
+
+// Usage is what is known about a persona's loads into the AI target files
+type Usage struct {
+	LastLoaded time.Time `json:"last_loaded"`
+	Loads      int       `json:"loads"`
+	// Path is the persona file that was loaded, relative to the project
+	Path string `json:"path"`
+	// Hash identifies the content that was loaded; see ContentHash
+	Hash string `json:"hash"`
+}
+
+// UsageLog maps persona names to their usage
+type UsageLog map[string]Usage
+
+// ContentHash returns the hash Usage records for a persona file's content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// UsagePath returns the usage log of a project
+func UsagePath(workingDir string) string {
+	return filepath.Join(workingDir, filepath.FromSlash(UsageFile))
+}
+
+// LoadUsage reads a project's usage log. A missing log is empty.
+func LoadUsage(workingDir string) (UsageLog, error) {
+	data, err := os.ReadFile(UsagePath(workingDir))
+	if os.IsNotExist(err) {
+		return UsageLog{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", UsageFile, err)
+	}
+	log := UsageLog{}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UsageFile, err)
+	}
+	return log, nil
+}
+
+// RecordLoads notes that the personas, keyed by name to the file each was
+// loaded from, were loaded at now
+func RecordLoads(workingDir string, loaded map[string]string, now time.Time) error {
+	path := UsagePath(workingDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(UsageFile), err)
+	}
+	return filelock.With(path, func() error {
+		log, err := LoadUsage(workingDir)
+		if err != nil {
+			// A damaged log is replaced rather than blocking loads
+			log = UsageLog{}
+		}
+		for name, file := range loaded {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			if rel, err := filepath.Rel(workingDir, file); err == nil {
+				file = rel
+			}
+			usage := log[name]
+			usage.LastLoaded = now.UTC()
+			usage.Loads++
+			usage.Path = filepath.ToSlash(file)
+			usage.Hash = ContentHash(content)
+			log[name] = usage
+		}
+		data, err := json.MarshalIndent(log, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", UsageFile, err)
+		}
+		return atomicfile.WriteFile(path, append(data, '\n'), 0644)
+	})
+}
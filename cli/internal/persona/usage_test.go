@@ -0,0 +1,40 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoads(t *testing.T) {
+	dir := t.TempDir()
+	log, err := LoadUsage(dir)
+	require.NoError(t, err)
+	assert.Empty(t, log)
+
+	file := filepath.Join(dir, ".ddx", "library", "personas", "reviewer.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte("# Reviewer\n"), 0644))
+
+	first := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, RecordLoads(dir, map[string]string{"reviewer": file, "gone": filepath.Join(dir, "gone.md")}, first))
+	second := first.Add(time.Hour)
+	require.NoError(t, RecordLoads(dir, map[string]string{"reviewer": file}, second))
+
+	log, err = LoadUsage(dir)
+	require.NoError(t, err)
+	assert.Equal(t, UsageLog{"reviewer": {
+		LastLoaded: second,
+		Loads:      2,
+		Path:       ".ddx/library/personas/reviewer.md",
+		Hash:       ContentHash([]byte("# Reviewer\n")),
+	}}, log, "personas whose file cannot be read are not recorded")
+
+	require.NoError(t, os.WriteFile(UsagePath(dir), []byte("{"), 0644))
+	_, err = LoadUsage(dir)
+	assert.ErrorContains(t, err, "failed to parse")
+}
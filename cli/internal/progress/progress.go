@@ -0,0 +1,117 @@
+// Package progress shows that a long operation is still running: a spinner
+// and a message redrawn in place on a terminal. New returns nil for output
+// that should stay quiet, and a nil Indicator does nothing, so callers need
+// not check for --quiet or a terminal themselves.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	frames   = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	interval = 100 * time.Millisecond
+)
+
+// Indicator draws a spinner and a message on one line of a terminal
+type Indicator struct {
+	w       io.Writer
+	mu      sync.Mutex
+	message string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New returns an indicator writing to w, or nil when it is not enabled
+func New(w io.Writer, enabled bool) *Indicator {
+	if !enabled {
+		return nil
+	}
+	return &Indicator{w: w}
+}
+
+// Start shows message, starting the spinner if it is not running
+func (i *Indicator) Start(message string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.message = message
+	if i.stop != nil {
+		return
+	}
+	i.stop, i.done = make(chan struct{}), make(chan struct{})
+	go i.spin(i.stop, i.done)
+}
+
+// Update replaces the message shown by a running indicator
+func (i *Indicator) Update(message string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.message = message
+}
+
+// Stop stops the spinner and clears its line
+func (i *Indicator) Stop() {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	stop, done := i.stop, i.done
+	i.stop, i.done = nil, nil
+	i.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	_, _ = fmt.Fprint(i.w, "\r\033[K")
+}
+
+// Bytes returns a function that shows the progress of a transfer of
+// total bytes, or of unknown size when total is not positive, after label
+func (i *Indicator) Bytes(label string) func(received, total int64) {
+	return func(received, total int64) {
+		if total > 0 {
+			i.Update(fmt.Sprintf("%s %s / %s", label, size(received), size(total)))
+		} else {
+			i.Update(fmt.Sprintf("%s %s", label, size(received)))
+		}
+	}
+}
+
+func (i *Indicator) spin(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		i.mu.Lock()
+		message := i.message
+		i.mu.Unlock()
+		_, _ = fmt.Fprintf(i.w, "\r\033[K%s %s", frames[frame%len(frames)], message)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// size renders a byte count for display
+func size(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
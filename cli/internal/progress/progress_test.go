@@ -0,0 +1,58 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a buffer the spinner can write to while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestIndicator(t *testing.T) {
+	interval = time.Millisecond
+	t.Cleanup(func() { interval = 100 * time.Millisecond })
+
+	var out syncBuffer
+	i := New(&out, true)
+	i.Start("Downloading")
+	assert.Eventually(t, func() bool { return strings.Contains(out.String(), "Downloading") }, time.Second, time.Millisecond)
+	i.Bytes("Downloading")(1536, 3<<20)
+	assert.Eventually(t, func() bool { return strings.Contains(out.String(), "Downloading 1.5 KB / 3.0 MB") }, time.Second, time.Millisecond)
+	i.Stop()
+	assert.True(t, strings.HasSuffix(out.String(), "\r\033[K"), "the line is cleared")
+
+	written := out.String()
+	i.Stop()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, written, out.String(), "nothing is drawn after Stop")
+}
+
+func TestDisabled(t *testing.T) {
+	var out syncBuffer
+	i := New(&out, false)
+	assert.Nil(t, i)
+	i.Start("Downloading")
+	i.Bytes("Downloading")(1, 2)
+	i.Stop()
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,131 @@
+// Package prompts expands the include directives in library prompts. A
+// prompt includes another with {{> name}}, where name is looked up like any
+// prompt, e.g. {{> common/security-guidelines}}.
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MaxIncludeDepth bounds nesting so that a runaway chain of includes fails
+// instead of exhausting memory
+const MaxIncludeDepth = 16
+
+// includePattern matches {{> name}} and {{>name}} directives
+var includePattern = regexp.MustCompile(`\{\{>\s*([^\s{}]+)\s*\}\}`)
+
+// Lookup returns the file an include name refers to
+type Lookup func(name string) (string, error)
+
+// Include is a prompt pulled in by a directive, with its own includes
+type Include struct {
+	Name     string
+	Path     string
+	Includes []Include
+}
+
+// DirLookup resolves include names against a prompts directory, with or
+// without the .md extension
+func DirLookup(dir string) Lookup {
+	return func(name string) (string, error) {
+		base := filepath.Join(dir, filepath.FromSlash(name))
+		for _, path := range []string{base + ".md", base} {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("prompt not found: %s", name)
+	}
+}
+
+// ExpandFile reads the prompt at path and expands its includes
+func ExpandFile(path string, lookup Lookup) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	expanded, _, err := expand(string(content), []string{path}, lookup)
+	return expanded, err
+}
+
+// Expand replaces every include directive in content, read from source,
+// with the expanded content of the prompt it names. Including a prompt
+// that is already being expanded is an error.
+func Expand(content, source string, lookup Lookup) (string, error) {
+	expanded, _, err := expand(content, []string{source}, lookup)
+	return expanded, err
+}
+
+// Tree returns the prompts included by the prompt at path, recursively
+func Tree(path string, lookup Lookup) ([]Include, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt: %w", err)
+	}
+	_, tree, err := expand(string(content), []string{path}, lookup)
+	return tree, err
+}
+
+// expand expands content, stack holding the files being expanded from the
+// outermost in
+func expand(content string, stack []string, lookup Lookup) (string, []Include, error) {
+	if !includePattern.MatchString(content) {
+		return content, nil, nil
+	}
+	if len(stack) > MaxIncludeDepth {
+		return "", nil, fmt.Errorf("includes nested more than %d deep in %s", MaxIncludeDepth, stack[len(stack)-1])
+	}
+
+	var (
+		tree     []Include
+		firstErr error
+	)
+	expanded := includePattern.ReplaceAllStringFunc(content, func(directive string) string {
+		if firstErr != nil {
+			return directive
+		}
+		name := includePattern.FindStringSubmatch(directive)[1]
+		path, err := lookup(name)
+		if err != nil {
+			firstErr = fmt.Errorf("%s includes %s: %w", stack[len(stack)-1], name, err)
+			return directive
+		}
+		for i, open := range stack {
+			if sameFile(open, path) {
+				chain := append(append([]string{}, stack[i:]...), path)
+				firstErr = fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+				return directive
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			firstErr = fmt.Errorf("failed to read included prompt %s: %w", name, err)
+			return directive
+		}
+		included, children, err := expand(string(data), append(stack, path), lookup)
+		if err != nil {
+			firstErr = err
+			return directive
+		}
+		tree = append(tree, Include{Name: name, Path: path, Includes: children})
+		return strings.TrimRight(included, "\n")
+	})
+	if firstErr != nil {
+		return "", nil, firstErr
+	}
+	return expanded, tree, nil
+}
+
+// sameFile reports whether two paths name the same prompt
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,65 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePrompts creates prompt files below dir
+func writePrompts(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writePrompts(t, dir, map[string]string{
+		"common/security-guidelines.md": "Never log secrets.\n{{> common/footer}}\n",
+		"common/footer.md":              "-- ddx\n",
+		"review.md":                     "# Review\n\n{{> common/security-guidelines}}\n\nCheck {{language}} code.\n",
+	})
+	lookup := DirLookup(dir)
+
+	expanded, err := ExpandFile(filepath.Join(dir, "review.md"), lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "# Review\n\nNever log secrets.\n-- ddx\n\nCheck {{language}} code.\n", expanded)
+
+	tree, err := Tree(filepath.Join(dir, "review.md"), lookup)
+	require.NoError(t, err)
+	require.Len(t, tree, 1)
+	assert.Equal(t, "common/security-guidelines", tree[0].Name)
+	require.Len(t, tree[0].Includes, 1)
+	assert.Equal(t, filepath.Join(dir, "common", "footer.md"), tree[0].Includes[0].Path)
+
+	plain, err := Expand("No includes {{name}}", "inline", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "No includes {{name}}", plain)
+
+	_, err = Expand("{{> missing}}", "inline", lookup)
+	assert.ErrorContains(t, err, "inline includes missing: prompt not found: missing")
+}
+
+func TestExpandCycle(t *testing.T) {
+	dir := t.TempDir()
+	writePrompts(t, dir, map[string]string{
+		"a.md":    "A {{> b}}",
+		"b.md":    "B {{>a}}",
+		"self.md": "{{> self}}",
+	})
+
+	_, err := ExpandFile(filepath.Join(dir, "a.md"), DirLookup(dir))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle")
+	assert.Contains(t, err.Error(), filepath.Join(dir, "a.md")+" -> "+filepath.Join(dir, "b.md")+" -> "+filepath.Join(dir, "a.md"))
+
+	_, err = ExpandFile(filepath.Join(dir, "self.md"), DirLookup(dir))
+	assert.ErrorContains(t, err, "include cycle")
+}
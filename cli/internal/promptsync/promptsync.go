@@ -0,0 +1,106 @@
+// Package promptsync records the prompt files 'ddx prompts sync' writes for
+// AI tools, such as Claude Code's project commands in .claude/commands, so
+// they can be refreshed from the library and removed again.
+package promptsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile lists the synced files, relative to the project root
+const ManifestFile = ".ddx/prompt-sync.json"
+
+// DefaultDir is where Claude Code looks for project slash commands
+const DefaultDir = ".claude/commands"
+
+// Entry is one synced file
+type Entry struct {
+	// Source is the library prompt, or workflow:command, it was made from
+	Source string `json:"source"`
+	// Checksum identifies the content that was written; see Checksum
+	Checksum string `json:"checksum"`
+}
+
+// Manifest maps synced files, relative to the project and slash separated,
+// to their entries
+type Manifest map[string]Entry
+
+// Checksum returns the checksum an Entry records for a file's content
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the manifest of a project
+func Path(workingDir string) string {
+	return filepath.Join(workingDir, filepath.FromSlash(ManifestFile))
+}
+
+// Load reads a project's manifest. A missing manifest is empty.
+func Load(workingDir string) (Manifest, error) {
+	data, err := os.ReadFile(Path(workingDir))
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+	m := Manifest{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	return m, nil
+}
+
+// Encode returns the manifest as it is saved
+func (m Manifest) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", ManifestFile, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Files returns the synced files in sorted order
+func (m Manifest) Files() []string {
+	files := make([]string, 0, len(m))
+	for file := range m {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Frontmatter is the metadata Claude Code reads from a command file
+type Frontmatter struct {
+	Description string `yaml:"description,omitempty"`
+	// ArgumentHint is shown after the command name while typing it
+	ArgumentHint string `yaml:"argument-hint,omitempty"`
+}
+
+// Document returns a command file: the frontmatter, when it has any
+// fields, followed by the prompt
+func Document(meta Frontmatter, body string) ([]byte, error) {
+	var b strings.Builder
+	if meta != (Frontmatter{}) {
+		data, err := yaml.Marshal(meta)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("---\n")
+		b.Write(data)
+		b.WriteString("---\n")
+	}
+	b.WriteString(strings.TrimSpace(body))
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,43 @@
+package promptsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, m)
+
+	m[".claude/commands/review.md"] = Entry{Source: "common/review.md", Checksum: Checksum([]byte("x"))}
+	m[".claude/commands/helix/build-story.md"] = Entry{Source: "helix:build-story"}
+	data, err := m.Encode()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(Path(dir)), 0755))
+	require.NoError(t, os.WriteFile(Path(dir), data, 0644))
+
+	loaded, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, m, loaded)
+	assert.Equal(t, []string{".claude/commands/helix/build-story.md", ".claude/commands/review.md"}, loaded.Files())
+
+	require.NoError(t, os.WriteFile(Path(dir), []byte("{"), 0644))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestDocument(t *testing.T) {
+	doc, err := Document(Frontmatter{Description: "Build: a story", ArgumentHint: "<story-id>"}, "\n# Build\n\nDo it.\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndescription: 'Build: a story'\nargument-hint: <story-id>\n---\n# Build\n\nDo it.\n", string(doc))
+
+	doc, err = Document(Frontmatter{}, "Plain.")
+	require.NoError(t, err)
+	assert.Equal(t, "Plain.\n", string(doc), "no frontmatter without fields")
+}
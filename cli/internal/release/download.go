@@ -0,0 +1,355 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// Releases are signed keylessly with sigstore by the release workflow
+const (
+	SignerIdentity = `^https://github\.com/easel/ddx/\.github/workflows/release\.yml@`
+	SignerIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// ErrNotFound is returned when a release asset does not exist
+var ErrNotFound = errors.New("release asset not found")
+
+// Artifact is a downloaded and verified release archive
+type Artifact struct {
+	Path     string
+	Platform Platform
+	Version  string
+	// Checksum is the SHA256 of the archive, hex encoded
+	Checksum string
+	// Signed reports whether the sigstore signature was verified
+	Signed bool
+}
+
+// Downloader fetches release archives over HTTPS, retrying transient
+// failures with exponential backoff
+type Downloader struct {
+	Client  *http.Client
+	BaseURL string
+	// Attempts is how often a request is tried before giving up
+	Attempts int
+	// Backoff is the wait before the first retry; it doubles every retry
+	Backoff time.Duration
+	// RequireSignature also downloads the archive's sigstore signature and
+	// certificate and checks them with VerifySignature
+	RequireSignature bool
+	VerifySignature  func(ctx context.Context, archive, signature, certificate string) error
+	// Progress, when set, is told how much of the archive has been
+	// received; total is -1 when the server does not say
+	Progress func(received, total int64)
+}
+
+// NewDownloader returns a downloader for the configured release location
+func NewDownloader(client *http.Client) *Downloader {
+	return &Downloader{
+		Client:          client,
+		BaseURL:         BaseURL(),
+		Attempts:        4,
+		Backoff:         time.Second,
+		VerifySignature: CosignVerify,
+	}
+}
+
+// Download fetches the platform's archive of a release into dir and
+// verifies it against the release checksums. A checksum file is written
+// next to the archive so that it can be verified again before installing.
+func (d *Downloader) Download(ctx context.Context, version string, p Platform, dir string) (*Artifact, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = Latest
+	}
+	archive := p.ArchiveName()
+
+	expected, err := d.checksum(ctx, version, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	err = d.fetch(ctx, AssetURL(d.BaseURL, version, archive), func(total int64) (io.Writer, error) {
+		if err := tmp.Truncate(0); err != nil {
+			return nil, err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if d.Progress != nil {
+			d.Progress(0, total)
+			return &progressWriter{w: tmp, total: total, report: d.Progress}, nil
+		}
+		return tmp, nil
+	})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := FileChecksum(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if actual != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archive, expected, actual)
+	}
+
+	artifact := &Artifact{Path: filepath.Join(dir, archive), Platform: p, Version: version, Checksum: actual}
+	if d.RequireSignature {
+		if err := d.verifySignature(ctx, version, archive, tmp.Name()); err != nil {
+			return nil, err
+		}
+		artifact.Signed = true
+	}
+
+	if err := os.Rename(tmp.Name(), artifact.Path); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", archive, err)
+	}
+	sidecar := fmt.Sprintf("%s  %s\n", actual, archive)
+	if err := atomicfile.WriteFile(artifact.Path+".sha256", []byte(sidecar), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write checksum: %w", err)
+	}
+	return artifact, nil
+}
+
+// checksum returns the published SHA256 of an archive, read from the
+// release's checksums file or, for older releases, the archive's own
+// .sha256 file
+func (d *Downloader) checksum(ctx context.Context, version, archive string) (string, error) {
+	for _, name := range []string{ChecksumsFile, archive + ".sha256"} {
+		data, err := d.fetchBytes(ctx, AssetURL(d.BaseURL, version, name))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if sum, ok := ParseChecksums(data)[archive]; ok {
+			return sum, nil
+		}
+	}
+	return "", fmt.Errorf("no published checksum for %s in release %s", archive, version)
+}
+
+// verifySignature downloads the archive's signature and certificate and
+// checks them against the archive at path
+func (d *Downloader) verifySignature(ctx context.Context, version, archive, path string) error {
+	if d.VerifySignature == nil {
+		return fmt.Errorf("no signature verifier configured")
+	}
+	dir, err := os.MkdirTemp("", "ddx-signature-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := map[string]string{}
+	for _, ext := range []string{".sig", ".pem"} {
+		data, err := d.fetchBytes(ctx, AssetURL(d.BaseURL, version, archive+ext))
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("release %s has no signature for %s", version, archive)
+		}
+		if err != nil {
+			return err
+		}
+		files[ext] = filepath.Join(dir, archive+ext)
+		if err := atomicfile.WriteFile(files[ext], data, 0600); err != nil {
+			return fmt.Errorf("failed to save %s%s: %w", archive, ext, err)
+		}
+	}
+	if err := d.VerifySignature(ctx, path, files[".sig"], files[".pem"]); err != nil {
+		return fmt.Errorf("signature verification failed for %s: %w", archive, err)
+	}
+	return nil
+}
+
+// fetchBytes downloads a small asset into memory
+func (d *Downloader) fetchBytes(ctx context.Context, rawURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := d.fetch(ctx, rawURL, func(int64) (io.Writer, error) {
+		buf.Reset()
+		return &buf, nil
+	})
+	return buf.Bytes(), err
+}
+
+// fetch downloads rawURL into the writer returned by reset, which is
+// called with the size of the download, or -1 when unknown, again before
+// every retry. Network errors, rate limiting and
+// server errors are retried; a missing asset returns ErrNotFound.
+func (d *Downloader) fetch(ctx context.Context, rawURL string, reset func(int64) (io.Writer, error)) error {
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme != "https" {
+		return fmt.Errorf("refusing to download %s: only https is allowed", rawURL)
+	}
+	attempts := max(d.Attempts, 1)
+	wait := d.Backoff
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		retry, err := d.get(ctx, rawURL, reset)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt >= attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	if errors.Is(lastErr, ErrNotFound) {
+		return lastErr
+	}
+	return fmt.Errorf("failed to download %s: %w", rawURL, lastErr)
+}
+
+// get makes one attempt at a download and reports whether a failure is
+// worth retrying
+func (d *Downloader) get(ctx context.Context, rawURL string, reset func(int64) (io.Writer, error)) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.Request != nil && resp.Request.URL.Scheme != "https" {
+		return false, fmt.Errorf("redirected to insecure location %s", resp.Request.URL)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, fmt.Errorf("%w: %s", ErrNotFound, rawURL)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("server returned %s", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	w, err := reset(resp.ContentLength)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		// A connection dropped mid-transfer is worth another try
+		return ctx.Err() == nil, err
+	}
+	return false, nil
+}
+
+// progressWriter reports the bytes written through it
+type progressWriter struct {
+	w        io.Writer
+	received int64
+	total    int64
+	report   func(received, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.received += int64(n)
+	p.report(p.received, p.total)
+	return n, err
+}
+
+// ParseChecksums reads sha256sum output into a map of file name to
+// checksum
+func ParseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks files read in binary mode with a leading *
+		name := filepath.Base(strings.TrimPrefix(fields[1], "*"))
+		sums[name] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// FileChecksum returns the hex encoded SHA256 of a file
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyFile checks a downloaded archive against the checksum file
+// written next to it by Download
+func VerifyFile(path string) error {
+	data, err := os.ReadFile(path + ".sha256")
+	if err != nil {
+		return fmt.Errorf("no checksum for %s; download it again with 'ddx download-binary': %w", path, err)
+	}
+	expected, ok := ParseChecksums(data)[filepath.Base(path)]
+	if !ok {
+		return fmt.Errorf("no checksum for %s in %s.sha256", filepath.Base(path), path)
+	}
+	actual, err := FileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(path), expected, actual)
+	}
+	return nil
+}
+
+// CosignVerify checks a keyless sigstore signature with the cosign CLI,
+// requiring the certificate to have been issued to the release workflow
+func CosignVerify(ctx context.Context, archive, signature, certificate string) error {
+	cosign, err := exec.LookPath("cosign")
+	if err != nil {
+		return fmt.Errorf("cosign is required to verify signatures; install it from https://docs.sigstore.dev")
+	}
+	cmd := exec.CommandContext(ctx, cosign, "verify-blob",
+		"--signature", signature,
+		"--certificate", certificate,
+		"--certificate-identity-regexp", SignerIdentity,
+		"--certificate-oidc-issuer", SignerIssuer,
+		archive)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
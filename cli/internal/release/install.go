@@ -0,0 +1,118 @@
+package release
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Install extracts the platform's ddx binary from a release archive into
+// dir and returns its path. The binary is written to a temporary file in
+// dir and renamed over any existing one, so an interrupted install never
+// leaves a partial executable behind.
+func Install(archive string, p Platform, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create install directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".ddx-install-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write to %s: %w", dir, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if strings.HasSuffix(archive, ".zip") {
+		err = extractZip(archive, p.BinaryName(), tmp)
+	} else {
+		err = extractTarGz(archive, p.BinaryName(), tmp)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	target := filepath.Join(dir, p.BinaryName())
+	if p.OS == "windows" {
+		// A running executable cannot be replaced on Windows, but it can be
+		// moved out of the way
+		old := target + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(target, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to replace %s: %w", target, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("failed to install %s: %w", target, err)
+	}
+	return target, nil
+}
+
+// extractTarGz copies the binary out of a gzipped tar archive
+func extractTarGz(archive, binary string, w io.Writer) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in %s", binary, filepath.Base(archive))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binary {
+			if _, err := io.Copy(w, tr); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", binary, err)
+			}
+			return nil
+		}
+	}
+}
+
+// extractZip copies the binary out of a zip archive
+func extractZip(archive, binary string, w io.Writer) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || path.Base(file.Name) != binary {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", binary, err)
+		}
+		defer func() { _ = rc.Close() }()
+		if _, err := io.Copy(w, rc); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", binary, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s not found in %s", binary, filepath.Base(archive))
+}
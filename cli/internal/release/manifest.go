@@ -0,0 +1,300 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// Homepage and license shown by package managers
+const (
+	Homepage    = "https://github.com/easel/ddx"
+	License     = "MIT"
+	Description = "Document-driven development toolkit for sharing prompts, templates and patterns across projects"
+)
+
+// ManifestFormat is a package manager a manifest is generated for
+type ManifestFormat string
+
+// Package manager manifests generated by GenerateManifests
+const (
+	FormatHomebrew   ManifestFormat = "homebrew"
+	FormatScoop      ManifestFormat = "scoop"
+	FormatChocolatey ManifestFormat = "chocolatey"
+)
+
+// ManifestFormats lists every format in generation order
+var ManifestFormats = []ManifestFormat{FormatHomebrew, FormatScoop, FormatChocolatey}
+
+// ParseManifestFormat accepts a format name or the package manager's
+// command name: brew, scoop or choco
+func ParseManifestFormat(s string) (ManifestFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "homebrew", "brew":
+		return FormatHomebrew, nil
+	case "scoop":
+		return FormatScoop, nil
+	case "chocolatey", "choco":
+		return FormatChocolatey, nil
+	}
+	return "", fmt.Errorf("unknown package manager '%s': use homebrew, scoop or chocolatey", s)
+}
+
+// ReleaseInfo is what manifests are generated from: a release version and
+// the checksums of its archives
+type ReleaseInfo struct {
+	// Version is the release tag without the leading v, e.g. 1.2.0
+	Version   string
+	BaseURL   string
+	Checksums map[string]string
+}
+
+// NewReleaseInfo prepares a release for manifest generation. Every
+// platform a release is built for must have a checksum.
+func NewReleaseInfo(version, baseURL string, checksums map[string]string) (*ReleaseInfo, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" || version == Latest {
+		return nil, errors.New("a release version such as v1.2.0 is required")
+	}
+	info := &ReleaseInfo{Version: version, BaseURL: strings.TrimRight(baseURL, "/"), Checksums: checksums}
+	var missing []string
+	for _, p := range Platforms() {
+		if _, ok := checksums[p.ArchiveName()]; !ok {
+			missing = append(missing, p.ArchiveName())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no checksum for %s in release v%s", strings.Join(missing, ", "), version)
+	}
+	return info, nil
+}
+
+// Platforms returns every platform releases are built for, sorted
+func Platforms() []Platform {
+	var platforms []Platform
+	for osName, arches := range supported {
+		for _, arch := range arches {
+			platforms = append(platforms, Platform{OS: osName, Arch: arch})
+		}
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+	return platforms
+}
+
+// FetchChecksums downloads the checksums file of a release
+func (d *Downloader) FetchChecksums(ctx context.Context, version string) (map[string]string, error) {
+	data, err := d.fetchBytes(ctx, AssetURL(d.BaseURL, version, ChecksumsFile))
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("release %s has no %s", version, ChecksumsFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseChecksums(data), nil
+}
+
+// archive is a platform's release archive as manifests reference it
+type archive struct {
+	URL    string
+	SHA256 string
+}
+
+// archive returns the download URL and checksum of a platform's archive
+func (r *ReleaseInfo) archive(osName, arch string) archive {
+	name := Platform{OS: osName, Arch: arch}.ArchiveName()
+	return archive{URL: AssetURL(r.BaseURL, "v"+r.Version, name), SHA256: r.Checksums[name]}
+}
+
+// ManifestFiles renders the files of a package manager's manifest, keyed
+// by their path relative to the output directory
+func (r *ReleaseInfo) ManifestFiles(format ManifestFormat) (map[string][]byte, error) {
+	switch format {
+	case FormatHomebrew:
+		data, err := r.render(homebrewFormula)
+		return map[string][]byte{"homebrew/ddx.rb": data}, err
+	case FormatScoop:
+		data, err := r.scoopManifest()
+		return map[string][]byte{"scoop/ddx.json": data}, err
+	case FormatChocolatey:
+		nuspec, err := r.render(chocolateyNuspec)
+		if err != nil {
+			return nil, err
+		}
+		install, err := r.render(chocolateyInstall)
+		return map[string][]byte{
+			"chocolatey/ddx.nuspec":                  nuspec,
+			"chocolatey/tools/chocolateyinstall.ps1": install,
+		}, err
+	}
+	return nil, fmt.Errorf("unknown package manager '%s'", format)
+}
+
+// GenerateManifests writes the manifests of the formats into dir,
+// replacing those of an earlier release, and returns the paths written
+func (r *ReleaseInfo) GenerateManifests(dir string, formats []ManifestFormat) ([]string, error) {
+	var written []string
+	for _, format := range formats {
+		files, err := r.ManifestFiles(format)
+		if err != nil {
+			return written, fmt.Errorf("failed to render %s manifest: %w", format, err)
+		}
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return written, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+			}
+			if err := atomicfile.WriteFile(path, files[name], 0644); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
+
+// manifestData is what manifest templates are executed with
+type manifestData struct {
+	*ReleaseInfo
+	Description string
+	Homepage    string
+	License     string
+}
+
+// render executes a manifest template with the release
+func (r *ReleaseInfo) render(tmpl *template.Template) ([]byte, error) {
+	var buf bytes.Buffer
+	data := manifestData{ReleaseInfo: r, Description: Description, Homepage: Homepage, License: License}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scoopManifest renders the Scoop manifest. Its checkver and autoupdate
+// sections let Scoop's excavator follow later releases on its own.
+func (r *ReleaseInfo) scoopManifest() ([]byte, error) {
+	windows := r.archive("windows", "amd64")
+	autoupdateURL := strings.Replace(windows.URL, "v"+r.Version, "v$version", 1)
+	manifest := map[string]interface{}{
+		"version":     r.Version,
+		"description": Description,
+		"homepage":    Homepage,
+		"license":     License,
+		"architecture": map[string]interface{}{
+			"64bit": map[string]string{"url": windows.URL, "hash": windows.SHA256},
+		},
+		"bin":      "ddx.exe",
+		"checkver": map[string]string{"github": Homepage},
+		"autoupdate": map[string]interface{}{
+			"architecture": map[string]interface{}{
+				"64bit": map[string]interface{}{
+					"url":  autoupdateURL,
+					"hash": map[string]string{"url": strings.TrimSuffix(autoupdateURL, Platform{OS: "windows", Arch: "amd64"}.ArchiveName()) + ChecksumsFile},
+				},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+var manifestFuncs = template.FuncMap{
+	"archive": func(r *ReleaseInfo, osName, arch string) archive { return r.archive(osName, arch) },
+}
+
+var homebrewFormula = template.Must(template.New("ddx.rb").Funcs(manifestFuncs).Parse(`# Generated by 'ddx release manifest'; do not edit
+class Ddx < Formula
+  desc "{{ .Description }}"
+  homepage "{{ .Homepage }}"
+  version "{{ .Version }}"
+  license "{{ .License }}"
+
+  on_macos do
+    on_arm do
+{{- with archive .ReleaseInfo "darwin" "arm64" }}
+      url "{{ .URL }}"
+      sha256 "{{ .SHA256 }}"
+{{- end }}
+    end
+    on_intel do
+{{- with archive .ReleaseInfo "darwin" "amd64" }}
+      url "{{ .URL }}"
+      sha256 "{{ .SHA256 }}"
+{{- end }}
+    end
+  end
+
+  on_linux do
+    on_arm do
+{{- with archive .ReleaseInfo "linux" "arm64" }}
+      url "{{ .URL }}"
+      sha256 "{{ .SHA256 }}"
+{{- end }}
+    end
+    on_intel do
+{{- with archive .ReleaseInfo "linux" "amd64" }}
+      url "{{ .URL }}"
+      sha256 "{{ .SHA256 }}"
+{{- end }}
+    end
+  end
+
+  def install
+    bin.install "ddx"
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/ddx version")
+  end
+end
+`))
+
+var chocolateyNuspec = template.Must(template.New("ddx.nuspec").Parse(`<?xml version="1.0" encoding="utf-8"?>
+<!-- Generated by 'ddx release manifest'; do not edit -->
+<package xmlns="http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd">
+  <metadata>
+    <id>ddx</id>
+    <version>{{ .Version }}</version>
+    <title>DDx</title>
+    <authors>DDx Contributors</authors>
+    <projectUrl>{{ .Homepage }}</projectUrl>
+    <licenseUrl>{{ .Homepage }}/blob/main/LICENSE</licenseUrl>
+    <requireLicenseAcceptance>false</requireLicenseAcceptance>
+    <releaseNotes>{{ .Homepage }}/releases/tag/v{{ .Version }}</releaseNotes>
+    <description>{{ .Description }}</description>
+    <tags>ddx cli ai prompts templates</tags>
+  </metadata>
+  <files>
+    <file src="tools\**" target="tools" />
+  </files>
+</package>
+`))
+
+var chocolateyInstall = template.Must(template.New("chocolateyinstall.ps1").Funcs(manifestFuncs).Parse(`# Generated by 'ddx release manifest'; do not edit
+$ErrorActionPreference = 'Stop'
+{{- with archive .ReleaseInfo "windows" "amd64" }}
+
+Install-ChocolateyZipPackage -PackageName $env:ChocolateyPackageName ` + "`" + `
+  -Url64bit '{{ .URL }}' ` + "`" + `
+  -Checksum64 '{{ .SHA256 }}' ` + "`" + `
+  -ChecksumType64 'sha256' ` + "`" + `
+  -UnzipLocation "$(Split-Path -Parent $MyInvocation.MyCommand.Definition)"
+{{- end }}
+`))
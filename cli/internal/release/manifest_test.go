@@ -0,0 +1,93 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseChecksums returns checksums for every archive of a release
+func releaseChecksums() map[string]string {
+	sums := map[string]string{}
+	for i, p := range Platforms() {
+		sums[p.ArchiveName()] = string(rune('a'+i)) + "0f3"
+	}
+	return sums
+}
+
+func TestNewReleaseInfo(t *testing.T) {
+	info, err := NewReleaseInfo("v1.2.0", DefaultBaseURL+"/", releaseChecksums())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", info.Version)
+
+	_, err = NewReleaseInfo("latest", DefaultBaseURL, releaseChecksums())
+	assert.Error(t, err)
+
+	sums := releaseChecksums()
+	delete(sums, "ddx-windows-amd64.zip")
+	_, err = NewReleaseInfo("1.2.0", DefaultBaseURL, sums)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ddx-windows-amd64.zip")
+}
+
+func TestManifestFiles(t *testing.T) {
+	sums := releaseChecksums()
+	info, err := NewReleaseInfo("v1.2.0", DefaultBaseURL, sums)
+	require.NoError(t, err)
+
+	files, err := info.ManifestFiles(FormatHomebrew)
+	require.NoError(t, err)
+	formula := string(files["homebrew/ddx.rb"])
+	assert.Contains(t, formula, `version "1.2.0"`)
+	assert.Contains(t, formula, `url "https://github.com/easel/ddx/releases/download/v1.2.0/ddx-darwin-arm64.tar.gz"`)
+	assert.Contains(t, formula, `sha256 "`+sums["ddx-linux-amd64.tar.gz"]+`"`)
+
+	files, err = info.ManifestFiles(FormatScoop)
+	require.NoError(t, err)
+	var scoop struct {
+		Version      string
+		Bin          string
+		Architecture map[string]struct{ URL, Hash string } `json:"architecture"`
+	}
+	require.NoError(t, json.Unmarshal(files["scoop/ddx.json"], &scoop))
+	assert.Equal(t, "1.2.0", scoop.Version)
+	assert.Equal(t, "ddx.exe", scoop.Bin)
+	assert.Equal(t, "https://github.com/easel/ddx/releases/download/v1.2.0/ddx-windows-amd64.zip", scoop.Architecture["64bit"].URL)
+	assert.Equal(t, sums["ddx-windows-amd64.zip"], scoop.Architecture["64bit"].Hash)
+
+	files, err = info.ManifestFiles(FormatChocolatey)
+	require.NoError(t, err)
+	assert.Contains(t, string(files["chocolatey/ddx.nuspec"]), "<version>1.2.0</version>")
+	assert.Contains(t, string(files["chocolatey/tools/chocolateyinstall.ps1"]), "-Checksum64 '"+sums["ddx-windows-amd64.zip"]+"'")
+}
+
+func TestGenerateManifestsReplacesEarlierRelease(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"1.1.0", "1.2.0"} {
+		info, err := NewReleaseInfo(version, DefaultBaseURL, releaseChecksums())
+		require.NoError(t, err)
+		written, err := info.GenerateManifests(dir, ManifestFormats)
+		require.NoError(t, err)
+		assert.Len(t, written, 4)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "homebrew", "ddx.rb"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `version "1.2.0"`)
+	assert.NotContains(t, string(data), "1.1.0")
+}
+
+func TestParseManifestFormat(t *testing.T) {
+	format, err := ParseManifestFormat("brew")
+	require.NoError(t, err)
+	assert.Equal(t, FormatHomebrew, format)
+	format, err = ParseManifestFormat("Choco")
+	require.NoError(t, err)
+	assert.Equal(t, FormatChocolatey, format)
+	_, err = ParseManifestFormat("apt")
+	assert.Error(t, err)
+}
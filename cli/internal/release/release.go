@@ -0,0 +1,121 @@
+// Package release downloads ddx release artifacts, verifies them and
+// installs the binary they contain. Archives are named
+// ddx-<os>-<arch>.tar.gz (.zip on Windows) and are published together with
+// a checksums.sha256 file, as built by the release workflow.
+package release
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/easel/ddx/internal/pathsetup"
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// DefaultBaseURL is where releases are published
+const DefaultBaseURL = "https://github.com/easel/ddx/releases"
+
+// BaseURLEnvVar overrides DefaultBaseURL, e.g. to install from a mirror
+const BaseURLEnvVar = "DDX_RELEASE_URL"
+
+// ChecksumsFile lists the SHA256 checksum of every archive of a release
+const ChecksumsFile = "checksums.sha256"
+
+// Latest selects the newest release
+const Latest = "latest"
+
+// supported lists the platforms releases are built for
+var supported = map[string][]string{
+	"linux":   {"amd64", "arm64"},
+	"darwin":  {"amd64", "arm64"},
+	"windows": {"amd64"},
+}
+
+// Platform is an operating system and CPU architecture pair
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// CurrentPlatform returns the platform ddx is running on
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// ParsePlatform parses "os/arch", accepting the names uname reports for
+// architectures, such as x86_64 and aarch64
+func ParsePlatform(s string) (Platform, error) {
+	osName, arch, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "/")
+	if !ok || osName == "" || arch == "" {
+		return Platform{}, fmt.Errorf("invalid platform '%s': use os/arch, e.g. linux/amd64", s)
+	}
+	switch arch {
+	case "x86_64", "x64":
+		arch = "amd64"
+	case "aarch64":
+		arch = "arm64"
+	}
+	p := Platform{OS: osName, Arch: arch}
+	return p, p.Validate()
+}
+
+// Validate reports whether releases are built for the platform
+func (p Platform) Validate() error {
+	for _, arch := range supported[p.OS] {
+		if arch == p.Arch {
+			return nil
+		}
+	}
+	return fmt.Errorf("no ddx release is built for %s", p)
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// ArchiveName returns the name of the platform's release archive
+func (p Platform) ArchiveName() string {
+	if p.OS == "windows" {
+		return fmt.Sprintf("ddx-%s-%s.zip", p.OS, p.Arch)
+	}
+	return fmt.Sprintf("ddx-%s-%s.tar.gz", p.OS, p.Arch)
+}
+
+// BinaryName returns the name of the ddx executable on the platform
+func (p Platform) BinaryName() string {
+	return pathsetup.BinaryName(p.OS)
+}
+
+// BaseURL returns the release location, honouring BaseURLEnvVar
+func BaseURL() string {
+	if base := os.Getenv(BaseURLEnvVar); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return DefaultBaseURL
+}
+
+// AssetURL returns the download URL of a release asset. The version is a
+// tag such as v1.2.0, or Latest.
+func AssetURL(base, version, asset string) string {
+	if version == "" || version == Latest {
+		return fmt.Sprintf("%s/latest/download/%s", base, url.PathEscape(asset))
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return fmt.Sprintf("%s/download/%s/%s", base, url.PathEscape(version), url.PathEscape(asset))
+}
+
+// DefaultDownloadDir returns where downloaded archives are kept, in the
+// ddx cache directory
+func DefaultDownloadDir() (string, error) {
+	cacheDir, err := userdirs.Dir(userdirs.Cache)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "downloads"), nil
+}
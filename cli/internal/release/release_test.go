@@ -0,0 +1,219 @@
+package release
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tarGz builds a release archive holding a binary with content
+func tarGz(t *testing.T, binary, content string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{"VERSION": "DDx v1.2.0\n", binary: content} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func sum(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestPlatform(t *testing.T) {
+	p, err := ParsePlatform("Linux/x86_64")
+	require.NoError(t, err)
+	assert.Equal(t, Platform{OS: "linux", Arch: "amd64"}, p)
+	assert.Equal(t, "ddx-linux-amd64.tar.gz", p.ArchiveName())
+	assert.Equal(t, "ddx", p.BinaryName())
+
+	windows := Platform{OS: "windows", Arch: "amd64"}
+	assert.Equal(t, "ddx-windows-amd64.zip", windows.ArchiveName())
+	assert.Equal(t, "ddx.exe", windows.BinaryName())
+
+	_, err = ParsePlatform("plan9/mips")
+	assert.Error(t, err)
+	_, err = ParsePlatform("linux")
+	assert.Error(t, err)
+
+	assert.Equal(t, "https://example.com/r/latest/download/a.tar.gz", AssetURL("https://example.com/r", "", "a.tar.gz"))
+	assert.Equal(t, "https://example.com/r/download/v1.2.0/a.tar.gz", AssetURL("https://example.com/r", "1.2.0", "a.tar.gz"))
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := ParseChecksums([]byte("ABC123  ddx-linux-amd64.tar.gz\ndef456 *build/ddx-windows-amd64.zip\n\nnot a checksum line at all\n"))
+	assert.Equal(t, map[string]string{"ddx-linux-amd64.tar.gz": "abc123", "ddx-windows-amd64.zip": "def456"}, sums)
+}
+
+func TestDownloadAndInstall(t *testing.T) {
+	p := Platform{OS: "linux", Arch: "amd64"}
+	archive := tarGz(t, "ddx", "#!/bin/sh\necho ddx\n")
+	checksums := fmt.Sprintf("%s  %s\n", sum(archive), p.ArchiveName())
+
+	var failures atomic.Int32
+	failures.Store(2)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download/v1.2.0/" + ChecksumsFile:
+			_, _ = w.Write([]byte(checksums))
+		case "/download/v1.2.0/" + p.ArchiveName():
+			// The first requests fail to exercise the retries
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d := &Downloader{Client: server.Client(), BaseURL: server.URL, Attempts: 3}
+	var received, total int64
+	d.Progress = func(r, t int64) { received, total = r, t }
+	dir := t.TempDir()
+	artifact, err := d.Download(context.Background(), "v1.2.0", p, dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(archive)), received)
+	assert.Equal(t, int64(len(archive)), total)
+	assert.Equal(t, filepath.Join(dir, p.ArchiveName()), artifact.Path)
+	assert.Equal(t, sum(archive), artifact.Checksum)
+	assert.False(t, artifact.Signed)
+	require.NoError(t, VerifyFile(artifact.Path))
+
+	binDir := filepath.Join(t.TempDir(), "bin")
+	target, err := Install(artifact.Path, p, binDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(binDir, "ddx"), target)
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho ddx\n", string(content))
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	entries, err := os.ReadDir(binDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	// A tampered archive no longer matches its checksum
+	require.NoError(t, os.WriteFile(artifact.Path, []byte("tampered"), 0644))
+	assert.ErrorContains(t, VerifyFile(artifact.Path), "checksum mismatch")
+}
+
+func TestDownloadRejectsBadArchives(t *testing.T) {
+	p := Platform{OS: "darwin", Arch: "arm64"}
+	archive := tarGz(t, "ddx", "binary")
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/latest/download/" + p.ArchiveName() + ".sha256":
+			_, _ = w.Write([]byte(strings.Repeat("0", 64) + "  " + p.ArchiveName() + "\n"))
+		case "/latest/download/" + p.ArchiveName():
+			_, _ = w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d := &Downloader{Client: server.Client(), BaseURL: server.URL, Attempts: 3}
+	dir := t.TempDir()
+	_, err := d.Download(context.Background(), Latest, p, dir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "a rejected archive is not kept")
+	assert.Equal(t, int32(3), requests.Load(), "missing assets are not retried")
+
+	_, err = d.Download(context.Background(), "v9.9.9", p, dir)
+	assert.ErrorContains(t, err, "no published checksum")
+
+	insecure := &Downloader{Client: http.DefaultClient, BaseURL: "http://example.com", Attempts: 1}
+	_, err = insecure.Download(context.Background(), Latest, p, dir)
+	assert.ErrorContains(t, err, "only https is allowed")
+}
+
+func TestDownloadVerifiesSignature(t *testing.T) {
+	p := Platform{OS: "linux", Arch: "arm64"}
+	archive := tarGz(t, "ddx", "binary")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/download/" + ChecksumsFile:
+			_, _ = fmt.Fprintf(w, "%s  %s\n", sum(archive), p.ArchiveName())
+		case "/latest/download/" + p.ArchiveName():
+			_, _ = w.Write(archive)
+		case "/latest/download/" + p.ArchiveName() + ".sig":
+			_, _ = w.Write([]byte("signature"))
+		case "/latest/download/" + p.ArchiveName() + ".pem":
+			_, _ = w.Write([]byte("certificate"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var verified []string
+	d := &Downloader{Client: server.Client(), BaseURL: server.URL, Attempts: 1, RequireSignature: true,
+		VerifySignature: func(ctx context.Context, archive, signature, certificate string) error {
+			sig, _ := os.ReadFile(signature)
+			cert, _ := os.ReadFile(certificate)
+			verified = append(verified, filepath.Base(archive), string(sig), string(cert))
+			return nil
+		}}
+	artifact, err := d.Download(context.Background(), "", p, t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, artifact.Signed)
+	assert.Equal(t, "signature", verified[1])
+	assert.Equal(t, "certificate", verified[2])
+
+	d.VerifySignature = func(ctx context.Context, archive, signature, certificate string) error {
+		return fmt.Errorf("certificate identity mismatch")
+	}
+	_, err = d.Download(context.Background(), "", p, t.TempDir())
+	assert.ErrorContains(t, err, "signature verification failed")
+}
+
+func TestInstallZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("ddx.exe")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("MZ"))
+	require.NoError(t, zw.Close())
+	archive := filepath.Join(t.TempDir(), "ddx-windows-amd64.zip")
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0644))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ddx.exe"), []byte("old"), 0755))
+	target, err := Install(archive, Platform{OS: "windows", Arch: "amd64"}, dir)
+	require.NoError(t, err)
+	content, _ := os.ReadFile(target)
+	assert.Equal(t, "MZ", string(content))
+	old, _ := os.ReadFile(target + ".old")
+	assert.Equal(t, "old", string(old))
+
+	_, err = Install(archive, Platform{OS: "linux", Arch: "amd64"}, t.TempDir())
+	assert.ErrorContains(t, err, "ddx not found")
+}
@@ -0,0 +1,180 @@
+// Package resolve finds the file an asset name refers to. Assets are looked
+// up in layers, highest precedence first: project overrides in .ddx/<type>
+// (for types that support them), the project library, and the global
+// library in the user data directory when the project has no library configured.
+package resolve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// Layer names, in precedence order
+const (
+	LayerOverride = "project override"
+	LayerLibrary  = "project library"
+	LayerGlobal   = "global library"
+)
+
+// Types are the asset types found in a library
+var Types = []string{"prompts", "personas", "templates", "patterns", "workflows", "mcp-servers", "hooks", "configs", "scripts", "tools", "environments"}
+
+// overridable types can be overridden per project in .ddx/<type>
+var overridable = map[string]bool{"prompts": true, "personas": true}
+
+// fileTypes are types whose assets are single files rather than directories
+var fileTypes = map[string]bool{"prompts": true, "personas": true}
+
+// Layer is a directory searched for assets of one type
+type Layer struct {
+	Name string `json:"layer"`
+	Dir  string `json:"dir"`
+	// Reason explains why the layer is searched
+	Reason string `json:"reason"`
+}
+
+// Candidate is a path checked while resolving an asset
+type Candidate struct {
+	Layer string `json:"layer"`
+	Path  string `json:"path"`
+	Found bool   `json:"found"`
+}
+
+// Match is the outcome of resolving an asset
+type Match struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// Path and Layer are empty when the asset was not found
+	Path   string `json:"path,omitempty"`
+	Layer  string `json:"layer,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Candidates lists each layer's path, found or not, in search order
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Found reports whether the asset resolved to a path
+func (m *Match) Found() bool {
+	return m.Path != ""
+}
+
+// Resolver looks up assets for a project
+type Resolver struct {
+	WorkingDir string
+	// LibraryPath is the project library; empty means none is configured
+	LibraryPath string
+	// GlobalPath is searched when the project has no library
+	GlobalPath string
+	// Selected, when set, hides the project library's assets whose
+	// slash-separated library path it rejects
+	Selected func(rel string) bool
+}
+
+// New returns a resolver for the project in workingDir using the library
+// at libPath, which may be relative to workingDir or empty
+func New(workingDir, libPath string) *Resolver {
+	r := &Resolver{WorkingDir: workingDir}
+	if libPath != "" {
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(workingDir, libPath)
+		}
+		r.LibraryPath = libPath
+	} else if global, err := userdirs.Path(userdirs.Data, "library"); err == nil {
+		r.GlobalPath = global
+	}
+	return r
+}
+
+// IsType reports whether name is a known asset type
+func IsType(name string) bool {
+	for _, t := range Types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Layers returns the directories searched for assets of a type, highest
+// precedence first
+func (r *Resolver) Layers(assetType string) ([]Layer, error) {
+	if !IsType(assetType) {
+		return nil, fmt.Errorf("unknown asset type %q (known types: %s)", assetType, strings.Join(Types, ", "))
+	}
+	var layers []Layer
+	if overridable[assetType] {
+		layers = append(layers, Layer{
+			Name:   LayerOverride,
+			Dir:    filepath.Join(r.WorkingDir, ".ddx", assetType),
+			Reason: fmt.Sprintf(".ddx/%s overrides the library", assetType),
+		})
+	}
+	if r.LibraryPath != "" {
+		layers = append(layers, Layer{
+			Name:   LayerLibrary,
+			Dir:    filepath.Join(r.LibraryPath, assetType),
+			Reason: "library.path in .ddx/config.yaml",
+		})
+	}
+	if r.GlobalPath != "" {
+		layers = append(layers, Layer{
+			Name:   LayerGlobal,
+			Dir:    filepath.Join(r.GlobalPath, assetType),
+			Reason: "no project library is configured",
+		})
+	}
+	return layers, nil
+}
+
+// Resolve looks up an asset by type and name. The name may include a file
+// extension. A missing asset is not an error; check Match.Found.
+func (r *Resolver) Resolve(assetType, name string) (*Match, error) {
+	layers, err := r.Layers(assetType)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	match := &Match{Type: assetType, Name: name, Candidates: []Candidate{}}
+	for _, layer := range layers {
+		path, found := r.find(assetType, layer.Dir, name)
+		if found && layer.Name == LayerLibrary && !r.selected(path) {
+			found = false
+		}
+		match.Candidates = append(match.Candidates, Candidate{Layer: layer.Name, Path: path, Found: found})
+		if found && !match.Found() {
+			match.Path, match.Layer, match.Reason = path, layer.Name, layer.Reason
+		}
+	}
+	return match, nil
+}
+
+// selected reports whether a path in the project library is kept by
+// Selected
+func (r *Resolver) selected(path string) bool {
+	if r.Selected == nil {
+		return true
+	}
+	rel, err := filepath.Rel(r.LibraryPath, path)
+	return err != nil || r.Selected(filepath.ToSlash(rel))
+}
+
+// find checks the forms an asset can take in dir: name.md, the name as
+// given, and name/README.md. It returns the first existing form, or the
+// first form when none exists.
+func (r *Resolver) find(assetType, dir, name string) (string, bool) {
+	base := filepath.Join(dir, filepath.FromSlash(name))
+	forms := []string{base + ".md", base, filepath.Join(base, "README.md")}
+	for _, path := range forms {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() || !fileTypes[assetType] && path == base {
+			return path, true
+		}
+	}
+	return forms[0], false
+}
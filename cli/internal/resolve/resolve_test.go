@@ -0,0 +1,102 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("content\n"), 0644))
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, ".ddx/library")
+	lib := filepath.Join(dir, ".ddx", "library")
+	writeFile(t, filepath.Join(lib, "prompts", "review.md"))
+	writeFile(t, filepath.Join(lib, "prompts", "guide", "README.md"))
+	writeFile(t, filepath.Join(lib, "personas", "reviewer.md"))
+	writeFile(t, filepath.Join(lib, "workflows", "helix", "workflow.yml"))
+	writeFile(t, filepath.Join(dir, ".ddx", "prompts", "review.md"))
+
+	t.Run("override shadows library", func(t *testing.T) {
+		match, err := r.Resolve("prompts", "review")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, ".ddx", "prompts", "review.md"), match.Path)
+		assert.Equal(t, LayerOverride, match.Layer)
+		require.Len(t, match.Candidates, 2)
+		assert.True(t, match.Candidates[1].Found)
+		assert.Equal(t, filepath.Join(lib, "prompts", "review.md"), match.Candidates[1].Path)
+	})
+
+	t.Run("directory with README", func(t *testing.T) {
+		match, err := r.Resolve("prompts", "guide")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(lib, "prompts", "guide", "README.md"), match.Path)
+		assert.Equal(t, LayerLibrary, match.Layer)
+	})
+
+	t.Run("directory assets", func(t *testing.T) {
+		match, err := r.Resolve("workflows", "helix")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(lib, "workflows", "helix"), match.Path)
+	})
+
+	t.Run("project personas shadow library", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, ".ddx", "personas", "reviewer.md"))
+		match, err := r.Resolve("personas", "reviewer.md")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, ".ddx", "personas", "reviewer.md"), match.Path)
+		assert.Equal(t, LayerOverride, match.Layer)
+		assert.Len(t, match.Candidates, 2)
+	})
+
+	t.Run("workflows have no override layer", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, ".ddx", "workflows", "helix", "workflow.yml"))
+		match, err := r.Resolve("workflows", "helix")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(lib, "workflows", "helix"), match.Path)
+		assert.Len(t, match.Candidates, 1)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		match, err := r.Resolve("personas", "missing")
+		require.NoError(t, err)
+		assert.False(t, match.Found())
+		assert.Equal(t, filepath.Join(lib, "personas", "missing.md"), match.Candidates[1].Path)
+	})
+
+	t.Run("selection hides library assets", func(t *testing.T) {
+		hidden := &Resolver{WorkingDir: dir, LibraryPath: lib, Selected: func(rel string) bool {
+			return rel != "prompts/guide/README.md"
+		}}
+		match, err := hidden.Resolve("prompts", "guide")
+		require.NoError(t, err)
+		assert.False(t, match.Found())
+		match, err = hidden.Resolve("workflows", "helix")
+		require.NoError(t, err)
+		assert.True(t, match.Found())
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := r.Resolve("widgets", "x")
+		assert.ErrorContains(t, err, `unknown asset type "widgets"`)
+	})
+}
+
+func TestResolveGlobal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".ddx", "library", "personas", "reviewer.md"))
+
+	match, err := New(t.TempDir(), "").Resolve("personas", "reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, LayerGlobal, match.Layer)
+	assert.Equal(t, filepath.Join(home, ".ddx", "library", "personas", "reviewer.md"), match.Path)
+}
@@ -0,0 +1,159 @@
+// Package scripts runs the executable helpers a library ships in its
+// scripts directory. A script only runs when the project allows it by name
+// and the user has approved its current content; approvals are recorded
+// per user by content hash, so a script that changes must be approved
+// again.
+package scripts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// TrustFile records approved scripts, kept in the user's config directory
+const TrustFile = "trusted-scripts.json"
+
+// passEnv are the variables a script inherits from the environment ddx
+// runs in; everything else must be passed with exec.env
+var passEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "TMPDIR", "TZ"}
+
+// Script is an executable found in a library's scripts directory
+type Script struct {
+	// Name is the file name without its extension
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Available lists the scripts in dir, sorted by name. Hidden files,
+// directories and READMEs are not scripts.
+func Available(dir string) ([]Script, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var found []Script
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(file, ".") || strings.HasPrefix(strings.ToLower(file), "readme") {
+			continue
+		}
+		found = append(found, Script{Name: strings.TrimSuffix(file, filepath.Ext(file)), Path: filepath.Join(dir, file)})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, nil
+}
+
+// Allowed reports whether a script name matches one of the patterns of
+// exec.allow
+func Allowed(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Hash returns the SHA-256 of a script's content
+func Hash(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Env builds a script's environment: the variables of passEnv and extra
+// found in environ, followed by vars, which take precedence
+func Env(environ []string, extra []string, vars map[string]string) []string {
+	keep := make(map[string]bool, len(passEnv)+len(extra))
+	for _, name := range append(append([]string(nil), passEnv...), extra...) {
+		keep[name] = true
+	}
+	var env []string
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if keep[name] && vars[name] == "" {
+			env = append(env, entry)
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	return env
+}
+
+// Trust is the record of scripts a user approved, by path and hash
+type Trust struct {
+	path    string
+	Scripts map[string]string `json:"scripts"`
+}
+
+// LoadTrust reads the approvals in file; a missing file has none
+func LoadTrust(file string) (*Trust, error) {
+	trust := &Trust{path: file, Scripts: map[string]string{}}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return trust, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script approvals: %w", err)
+	}
+	if err := json.Unmarshal(data, trust); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if trust.Scripts == nil {
+		trust.Scripts = map[string]string{}
+	}
+	return trust, nil
+}
+
+// Approved reports whether the script at path was approved with this hash
+func (t *Trust) Approved(script, hash string) bool {
+	return t.Scripts[script] == hash
+}
+
+// Known reports whether the script at path was approved with any hash
+func (t *Trust) Known(script string) bool {
+	_, ok := t.Scripts[script]
+	return ok
+}
+
+// Approve records a script's hash and saves the approvals
+func (t *Trust) Approve(script, hash string) error {
+	t.Scripts[script] = hash
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return fmt.Errorf("failed to save script approvals: %w", err)
+	}
+	if err := atomicfile.WriteFile(t.path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to save script approvals: %w", err)
+	}
+	return nil
+}
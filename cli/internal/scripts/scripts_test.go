@@ -0,0 +1,70 @@
+package scripts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailable(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"lint.sh", "release", "README.md", ".hidden"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0755))
+
+	found, err := Available(dir)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, Script{Name: "lint", Path: filepath.Join(dir, "lint.sh")}, found[0])
+	assert.Equal(t, "release", found[1].Name)
+
+	found, err = Available(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestAllowed(t *testing.T) {
+	assert.True(t, Allowed([]string{"lint"}, "lint"))
+	assert.True(t, Allowed([]string{"release-*"}, "release-notes"))
+	assert.True(t, Allowed([]string{"*"}, "anything"))
+	assert.False(t, Allowed([]string{"lint"}, "release"))
+	assert.False(t, Allowed(nil, "lint"))
+}
+
+func TestEnv(t *testing.T) {
+	environ := []string{"PATH=/bin", "HOME=/home/me", "GITHUB_TOKEN=secret", "NPM_CONFIG=x", "DDX_PROJECT_DIR=/elsewhere"}
+	env := Env(environ, []string{"NPM_CONFIG"}, map[string]string{"DDX_PROJECT_DIR": "/project", "DDX_SCRIPT": "lint"})
+	assert.Equal(t, []string{"PATH=/bin", "HOME=/home/me", "NPM_CONFIG=x", "DDX_PROJECT_DIR=/project", "DDX_SCRIPT=lint"}, env)
+}
+
+func TestTrust(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "lint.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho lint\n"), 0755))
+	hash, err := Hash(script)
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	file := filepath.Join(dir, "home", ".ddx", TrustFile)
+	trust, err := LoadTrust(file)
+	require.NoError(t, err)
+	assert.False(t, trust.Known(script))
+	require.NoError(t, trust.Approve(script, hash))
+
+	trust, err = LoadTrust(file)
+	require.NoError(t, err)
+	assert.True(t, trust.Approved(script, hash))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nrm -rf /\n"), 0755))
+	changed, err := Hash(script)
+	require.NoError(t, err)
+	assert.False(t, trust.Approved(script, changed))
+	assert.True(t, trust.Known(script))
+}
@@ -0,0 +1,83 @@
+// Package sealed encrypts small files with a passphrase, for data that
+// leaves the machine such as synced settings and exported credentials. The
+// key is derived from the passphrase with scrypt and the data is encrypted
+// with AES-256-GCM, so a sealed file holds no plaintext.
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// saltSize is the length of the random salt used to derive the key
+const saltSize = 16
+
+// ErrOpen is returned when sealed data cannot be decrypted: the passphrase
+// is wrong or the data was corrupted
+var ErrOpen = errors.New("wrong passphrase or corrupted data")
+
+// Seal encrypts plaintext with a key derived from passphrase. The result
+// is header, which says what the file is, followed by the salt, nonce and
+// ciphertext in base64.
+func Seal(header string, plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(append(salt, nonce...), gcm.Seal(nil, nonce, plaintext, nil)...)
+	return []byte(header + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Open decrypts data made by Seal with the same header
+func Open(header string, data []byte, passphrase string) ([]byte, error) {
+	encoded := strings.TrimSpace(strings.TrimPrefix(string(data), header))
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < saltSize {
+		return nil, ErrOpen
+	}
+	salt, rest := sealed[:saltSize], sealed[saltSize:]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrOpen
+	}
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return plaintext, nil
+}
+
+// newGCM derives an AES-256 key from the passphrase with scrypt
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,29 @@
+package sealed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	const header = "# Test data, encrypted with a passphrase.\n"
+	data, err := Seal(header, []byte("secret value"), "correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), header))
+	assert.NotContains(t, string(data), "secret value")
+
+	plaintext, err := Open(header, data, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "secret value", string(plaintext))
+
+	_, err = Open(header, data, "wrong")
+	assert.ErrorIs(t, err, ErrOpen)
+	_, err = Open(header, []byte(header+"not base64!\n"), "correct horse")
+	assert.ErrorIs(t, err, ErrOpen)
+
+	_, err = Seal(header, []byte("x"), "")
+	assert.ErrorContains(t, err, "passphrase cannot be empty")
+}
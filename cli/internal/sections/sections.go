@@ -0,0 +1,324 @@
+// Package sections manages blocks of generated content inside AI
+// configuration files such as CLAUDE.md, leaving the rest of the file to
+// its authors.
+package sections
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// DefaultOrder places sections that do not ask for a position
+const DefaultOrder = 50
+
+// Marker prefixes for named sections
+const (
+	startMarker = "<!-- DDX:START"
+	endMarker   = "<!-- DDX:END"
+)
+
+// startPattern matches a section start marker and captures its attributes
+var startPattern = regexp.MustCompile(`(?m)^<!-- DDX:START((?:\s+[a-z]+="[^"]*")*)\s*-->[ \t]*$`)
+
+// attributePattern matches a key="value" marker attribute
+var attributePattern = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+
+// legacyBlocks are the markers earlier versions wrote around the personas
+// and meta-prompt blocks before they became named sections. Parse reads
+// them as those sections, in place, so the next save migrates the file.
+var legacyBlocks = []struct{ name, start, end string }{
+	{"personas", "<!-- PERSONAS:START -->", "<!-- PERSONAS:END -->"},
+	{"meta-prompt", "<!-- DDX-META-PROMPT:START -->", "<!-- DDX-META-PROMPT:END -->"},
+}
+
+// legacySourcePattern matches the source comment that opened a legacy block
+var legacySourcePattern = regexp.MustCompile(`^\s*<!-- Source: ([^\n]*?) -->\n?`)
+
+// namePattern restricts section names to what fits in a marker
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Section is a named block of managed content
+type Section struct {
+	Name string
+	// Source records where the content came from, such as a library prompt
+	Source string
+	// Order positions the section; lower orders come first, then names
+	Order int
+	// Checksum is the content checksum recorded when the section was
+	// written, used to detect edits made by hand
+	Checksum string
+	Content  string
+}
+
+// Modified reports whether the content was changed since it was written
+func (s Section) Modified() bool {
+	return s.Checksum != "" && s.Checksum != Checksum(s.Content)
+}
+
+// Checksum returns the checksum recorded for content
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ValidateName checks that name can be used for a section
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid section name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// Markers returns the start marker of the named section, up to its
+// attributes, and its end marker, for finding the section in a file
+func Markers(name string) (start, end string) {
+	return fmt.Sprintf(`%s name="%s"`, startMarker, name), fmt.Sprintf(`%s name="%s" -->`, endMarker, name)
+}
+
+// Document is a file split into its own text and its managed sections
+type Document struct {
+	// Text is the file content outside managed sections
+	Text     string
+	Sections []Section
+
+	// pieces are the text around the parsed sections, which sat between
+	// them in the order of placed, so rendering keeps each section where
+	// it was. They apply while Text is unchanged.
+	pieces []string
+	placed []string
+	parsed string
+}
+
+// Parse splits content into text and managed sections. A section without
+// its end marker is an error so nothing the user wrote is lost. Blocks with
+// legacy markers are read as the sections that replaced them.
+func Parse(content string) (*Document, error) {
+	doc := &Document{}
+	rest := migrateLegacy(content)
+	for {
+		loc := startPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			doc.pieces = append(doc.pieces, rest)
+			break
+		}
+		doc.pieces = append(doc.pieces, rest[:loc[0]])
+
+		section := Section{Order: DefaultOrder}
+		for _, attr := range attributePattern.FindAllStringSubmatch(rest[loc[2]:loc[3]], -1) {
+			switch attr[1] {
+			case "name":
+				section.Name = attr[2]
+			case "source":
+				section.Source = attr[2]
+			case "order":
+				if order, err := strconv.Atoi(attr[2]); err == nil {
+					section.Order = order
+				}
+			case "checksum":
+				section.Checksum = attr[2]
+			}
+		}
+		if section.Name == "" {
+			return nil, fmt.Errorf("managed section without a name")
+		}
+
+		body := rest[loc[1]:]
+		_, end := Markers(section.Name)
+		endIdx := strings.Index(body, end)
+		if endIdx == -1 {
+			return nil, fmt.Errorf("managed section %s has no end marker", section.Name)
+		}
+		section.Content = strings.Trim(body[:endIdx], "\n")
+		doc.Sections = append(doc.Sections, section)
+		doc.placed = append(doc.placed, section.Name)
+		rest = body[endIdx+len(end):]
+	}
+
+	// Text around a removed section is rejoined with a blank line
+	var kept []string
+	for i, piece := range doc.pieces {
+		doc.pieces[i] = strings.Trim(piece, "\n")
+		if doc.pieces[i] != "" {
+			kept = append(kept, doc.pieces[i])
+		}
+	}
+	doc.Text = strings.Join(kept, "\n\n")
+	doc.parsed = doc.Text
+	return doc, nil
+}
+
+// migrateLegacy rewrites blocks with legacy markers as named sections. A
+// block missing its end marker is left as text.
+func migrateLegacy(content string) string {
+	for _, legacy := range legacyBlocks {
+		startIdx := strings.Index(content, legacy.start)
+		if startIdx == -1 {
+			continue
+		}
+		body := content[startIdx+len(legacy.start):]
+		endIdx := strings.Index(body, legacy.end)
+		if endIdx == -1 {
+			continue
+		}
+		attrs := fmt.Sprintf(`name="%s"`, legacy.name)
+		inner := body[:endIdx]
+		if match := legacySourcePattern.FindStringSubmatch(inner); match != nil {
+			attrs += fmt.Sprintf(` source="%s"`, strings.ReplaceAll(match[1], `"`, ""))
+			inner = inner[len(match[0]):]
+		}
+		_, end := Markers(legacy.name)
+		content = content[:startIdx] + fmt.Sprintf("%s %s -->\n%s\n%s", startMarker, attrs, strings.Trim(inner, "\n"), end) +
+			body[endIdx+len(legacy.end):]
+	}
+	return content
+}
+
+// Load reads and parses a file; a missing file is an empty document
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Document{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	doc, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Get returns the named section
+func (d *Document) Get(name string) (Section, bool) {
+	for _, s := range d.Sections {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Set adds or replaces a section, recording the checksum of its content.
+// It reports whether the document changed.
+func (d *Document) Set(section Section) bool {
+	section.Content = strings.Trim(section.Content, "\n")
+	section.Checksum = Checksum(section.Content)
+	for i, s := range d.Sections {
+		if s.Name == section.Name {
+			changed := s != section
+			d.Sections[i] = section
+			return changed
+		}
+	}
+	d.Sections = append(d.Sections, section)
+	return true
+}
+
+// Remove deletes the named section, reporting whether it was present
+func (d *Document) Remove(name string) bool {
+	for i, s := range d.Sections {
+		if s.Name == name {
+			d.Sections = append(d.Sections[:i], d.Sections[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Sorted returns the sections in the order they are written
+func (d *Document) Sorted() []Section {
+	sorted := append([]Section(nil), d.Sections...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sortsBefore(sorted[i], sorted[j]) })
+	return sorted
+}
+
+// String renders the document. Parsed sections stay where they were in
+// the file; a new section goes before the first one that sorts after it,
+// or at the end. When Text was replaced, it is followed by the sections in
+// order.
+func (d *Document) String() string {
+	pieces, placed := d.pieces, d.placed
+	if pieces == nil || d.Text != d.parsed {
+		pieces, placed = []string{d.Text}, nil
+	}
+	current := make(map[string]Section, len(d.Sections))
+	for _, s := range d.Sections {
+		current[s.Name] = s
+	}
+	var unplaced []Section
+	for _, s := range d.Sorted() {
+		if !slices.Contains(placed, s.Name) {
+			unplaced = append(unplaced, s)
+		}
+	}
+
+	parts := []string{}
+	for i, piece := range pieces {
+		if text := strings.TrimRight(piece, " \t\n"); text != "" {
+			parts = append(parts, text)
+		}
+		if i >= len(placed) {
+			continue
+		}
+		s, ok := current[placed[i]]
+		if !ok {
+			continue
+		}
+		for len(unplaced) > 0 && sortsBefore(unplaced[0], s) {
+			parts = append(parts, render(unplaced[0]))
+			unplaced = unplaced[1:]
+		}
+		parts = append(parts, render(s))
+	}
+	for _, s := range unplaced {
+		parts = append(parts, render(s))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// sortsBefore reports whether section a is written before b
+func sortsBefore(a, b Section) bool {
+	if a.Order != b.Order {
+		return a.Order < b.Order
+	}
+	return a.Name < b.Name
+}
+
+// render writes a section with its markers
+func render(s Section) string {
+	attrs := fmt.Sprintf(`name="%s"`, s.Name)
+	if s.Source != "" {
+		attrs += fmt.Sprintf(` source="%s"`, s.Source)
+	}
+	attrs += fmt.Sprintf(` order="%d"`, s.Order)
+	if s.Checksum != "" {
+		attrs += fmt.Sprintf(` checksum="%s"`, s.Checksum)
+	}
+	_, end := Markers(s.Name)
+	return fmt.Sprintf("%s %s -->\n%s\n%s", startMarker, attrs, s.Content, end)
+}
+
+// Save writes the document to path, creating its directory if needed
+func (d *Document) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := atomicfile.WriteFile(path, []byte(d.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
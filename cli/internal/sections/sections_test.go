@@ -0,0 +1,158 @@
+package sections
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := Parse("# Project\n\nOur notes.\n")
+	require.NoError(t, err)
+
+	assert.True(t, doc.Set(Section{Name: "review", Source: "common/review.md", Order: DefaultOrder, Content: "Review carefully."}))
+	assert.True(t, doc.Set(Section{Name: "focus", Source: "claude/focused.md", Order: 10, Content: "Stay focused.\n"}))
+	assert.False(t, doc.Set(Section{Name: "review", Source: "common/review.md", Order: DefaultOrder, Content: "Review carefully."}),
+		"setting the same content is not a change")
+
+	rendered := doc.String()
+	assert.Equal(t, `# Project
+
+Our notes.
+
+<!-- DDX:START name="focus" source="claude/focused.md" order="10" checksum="`+Checksum("Stay focused.")+`" -->
+Stay focused.
+<!-- DDX:END name="focus" -->
+
+<!-- DDX:START name="review" source="common/review.md" order="50" checksum="`+Checksum("Review carefully.")+`" -->
+Review carefully.
+<!-- DDX:END name="review" -->
+`, rendered)
+
+	parsed, err := Parse(rendered)
+	require.NoError(t, err)
+	assert.Equal(t, "# Project\n\nOur notes.", parsed.Text)
+	assert.Equal(t, doc.Sorted(), parsed.Sorted())
+	assert.Equal(t, rendered, parsed.String(), "rendering is stable")
+
+	assert.True(t, parsed.Remove("focus"))
+	assert.False(t, parsed.Remove("focus"))
+	_, found := parsed.Get("focus")
+	assert.False(t, found)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("text around sections", func(t *testing.T) {
+		doc, err := Parse("before\n\n<!-- DDX:START name=\"a\" -->\nA\n<!-- DDX:END name=\"a\" -->\n\nafter\n")
+		require.NoError(t, err)
+		assert.Equal(t, "before\n\nafter", doc.Text)
+		section, found := doc.Get("a")
+		require.True(t, found)
+		assert.Equal(t, "A", section.Content)
+		assert.Equal(t, DefaultOrder, section.Order)
+		assert.False(t, section.Modified(), "sections without a checksum are not reported as edited")
+	})
+
+	t.Run("edited by hand", func(t *testing.T) {
+		doc := &Document{}
+		doc.Set(Section{Name: "a", Content: "original"})
+		edited := doc.String()
+		edited = edited[:len(edited)-len("original\n<!-- DDX:END name=\"a\" -->\n")] + "changed\n<!-- DDX:END name=\"a\" -->\n"
+		parsed, err := Parse(edited)
+		require.NoError(t, err)
+		section, _ := parsed.Get("a")
+		assert.True(t, section.Modified())
+	})
+
+	t.Run("missing end marker", func(t *testing.T) {
+		_, err := Parse("<!-- DDX:START name=\"a\" -->\nA\n")
+		assert.ErrorContains(t, err, "managed section a has no end marker")
+	})
+
+	t.Run("other markers are text", func(t *testing.T) {
+		content := "<!-- OTHER:START -->\nother\n<!-- OTHER:END -->"
+		doc, err := Parse(content)
+		require.NoError(t, err)
+		assert.Equal(t, content, doc.Text)
+		assert.Empty(t, doc.Sections)
+	})
+
+	t.Run("legacy markers are migrated in place", func(t *testing.T) {
+		doc, err := Parse("# Rules\n\n<!-- DDX-META-PROMPT:START -->\n<!-- Source: claude/focused.md -->\nStay focused.\n<!-- DDX-META-PROMPT:END -->\n\n" +
+			"Middle.\n\n<!-- PERSONAS:START -->\n## Active Personas\n<!-- PERSONAS:END -->\n\nEnd.\n")
+		require.NoError(t, err)
+		metaPrompt, found := doc.Get("meta-prompt")
+		require.True(t, found)
+		assert.Equal(t, Section{Name: "meta-prompt", Source: "claude/focused.md", Order: DefaultOrder, Content: "Stay focused."}, metaPrompt)
+		personas, found := doc.Get("personas")
+		require.True(t, found)
+		assert.Equal(t, "## Active Personas", personas.Content)
+
+		rendered := doc.String()
+		assert.NotContains(t, rendered, "PERSONAS:START")
+		assert.NotContains(t, rendered, "DDX-META-PROMPT")
+		assert.Regexp(t, `(?s)^# Rules\n\n<!-- DDX:START name="meta-prompt".*Middle\.\n\n<!-- DDX:START name="personas".*-->\n\nEnd\.\n$`, rendered)
+	})
+
+	t.Run("unterminated legacy block is text", func(t *testing.T) {
+		doc, err := Parse("<!-- PERSONAS:START -->\npersonas")
+		require.NoError(t, err)
+		assert.Empty(t, doc.Sections)
+	})
+}
+
+func TestDocumentKeepsSectionPositions(t *testing.T) {
+	doc, err := Parse("# Top\n\n<!-- DDX:START name=\"b\" order=\"50\" -->\nB\n<!-- DDX:END name=\"b\" -->\n\n" +
+		"Middle.\n\n<!-- DDX:START name=\"d\" order=\"50\" -->\nD\n<!-- DDX:END name=\"d\" -->\n\nBottom.\n")
+	require.NoError(t, err)
+
+	doc.Set(Section{Name: "b", Order: 50, Content: "B2"})
+	doc.Set(Section{Name: "c", Order: 50, Content: "C"})
+	doc.Set(Section{Name: "e", Order: 50, Content: "E"})
+	var order []string
+	rendered := doc.String()
+	for _, line := range strings.Split(rendered, "\n") {
+		if line != "" && !strings.HasPrefix(line, "<!-- DDX:START") {
+			order = append(order, line)
+		}
+	}
+	assert.Equal(t, []string{"# Top", "B2", "<!-- DDX:END name=\"b\" -->", "Middle.", "C", "<!-- DDX:END name=\"c\" -->",
+		"D", "<!-- DDX:END name=\"d\" -->", "Bottom.", "E", "<!-- DDX:END name=\"e\" -->"}, order,
+		"existing sections stay in place and new ones go by order")
+
+	assert.True(t, doc.Remove("b"))
+	assert.NotContains(t, doc.String(), "name=\"b\"")
+	assert.Contains(t, doc.String(), "# Top\n\nMiddle.\n\n")
+}
+
+func TestMarkers(t *testing.T) {
+	doc := &Document{}
+	doc.Set(Section{Name: "personas", Content: "P"})
+	start, end := Markers("personas")
+	assert.True(t, strings.HasPrefix(doc.String(), start+" "))
+	assert.True(t, strings.HasSuffix(doc.String(), end+"\n"))
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AGENTS.md")
+	doc, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, doc.Sections)
+
+	doc.Set(Section{Name: "a", Order: 1, Content: "A"})
+	require.NoError(t, doc.Save(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- DDX:START name=\"a\" order=\"1\" checksum=\""+Checksum("A")+"\" -->\nA\n<!-- DDX:END name=\"a\" -->\n", string(data))
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("code-review.v2"))
+	assert.Error(t, ValidateName(""))
+	assert.Error(t, ValidateName("has space"))
+	assert.Error(t, ValidateName(`quote"`))
+}
@@ -0,0 +1,219 @@
+// Package settings syncs a user's personal DDx settings between machines
+// through a git repository they provide. Settings are encrypted with a
+// passphrase before they leave the machine, so the repository only ever
+// holds ciphertext.
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/git"
+	"github.com/easel/ddx/internal/sealed"
+)
+
+// FileName is the encrypted settings file in the sync repository
+const FileName = "ddx-settings.enc"
+
+// Version is the settings bundle format version
+const Version = 1
+
+// SyncedFiles are the files synced from the user's ~/.ddx directory. The
+// global config holds personal defaults and prompt aliases.
+var SyncedFiles = []string{"config.yaml"}
+
+// header starts the encrypted settings file
+const header = "# DDx settings, encrypted with a passphrase. Decrypt with 'ddx settings sync pull'.\n"
+
+// ErrPassphrase is returned when settings cannot be decrypted
+var ErrPassphrase = errors.New("wrong passphrase or corrupted settings")
+
+// Bundle is the set of settings files that is encrypted and synced
+type Bundle struct {
+	Version int `json:"version"`
+	// Files maps names relative to ~/.ddx to their content
+	Files map[string]string `json:"files"`
+}
+
+// Collect reads the synced files present in dir
+func Collect(dir string) (*Bundle, error) {
+	b := &Bundle{Version: Version, Files: map[string]string{}}
+	for _, name := range SyncedFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		b.Files[name] = string(data)
+	}
+	if len(b.Files) == 0 {
+		return nil, fmt.Errorf("no settings to sync in %s", dir)
+	}
+	return b, nil
+}
+
+// Names returns the bundled file names, sorted
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply writes the bundle into dir. A file that is about to be replaced
+// with different content is kept alongside it with a .bak suffix. It
+// returns the names of the files that changed.
+func (b *Bundle) Apply(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, name := range b.Names() {
+		if !isSynced(name) {
+			return changed, fmt.Errorf("settings contain unexpected file %q", name)
+		}
+		path := filepath.Join(dir, name)
+		current, err := os.ReadFile(path)
+		if err == nil && string(current) == b.Files[name] {
+			continue
+		}
+		if err == nil {
+			if err := atomicfile.WriteFile(path+".bak", current, 0600); err != nil {
+				return changed, fmt.Errorf("failed to back up %s: %w", name, err)
+			}
+		}
+		if err := atomicfile.WriteFile(path, []byte(b.Files[name]), 0600); err != nil {
+			return changed, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		changed = append(changed, name)
+	}
+	return changed, nil
+}
+
+// isSynced reports whether name is one of SyncedFiles
+func isSynced(name string) bool {
+	for _, synced := range SyncedFiles {
+		if name == synced {
+			return true
+		}
+	}
+	return false
+}
+
+// Encrypt seals the bundle with a key derived from passphrase and returns
+// the content of the settings file
+func (b *Bundle) Encrypt(passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return sealed.Seal(header, plaintext, passphrase)
+}
+
+// Decrypt opens a settings file encrypted with passphrase
+func Decrypt(data []byte, passphrase string) (*Bundle, error) {
+	plaintext, err := sealed.Open(header, data, passphrase)
+	if errors.Is(err, sealed.ErrOpen) {
+		return nil, ErrPassphrase
+	}
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{}
+	if err := json.Unmarshal(plaintext, b); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("settings have unsupported version %d (expected %d)", b.Version, Version)
+	}
+	return b, nil
+}
+
+// Push commits the encrypted settings to branch of the repository. An
+// empty repository is initialized with the branch.
+func Push(ctx context.Context, repoURL, branch string, data []byte) error {
+	dir, err := clone(ctx, repoURL, "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if err := runGit(ctx, dir, "checkout", "-q", "-B", branch); err != nil {
+		return err
+	}
+	if err := runGit(ctx, dir, "fetch", "-q", "origin", branch); err == nil {
+		if err := runGit(ctx, dir, "reset", "-q", "--hard", "FETCH_HEAD"); err != nil {
+			return err
+		}
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+		return err
+	}
+	if err := runGit(ctx, dir, "add", FileName); err != nil {
+		return err
+	}
+	name, email := git.UserIdentity(dir)
+	if email == "" {
+		email = name + "@localhost"
+	}
+	if err := runGit(ctx, dir, "-c", "user.name="+name, "-c", "user.email="+email, "commit", "-q", "-m", "Update DDx settings"); err != nil {
+		return err
+	}
+	return runGit(ctx, dir, "push", "-q", "origin", "HEAD:refs/heads/"+branch)
+}
+
+// Pull returns the encrypted settings from branch of the repository
+func Pull(ctx context.Context, repoURL, branch string) ([]byte, error) {
+	dir, err := clone(ctx, repoURL, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s has no %s on %s; run 'ddx settings sync push' first", repoURL, FileName, branch)
+	}
+	return data, err
+}
+
+// clone checks out the repository into a temporary directory, which the
+// caller removes. An empty branch clones the default branch.
+func clone(ctx context.Context, repoURL, branch string) (string, error) {
+	dir, err := os.MkdirTemp("", "ddx-settings-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	args := []string{"clone", "--depth=1", "--quiet"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, "--", repoURL, dir)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to clone %s: %v\n%s", repoURL, err, strings.TrimSpace(string(output)))
+	}
+	return dir, nil
+}
+
+// runGit runs a git command in dir
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %v\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
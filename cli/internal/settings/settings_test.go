@@ -0,0 +1,70 @@
+package settings
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	b := &Bundle{Version: Version, Files: map[string]string{"config.yaml": "prompts:\n  review: code-review\n"}}
+	data, err := b.Encrypt("correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "code-review")
+
+	opened, err := Decrypt(data, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, b.Files, opened.Files)
+
+	_, err = Decrypt(data, "wrong")
+	assert.ErrorIs(t, err, ErrPassphrase)
+	_, err = b.Encrypt("")
+	assert.EqualError(t, err, "passphrase cannot be empty")
+}
+
+func TestCollectApply(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Collect(dir)
+	assert.ErrorContains(t, err, "no settings to sync")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("old\n"), 0644))
+	b, err := Collect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.yaml"}, b.Names())
+
+	changed, err := b.Apply(dir)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	b.Files["config.yaml"] = "new\n"
+	changed, err = b.Apply(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.yaml"}, changed)
+	backup, err := os.ReadFile(filepath.Join(dir, "config.yaml.bak"))
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(backup))
+
+	b.Files["../escape"] = "x"
+	_, err = b.Apply(dir)
+	assert.ErrorContains(t, err, `unexpected file "../escape"`)
+}
+
+func TestPushPull(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "settings.git")
+	require.NoError(t, exec.Command("git", "init", "-q", "--bare", remote).Run())
+	ctx := context.Background()
+
+	_, err := Pull(ctx, remote, "main")
+	assert.Error(t, err)
+
+	require.NoError(t, Push(ctx, remote, "main", []byte("first\n")))
+	require.NoError(t, Push(ctx, remote, "main", []byte("second\n")))
+	data, err := Pull(ctx, remote, "main")
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(data))
+}
@@ -0,0 +1,378 @@
+// Package snapshot records project state before ddx changes it so the
+// change can be rolled back: the library files, .ddx/config.yaml, ddx.lock
+// and the managed sections of the AI target files, such as CLAUDE.md.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/lock"
+	"github.com/easel/ddx/internal/sections"
+)
+
+// Dir holds snapshots, relative to the project root
+const Dir = ".ddx/snapshots"
+
+// Keep is the number of snapshots retained; older ones are removed
+const Keep = 10
+
+// manifestName is the snapshot metadata file
+const manifestName = "snapshot.json"
+
+// trackedFiles are project files captured whole, relative to the root
+var trackedFiles = []string{".ddx/config.yaml", lock.FileName}
+
+// Snapshot is a recorded project state
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Created   time.Time `json:"created"`
+	Operation string    `json:"operation"`
+	// LibraryPath is the library directory, relative to the project root
+	LibraryPath string       `json:"library_path"`
+	Library     lock.Library `json:"library"`
+	// Assets maps library paths to content hashes, as in ddx.lock
+	Assets map[string]string `json:"assets"`
+	// Files maps tracked project files to content hashes
+	Files map[string]string `json:"files"`
+	// Sections maps each AI target file to its managed sections, with
+	// their checksums. A file without sections maps to an empty set.
+	Sections map[string]map[string]string `json:"sections,omitempty"`
+
+	dir string
+}
+
+// Root returns the snapshot directory for a project
+func Root(workingDir string) string {
+	return filepath.Join(workingDir, filepath.FromSlash(Dir))
+}
+
+// Create records the current state of the project. libPath is the library
+// directory and targetFiles the AI target files, relative to workingDir.
+// Snapshots beyond Keep are removed.
+func Create(workingDir, libPath, operation string, library lock.Library, targetFiles []string) (*Snapshot, error) {
+	now := time.Now().UTC()
+	s := &Snapshot{
+		ID:          now.Format("20060102-150405"),
+		Created:     now,
+		Operation:   operation,
+		LibraryPath: filepath.ToSlash(libPath),
+		Library:     library,
+		Files:       map[string]string{},
+		Sections:    map[string]map[string]string{},
+	}
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(Root(workingDir), s.ID)); os.IsNotExist(err) {
+			break
+		}
+		s.ID = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+	s.dir = filepath.Join(Root(workingDir), s.ID)
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	// Snapshots are local state and stay out of version control
+	ignore := filepath.Join(Root(workingDir), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	if err := s.capture(workingDir, targetFiles); err != nil {
+		_ = os.RemoveAll(s.dir)
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	if err := prune(workingDir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// capture copies the project state into the snapshot directory
+func (s *Snapshot) capture(workingDir string, targetFiles []string) error {
+	libDir := filepath.Join(workingDir, filepath.FromSlash(s.LibraryPath))
+	assets, err := lock.HashLibrary(libDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if assets == nil {
+		assets = map[string]string{}
+	}
+	s.Assets = assets
+	for rel := range assets {
+		if err := atomicfile.CopyFile(filepath.Join(libDir, filepath.FromSlash(rel)), filepath.Join(s.dir, "library", filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range trackedFiles {
+		src := filepath.Join(workingDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := atomicfile.CopyFile(src, filepath.Join(s.dir, "files", filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+		hash, err := hashFile(src)
+		if err != nil {
+			return err
+		}
+		s.Files[rel] = hash
+	}
+
+	for _, rel := range targetFiles {
+		rel = filepath.ToSlash(rel)
+		doc, err := sections.Load(filepath.Join(workingDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		s.Sections[rel] = sectionChecksums(doc)
+		if len(doc.Sections) == 0 {
+			continue
+		}
+		kept := &sections.Document{Sections: doc.Sections}
+		if err := kept.Save(filepath.Join(s.dir, "sections", filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	return s.save()
+}
+
+// save writes the snapshot manifest
+func (s *Snapshot) save() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(s.dir, manifestName), data, 0644)
+}
+
+// List returns the project's snapshots, newest first
+func List(workingDir string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(Root(workingDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	var list []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		s, err := Load(workingDir, entry.Name())
+		if err != nil {
+			continue
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.After(list[j].Created)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// Load reads a snapshot by ID
+func Load(workingDir, id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid snapshot id %q", id)
+	}
+	dir := filepath.Join(Root(workingDir), id)
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", id, err)
+	}
+	s.dir = dir
+	return s, nil
+}
+
+// Restore puts the project back into the recorded state. The library is
+// replaced, tracked files are restored or removed, and the managed
+// sections of each AI target file are replaced while the rest of the file
+// is kept.
+func (s *Snapshot) Restore(workingDir string) error {
+	libDir := filepath.Join(workingDir, filepath.FromSlash(s.LibraryPath))
+	if err := clearDir(libDir); err != nil {
+		return fmt.Errorf("failed to clear library: %w", err)
+	}
+	for rel := range s.Assets {
+		if err := atomicfile.CopyFile(filepath.Join(s.dir, "library", filepath.FromSlash(rel)), filepath.Join(libDir, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("failed to restore library: %w", err)
+		}
+	}
+
+	for _, rel := range trackedFiles {
+		dst := filepath.Join(workingDir, filepath.FromSlash(rel))
+		if _, ok := s.Files[rel]; !ok {
+			if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", rel, err)
+			}
+			continue
+		}
+		if err := atomicfile.CopyFile(filepath.Join(s.dir, "files", filepath.FromSlash(rel)), dst); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", rel, err)
+		}
+	}
+
+	for rel := range s.Sections {
+		kept, err := sections.Load(filepath.Join(s.dir, "sections", filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(workingDir, filepath.FromSlash(rel))
+		doc, err := sections.Load(path)
+		if err != nil {
+			return err
+		}
+		if len(doc.Sections) == 0 && len(kept.Sections) == 0 {
+			continue
+		}
+		doc.Sections = kept.Sections
+		if err := doc.Save(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify compares the project with the snapshot and describes every
+// difference; an empty result means the project matches
+func (s *Snapshot) Verify(workingDir string) ([]string, error) {
+	l := &lock.Lock{Assets: s.Assets}
+	drift, err := l.Verify(filepath.Join(workingDir, filepath.FromSlash(s.LibraryPath)))
+	if errors.Is(err, fs.ErrNotExist) {
+		// Without a library every recorded asset is missing
+		drift, err = nil, nil
+		for rel := range s.Assets {
+			drift = append(drift, lock.Drift{Path: rel, Status: lock.DriftMissing})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, d := range drift {
+		problems = append(problems, fmt.Sprintf("%s/%s (%s)", s.LibraryPath, d.Path, d.Status))
+	}
+
+	for _, rel := range trackedFiles {
+		want, tracked := s.Files[rel]
+		got, err := hashFile(filepath.Join(workingDir, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err) && tracked:
+			problems = append(problems, rel+" (missing)")
+		case err != nil && !os.IsNotExist(err):
+			return nil, err
+		case err == nil && !tracked:
+			problems = append(problems, rel+" (unexpected)")
+		case err == nil && got != want:
+			problems = append(problems, rel+" (modified)")
+		}
+	}
+
+	for rel, recorded := range s.Sections {
+		doc, err := sections.Load(filepath.Join(workingDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		current := sectionChecksums(doc)
+		for name, checksum := range recorded {
+			if current[name] != checksum {
+				problems = append(problems, fmt.Sprintf("%s section %s (modified)", rel, name))
+			}
+		}
+		for name := range current {
+			if _, ok := recorded[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s section %s (unexpected)", rel, name))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// prune removes the oldest snapshots beyond Keep
+func prune(workingDir string) error {
+	list, err := List(workingDir)
+	if err != nil {
+		return err
+	}
+	for i := Keep; i < len(list); i++ {
+		if err := os.RemoveAll(list[i].dir); err != nil {
+			return fmt.Errorf("failed to remove snapshot %s: %w", list[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// SectionCount returns the number of managed sections recorded across the
+// AI target files
+func (s *Snapshot) SectionCount() int {
+	count := 0
+	for _, recorded := range s.Sections {
+		count += len(recorded)
+	}
+	return count
+}
+
+// sectionChecksums maps each section of doc to a checksum of its content
+func sectionChecksums(doc *sections.Document) map[string]string {
+	sums := make(map[string]string, len(doc.Sections))
+	for _, section := range doc.Sections {
+		sums[section.Name] = sections.Checksum(section.Content)
+	}
+	return sums
+}
+
+// clearDir removes everything in dir except a .git directory
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 of a file
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/lock"
+	"github.com/easel/ddx/internal/sections"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCreateRestore(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "library", "personas", "reviewer.md"), "# Reviewer\n")
+	doc := &sections.Document{Text: "# My project"}
+	doc.Set(sections.Section{Name: "review", Order: 50, Content: "Review carefully"})
+	require.NoError(t, doc.Save(filepath.Join(dir, "CLAUDE.md")))
+	rules := &sections.Document{Sections: append([]sections.Section(nil), doc.Sections...)}
+	rules.Set(sections.Section{Name: "personas", Order: 50, Content: "## Active Personas"})
+	require.NoError(t, rules.Save(filepath.Join(dir, ".cursorrules")))
+	targetFiles := []string{"CLAUDE.md", ".cursorrules", ".github/copilot-instructions.md"}
+
+	s, err := Create(dir, ".ddx/library", "update", lock.Library{Commit: "abc"}, targetFiles)
+	require.NoError(t, err)
+	assert.Contains(t, s.Assets, "personas/reviewer.md")
+	assert.Contains(t, s.Files, ".ddx/config.yaml")
+	assert.FileExists(t, filepath.Join(Root(dir), ".gitignore"))
+
+	// Change everything the snapshot covers, plus the user's own text
+	writeFile(t, filepath.Join(dir, ".ddx", "config.yaml"), "version: \"2.0\"\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "library", "personas", "reviewer.md"), "# Lenient\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "library", "personas", "new.md"), "# New\n")
+	writeFile(t, filepath.Join(dir, lock.FileName), "version: 1\n")
+	doc.Text = "# My renamed project"
+	doc.Set(sections.Section{Name: "review", Order: 50, Content: "Review quickly"})
+	require.NoError(t, doc.Save(filepath.Join(dir, "CLAUDE.md")))
+	cursor := &sections.Document{}
+	cursor.Set(sections.Section{Name: "personas", Order: 50, Content: "## Other Personas"})
+	require.NoError(t, cursor.Save(filepath.Join(dir, ".cursorrules")))
+	require.NoError(t, cursor.Save(filepath.Join(dir, ".github", "copilot-instructions.md")))
+
+	problems, err := s.Verify(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		".cursorrules section personas (modified)",
+		".cursorrules section review (modified)",
+		".ddx/config.yaml (modified)",
+		".ddx/library/personas/new.md (unlocked)",
+		".ddx/library/personas/reviewer.md (modified)",
+		".github/copilot-instructions.md section personas (unexpected)",
+		"CLAUDE.md section review (modified)",
+		"ddx.lock (unexpected)",
+	}, problems)
+
+	loaded, err := Load(dir, s.ID)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Restore(dir))
+	problems, err = loaded.Verify(dir)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	data, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# My renamed project")
+	assert.Contains(t, string(data), "Review carefully")
+	assert.NoFileExists(t, filepath.Join(dir, lock.FileName))
+	cursorData, err := os.ReadFile(filepath.Join(dir, ".cursorrules"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cursorData), "## Active Personas")
+	assert.Contains(t, string(cursorData), "Review carefully")
+	copilot, err := sections.Load(filepath.Join(dir, ".github", "copilot-instructions.md"))
+	require.NoError(t, err)
+	assert.Empty(t, copilot.Sections)
+	assert.Equal(t, 3, loaded.SectionCount())
+}
+
+func TestListPrunes(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < Keep+2; i++ {
+		_, err := Create(dir, ".ddx/library", "update", lock.Library{}, nil)
+		require.NoError(t, err)
+	}
+	list, err := List(dir)
+	require.NoError(t, err)
+	assert.Len(t, list, Keep)
+	assert.False(t, list[0].Created.Before(list[Keep-1].Created))
+
+	_, err = Load(dir, "../escape")
+	assert.ErrorContains(t, err, "invalid snapshot id")
+	_, err = Load(dir, "missing")
+	assert.EqualError(t, err, "snapshot missing not found")
+}
@@ -0,0 +1,77 @@
+// Package starter provides the minimal DDx library compiled into the binary.
+// It is installed when the library repository cannot be reached so that
+// a freshly initialized project always has usable resources.
+package starter
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+//go:embed all:library
+var content embed.FS
+
+// root is the directory within content that holds the library
+const root = "library"
+
+// FS returns the embedded starter library rooted at its top directory
+func FS() fs.FS {
+	sub, err := fs.Sub(content, root)
+	if err != nil {
+		// The embedded root is fixed at build time
+		panic(err)
+	}
+	return sub
+}
+
+// Files returns the slash-separated paths of every file in the starter library
+func Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(FS(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read starter library: %w", err)
+	}
+	return files, nil
+}
+
+// Install writes the starter library into dest, which must not already
+// contain files. It returns the number of files written.
+func Install(dest string) (int, error) {
+	if entries, err := os.ReadDir(dest); err == nil && len(entries) > 0 {
+		return 0, fmt.Errorf("library directory is not empty: %s", dest)
+	}
+
+	files, err := Files()
+	if err != nil {
+		return 0, err
+	}
+
+	library := FS()
+	for _, file := range files {
+		data, err := fs.ReadFile(library, file)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read starter file %s: %w", file, err)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directory for %s: %w", file, err)
+		}
+		if err := atomicfile.WriteFile(target, data, 0644); err != nil {
+			return 0, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return len(files), nil
+}
@@ -0,0 +1,52 @@
+package starter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/persona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFiles tests that the core starter resources are embedded
+func TestFiles(t *testing.T) {
+	files, err := Files()
+	require.NoError(t, err)
+	assert.Contains(t, files, "personas/strict-code-reviewer.md")
+	assert.Contains(t, files, "workflows/helix/workflow.yml")
+	assert.Contains(t, files, "templates/basic-project/README.md")
+	assert.Contains(t, files, "prompts/claude/system-prompts/focused.md")
+}
+
+// TestInstall tests writing the starter library to disk
+func TestInstall(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), ".ddx", "library")
+
+	count, err := Install(dest)
+	require.NoError(t, err)
+	files, err := Files()
+	require.NoError(t, err)
+	assert.Equal(t, len(files), count)
+	assert.FileExists(t, filepath.Join(dest, "workflows", "helix", "workflow.yml"))
+
+	// Embedded personas must parse like library personas
+	loader := persona.NewPersonaLoaderWithDir(filepath.Join(dest, "personas"))
+	personas, err := loader.ListPersonas()
+	require.NoError(t, err)
+	assert.Len(t, personas, 3)
+
+	_, err = Install(dest)
+	assert.ErrorContains(t, err, "not empty")
+}
+
+// TestInstall_EmptyDirectory tests installing into an existing empty directory
+func TestInstall_EmptyDirectory(t *testing.T) {
+	dest := t.TempDir()
+	require.NoError(t, os.MkdirAll(dest, 0755))
+
+	count, err := Install(dest)
+	require.NoError(t, err)
+	assert.Positive(t, count)
+}
@@ -0,0 +1,206 @@
+// Package targets names the AI assistant configuration files that personas
+// and meta-prompts are written to, such as CLAUDE.md and .cursorrules.
+package targets
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultTarget is used when the configuration names no targets
+const DefaultTarget = "claude"
+
+// Target is an assistant configuration file
+type Target struct {
+	Name        string
+	Description string
+	// File is relative to the project root, with forward slashes
+	File string
+	// Header starts the file when ddx creates it
+	Header string
+}
+
+// builtins are the assistants known by name
+var builtins = map[string]Target{
+	"claude": {
+		Name:        "claude",
+		Description: "Claude Code",
+		File:        "CLAUDE.md",
+		Header:      "# CLAUDE.md\n\nThis file provides guidance to Claude when working with code in this repository.",
+	},
+	"cursor": {
+		Name:        "cursor",
+		Description: "Cursor project rules",
+		File:        ".cursorrules",
+	},
+	"copilot": {
+		Name:        "copilot",
+		Description: "GitHub Copilot repository instructions",
+		File:        ".github/copilot-instructions.md",
+	},
+	"windsurf": {
+		Name:        "windsurf",
+		Description: "Windsurf workspace rules",
+		File:        ".windsurfrules",
+	},
+}
+
+// Lookup returns a built-in target by name
+func Lookup(name string) (Target, error) {
+	target, ok := builtins[strings.ToLower(name)]
+	if !ok {
+		return Target{}, fmt.Errorf("unknown AI target %q (available: %s, or a file path)", name, strings.Join(Names(), ", "))
+	}
+	return target, nil
+}
+
+// Names returns the built-in target names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtins returns the built-in targets sorted by name
+func Builtins() []Target {
+	list := make([]Target, 0, len(builtins))
+	for _, name := range Names() {
+		list = append(list, builtins[name])
+	}
+	return list
+}
+
+// Resolve turns configured entries into targets. An entry is a built-in
+// name or a file path relative to the project; no entries means the
+// default target. Entries naming the same file are only returned once.
+func Resolve(entries []string) ([]Target, error) {
+	if len(entries) == 0 {
+		entries = []string{DefaultTarget}
+	}
+
+	var resolved []Target
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		var target Target
+		if strings.ContainsAny(entry, `./\`) {
+			file, err := cleanFile(entry)
+			if err != nil {
+				return nil, err
+			}
+			target = Target{Name: file, Description: "Custom file", File: file, Header: Header(file)}
+		} else {
+			t, err := Lookup(entry)
+			if err != nil {
+				return nil, err
+			}
+			target = t
+		}
+		if seen[target.File] {
+			continue
+		}
+		seen[target.File] = true
+		resolved = append(resolved, target)
+	}
+	return resolved, nil
+}
+
+// Files returns the files of the given targets
+func Files(list []Target) []string {
+	files := make([]string, 0, len(list))
+	for _, target := range list {
+		files = append(files, target.File)
+	}
+	return files
+}
+
+// Header returns the header for a new file, empty for files that are not
+// a built-in target
+func Header(file string) string {
+	file = filepath.ToSlash(file)
+	for _, target := range builtins {
+		if target.File == file || target.File == path.Base(file) {
+			return target.Header
+		}
+	}
+	return ""
+}
+
+// cleanFile validates a custom target path
+func cleanFile(entry string) (string, error) {
+	file := path.Clean(filepath.ToSlash(entry))
+	if path.IsAbs(file) || filepath.IsAbs(entry) || file == ".." || strings.HasPrefix(file, "../") {
+		return "", fmt.Errorf("AI target %q must be a path inside the project", entry)
+	}
+	if file == "." {
+		return "", fmt.Errorf("AI target %q is not a file", entry)
+	}
+	return file, nil
+}
+
+// Rules adjust a project's targets: the Claude target can be given another
+// file, and files in disabled directories are never written
+type Rules struct {
+	// ClaudeFile replaces CLAUDE.md as the claude target's file
+	ClaudeFile string
+	// DisabledPaths are slash-separated globs matched against the directory
+	// of a target file or any of its parents; "." disables every target
+	DisabledPaths []string
+}
+
+// Apply returns the targets with the rules applied, split into those that
+// may be written and those in a disabled directory
+func (r Rules) Apply(list []Target) (enabled, disabled []Target, err error) {
+	claudeFile := ""
+	if r.ClaudeFile != "" {
+		if claudeFile, err = cleanFile(r.ClaudeFile); err != nil {
+			return nil, nil, fmt.Errorf("injection.claude_file: %w", err)
+		}
+	}
+	for _, pattern := range r.DisabledPaths {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" || strings.HasPrefix(pattern, "/") {
+			return nil, nil, fmt.Errorf("invalid injection.disabled_paths entry %q", pattern)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, target := range list {
+		if target.Name == DefaultTarget && claudeFile != "" {
+			target.File = claudeFile
+		}
+		if seen[target.File] {
+			continue
+		}
+		seen[target.File] = true
+		if r.Disabled(target.File) {
+			disabled = append(disabled, target)
+			continue
+		}
+		enabled = append(enabled, target)
+	}
+	return enabled, disabled, nil
+}
+
+// Disabled reports whether a file, relative to the project, is in a
+// disabled directory
+func (r Rules) Disabled(file string) bool {
+	dir := path.Dir(path.Clean(filepath.ToSlash(file)))
+	for _, pattern := range r.DisabledPaths {
+		pattern = path.Clean(strings.TrimSuffix(pattern, "/"))
+		if pattern == "." {
+			return true
+		}
+		for candidate := dir; candidate != "."; candidate = path.Dir(candidate) {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
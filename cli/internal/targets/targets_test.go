@@ -0,0 +1,63 @@
+package targets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	resolved, err := Resolve(nil)
+	require.NoError(t, err)
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "CLAUDE.md", resolved[0].File)
+
+	resolved, err = Resolve([]string{"claude", "Cursor", "copilot", "windsurf", "docs/AGENTS.md", "./CLAUDE.md"})
+	require.NoError(t, err)
+	var files []string
+	for _, target := range resolved {
+		files = append(files, target.File)
+	}
+	assert.Equal(t, []string{"CLAUDE.md", ".cursorrules", ".github/copilot-instructions.md", ".windsurfrules", "docs/AGENTS.md"}, files,
+		"paths naming a built-in file are not repeated")
+	assert.Equal(t, "docs/AGENTS.md", resolved[4].Name)
+
+	_, err = Resolve([]string{"emacs"})
+	assert.ErrorContains(t, err, `unknown AI target "emacs" (available: claude, copilot, cursor, windsurf, or a file path)`)
+	_, err = Resolve([]string{"../outside.md"})
+	assert.ErrorContains(t, err, "must be a path inside the project")
+	_, err = Resolve([]string{"/etc/rules"})
+	assert.ErrorContains(t, err, "must be a path inside the project")
+}
+
+func TestHeader(t *testing.T) {
+	assert.Contains(t, Header("CLAUDE.md"), "# CLAUDE.md")
+	assert.Contains(t, Header("/tmp/project/CLAUDE.md"), "# CLAUDE.md")
+	assert.Empty(t, Header(".cursorrules"))
+	assert.Empty(t, Header("docs/AGENTS.md"))
+}
+
+func TestRules(t *testing.T) {
+	list, err := Resolve([]string{"claude", "copilot", "docs/generated/AGENTS.md", "packages/api/CLAUDE.md"})
+	require.NoError(t, err)
+
+	rules := Rules{ClaudeFile: "AGENTS.md", DisabledPaths: []string{"docs/generated", "packages/*"}}
+	enabled, disabled, err := rules.Apply(list)
+	require.NoError(t, err)
+	require.Len(t, enabled, 2)
+	assert.Equal(t, "AGENTS.md", enabled[0].File)
+	assert.Equal(t, "claude", enabled[0].Name)
+	assert.Equal(t, ".github/copilot-instructions.md", enabled[1].File)
+	assert.Equal(t, []string{"docs/generated/AGENTS.md", "packages/api/CLAUDE.md"}, Files(disabled))
+
+	enabled, disabled, err = Rules{DisabledPaths: []string{"."}}.Apply(list)
+	require.NoError(t, err)
+	assert.Empty(t, enabled)
+	assert.Len(t, disabled, 4)
+
+	_, _, err = Rules{ClaudeFile: "../CLAUDE.md"}.Apply(list)
+	assert.ErrorContains(t, err, "injection.claude_file")
+	_, _, err = Rules{DisabledPaths: []string{"/abs"}}.Apply(list)
+	assert.ErrorContains(t, err, `invalid injection.disabled_paths entry "/abs"`)
+}
@@ -0,0 +1,236 @@
+// Package telemetry counts which ddx commands are used and how they fail,
+// without recording anything that identifies a user or a project. Counts
+// are kept in the user's cache directory and posted in batches to the
+// configured endpoint; nothing but command names, error categories and
+// the ddx version and platform is ever sent.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/userdirs"
+)
+
+// fileName is the pending batch in the ddx cache directory
+const fileName = "telemetry.json"
+
+// FlushInterval is how often batches are sent
+const FlushInterval = 24 * time.Hour
+
+// Error categories
+const (
+	CategoryNetwork    = "network"
+	CategoryAuth       = "auth"
+	CategoryNotFound   = "not_found"
+	CategoryPermission = "permission"
+	CategoryConfig     = "config"
+	CategoryUsage      = "usage"
+	CategoryOther      = "other"
+)
+
+// Batch is the usage counted since the last batch was sent
+type Batch struct {
+	Since   time.Time `json:"since"`
+	Version string    `json:"version"`
+	OS      string    `json:"os"`
+	Arch    string    `json:"arch"`
+	// Commands counts invocations by command path, e.g. "ddx persona load"
+	Commands map[string]int `json:"commands"`
+	// Errors counts failures by command path and error category
+	Errors map[string]map[string]int `json:"errors,omitempty"`
+}
+
+// Store is the telemetry state kept on disk
+type Store struct {
+	LastSent time.Time `json:"last_sent,omitempty"`
+	Batch    Batch     `json:"batch"`
+
+	path string
+}
+
+// DefaultPath returns the store location in the ddx cache directory
+func DefaultPath() (string, error) {
+	cacheDir, err := userdirs.Dir(userdirs.Cache)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, fileName), nil
+}
+
+// Load reads the store at path; a missing store is empty
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read telemetry: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		// A damaged store is started over rather than blocking commands
+		return &Store{path: path}, nil
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	return atomicfile.WriteFile(s.path, data, 0644)
+}
+
+// Remove deletes the store and any pending metrics
+func (s *Store) Remove() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove telemetry: %w", err)
+	}
+	*s = Store{path: s.path}
+	return nil
+}
+
+// Pending reports the number of invocations not yet sent
+func (s *Store) Pending() int {
+	total := 0
+	for _, n := range s.Batch.Commands {
+		total += n
+	}
+	return total
+}
+
+// Record counts an invocation of command by ddx version. A failed
+// invocation is also counted under the category of its error message.
+func (s *Store) Record(version, command, errMessage string, now time.Time) {
+	if s.Batch.Commands == nil {
+		s.Batch = Batch{Since: now.UTC(), Commands: map[string]int{}}
+	}
+	s.Batch.Version, s.Batch.OS, s.Batch.Arch = version, runtime.GOOS, runtime.GOARCH
+	s.Batch.Commands[command]++
+	if errMessage == "" {
+		return
+	}
+	if s.Batch.Errors == nil {
+		s.Batch.Errors = map[string]map[string]int{}
+	}
+	if s.Batch.Errors[command] == nil {
+		s.Batch.Errors[command] = map[string]int{}
+	}
+	s.Batch.Errors[command][Categorize(errMessage)]++
+}
+
+// Due reports whether the pending batch should be sent
+func (s *Store) Due(now time.Time) bool {
+	if s.Pending() == 0 {
+		return false
+	}
+	last := s.LastSent
+	if last.IsZero() {
+		last = s.Batch.Since
+	}
+	return now.Sub(last) >= FlushInterval
+}
+
+// Payload returns the batch as it is sent, with every name redacted
+func (s *Store) Payload() Batch {
+	payload := s.Batch
+	payload.Commands = make(map[string]int, len(s.Batch.Commands))
+	for command, n := range s.Batch.Commands {
+		payload.Commands[Redact(command)] += n
+	}
+	if len(s.Batch.Errors) > 0 {
+		payload.Errors = make(map[string]map[string]int, len(s.Batch.Errors))
+		for command, categories := range s.Batch.Errors {
+			command = Redact(command)
+			if payload.Errors[command] == nil {
+				payload.Errors[command] = map[string]int{}
+			}
+			for category, n := range categories {
+				payload.Errors[command][Redact(category)] += n
+			}
+		}
+	}
+	return payload
+}
+
+// Send posts the pending batch to endpoint and starts a new one. The
+// batch is kept when sending fails so it can be retried.
+func (s *Store) Send(ctx context.Context, client *http.Client, endpoint string, now time.Time) error {
+	data, err := json.Marshal(s.Payload())
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid telemetry endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	s.LastSent, s.Batch = now.UTC(), Batch{}
+	return nil
+}
+
+// categoryPatterns classify error messages, checked in order
+var categoryPatterns = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{CategoryAuth, regexp.MustCompile(`(?i)auth|credential|401|403|permission denied \(publickey`)},
+	{CategoryNetwork, regexp.MustCompile(`(?i)dial tcp|no such host|connection refused|timeout|timed out|network|tls|could not resolve`)},
+	{CategoryPermission, regexp.MustCompile(`(?i)permission denied|operation not permitted|read-only`)},
+	{CategoryNotFound, regexp.MustCompile(`(?i)not found|no such file|does not exist`)},
+	{CategoryUsage, regexp.MustCompile(`(?i)unknown (command|flag|shorthand)|accepts? .*arg|requires? .*arg|required|invalid argument`)},
+	{CategoryConfig, regexp.MustCompile(`(?i)config|schema|yaml`)},
+}
+
+// Categorize maps an error message to one of a fixed set of categories so
+// that no part of the message itself is kept
+func Categorize(message string) string {
+	for _, c := range categoryPatterns {
+		if c.pattern.MatchString(message) {
+			return c.category
+		}
+	}
+	return CategoryOther
+}
+
+// redactions replace values that could identify a user or project
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+|[\w.-]+@[\w.-]+:\S+`), "<url>"},
+	{regexp.MustCompile(`\b(gh[pousr]_|github_pat_|glpat-|sk-|xox[abpr]-)[A-Za-z0-9_-]+|\b[A-Za-z0-9+/_-]{32,}={0,2}`), "<token>"},
+	{regexp.MustCompile(`(~|\.{1,2})?(/[^\s/]+){2,}/?|[A-Za-z]:\\\S*`), "<path>"},
+}
+
+// Redact replaces URLs, tokens and paths in s
+func Redact(s string) string {
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddx", fileName)
+	store, err := Load(path)
+	require.NoError(t, err)
+	assert.Zero(t, store.Pending())
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	store.Record("1.2.0", "ddx persona load", "", start)
+	store.Record("1.2.0", "ddx persona load", "", start)
+	store.Record("1.2.0", "ddx update", `Get "https://github.com/acme/library": dial tcp: no such host`, start)
+	require.NoError(t, store.Save())
+
+	store, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.Pending())
+	assert.Equal(t, map[string]int{"ddx persona load": 2, "ddx update": 1}, store.Batch.Commands)
+	assert.Equal(t, map[string]map[string]int{"ddx update": {CategoryNetwork: 1}}, store.Batch.Errors)
+	assert.False(t, store.Due(start.Add(time.Hour)))
+	assert.True(t, store.Due(start.Add(FlushInterval)))
+
+	var received Batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+	sent := start.Add(FlushInterval)
+	require.NoError(t, store.Send(context.Background(), server.Client(), server.URL, sent))
+	assert.Equal(t, 2, received.Commands["ddx persona load"])
+	assert.Equal(t, "1.2.0", received.Version)
+	assert.Zero(t, store.Pending())
+	assert.True(t, store.LastSent.Equal(sent))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	store.Record("1.2.0", "ddx list", "", sent)
+	assert.Error(t, store.Send(context.Background(), failing.Client(), failing.URL, sent))
+	assert.Equal(t, 1, store.Pending(), "the batch is kept for a retry")
+
+	require.NoError(t, store.Remove())
+	assert.NoFileExists(t, path)
+}
+
+func TestCategorize(t *testing.T) {
+	tests := map[string]string{
+		"authentication failed for github.com":          CategoryAuth,
+		"dial tcp: lookup api.github.com: no such host": CategoryNetwork,
+		"open CLAUDE.md: permission denied":             CategoryPermission,
+		"workflow 'helix' not found":                    CategoryNotFound,
+		`unknown flag: --fast`:                          CategoryUsage,
+		"failed to parse config YAML":                   CategoryConfig,
+		"something went wrong":                          CategoryOther,
+	}
+	for message, want := range tests {
+		assert.Equal(t, want, Categorize(message), message)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := map[string]string{
+		"ddx persona load": "ddx persona load",
+		"clone https://github.com/acme/private.git failed": "clone <url> failed",
+		"git@github.com:acme/private.git":                  "<url>",
+		"token ghp_abcdefABCDEF1234567890":                 "token <token>",
+		"open /home/alice/project/.ddx/config.yaml":        "open <path>",
+		`C:\Users\bob\project`:                             "<path>",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, Redact(input), input)
+	}
+}
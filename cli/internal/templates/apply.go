@@ -0,0 +1,273 @@
+package templates
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/assets"
+	"github.com/easel/ddx/internal/transaction"
+	"github.com/easel/ddx/internal/variables"
+)
+
+// ApplyResult lists the files written when a template is applied, as
+// slash-separated paths relative to the destination
+type ApplyResult struct {
+	Created []string
+	// Modified files already existed and were overwritten
+	Modified []string
+	// Skipped files already existed and were left unchanged
+	Skipped []string
+}
+
+// Values returns the manifest's variables filled from values, falling back
+// to each variable's default. Required variables without a value are an
+// error.
+func (m *Manifest) Values(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(values)+len(m.Variables))
+	for name, value := range values {
+		resolved[name] = value
+	}
+
+	var missing []string
+	for _, v := range m.Variables {
+		if resolved[v.Name] != "" {
+			continue
+		}
+		if v.Default != "" {
+			resolved[v.Name] = v.Default
+			continue
+		}
+		if v.Required {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("template %s requires variables: %s", m.Name, strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// FileStatus is what applying a template does to one file
+type FileStatus string
+
+const (
+	// FileCreated files do not exist in the destination yet
+	FileCreated FileStatus = "created"
+	// FileModified files exist and are overwritten with the template's
+	FileModified FileStatus = "modified"
+	// FileSkipped files exist and are kept
+	FileSkipped FileStatus = "skipped"
+)
+
+// PlannedFile is one file of a template rendered for a destination
+type PlannedFile struct {
+	// Path is slash-separated and relative to the destination
+	Path   string
+	Status FileStatus
+	// Current is the file's content in the destination, empty when it
+	// does not exist
+	Current string
+	// Content is the rendered file. Assets are only fetched when the plan
+	// is applied, so it is empty for them.
+	Content string
+	// Asset is set when the file's content is a library asset
+	Asset *assets.Pointer
+	// Differs reports whether an existing file differs from the template
+	Differs bool
+
+	mode fs.FileMode
+}
+
+// ApplyPlan is a template rendered in memory for a destination, so the
+// changes can be reviewed before any file is written
+type ApplyPlan struct {
+	Manifest *Manifest
+	Dest     string
+	Files    []PlannedFile
+}
+
+// Plan renders the template in dir for dest, substituting {{name}}
+// placeholders in file contents and paths. The manifest itself is not
+// part of the plan. Files that already exist in dest are kept unless
+// overwrite is set.
+func Plan(dir, dest string, values map[string]string, overwrite bool) (*ApplyPlan, error) {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := manifest.Values(values)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ApplyPlan{Manifest: manifest, Dest: dest}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || rel == ManifestFile {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rendered, _ := variables.Render(filepath.ToSlash(rel), resolved)
+		file := PlannedFile{Status: FileCreated, mode: info.Mode().Perm()}
+		if assets.IsPointer(rendered) {
+			if file.Asset, err = assets.LoadPointer(path, filepath.ToSlash(rel)); err != nil {
+				return err
+			}
+			rendered = strings.TrimSuffix(rendered, assets.PointerSuffix)
+		} else {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read template file %s: %w", rel, err)
+			}
+			file.Content, _ = variables.Render(string(data), resolved)
+		}
+		file.Path = rendered
+
+		current, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(rendered)))
+		if err == nil {
+			file.Current = string(current)
+			if file.Asset != nil {
+				sum := sha256.Sum256(current)
+				file.Differs = hex.EncodeToString(sum[:]) != file.Asset.SHA256
+			} else {
+				file.Differs = file.Current != file.Content
+			}
+			file.Status = FileSkipped
+			if overwrite && file.Differs {
+				file.Status = FileModified
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", rendered, err)
+		}
+		plan.Files = append(plan.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", manifest.Name, err)
+	}
+	return plan, nil
+}
+
+// Apply copies the template in dir into dest, keeping files that already
+// exist in dest. See Plan.
+func Apply(ctx context.Context, dir, dest string, values map[string]string) (*ApplyResult, error) {
+	plan, err := Plan(dir, dest, values, false)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Apply(ctx)
+}
+
+// Apply writes the created and modified files as one transaction: either
+// all of them are written or, when any fails, none. Asset pointers are
+// replaced by their content; the assets are fetched in parallel before any
+// file is written. When ctx is cancelled, Apply stops before the next file.
+func (p *ApplyPlan) Apply(ctx context.Context) (*ApplyResult, error) {
+	tx := transaction.Begin(p.Dest)
+	result, err := p.Stage(ctx, tx)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return nil, tx.Fail("applying template "+p.Manifest.Name, err)
+	}
+	return result, nil
+}
+
+// Stage stages the created and modified files in tx, for an operation
+// that applies the template as one of its steps. See Apply.
+func (p *ApplyPlan) Stage(ctx context.Context, tx *transaction.Tx) (*ApplyResult, error) {
+	var needed []*assets.Pointer
+	for _, file := range p.Files {
+		if file.Asset != nil && file.Status != FileSkipped {
+			needed = append(needed, file.Asset)
+		}
+	}
+	store, err := prefetchAssets(ctx, needed)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+	for _, file := range p.Files {
+		if file.Status == FileSkipped {
+			result.Skipped = append(result.Skipped, file.Path)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := p.stage(tx, store, file); err != nil {
+			return nil, err
+		}
+		if file.Status == FileModified {
+			result.Modified = append(result.Modified, file.Path)
+		} else {
+			result.Created = append(result.Created, file.Path)
+		}
+	}
+	return result, nil
+}
+
+// stage stages one planned file in the destination
+func (p *ApplyPlan) stage(tx *transaction.Tx, store *assets.Store, file PlannedFile) error {
+	target := filepath.Join(p.Dest, filepath.FromSlash(file.Path))
+	if file.Asset == nil {
+		if err := tx.WriteFile(target, []byte(file.Content), file.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		return nil
+	}
+	path, _, err := store.Fetch(file.Asset)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	if err := tx.Write(target, file.mode, func(w io.Writer) error {
+		_, err := io.Copy(w, src)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// prefetchAssets downloads the assets a template will write. It returns
+// the store holding them, or nil when there are none.
+func prefetchAssets(ctx context.Context, needed []*assets.Pointer) (*assets.Store, error) {
+	if len(needed) == 0 {
+		return nil, nil
+	}
+
+	store, err := assets.DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.FetchAll(ctx, needed, assets.FetchOptions{}); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
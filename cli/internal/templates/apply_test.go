@@ -0,0 +1,154 @@
+package templates
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTemplate creates a template directory from a map of relative paths
+func writeTemplate(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestApply(t *testing.T) {
+	dir := writeTemplate(t, map[string]string{
+		ManifestFile: `name: service
+variables:
+  - name: project_name
+    required: true
+  - name: license
+    default: MIT
+`,
+		"README.md":                    "# {{project_name}}\n\nLicensed under {{ license }}.\n",
+		"cmd/{{project_name}}/main.go": "package main\n",
+		"docs/notes.md":                "notes\n",
+	})
+
+	t.Run("renders files and paths", func(t *testing.T) {
+		dest := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dest, "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "docs", "notes.md"), []byte("mine\n"), 0644))
+
+		result, err := Apply(context.Background(), dir, dest, map[string]string{"project_name": "demo"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"README.md", "cmd/demo/main.go"}, result.Created)
+		assert.Equal(t, []string{"docs/notes.md"}, result.Skipped)
+
+		readme, err := os.ReadFile(filepath.Join(dest, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# demo\n\nLicensed under MIT.\n", string(readme))
+
+		notes, err := os.ReadFile(filepath.Join(dest, "docs", "notes.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "mine\n", string(notes), "existing files must be kept")
+
+		assert.NoFileExists(t, filepath.Join(dest, ManifestFile))
+	})
+
+	t.Run("missing required variable", func(t *testing.T) {
+		_, err := Apply(context.Background(), dir, t.TempDir(), nil)
+		assert.ErrorContains(t, err, "requires variables: project_name")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		dest := t.TempDir()
+		_, err := Apply(ctx, dir, dest, map[string]string{"project_name": "demo"})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, filepath.Join(dest, "README.md"))
+	})
+
+	t.Run("fetches asset pointers", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		src := filepath.Join(t.TempDir(), "logo.png")
+		require.NoError(t, os.WriteFile(src, []byte("PNG"), 0644))
+		hash := sha256.Sum256([]byte("PNG"))
+		assetDir := writeTemplate(t, map[string]string{
+			ManifestFile: "name: brand\n",
+			"assets/logo.png.ddx-asset": fmt.Sprintf("sha256: %s\nurl: file://%s\n",
+				hex.EncodeToString(hash[:]), filepath.ToSlash(src)),
+		})
+
+		dest := t.TempDir()
+		result, err := Apply(context.Background(), assetDir, dest, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assets/logo.png"}, result.Created)
+		data, err := os.ReadFile(filepath.Join(dest, "assets", "logo.png"))
+		require.NoError(t, err)
+		assert.Equal(t, "PNG", string(data))
+	})
+
+	t.Run("failed asset download writes nothing", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		brokenDir := writeTemplate(t, map[string]string{
+			ManifestFile: "name: brand\n",
+			"README.md":  "# Brand\n",
+			"assets/logo.png.ddx-asset": fmt.Sprintf("sha256: %064d\nurl: file://%s/missing.png\n",
+				0, filepath.ToSlash(t.TempDir())),
+		})
+
+		dest := t.TempDir()
+		_, err := Apply(context.Background(), brokenDir, dest, nil)
+		assert.ErrorContains(t, err, "asset assets/logo.png")
+		assert.NoFileExists(t, filepath.Join(dest, "README.md"))
+	})
+}
+
+func TestPlan(t *testing.T) {
+	dir := writeTemplate(t, map[string]string{
+		ManifestFile:    "name: service\n",
+		"README.md":     "# Service\n",
+		"Makefile":      "build:\n\tgo build\n",
+		"docs/notes.md": "notes\n",
+	})
+	dest := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "Makefile"), []byte("build:\n\tmake all\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dest, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "docs", "notes.md"), []byte("notes\n"), 0644))
+
+	statuses := func(plan *ApplyPlan) map[string]FileStatus {
+		got := make(map[string]FileStatus)
+		for _, f := range plan.Files {
+			got[f.Path] = f.Status
+		}
+		return got
+	}
+
+	plan, err := Plan(dir, dest, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FileStatus{
+		"README.md":     FileCreated,
+		"Makefile":      FileSkipped,
+		"docs/notes.md": FileSkipped,
+	}, statuses(plan))
+	assert.NoFileExists(t, filepath.Join(dest, "README.md"), "planning writes nothing")
+
+	plan, err = Plan(dir, dest, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, FileModified, statuses(plan)["Makefile"])
+	assert.Equal(t, FileSkipped, statuses(plan)["docs/notes.md"], "identical files are not rewritten")
+
+	result, err := plan.Apply(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, result.Created)
+	assert.Equal(t, []string{"Makefile"}, result.Modified)
+	makefile, err := os.ReadFile(filepath.Join(dest, "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t, "build:\n\tgo build\n", string(makefile))
+}
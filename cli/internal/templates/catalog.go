@@ -0,0 +1,80 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/assets"
+)
+
+// Template is a template found in a library
+type Template struct {
+	Name     string
+	Dir      string
+	Manifest *Manifest
+}
+
+// Available lists the templates in a library's templates directory, sorted
+// by name. Directories without a manifest are not templates.
+func Available(dir string) ([]Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var found []Template
+	for _, entry := range entries {
+		templateDir := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(templateDir, ManifestFile)); err != nil {
+			continue
+		}
+		manifest, err := LoadManifest(templateDir)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, Template{Name: entry.Name(), Dir: templateDir, Manifest: manifest})
+	}
+	return found, nil
+}
+
+// Files lists the files the template in dir creates, as slash-separated
+// paths with their {{name}} placeholders unfilled, sorted. Asset pointers
+// are listed under the name of the file they become; the manifest is left
+// out as it is when applying.
+func Files(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || rel == ManifestFile {
+			return nil
+		}
+		files = append(files, strings.TrimSuffix(filepath.ToSlash(rel), assets.PointerSuffix))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template files: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// NeedsValue reports whether the variable must be given a value when the
+// template is applied: it is required and has no default
+func (v Variable) NeedsValue() bool {
+	return v.Required && v.Default == ""
+}
@@ -0,0 +1,54 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailable(t *testing.T) {
+	dir := t.TempDir()
+	for name, manifest := range map[string]string{
+		"service": "name: service\ndescription: Go service\n",
+		"cli":     "name: cli\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, ManifestFile), []byte(manifest), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "no-manifest"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Templates\n"), 0644))
+
+	found, err := Available(dir)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "cli", found[0].Name)
+	assert.Equal(t, "service", found[1].Name)
+	assert.Equal(t, "Go service", found[1].Manifest.Description)
+	assert.Equal(t, filepath.Join(dir, "service"), found[1].Dir)
+
+	found, err = Available(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestFiles(t *testing.T) {
+	dir := writeTemplate(t, map[string]string{
+		ManifestFile:                   "name: service\n",
+		"README.md":                    "# {{project_name}}\n",
+		"cmd/{{project_name}}/main.go": "package main\n",
+		"assets/logo.png.ddx-asset":    "url: https://example.com/logo.png\nsha256: abc\n",
+	})
+
+	files, err := Files(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "assets/logo.png", "cmd/{{project_name}}/main.go"}, files)
+}
+
+func TestNeedsValue(t *testing.T) {
+	assert.True(t, Variable{Name: "project_name", Required: true}.NeedsValue())
+	assert.False(t, Variable{Name: "license", Required: true, Default: "MIT"}.NeedsValue())
+	assert.False(t, Variable{Name: "port"}.NeedsValue())
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+)
+
+// HookRunner runs template hooks after verifying their prerequisites
+type HookRunner struct {
+	checker *Checker
+	out     io.Writer
+}
+
+// NewHookRunner creates a hook runner that writes hook output to out
+func NewHookRunner(out io.Writer) *HookRunner {
+	return NewHookRunnerWithChecker(NewChecker(), out)
+}
+
+// NewHookRunnerWithChecker creates a hook runner with a custom checker
+func NewHookRunnerWithChecker(checker *Checker, out io.Writer) *HookRunner {
+	return &HookRunner{checker: checker, out: out}
+}
+
+// RunPostApply runs the manifest's post-apply hooks in dir. The template's
+// requirements and those of every hook are checked before any hook runs,
+// so a missing tool is reported up front instead of part-way through.
+func (r *HookRunner) RunPostApply(ctx context.Context, manifest *Manifest, dir string) error {
+	hooks := manifest.Hooks.PostApply
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	reqs := append([]Requirement{}, manifest.Requires...)
+	for _, hook := range hooks {
+		reqs = append(reqs, hook.Requires...)
+	}
+	if err := r.checker.Check(ctx, reqs); err != nil {
+		return fmt.Errorf("cannot run post-apply hooks for %s: %w", manifest.Name, err)
+	}
+
+	for _, hook := range hooks {
+		_, _ = fmt.Fprintf(r.out, "▶ %s\n", hook.hookName())
+		cmd := shellCommand(ctx, hook.Run)
+		cmd.Dir = dir
+		cmd.Stdout = r.out
+		cmd.Stderr = r.out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("post-apply hook %q failed: %w", hook.hookName(), err)
+		}
+	}
+	return nil
+}
+
+// shellCommand runs a hook command line through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,82 @@
+// Package templates loads DDx project template manifests and runs the
+// hooks they declare.
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest at the root of a template
+const ManifestFile = "template.yml"
+
+// Manifest describes a template from its template.yml
+type Manifest struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Variables   []Variable    `yaml:"variables,omitempty"`
+	Requires    []Requirement `yaml:"requires,omitempty"`
+	Hooks       Hooks         `yaml:"hooks,omitempty"`
+}
+
+// Variable is a value substituted into the template when it is applied
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+}
+
+// Hooks are commands run at points in the template lifecycle
+type Hooks struct {
+	PostApply []Hook `yaml:"post_apply,omitempty"`
+}
+
+// Hook is a shell command with the tools it needs to succeed
+type Hook struct {
+	Name     string        `yaml:"name"`
+	Run      string        `yaml:"run"`
+	Requires []Requirement `yaml:"requires,omitempty"`
+}
+
+// LoadManifest reads the manifest of the template in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid template manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks that the manifest is complete
+func (m *Manifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	for i, hook := range m.Hooks.PostApply {
+		if hook.Run == "" {
+			return fmt.Errorf("post_apply hook %d has no run command", i+1)
+		}
+	}
+	return nil
+}
+
+// hookName returns a label for the hook in messages
+func (h Hook) hookName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Run
+}
@@ -0,0 +1,229 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Requirement is a tool that must be installed, optionally at a minimum
+// or exact version, written as "node >= 20" or "go >= 1.22"
+type Requirement struct {
+	Tool     string
+	Operator string
+	Version  string
+}
+
+var (
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9_.+-]+)\s*(?:(>=|<=|==|=|>|<)\s*v?([0-9]+(?:\.[0-9]+)*))?$`)
+	versionPattern     = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)+|[0-9]+`)
+)
+
+// versionArgs are the arguments that print a tool's version, for tools that
+// do not accept --version
+var versionArgs = map[string][]string{
+	"go":   {"version"},
+	"java": {"-version"},
+}
+
+// versionTimeout bounds how long a tool may take to report its version
+const versionTimeout = 10 * time.Second
+
+// ParseRequirement parses a requirement such as "node >= 20"
+func ParseRequirement(value string) (Requirement, error) {
+	match := requirementPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return Requirement{}, fmt.Errorf("invalid requirement %q (expected \"tool\" or \"tool >= version\")", value)
+	}
+
+	req := Requirement{Tool: match[1], Operator: match[2], Version: match[3]}
+	if req.Operator == "==" {
+		req.Operator = "="
+	}
+	return req, nil
+}
+
+// UnmarshalYAML parses a requirement from its string form
+func (r *Requirement) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	req, err := ParseRequirement(value)
+	if err != nil {
+		return err
+	}
+	*r = req
+	return nil
+}
+
+// MarshalYAML writes a requirement in its string form
+func (r Requirement) MarshalYAML() (interface{}, error) {
+	return r.String(), nil
+}
+
+// String returns the requirement as written in a manifest
+func (r Requirement) String() string {
+	if r.Operator == "" {
+		return r.Tool
+	}
+	return fmt.Sprintf("%s %s %s", r.Tool, r.Operator, r.Version)
+}
+
+// Satisfied reports whether an installed version meets the requirement
+func (r Requirement) Satisfied(installed string) bool {
+	if r.Operator == "" {
+		return true
+	}
+
+	cmp := compareVersions(installed, r.Version)
+	switch r.Operator {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return versionMatches(installed, r.Version)
+	}
+}
+
+// compareVersions compares dotted numeric versions, treating missing
+// components as zero
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionMatches reports whether installed is the required version, where
+// "1.22" matches any 1.22.x release
+func versionMatches(installed, required string) bool {
+	return installed == required || strings.HasPrefix(installed, required+".")
+}
+
+// UnmetRequirement records why a requirement is not satisfied
+type UnmetRequirement struct {
+	Requirement Requirement
+	// Installed is the detected version, empty when the tool is missing
+	Installed string
+	Reason    string
+}
+
+// PrerequisiteError lists requirements that are not met
+type PrerequisiteError struct {
+	Unmet []UnmetRequirement
+}
+
+// Error implements the error interface
+func (e *PrerequisiteError) Error() string {
+	var b strings.Builder
+	b.WriteString("missing prerequisites:")
+	for _, unmet := range e.Unmet {
+		fmt.Fprintf(&b, "\n  - %s: %s", unmet.Requirement, unmet.Reason)
+	}
+	return b.String()
+}
+
+// ToolProbe reports the installed version of a tool. It returns found as
+// false when the tool is not on PATH, and an empty version when the tool
+// is present but its version cannot be determined.
+type ToolProbe func(ctx context.Context, tool string) (version string, found bool, err error)
+
+// Checker verifies requirements against the tools available on this machine
+type Checker struct {
+	probe    ToolProbe
+	versions map[string]string
+}
+
+// NewChecker creates a checker that probes tools on PATH
+func NewChecker() *Checker {
+	return NewCheckerWithProbe(probeTool)
+}
+
+// NewCheckerWithProbe creates a checker with a custom tool probe
+func NewCheckerWithProbe(probe ToolProbe) *Checker {
+	return &Checker{probe: probe, versions: make(map[string]string)}
+}
+
+// Check returns a PrerequisiteError describing every requirement that is
+// not met, or nil when all are satisfied
+func (c *Checker) Check(ctx context.Context, reqs []Requirement) error {
+	var unmet []UnmetRequirement
+	for _, req := range reqs {
+		version, found, err := c.version(ctx, req.Tool)
+		switch {
+		case !found:
+			unmet = append(unmet, UnmetRequirement{Requirement: req, Reason: req.Tool + " is not installed or not on PATH"})
+		case req.Operator == "":
+			// Presence is enough
+		case err != nil || version == "":
+			unmet = append(unmet, UnmetRequirement{Requirement: req, Reason: "could not determine installed version"})
+		case !req.Satisfied(version):
+			unmet = append(unmet, UnmetRequirement{
+				Requirement: req,
+				Installed:   version,
+				Reason:      fmt.Sprintf("found %s %s", req.Tool, version),
+			})
+		}
+	}
+
+	if len(unmet) > 0 {
+		return &PrerequisiteError{Unmet: unmet}
+	}
+	return nil
+}
+
+// version probes a tool once and caches the result
+func (c *Checker) version(ctx context.Context, tool string) (string, bool, error) {
+	if version, ok := c.versions[tool]; ok {
+		return version, true, nil
+	}
+	version, found, err := c.probe(ctx, tool)
+	if found && err == nil {
+		c.versions[tool] = version
+	}
+	return version, found, err
+}
+
+// probeTool looks a tool up on PATH and parses its version output
+func probeTool(ctx context.Context, tool string) (string, bool, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return "", false, nil
+	}
+
+	args, ok := versionArgs[tool]
+	if !ok {
+		args = []string{"--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", true, fmt.Errorf("running %s %s: %w", tool, strings.Join(args, " "), err)
+	}
+	return versionPattern.FindString(string(output)), true, nil
+}
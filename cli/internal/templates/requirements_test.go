@@ -0,0 +1,160 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Requirement
+		wantErr bool
+	}{
+		{"node", Requirement{Tool: "node"}, false},
+		{"node >= 20", Requirement{Tool: "node", Operator: ">=", Version: "20"}, false},
+		{"go>=1.22", Requirement{Tool: "go", Operator: ">=", Version: "1.22"}, false},
+		{"python3 == v3.11", Requirement{Tool: "python3", Operator: "=", Version: "3.11"}, false},
+		{"node >= twenty", Requirement{}, true},
+		{"", Requirement{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRequirement(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequirementSatisfied(t *testing.T) {
+	tests := []struct {
+		req       string
+		installed string
+		want      bool
+	}{
+		{"node >= 20", "20.11.1", true},
+		{"node >= 20", "18.19.0", false},
+		{"go >= 1.22", "1.22.3", true},
+		{"go >= 1.22", "1.9.0", false},
+		{"go > 1.22", "1.22", false},
+		{"go < 2", "1.22", true},
+		{"go = 1.22", "1.22.5", true},
+		{"go = 1.22", "1.23.0", false},
+		{"node", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.req+" "+tt.installed, func(t *testing.T) {
+			req, err := ParseRequirement(tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.Satisfied(tt.installed))
+		})
+	}
+}
+
+// fakeProbe reports versions from a map; tools not in the map are missing
+func fakeProbe(versions map[string]string) ToolProbe {
+	return func(ctx context.Context, tool string) (string, bool, error) {
+		version, ok := versions[tool]
+		return version, ok, nil
+	}
+}
+
+func TestCheckerCheck(t *testing.T) {
+	checker := NewCheckerWithProbe(fakeProbe(map[string]string{"node": "18.19.0", "go": "1.22.3"}))
+
+	reqs := []Requirement{
+		{Tool: "go", Operator: ">=", Version: "1.22"},
+		{Tool: "node", Operator: ">=", Version: "20"},
+		{Tool: "pnpm"},
+	}
+	err := checker.Check(context.Background(), reqs)
+
+	var prereqErr *PrerequisiteError
+	require.True(t, errors.As(err, &prereqErr))
+	require.Len(t, prereqErr.Unmet, 2)
+	assert.Equal(t, "node", prereqErr.Unmet[0].Requirement.Tool)
+	assert.Equal(t, "18.19.0", prereqErr.Unmet[0].Installed)
+	assert.Equal(t, "pnpm", prereqErr.Unmet[1].Requirement.Tool)
+	assert.Contains(t, err.Error(), "node >= 20: found node 18.19.0")
+	assert.Contains(t, err.Error(), "pnpm: pnpm is not installed or not on PATH")
+
+	assert.NoError(t, checker.Check(context.Background(), reqs[:1]))
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `name: go-service
+description: Go service
+requires:
+  - go >= 1.22
+hooks:
+  post_apply:
+    - name: Tidy modules
+      run: go mod tidy
+    - name: Install web dependencies
+      run: npm install
+      requires: ["node >= 20", npm]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0644))
+
+	m, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []Requirement{{Tool: "go", Operator: ">=", Version: "1.22"}}, m.Requires)
+	require.Len(t, m.Hooks.PostApply, 2)
+	assert.Equal(t, []Requirement{{Tool: "node", Operator: ">=", Version: "20"}, {Tool: "npm"}}, m.Hooks.PostApply[1].Requires)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFile), []byte("name: bad\nrequires: [\"node >= latest\"]\n"), 0644))
+	_, err = LoadManifest(dir)
+	assert.Error(t, err)
+}
+
+func TestRunPostApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use POSIX shell syntax")
+	}
+
+	manifest := &Manifest{
+		Name: "example",
+		Hooks: Hooks{PostApply: []Hook{
+			{Name: "first", Run: "touch first"},
+			{Name: "second", Run: "touch second", Requires: []Requirement{{Tool: "node", Operator: ">=", Version: "20"}}},
+		}},
+	}
+
+	t.Run("missing prerequisite stops before any hook runs", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		runner := NewHookRunnerWithChecker(NewCheckerWithProbe(fakeProbe(map[string]string{"node": "18.0.0"})), &out)
+
+		err := runner.RunPostApply(context.Background(), manifest, dir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "node >= 20: found node 18.0.0")
+		assert.NoFileExists(t, filepath.Join(dir, "first"))
+	})
+
+	t.Run("runs hooks in order", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		runner := NewHookRunnerWithChecker(NewCheckerWithProbe(fakeProbe(map[string]string{"node": "20.1.0"})), &out)
+
+		require.NoError(t, runner.RunPostApply(context.Background(), manifest, dir))
+		assert.FileExists(t, filepath.Join(dir, "first"))
+		assert.FileExists(t, filepath.Join(dir, "second"))
+		assert.Contains(t, out.String(), "▶ first")
+	})
+}
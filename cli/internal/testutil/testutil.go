@@ -0,0 +1,235 @@
+// Package testutil builds the libraries and projects that ddx commands
+// work on, so a test states what it needs instead of writing each file:
+//
+//	lib := testutil.NewLibrary(t, "").
+//		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}})
+//	project := testutil.NewProject(t).
+//		WithLibrary(lib).
+//		Bind("code-reviewer", "strict-reviewer").
+//		Build()
+//
+// Builders fail the test on any error, so their methods can be chained.
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PersonaSpec describes a persona file. Description defaults to one
+// derived from the name and Body to a heading.
+type PersonaSpec struct {
+	Name        string
+	Roles       []string
+	Description string
+	Tags        []string
+	Body        string
+}
+
+// Content returns the persona as a markdown file with YAML frontmatter
+func (p PersonaSpec) Content() string {
+	front := struct {
+		Name        string   `yaml:"name"`
+		Roles       []string `yaml:"roles"`
+		Description string   `yaml:"description"`
+		Tags        []string `yaml:"tags,omitempty"`
+	}{p.Name, p.Roles, p.Description, p.Tags}
+	if front.Description == "" {
+		front.Description = "The " + strings.ReplaceAll(p.Name, "-", " ") + " persona"
+	}
+	data, _ := yaml.Marshal(front)
+
+	body := p.Body
+	if body == "" {
+		body = "# " + p.Name + "\n"
+	}
+	return "---\n" + string(data) + "---\n\n" + body
+}
+
+// writeFile creates a file and its directories, failing the test on error
+func writeFile(t testing.TB, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// LibraryBuilder writes a DDx library: personas, prompts, templates and
+// workflows in the directories ddx reads them from
+type LibraryBuilder struct {
+	t   testing.TB
+	dir string
+}
+
+// NewLibrary starts a library in dir, or in a new temporary directory when
+// dir is empty
+func NewLibrary(t testing.TB, dir string) *LibraryBuilder {
+	t.Helper()
+	if dir == "" {
+		dir = t.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create library: %v", err)
+	}
+	return &LibraryBuilder{t: t, dir: dir}
+}
+
+// Dir returns the library directory
+func (b *LibraryBuilder) Dir() string {
+	return b.dir
+}
+
+// File writes a file at a slash-separated path in the library
+func (b *LibraryBuilder) File(path, content string) *LibraryBuilder {
+	b.t.Helper()
+	writeFile(b.t, filepath.Join(b.dir, filepath.FromSlash(path)), content)
+	return b
+}
+
+// Persona adds personas/<name>.md
+func (b *LibraryBuilder) Persona(spec PersonaSpec) *LibraryBuilder {
+	b.t.Helper()
+	return b.File("personas/"+spec.Name+".md", spec.Content())
+}
+
+// Prompt adds a prompt at a path under prompts/, such as "claude/review.md"
+func (b *LibraryBuilder) Prompt(path, content string) *LibraryBuilder {
+	b.t.Helper()
+	return b.File("prompts/"+path, content)
+}
+
+// Template adds templates/<name> with files keyed by their path in it
+func (b *LibraryBuilder) Template(name string, files map[string]string) *LibraryBuilder {
+	b.t.Helper()
+	for path, content := range files {
+		b.File("templates/"+name+"/"+path, content)
+	}
+	return b
+}
+
+// Workflow adds workflows/<name>/workflow.yml. An empty definition writes
+// one with just the name.
+func (b *LibraryBuilder) Workflow(name, definition string) *LibraryBuilder {
+	b.t.Helper()
+	if definition == "" {
+		definition = fmt.Sprintf("name: %s\nversion: 1.0.0\n", name)
+	}
+	return b.File("workflows/"+name+"/workflow.yml", definition)
+}
+
+// ProjectBuilder writes a DDx project: .ddx/config.yaml, its library and
+// project files. The configuration is written by Build.
+type ProjectBuilder struct {
+	t        testing.TB
+	dir      string
+	library  string
+	bindings map[string]string
+	active   []string
+	extra    map[string]any
+}
+
+// NewProject starts a project in a new temporary directory with its
+// library at .ddx/library
+func NewProject(t testing.TB) *ProjectBuilder {
+	t.Helper()
+	return &ProjectBuilder{t: t, dir: t.TempDir(), library: ".ddx/library", bindings: map[string]string{}, extra: map[string]any{}}
+}
+
+// Dir returns the project directory
+func (p *ProjectBuilder) Dir() string {
+	return p.dir
+}
+
+// Library returns a builder for the project's library directory
+func (p *ProjectBuilder) Library() *LibraryBuilder {
+	p.t.Helper()
+	return NewLibrary(p.t, p.LibraryDir())
+}
+
+// LibraryDir returns the absolute path of the project's library
+func (p *ProjectBuilder) LibraryDir() string {
+	if filepath.IsAbs(p.library) {
+		return p.library
+	}
+	return filepath.Join(p.dir, filepath.FromSlash(p.library))
+}
+
+// WithLibrary points library.path at a library built elsewhere
+func (p *ProjectBuilder) WithLibrary(lib *LibraryBuilder) *ProjectBuilder {
+	p.library = lib.Dir()
+	return p
+}
+
+// Bind binds a persona to a role in persona_bindings
+func (p *ProjectBuilder) Bind(role, persona string) *ProjectBuilder {
+	p.bindings[role] = persona
+	return p
+}
+
+// Activate adds workflows to workflows.active
+func (p *ProjectBuilder) Activate(workflows ...string) *ProjectBuilder {
+	p.active = append(p.active, workflows...)
+	return p
+}
+
+// Set sets any other top-level configuration section, such as "ai" or
+// "injection", to a value that marshals to it
+func (p *ProjectBuilder) Set(key string, value any) *ProjectBuilder {
+	p.extra[key] = value
+	return p
+}
+
+// File writes a file at a slash-separated path in the project
+func (p *ProjectBuilder) File(path, content string) *ProjectBuilder {
+	p.t.Helper()
+	writeFile(p.t, filepath.Join(p.dir, filepath.FromSlash(path)), content)
+	return p
+}
+
+// Persona adds a project persona in .ddx/personas, which takes precedence
+// over the library's
+func (p *ProjectBuilder) Persona(spec PersonaSpec) *ProjectBuilder {
+	p.t.Helper()
+	return p.File(".ddx/personas/"+spec.Name+".md", spec.Content())
+}
+
+// Build writes .ddx/config.yaml and creates the library directory,
+// returning the project directory
+func (p *ProjectBuilder) Build() string {
+	p.t.Helper()
+	cfg := map[string]any{}
+	for key, value := range p.extra {
+		cfg[key] = value
+	}
+	cfg["version"] = "1.0"
+	cfg["library"] = map[string]any{
+		"path": p.library,
+		"repository": map[string]string{
+			"url":    "https://github.com/easel/ddx-library",
+			"branch": "main",
+		},
+	}
+	if len(p.bindings) > 0 {
+		cfg["persona_bindings"] = p.bindings
+	}
+	if len(p.active) > 0 {
+		cfg["workflows"] = map[string]any{"active": p.active}
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		p.t.Fatalf("failed to encode configuration: %v", err)
+	}
+	p.File(".ddx/config.yaml", string(data))
+	if err := os.MkdirAll(p.LibraryDir(), 0755); err != nil {
+		p.t.Fatalf("failed to create library: %v", err)
+	}
+	return p.dir
+}
@@ -0,0 +1,58 @@
+package testutil_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/persona"
+	"github.com/easel/ddx/internal/testutil"
+	"github.com/easel/ddx/internal/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectBuilder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := testutil.NewProject(t).
+		Bind("code-reviewer", "strict-reviewer").
+		Activate("helix").
+		Set("ai", map[string]any{"targets": []string{"cursor"}})
+	project.Library().
+		Persona(testutil.PersonaSpec{Name: "strict-reviewer", Roles: []string{"code-reviewer"}, Tags: []string{"quality"}}).
+		Workflow("helix", "").
+		Prompt("claude/review.md", "Review the change.\n")
+	project.Persona(testutil.PersonaSpec{Name: "team-architect", Roles: []string{"architect"}, Body: "# Team Architect\n\nKnows the system.\n"})
+	dir := project.Build()
+
+	cfg, err := config.LoadWithWorkingDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ".ddx/library", cfg.Library.Path)
+	assert.Equal(t, "strict-reviewer", cfg.PersonaBindings["code-reviewer"])
+	assert.Equal(t, []string{"helix"}, cfg.Workflows.Active)
+	assert.Equal(t, []string{"cursor"}, cfg.GetAITargets())
+
+	p, err := persona.NewPersonaLoaderWithDir(filepath.Join(project.LibraryDir(), "personas")).LoadPersona("strict-reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"quality"}, p.Tags)
+	assert.Equal(t, "The strict reviewer persona", p.Description)
+	p, err = persona.NewPersonaLoaderWithDir(filepath.Join(dir, ".ddx", "personas")).LoadPersona("team-architect")
+	require.NoError(t, err)
+	assert.Contains(t, p.Content, "Knows the system.")
+
+	def, err := workflow.NewLoader(project.LibraryDir()).Load("helix")
+	require.NoError(t, err)
+	assert.Equal(t, "helix", def.Name)
+	assert.FileExists(t, filepath.Join(project.LibraryDir(), "prompts", "claude", "review.md"))
+}
+
+func TestProjectBuilder_WithLibrary(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	lib := testutil.NewLibrary(t, "").Template("go-service", map[string]string{"main.go": "package main\n"})
+	dir := testutil.NewProject(t).WithLibrary(lib).Build()
+
+	cfg, err := config.LoadWithWorkingDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, lib.Dir(), cfg.Library.Path)
+	assert.FileExists(t, filepath.Join(lib.Dir(), "templates", "go-service", "main.go"))
+}
@@ -0,0 +1,390 @@
+// Package transaction makes commands that change several files all or
+// nothing. Writes are staged in temporary files beside their targets and
+// only renamed into place on Apply or Commit. Files that other code
+// changes, such as git or a hook, are tracked first so they can be put
+// back. When any step fails, Rollback restores every file the transaction
+// touched and removes the ones it created.
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+)
+
+// Error is the failure of an operation whose changes were rolled back
+type Error struct {
+	// Op names the operation, such as "persona load"
+	Op  string
+	Err error
+	// RollbackErr is set when some changes could not be undone
+	RollbackErr error
+}
+
+func (e *Error) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("%s failed: %v (rolling back also failed, so some files may be left changed: %v)", e.Op, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("%s failed and its changes were rolled back: %v", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// staged is a write waiting to be applied
+type staged struct {
+	path string
+	tmp  string
+}
+
+// Tx is a set of file changes under a root directory, normally the project
+type Tx struct {
+	root   string
+	staged []staged
+	// undo restores what the transaction changed, in the order it changed
+	// it; Rollback runs it backwards
+	undo []func() error
+	// tracked are the paths whose original state is recorded
+	tracked []string
+	// backups are removed once the transaction commits
+	backups []string
+	done    bool
+}
+
+// Begin starts a transaction for changes under root
+func Begin(root string) *Tx {
+	return &Tx{root: filepath.Clean(root)}
+}
+
+// Run runs fn in a transaction and commits it. When fn or the commit
+// fails, the transaction is rolled back and the returned *Error names op.
+func Run(root, op string, fn func(tx *Tx) error) error {
+	tx := Begin(root)
+	err := fn(tx)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return tx.Fail(op, err)
+	}
+	return nil
+}
+
+// abs resolves a path against the root
+func (t *Tx) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(t.root, path)
+}
+
+// WriteFile stages data to be written to path. An existing file keeps its
+// permissions; perm applies to new files.
+func (t *Tx) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return t.Write(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Write stages the content fn writes to be written to path. Staging a
+// path again replaces what was staged before.
+func (t *Tx) Write(path string, perm os.FileMode, fn func(w io.Writer) error) (err error) {
+	if t.done {
+		return fmt.Errorf("transaction already committed")
+	}
+	path = t.abs(path)
+	// Write through symlinks, as atomicfile does
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := t.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err = fn(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	for i, s := range t.staged {
+		if s.path == path {
+			_ = os.Remove(s.tmp)
+			t.staged[i].tmp = tmp.Name()
+			return nil
+		}
+	}
+	t.staged = append(t.staged, staged{path: path, tmp: tmp.Name()})
+	return nil
+}
+
+// mkdirAll creates a directory and its parents, undoing their creation on
+// rollback
+func (t *Tx) mkdirAll(dir string) error {
+	missing := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		}
+		missing = d
+		if d == filepath.Dir(d) {
+			break
+		}
+	}
+	if missing == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", t.display(dir), err)
+	}
+	t.undo = append(t.undo, func() error { return os.RemoveAll(missing) })
+	return nil
+}
+
+// Track records the current state of files or directories that are about
+// to be changed outside the transaction, so Rollback can restore them.
+// Paths that do not exist yet are removed on rollback, together with any
+// parent directories created since.
+func (t *Tx) Track(paths ...string) error {
+	for _, path := range paths {
+		if err := t.track(t.abs(path), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// track records the state of one path. Files are linked rather than
+// copied when link is set, which is only safe for files that are going to
+// be replaced by a rename rather than rewritten in place.
+func (t *Tx) track(path string, link bool) error {
+	for _, tracked := range t.tracked {
+		if path == tracked || strings.HasPrefix(path, tracked+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	t.tracked = append(t.tracked, path)
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Remove the path and whatever parents it will be created in
+		created := path
+		for d := filepath.Dir(path); d != filepath.Dir(d); d = filepath.Dir(d) {
+			if _, err := os.Lstat(d); err == nil {
+				break
+			}
+			created = d
+		}
+		t.undo = append(t.undo, func() error { return os.RemoveAll(created) })
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	backup, err := saveOriginal(path, info, link)
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", t.display(path), err)
+	}
+	t.backups = append(t.backups, backup)
+	t.undo = append(t.undo, func() error {
+		if info.IsDir() {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		return os.Rename(backup, path)
+	})
+	return nil
+}
+
+// saveOriginal copies a file, symlink or directory beside itself. With
+// link, a file is hard linked where the filesystem allows.
+func saveOriginal(path string, info fs.FileInfo, link bool) (string, error) {
+	if info.IsDir() {
+		backup, err := os.MkdirTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.orig")
+		if err != nil {
+			return "", err
+		}
+		if err := copyTree(path, backup); err != nil {
+			_ = os.RemoveAll(backup)
+			return "", err
+		}
+		return backup, nil
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.orig")
+	if err != nil {
+		return "", err
+	}
+	backup := f.Name()
+	_ = f.Close()
+	_ = os.Remove(backup)
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return backup, os.Symlink(target, backup)
+	}
+	if link && os.Link(path, backup) == nil {
+		return backup, nil
+	}
+	if err := copyFile(path, backup, info); err != nil {
+		_ = os.Remove(backup)
+		return "", err
+	}
+	return backup, nil
+}
+
+// OnRollback registers fn to undo a step that is not a file change, such
+// as a git commit. It runs in reverse order with the file restores.
+func (t *Tx) OnRollback(fn func() error) {
+	t.undo = append(t.undo, fn)
+}
+
+// Apply moves the staged writes into place, so later steps of the
+// operation see them, while keeping what is needed to roll them back. If
+// a write fails, the transaction stays open for Rollback to undo the ones
+// before it.
+func (t *Tx) Apply() error {
+	if t.done {
+		return fmt.Errorf("transaction already committed")
+	}
+	for len(t.staged) > 0 {
+		s := t.staged[0]
+		if err := t.track(s.path, true); err != nil {
+			return err
+		}
+		if err := os.Rename(s.tmp, s.path); err != nil {
+			return fmt.Errorf("failed to write %s: %w", t.display(s.path), err)
+		}
+		t.staged = t.staged[1:]
+	}
+	return nil
+}
+
+// Commit applies the staged writes and ends the transaction, after which
+// Rollback does nothing. A failed commit leaves the transaction for
+// Rollback.
+func (t *Tx) Commit() error {
+	if err := t.Apply(); err != nil {
+		return err
+	}
+	t.done = true
+	for _, backup := range t.backups {
+		_ = os.RemoveAll(backup)
+	}
+	t.undo, t.tracked, t.backups = nil, nil, nil
+	return nil
+}
+
+// Rollback discards the staged writes and restores everything the
+// transaction changed. Once committed it does nothing.
+func (t *Tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	for _, s := range t.staged {
+		_ = os.Remove(s.tmp)
+	}
+	var errs []error
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		if err := t.undo[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.staged, t.undo, t.tracked = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+// Fail rolls the transaction back after err ended op, returning the one
+// error that describes both. When the transaction had not changed or
+// staged anything yet, err is returned as it is.
+func (t *Tx) Fail(op string, err error) error {
+	if !t.done && len(t.staged) == 0 && len(t.undo) == 0 {
+		t.done = true
+		return err
+	}
+	return &Error{Op: op, Err: err, RollbackErr: t.Rollback()}
+}
+
+// display shows a path relative to the root when it is inside it
+func (t *Tx) display(path string) string {
+	if rel, err := filepath.Rel(t.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// copyTree copies the directory src into the existing directory dst
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info)
+		}
+	})
+}
+
+// copyFile copies a regular file, keeping its permissions
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	return atomicfile.Write(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
@@ -0,0 +1,119 @@
+package transaction
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// entries lists the names in a directory, including hidden ones
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+	list, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range list {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestCommit(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "CLAUDE.md"), "# Old\n")
+
+	tx := Begin(dir)
+	require.NoError(t, tx.WriteFile("CLAUDE.md", []byte("# New\n"), 0644))
+	require.NoError(t, tx.WriteFile(filepath.Join("docs", "guide", "intro.md"), []byte("Intro\n"), 0644))
+
+	// Nothing is visible until the transaction commits
+	data, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Old\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "guide", "intro.md"))
+
+	require.NoError(t, tx.Commit())
+	data, err = os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New\n", string(data))
+	assert.FileExists(t, filepath.Join(dir, "docs", "guide", "intro.md"))
+	assert.ElementsMatch(t, []string{"CLAUDE.md", "docs"}, entries(t, dir))
+
+	// Rolling back a committed transaction changes nothing
+	require.NoError(t, tx.Rollback())
+	assert.FileExists(t, filepath.Join(dir, "docs", "guide", "intro.md"))
+}
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "CLAUDE.md"), "# Old\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "library", "personas", "reviewer.md"), "# Reviewer\n")
+
+	tx := Begin(dir)
+	require.NoError(t, tx.WriteFile("CLAUDE.md", []byte("# New\n"), 0644))
+	require.NoError(t, tx.WriteFile(filepath.Join("docs", "intro.md"), []byte("Intro\n"), 0644))
+	require.NoError(t, tx.Apply())
+	data, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New\n", string(data))
+
+	// Changes made outside the transaction after tracking
+	require.NoError(t, tx.Track(".ddx", "ddx.lock"))
+	writeFile(t, filepath.Join(dir, ".ddx", "config.yaml"), "version: \"2.0\"\n")
+	writeFile(t, filepath.Join(dir, ".ddx", "library", "personas", "new.md"), "# New\n")
+	writeFile(t, filepath.Join(dir, "ddx.lock"), "version: 1\n")
+	var undone []string
+	tx.OnRollback(func() error { undone = append(undone, "commit"); return nil })
+	require.NoError(t, tx.WriteFile(filepath.Join(".ddx", "prompts", "review.md"), []byte("Review\n"), 0644))
+
+	require.NoError(t, tx.Rollback())
+	assert.Equal(t, []string{"commit"}, undone)
+	data, err = os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Old\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, ".ddx", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"1.0\"\n", string(data))
+	assert.FileExists(t, filepath.Join(dir, ".ddx", "library", "personas", "reviewer.md"))
+	assert.NoFileExists(t, filepath.Join(dir, ".ddx", "library", "personas", "new.md"))
+	assert.ElementsMatch(t, []string{"CLAUDE.md", ".ddx"}, entries(t, dir))
+	assert.ElementsMatch(t, []string{"config.yaml", "library"}, entries(t, filepath.Join(dir, ".ddx")))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "CLAUDE.md"), "# Old\n")
+	cause := errors.New("hook failed")
+
+	err := Run(dir, "template apply", func(tx *Tx) error {
+		require.NoError(t, tx.WriteFile("CLAUDE.md", []byte("# New\n"), 0644))
+		require.NoError(t, tx.Apply())
+		return cause
+	})
+	var txErr *Error
+	require.ErrorAs(t, err, &txErr)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "template apply failed and its changes were rolled back: hook failed", err.Error())
+	data, readErr := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, readErr)
+	assert.Equal(t, "# Old\n", string(data))
+	assert.Equal(t, []string{"CLAUDE.md"}, entries(t, dir))
+
+	require.NoError(t, Run(dir, "template apply", func(tx *Tx) error {
+		return tx.WriteFile("CLAUDE.md", []byte("# New\n"), 0644)
+	}))
+	data, err = os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New\n", string(data))
+}
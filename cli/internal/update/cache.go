@@ -6,13 +6,14 @@ import (
 	"os"
 	"path/filepath"
 	"time"
-)
 
-const (
-	cacheTTL      = 24 * time.Hour
-	cacheFileName = "last-update-check.json"
+	"github.com/easel/ddx/internal/atomicfile"
+	"github.com/easel/ddx/internal/userdirs"
 )
 
+// cacheFileName is the update check cache, kept in the ddx cache directory
+const cacheFileName = "update-check.json"
+
 // Cache manages the update check cache file
 type Cache struct {
 	filePath string
@@ -75,33 +76,22 @@ func (c *Cache) Save() error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(c.filePath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(c.filePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write cache: %w", err)
 	}
 
 	return nil
 }
 
-// IsExpired checks if cache is older than TTL
-func (c *Cache) IsExpired() bool {
+// IsExpired checks if the last check is older than ttl
+func (c *Cache) IsExpired(ttl time.Duration) bool {
 	if c.data.LastCheck.IsZero() {
 		return true // No check recorded
 	}
-	return time.Since(c.data.LastCheck) > cacheTTL
+	return time.Since(c.data.LastCheck) > ttl
 }
 
-// getCacheFilePath returns the cache file path following XDG Base Directory spec
+// getCacheFilePath returns the cache file in the ddx cache directory
 func (c *Cache) getCacheFilePath() (string, error) {
-	// Check XDG_CACHE_HOME first
-	cacheDir := os.Getenv("XDG_CACHE_HOME")
-	if cacheDir == "" {
-		// Fall back to ~/.cache
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		cacheDir = filepath.Join(homeDir, ".cache")
-	}
-
-	return filepath.Join(cacheDir, "ddx", cacheFileName), nil
+	return userdirs.Path(userdirs.Cache, filepath.Join("state", cacheFileName))
 }
@@ -75,7 +75,7 @@ func TestCache_IsExpired_Fresh(t *testing.T) {
 	}
 
 	// When: IsExpired is called
-	expired := cache.IsExpired()
+	expired := cache.IsExpired(24 * time.Hour)
 
 	// Then: Should not be expired
 	assert.False(t, expired, "Cache should not be expired after 1 hour")
@@ -90,7 +90,7 @@ func TestCache_IsExpired_Old(t *testing.T) {
 	}
 
 	// When: IsExpired is called
-	expired := cache.IsExpired()
+	expired := cache.IsExpired(24 * time.Hour)
 
 	// Then: Should be expired
 	assert.True(t, expired, "Cache should be expired after 25 hours")
@@ -105,7 +105,7 @@ func TestCache_IsExpired_Exactly24Hours(t *testing.T) {
 	}
 
 	// When: IsExpired is called
-	expired := cache.IsExpired()
+	expired := cache.IsExpired(24 * time.Hour)
 
 	// Then: Should not be expired (24h is the limit, > 24h is expired)
 	assert.False(t, expired, "Cache at exactly 24 hours should not be expired")
@@ -156,11 +156,9 @@ func TestCache_Save_CreatesDirectory(t *testing.T) {
 
 func TestCache_GetCacheFilePath_XDGCompliance(t *testing.T) {
 	// Given: XDG_CACHE_HOME environment variable set
-	originalXDG := os.Getenv("XDG_CACHE_HOME")
-	defer func() { _ = os.Setenv("XDG_CACHE_HOME", originalXDG) }()
-
 	testCacheDir := t.TempDir()
-	_ = os.Setenv("XDG_CACHE_HOME", testCacheDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", testCacheDir)
 
 	// When: getCacheFilePath is called
 	cache := &Cache{}
@@ -168,26 +166,30 @@ func TestCache_GetCacheFilePath_XDGCompliance(t *testing.T) {
 
 	// Then: Should use XDG_CACHE_HOME
 	require.NoError(t, err)
-	assert.Contains(t, path, testCacheDir)
-	assert.Contains(t, path, "ddx")
-	assert.Contains(t, path, "last-update-check.json")
+	assert.Equal(t, filepath.Join(testCacheDir, "ddx", "state", "update-check.json"), path)
 }
 
 func TestCache_GetCacheFilePath_FallbackToHome(t *testing.T) {
-	// Given: XDG_CACHE_HOME not set
-	originalXDG := os.Getenv("XDG_CACHE_HOME")
-	defer func() { _ = os.Setenv("XDG_CACHE_HOME", originalXDG) }()
-	_ = os.Unsetenv("XDG_CACHE_HOME")
+	// Given: A home directory and no XDG_CACHE_HOME
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
 
 	// When: getCacheFilePath is called
 	cache := &Cache{}
 	path, err := cache.getCacheFilePath()
 
-	// Then: Should fall back to ~/.cache/ddx/
+	// Then: Should fall back to ~/.cache/ddx
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cache", "ddx", "state", "update-check.json"), path)
+
+	// And: A cache not yet migrated from ~/.ddx/state is used in place
+	legacy := filepath.Join(home, ".ddx", "state", "update-check.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0755))
+	require.NoError(t, os.WriteFile(legacy, []byte("{}"), 0644))
+	path, err = cache.getCacheFilePath()
 	require.NoError(t, err)
-	homeDir, _ := os.UserHomeDir()
-	expectedPrefix := filepath.Join(homeDir, ".cache", "ddx")
-	assert.Contains(t, path, expectedPrefix)
+	assert.Equal(t, legacy, path)
 }
 
 func TestCacheData_JSONSerialization(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/github"
 )
 
 // Checker handles update checking logic
@@ -15,6 +16,8 @@ type Checker struct {
 	config         *config.NewConfig
 	cache          *Cache
 	result         *UpdateCheckResult
+	// GitHub queries the latest release
+	GitHub *github.Client
 }
 
 // NewChecker creates a new Checker instance
@@ -27,18 +30,20 @@ func NewChecker(version string, cfg *config.NewConfig) *Checker {
 		currentVersion: version,
 		config:         cfg,
 		cache:          cache,
+		GitHub:         github.NewClient(nil),
 	}
 }
 
 // ShouldCheck returns true if an update check is needed
 func (c *Checker) ShouldCheck() bool {
 	// Check if disabled in config
-	if c.config.UpdateCheck != nil && !c.config.UpdateCheck.Enabled {
+	interval := c.config.UpdateCheckInterval()
+	if interval == 0 {
 		return false
 	}
 
 	// Check if cache is expired or missing
-	if c.cache.IsExpired() {
+	if c.cache.IsExpired(interval) {
 		return true
 	}
 
@@ -78,14 +83,12 @@ func (c *Checker) CheckForUpdate(ctx context.Context) (*UpdateCheckResult, error
 	result := &UpdateCheckResult{}
 
 	// Fetch latest release from GitHub
-	release, err := FetchLatestRelease()
+	release, err := FetchLatestRelease(ctx, c.GitHub)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to fetch latest release: %w", err)
 		c.result = result
 
-		// Update cache with error
-		c.cache.data.CheckError = err.Error()
-		_ = c.cache.Save() // Ignore save errors
+		c.recordFailure(err)
 
 		return result, err
 	}
@@ -96,9 +99,7 @@ func (c *Checker) CheckForUpdate(ctx context.Context) (*UpdateCheckResult, error
 		result.Error = fmt.Errorf("failed to compare versions: %w", err)
 		c.result = result
 
-		// Update cache with error
-		c.cache.data.CheckError = err.Error()
-		_ = c.cache.Save() // Ignore save errors
+		c.recordFailure(err)
 
 		return result, err
 	}
@@ -120,6 +121,15 @@ func (c *Checker) CheckForUpdate(ctx context.Context) (*UpdateCheckResult, error
 	return result, nil
 }
 
+// recordFailure caches a failed check. The attempt counts as a check, so
+// an offline machine tries once per interval rather than on every command.
+func (c *Checker) recordFailure(err error) {
+	c.cache.data.LastCheck = time.Now()
+	c.cache.data.CurrentVersion = c.currentVersion
+	c.cache.data.CheckError = err.Error()
+	_ = c.cache.Save() // Ignore save errors
+}
+
 // IsUpdateAvailable returns the result from the last check
 func (c *Checker) IsUpdateAvailable() (bool, string, error) {
 	if c.result == nil {
@@ -2,13 +2,17 @@ package update
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/easel/ddx/internal/config"
+	"github.com/easel/ddx/internal/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewChecker(t *testing.T) {
@@ -23,8 +27,9 @@ func TestNewChecker(t *testing.T) {
 func TestChecker_ShouldCheck_Disabled(t *testing.T) {
 	// Given: Update check disabled in config
 	cfg := config.DefaultNewConfig()
+	enabled := false
 	cfg.UpdateCheck = &config.UpdateCheckConfig{
-		Enabled:   false,
+		Enabled:   &enabled,
 		Frequency: "24h",
 	}
 
@@ -41,7 +46,7 @@ func TestChecker_ShouldCheck_CacheFresh(t *testing.T) {
 	// Given: Config enabled, fresh cache
 	tempDir := t.TempDir()
 	cfg := config.DefaultNewConfig()
-	cfg.UpdateCheck.Enabled = true
+	cfg.UpdateCheck.Enabled = nil
 
 	checker := NewChecker("v0.1.2", cfg)
 
@@ -65,7 +70,7 @@ func TestChecker_ShouldCheck_CacheExpired(t *testing.T) {
 	// Given: Config enabled, expired cache
 	tempDir := t.TempDir()
 	cfg := config.DefaultNewConfig()
-	cfg.UpdateCheck.Enabled = true
+	cfg.UpdateCheck.Enabled = nil
 
 	checker := NewChecker("v0.1.2", cfg)
 
@@ -89,7 +94,7 @@ func TestChecker_ShouldCheck_NoCache(t *testing.T) {
 	// Given: Config enabled, no cache
 	tempDir := t.TempDir()
 	cfg := config.DefaultNewConfig()
-	cfg.UpdateCheck.Enabled = true
+	cfg.UpdateCheck.Enabled = nil
 
 	checker := NewChecker("v0.1.2", cfg)
 	checker.cache = &Cache{
@@ -183,7 +188,7 @@ func TestChecker_RespectEnvVar(t *testing.T) {
 	_ = os.Setenv("DDX_DISABLE_UPDATE_CHECK", "1")
 
 	cfg := config.DefaultNewConfig()
-	cfg.UpdateCheck.Enabled = true // Config says enabled
+	cfg.UpdateCheck.Enabled = nil // Config says enabled
 
 	checker := NewChecker("v0.1.2", cfg)
 
@@ -234,3 +239,50 @@ func TestChecker_VersionChanged(t *testing.T) {
 	// Then: Should check (version mismatch indicates binary was updated)
 	assert.True(t, should, "Should check when current version differs from cached version")
 }
+
+func TestChecker_ShouldCheck_Frequency(t *testing.T) {
+	tests := []struct {
+		check string
+		age   time.Duration
+		want  bool
+	}{
+		{config.UpdatesNever, 30 * 24 * time.Hour, false},
+		{config.UpdatesDaily, 2 * time.Hour, false},
+		{config.UpdatesDaily, 25 * time.Hour, true},
+		{config.UpdatesWeekly, 3 * 24 * time.Hour, false},
+		{config.UpdatesWeekly, 8 * 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.check, func(t *testing.T) {
+			cfg := config.DefaultNewConfig()
+			cfg.Updates = &config.UpdatesConfig{Check: tt.check}
+			checker := NewChecker("v0.1.2", cfg)
+			checker.cache = &Cache{
+				filePath: filepath.Join(t.TempDir(), "cache.json"),
+				data:     &CacheData{LastCheck: time.Now().Add(-tt.age), CurrentVersion: "v0.1.2"},
+			}
+			assert.Equal(t, tt.want, checker.ShouldCheck())
+		})
+	}
+}
+
+func TestChecker_CheckForUpdate_RecordsFailure(t *testing.T) {
+	// Given: A release server that is down
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	checker := NewChecker("v0.1.2", config.DefaultNewConfig())
+	checker.GitHub = github.NewClient(nil)
+	checker.GitHub.BaseURL = server.URL
+	checker.cache = &Cache{filePath: filepath.Join(t.TempDir(), "cache.json"), data: &CacheData{}}
+
+	// When: The check fails
+	_, err := checker.CheckForUpdate(context.Background())
+	require.Error(t, err)
+
+	// Then: The attempt is cached, so the next command does not retry
+	assert.False(t, checker.ShouldCheck())
+	assert.NotEmpty(t, checker.cache.data.CheckError)
+}
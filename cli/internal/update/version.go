@@ -1,33 +1,24 @@
 package update
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/easel/ddx/internal/github"
 )
 
 const (
-	githubAPIURL = "https://api.github.com/repos/easel/ddx/releases/latest"
+	latestReleasePath = "/repos/easel/ddx/releases/latest"
 )
 
 // FetchLatestRelease fetches the latest release information from GitHub
-func FetchLatestRelease() (*GitHubRelease, error) {
-	resp, err := http.Get(githubAPIURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release info: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
+func FetchLatestRelease(ctx context.Context, client *github.Client) (*GitHubRelease, error) {
 	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	if err := client.Get(ctx, latestReleasePath, &release); err != nil {
+		return nil, fmt.Errorf("failed to fetch release info: %w", err)
 	}
 
 	return &release, nil
@@ -0,0 +1,267 @@
+// Package userdirs locates the files ddx keeps for a user outside any
+// project. They follow the XDG base directory specification: settings in
+// $XDG_CONFIG_HOME/ddx, data such as the global library and credentials in
+// $XDG_DATA_HOME/ddx, and anything that can be fetched again in
+// $XDG_CACHE_HOME/ddx. Older versions kept all of it in ~/.ddx; files still
+// there are used in place until Migrate moves them.
+package userdirs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of a user file, which decides the directory it lives in
+type Kind string
+
+const (
+	// Config holds settings the user edits or decides on
+	Config Kind = "config"
+	// Data holds files that cannot be recreated, like credentials
+	Data Kind = "data"
+	// Cache holds files that can be downloaded or computed again
+	Cache Kind = "cache"
+)
+
+// Kinds lists the kinds in the order they are reported
+var Kinds = []Kind{Config, Data, Cache}
+
+// LegacyDirName is the directory in the home directory that held every
+// user file before the XDG layout
+const LegacyDirName = ".ddx"
+
+// base is where a kind's directory is found: the environment variable
+// naming it and the default relative to the home directory
+type base struct {
+	env      string
+	fallback string
+}
+
+var bases = map[Kind]base{
+	Config: {env: "XDG_CONFIG_HOME", fallback: ".config"},
+	Data:   {env: "XDG_DATA_HOME", fallback: filepath.Join(".local", "share")},
+	Cache:  {env: "XDG_CACHE_HOME", fallback: ".cache"},
+}
+
+// Dir returns the ddx directory for a kind. As the specification requires,
+// a relative XDG variable is ignored in favor of the default.
+func Dir(kind Kind) (string, error) {
+	b, ok := bases[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown user directory kind %q", kind)
+	}
+	dir := os.Getenv(b.env)
+	if dir == "" || !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir = filepath.Join(home, b.fallback)
+	}
+	return filepath.Join(dir, "ddx"), nil
+}
+
+// LegacyDir returns the ~/.ddx directory, whether or not it exists
+func LegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, LegacyDirName), nil
+}
+
+// Entry is a file or directory that moved out of ~/.ddx
+type Entry struct {
+	// Legacy is its path relative to ~/.ddx
+	Legacy string
+	// Kind and Name give its new location, Name relative to the kind's
+	// directory
+	Kind Kind
+	Name string
+}
+
+// Entries are the files ddx kept in ~/.ddx and where each belongs now
+var Entries = []Entry{
+	{Legacy: "config.yaml", Kind: Config, Name: "config.yaml"},
+	{Legacy: "trusted-scripts.json", Kind: Config, Name: "trusted-scripts.json"},
+	{Legacy: "library", Kind: Data, Name: "library"},
+	{Legacy: "credentials.enc", Kind: Data, Name: "credentials.enc"},
+	{Legacy: "auth-audit.jsonl", Kind: Data, Name: "auth-audit.jsonl"},
+	{Legacy: "onboarding.json", Kind: Data, Name: "onboarding.json"},
+	{Legacy: "state", Kind: Cache, Name: "state"},
+	{Legacy: filepath.Join("cache", "github"), Kind: Cache, Name: "github"},
+}
+
+// Path returns where the user file name of a kind lives. When it has not
+// been created there but is still in ~/.ddx, the legacy path is returned so
+// nothing is lost before it is migrated.
+func Path(kind Kind, name string) (string, error) {
+	dir, err := Dir(kind)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if legacy, ok := legacyPath(kind, name); ok {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy, nil
+		}
+	}
+	return path, nil
+}
+
+// legacyPath returns where name of a kind was kept in ~/.ddx
+func legacyPath(kind Kind, name string) (string, bool) {
+	legacyDir, err := LegacyDir()
+	if err != nil {
+		return "", false
+	}
+	name = filepath.Clean(name)
+	for _, e := range Entries {
+		if e.Kind != kind {
+			continue
+		}
+		if name == e.Name {
+			return filepath.Join(legacyDir, e.Legacy), true
+		}
+		if rest, ok := strings.CutPrefix(name, e.Name+string(filepath.Separator)); ok {
+			return filepath.Join(legacyDir, e.Legacy, rest), true
+		}
+	}
+	return "", false
+}
+
+// Move is an entry Migrate moved
+type Move struct {
+	From string
+	To   string
+}
+
+// Migrate moves the entries still in ~/.ddx to their XDG locations and
+// removes ~/.ddx when nothing else is left in it. An entry whose new
+// location already exists stays where it is, for the user to reconcile;
+// Inspect reports it. Entries that fail to move are reported in the error
+// and keep being used in place.
+func Migrate() ([]Move, error) {
+	legacyDir, err := LegacyDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(legacyDir); err != nil {
+		return nil, nil
+	}
+
+	var moves []Move
+	var errs []error
+	for _, e := range Entries {
+		from := filepath.Join(legacyDir, e.Legacy)
+		if _, err := os.Lstat(from); err != nil {
+			continue
+		}
+		dir, err := Dir(e.Kind)
+		if err != nil {
+			return moves, err
+		}
+		to := filepath.Join(dir, e.Name)
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s: %w", filepath.Dir(to), err))
+			continue
+		}
+		if err := os.Rename(from, to); err != nil {
+			errs = append(errs, fmt.Errorf("failed to move %s to %s: %w", from, to, err))
+			continue
+		}
+		moves = append(moves, Move{From: from, To: to})
+	}
+
+	// Directories emptied by the moves go too; os.Remove leaves any that
+	// still hold something
+	_ = os.Remove(filepath.Join(legacyDir, "cache"))
+	_ = os.Remove(legacyDir)
+	return moves, errors.Join(errs...)
+}
+
+// Layout describes which of the two layouts hold user files
+type Layout struct {
+	// Dirs are the XDG directories by kind
+	Dirs map[Kind]string
+	// Legacy is ~/.ddx when it exists, or ""
+	Legacy string
+	// Pending are entries only in ~/.ddx, used there until migrated
+	Pending []Entry
+	// Conflicts are entries in both layouts; the XDG copy is used and
+	// the one in ~/.ddx is ignored
+	Conflicts []Entry
+	// Unknown are the other files in ~/.ddx, relative to it
+	Unknown []string
+}
+
+// Inspect reports where user files are, for 'ddx doctor'
+func Inspect() (*Layout, error) {
+	l := &Layout{Dirs: map[Kind]string{}}
+	for _, kind := range Kinds {
+		dir, err := Dir(kind)
+		if err != nil {
+			return nil, err
+		}
+		l.Dirs[kind] = dir
+	}
+	legacyDir, err := LegacyDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(legacyDir); err != nil {
+		return l, nil
+	}
+	l.Legacy = legacyDir
+
+	known := map[string]bool{}
+	for _, e := range Entries {
+		known[e.Legacy] = true
+		if _, err := os.Lstat(filepath.Join(legacyDir, e.Legacy)); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(l.Dirs[e.Kind], e.Name)); err == nil {
+			l.Conflicts = append(l.Conflicts, e)
+		} else {
+			l.Pending = append(l.Pending, e)
+		}
+	}
+
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", legacyDir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && name == "cache" {
+			// Only its github cache is known; report anything else in it
+			inner, _ := os.ReadDir(filepath.Join(legacyDir, name))
+			for _, c := range inner {
+				if rel := filepath.Join(name, c.Name()); !known[rel] {
+					l.Unknown = append(l.Unknown, rel)
+				}
+			}
+			continue
+		}
+		if !known[name] {
+			l.Unknown = append(l.Unknown, name)
+		}
+	}
+	sort.Strings(l.Unknown)
+	return l, nil
+}
+
+// Mixed reports whether files are in both layouts
+func (l *Layout) Mixed() bool {
+	return l.Legacy != "" && (len(l.Pending) > 0 || len(l.Conflicts) > 0 || len(l.Unknown) > 0)
+}
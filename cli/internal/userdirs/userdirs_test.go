@@ -0,0 +1,164 @@
+package userdirs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setHome points the home directory at a temporary one without XDG
+// variables, returning it
+func setHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, b := range bases {
+		t.Setenv(b.env, "")
+	}
+	return home
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestDir(t *testing.T) {
+	home := setHome(t)
+	want := map[Kind]string{
+		Config: filepath.Join(home, ".config", "ddx"),
+		Data:   filepath.Join(home, ".local", "share", "ddx"),
+		Cache:  filepath.Join(home, ".cache", "ddx"),
+	}
+	for kind, path := range want {
+		dir, err := Dir(kind)
+		require.NoError(t, err)
+		assert.Equal(t, path, dir)
+	}
+
+	custom := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", custom)
+	dir, err := Dir(Data)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(custom, "ddx"), dir)
+
+	t.Setenv("XDG_CONFIG_HOME", "relative/config")
+	dir, err = Dir(Config)
+	require.NoError(t, err)
+	assert.Equal(t, want[Config], dir, "relative XDG paths are ignored")
+
+	_, err = Dir("other")
+	assert.Error(t, err)
+}
+
+func TestPath(t *testing.T) {
+	home := setHome(t)
+
+	path, err := Path(Config, "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "ddx", "config.yaml"), path, "new files go to the XDG directory")
+
+	legacy := filepath.Join(home, ".ddx", "config.yaml")
+	writeFile(t, legacy, "version: \"1.0\"\n")
+	path, err = Path(Config, "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, path, "a file not yet migrated is used in place")
+
+	personas := filepath.Join(home, ".ddx", "library", "personas")
+	require.NoError(t, os.MkdirAll(personas, 0755))
+	path, err = Path(Data, filepath.Join("library", "personas"))
+	require.NoError(t, err)
+	assert.Equal(t, personas, path, "paths inside a legacy directory are found")
+
+	github := filepath.Join(home, ".ddx", "cache", "github")
+	require.NoError(t, os.MkdirAll(github, 0755))
+	path, err = Path(Cache, "github")
+	require.NoError(t, err)
+	assert.Equal(t, github, path, "entries may be renamed")
+
+	writeFile(t, filepath.Join(home, ".config", "ddx", "config.yaml"), "version: \"1.0\"\n")
+	path, err = Path(Config, "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "ddx", "config.yaml"), path, "the XDG file wins")
+}
+
+func TestMigrate(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".ddx")
+	writeFile(t, filepath.Join(legacy, "config.yaml"), "config")
+	writeFile(t, filepath.Join(legacy, "credentials.enc"), "secret")
+	writeFile(t, filepath.Join(legacy, "library", "personas", "reviewer.md"), "persona")
+	writeFile(t, filepath.Join(legacy, "state", "update-check.json"), "{}")
+	writeFile(t, filepath.Join(legacy, "cache", "github", "etag"), "etag")
+
+	moves, err := Migrate()
+	require.NoError(t, err)
+	assert.Len(t, moves, 5)
+	assert.NoDirExists(t, legacy, "an emptied ~/.ddx is removed")
+
+	for path, content := range map[string]string{
+		filepath.Join(home, ".config", "ddx", "config.yaml"):                                "config",
+		filepath.Join(home, ".local", "share", "ddx", "credentials.enc"):                    "secret",
+		filepath.Join(home, ".local", "share", "ddx", "library", "personas", "reviewer.md"): "persona",
+		filepath.Join(home, ".cache", "ddx", "state", "update-check.json"):                  "{}",
+		filepath.Join(home, ".cache", "ddx", "github", "etag"):                              "etag",
+	} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, content, string(data))
+	}
+
+	moves, err = Migrate()
+	require.NoError(t, err)
+	assert.Empty(t, moves, "migrating again does nothing")
+}
+
+func TestMigrateKeepsConflictsAndUnknownFiles(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".ddx")
+	writeFile(t, filepath.Join(legacy, "config.yaml"), "old")
+	writeFile(t, filepath.Join(legacy, "onboarding.json"), "{}")
+	writeFile(t, filepath.Join(legacy, "notes.txt"), "mine")
+	writeFile(t, filepath.Join(home, ".config", "ddx", "config.yaml"), "new")
+
+	moves, err := Migrate()
+	require.NoError(t, err)
+	require.Len(t, moves, 1)
+	assert.Equal(t, filepath.Join(legacy, "onboarding.json"), moves[0].From)
+
+	data, err := os.ReadFile(filepath.Join(home, ".config", "ddx", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data), "an existing XDG file is not overwritten")
+	assert.FileExists(t, filepath.Join(legacy, "config.yaml"))
+	assert.FileExists(t, filepath.Join(legacy, "notes.txt"))
+
+	layout, err := Inspect()
+	require.NoError(t, err)
+	assert.Equal(t, legacy, layout.Legacy)
+	assert.True(t, layout.Mixed())
+	assert.Empty(t, layout.Pending)
+	require.Len(t, layout.Conflicts, 1)
+	assert.Equal(t, "config.yaml", layout.Conflicts[0].Legacy)
+	assert.Equal(t, []string{"notes.txt"}, layout.Unknown)
+}
+
+func TestInspect(t *testing.T) {
+	home := setHome(t)
+
+	layout, err := Inspect()
+	require.NoError(t, err)
+	assert.Empty(t, layout.Legacy)
+	assert.False(t, layout.Mixed())
+	assert.Equal(t, filepath.Join(home, ".cache", "ddx"), layout.Dirs[Cache])
+
+	writeFile(t, filepath.Join(home, ".ddx", "trusted-scripts.json"), "{}")
+	writeFile(t, filepath.Join(home, ".ddx", "cache", "other", "file"), "x")
+	layout, err = Inspect()
+	require.NoError(t, err)
+	assert.True(t, layout.Mixed())
+	require.Len(t, layout.Pending, 1)
+	assert.Equal(t, "trusted-scripts.json", layout.Pending[0].Legacy)
+	assert.Equal(t, []string{filepath.Join("cache", "other")}, layout.Unknown)
+}
@@ -0,0 +1,195 @@
+// Package variables maintains the project's variable registry, the values
+// entered for template and prompt placeholders that are reused as defaults
+// the next time the same variable is needed.
+package variables
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the registry file within the project's .ddx directory
+const FileName = "variables.yml"
+
+// placeholderPattern matches {{name}} and {{ name }} placeholders
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// secretPattern matches variable names whose values are never recorded
+var secretPattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|credential|api_?key|private_?key)`)
+
+// Registry is the set of recorded variable values for a project
+type Registry struct {
+	Values map[string]string `yaml:"variables"`
+	// Exclude lists variables that are never recorded
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	path string
+}
+
+// Path returns the registry file for a project
+func Path(workingDir string) string {
+	return filepath.Join(workingDir, ".ddx", FileName)
+}
+
+// Load reads the project's registry, returning an empty registry when the
+// file does not exist
+func Load(workingDir string) (*Registry, error) {
+	reg := &Registry{Values: make(map[string]string), path: Path(workingDir)}
+
+	data, err := os.ReadFile(reg.path)
+	if os.IsNotExist(err) {
+		return reg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables: %w", err)
+	}
+	if err := yaml.Unmarshal(data, reg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", reg.path, err)
+	}
+	if reg.Values == nil {
+		reg.Values = make(map[string]string)
+	}
+	return reg, nil
+}
+
+// Get returns the recorded value of a variable
+func (r *Registry) Get(name string) (string, bool) {
+	value, ok := r.Values[name]
+	return value, ok
+}
+
+// Recordable reports whether a variable's value may be recorded. Secret
+// looking names and excluded variables are never written to the registry.
+func (r *Registry) Recordable(name string) bool {
+	if secretPattern.MatchString(name) {
+		return false
+	}
+	for _, excluded := range r.Exclude {
+		if excluded == name {
+			return false
+		}
+	}
+	return true
+}
+
+// Record stores values for reuse, skipping variables that are not
+// recordable. It reports whether the registry changed.
+func (r *Registry) Record(values map[string]string) bool {
+	changed := false
+	for name, value := range values {
+		if value == "" || !r.Recordable(name) {
+			continue
+		}
+		if current, ok := r.Values[name]; ok && current == value {
+			continue
+		}
+		r.Values[name] = value
+		changed = true
+	}
+	return changed
+}
+
+// Unset removes a recorded variable, reporting whether it was present
+func (r *Registry) Unset(name string) bool {
+	if _, ok := r.Values[name]; !ok {
+		return false
+	}
+	delete(r.Values, name)
+	return true
+}
+
+// Names returns the recorded variable names in sorted order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.Values))
+	for name := range r.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save writes the registry to the project's .ddx directory
+func (r *Registry) Save() error {
+	var buf bytes.Buffer
+	buf.WriteString("# Variable values reused as defaults by ddx. Secrets are never recorded.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to marshal variables: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create .ddx directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(r.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write variables: %w", err)
+	}
+	return nil
+}
+
+// Merge returns the recorded values overlaid with the given values
+func (r *Registry) Merge(values map[string]string) map[string]string {
+	merged := make(map[string]string, len(r.Values)+len(values))
+	for name, value := range r.Values {
+		merged[name] = value
+	}
+	for name, value := range values {
+		merged[name] = value
+	}
+	return merged
+}
+
+// Placeholders returns the distinct placeholder names in content, in order
+// of first appearance
+func Placeholders(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Render substitutes known values into {{name}} placeholders. Placeholders
+// without a value are left in place and their names returned.
+func Render(content string, values map[string]string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+	rendered := placeholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return match
+	})
+	return rendered, missing
+}
+
+// ParseAssignments parses name=value pairs such as those given with --var
+func ParseAssignments(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q (expected name=value)", pair)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,66 @@
+package variables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	content := "# {{project_name}}\n{{ module_path }} {{missing}} {{missing}}"
+
+	rendered, missing := Render(content, map[string]string{"project_name": "demo", "module_path": "example.com/demo"})
+	assert.Equal(t, "# demo\nexample.com/demo {{missing}} {{missing}}", rendered)
+	assert.Equal(t, []string{"missing"}, missing)
+	assert.Equal(t, []string{"project_name", "module_path", "missing"}, Placeholders(content))
+}
+
+func TestRegistryRecord(t *testing.T) {
+	dir := t.TempDir()
+	reg, err := Load(dir)
+	require.NoError(t, err)
+	reg.Exclude = []string{"owner"}
+
+	changed := reg.Record(map[string]string{
+		"project_name":   "demo",
+		"GITHUB_TOKEN":   "ghp_x",
+		"db_password":    "hunter2",
+		"owner":          "me",
+		"empty_variable": "",
+	})
+	assert.True(t, changed)
+	assert.Equal(t, []string{"project_name"}, reg.Names())
+	assert.False(t, reg.Record(map[string]string{"project_name": "demo"}), "unchanged values are not a change")
+	require.NoError(t, reg.Save())
+
+	loaded, err := Load(dir)
+	require.NoError(t, err)
+	value, ok := loaded.Get("project_name")
+	assert.True(t, ok)
+	assert.Equal(t, "demo", value)
+	assert.Equal(t, []string{"owner"}, loaded.Exclude)
+
+	assert.True(t, loaded.Unset("project_name"))
+	assert.False(t, loaded.Unset("project_name"))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ddx"), 0755))
+	require.NoError(t, os.WriteFile(Path(dir), []byte("variables: [not, a, map]"), 0644))
+
+	_, err := Load(dir)
+	assert.Error(t, err)
+}
+
+func TestParseAssignments(t *testing.T) {
+	values, err := ParseAssignments([]string{"name=demo", "url=https://x?a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "demo", "url": "https://x?a=b"}, values)
+
+	_, err = ParseAssignments([]string{"novalue"})
+	assert.Error(t, err)
+}
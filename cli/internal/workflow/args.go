@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommandArg declares a positional argument of a workflow command
+type CommandArg struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+	// Pattern is a regular expression the whole argument must match
+	Pattern string `yaml:"pattern,omitempty"`
+	// Values are the accepted values; shell completion offers them
+	Values []string `yaml:"values,omitempty"`
+}
+
+// CommandSpec is the frontmatter of a command's prompt file:
+//
+//	---
+//	description: Implement a user story
+//	args:
+//	  - name: story-id
+//	    required: true
+//	    pattern: US-[0-9]{3,}
+//	references:
+//	  - ../phases/04-build/README.md
+//	---
+type CommandSpec struct {
+	Description string       `yaml:"description,omitempty"`
+	Args        []CommandArg `yaml:"args,omitempty"`
+	// References are library files the command needs as context, besides
+	// those its prompt links to; see References
+	References []string `yaml:"references,omitempty"`
+}
+
+// ParseCommandPrompt splits a command's prompt file into its frontmatter
+// and the prompt itself. A file without frontmatter has an empty spec and
+// accepts any arguments.
+func ParseCommandPrompt(content string) (*CommandSpec, string, error) {
+	spec := &CommandSpec{}
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return spec, content, nil
+	}
+	frontmatter, body, ok := strings.Cut(normalized[len("---\n"):], "\n---\n")
+	if !ok {
+		if frontmatter, ok = strings.CutSuffix(normalized[len("---\n"):], "\n---"); !ok {
+			return nil, "", fmt.Errorf("frontmatter is not closed with ---")
+		}
+		body = ""
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), spec); err != nil {
+		return nil, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	return spec, strings.TrimLeft(body, "\n"), nil
+}
+
+// Issues returns what is wrong with the declared arguments: missing or
+// duplicate names, patterns that do not compile and required arguments
+// following optional ones
+func (s *CommandSpec) Issues() []Issue {
+	var issues []Issue
+	problem := func(field, format string, a ...any) {
+		issues = append(issues, Issue{Severity: SeverityError, Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+	seen := make(map[string]bool)
+	optional := ""
+	for i, arg := range s.Args {
+		field := fmt.Sprintf("args[%d]", i)
+		if arg.Name == "" {
+			problem(field+".name", "is required")
+		} else if seen[arg.Name] {
+			problem(field+".name", "duplicate argument '%s'", arg.Name)
+		}
+		seen[arg.Name] = true
+		if arg.Pattern != "" {
+			if _, err := compilePattern(arg.Pattern); err != nil {
+				problem(field+".pattern", "%v", err)
+			}
+		}
+		switch {
+		case !arg.Required:
+			optional = arg.Name
+		case optional != "":
+			problem(field+".required", "'%s' cannot follow the optional argument '%s'", arg.Name, optional)
+		}
+	}
+	return issues
+}
+
+// Usage returns the declared arguments as they are written on the command
+// line: <required> [optional]
+func (s *CommandSpec) Usage() string {
+	var parts []string
+	for _, arg := range s.Args {
+		if arg.Required {
+			parts = append(parts, "<"+arg.Name+">")
+		} else {
+			parts = append(parts, "["+arg.Name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ValidateArgs checks command line arguments against the declared ones.
+// A command that declares no arguments accepts any.
+func (s *CommandSpec) ValidateArgs(args []string) error {
+	if len(s.Args) == 0 {
+		return nil
+	}
+	if len(args) > len(s.Args) {
+		return fmt.Errorf("expected at most %d argument(s), got %d", len(s.Args), len(args))
+	}
+	for i, arg := range s.Args {
+		if i >= len(args) {
+			if arg.Required {
+				return fmt.Errorf("missing required argument <%s>", arg.Name)
+			}
+			continue
+		}
+		value := args[i]
+		if len(arg.Values) > 0 && !slices.Contains(arg.Values, value) {
+			return fmt.Errorf("<%s> must be one of %s, got '%s'", arg.Name, strings.Join(arg.Values, ", "), value)
+		}
+		if arg.Pattern == "" {
+			continue
+		}
+		re, err := compilePattern(arg.Pattern)
+		if err != nil {
+			return fmt.Errorf("<%s> has an invalid pattern: %w", arg.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("<%s> must match %s, got '%s'", arg.Name, arg.Pattern, value)
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles an argument pattern anchored to the whole value
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
@@ -0,0 +1,93 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommandPrompt(t *testing.T) {
+	spec, body, err := ParseCommandPrompt("# Build\n\nNo frontmatter.\n")
+	if err != nil || len(spec.Args) != 0 || body != "# Build\n\nNo frontmatter.\n" {
+		t.Fatalf("ParseCommandPrompt() = %+v, %q, %v", spec, body, err)
+	}
+
+	spec, body, err = ParseCommandPrompt(`---
+description: Implement a user story
+args:
+  - name: story-id
+    required: true
+    pattern: US-[0-9]{3,}
+  - name: depth
+    values: [quick, full]
+---
+
+# Build Story
+`)
+	if err != nil {
+		t.Fatalf("ParseCommandPrompt() error = %v", err)
+	}
+	if body != "# Build Story\n" {
+		t.Errorf("body = %q", body)
+	}
+	if spec.Description != "Implement a user story" || len(spec.Args) != 2 {
+		t.Fatalf("spec = %+v", spec)
+	}
+	if usage := spec.Usage(); usage != "<story-id> [depth]" {
+		t.Errorf("Usage() = %q", usage)
+	}
+
+	if _, _, err := ParseCommandPrompt("---\nargs: [\n"); err == nil {
+		t.Error("expected an error for unclosed frontmatter")
+	}
+	if _, _, err := ParseCommandPrompt("---\nargs: {\n---\n"); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestCommandSpec_ValidateArgs(t *testing.T) {
+	spec := &CommandSpec{Args: []CommandArg{
+		{Name: "story-id", Required: true, Pattern: "US-[0-9]{3,}"},
+		{Name: "depth", Values: []string{"quick", "full"}},
+	}}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"US-001"}},
+		{args: []string{"US-042", "full"}},
+		{args: nil, want: "missing required argument <story-id>"},
+		{args: []string{"US-1"}, want: "<story-id> must match US-[0-9]{3,}, got 'US-1'"},
+		{args: []string{"xUS-001"}, want: "must match"},
+		{args: []string{"US-001", "deep"}, want: "<depth> must be one of quick, full, got 'deep'"},
+		{args: []string{"US-001", "full", "extra"}, want: "expected at most 2 argument(s), got 3"},
+	}
+	for _, tt := range tests {
+		err := spec.ValidateArgs(tt.args)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("ValidateArgs(%v) error = %v", tt.args, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("ValidateArgs(%v) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+
+	if err := (&CommandSpec{}).ValidateArgs([]string{"anything", "goes"}); err != nil {
+		t.Errorf("a command without declared arguments rejected %v", err)
+	}
+}
+
+func TestCommandSpec_Issues(t *testing.T) {
+	spec := &CommandSpec{Args: []CommandArg{
+		{Name: "topic"},
+		{Name: "topic", Required: true, Pattern: "("},
+		{Required: true},
+	}}
+	var fields []string
+	for _, issue := range spec.Issues() {
+		fields = append(fields, issue.Field)
+	}
+	want := "args[1].name args[1].pattern args[1].required args[2].name args[2].required"
+	if got := strings.Join(fields, " "); got != want {
+		t.Errorf("Issues() fields = %s, want %s", got, want)
+	}
+}
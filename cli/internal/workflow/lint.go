@@ -0,0 +1,267 @@
+package workflow
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema/workflow.schema.json
+var schemaJSON []byte
+
+// Issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in a workflow definition
+type Issue struct {
+	Severity string `json:"severity"`
+	// Field locates the problem in workflow.yml, e.g. phases[1].required_role
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Field == "" {
+		return i.Message
+	}
+	return i.Field + ": " + i.Message
+}
+
+// LintOptions adds project knowledge to linting
+type LintOptions struct {
+	// Roles are the roles personas declare or the project binds; a phase
+	// requiring any other role is reported. Nil skips the check.
+	Roles map[string]bool
+}
+
+// quotedName matches the property names in schema error messages
+var quotedName = regexp.MustCompile(`'([^']+)'`)
+
+// Lint checks a workflow in the library against the workflow schema and
+// for references that do not resolve: prompt and template files that are
+// missing and roles nothing defines. Issues are sorted by field.
+func (l *Loader) Lint(name string, opts LintOptions) ([]Issue, error) {
+	dir := filepath.Join(l.libraryPath, "workflows", name)
+	data, err := os.ReadFile(filepath.Join(dir, "workflow.yml"))
+	if os.IsNotExist(err) {
+		return []Issue{{Severity: SeverityError, Message: "workflow.yml not found"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return []Issue{{Severity: SeverityError, Message: fmt.Sprintf("invalid YAML: %v", err)}}, nil
+	}
+	issues, err := schemaIssues(normalizeYAML(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		// The schema issues already describe what does not fit
+		return sortIssues(issues), nil
+	}
+	if def.Name != "" && def.Name != name {
+		issues = append(issues, Issue{Severity: SeverityError, Field: "name", Message: fmt.Sprintf("'%s' does not match the directory name '%s'", def.Name, name)})
+	}
+
+	ids := make(map[string]bool)
+	for i, phase := range def.Phases {
+		field := fmt.Sprintf("phases[%d]", i)
+		if ids[phase.ID] {
+			issues = append(issues, Issue{Severity: SeverityError, Field: field + ".id", Message: fmt.Sprintf("duplicate phase id '%s'", phase.ID)})
+		}
+		ids[phase.ID] = true
+		if phase.Order != i+1 {
+			issues = append(issues, Issue{Severity: SeverityWarning, Field: field + ".order", Message: fmt.Sprintf("is %d but the phase is listed %d; phases advance by order", phase.Order, i+1)})
+		}
+		if phase.RequiredRole != "" && opts.Roles != nil && !opts.Roles[phase.RequiredRole] {
+			issues = append(issues, Issue{Severity: SeverityWarning, Field: field + ".required_role", Message: fmt.Sprintf("role '%s' is not declared by any persona or bound in the project", phase.RequiredRole)})
+		}
+		for j, artifact := range phase.Artifacts {
+			artifactField := fmt.Sprintf("%s.artifacts[%d]", field, j)
+			issues = append(issues, missingFile(dir, artifactField+".template", "template", artifact.Template)...)
+			issues = append(issues, missingFile(dir, artifactField+".prompt", "prompt", artifact.Prompt)...)
+			if artifact.Path != "" && !filepath.IsLocal(filepath.FromSlash(artifact.Path)) {
+				issues = append(issues, Issue{Severity: SeverityError, Field: artifactField + ".path", Message: fmt.Sprintf("'%s' must be a relative path inside the docs directory", artifact.Path)})
+			}
+		}
+	}
+	for i, command := range def.Commands {
+		issues = append(issues, missingFile(dir, fmt.Sprintf("commands[%d]", i), "prompt", command.PromptPath())...)
+	}
+	issues = append(issues, commandIssues(dir, &def)...)
+	for name, steps := range def.Pipelines {
+		for i, step := range steps {
+			field := fmt.Sprintf("pipelines.%s[%d].command", name, i)
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(def.CommandPromptPath(step.Command)))); err != nil {
+				issues = append(issues, Issue{Severity: SeverityError, Field: field, Message: fmt.Sprintf("unknown command '%s'", step.Command)})
+			}
+		}
+	}
+	if def.Coordinator != "" {
+		issues = append(issues, missingFile(dir, "coordinator", "coordinator", def.Coordinator)...)
+	}
+	return sortIssues(issues), nil
+}
+
+// missingFile reports a referenced file that does not exist in the
+// workflow directory
+func missingFile(dir, field, kind, rel string) []Issue {
+	if rel == "" {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err == nil {
+		return nil
+	}
+	return []Issue{{Severity: SeverityError, Field: field, Message: fmt.Sprintf("missing %s %s", kind, rel)}}
+}
+
+// commandIssues checks the frontmatter of the command prompt files: those
+// in commands/ and those the definition declares elsewhere
+func commandIssues(dir string, def *Definition) []Issue {
+	var prompts []string
+	if entries, err := os.ReadDir(filepath.Join(dir, "commands")); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+				prompts = append(prompts, "commands/"+entry.Name())
+			}
+		}
+	}
+	for _, command := range def.Commands {
+		if !slices.Contains(prompts, command.PromptPath()) {
+			prompts = append(prompts, command.PromptPath())
+		}
+	}
+
+	var issues []Issue
+	for _, rel := range prompts {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			// Missing prompts are reported with the commands
+			continue
+		}
+		spec, body, err := ParseCommandPrompt(string(content))
+		if err != nil {
+			issues = append(issues, Issue{Severity: SeverityError, Field: rel, Message: err.Error()})
+			continue
+		}
+		for _, issue := range spec.Issues() {
+			issue.Field = rel + ":" + issue.Field
+			issues = append(issues, issue)
+		}
+		promptDir := filepath.Dir(filepath.Join(dir, filepath.FromSlash(rel)))
+		for _, ref := range References(spec, body, promptDir, dir, filepath.Dir(filepath.Dir(dir))) {
+			if ref.Path == "" {
+				issues = append(issues, Issue{Severity: SeverityWarning, Field: rel, Message: fmt.Sprintf("reference '%s' is not a file in the library", ref.Link)})
+			}
+		}
+	}
+	return issues
+}
+
+// schemaIssues validates a decoded workflow.yml against the schema
+func schemaIssues(raw interface{}) ([]Issue, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("workflow.schema.json", strings.NewReader(string(schemaJSON))); err != nil {
+		return nil, fmt.Errorf("failed to add schema resource: %w", err)
+	}
+	schema, err := compiler.Compile("workflow.schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	err = schema.Validate(raw)
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+	var issues []Issue
+	for _, cause := range leafErrors(validationErr) {
+		field := fieldPath(cause.InstanceLocation)
+		if strings.HasSuffix(cause.KeywordLocation, "/additionalProperties") {
+			for _, m := range quotedName.FindAllStringSubmatch(cause.Message, -1) {
+				issues = append(issues, Issue{Severity: SeverityError, Field: joinField(field, m[1]), Message: "unknown field"})
+			}
+			continue
+		}
+		issues = append(issues, Issue{Severity: SeverityError, Field: field, Message: cause.Message})
+	}
+	return issues, nil
+}
+
+// leafErrors flattens nested schema errors to the ones without causes
+func leafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+// fieldPath turns a JSON pointer like /phases/1/id into phases[1].id
+func fieldPath(pointer string) string {
+	var field string
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		if strings.Trim(part, "0123456789") == "" {
+			field += "[" + part + "]"
+			continue
+		}
+		field = joinField(field, part)
+	}
+	return field
+}
+
+// joinField appends a property name to a field path
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// normalizeYAML converts values decoded from YAML into the JSON types the
+// schema validator expects. Unquoted dates decode as times.
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeYAML(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+	case time.Time:
+		return value.Format("2006-01-02")
+	}
+	return v
+}
+
+// sortIssues orders issues by field, keeping the order of issues on the
+// same field
+func sortIssues(issues []Issue) []Issue {
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	return issues
+}
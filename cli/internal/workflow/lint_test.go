@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoader_Lint tests schema and reference checks on workflow.yml
+func TestLoader_Lint(t *testing.T) {
+	libDir := t.TempDir()
+	dir := filepath.Join(libDir, "workflows", "review")
+	if err := os.MkdirAll(filepath.Join(dir, "commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "commands", "start.md"), []byte("# Start\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "workflow.yml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loader := NewLoader(libDir)
+	roles := LintOptions{Roles: map[string]bool{"reviewer": true}}
+
+	write(`name: review
+version: 1.0.0
+created: 2025-01-13
+phases:
+  - id: draft
+    order: 1
+    name: Draft
+    required_role: reviewer
+    artifacts:
+      - name: Summary
+commands:
+  - name: start
+`)
+	issues, err := loader.Lint("review", roles)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+
+	write(`name: reviews
+version: 1.0.0
+phases:
+  - id: draft
+    order: 2
+    name: Draft
+    required_role: editor
+    exit_critera: [done]
+    artifacts:
+      - name: Summary
+        template: templates/summary.md
+commands:
+  - name: publish
+pipelines:
+  ci:
+    - command: start
+    - command: deploy
+`)
+	issues, err = loader.Lint("review", roles)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := []Issue{
+		{Severity: SeverityError, Field: "commands[0]", Message: "missing prompt commands/publish.md"},
+		{Severity: SeverityError, Field: "name", Message: "'reviews' does not match the directory name 'review'"},
+		{Severity: SeverityError, Field: "phases[0].artifacts[0].template", Message: "missing template templates/summary.md"},
+		{Severity: SeverityError, Field: "phases[0].exit_critera", Message: "unknown field"},
+		{Severity: SeverityWarning, Field: "phases[0].order", Message: "is 2 but the phase is listed 1; phases advance by order"},
+		{Severity: SeverityWarning, Field: "phases[0].required_role", Message: "role 'editor' is not declared by any persona or bound in the project"},
+		{Severity: SeverityError, Field: "pipelines.ci[1].command", Message: "unknown command 'deploy'"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %v, want %v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+
+	// Command frontmatter is checked too
+	write("name: review\nversion: 1.0.0\nphases:\n  - id: draft\n    order: 1\n    name: Draft\n")
+	if err := os.WriteFile(filepath.Join(dir, "commands", "start.md"), []byte("---\nargs:\n  - name: id\n    pattern: \"[\"\n---\n# Start\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "commands", "stop.md"), []byte("---\nargs: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	issues, err = loader.Lint("review", roles)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(issues) != 2 || issues[0].Field != "commands/start.md:args[0].pattern" || issues[1].Field != "commands/stop.md" {
+		t.Errorf("issues = %v", issues)
+	}
+}
+
+// TestLoader_LintReferences tests that command references must resolve
+func TestLoader_LintReferences(t *testing.T) {
+	libDir := t.TempDir()
+	dir := filepath.Join(libDir, "workflows", "review")
+	if err := os.MkdirAll(filepath.Join(dir, "commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"workflow.yml":      "name: review\nversion: 1.0.0\nphases:\n  - id: draft\n    order: 1\n    name: Draft\n",
+		"guide.md":          "# Guide\n",
+		"commands/start.md": "# Start\n\nRead the [guide](../guide.md) and the [checklist](checklist.md).\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issues, err := NewLoader(libDir).Lint("review", LintOptions{})
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := Issue{Severity: SeverityWarning, Field: "commands/start.md", Message: "reference 'checklist.md' is not a file in the library"}
+	if len(issues) != 1 || issues[0] != want {
+		t.Errorf("issues = %v, want %v", issues, want)
+	}
+}
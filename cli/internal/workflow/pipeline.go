@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPipeline is the pipeline run when none is named
+const DefaultPipeline = "default"
+
+// RunsDir holds pipeline run output, relative to the project root
+const RunsDir = ".ddx/runs"
+
+// Step is one command in a pipeline
+type Step struct {
+	Command string   `yaml:"command" json:"command"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// Pipeline returns the steps of a named pipeline. The default pipeline,
+// when not defined, runs the declared commands in order.
+func (d *Definition) Pipeline(name string) ([]Step, error) {
+	if name == "" {
+		name = DefaultPipeline
+	}
+	if steps, ok := d.Pipelines[name]; ok {
+		if len(steps) == 0 {
+			return nil, fmt.Errorf("pipeline '%s' has no steps", name)
+		}
+		return steps, nil
+	}
+	if name == DefaultPipeline && len(d.Commands) > 0 {
+		steps := make([]Step, 0, len(d.Commands))
+		for _, command := range d.Commands {
+			steps = append(steps, Step{Command: command.Name})
+		}
+		return steps, nil
+	}
+
+	names := make([]string, 0, len(d.Pipelines))
+	for n := range d.Pipelines {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("workflow '%s' defines no pipelines or commands to run", d.Name)
+	}
+	return nil, fmt.Errorf("pipeline '%s' not found in workflow '%s' (available: %s)", name, d.Name, strings.Join(names, ", "))
+}
+
+// Run statuses
+const (
+	RunPassed  = "passed"
+	RunFailed  = "failed"
+	RunSkipped = "skipped"
+)
+
+// StepResult records how a pipeline step ran
+type StepResult struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args,omitempty"`
+	Status   string   `json:"status"`
+	ExitCode int      `json:"exit_code"`
+	// Prompt and Output are files in the run directory
+	Prompt     string `json:"prompt,omitempty"`
+	Output     string `json:"output,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RunSummary describes a pipeline run
+type RunSummary struct {
+	Workflow string       `json:"workflow"`
+	Pipeline string       `json:"pipeline"`
+	Dir      string       `json:"dir"`
+	Started  time.Time    `json:"started"`
+	Finished time.Time    `json:"finished"`
+	Status   string       `json:"status"`
+	ExitCode int          `json:"exit_code"`
+	Steps    []StepResult `json:"steps"`
+}
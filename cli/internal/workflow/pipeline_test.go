@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDefinition_Pipeline tests resolving pipeline steps
+func TestDefinition_Pipeline(t *testing.T) {
+	def := &Definition{
+		Name:     "review-cycle",
+		Commands: []Command{{Name: "start"}, {Name: "review"}},
+		Pipelines: map[string][]Step{
+			"ci":    {{Command: "review", Args: []string{"--strict"}}},
+			"empty": {},
+		},
+	}
+
+	steps, err := def.Pipeline("")
+	if err != nil {
+		t.Fatalf("Pipeline(\"\") error = %v", err)
+	}
+	if len(steps) != 2 || steps[0].Command != "start" || steps[1].Command != "review" {
+		t.Errorf("default pipeline = %v, want the declared commands", steps)
+	}
+
+	steps, err = def.Pipeline("ci")
+	if err != nil {
+		t.Fatalf("Pipeline(ci) error = %v", err)
+	}
+	if len(steps) != 1 || steps[0].Args[0] != "--strict" {
+		t.Errorf("ci pipeline = %v", steps)
+	}
+
+	if _, err := def.Pipeline("empty"); err == nil || !strings.Contains(err.Error(), "no steps") {
+		t.Errorf("Pipeline(empty) error = %v", err)
+	}
+	if _, err := def.Pipeline("nightly"); err == nil || !strings.Contains(err.Error(), "available: ci, empty") {
+		t.Errorf("Pipeline(nightly) error = %v", err)
+	}
+	if _, err := (&Definition{Name: "bare"}).Pipeline(""); err == nil {
+		t.Error("Pipeline() on a workflow without commands should fail")
+	}
+}
@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Reference is a library file a command prompt refers to
+type Reference struct {
+	// Link is the reference as written in the prompt
+	Link string `json:"link"`
+	// Path is the file it resolves to, empty when there is none
+	Path string `json:"path,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// markdownLink matches [text](target), but not images
+var markdownLink = regexp.MustCompile(`(^|[^!])\[[^\]]*\]\(<?([^)\s>]+)>?(?:\s+"[^"]*")?\)`)
+
+// urlScheme matches links such as https: and mailto:
+var urlScheme = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// References finds the files a command prompt refers to: those listed
+// under references in its frontmatter, then the relative markdown links in
+// its body outside code blocks. Each is looked up against the prompt's
+// directory, the workflow directory and the library root in turn, and must
+// stay inside the library. A file referred to twice is listed once.
+func References(spec *CommandSpec, body, promptDir, workflowDir, libraryDir string) []Reference {
+	links := append([]string(nil), spec.References...)
+	fenced := false
+	for _, line := range strings.Split(body, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		for _, m := range markdownLink.FindAllStringSubmatch(line, -1) {
+			links = append(links, m[2])
+		}
+	}
+
+	var refs []Reference
+	seen := make(map[string]bool)
+	for _, link := range links {
+		target, ok := linkTarget(link)
+		if !ok {
+			continue
+		}
+		ref := Reference{Link: link}
+		bases := []string{promptDir, workflowDir, libraryDir}
+		if strings.HasPrefix(target, "/") {
+			bases = []string{libraryDir}
+		}
+		for _, base := range bases {
+			path := filepath.Join(base, filepath.FromSlash(target))
+			if rel, err := filepath.Rel(libraryDir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				ref.Path, ref.Size = path, info.Size()
+				break
+			}
+		}
+		key := ref.Path
+		if key == "" {
+			key = "missing:" + link
+		}
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// linkTarget returns the file a relative link names, without its fragment
+// or query. URLs and links within the page have none.
+func linkTarget(link string) (string, bool) {
+	if urlScheme.MatchString(link) || strings.HasPrefix(link, "//") {
+		return "", false
+	}
+	if i := strings.IndexAny(link, "#?"); i >= 0 {
+		link = link[:i]
+	}
+	if unescaped, err := url.PathUnescape(link); err == nil {
+		link = unescaped
+	}
+	return link, link != ""
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	libDir := t.TempDir()
+	workflowDir := filepath.Join(libDir, "workflows", "helix")
+	promptDir := filepath.Join(workflowDir, "commands")
+	files := map[string]string{
+		"workflows/helix/phases/04-build/README.md": "# Build\n",
+		"workflows/helix/conventions.md":            "# Conventions\n",
+		"templates/story.md":                        "# Story\n",
+		"../outside.md":                             "secret\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(libDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := &CommandSpec{References: []string{"../phases/04-build/README.md"}}
+	body := "Follow the [conventions](conventions.md#naming), the [phase guide](../phases/04-build/README.md)\n" +
+		"and the [story template](/templates/story.md). See [the site](https://example.com), [above](#top),\n" +
+		"![diagram](diagram.png), [outside](../../../../outside.md) and [missing](missing.md).\n" +
+		"```\n[in code](conventions.md)\n```\n"
+	refs := References(spec, body, promptDir, workflowDir, libDir)
+
+	want := []Reference{
+		{Link: "../phases/04-build/README.md", Path: filepath.Join(workflowDir, "phases", "04-build", "README.md"), Size: 8},
+		{Link: "conventions.md#naming", Path: filepath.Join(workflowDir, "conventions.md"), Size: 14},
+		{Link: "/templates/story.md", Path: filepath.Join(libDir, "templates", "story.md"), Size: 8},
+		{Link: "../../../../outside.md"},
+		{Link: "missing.md"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("References() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("reference %d = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
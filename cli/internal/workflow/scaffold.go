@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/easel/ddx/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPhases are the phases of a new workflow when none are given
+var DefaultPhases = []string{"plan", "build", "review"}
+
+// reservedNames are workflow subcommands a workflow cannot be named after
+var reservedNames = map[string]bool{
+	"status": true, "list": true, "activate": true, "deactivate": true,
+	"advance": true, "report": true, "create": true, "lint": true, "bindings": true,
+	"run": true,
+}
+
+// namePattern matches workflow and phase identifiers
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ScaffoldOptions describes a workflow to generate
+type ScaffoldOptions struct {
+	Name        string
+	Description string
+	Author      string
+	// Phases are phase IDs in order; DefaultPhases when empty
+	Phases []string
+}
+
+// Scaffold creates a new workflow in libraryPath/workflows/<name>: the
+// workflow.yml definition, a README, a commands/ directory with a starter
+// command and a documentation directory per phase. The generated workflow
+// is loaded back and linted; on failure nothing is left behind. It
+// returns the created files relative to the workflow directory.
+func Scaffold(libraryPath string, opts ScaffoldOptions) (*Definition, []string, error) {
+	if !namePattern.MatchString(opts.Name) {
+		return nil, nil, fmt.Errorf("invalid workflow name '%s': use lowercase letters, digits and dashes", opts.Name)
+	}
+	if reservedNames[opts.Name] {
+		return nil, nil, fmt.Errorf("'%s' is a workflow subcommand and cannot be used as a workflow name", opts.Name)
+	}
+	phases := opts.Phases
+	if len(phases) == 0 {
+		phases = DefaultPhases
+	}
+	seen := make(map[string]bool)
+	for _, id := range phases {
+		if !namePattern.MatchString(id) {
+			return nil, nil, fmt.Errorf("invalid phase id '%s': use lowercase letters, digits and dashes", id)
+		}
+		if seen[id] {
+			return nil, nil, fmt.Errorf("phase '%s' is listed more than once", id)
+		}
+		seen[id] = true
+	}
+
+	dir := filepath.Join(libraryPath, "workflows", opts.Name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, nil, fmt.Errorf("workflow '%s' already exists at %s", opts.Name, dir)
+	}
+
+	def := &Definition{
+		Name:        opts.Name,
+		Version:     "0.1.0",
+		Description: opts.Description,
+		Author:      opts.Author,
+		Created:     time.Now().Format("2006-01-02"),
+		Commands:    []Command{{Name: "start", Description: fmt.Sprintf("Start the %s workflow", opts.Name)}},
+	}
+	if def.Description == "" {
+		def.Description = fmt.Sprintf("%s workflow", titleCase(opts.Name))
+	}
+	for i, id := range phases {
+		def.Phases = append(def.Phases, Phase{
+			ID:           id,
+			Order:        i + 1,
+			Name:         titleCase(id),
+			Description:  fmt.Sprintf("Describe the work done in the %s phase", titleCase(id)),
+			ExitCriteria: []string{"Define what must be true to leave this phase"},
+		})
+	}
+
+	definition, err := yaml.Marshal(def)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode workflow.yml: %w", err)
+	}
+	files := [][2]string{
+		{"workflow.yml", string(definition)},
+		{"README.md", scaffoldReadme(def)},
+		{"commands/start.md", scaffoldStartCommand(def)},
+	}
+	for _, phase := range def.Phases {
+		files = append(files, [2]string{phaseDocPath(phase), scaffoldPhaseDoc(def, phase)})
+	}
+
+	created := make([]string, 0, len(files))
+	for _, file := range files {
+		rel, content := file[0], file[1]
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, nil, fmt.Errorf("failed to create %s: %w", rel, err)
+		}
+		if err := atomicfile.WriteFile(path, []byte(content), 0644); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, nil, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		created = append(created, rel)
+	}
+
+	loader := NewLoader(libraryPath)
+	loaded, err := loader.Load(opts.Name)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, fmt.Errorf("generated workflow is invalid: %w", err)
+	}
+	issues, err := loader.Lint(opts.Name, LintOptions{})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			_ = os.RemoveAll(dir)
+			return nil, nil, fmt.Errorf("generated workflow is invalid: %s", issue)
+		}
+	}
+	return loaded, created, nil
+}
+
+// phaseDocPath is the documentation file of a phase, numbered by order
+// like the library's workflows
+func phaseDocPath(phase Phase) string {
+	return fmt.Sprintf("phases/%02d-%s/README.md", phase.Order, phase.ID)
+}
+
+// scaffoldReadme returns the workflow's README
+func scaffoldReadme(def *Definition) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n## Phases\n\n", titleCase(def.Name), def.Description)
+	for _, phase := range def.Phases {
+		fmt.Fprintf(&b, "%d. [%s](%s) (`%s`)\n", phase.Order, phase.Name, phaseDocPath(phase), phase.ID)
+	}
+	fmt.Fprintf(&b, `
+## Commands
+
+Each Markdown file in commands/ is a command. List them with
+'ddx workflow %[1]s commands' and run one with
+'ddx workflow %[1]s execute <command>'.
+`, def.Name)
+	return b.String()
+}
+
+// scaffoldStartCommand returns the starter command
+func scaffoldStartCommand(def *Definition) string {
+	first := def.Phases[0]
+	return fmt.Sprintf(`# Start the %s workflow
+
+Begin with the %s phase. Read %s and work toward its exit criteria
+before running 'ddx workflow advance %s'.
+`, def.Name, first.Name, phaseDocPath(first), def.Name)
+}
+
+// scaffoldPhaseDoc returns the documentation of one phase
+func scaffoldPhaseDoc(def *Definition, phase Phase) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n## Exit Criteria\n\n", phase.Name, phase.Description)
+	for _, criterion := range phase.ExitCriteria {
+		fmt.Fprintf(&b, "- [ ] %s\n", criterion)
+	}
+	if next := def.GetNextPhase(phase.ID); next != nil {
+		fmt.Fprintf(&b, "\nNext phase: %s\n", next.Name)
+	}
+	return b.String()
+}
+
+// titleCase turns a dashed identifier into words with initial capitals
+func titleCase(id string) string {
+	words := strings.Split(id, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
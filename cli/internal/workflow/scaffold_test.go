@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestScaffold tests generating a new workflow
+func TestScaffold(t *testing.T) {
+	libDir := t.TempDir()
+
+	def, files, err := Scaffold(libDir, ScaffoldOptions{Name: "review-cycle", Phases: []string{"draft", "peer-review"}})
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+	if def.Description != "Review Cycle workflow" {
+		t.Errorf("Description = %q", def.Description)
+	}
+	if got := def.GetPhaseNames(); strings.Join(got, ",") != "draft,peer-review" {
+		t.Errorf("phases = %v", got)
+	}
+	if def.GetNextPhase("draft").Name != "Peer Review" {
+		t.Errorf("next phase after draft = %v", def.GetNextPhase("draft"))
+	}
+
+	want := []string{"workflow.yml", "README.md", "commands/start.md", "phases/01-draft/README.md", "phases/02-peer-review/README.md"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	for _, file := range want {
+		if _, err := os.Stat(filepath.Join(libDir, "workflows", "review-cycle", file)); err != nil {
+			t.Errorf("%s not created: %v", file, err)
+		}
+	}
+
+	if _, _, err := Scaffold(libDir, ScaffoldOptions{Name: "review-cycle"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an already exists error, got %v", err)
+	}
+}
+
+// TestScaffold_InvalidInput tests that bad names and phases are refused
+// without creating anything
+func TestScaffold_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        ScaffoldOptions
+		errContains string
+	}{
+		{"uppercase name", ScaffoldOptions{Name: "Review"}, "invalid workflow name"},
+		{"reserved name", ScaffoldOptions{Name: "status"}, "workflow subcommand"},
+		{"bad phase", ScaffoldOptions{Name: "review", Phases: []string{"Draft"}}, "invalid phase id"},
+		{"duplicate phase", ScaffoldOptions{Name: "review", Phases: []string{"draft", "draft"}}, "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			libDir := t.TempDir()
+			_, _, err := Scaffold(libDir, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.errContains)
+			}
+			if _, err := os.Stat(filepath.Join(libDir, "workflows")); !os.IsNotExist(err) {
+				t.Error("workflows directory should not be created")
+			}
+		})
+	}
+}
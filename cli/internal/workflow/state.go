@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/easel/ddx/internal/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,11 +21,70 @@ type State struct {
 	TasksCompleted  []string          `yaml:"tasks_completed,omitempty"`
 	NextActions     []string          `yaml:"next_actions,omitempty"`
 	PhaseProgress   map[string]int    `yaml:"phase_progress,omitempty"`
+	// Artifacts are the documents generated for the workflow, by name
+	Artifacts map[string]ArtifactRecord `yaml:"artifacts,omitempty"`
+	// Audit is the append-only record of commands run and phase transitions
+	Audit []AuditEntry `yaml:"audit,omitempty"`
+}
+
+// ArtifactRecord is a document generated by 'ddx workflow <name> artifact'
+type ArtifactRecord struct {
+	// Path is relative to the project, with forward slashes
+	Path     string   `yaml:"path"`
+	Phase    string   `yaml:"phase,omitempty"`
+	Role     string   `yaml:"role,omitempty"`
+	Personas []string `yaml:"personas,omitempty"`
+	// Generated is when the stub was written, in RFC 3339 format
+	Generated string `yaml:"generated"`
+}
+
+// Audit actions
+const (
+	AuditExecute  = "execute"
+	AuditAdvance  = "advance"
+	AuditArtifact = "artifact"
+)
+
+// AuditEntry records who did what to a workflow and when
+type AuditEntry struct {
+	// Time is when the action ran, in RFC 3339 format
+	Time   string `yaml:"time" json:"time"`
+	User   string `yaml:"user" json:"user"`
+	Email  string `yaml:"email,omitempty" json:"email,omitempty"`
+	Action string `yaml:"action" json:"action"`
+	// Command and Args describe an executed workflow command
+	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+	// Artifact names a generated artifact
+	Artifact string `yaml:"artifact,omitempty" json:"artifact,omitempty"`
+	// Phase is the phase the workflow was in; for an advance it is the
+	// phase that was left and To the phase that was entered
+	Phase string `yaml:"phase,omitempty" json:"phase,omitempty"`
+	To    string `yaml:"to,omitempty" json:"to,omitempty"`
+}
+
+// Record appends an entry to the audit trail, stamping it with the current
+// time when it has none
+func (s *State) Record(entry AuditEntry) {
+	if entry.Time == "" {
+		entry.Time = time.Now().Format(time.RFC3339)
+	}
+	s.Audit = append(s.Audit, entry)
+}
+
+// StateFile returns the name of a workflow's state file
+func StateFile(workflowName string) string {
+	return fmt.Sprintf(".%s-state.yml", workflowName)
 }
 
 // LoadState loads the workflow state for a given workflow
 func LoadState(workflowName string) (*State, error) {
-	stateFile := fmt.Sprintf(".%s-state.yml", workflowName)
+	return LoadStateFrom("", workflowName)
+}
+
+// LoadStateFrom loads the workflow state kept in dir
+func LoadStateFrom(dir, workflowName string) (*State, error) {
+	stateFile := filepath.Join(dir, StateFile(workflowName))
 
 	data, err := os.ReadFile(stateFile)
 	if err != nil {
@@ -44,7 +104,12 @@ func LoadState(workflowName string) (*State, error) {
 
 // SaveState saves the workflow state
 func SaveState(state *State) error {
-	stateFile := fmt.Sprintf(".%s-state.yml", state.Workflow)
+	return SaveStateTo("", state)
+}
+
+// SaveStateTo saves the workflow state in dir
+func SaveStateTo(dir string, state *State) error {
+	stateFile := filepath.Join(dir, StateFile(state.Workflow))
 
 	state.LastUpdated = time.Now().Format("2006-01-02 15:04:05")
 
@@ -53,7 +118,7 @@ func SaveState(state *State) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(stateFile, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(stateFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -22,6 +23,38 @@ type Definition struct {
 	AgentCommands map[string]AgentCommand `yaml:"agent_commands,omitempty"`
 	Phases        []Phase                 `yaml:"phases"`
 	Variables     []Variable              `yaml:"variables,omitempty"`
+	Commands      []Command               `yaml:"commands,omitempty"`
+	// Pipelines are named sequences of commands run by 'ddx workflow run'
+	Pipelines map[string][]Step `yaml:"pipelines,omitempty"`
+}
+
+// Command declares a workflow command and the prompt file it shows
+type Command struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Prompt is relative to the workflow directory; commands/<name>.md
+	// when empty
+	Prompt string `yaml:"prompt,omitempty"`
+}
+
+// PromptPath returns the command's prompt file relative to the workflow
+// directory
+func (c Command) PromptPath() string {
+	if c.Prompt != "" {
+		return c.Prompt
+	}
+	return "commands/" + c.Name + ".md"
+}
+
+// CommandPromptPath returns the prompt file of a command relative to the
+// workflow directory, whether or not the command is declared
+func (d *Definition) CommandPromptPath(name string) string {
+	for _, command := range d.Commands {
+		if command.Name == name {
+			return command.PromptPath()
+		}
+	}
+	return Command{Name: name}.PromptPath()
 }
 
 // AgentCommand defines a command that Claude can invoke
@@ -40,13 +73,64 @@ type Triggers struct {
 
 // Phase represents a workflow phase
 type Phase struct {
-	ID                string   `yaml:"id"`
-	Order             int      `yaml:"order"`
-	Name              string   `yaml:"name"`
-	Description       string   `yaml:"description"`
-	RequiredRole      string   `yaml:"required_role,omitempty"`
-	ExitCriteria      []string `yaml:"exit_criteria,omitempty"`
-	EstimatedDuration string   `yaml:"estimated_duration,omitempty"`
+	ID           string `yaml:"id"`
+	Order        int    `yaml:"order"`
+	Name         string `yaml:"name"`
+	Description  string `yaml:"description"`
+	RequiredRole string `yaml:"required_role,omitempty"`
+	// Principles guide AI agents while the phase is current, such as
+	// "tests first"; they are written into the AI configuration files
+	Principles        []string   `yaml:"principles,omitempty"`
+	ExitCriteria      []string   `yaml:"exit_criteria,omitempty"`
+	EstimatedDuration string     `yaml:"estimated_duration,omitempty"`
+	Artifacts         []Artifact `yaml:"artifacts,omitempty"`
+}
+
+// Artifact is a document a phase produces. Template and Prompt are
+// relative to the workflow directory.
+type Artifact struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Template    string `yaml:"template,omitempty"`
+	Prompt      string `yaml:"prompt,omitempty"`
+	// RequiredRole is the role that writes the artifact; the phase's
+	// required role when empty
+	RequiredRole string `yaml:"required_role,omitempty"`
+	// Path is where the document is generated, relative to the project's
+	// docs directory; <workflow>/<name>.md when empty
+	Path string `yaml:"path,omitempty"`
+}
+
+// OutputPath returns the slash-separated path of the artifact's document
+// relative to the docs directory
+func (a Artifact) OutputPath(workflow string) string {
+	if a.Path != "" {
+		return path.Clean(a.Path)
+	}
+	return workflow + "/" + a.Slug() + ".md"
+}
+
+// Slug returns the artifact's name in lower case with hyphens for spaces
+func (a Artifact) Slug() string {
+	return strings.ToLower(strings.Join(strings.Fields(a.Name), "-"))
+}
+
+// FindArtifact returns the artifact with the given name or slug, compared
+// without regard to case, and the phase that produces it
+func (d *Definition) FindArtifact(name string) (*Artifact, *Phase) {
+	for i := range d.Phases {
+		phase := &d.Phases[i]
+		for j := range phase.Artifacts {
+			if strings.EqualFold(phase.Artifacts[j].Name, name) || strings.EqualFold(phase.Artifacts[j].Slug(), name) {
+				artifact := phase.Artifacts[j]
+				if artifact.RequiredRole == "" {
+					artifact.RequiredRole = phase.RequiredRole
+				}
+				return &artifact, phase
+			}
+		}
+	}
+	return nil, nil
 }
 
 // Variable represents a workflow variable
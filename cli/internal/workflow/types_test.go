@@ -0,0 +1,41 @@
+package workflow
+
+import "testing"
+
+// TestDefinition_FindArtifact tests looking up artifacts and where they are
+// generated
+func TestDefinition_FindArtifact(t *testing.T) {
+	def := &Definition{
+		Name: "helix",
+		Phases: []Phase{
+			{ID: "frame", RequiredRole: "product-owner", Artifacts: []Artifact{{Name: "Feature Spec"}}},
+			{ID: "design", RequiredRole: "architect", Artifacts: []Artifact{
+				{Name: "architecture-doc", Path: "design/./architecture.md"},
+				{Name: "test-plan", RequiredRole: "test-engineer"},
+			}},
+		},
+	}
+
+	artifact, phase := def.FindArtifact("feature-spec")
+	if artifact == nil || phase.ID != "frame" {
+		t.Fatalf("FindArtifact(feature-spec) = %v, %v", artifact, phase)
+	}
+	if artifact.RequiredRole != "product-owner" {
+		t.Errorf("RequiredRole = %q, want the phase's role", artifact.RequiredRole)
+	}
+	if got := artifact.OutputPath("helix"); got != "helix/feature-spec.md" {
+		t.Errorf("OutputPath() = %q", got)
+	}
+
+	artifact, _ = def.FindArtifact("Architecture-Doc")
+	if artifact == nil || artifact.OutputPath("helix") != "design/architecture.md" {
+		t.Errorf("FindArtifact(Architecture-Doc) = %v", artifact)
+	}
+	artifact, _ = def.FindArtifact("test-plan")
+	if artifact == nil || artifact.RequiredRole != "test-engineer" {
+		t.Errorf("FindArtifact(test-plan) = %v, want its own role", artifact)
+	}
+	if artifact, _ := def.FindArtifact("runbook"); artifact != nil {
+		t.Errorf("FindArtifact(runbook) = %v, want nil", artifact)
+	}
+}
@@ -0,0 +1,175 @@
+// Package workspace finds the DDx projects of a repository. A project is a
+// directory with .ddx/config.yaml. In a monorepo, where each service keeps
+// its own persona bindings and workflows, the repository root can list its
+// sub-projects in .ddx/workspace.yaml:
+//
+//	projects:
+//	  - services/api
+//	  - services/*
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the workspace file, kept in the workspace root's .ddx
+const FileName = "workspace.yaml"
+
+// projectConfig is the file that makes a directory a project
+var projectConfig = filepath.Join(".ddx", "config.yaml")
+
+// Workspace is a directory whose sub-projects are worked on together
+type Workspace struct {
+	// Root is the directory holding .ddx/workspace.yaml
+	Root string `yaml:"-"`
+	// Projects are slash-separated paths or globs relative to Root
+	Projects []string `yaml:"projects"`
+}
+
+// Project is a sub-project of a workspace
+type Project struct {
+	// Path is relative to the workspace root, with forward slashes
+	Path string `json:"path"`
+	Dir  string `json:"dir"`
+	// Initialized is set when the project has .ddx/config.yaml
+	Initialized bool `json:"initialized"`
+}
+
+// IsProject reports whether dir holds a project configuration
+func IsProject(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, projectConfig))
+	return err == nil
+}
+
+// FindProject returns the nearest directory at or above dir that is a
+// project. The home directory is never one: its .ddx holds the global
+// configuration.
+func FindProject(dir string) (string, bool) {
+	return findUp(dir, IsProject)
+}
+
+// Find returns the workspace of the nearest directory at or above dir with
+// a workspace file, or nil when there is none
+func Find(dir string) (*Workspace, error) {
+	root, ok := findUp(dir, func(d string) bool {
+		_, err := os.Stat(filepath.Join(d, ".ddx", FileName))
+		return err == nil
+	})
+	if !ok {
+		return nil, nil
+	}
+	return Load(root)
+}
+
+// Load reads the workspace file of root
+func Load(root string) (*Workspace, error) {
+	file := filepath.Join(root, ".ddx", FileName)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+	ws := &Workspace{}
+	if err := yaml.Unmarshal(data, ws); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	for _, pattern := range ws.Projects {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" || path.IsAbs(pattern) ||
+			pattern == ".." || strings.HasPrefix(path.Clean(pattern), "../") {
+			return nil, fmt.Errorf("invalid project %q in %s", pattern, file)
+		}
+	}
+	ws.Root = root
+	return ws, nil
+}
+
+// List returns the workspace's projects sorted by path. Globs expand to the
+// matching directories that are projects; plain paths are listed whether
+// or not they have been initialized.
+func (w *Workspace) List() ([]Project, error) {
+	seen := map[string]bool{}
+	var projects []Project
+	add := func(dir string) {
+		rel, err := filepath.Rel(w.Root, dir)
+		if err != nil || seen[rel] {
+			return
+		}
+		seen[rel] = true
+		projects = append(projects, Project{Path: filepath.ToSlash(rel), Dir: dir, Initialized: IsProject(dir)})
+	}
+
+	for _, pattern := range w.Projects {
+		pattern = path.Clean(pattern)
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(filepath.Join(w.Root, filepath.FromSlash(pattern)))
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(w.Root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid project %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if IsProject(match) {
+				add(match)
+			}
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+	return projects, nil
+}
+
+// Lookup finds a project by its path or, when unambiguous, by the last
+// element of its path
+func (w *Workspace) Lookup(name string) (Project, error) {
+	projects, err := w.List()
+	if err != nil {
+		return Project{}, err
+	}
+	name = strings.Trim(path.Clean(filepath.ToSlash(name)), "/")
+	var matches []Project
+	for _, p := range projects {
+		if p.Path == name {
+			return p, nil
+		}
+		if path.Base(p.Path) == name {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("project %q is not in the workspace at %s", name, w.Root)
+	case 1:
+		return matches[0], nil
+	}
+	paths := make([]string, len(matches))
+	for i, p := range matches {
+		paths[i] = p.Path
+	}
+	return Project{}, fmt.Errorf("project %q is ambiguous: %s", name, strings.Join(paths, ", "))
+}
+
+// findUp returns the first directory from dir up to the filesystem root,
+// skipping the home directory, for which found holds
+func findUp(dir string, found func(string) bool) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		if dir != home && found(dir) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	writeFile(t, filepath.Join(root, "services", "api", ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "api", "internal", "handlers"), 0755))
+
+	dir, ok := FindProject(filepath.Join(root, "services", "api", "internal", "handlers"))
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "services", "api"), dir)
+
+	_, ok = FindProject(filepath.Join(root, "services"))
+	assert.False(t, ok)
+}
+
+func TestFindProjectSkipsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "code"), 0755))
+
+	_, ok := FindProject(filepath.Join(home, "code"))
+	assert.False(t, ok, "the global configuration does not make home a project")
+}
+
+func TestWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".ddx", FileName), "projects:\n  - services/*\n  - tools/cli\n")
+	writeFile(t, filepath.Join(root, "services", "api", ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	writeFile(t, filepath.Join(root, "services", "web", ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "docs"), 0755))
+
+	ws, err := Find(filepath.Join(root, "services", "api"))
+	require.NoError(t, err)
+	require.NotNil(t, ws)
+	assert.Equal(t, root, ws.Root)
+
+	projects, err := ws.List()
+	require.NoError(t, err)
+	require.Len(t, projects, 3)
+	assert.Equal(t, "services/api", projects[0].Path)
+	assert.True(t, projects[0].Initialized)
+	assert.Equal(t, "services/web", projects[1].Path)
+	assert.Equal(t, "tools/cli", projects[2].Path)
+	assert.False(t, projects[2].Initialized, "listed paths are kept before they are initialized")
+
+	project, err := ws.Lookup("web")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "services", "web"), project.Dir)
+	project, err = ws.Lookup("services/api/")
+	require.NoError(t, err)
+	assert.Equal(t, "services/api", project.Path)
+	_, err = ws.Lookup("billing")
+	assert.ErrorContains(t, err, `project "billing" is not in the workspace`)
+
+	writeFile(t, filepath.Join(root, "tools", "api", ".ddx", "config.yaml"), "version: \"1.0\"\n")
+	writeFile(t, filepath.Join(root, ".ddx", FileName), "projects: [services/*, tools/*]\n")
+	ws, err = Load(root)
+	require.NoError(t, err)
+	_, err = ws.Lookup("api")
+	assert.ErrorContains(t, err, `project "api" is ambiguous: services/api, tools/api`)
+}
+
+func TestLoadRejectsOutsidePaths(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".ddx", FileName), "projects: [../other]\n")
+	_, err := Load(root)
+	assert.ErrorContains(t, err, `invalid project "../other"`)
+
+	ws, err := Find(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, ws)
+}
@@ -0,0 +1,14 @@
+#!/bin/sh
+# Rejects commit messages that do not start with a Conventional Commits
+# type, such as "feat: add login" or "fix(api)!: drop v1 routes".
+message=$(grep -v '^#' "$1" | head -n 1)
+
+case "$message" in
+Merge* | Revert* | fixup!* | squash!*) exit 0 ;;
+esac
+
+if ! printf '%s\n' "$message" | grep -qE '^(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([a-z0-9._-]+\))?!?: .+'; then
+	echo "commit-msg: '$message' is not a Conventional Commits message" >&2
+	echo "  expected <type>(<scope>): <summary>, e.g. 'feat(cli): add hooks command'" >&2
+	exit 1
+fi
@@ -0,0 +1,20 @@
+#!/bin/sh
+# Runs the project's tests before pushing. Set DDX_PRE_PUSH_TEST to choose
+# the command; otherwise make test, npm test or go test is used.
+if [ -n "$DDX_PRE_PUSH_TEST" ]; then
+	test_cmd=$DDX_PRE_PUSH_TEST
+elif [ -f Makefile ] && grep -q '^test:' Makefile; then
+	test_cmd="make test"
+elif [ -f package.json ]; then
+	test_cmd="npm test"
+elif [ -f go.mod ]; then
+	test_cmd="go test ./..."
+else
+	exit 0
+fi
+
+echo "pre-push: running $test_cmd" >&2
+if ! sh -c "$test_cmd"; then
+	echo "pre-push: tests failed; push aborted (use --no-verify to skip)" >&2
+	exit 1
+fi